@@ -3,8 +3,9 @@ Package main is the entry point for the Agent Swarm Go application.
 
 This file orchestrates the entire system by:
   - Loading environment variables from .env file
+  - Loading the swarm composition from swarm.yaml (or -config PATH)
   - Creating and configuring the agent swarm
-  - Initializing AI-powered agents (researcher, analyzer, reporter)
+  - Initializing the AI-powered agents the config declares
   - Starting the web dashboard for real-time monitoring
   - Launching the interactive CLI for user interaction
   - Handling graceful shutdown on interrupt signals
@@ -20,8 +21,12 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
@@ -29,10 +34,23 @@ import (
 	"time"
 
 	"agent-swarm-go/pkg/agents"
+	"agent-swarm-go/pkg/agentstats"
+	"agent-swarm-go/pkg/archive"
+	"agent-swarm-go/pkg/batch"
+	"agent-swarm-go/pkg/bench"
+	"agent-swarm-go/pkg/config"
+	"agent-swarm-go/pkg/experiment"
 	"agent-swarm-go/pkg/interactive"
 	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/notify"
+	"agent-swarm-go/pkg/prompts"
 	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/transport"
+	"agent-swarm-go/pkg/types"
 	"agent-swarm-go/pkg/web"
+	"agent-swarm-go/pkg/webhook"
+	"agent-swarm-go/pkg/workflows"
 )
 
 // init runs before main() and loads environment variables from .env file
@@ -89,61 +107,231 @@ func loadEnvFile(filename string) {
 // main is the application entry point that sets up and runs the agent swarm system.
 //
 // Execution flow:
-//   1. Create swarm coordinator
-//   2. Check for API keys (OpenAI or Anthropic)
-//   3. Create three specialized AI agents:
+//   1. Load the swarm config (swarm.yaml, -config PATH, or the built-in default)
+//   2. Create swarm coordinator
+//   3. Check for API keys (OpenAI or Anthropic)
+//   4. Create the agents the config declares; by default:
 //      - researcher-1: Gathers comprehensive information on topics
 //      - analyzer-1: Analyzes data and identifies patterns
 //      - reporter-1: Generates professional reports
-//   4. Start all agents in background goroutines
-//   5. Launch web dashboard on port 8080
-//   6. Start interactive CLI for user commands
-//   7. Wait for user to quit or Ctrl+C
-//   8. Gracefully shutdown all components
+//      - documenter-1: Writes a cover page for each research workflow
+//   5. Start all agents in background goroutines
+//   6. Launch web dashboard on the configured address and port (default :8080)
+//   7. Start interactive CLI for user commands
+//   8. Wait for user to quit or Ctrl+C
+//   9. Gracefully shutdown all components
 func main() {
+	// Without -config, swarm.yaml is used if present, else the built-in swarm
+	configPath := flag.String("config", "", "swarm config file (YAML or JSON, default "+config.DefaultPath+")")
+	// With -checkpoint, a run interrupted by a crash or deploy picks up where it left off
+	checkpointPath := flag.String("checkpoint", "", "save the swarm to this file as it runs and restore it at start-up")
+	// Where the dashboard listens; these override web.address/web.port and DASHBOARD_ADDR/DASHBOARD_PORT
+	webAddr := flag.String("addr", "", "dashboard bind address (default every interface)")
+	webPort := flag.Int("port", 0, "dashboard port (default web.port from the config, 8080)")
+	flag.Parse()
+
+	// "run FILE" executes a task file and exits instead of starting the
+	// dashboard and interactive CLI; "experiment FILE" runs a task set against
+	// two variants and writes a comparison; "worker" serves tasks for a
+	// coordinator through the config's transport; "prompts [DIR]" writes out
+	// the built-in system prompts for editing; "bench [FLAGS]" measures
+	// throughput with synthetic agents and prints a JSON report; "research
+	// [-json] [TOPIC]" researches a topic, or each line of stdin, and prints
+	// the results
+	var batchFile, experimentFile string
+	var research *researchRequest
+	worker := false
+	switch flag.Arg(0) {
+	case "":
+	case "run":
+		if flag.NArg() != 2 {
+			log.Fatalf("Usage: %s [-config PATH] run TASKS.json|TASKS.csv", os.Args[0])
+		}
+		batchFile = flag.Arg(1)
+	case "experiment":
+		if flag.NArg() != 2 {
+			log.Fatalf("Usage: %s [-config PATH] experiment EXPERIMENT.yaml", os.Args[0])
+		}
+		experimentFile = flag.Arg(1)
+	case "worker":
+		worker = true
+	case "prompts":
+		writePrompts(flag.Arg(1))
+		return
+	case "bench":
+		runBench(flag.Args()[1:])
+		return
+	case "research":
+		research = parseResearch(flag.Args()[1:])
+	default:
+		log.Fatalf("Unknown command %q (want \"run\", \"experiment\", \"worker\", \"prompts\", \"bench\", or \"research\")", flag.Arg(0))
+	}
+
+	// Install the structured logger (LOG_FORMAT=text|json, LOG_LEVEL=debug|info|warn|error)
+	// Agents, the swarm, and the web server all log through slog's default logger
+	logging.Setup()
+
+	// Load and validate the config before anything starts, so a typo fails fast
+	// with every problem listed
+	cfg, err := config.LoadDefault(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Scrub the config's secrets and patterns from events, logs, and the
+	// dashboard, on top of the API keys found in the environment
+	if err := cfg.ApplyRedaction(); err != nil {
+		log.Fatalf("Failed to set up redaction: %v", err)
+	}
+	if err := cfg.ApplyEventTypes(); err != nil {
+		log.Fatalf("Failed to register event types: %v", err)
+	}
+
+	// Read the task file up front too, so a bad row doesn't cost an agent start-up
+	var batchTasks []types.Task
+	if batchFile != "" {
+		if batchTasks, err = batch.Load(batchFile); err != nil {
+			log.Fatalf("Failed to load tasks: %v", err)
+		}
+	}
+	var exp *experiment.Experiment
+	if experimentFile != "" {
+		if exp, err = experiment.Load(experimentFile); err != nil {
+			log.Fatalf("Failed to load experiment: %v", err)
+		}
+	}
+
+	// Status messages go to stderr when stdout carries research results, so
+	// they can be piped
+	status := os.Stdout
+	if research != nil {
+		status = os.Stderr
+	}
+
+	// Cap LLM requests across all agents (0 = unlimited)
+	llm.SetRateLimit(cfg.RateLimits.LLMRequestsPerMinute)
+
+	// Retry LLM requests that fail with a network error, 429, or 5xx
+	llm.SetRetryPolicy(cfg.LLMRetry.Policy())
+
+	// Give mock responses a real provider's latency and failures, if the
+	// config asks to
+	if sim := cfg.MockSimulation.Simulation(); sim.Enabled() {
+		llm.SetSimulation(sim)
+		slog.Info("mock simulation enabled", "latency", sim.Latency.Distribution,
+			"error_rate", sim.ErrorRate, "fatal_rate", sim.FatalRate, "hang_rate", sim.HangRate)
+	}
+
+	// Let callbacks reach the internal hosts the config names, and no others
+	webhook.AllowHosts(cfg.Webhooks.AllowHosts)
+
+	// Pool connections to the LLM APIs, with the configured timeouts and proxy
+	if err := cfg.LLMHTTP.Apply(); err != nil {
+		log.Fatalf("Failed to set up LLM HTTP client: %v", err)
+	}
+
+	// Send short or simple tasks to a cheaper model, if the config asks to
+	if router := cfg.ModelRouting.Router(); router != nil {
+		llm.SetRouter(router)
+		slog.Info("LLM model routing enabled", "max_cheap_tokens", router.MaxCheapTokens)
+	}
+
+	// Answer repeated LLM requests from the cache, if the config sets one up
+	if cache, err := cfg.LLMCache.Cache(); err != nil {
+		log.Fatalf("Failed to open LLM cache: %v", err)
+	} else if cache != nil {
+		llm.SetCache(cache)
+		slog.Info("LLM response cache enabled", "type", cfg.LLMCache.Type)
+	}
+
+	// Record real responses to fixture files, or replay them without an API
+	// key (LLM_FIXTURES=record|replay, LLM_FIXTURES_DIR)
+	fixtureMode, err := llm.SetupFixtures()
+	if err != nil {
+		log.Fatalf("Failed to set up LLM fixtures: %v", err)
+	}
+
+	// Swap in any system prompts the user has rewritten
+	if n, err := cfg.Prompts.Apply(); err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
+	} else if n > 0 {
+		slog.Info("prompt overrides loaded", "count", n)
+	}
+
+	if worker {
+		runWorker(cfg)
+		return
+	}
+	if exp != nil {
+		if failed := runExperiment(cfg, experimentFile, exp); failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create a new swarm coordinator that manages all agents
 	// The swarm handles agent registration, task distribution, and event publishing
-	s := swarm.NewSwarm()
+	// The task queue lives in Redis when the config gives one
+	swarmOpts, err := cfg.SwarmOptions()
+	if err != nil {
+		log.Fatalf("Failed to set up task queue: %v", err)
+	}
+	s := swarm.NewSwarm(swarmOpts...)
+
+	// Redeliver tasks whose agent goes quiet for 2 minutes (at most 3 deliveries)
+	// so a hung agent can't leave a workflow waiting forever
+	s.SetVisibilityTimeout(swarm.VisibilityConfig{
+		Timeout:     2 * time.Minute,
+		MaxReceives: 3,
+		RetryDelay:  2 * time.Second,
+	})
+
+	// Cron schedules live in schedules.json; scheduled workflows run on the
+	// same engine as the CLI, with approvals answered from the dashboard
+	if err := s.SetScheduleStore("schedules.json"); err != nil {
+		log.Fatalf("Failed to load schedules: %v", err)
+	}
+	s.SetWorkflowRunner(func(path string, inputs map[string]string) error {
+		def, err := workflows.LoadDefinition(path)
+		if err != nil {
+			return err
+		}
+		_, err = workflows.NewEngine(s).Run(def, inputs)
+		return err
+	})
 
 	// Check for API keys and display connection status
 	// The LLM client will try OPENAI_API_KEY first, then ANTHROPIC_API_KEY
 	llmClient := llm.NewClient()
-	if !llmClient.HasAPIKey() {
+	if fixtureMode == llm.FixturesReplay {
+		fmt.Fprintln(status, "\n🎞️  Replaying recorded LLM responses (LLM_FIXTURES=replay); no API calls are made")
+		fmt.Fprintln(status)
+	} else if !llmClient.HasAPIKey() {
 		// No API key found - system will run in demo mode with simulated responses
-		fmt.Println("\n⚠️  WARNING: No API key found (OPENAI_API_KEY or ANTHROPIC_API_KEY)")
-		fmt.Println("   Agents will run in DEMO MODE with simulated responses")
-		fmt.Println("   Set an API key in your environment for real AI-powered research\n")
+		fmt.Fprintln(status, "\n⚠️  WARNING: No API key found (OPENAI_API_KEY or ANTHROPIC_API_KEY)")
+		fmt.Fprintln(status, "   Agents will run in DEMO MODE with simulated responses")
+		fmt.Fprintln(status, "   Set an API key in your environment for real AI-powered research")
+		fmt.Fprintln(status)
 		time.Sleep(2 * time.Second)
 	} else {
 		// API key found - show which provider we're using
-		fmt.Printf("\n✅ Connected to %s for AI-powered agent swarm\n\n", llmClient.GetProvider())
+		fmt.Fprintf(status, "\n✅ Connected to %s for AI-powered agent swarm\n\n", llmClient.GetProvider())
 	}
 
-	// Create three specialized AI agents
-	// Each agent has a unique ID and receives the swarm's event bus for publishing events
-
-	// ResearchAgent: Specializes in gathering comprehensive information on any topic
-	// Uses LLM to break down research questions, gather data, and identify key insights
-	researcher := agents.NewResearchAgent("researcher-1", s.GetEventBus())
-
-	// AnalysisAgent: Specializes in analyzing research data
-	// Uses LLM to identify patterns, correlations, and generate actionable insights
-	analyzer := agents.NewAnalysisAgent("analyzer-1", s.GetEventBus())
-
-	// ReportAgent: Specializes in creating professional reports
-	// Uses LLM to synthesize research and analysis into executive-friendly reports
-	reporter := agents.NewReportAgent("reporter-1", s.GetEventBus())
+	// Create the agents declared in the config
+	// Each agent has a unique ID, its own LLM client, and the swarm's event bus
+	// for publishing events
+	swarmAgents, err := cfg.NewAgents(s.GetEventBus())
+	if err != nil {
+		log.Fatalf("Failed to create agents: %v", err)
+	}
 
 	// Add all agents to the swarm
 	// The swarm maintains a registry of agents and routes tasks to them
-	if err := s.AddAgent(researcher); err != nil {
-		log.Fatalf("Failed to add researcher: %v", err)
-	}
-	if err := s.AddAgent(analyzer); err != nil {
-		log.Fatalf("Failed to add analyzer: %v", err)
-	}
-	if err := s.AddAgent(reporter); err != nil {
-		log.Fatalf("Failed to add reporter: %v", err)
+	for _, a := range swarmAgents {
+		if err := s.AddAgent(a); err != nil {
+			log.Fatalf("Failed to add %s: %v", a.GetID(), err)
+		}
 	}
 
 	// Create a context for coordinating graceful shutdown
@@ -151,19 +339,84 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Hand the transport's remote specialties to worker processes, and feed
+	// their task events back into the swarm
+	if cfg.Transport.Enabled() {
+		conn, err := cfg.Transport.Connect()
+		if err != nil {
+			log.Fatalf("Failed to connect transport: %v", err)
+		}
+		defer conn.Close()
+		for _, a := range cfg.Transport.RemoteAgents(conn) {
+			if err := s.AddAgent(a); err != nil {
+				log.Fatalf("Failed to add %s: %v", a.GetID(), err)
+			}
+		}
+		go transport.Relay(ctx, conn, s.GetEventBus())
+		fmt.Fprintf(status, "🛰️  Remote workers serve: %s\n", strings.Join(cfg.Transport.Remote, ", "))
+	}
+
+	// Keep every completed workflow in ./runs for the CLI's Past Runs and /api/runs
+	runArchive, err := archive.Open(archive.DefaultDir)
+	if err != nil {
+		log.Fatalf("Failed to open run archive: %v", err)
+	}
+	go runArchive.Run(ctx, s.GetEventBus())
+
+	// Keep each agent's totals in ./agent-stats.json across restarts, for
+	// Agent Details and /api/agents/{id}/stats
+	agentStats, err := agentstats.Open(agentstats.DefaultPath)
+	if err != nil {
+		log.Fatalf("Failed to open agent statistics: %v", err)
+	}
+	s.GetEventBus().Observe(agentStats.Record)
+	go agentStats.Run(ctx)
+
+	// Post workflow completions, failures, and LLM spend to Slack/Discord
+	// when a webhook is configured (swarm.yaml or SLACK_WEBHOOK_URL / DISCORD_WEBHOOK_URL).
+	// With leader election only the leader posts the cost summary.
+	notifyCfg := cfg.Notifications.NotifyConfig()
+	notifyCfg.IsLeader = s.IsLeader
+	if notifier := notify.New(notifyCfg); notifier.Enabled() {
+		go notifier.Run(ctx, s.GetEventBus())
+		fmt.Fprintln(status, "🔔 Chat notifications enabled")
+	}
+
+	// Builds agents by ID and type, for a checkpoint's agents and those
+	// admins add from the dashboard API
+	build := func(id, specialty string) (types.Agent, error) {
+		return agents.New(specialty, id, s.GetEventBus(), llmClient)
+	}
+
+	// Bring back the agents, unfinished tasks, and workflow progress of the
+	// last run, if it left a checkpoint
+	if *checkpointPath != "" {
+		snap, err := swarm.LoadSnapshot(*checkpointPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			log.Fatalf("Failed to load checkpoint: %v", err)
+		default:
+			if err := s.Restore(snap, build); err != nil {
+				slog.Warn("checkpoint partly restored", logging.KeyError, err)
+			}
+			fmt.Fprintf(status, "♻️  Restored from %s: %d task(s), %d workflow(s)\n", *checkpointPath, len(snap.Tasks), len(snap.Workflows))
+		}
+	}
+
 	// Set up signal handler for graceful shutdown on Ctrl+C or SIGTERM
 	// This ensures all agents finish their current tasks before exiting
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan // Block until we receive an interrupt signal
-		fmt.Println("\n\nReceived interrupt signal. Shutting down...")
+		fmt.Fprintln(status, "\n\nReceived interrupt signal. Shutting down...")
 		cancel() // Trigger cancellation to stop all components
 	}()
 
 	// Start all agents in background goroutines
 	// Each agent runs its own message processing loop
-	fmt.Println("Initializing agent swarm...")
+	fmt.Fprintln(status, "Initializing agent swarm...")
 	if err := s.Start(ctx); err != nil {
 		log.Fatalf("Failed to start swarm: %v", err)
 	}
@@ -171,22 +424,83 @@ func main() {
 	// Give agents a moment to fully initialize their goroutines
 	time.Sleep(500 * time.Millisecond)
 
+	// Resume restored workflows and keep the checkpoint up to date
+	if *checkpointPath != "" {
+		for _, progress := range s.Workflows() {
+			go func(progress swarm.WorkflowProgress) {
+				if _, err := workflows.NewEngine(s).Resume(progress); err != nil {
+					slog.Error("resumed workflow failed", "workflow", progress.ID, logging.KeyError, err)
+				}
+			}(progress)
+		}
+		go s.RunCheckpoints(ctx, *checkpointPath, checkpointInterval)
+	}
+
+	if batchFile != "" {
+		failed := runBatch(ctx, s, batchFile, batchTasks)
+		saveCheckpoint(s, *checkpointPath)
+		if err := s.Stop(); err != nil {
+			slog.Error("error stopping swarm", logging.KeyError, err)
+		}
+		saveAgentStats(agentStats)
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if research != nil {
+		failed := runResearch(ctx, s, research)
+		saveCheckpoint(s, *checkpointPath)
+		if err := s.Stop(); err != nil {
+			slog.Error("error stopping swarm", logging.KeyError, err)
+		}
+		saveAgentStats(agentStats)
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Start web dashboard server in background goroutine
-	// The web server provides real-time monitoring at http://localhost:8080
+	// The web server provides real-time monitoring at http://localhost:<web.port>
 	// Features:
 	//   - Live agent status cards
 	//   - Real-time event stream via WebSocket
 	//   - Task statistics and completion tracking
 	//   - Full task results display
+	listen := cfg.Web.ListenConfig()
+	if *webAddr != "" {
+		listen.Address = *webAddr
+	}
+	if *webPort != 0 {
+		listen.Port = *webPort
+	}
 	webServer := web.NewServer(s)
+	webServer.SetArchive(runArchive)
+	webServer.SetAgentStats(agentStats)
+	webServer.SetAgentBuilder(build)
+	webAuth, err := cfg.Web.Auth()
+	if err != nil {
+		log.Fatalf("Failed to configure dashboard access: %v", err)
+	}
+	webServer.SetAuth(webAuth)
+	webServer.SetEventTextLimit(cfg.Web.EventTextLimit)
+	namespaces := startNamespaces(ctx, cfg, webServer, llmClient)
 	go func() {
-		if err := webServer.Start(8080); err != nil {
-			log.Printf("Web server error: %v", err)
+		if err := webServer.Serve(listen); err != nil {
+			slog.Error("web server stopped", logging.KeyError, err)
 		}
 	}()
 
+	// Apply edits to the config file as it is saved: agents are started,
+	// stopped, or replaced, and rate limits and retries change in place
+	if path := config.ResolvePath(*configPath); path != "" {
+		go config.NewReloader(path, cfg, s).Run(ctx)
+	}
+
 	// Display web dashboard URL to user
-	fmt.Println("\n🌐 Web Dashboard: http://localhost:8080")
+	fmt.Printf("\n🌐 Web Dashboard: %s\n", listen.URL())
 	fmt.Println("📊 Open the URL above in your browser for real-time monitoring!")
 	time.Sleep(1 * time.Second)
 
@@ -197,6 +511,8 @@ func main() {
 	//   - Viewing agent status
 	//   - Broadcasting messages
 	session := interactive.NewSession(s)
+	session.SetArchive(runArchive)
+	session.SetAgentStats(agentStats)
 
 	// Run interactive session in a goroutine so we can monitor for shutdown
 	done := make(chan bool)
@@ -216,14 +532,332 @@ func main() {
 		// Interrupt signal received (Ctrl+C)
 	}
 
+	// Release the dashboard port first, letting in-flight API requests finish
+	// while the swarm is still running
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), webShutdownTimeout)
+	if err := webServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("error stopping web server", logging.KeyError, err)
+	}
+	cancelShutdown()
+
 	// Gracefully stop all agents
 	// This allows agents to finish their current tasks before exiting
 	fmt.Println("\nStopping all agents...")
+	saveCheckpoint(s, *checkpointPath)
 	if err := s.Stop(); err != nil {
-		log.Printf("Error stopping swarm: %v", err)
+		slog.Error("error stopping swarm", logging.KeyError, err)
 	}
+	for name, ns := range namespaces {
+		if err := ns.Stop(); err != nil {
+			slog.Error("error stopping namespace", "namespace", name, logging.KeyError, err)
+		}
+	}
+	saveAgentStats(agentStats)
 
 	// Display goodbye message
 	fmt.Println("\n=== Agent Swarm Demo Complete ===")
 	fmt.Println("Thank you for using Agent Swarm!")
-}
\ No newline at end of file
+}
+// checkpointInterval is how often -checkpoint saves the swarm while it runs
+const checkpointInterval = 30 * time.Second
+
+// webShutdownTimeout is how long in-flight dashboard requests get to finish
+// on shutdown
+const webShutdownTimeout = 5 * time.Second
+
+// startNamespaces creates and starts a swarm for each namespace in the config
+// and serves its dashboard under /ns/{name}/ of webServer
+func startNamespaces(ctx context.Context, cfg *config.Config, webServer *web.Server, llmClient *llm.Client) map[string]*swarm.Swarm {
+	started := make(map[string]*swarm.Swarm)
+	for _, name := range cfg.NamespaceNames() {
+		ns := swarm.NewSwarm(cfg.NamespaceOptions(name)...)
+		nsAgents, err := cfg.NewNamespaceAgents(name, ns.GetEventBus())
+		if err != nil {
+			log.Fatalf("Failed to create agents of namespace %s: %v", name, err)
+		}
+		for _, a := range nsAgents {
+			if err := ns.AddAgent(a); err != nil {
+				log.Fatalf("Failed to add %s to namespace %s: %v", a.GetID(), name, err)
+			}
+		}
+		if err := ns.Start(ctx); err != nil {
+			log.Fatalf("Failed to start namespace %s: %v", name, err)
+		}
+
+		nsServer, err := webServer.AddNamespace(name, ns)
+		if err != nil {
+			log.Fatalf("Failed to serve namespace %s: %v", name, err)
+		}
+		nsServer.SetAgentBuilder(func(id, specialty string) (types.Agent, error) {
+			return agents.New(specialty, id, ns.GetEventBus(), llmClient)
+		})
+		started[name] = ns
+		fmt.Printf("🗂️  Namespace %s: %d agent(s), dashboard at /ns/%s/\n", name, len(nsAgents), name)
+	}
+	return started
+}
+
+// saveCheckpoint saves the swarm to path (if set) before it is stopped, while
+// the tasks still running are unfinished rather than cancelled
+func saveCheckpoint(s *swarm.Swarm, path string) {
+	if path == "" {
+		return
+	}
+	if err := s.SaveSnapshot(path); err != nil {
+		slog.Error("saving checkpoint failed", "path", path, logging.KeyError, err)
+		return
+	}
+	fmt.Printf("💾 Checkpoint saved to %s\n", path)
+}
+
+// saveAgentStats saves the agents' statistics once the swarm has stopped, so
+// the last results it reported are kept
+func saveAgentStats(st *agentstats.Store) {
+	if err := st.Save(); err != nil {
+		slog.Error("saving agent statistics failed", "path", agentstats.DefaultPath, logging.KeyError, err)
+	}
+}
+
+// writePrompts writes the built-in system prompts to dir (default
+// prompts.DefaultDir), where they override the built-ins once edited
+func writePrompts(dir string) {
+	if dir == "" {
+		dir = prompts.DefaultDir
+	}
+	written, err := agents.Prompts.WriteDefaults(dir)
+	if err != nil {
+		log.Fatalf("Failed to write prompts: %v", err)
+	}
+	for _, path := range written {
+		fmt.Println("wrote", path)
+	}
+	fmt.Printf("%d of %d prompts written to %s; existing files were kept\n", len(written), len(agents.Prompts.Names()), dir)
+}
+
+// runBench runs a benchmark with the settings in args and writes its JSON
+// report to stdout, or to -out
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	agentCount := fs.Int("agents", bench.DefaultAgents, "synthetic worker agents")
+	taskCount := fs.Int("tasks", bench.DefaultTasks, "tasks to push through the swarm")
+	workTime := fs.Duration("work", bench.DefaultWorkTime, "simulated work per task")
+	workers := fs.Int("workers", 1, "tasks each agent handles at once")
+	queueSize := fs.Int("queue", 0, "submission queue capacity (default the swarm's, 1000)")
+	strategy := fs.String("strategy", "", "load-balancing strategy (default least_loaded)")
+	out := fs.String("out", "", "write the report to this file instead of stdout")
+	fs.Parse(args)
+
+	// Logs go to stderr, so they don't mix with the report on stdout
+	logging.Setup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := bench.Run(ctx, bench.Config{
+		Agents:    *agentCount,
+		Tasks:     *taskCount,
+		WorkTime:  *workTime,
+		Workers:   *workers,
+		QueueSize: *queueSize,
+		Strategy:  swarm.Strategy(*strategy),
+	})
+	if err != nil {
+		log.Fatalf("Benchmark failed: %v", err)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create report: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := report.Write(w); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
+
+// runWorker runs the config's agents as a worker: it takes their tasks from
+// the transport, runs them, and sends the results back to the coordinator
+// until interrupted
+func runWorker(cfg *config.Config) {
+	if !cfg.Transport.Enabled() {
+		log.Fatalf("The worker command needs a transport section in the config")
+	}
+	conn, err := cfg.Transport.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect transport: %v", err)
+	}
+	defer conn.Close()
+
+	// Events stay local; the worker reports task outcomes to the coordinator
+	// itself and forwards only progress
+	eventBus := types.NewEventBus()
+	workerAgents, err := cfg.NewAgents(eventBus)
+	if err != nil {
+		log.Fatalf("Failed to create agents: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	worker := transport.NewWorker(conn, workerAgents...)
+	go worker.ForwardProgress(ctx, eventBus)
+
+	fmt.Printf("🛠️  Worker running %d agents; Ctrl+C to stop\n", len(workerAgents))
+	if err := worker.Run(ctx); err != nil {
+		log.Fatalf("Worker failed: %v", err)
+	}
+	fmt.Println("Worker stopped")
+}
+
+// runBatch runs the tasks of a task file through the swarm, printing a line as
+// each finishes, and writes the summary next to the file. It returns the
+// number of tasks that failed.
+func runBatch(ctx context.Context, s *swarm.Swarm, path string, tasks []types.Task) int {
+	fmt.Printf("\n📦 Running %d tasks from %s\n\n", len(tasks), path)
+
+	summary := batch.Run(ctx, s, tasks, func(done, total int, o batch.Outcome) {
+		if o.Success {
+			fmt.Printf("[%d/%d] ✅ %s (%s, %v)\n", done, total, o.TaskID, o.AgentID, o.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Printf("[%d/%d] ❌ %s: %s\n", done, total, o.TaskID, o.Error)
+		}
+	})
+
+	fmt.Printf("\n%d succeeded, %d failed in %v (run %s)\n", summary.Succeeded, summary.Failed, summary.Duration.Round(time.Second), summary.RunID)
+	summaryPath := batch.SummaryPath(path)
+	if err := summary.Write(summaryPath); err != nil {
+		slog.Error("failed to write batch summary", logging.KeyError, err)
+	} else {
+		fmt.Printf("📝 Summary written to %s\n", summaryPath)
+	}
+	return summary.Failed
+}
+
+// researchRequest is what the research command was asked to do
+type researchRequest struct {
+	topics []string
+	json   bool   // Print results as JSON lines
+	locale string // Language the agents write in, e.g. "ja"
+}
+
+// parseResearch reads the research command's flags and topics: the
+// arguments, joined into one topic, or else each non-empty line of stdin, so
+// `echo "topic" | agent-swarm research -json` works in a pipeline
+func parseResearch(args []string) *researchRequest {
+	usage := fmt.Sprintf("Usage: %s [-config PATH] research [-json] [-locale TAG] [TOPIC] (or topics on stdin, one per line)", os.Args[0])
+	fs := flag.NewFlagSet("research", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "print each result as one line of JSON")
+	locale := fs.String("locale", "", "language the agents write in, e.g. ja or pt-BR")
+	fs.Parse(args)
+	if err := types.ValidateLocale(*locale); err != nil {
+		log.Fatal(err)
+	}
+
+	req := &researchRequest{json: *jsonOut, locale: *locale}
+	if fs.NArg() > 0 {
+		req.topics = []string{strings.Join(fs.Args(), " ")}
+		return req
+	}
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		log.Fatal(usage)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if topic := strings.TrimSpace(scanner.Text()); topic != "" {
+			req.topics = append(req.topics, topic)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("Failed to read topics: %v", err)
+	}
+	if len(req.topics) == 0 {
+		log.Fatal(usage)
+	}
+	return req
+}
+
+// researchOutput is one line of the research command's JSON output: the
+// workflow result, or the topic and why it failed
+type researchOutput struct {
+	*workflows.WorkflowResult
+	Error string `json:"error,omitempty"`
+}
+
+// runResearch runs the research workflow on each topic in turn, as one run,
+// and prints the results to stdout: one JSON object per line with -json,
+// else as the interactive CLI shows them. Progress goes to stderr. It
+// returns the number of topics whose workflow failed.
+func runResearch(ctx context.Context, s *swarm.Swarm, req *researchRequest) int {
+	runID := types.NewRunID()
+	encoder := json.NewEncoder(os.Stdout)
+	failed := 0
+	for _, topic := range req.topics {
+		if ctx.Err() != nil {
+			break
+		}
+		rw := workflows.NewResearchWorkflow(s)
+		rw.SetRunID(runID)
+		rw.SetOutput(os.Stderr)
+		rw.SetLocale(req.locale)
+		result, err := rw.Execute(topic)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", topic, err)
+		}
+
+		if req.json {
+			out := researchOutput{WorkflowResult: result}
+			if err != nil {
+				out.WorkflowResult = &workflows.WorkflowResult{Topic: topic, RunID: runID}
+				out.Error = err.Error()
+			}
+			if err := encoder.Encode(out); err != nil {
+				log.Fatalf("Failed to write result: %v", err)
+			}
+		} else if err == nil {
+			result.Display()
+		}
+	}
+	return failed
+}
+
+// runExperiment runs an experiment's tasks against both of its variants,
+// printing a line as each task finishes, and writes the comparison report
+// next to the file. It returns the number of tasks that failed across both
+// variants.
+func runExperiment(cfg *config.Config, path string, exp *experiment.Experiment) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("\n🧪 Experiment %q: %d tasks, %s vs %s\n\n", exp.Name, len(exp.Tasks), exp.Variants[0].Label(), exp.Variants[1].Label())
+
+	report, err := experiment.Run(ctx, cfg, exp, func(variant string, done, total int, o batch.Outcome) {
+		if o.Success {
+			fmt.Printf("[%s %d/%d] ✅ %s (%s, %v)\n", variant, done, total, o.TaskID, o.AgentID, o.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Printf("[%s %d/%d] ❌ %s: %s\n", variant, done, total, o.TaskID, o.Error)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Experiment failed: %v", err)
+	}
+
+	failed := 0
+	fmt.Println()
+	for _, v := range report.Variants {
+		failed += v.Summary.Failed
+		fmt.Printf("%s: %d succeeded, %d failed, mean latency %v, $%.4f\n", v.Variant.Name,
+			v.Summary.Succeeded, v.Summary.Failed, v.MeanLatency().Round(time.Millisecond), v.Usage.CostUSD)
+	}
+	reportPath := experiment.ReportPath(path)
+	if err := report.Write(reportPath); err != nil {
+		slog.Error("failed to write experiment report", logging.KeyError, err)
+	} else {
+		fmt.Printf("📝 Report written to %s\n", reportPath)
+	}
+	return failed
+}
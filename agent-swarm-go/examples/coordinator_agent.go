@@ -2,9 +2,9 @@ package examples
 
 import (
 	"fmt"
-	"time"
 
 	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/logging"
 	"agent-swarm-go/pkg/types"
 )
 
@@ -39,11 +39,10 @@ func (ca *CoordinatorAgent) handleTask(msg types.Message) error {
 		return fmt.Errorf("invalid task format")
 	}
 
-	fmt.Printf("[%s] Coordinator %s received task: %s (priority: %d)\n",
-		time.Now().Format("15:04:05"),
-		ca.GetID(),
-		task.Description,
-		task.Priority)
+	ca.Logger().Info("coordinator received task",
+		logging.KeyTask, task.ID,
+		"description", task.Description,
+		"priority", task.Priority)
 
 	ca.taskQueue = append(ca.taskQueue, task)
 	return ca.delegateNextTask()
@@ -56,12 +55,10 @@ func (ca *CoordinatorAgent) handleResult(msg types.Message) error {
 		return fmt.Errorf("invalid result format")
 	}
 
-	fmt.Printf("[%s] Coordinator %s received result from %s: task %s, success: %v\n",
-		time.Now().Format("15:04:05"),
-		ca.GetID(),
-		msg.From,
-		result.TaskID,
-		result.Success)
+	ca.Logger().Info("coordinator received result",
+		"from", msg.From,
+		logging.KeyTask, result.TaskID,
+		"success", result.Success)
 
 	return nil
 }
@@ -78,11 +75,9 @@ func (ca *CoordinatorAgent) delegateNextTask() error {
 	workerID := ca.workerIDs[ca.nextWorker]
 	ca.nextWorker = (ca.nextWorker + 1) % len(ca.workerIDs)
 
-	fmt.Printf("[%s] Coordinator %s delegating task %s to worker %s\n",
-		time.Now().Format("15:04:05"),
-		ca.GetID(),
-		task.ID,
-		workerID)
+	ca.Logger().Info("coordinator delegating task",
+		logging.KeyTask, task.ID,
+		"worker", workerID)
 
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,50 @@
+#!/usr/bin/env python3
+"""Example external agent for agent-swarm-go.
+
+Reads one JSON task per line on stdin and writes one JSON result per line on
+stdout (see agents.ExternalAgent). Run it from swarm.yaml:
+
+    agents:
+      - type: external
+        id: echo-1
+        specialty: echo
+        command: [python3, examples/external/echo_agent.py]
+
+then send it tasks with Payload {"agent_type": "echo"}.
+"""
+
+import json
+import sys
+
+
+def send(message):
+    # One line per message, flushed so the swarm sees it at once
+    print(json.dumps(message), flush=True)
+
+
+def handle(task):
+    description = task.get("description", "")
+    if not description.strip():
+        raise ValueError("task has no description")
+
+    send({"type": "log", "message": f"echoing {len(description)} characters"})
+    send({"type": "progress", "percent": 50, "phase": "echoing context"})
+    lines = [f"Echo: {description}"]
+    for key, value in sorted((task.get("context") or {}).items()):
+        lines.append(f"- {key}: {str(value)[:80]}")
+    return "\n".join(lines)
+
+
+def main():
+    for line in sys.stdin:
+        if not line.strip():
+            continue
+        task = json.loads(line)
+        try:
+            send({"id": task["id"], "success": True, "data": handle(task)})
+        except Exception as err:  # Report any failure as the task's error
+            send({"id": task["id"], "success": False, "error": str(err)})
+
+
+if __name__ == "__main__":
+    main()
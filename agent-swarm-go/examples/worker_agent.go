@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/logging"
 	"agent-swarm-go/pkg/types"
 )
 
@@ -54,11 +55,10 @@ func (wa *WorkerAgent) handleTask(msg types.Message) error {
 		})
 	}
 
-	fmt.Printf("[%s] 📥 Worker %s (%s) received task: %s\n",
-		time.Now().Format("15:04:05"),
-		wa.GetID(),
-		wa.specialty,
-		task.Description)
+	wa.Logger().Info("task received",
+		logging.KeyTask, task.ID,
+		"specialty", wa.specialty,
+		"description", task.Description)
 
 	// Publish task started event
 	if wa.eventBus != nil {
@@ -89,16 +89,10 @@ func (wa *WorkerAgent) handleTask(msg types.Message) error {
 		})
 	}
 
-	statusIcon := "✅"
-	if !result.Success {
-		statusIcon = "❌"
-	}
-	fmt.Printf("[%s] %s Worker %s (%s) completed task: %s\n",
-		time.Now().Format("15:04:05"),
-		statusIcon,
-		wa.GetID(),
-		wa.specialty,
-		task.ID)
+	wa.Logger().Info("task completed",
+		logging.KeyTask, task.ID,
+		"specialty", wa.specialty,
+		"success", result.Success)
 
 	return nil
 }
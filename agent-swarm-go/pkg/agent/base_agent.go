@@ -2,7 +2,7 @@
 Package agent provides the base agent implementation that all specialized agents inherit from.
 
 BaseAgent is the foundation for all agents in the swarm system. It provides:
-  - Message queue and processing loop
+  - Prioritized message queue (urgent/high/normal/low lanes) and processing loop
   - Thread-safe state management
   - Customizable message handlers
   - Lifecycle management (start/stop)
@@ -26,8 +26,12 @@ package agent
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"agent-swarm-go/pkg/logging"
 	"agent-swarm-go/pkg/types"
 )
 
@@ -49,12 +53,16 @@ import (
 //
 // Concurrency Model:
 //   - Each agent runs in its own goroutine (via run() loop)
-//   - Messages are sent to a buffered channel (inbox)
-//   - The agent's goroutine processes messages sequentially
+//   - Messages are sent to one of four buffered channels (inbox lanes)
+//   - The agent's goroutine takes messages one at a time, always
+//     draining the urgent lane first, then high, normal, and low
+//   - By default each message is handled before the next is taken; with
+//     WithWorkers(n), up to n are handled at once, though messages in the
+//     same conversation still run in order (see workers.go)
 //   - Thread-safety is ensured with sync.RWMutex
 //
 // Message Flow:
-//   External → SendMessage() → inbox channel → run() loop → handleMessage() → registered handler
+//   External → SendMessage() → inbox lane → run() loop → handleMessage() → registered handler
 //
 // Example Usage:
 //   // Create a basic agent
@@ -74,14 +82,70 @@ import (
 type BaseAgent struct {
 	id       string                               // Unique identifier (e.g., "researcher-1", "worker-2")
 	state    types.AgentState                     // Current state: idle, processing, or stopped
-	inbox    chan types.Message                   // Message queue (buffered to 100 messages)
+	inbox    [laneCount]chan types.Message        // Message lanes: urgent, high, normal, low (each buffered to inboxSize messages)
 	mu       sync.RWMutex                         // Mutex for thread-safe access to state and handlers
 	ctx      context.Context                      // Context for cancellation and lifecycle
 	cancel   context.CancelFunc                   // Function to cancel the context and stop the agent
 	wg       sync.WaitGroup                       // WaitGroup to ensure graceful shutdown
 	handlers map[types.MessageType]MessageHandler // Custom handlers for different message types
+
+	// Pause/cancel control (see Pause, Resume, CancelTask)
+	paused   bool                   // When set, run() holds messages until Resume
+	resumeCh chan struct{}          // Closed by Resume to wake a paused run() loop
+	stopping bool                   // Set once Stop has begun shutting the agent down
+	tasks    map[string]*activeTask // Tasks being handled, by ID
+	lastTask string                 // The most recently started of them (see TaskContext)
+	dropped  map[string]time.Time   // Tasks cancelled before they were dequeued, and when
+
+	threads types.ThreadRouter // Carries questions to other agents (see Ask); set by the swarm
+	memory  types.MemoryStore  // Each run's shared memory (see Memory); set by the swarm
+
+	// Inbox capacity and what to do when a lane is full (see overflow.go)
+	inboxSize int
+	overflow  OverflowPolicy
+	drops     atomic.Int64   // Messages discarded by a drop policy
+	senders   sync.WaitGroup // SendMessage calls blocked by OverflowBlock
+	stopped   chan struct{}  // Closed by Stop to release blocked senders
+
+	middleware []TaskMiddleware // Wraps task processing, outermost first (see hooks.go)
+
+	// Messages handled at once (see workers.go)
+	workers int
+	slots   chan struct{}              // Holds a token per busy worker
+	convMu  sync.Mutex                 // Guards convs, apart from mu so workers never need mu to go on
+	convs   map[string][]types.Message // Messages waiting behind a busy conversation, by key
+
+	capabilities []string // Tags tasks can require (see capabilities.go)
+
+	preemptions atomic.Int64 // Urgent messages taken ahead of waiting ones
+}
+
+// activeTask is a task being handled and the context it runs under
+type activeTask struct {
+	ctx    context.Context    // Cancelled by CancelTask or Stop
+	cancel context.CancelFunc // Cancels ctx
 }
 
+// taskIDKey is the context key under which a task's context carries its ID
+type taskIDKey struct{}
+
+// Inbox lane indexes. Lower index means higher priority.
+const (
+	laneUrgent = iota
+	laneHigh
+	laneNormal
+	laneLow
+	laneCount
+)
+
+// dropExpiry is how long a task cancelled before it was dequeued is watched
+// for (see CancelTask); a later task reusing the ID runs
+const dropExpiry = 10 * time.Minute
+
+// defaultInboxSize is the buffer capacity of each inbox lane unless
+// WithInboxSize says otherwise
+const defaultInboxSize = 100
+
 // MessageHandler is a function signature for handling specific message types.
 //
 // When you register a handler for a message type (e.g., MessageTypeTask),
@@ -101,40 +165,61 @@ type BaseAgent struct {
 //   }
 //
 // Thread Safety:
-//   Handlers are called from the agent's goroutine, so they execute sequentially.
-//   You don't need locks within the handler for the same agent.
+//   With the default single worker, handlers execute sequentially, so you
+//   don't need locks within the handler for the same agent. With
+//   WithWorkers(n), up to n run at once and must guard any shared state.
 type MessageHandler func(msg types.Message) error
 
 // NewBaseAgent creates and initializes a new base agent.
 //
 // The agent is created in the "idle" state with:
-//   - Empty message queue (buffered channel, capacity 100)
+//   - Empty urgent, high, normal, and low priority lanes (buffered channels, capacity 100 each)
 //   - No registered handlers (empty map)
 //   - Ready to be started
 //
-// Buffer Size (100):
-//   The inbox can hold up to 100 messages before SendMessage() blocks.
+// Buffer Size (100 per lane, see WithInboxSize):
+//   Each lane can hold up to 100 messages before SendMessage() rejects more.
 //   This prevents overwhelming the agent with too many tasks at once.
-//   If the buffer fills up, senders will get an error.
+//   Because lanes are independent, a full low lane of bulk tasks never
+//   blocks control messages on the high lane or urgent tasks.
+//   WithOverflowPolicy chooses to block or drop instead of rejecting.
 //
 // Parameters:
 //   - id: Unique identifier for this agent (should be unique in the swarm)
+//   - opts: Inbox size, overflow policy, and workers; none keeps the defaults
 //
 // Returns:
 //   - *BaseAgent: Fully initialized agent ready to be started
 //
 // Example:
 //   agent := NewBaseAgent("worker-1")
+//   // or: NewBaseAgent("worker-1", WithInboxSize(500), WithOverflowPolicy(OverflowBlock))
 //   // agent.state == StateIdle
-//   // agent.inbox is ready to receive messages
+//   // agent.inbox lanes are ready to receive messages
 //   // agent.handlers is empty (default behavior)
-func NewBaseAgent(id string) *BaseAgent {
-	return &BaseAgent{
-		id:       id,                                           // Store unique identifier
-		state:    types.StateIdle,                              // Start in idle state
-		inbox:    make(chan types.Message, 100),                // Buffered channel for messages
-		handlers: make(map[types.MessageType]MessageHandler),   // Empty handler map
+func NewBaseAgent(id string, opts ...Option) *BaseAgent {
+	a := &BaseAgent{
+		id:        id,                                         // Store unique identifier
+		state:     types.StateIdle,                            // Start in idle state
+		handlers:  make(map[types.MessageType]MessageHandler), // Empty handler map
+		tasks:     make(map[string]*activeTask),               // No tasks running yet
+		dropped:   make(map[string]time.Time),                 // No cancelled tasks yet
+		convs:     make(map[string][]types.Message),           // No conversations waiting
+		inboxSize: defaultInboxSize,
+		workers:   1,
+		overflow:  OverflowError,
+		stopped:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+
+	// One buffered channel per priority lane
+	for i := range a.inbox {
+		a.inbox[i] = make(chan types.Message, a.inboxSize)
+	}
+
+	return a
 }
 
 // GetID returns the unique identifier of this agent.
@@ -153,6 +238,20 @@ func (a *BaseAgent) GetID() string {
 	return a.id
 }
 
+// Logger returns the default structured logger with this agent's ID attached.
+//
+// Every log line produced through it carries an "agent" field, so output from
+// many concurrent agents can be filtered per agent (e.g. with jq when
+// LOG_FORMAT=json). The logger is derived from slog.Default() on each call so
+// it always reflects the configuration installed by logging.Setup().
+//
+// Example:
+//   a.Logger().Info("task received", logging.KeyTask, task.ID)
+//   // time=... level=INFO msg="task received" agent=researcher-1 task=task-001
+func (a *BaseAgent) Logger() *slog.Logger {
+	return slog.Default().With(logging.KeyAgent, a.id)
+}
+
 // Start begins the agent's execution loop in a background goroutine.
 //
 // What happens when you call Start():
@@ -210,6 +309,9 @@ func (a *BaseAgent) Start(ctx context.Context) error {
 	// even if they're currently idle waiting for messages
 	a.state = types.StateProcessing
 
+	// One token per worker that may handle a message at once
+	a.slots = make(chan struct{}, a.workers)
+
 	a.mu.Unlock() // Release write lock
 
 	// Add to WaitGroup for graceful shutdown tracking
@@ -226,9 +328,9 @@ func (a *BaseAgent) Start(ctx context.Context) error {
 // Shutdown sequence:
 //   1. Checks if agent is already stopped (idempotent)
 //   2. Cancels the agent's context (signals run() to exit)
-//   3. Waits for run() goroutine to finish (wg.Wait)
+//   3. Waits for run() and its workers to finish (wg.Wait), without the lock
 //   4. Sets state to "stopped"
-//   5. Closes the inbox channel (no more messages accepted)
+//   5. Closes the inbox lanes (no more messages accepted)
 //
 // Graceful Shutdown:
 //   - Agent finishes processing current message before exiting
@@ -258,45 +360,68 @@ func (a *BaseAgent) Start(ctx context.Context) error {
 // Thread Safety: Uses mutex to protect state and ensures thread-safe shutdown
 func (a *BaseAgent) Stop() error {
 	a.mu.Lock()
-	defer a.mu.Unlock()
 
-	// Already stopped? Return early (idempotent)
-	if a.state == types.StateStopped {
+	// Already stopped, or stopping in another call? Return early (idempotent)
+	if a.state == types.StateStopped || a.stopping {
+		a.mu.Unlock()
 		return nil
 	}
+	a.stopping = true
+	cancel := a.cancel
+	a.mu.Unlock()
 
 	// Cancel the context to signal run() to exit
-	if a.cancel != nil {
-		a.cancel()
+	if cancel != nil {
+		cancel()
 	}
 
-	// Wait for run() goroutine to finish
+	// Wait for run() and the workers to finish, without the lock: a task
+	// being handled takes it on its way out (see endTask)
 	// This ensures we don't close the inbox while run() is still using it
 	a.wg.Wait()
 
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	// Update state to stopped
 	a.state = types.StateStopped
 
-	// Close inbox channel
+	// Release senders blocked on a full lane before closing it under them
+	close(a.stopped)
+	a.senders.Wait()
+
+	// Close all inbox lanes
 	// This will cause any pending SendMessage() calls to fail
-	close(a.inbox)
+	for _, lane := range a.inbox {
+		close(lane)
+	}
 
 	return nil
 }
 
-// SendMessage sends a message to this agent's inbox.
+// SendMessage sends a message to one of this agent's inbox lanes.
 //
 // Message Delivery:
-//   - Non-blocking: Returns immediately with error if inbox is full
-//   - Buffered: Can queue up to 100 messages
+//   - Non-blocking: Returns immediately with error if the lane is full,
+//     unless the agent was built with another OverflowPolicy
+//   - Buffered: Each lane can queue up to 100 messages (see WithInboxSize)
 //   - Thread-safe: Safe to call from multiple goroutines
 //
+// Lane Selection (see laneFor):
+//   - msg.Priority set explicitly: PriorityUrgent, PriorityHigh, PriorityNormal
+//     or PriorityLow lane
+//   - PriorityDefault task messages: normal lane
+//   - PriorityDefault control messages (broadcast, query, result): high lane
+//
 // Error Conditions:
 //   - Agent is stopped: Returns error
-//   - Inbox is full (100 messages waiting): Returns error
+//   - Selected lane is full (100 messages waiting): Returns error under
+//     OverflowError; blocks, or drops a message, under the other policies
 //
 // Message Processing:
-//   Messages are processed in FIFO order by the agent's run() loop.
+//   Messages are processed in FIFO order within a lane, and the run() loop
+//   always prefers the urgent lane over high, high over normal, and normal
+//   over low.
 //   Each message is handled sequentially (one at a time).
 //
 // Parameters:
@@ -322,30 +447,82 @@ func (a *BaseAgent) Stop() error {
 //       log.Printf("Failed to send message: %v", err)
 //   }
 //
-// Thread Safety: Uses read lock to check state, select ensures channel safety
+// Thread Safety: Uses read lock to check state, select ensures channel safety.
+// A sender blocked by OverflowBlock waits without the lock, so Stop can
+// still run; Stop releases it before closing the lanes.
 func (a *BaseAgent) SendMessage(msg types.Message) error {
 	a.mu.RLock()
-	defer a.mu.RUnlock()
 
 	// Check if agent is stopped
 	if a.state == types.StateStopped {
+		a.mu.RUnlock()
 		return fmt.Errorf("agent %s is stopped", a.id)
 	}
 
-	// Try to send message to inbox
+	// Try to send message to the selected lane
+	lane := laneFor(msg)
 	select {
-	case a.inbox <- msg:
+	case a.inbox[lane] <- msg:
 		// Successfully queued message
+		a.mu.RUnlock()
 		return nil
 	default:
-		// Inbox is full (100 messages already waiting)
-		return fmt.Errorf("agent %s inbox is full", a.id)
+		// Lane is full: the overflow policy decides
+	}
+
+	if a.overflow == OverflowBlock {
+		a.senders.Add(1)
+		a.mu.RUnlock()
+		defer a.senders.Done()
+		return a.sendBlocking(lane, msg)
+	}
+
+	defer a.mu.RUnlock()
+	return a.overflowFull(lane, msg)
+}
+
+// laneFor picks the inbox lane for a message.
+//
+// An explicit msg.Priority always wins. Otherwise task messages are queued on
+// the normal lane and everything else (broadcasts, queries, results) is treated
+// as a control message and queued on the high lane, so it is never stuck behind
+// a backlog of tasks.
+func laneFor(msg types.Message) int {
+	switch msg.Priority {
+	case types.PriorityUrgent:
+		return laneUrgent
+	case types.PriorityHigh:
+		return laneHigh
+	case types.PriorityNormal:
+		return laneNormal
+	case types.PriorityLow:
+		return laneLow
+	}
+
+	if msg.Type == types.MessageTypeTask {
+		return laneNormal
+	}
+	return laneHigh
+}
+
+// laneName returns the human-readable name of a lane index
+func laneName(lane int) string {
+	switch lane {
+	case laneUrgent:
+		return types.PriorityUrgent.String()
+	case laneHigh:
+		return types.PriorityHigh.String()
+	case laneLow:
+		return types.PriorityLow.String()
+	default:
+		return types.PriorityNormal.String()
 	}
 }
 
 // ReceiveMessage attempts to receive a message from the inbox (non-blocking).
 //
 // This is a non-blocking receive operation:
+//   - Lanes are checked in priority order (urgent, high, normal, low)
 //   - Returns immediately with (message, true) if message available
 //   - Returns immediately with (nil, false) if no message available
 //   - Does not wait for messages to arrive
@@ -374,17 +551,21 @@ func (a *BaseAgent) SendMessage(msg types.Message) error {
 //
 // Thread Safety: Channel operations are thread-safe
 func (a *BaseAgent) ReceiveMessage() (*types.Message, bool) {
-	select {
-	case msg, ok := <-a.inbox:
-		if !ok {
-			// Channel closed
-			return nil, false
+	for _, lane := range a.inbox {
+		select {
+		case msg, ok := <-lane:
+			if !ok {
+				// Channel closed
+				return nil, false
+			}
+			return &msg, true
+		default:
+			// Nothing on this lane, try the next one
 		}
-		return &msg, true
-	default:
-		// No message available
-		return nil, false
 	}
+
+	// No message available
+	return nil, false
 }
 
 // ProcessTask processes a task and returns a result.
@@ -420,8 +601,9 @@ func (a *BaseAgent) ReceiveMessage() (*types.Message, bool) {
 //
 // Example Override:
 //   func (ra *ResearchAgent) ProcessTask(task types.Task) types.Result {
-//       // Call LLM API
-//       response, err := ra.llmClient.Complete(...)
+//       // Call LLM API under the task's context, so cancelling the task
+//       // aborts the request
+//       response, err := ra.llmClient.CompleteContext(ra.TaskContextFor(task.ID), ...)
 //       if err != nil {
 //           return types.Result{TaskID: task.ID, Success: false}
 //       }
@@ -441,6 +623,7 @@ func (a *BaseAgent) ProcessTask(task types.Task) types.Result {
 // Possible States:
 //   - StateIdle:       Agent created but not started
 //   - StateProcessing: Agent running and ready to process messages
+//   - StatePaused:     Agent running but holding messages until Resume()
 //   - StateStopped:    Agent has been shut down
 //
 // Use Cases:
@@ -467,9 +650,205 @@ func (a *BaseAgent) ProcessTask(task types.Task) types.Result {
 func (a *BaseAgent) GetState() types.AgentState {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
+	if a.paused && a.state == types.StateProcessing {
+		return types.StatePaused
+	}
 	return a.state
 }
 
+// Pause stops the agent from taking new messages. A task already being
+// handled runs to completion; later messages stay queued in the inbox until
+// Resume is called. Pausing a paused agent is a no-op.
+func (a *BaseAgent) Pause() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.state == types.StateStopped {
+		return fmt.Errorf("agent %s is stopped", a.id)
+	}
+	if !a.paused {
+		a.paused = true
+		a.resumeCh = make(chan struct{})
+	}
+	return nil
+}
+
+// Resume lets a paused agent continue with its queued messages
+func (a *BaseAgent) Resume() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.state == types.StateStopped {
+		return fmt.Errorf("agent %s is stopped", a.id)
+	}
+	if a.paused {
+		a.paused = false
+		close(a.resumeCh)
+	}
+	return nil
+}
+
+// CancelTask cancels taskID. If the agent is handling it right now, the
+// task's context (see TaskContextFor) is cancelled and true is returned.
+// Otherwise the ID is remembered and the task is dropped if it is dequeued
+// within dropExpiry.
+func (a *BaseAgent) CancelTask(taskID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if task, ok := a.tasks[taskID]; ok {
+		task.cancel()
+		return true
+	}
+	now := time.Now()
+	for id, at := range a.dropped {
+		if now.Sub(at) > dropExpiry {
+			delete(a.dropped, id)
+		}
+	}
+	a.dropped[taskID] = now
+	return false
+}
+
+// TaskContext returns the context of the task being handled, or of the most
+// recently started one when several workers are busy. Handlers that know
+// their task's ID should use TaskContextFor instead.
+func (a *BaseAgent) TaskContext() context.Context {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.contextFor(a.lastTask)
+}
+
+// TaskContextFor returns the context of the task being handled as taskID. It
+// is cancelled when the task is cancelled via CancelTask or the agent stops.
+// Handlers should pass it to long-running calls so cancellation takes effect.
+// For a task that isn't running it returns the agent's own context.
+func (a *BaseAgent) TaskContextFor(taskID string) context.Context {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.contextFor(taskID)
+}
+
+// Context returns the agent's own context, which is cancelled when it stops.
+// Work that isn't a task, like answering another agent's question, runs under
+// it.
+func (a *BaseAgent) Context() context.Context {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.contextFor("")
+}
+
+// contextFor returns a running task's context, falling back to the agent's.
+// The caller holds a.mu.
+func (a *BaseAgent) contextFor(taskID string) context.Context {
+	if task, ok := a.tasks[taskID]; ok {
+		return task.ctx
+	}
+	if a.ctx != nil {
+		return a.ctx
+	}
+	return context.Background()
+}
+
+// InboxDepth reports how much work the agent has: the messages waiting in its
+// inbox lanes or behind a busy conversation, plus the tasks it is handling.
+// The swarm's least-loaded strategy routes new tasks to the agent with the
+// smallest depth.
+func (a *BaseAgent) InboxDepth() int {
+	depth := 0
+	for i := range a.inbox {
+		depth += len(a.inbox[i])
+	}
+
+	a.mu.RLock()
+	depth += len(a.tasks)
+	a.mu.RUnlock()
+
+	a.convMu.Lock()
+	defer a.convMu.Unlock()
+	for _, waiting := range a.convs {
+		depth += len(waiting)
+	}
+	return depth
+}
+
+// SetThreadRouter gives the agent a way to ask other agents questions. The
+// swarm calls it when the agent is added.
+func (a *BaseAgent) SetThreadRouter(router types.ThreadRouter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.threads = router
+}
+
+// SetMemoryStore gives the agent access to the memory each run's agents
+// share. The swarm calls it when the agent is added.
+func (a *BaseAgent) SetMemoryStore(store types.MemoryStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.memory = store
+}
+
+// Memory returns the memory shared by the agents working on run runID (see
+// types.Task.RunID), or nil outside a swarm or a run; a nil memory is empty
+// and ignores writes.
+//
+// Example (inside a task handler):
+//   memory := a.Memory(task.RunID)
+//   memory.Put(types.Document{Name: "sources", Author: a.GetID(), Text: sources})
+//   if market, ok := memory.Get("market_size"); ok { ... }
+func (a *BaseAgent) Memory(runID string) *types.RunMemory {
+	a.mu.RLock()
+	store := a.memory
+	a.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+	return store.Memory(runID)
+}
+
+// Ask puts a question to another agent, named by ID or by specialty, and
+// waits for its answer. An empty threadID starts a new thread; passing the
+// ThreadID of an earlier answer continues that conversation.
+//
+// The other agent answers between its own messages, so Ask blocks until it
+// gets to the question. Pass a context with a deadline: two agents asking
+// each other at the same time would otherwise wait forever.
+//
+// Example (inside a task handler):
+//   ctx, cancel := context.WithTimeout(a.TaskContext(), 2*time.Minute)
+//   defer cancel()
+//   reply, err := a.Ask(ctx, "research", "", "What was the 2023 market size?")
+func (a *BaseAgent) Ask(ctx context.Context, to, threadID, question string) (types.ThreadMessage, error) {
+	// A context derived from the task's own names the task; otherwise guess
+	// the one started last
+	a.mu.RLock()
+	router, taskID := a.threads, a.lastTask
+	a.mu.RUnlock()
+	if id, ok := ctx.Value(taskIDKey{}).(string); ok {
+		taskID = id
+	}
+
+	if router == nil {
+		return types.ThreadMessage{}, fmt.Errorf("agent %s is not in a swarm", a.id)
+	}
+	return router.Ask(ctx, types.ThreadQuestion{
+		ThreadID: threadID,
+		TaskID:   taskID,
+		From:     a.id,
+		To:       to,
+		Text:     question,
+	})
+}
+
+// AnswersQuestions reports whether the agent has a handler for questions
+// from other agents (MessageTypeQuery)
+func (a *BaseAgent) AnswersQuestions() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, ok := a.handlers[types.MessageTypeQuery]
+	return ok
+}
+
 // RegisterHandler registers a custom handler function for a specific message type.
 //
 // Message Type Routing:
@@ -520,22 +899,25 @@ func (a *BaseAgent) RegisterHandler(msgType types.MessageType, handler MessageHa
 // This function runs in a separate goroutine (started by Start()).
 //
 // Loop Behavior:
-//   1. Wait for one of two events:
+//   1. Wait for a free worker (see WithWorkers; one by default)
+//   2. Wait for one of two events (see next()):
 //      a) Context cancelled (shutdown signal)
-//      b) Message arrives in inbox
-//   2. If context cancelled: Exit loop and return
-//   3. If message arrives: Call handleMessage() to process it
-//   4. Repeat (back to step 1)
+//      b) Message available in the urgent, high, normal, or low lane
+//   3. If context cancelled: Exit loop and return
+//   4. If message arrives: hand it to a worker, which calls handleMessage()
+//   5. Repeat (back to step 1)
 //
 // Message Processing:
-//   - Messages are processed sequentially (one at a time)
-//   - No concurrent message processing within the same agent
-//   - This ensures thread-safety for agent state
+//   - With one worker, messages are processed sequentially (one at a time),
+//     which keeps agent state thread-safe without locks
+//   - With more, independent messages run concurrently, while messages in
+//     the same conversation wait their turn (see dispatch)
 //
 // Lifecycle:
 //   - Runs until ctx.Done() signals (via cancel() or parent cancellation)
 //   - Decrements WaitGroup on exit (allows Stop() to wait for completion)
-//   - Exits gracefully after processing current message
+//   - Workers are in the WaitGroup too, so Stop() waits for the messages
+//     being handled to finish
 //
 // Goroutine Management:
 //   - defer a.wg.Done() ensures WaitGroup is decremented even if panic occurs
@@ -551,22 +933,68 @@ func (a *BaseAgent) run() {
 
 	// Main message processing loop
 	for {
+		// Wait for a free worker, so messages stay in their lanes (and in
+		// priority order) until one can take them
 		select {
+		case a.slots <- struct{}{}:
 		case <-a.ctx.Done():
+			return
+		}
+
+		msg, ok := a.next()
+		if !ok {
 			// Context was cancelled (Stop() was called or parent context cancelled)
-			// Exit the loop and return, ending the goroutine
+			// or a lane was closed. Exit the loop and return, ending the goroutine
 			return
+		}
 
-		case msg, ok := <-a.inbox:
-			if !ok {
-				// Channel was closed (shouldn't happen before ctx.Done, but handled defensively)
-				return
-			}
+		// Hold the message while paused (it may have arrived just as Pause was called)
+		if !a.waitWhilePaused() {
+			return
+		}
+
+		// Process the message using registered handlers or default handling,
+		// on a worker of its own (see workers.go)
+		a.dispatch(msg)
+	}
+}
 
-			// Process the message using registered handlers or default handling
-			a.handleMessage(msg)
+// next returns the next message to process, honoring lane priority.
+//
+// Lanes are first polled without blocking in priority order, so a waiting
+// high-lane message is always taken before anything on the normal or low
+// lanes. Only when every lane is empty does next block on all lanes at once
+// and return whichever message arrives first.
+//
+// Returns false when the agent's context is cancelled or a lane is closed.
+func (a *BaseAgent) next() (types.Message, bool) {
+	// Fast path: drain lanes strictly in priority order
+	for i, lane := range a.inbox {
+		select {
+		case <-a.ctx.Done():
+			return types.Message{}, false
+		case msg, ok := <-lane:
+			if ok && i == laneUrgent && a.backlogged() {
+				a.preemptions.Add(1)
+			}
+			return msg, ok
+		default:
 		}
 	}
+
+	// All lanes empty: block until any lane receives a message
+	select {
+	case <-a.ctx.Done():
+		return types.Message{}, false
+	case msg, ok := <-a.inbox[laneUrgent]:
+		return msg, ok
+	case msg, ok := <-a.inbox[laneHigh]:
+		return msg, ok
+	case msg, ok := <-a.inbox[laneNormal]:
+		return msg, ok
+	case msg, ok := <-a.inbox[laneLow]:
+		return msg, ok
+	}
 }
 
 // handleMessage processes an incoming message by routing it to the appropriate handler.
@@ -578,7 +1006,7 @@ func (a *BaseAgent) run() {
 //
 // Registered Handler Path:
 //   - Handler function is called with the message
-//   - If handler returns error, it's logged but doesn't crash the agent
+//   - If handler returns error, it's logged (slog, level error) but doesn't crash the agent
 //   - Agent continues processing next message
 //
 // Default Handling (no registered handler):
@@ -595,9 +1023,19 @@ func (a *BaseAgent) run() {
 //
 // Thread Safety:
 //   - Uses read lock to access handler map
-//   - Called from run() goroutine (sequential processing)
-//   - No concurrent calls within the same agent
+//   - Called from a worker goroutine; with WithWorkers(n), up to n calls run
+//     at once, but never two from the same conversation
 func (a *BaseAgent) handleMessage(msg types.Message) {
+	// Tasks get their own cancellable context for the duration of the handler
+	if task, ok := msg.Content.(types.Task); ok && msg.Type == types.MessageTypeTask {
+		active, ok := a.beginTask(task.ID)
+		if !ok {
+			a.Logger().Info("dropping cancelled task", logging.KeyTask, task.ID)
+			return
+		}
+		defer a.endTask(task.ID, active)
+	}
+
 	// Look up handler for this message type (with read lock)
 	a.mu.RLock()
 	handler, exists := a.handlers[msg.Type]
@@ -608,7 +1046,7 @@ func (a *BaseAgent) handleMessage(msg types.Message) {
 		if err := handler(msg); err != nil {
 			// Handler returned an error - log it but continue processing
 			// This ensures one bad message doesn't crash the agent
-			fmt.Printf("Agent %s: error handling message: %v\n", a.id, err)
+			a.Logger().Error("error handling message", "type", msg.Type, "from", msg.From, logging.KeyError, err)
 		}
 	} else {
 		// No registered handler - use default handling
@@ -617,12 +1055,66 @@ func (a *BaseAgent) handleMessage(msg types.Message) {
 			// Default task handling
 			if task, ok := msg.Content.(types.Task); ok {
 				// Call ProcessTask (may be overridden by specialized agents)
-				result := a.ProcessTask(task)
-				fmt.Printf("Agent %s: processed task %s, result: %+v\n", a.id, task.ID, result)
+				result := a.WrapTask(a.ProcessTask)(task)
+				a.Logger().Info("processed task", logging.KeyTask, task.ID, "success", result.Success)
 			}
 		default:
 			// Unknown message type - just log it
-			fmt.Printf("Agent %s: received message type %s from %s\n", a.id, msg.Type, msg.From)
+			a.Logger().Info("received message", "type", msg.Type, "from", msg.From)
+		}
+	}
+}
+
+// waitWhilePaused blocks while the agent is paused. It returns false if the
+// agent is stopped in the meantime.
+func (a *BaseAgent) waitWhilePaused() bool {
+	a.mu.RLock()
+	paused, resumeCh := a.paused, a.resumeCh
+	a.mu.RUnlock()
+
+	if !paused {
+		return true
+	}
+
+	select {
+	case <-resumeCh:
+		return true
+	case <-a.ctx.Done():
+		return false
+	}
+}
+
+// beginTask sets up the context for a task about to be handled. It returns
+// false if the task was cancelled while it was still queued.
+func (a *BaseAgent) beginTask(taskID string) (*activeTask, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if at, ok := a.dropped[taskID]; ok {
+		delete(a.dropped, taskID)
+		if time.Since(at) <= dropExpiry {
+			return nil, false
+		}
+	}
+	task := &activeTask{}
+	task.ctx, task.cancel = context.WithCancel(ContextWithTaskID(a.ctx, taskID))
+	a.tasks[taskID] = task
+	a.lastTask = taskID
+	return task, true
+}
+
+// endTask releases the context of a task that just finished. A redelivered
+// copy of the task may have started meanwhile under the same ID; it keeps
+// its entry.
+func (a *BaseAgent) endTask(taskID string, task *activeTask) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	task.cancel()
+	if a.tasks[taskID] == task {
+		delete(a.tasks, taskID)
+		if a.lastTask == taskID {
+			a.lastTask = ""
 		}
 	}
 }
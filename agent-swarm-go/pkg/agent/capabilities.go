@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithCapabilities gives the agent capability tags, e.g. "web-search" or
+// "finance". The swarm only hands the agent tasks whose Requires tags it has
+// all of.
+func WithCapabilities(tags ...string) Option {
+	return func(a *BaseAgent) {
+		a.capabilities = normalizeCapabilities(tags)
+	}
+}
+
+// SetCapabilities replaces the agent's capability tags, for agents built
+// without WithCapabilities. Tasks already in the inbox are not rerouted.
+func (a *BaseAgent) SetCapabilities(tags ...string) error {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("agent %s: capability tags must not be empty", a.id)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.capabilities = normalizeCapabilities(tags)
+	return nil
+}
+
+// GetCapabilities returns the agent's capability tags
+func (a *BaseAgent) GetCapabilities() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]string(nil), a.capabilities...)
+}
+
+// normalizeCapabilities trims the tags and drops empty and repeated ones
+func normalizeCapabilities(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
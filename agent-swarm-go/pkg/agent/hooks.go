@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// TaskFunc processes a task, like an agent's ProcessTask
+type TaskFunc func(task types.Task) types.Result
+
+// TaskMiddleware wraps task processing: it can inspect or change the task,
+// skip next to reject it, and inspect or change the result. Register one
+// with Use.
+type TaskMiddleware func(next TaskFunc) TaskFunc
+
+// Use adds middleware around the agent's task processing. The first
+// registered runs outermost, so it sees the task first and the result last.
+// Middleware applies to tasks started after it is registered.
+//
+// Example:
+//
+//	researcher.Use(agent.LogTiming())
+//	researcher.BeforeTask(func(task *types.Task) error {
+//	    if task.Description == "" {
+//	        return fmt.Errorf("empty description")
+//	    }
+//	    return nil
+//	})
+func (a *BaseAgent) Use(middleware ...TaskMiddleware) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.middleware = append(a.middleware, middleware...)
+}
+
+// BeforeTask registers a hook that runs before each task is processed. It may
+// modify the task; returning an error rejects the task, which then fails with
+// FailureRejected without being processed.
+func (a *BaseAgent) BeforeTask(hook func(task *types.Task) error) {
+	a.Use(func(next TaskFunc) TaskFunc {
+		return func(task types.Task) types.Result {
+			if err := hook(&task); err != nil {
+				return types.Result{
+					TaskID:  task.ID,
+					Success: false,
+					Data:    fmt.Sprintf("Task rejected: %v", err),
+					Error:   err,
+					Failure: types.FailureRejected,
+				}
+			}
+			return next(task)
+		}
+	})
+}
+
+// AfterTask registers a hook that runs after each task is processed and may
+// modify the result. Tasks rejected by a BeforeTask hook registered earlier
+// never reach it; register it first to see those results too.
+func (a *BaseAgent) AfterTask(hook func(task types.Task, result *types.Result)) {
+	a.Use(func(next TaskFunc) TaskFunc {
+		return func(task types.Task) types.Result {
+			result := next(task)
+			hook(task, &result)
+			return result
+		}
+	})
+}
+
+// WrapTask returns process wrapped in the agent's middleware. Agents call it
+// on their ProcessTask when handling a task message, so hooks run however the
+// agent was built.
+func (a *BaseAgent) WrapTask(process TaskFunc) TaskFunc {
+	a.mu.RLock()
+	middleware := append([]TaskMiddleware(nil), a.middleware...)
+	a.mu.RUnlock()
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		process = middleware[i](process)
+	}
+	return process
+}
+
+// LogTiming is middleware that logs how long each task took to process
+func LogTiming() TaskMiddleware {
+	return func(next TaskFunc) TaskFunc {
+		return func(task types.Task) types.Result {
+			start := time.Now()
+			result := next(task)
+			slog.Info("task timing", logging.KeyTask, task.ID,
+				"duration", time.Since(start).Round(time.Millisecond), "success", result.Success)
+			return result
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// OverflowPolicy decides what SendMessage does when the message's inbox lane
+// is full
+type OverflowPolicy string
+
+const (
+	// OverflowError rejects the new message with an error. It is the default,
+	// leaving the sender (e.g. the swarm's task queue) to retry elsewhere.
+	OverflowError OverflowPolicy = "error"
+
+	// OverflowBlock waits until the lane has room or the agent stops. The
+	// sender is stuck for as long as the agent is busy, so use it only where
+	// back-pressure is wanted.
+	OverflowBlock OverflowPolicy = "block"
+
+	// OverflowDropOldest discards the longest-waiting message on the lane to
+	// make room. Suits streams where only recent messages matter; a dropped
+	// task never runs unless the swarm's visibility timeout redelivers it.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+
+	// OverflowDropNewest discards the new message and reports success
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+)
+
+// Option configures a BaseAgent at construction
+type Option func(*BaseAgent)
+
+// WithInboxSize sets the capacity of each inbox lane (default 100)
+func WithInboxSize(size int) Option {
+	return func(a *BaseAgent) {
+		if size > 0 {
+			a.inboxSize = size
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens when a message arrives at a full lane
+// (default OverflowError)
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(a *BaseAgent) { a.overflow = policy }
+}
+
+// InboxSize returns the capacity of each inbox lane
+func (a *BaseAgent) InboxSize() int {
+	return a.inboxSize
+}
+
+// OverflowPolicy returns what happens when a message arrives at a full lane
+func (a *BaseAgent) OverflowPolicy() OverflowPolicy {
+	return a.overflow
+}
+
+// OverflowDrops returns how many messages the drop policies have discarded
+func (a *BaseAgent) OverflowDrops() int64 {
+	return a.drops.Load()
+}
+
+// overflowFull applies a non-blocking overflow policy to msg, whose lane was
+// full. The caller holds a.mu's read lock, so the lanes can't be closed.
+func (a *BaseAgent) overflowFull(lane int, msg types.Message) error {
+	switch a.overflow {
+	case OverflowDropNewest:
+		a.logDrop(lane, msg)
+		return nil
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case a.inbox[lane] <- msg:
+				return nil
+			default:
+			}
+			// The run() loop may empty the lane first, so the receive can miss
+			select {
+			case old := <-a.inbox[lane]:
+				a.logDrop(lane, old)
+			default:
+			}
+		}
+
+	default:
+		return fmt.Errorf("agent %s inbox is full (%s lane)", a.id, laneName(lane))
+	}
+}
+
+// sendBlocking waits for room on the lane for OverflowBlock. The caller is
+// counted in a.senders, so Stop waits for it before closing the lanes.
+func (a *BaseAgent) sendBlocking(lane int, msg types.Message) error {
+	select {
+	case a.inbox[lane] <- msg:
+		return nil
+	case <-a.stopped:
+		return fmt.Errorf("agent %s is stopped", a.id)
+	}
+}
+
+// logDrop counts and logs a message discarded by a drop policy
+func (a *BaseAgent) logDrop(lane int, msg types.Message) {
+	a.drops.Add(1)
+	args := []interface{}{logging.KeyAgent, a.id, "lane", laneName(lane), "type", msg.Type}
+	if task, ok := msg.Content.(types.Task); ok {
+		args = append(args, logging.KeyTask, task.ID)
+	}
+	slog.Warn("inbox full, message dropped", args...)
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+func taskMessage(id string, priority types.MessagePriority) types.Message {
+	return types.Message{Type: types.MessageTypeTask, Priority: priority, Content: types.Task{ID: id}}
+}
+
+// receiveTasks empties an agent that was never started, returning the task
+// IDs in the order its run loop would take them
+func receiveTasks(a *BaseAgent) []string {
+	var ids []string
+	for {
+		msg, ok := a.ReceiveMessage()
+		if !ok {
+			return ids
+		}
+		ids = append(ids, msg.Content.(types.Task).ID)
+	}
+}
+
+func TestLanesAreTakenInPriorityOrder(t *testing.T) {
+	a := NewBaseAgent("agent-1")
+	for _, msg := range []types.Message{
+		taskMessage("low", types.PriorityLow),
+		taskMessage("default", types.PriorityDefault),
+		taskMessage("high", types.PriorityHigh),
+		taskMessage("urgent", types.PriorityUrgent),
+	} {
+		if err := a.SendMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := receiveTasks(a)
+	want := []string{"urgent", "high", "default", "low"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestControlMessagesSkipTaskBacklog(t *testing.T) {
+	if lane := laneFor(types.Message{Type: types.MessageTypeBroadcast}); lane != laneHigh {
+		t.Errorf("broadcast goes to the %s lane; want high", laneName(lane))
+	}
+	if lane := laneFor(taskMessage("t", types.PriorityDefault)); lane != laneNormal {
+		t.Errorf("task goes to the %s lane; want normal", laneName(lane))
+	}
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy  OverflowPolicy
+		wantErr bool
+		want    string // The task left in the lane
+		drops   int64
+	}{
+		{OverflowError, true, "first", 0},
+		{OverflowDropNewest, false, "first", 1},
+		{OverflowDropOldest, false, "second", 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			a := NewBaseAgent("agent-1", WithInboxSize(1), WithOverflowPolicy(tt.policy))
+			if err := a.SendMessage(taskMessage("first", types.PriorityNormal)); err != nil {
+				t.Fatal(err)
+			}
+			err := a.SendMessage(taskMessage("second", types.PriorityNormal))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("second send: %v; want error %v", err, tt.wantErr)
+			}
+			if got := receiveTasks(a); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("lane holds %v; want [%s]", got, tt.want)
+			}
+			if n := a.OverflowDrops(); n != tt.drops {
+				t.Errorf("%d drops; want %d", n, tt.drops)
+			}
+		})
+	}
+}
+
+func TestFullLaneDoesNotBlockOtherLanes(t *testing.T) {
+	a := NewBaseAgent("agent-1", WithInboxSize(1))
+	if err := a.SendMessage(taskMessage("bulk", types.PriorityLow)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SendMessage(taskMessage("more", types.PriorityLow)); err == nil {
+		t.Error("low lane accepted a second task; want it full")
+	}
+	if err := a.SendMessage(taskMessage("urgent", types.PriorityUrgent)); err != nil {
+		t.Errorf("urgent send: %v; want its own lane to have room", err)
+	}
+}
+
+func TestOverflowBlockWaitsForRoom(t *testing.T) {
+	a := NewBaseAgent("agent-1", WithInboxSize(1), WithOverflowPolicy(OverflowBlock))
+	if err := a.SendMessage(taskMessage("first", types.PriorityNormal)); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan error, 1)
+	go func() { sent <- a.SendMessage(taskMessage("second", types.PriorityNormal)) }()
+	select {
+	case err := <-sent:
+		t.Fatalf("send to a full lane returned %v; want it to wait", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.ReceiveMessage()
+	if err := <-sent; err != nil {
+		t.Errorf("send after room was made: %v", err)
+	}
+}
+
+func TestStopReleasesBlockedSender(t *testing.T) {
+	a := NewBaseAgent("agent-1", WithInboxSize(1), WithOverflowPolicy(OverflowBlock))
+	if err := a.SendMessage(taskMessage("first", types.PriorityNormal)); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan error, 1)
+	go func() { sent <- a.SendMessage(taskMessage("second", types.PriorityNormal)) }()
+	time.Sleep(20 * time.Millisecond)
+	a.Stop()
+
+	select {
+	case err := <-sent:
+		if err == nil {
+			t.Error("blocked send succeeded on a stopped agent")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop left the sender blocked")
+	}
+}
@@ -0,0 +1,17 @@
+package agent
+
+// Preemptions returns how many urgent messages the agent has taken ahead of
+// messages already waiting on its other lanes
+func (a *BaseAgent) Preemptions() int64 {
+	return a.preemptions.Load()
+}
+
+// backlogged reports whether any lane below urgent has messages waiting
+func (a *BaseAgent) backlogged() bool {
+	for lane := laneHigh; lane < laneCount; lane++ {
+		if len(a.inbox[lane]) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// WithWorkers lets the agent handle up to n messages at once (default 1), so
+// one slow LLM call doesn't hold up the independent tasks queued behind it.
+// Messages in the same conversation still run one at a time, in the order
+// they were taken from the inbox (see conversationKey). Handlers of an agent
+// with more than one worker must guard any state they share.
+func WithWorkers(n int) Option {
+	return func(a *BaseAgent) {
+		if n > 0 {
+			a.workers = n
+		}
+	}
+}
+
+// SetWorkers changes how many messages the agent handles at once, for agents
+// built without WithWorkers. It must be called before Start.
+func (a *BaseAgent) SetWorkers(n int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if n < 1 {
+		return fmt.Errorf("agent %s: workers must be at least 1", a.id)
+	}
+	if a.state != types.StateIdle {
+		return fmt.Errorf("agent %s: workers can't change once started", a.id)
+	}
+	a.workers = n
+	return nil
+}
+
+// Workers returns how many messages the agent handles at once
+func (a *BaseAgent) Workers() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.workers
+}
+
+// ContextWithTaskID marks ctx as working on taskID, so Ask files questions
+// asked under it with the right task when several are running
+func ContextWithTaskID(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, taskIDKey{}, taskID)
+}
+
+// conversationKey names the conversation a message belongs to, or returns ""
+// when it can run alongside anything. Tasks belong to the conversation in
+// their Payload["conversation_id"] and questions from other agents to their
+// thread.
+func conversationKey(msg types.Message) string {
+	switch content := msg.Content.(type) {
+	case types.Task:
+		if id := content.ConversationID(); id != "" {
+			return "task:" + id
+		}
+	case types.Query:
+		if content.ThreadID != "" {
+			return "thread:" + content.ThreadID
+		}
+	}
+	return ""
+}
+
+// dispatch hands msg to a worker. run has already taken a worker slot for it.
+// If msg's conversation is busy, it waits behind it instead and the slot is
+// given back; the conversation's worker runs it next.
+func (a *BaseAgent) dispatch(msg types.Message) {
+	key := conversationKey(msg)
+	if key != "" {
+		a.convMu.Lock()
+		waiting, busy := a.convs[key]
+		if busy {
+			a.convs[key] = append(waiting, msg)
+			a.convMu.Unlock()
+			<-a.slots
+			return
+		}
+		a.convs[key] = nil
+		a.convMu.Unlock()
+	}
+
+	a.wg.Add(1)
+	go a.work(key, msg)
+}
+
+// work handles msg, then whatever queued up behind it in its conversation,
+// and frees its slot
+func (a *BaseAgent) work(key string, msg types.Message) {
+	defer a.wg.Done()
+	defer func() { <-a.slots }()
+
+	for {
+		a.handleMessage(msg)
+		if key == "" {
+			return
+		}
+
+		a.convMu.Lock()
+		waiting := a.convs[key]
+		if len(waiting) == 0 {
+			delete(a.convs, key)
+			a.convMu.Unlock()
+			return
+		}
+		msg, a.convs[key] = waiting[0], waiting[1:]
+		a.convMu.Unlock()
+
+		// A stopped agent drops the rest, as it does its inbox
+		if !a.waitWhilePaused() {
+			return
+		}
+	}
+}
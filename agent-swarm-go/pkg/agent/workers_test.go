@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// conversationTask is a task message in conversation conv
+func conversationTask(id, conv string) types.Message {
+	task := types.Task{ID: id}
+	if conv != "" {
+		task.Payload = map[string]interface{}{"conversation_id": conv}
+	}
+	return types.Message{Type: types.MessageTypeTask, Content: task}
+}
+
+func startAgent(t *testing.T, a *BaseAgent) {
+	t.Helper()
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Stop() })
+}
+
+func TestConversationRunsInOrderAcrossWorkers(t *testing.T) {
+	const n = 20
+	a := NewBaseAgent("agent-1", WithWorkers(4))
+
+	var (
+		mu      sync.Mutex
+		order   = map[string][]string{}
+		running = map[string]bool{}
+		overlap bool
+		done    sync.WaitGroup
+	)
+	a.RegisterHandler(types.MessageTypeTask, func(msg types.Message) error {
+		defer done.Done()
+		task := msg.Content.(types.Task)
+		conv := task.ConversationID()
+		mu.Lock()
+		overlap = overlap || running[conv]
+		running[conv] = true
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		running[conv] = false
+		order[conv] = append(order[conv], task.ID)
+		mu.Unlock()
+		return nil
+	})
+	startAgent(t, a)
+
+	done.Add(2 * n)
+	for i := 0; i < n; i++ {
+		for _, conv := range []string{"a", "b"} {
+			if err := a.SendMessage(conversationTask(fmt.Sprintf("%s-%02d", conv, i), conv)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	done.Wait()
+
+	if overlap {
+		t.Error("two tasks of one conversation ran at once")
+	}
+	for _, conv := range []string{"a", "b"} {
+		for i, id := range order[conv] {
+			if want := fmt.Sprintf("%s-%02d", conv, i); id != want {
+				t.Fatalf("conversation %s ran %v; want it in send order", conv, order[conv])
+			}
+		}
+	}
+}
+
+func TestIndependentTasksRunConcurrently(t *testing.T) {
+	a := NewBaseAgent("agent-1", WithWorkers(2))
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	a.RegisterHandler(types.MessageTypeTask, func(msg types.Message) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	startAgent(t, a)
+	defer close(release)
+
+	for _, id := range []string{"t-1", "t-2"} {
+		if err := a.SendMessage(conversationTask(id, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("%d of 2 tasks started; want both running at once", i)
+		}
+	}
+}
+
+func TestPauseHoldsMessagesUntilResume(t *testing.T) {
+	a := NewBaseAgent("agent-1")
+	handled := make(chan string, 1)
+	a.RegisterHandler(types.MessageTypeTask, func(msg types.Message) error {
+		handled <- msg.Content.(types.Task).ID
+		return nil
+	})
+	startAgent(t, a)
+
+	if err := a.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SendMessage(conversationTask("t-1", "")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-handled:
+		t.Fatalf("paused agent handled %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := a.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("task not handled after Resume")
+	}
+}
+
+func TestSetWorkersRefusedOnceStarted(t *testing.T) {
+	a := NewBaseAgent("agent-1")
+	startAgent(t, a)
+	if err := a.SetWorkers(4); err == nil {
+		t.Error("SetWorkers on a running agent succeeded")
+	}
+}
@@ -2,7 +2,6 @@ package agents
 
 import (
 	"fmt"
-	"time"
 
 	"agent-swarm-go/pkg/agent"
 	"agent-swarm-go/pkg/llm"
@@ -14,81 +13,41 @@ type AnalysisAgent struct {
 	*agent.BaseAgent
 	llmClient *llm.Client
 	eventBus  *types.EventBus
-	history   []llm.Message
+	history   conversation // See history.go
 }
 
 // NewAnalysisAgent creates a new analysis agent
 func NewAnalysisAgent(id string, eventBus *types.EventBus) *AnalysisAgent {
+	return newAnalysisAgent(id, eventBus, llm.NewClient())
+}
+
+// newAnalysisAgent creates a analysis agent that uses the given LLM client
+func newAnalysisAgent(id string, eventBus *types.EventBus, client *llm.Client) *AnalysisAgent {
 	aa := &AnalysisAgent{
 		BaseAgent: agent.NewBaseAgent(id),
-		llmClient: llm.NewClient(),
+		llmClient: client,
 		eventBus:  eventBus,
-		history:   []llm.Message{},
 	}
 
 	aa.RegisterHandler(types.MessageTypeTask, aa.handleTask)
+	answerQuestions(aa.BaseAgent, client, "a data analysis specialist")
 	return aa
 }
 
-func (aa *AnalysisAgent) handleTask(msg types.Message) error {
-	task, ok := msg.Content.(types.Task)
-	if !ok {
-		return fmt.Errorf("invalid task format")
-	}
-
-	if aa.eventBus != nil {
-		aa.eventBus.Publish(types.Event{
-			Type:      types.EventTaskReceived,
-			Timestamp: time.Now(),
-			AgentID:   aa.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("📥 Received analysis task: %s", task.Description),
-		})
-	}
-
-	fmt.Printf("[%s] 📥 Analyzer %s received task: %s\n",
-		time.Now().Format("15:04:05"), aa.GetID(), task.Description)
-
-	if aa.eventBus != nil {
-		aa.eventBus.Publish(types.Event{
-			Type:      types.EventTaskStarted,
-			Timestamp: time.Now(),
-			AgentID:   aa.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("⚙️  Analyzing: %s", task.Description),
-		})
-	}
-
-	result := aa.ProcessTask(task)
-
-	eventType := types.EventTaskCompleted
-	if !result.Success {
-		eventType = types.EventTaskFailed
-	}
-	if aa.eventBus != nil {
-		aa.eventBus.Publish(types.Event{
-			Type:      eventType,
-			Timestamp: time.Now(),
-			AgentID:   aa.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("✅ Analysis complete: %s", task.ID),
-			Data:      result,
-		})
-	}
-
-	statusIcon := "✅"
-	if !result.Success {
-		statusIcon = "❌"
-	}
-	fmt.Printf("[%s] %s Analyzer %s completed: %s\n",
-		time.Now().Format("15:04:05"), statusIcon, aa.GetID(), task.ID)
+// analysisLabels is the wording used in this agent's task events
+var analysisLabels = taskLabels{
+	received:  "📥 Received analysis task: %s",
+	started:   "⚙️  Analyzing: %s",
+	completed: "✅ Analysis complete: %s",
+	failed:    "❌ Analysis failed: %s",
+}
 
-	return nil
+func (aa *AnalysisAgent) handleTask(msg types.Message) error {
+	return runTask(aa.BaseAgent, aa.eventBus, analysisLabels, msg, aa.ProcessTask)
 }
 
-// ProcessTask performs analysis using LLM
-func (aa *AnalysisAgent) ProcessTask(task types.Task) types.Result {
-	systemPrompt := `You are a data analysis specialist agent. Your role is to:
+// analysisPrompt is the built-in analysis system prompt (see Prompts)
+const analysisPrompt = `You are a data analysis specialist agent. Your role is to:
 1. Assess data quality and completeness
 2. Identify patterns, trends, and anomalies
 3. Apply analytical techniques
@@ -96,11 +55,15 @@ func (aa *AnalysisAgent) ProcessTask(task types.Task) types.Result {
 
 Provide thorough, evidence-based analysis.`
 
-	contextStr := ""
-	if task.Context != nil {
-		contextStr = fmt.Sprintf("%v", task.Context)
+// ProcessTask performs analysis using LLM
+func (aa *AnalysisAgent) ProcessTask(task types.Task) types.Result {
+	systemPrompt, err := renderPrompt("analysis", aa.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Analysis failed: %v", err)}
 	}
 
+	contextStr := renderContext(task.Context)
+
 	userPrompt := fmt.Sprintf(`Analysis Task: %s
 
 Data/Research to analyze:
@@ -114,32 +77,47 @@ Please provide a comprehensive analysis including:
 - Actionable recommendations
 - Confidence levels in findings`, task.Description, contextStr)
 
-	response, err := aa.llmClient.Complete(systemPrompt, userPrompt, aa.history)
+	ctx, cancel := taskContext(aa.BaseAgent, aa.eventBus, task)
+	defer cancel()
+	reportProgress(aa.eventBus, aa.BaseAgent, task, 10, phaseCallingLLM)
+	response, err := completeWithPeers(ctx, aa.BaseAgent, aa.llmClient, []string{"research"}, systemPrompt, userPrompt, aa.history.snapshot())
 	if err != nil {
 		return types.Result{
-			TaskID:  task.ID,
-			Success: false,
-			Data:    fmt.Sprintf("Analysis failed: %v", err),
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Analysis failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
 		}
 	}
 
-	aa.history = append(aa.history,
-		llm.Message{Role: "user", Content: userPrompt},
-		llm.Message{Role: "assistant", Content: response},
-	)
-
-	if len(aa.history) > 6 {
-		aa.history = aa.history[len(aa.history)-6:]
-	}
+	reportProgress(aa.eventBus, aa.BaseAgent, task, 90, phasePostProcessing)
+	aa.history.record(ctx, aa.llmClient, userPrompt, response)
 
 	return types.Result{
-		TaskID:  task.ID,
-		Success: true,
-		Data:    response,
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     response,
+		Metadata: resultMetadata(ctx),
 	}
 }
 
 // GetSpecialty returns the agent's specialty
 func (aa *AnalysisAgent) GetSpecialty() string {
 	return "analysis"
+}
+
+// HistorySize returns the number of messages in the conversation history
+func (aa *AnalysisAgent) HistorySize() int {
+	return aa.history.size()
+}
+
+// HistorySummary returns the rolling summary of older exchanges
+func (aa *AnalysisAgent) HistorySummary() string {
+	return aa.history.rollingSummary()
+}
+
+// Warmup runs the LLM self-test
+func (aa *AnalysisAgent) Warmup() error {
+	return aa.llmClient.SelfTest()
 }
\ No newline at end of file
@@ -0,0 +1,136 @@
+package agents
+
+import (
+	"fmt"
+	"strings"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// CodeAgent writes code for engineering tasks: implementations, scripts,
+// tests, and fixes, returned as markdown with the code in fenced blocks.
+//
+// Optional Payload hints:
+//   - "language": the language to write in, e.g. "go" or "python"
+//   - "framework": a library or framework the code should use
+type CodeAgent struct {
+	*agent.BaseAgent
+	llmClient *llm.Client
+	eventBus  *types.EventBus
+}
+
+// NewCodeAgent creates a new code generation agent
+func NewCodeAgent(id string, eventBus *types.EventBus) *CodeAgent {
+	return newCodeAgent(id, eventBus, llm.NewClient())
+}
+
+// newCodeAgent creates a code agent that uses the given LLM client
+func newCodeAgent(id string, eventBus *types.EventBus, client *llm.Client) *CodeAgent {
+	ca := &CodeAgent{
+		BaseAgent: agent.NewBaseAgent(id),
+		llmClient: client,
+		eventBus:  eventBus,
+	}
+
+	ca.RegisterHandler(types.MessageTypeTask, ca.handleTask)
+	return ca
+}
+
+// codeLabels is the wording used in this agent's task events
+var codeLabels = taskLabels{
+	received:  "📥 Received code task: %s",
+	started:   "⚙️  Coding: %s",
+	completed: "✅ Code complete: %s",
+	failed:    "❌ Code failed: %s",
+}
+
+func (ca *CodeAgent) handleTask(msg types.Message) error {
+	return runTask(ca.BaseAgent, ca.eventBus, codeLabels, msg, ca.ProcessTask)
+}
+
+// codePrompt is the built-in system prompt for code tasks (see Prompts). The
+// task's language and framework go in the user prompt.
+const codePrompt = `You are a senior software engineer agent. Your role is to:
+1. Work out exactly what the task requires, noting any assumptions
+2. Write correct, idiomatic, production-quality code
+3. Handle errors and edge cases instead of leaving TODOs
+4. Explain briefly how to use or run the code
+
+Put all code in fenced markdown code blocks tagged with the language.`
+
+// ProcessTask writes the code a task asks for using LLM. Results of earlier
+// steps (a spec, an analysis) may be passed in task.Context.
+func (ca *CodeAgent) ProcessTask(task types.Task) types.Result {
+	language := payloadString(task, "language")
+	framework := payloadString(task, "framework")
+
+	systemPrompt, err := renderPrompt("code", ca.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Code generation failed: %v", err)}
+	}
+
+	var hints strings.Builder
+	if language != "" {
+		fmt.Fprintf(&hints, "\nLanguage: %s", language)
+	}
+	if framework != "" {
+		fmt.Fprintf(&hints, "\nFramework/library: %s", framework)
+	}
+
+	contextStr := ""
+	if rendered := renderContext(task.Context); rendered != "" {
+		contextStr = "\n\nContext from previous tasks:\n\n" + rendered
+	}
+
+	userPrompt := fmt.Sprintf(`Code Task: %s%s
+
+Please respond with:
+- A one-paragraph summary of the approach
+- The complete code in fenced code blocks
+- Usage notes (how to build, run, or test it)%s`, task.Description, hints.String(), contextStr)
+
+	// Each task stands alone, so no conversation history is kept
+	ctx, cancel := taskContext(ca.BaseAgent, ca.eventBus, task)
+	defer cancel()
+	reportProgress(ca.eventBus, ca.BaseAgent, task, 10, phaseCallingLLM)
+	response, err := ca.llmClient.CompleteContext(ctx, systemPrompt, userPrompt, nil)
+	if err != nil {
+		return types.Result{
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Code generation failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
+		}
+	}
+
+	return types.Result{
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     fenceCode(response, language),
+		Metadata: resultMetadata(ctx),
+	}
+}
+
+// fenceCode makes sure a response's code is fenced. Models occasionally answer
+// with bare code; such a response is wrapped in a single block tagged with
+// the requested language.
+func fenceCode(response, language string) string {
+	response = strings.TrimSpace(response)
+	if strings.Contains(response, "```") {
+		return response
+	}
+	return "```" + strings.ToLower(language) + "\n" + response + "\n```"
+}
+
+// GetSpecialty returns the agent's specialty
+func (ca *CodeAgent) GetSpecialty() string {
+	return "code"
+}
+
+// Warmup runs the LLM self-test
+func (ca *CodeAgent) Warmup() error {
+	return ca.llmClient.SelfTest()
+}
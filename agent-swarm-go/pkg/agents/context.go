@@ -0,0 +1,74 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// renderContext formats a task's context, usually the outputs of the steps
+// it depends on, as markdown for a prompt: one section per key, labelled
+// after it ("research_findings" → "## Research Findings") and sorted by key,
+// so the same context always makes the same prompt. Text is written as is,
+// and structured values (maps, lists, structs) as indented JSON. Empty values
+// are left out; with nothing left, it returns "".
+func renderContext(context map[string]interface{}) string {
+	keys := make([]string, 0, len(context))
+	for key := range context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sections []string
+	for _, key := range keys {
+		value := renderContextValue(context[key])
+		if value == "" {
+			continue
+		}
+		sections = append(sections, fmt.Sprintf("## %s\n\n%s", contextLabel(key), value))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// renderContextValue formats one context value, or returns "" if it is empty
+func renderContextValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case fmt.Stringer: // e.g. types.Translations, which renders its own sections
+		return strings.TrimSpace(v.String())
+	case error:
+		return v.Error()
+	case bool, int, int64, float64:
+		return fmt.Sprint(v)
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return strings.TrimSpace(fmt.Sprintf("%v", value))
+	}
+	switch string(data) {
+	case "null", "{}", "[]", `""`:
+		return ""
+	}
+	return "```json\n" + string(data) + "\n```"
+}
+
+// contextLabel turns a context key into a heading: words split on '_', '-',
+// and '.', each capitalized
+func contextLabel(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	if len(words) == 0 {
+		return key
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,209 @@
+package agents
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultMinScore is the accuracy and completeness score (out of 10) output
+// must reach for a CriticAgent to approve it
+const DefaultMinScore = 7
+
+// Context keys of a review task (see ReviewTask)
+const (
+	ReviewKeyTask   = "original_task"
+	ReviewKeyOutput = "output"
+)
+
+// CriticAgent reviews another agent's output against the task that produced
+// it, scores its accuracy and completeness, and approves it or asks for a
+// revision. Its Result.Data is a types.Review.
+type CriticAgent struct {
+	*agent.BaseAgent
+	llmClient *llm.Client
+	eventBus  *types.EventBus
+}
+
+// NewCriticAgent creates a new review agent
+func NewCriticAgent(id string, eventBus *types.EventBus) *CriticAgent {
+	return newCriticAgent(id, eventBus, llm.NewClient())
+}
+
+// newCriticAgent creates a critic agent that uses the given LLM client
+func newCriticAgent(id string, eventBus *types.EventBus, client *llm.Client) *CriticAgent {
+	ca := &CriticAgent{
+		BaseAgent: agent.NewBaseAgent(id),
+		llmClient: client,
+		eventBus:  eventBus,
+	}
+
+	ca.RegisterHandler(types.MessageTypeTask, ca.handleTask)
+	return ca
+}
+
+// ReviewTask builds the task asking a critic to review output produced for
+// original. Output scoring below minScore (0 means DefaultMinScore) on either
+// axis is sent back for revision.
+func ReviewTask(original types.Task, output interface{}, minScore int) types.Task {
+	if minScore <= 0 {
+		minScore = DefaultMinScore
+	}
+	return types.Task{
+		ID:          original.ID + "-review",
+		Description: fmt.Sprintf("Review the output of task %s", original.ID),
+		Payload:     map[string]interface{}{"agent_type": "critic", "min_score": minScore},
+		Priority:    original.Priority,
+		Context: map[string]interface{}{
+			ReviewKeyTask:   original.Description,
+			ReviewKeyOutput: output,
+		},
+		Dependencies: []string{original.ID},
+		Timeout:      original.Timeout,
+	}
+}
+
+// criticLabels is the wording used in this agent's task events
+var criticLabels = taskLabels{
+	received:  "📥 Received review task: %s",
+	started:   "⚙️  Reviewing: %s",
+	completed: "✅ Review complete: %s",
+	failed:    "❌ Review failed: %s",
+}
+
+func (ca *CriticAgent) handleTask(msg types.Message) error {
+	return runTask(ca.BaseAgent, ca.eventBus, criticLabels, msg, ca.ProcessTask)
+}
+
+// criticPrompt is the built-in reviewer system prompt (see Prompts). The
+// answer format parseReview reads is asked for in the user prompt.
+const criticPrompt = `You are a critical reviewer agent. Your role is to:
+1. Check the output against exactly what the task asked for
+2. Flag factual errors, unsupported claims, and contradictions
+3. Note anything the task required that is missing or thin
+4. Give specific, actionable feedback the author can apply
+
+Be strict but fair, and answer in the exact format requested.`
+
+// ProcessTask reviews the output in task.Context (see ReviewTask) using LLM.
+// The output is approved only if the model approves it and both scores reach
+// the task's min_score.
+func (ca *CriticAgent) ProcessTask(task types.Task) types.Result {
+	original := fmt.Sprint(task.Context[ReviewKeyTask])
+	output := fmt.Sprint(task.Context[ReviewKeyOutput])
+	if task.Context[ReviewKeyOutput] == nil || strings.TrimSpace(output) == "" {
+		return types.Result{TaskID: task.ID, Success: false, Data: "Review failed: no output to review"}
+	}
+
+	minScore := DefaultMinScore
+	if n, ok := payloadInt(task, "min_score"); ok && n > 0 {
+		minScore = n
+	}
+
+	systemPrompt, err := renderPrompt("critic", ca.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Review failed: %v", err)}
+	}
+
+	userPrompt := fmt.Sprintf(`Review Task: check the output below against its task.
+
+Original task:
+%s
+
+Output to review:
+%s
+
+Answer in exactly this format:
+ACCURACY: <1-10>
+COMPLETENESS: <1-10>
+VERDICT: APPROVE or REVISE
+FEEDBACK: <what to fix, or why it is good enough>
+
+Approve only if both scores are at least %d.`, original, output, minScore)
+
+	// Reviews are independent, so no conversation history is kept
+	ctx, cancel := taskContext(ca.BaseAgent, ca.eventBus, task)
+	defer cancel()
+	reportProgress(ca.eventBus, ca.BaseAgent, task, 10, phaseCallingLLM)
+	response, err := ca.llmClient.CompleteContext(ctx, systemPrompt, userPrompt, nil)
+	if err != nil {
+		return types.Result{
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Review failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
+		}
+	}
+
+	reportProgress(ca.eventBus, ca.BaseAgent, task, 80, phaseParsing)
+	review, err := parseReview(response)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Review failed: %v", err)}
+	}
+	review.Approved = review.Approved && review.Accuracy >= minScore && review.Completeness >= minScore
+
+	return types.Result{
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     review,
+		Metadata: resultMetadata(ctx),
+	}
+}
+
+// reviewLine matches one "FIELD: value" line of a review response
+var reviewLine = regexp.MustCompile(`(?im)^\W*(accuracy|completeness|verdict|feedback)\W*:\s*(.*)$`)
+
+// parseReview reads the fields requested by ProcessTask from a response.
+// Scores may be written as "8" or "8/10"; feedback may span several lines.
+func parseReview(response string) (types.Review, error) {
+	var review types.Review
+	var haveVerdict bool
+
+	matches := reviewLine.FindAllStringSubmatchIndex(response, -1)
+	for i, m := range matches {
+		field := strings.ToLower(response[m[2]:m[3]])
+		value := strings.TrimLeft(response[m[4]:m[5]], "*_ \t") // Markdown emphasis, as in "**Accuracy:** 8"
+		switch field {
+		case "accuracy", "completeness":
+			n, err := strconv.Atoi(strings.TrimSpace(strings.SplitN(value, "/", 2)[0]))
+			if err != nil {
+				return review, fmt.Errorf("%s score %q is not a number", field, value)
+			}
+			if field == "accuracy" {
+				review.Accuracy = n
+			} else {
+				review.Completeness = n
+			}
+		case "verdict":
+			haveVerdict = true
+			review.Approved = strings.HasPrefix(strings.ToUpper(value), "APPROVE")
+		case "feedback":
+			end := len(response)
+			if i+1 < len(matches) {
+				end = matches[i+1][0]
+			}
+			review.Feedback = strings.TrimSpace(strings.Trim(strings.TrimSpace(response[m[4]:end]), "*_"))
+		}
+	}
+
+	if !haveVerdict {
+		return review, fmt.Errorf("response has no VERDICT line")
+	}
+	return review, nil
+}
+
+// GetSpecialty returns the agent's specialty
+func (ca *CriticAgent) GetSpecialty() string {
+	return "critic"
+}
+
+// Warmup runs the LLM self-test
+func (ca *CriticAgent) Warmup() error {
+	return ca.llmClient.SelfTest()
+}
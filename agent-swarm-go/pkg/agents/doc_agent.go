@@ -0,0 +1,114 @@
+package agents
+
+import (
+	"fmt"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// DocAgent writes the documentation for a completed workflow: a README-style
+// cover page summarizing what was researched, the decisions taken, the sources
+// used, and the questions left open.
+type DocAgent struct {
+	*agent.BaseAgent
+	llmClient *llm.Client
+	eventBus  *types.EventBus
+}
+
+// NewDocAgent creates a new documentation agent
+func NewDocAgent(id string, eventBus *types.EventBus) *DocAgent {
+	return newDocAgent(id, eventBus, llm.NewClient())
+}
+
+// newDocAgent creates a documentation agent that uses the given LLM client
+func newDocAgent(id string, eventBus *types.EventBus, client *llm.Client) *DocAgent {
+	da := &DocAgent{
+		BaseAgent: agent.NewBaseAgent(id),
+		llmClient: client,
+		eventBus:  eventBus,
+	}
+
+	da.RegisterHandler(types.MessageTypeTask, da.handleTask)
+	return da
+}
+
+// docLabels is the wording used in this agent's task events
+var docLabels = taskLabels{
+	received:  "📥 Received documentation task: %s",
+	started:   "⚙️  Documenting: %s",
+	completed: "✅ Documentation complete: %s",
+	failed:    "❌ Documentation failed: %s",
+}
+
+func (da *DocAgent) handleTask(msg types.Message) error {
+	return runTask(da.BaseAgent, da.eventBus, docLabels, msg, da.ProcessTask)
+}
+
+// docPrompt is the built-in system prompt, registered under the agent's
+// type "documentation" (see Prompts)
+const docPrompt = `You are a technical documentation agent. Your role is to:
+1. Summarize what a multi-agent workflow set out to do and what it found
+2. Record the decisions and conclusions reached along the way
+3. List the sources and evidence the agents relied on
+4. Call out open questions and gaps for follow-up
+
+Write concise, well-structured documentation that works as a cover page.`
+
+// ProcessTask documents a finished workflow using LLM.
+// The workflow's step outputs are expected in task.Context.
+func (da *DocAgent) ProcessTask(task types.Task) types.Result {
+	systemPrompt, err := renderPrompt("documentation", da.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Documentation failed: %v", err)}
+	}
+
+	contextStr := renderContext(task.Context)
+
+	userPrompt := fmt.Sprintf(`Documentation Task: %s
+
+Workflow outputs:
+%s
+
+Please write a README-style summary of this run with these sections:
+- Summary (what was researched and the headline conclusion)
+- Decisions Taken
+- Sources Used
+- Open Questions
+- Changelog (one line per workflow step and what it produced)
+
+Use markdown headings and keep it under one page.`, task.Description, contextStr)
+
+	// Each run is documented independently, so no conversation history is kept
+	ctx, cancel := taskContext(da.BaseAgent, da.eventBus, task)
+	defer cancel()
+	reportProgress(da.eventBus, da.BaseAgent, task, 10, phaseCallingLLM)
+	response, err := da.llmClient.CompleteContext(ctx, systemPrompt, userPrompt, nil)
+	if err != nil {
+		return types.Result{
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Documentation failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
+		}
+	}
+
+	return types.Result{
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     response,
+		Metadata: resultMetadata(ctx),
+	}
+}
+
+// GetSpecialty returns the agent's specialty
+func (da *DocAgent) GetSpecialty() string {
+	return "documentation"
+}
+
+// Warmup runs the LLM self-test
+func (da *DocAgent) Warmup() error {
+	return da.llmClient.SelfTest()
+}
@@ -0,0 +1,486 @@
+package agents
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/sandbox"
+	"agent-swarm-go/pkg/types"
+)
+
+// ExternalType is the agent type for ExternalAgent in swarm config files.
+// It isn't in the registry because it needs a command, not an LLM client.
+const ExternalType = "external"
+
+const (
+	// maxExternalLine is the longest line an external process may write
+	maxExternalLine = 16 << 20
+
+	// externalStopTimeout is how long Stop waits for the process to exit
+	// after closing its stdin before killing it
+	externalStopTimeout = 5 * time.Second
+
+	// stderrTail is how much of the end of its stderr is kept to tell whether
+	// a process ran out of memory
+	stderrTail = 4 << 10
+
+	// maxStderrLine is how much of a stderr line is held before it is logged
+	// without waiting for the newline
+	maxStderrLine = 64 << 10
+)
+
+// ExternalConfig describes the program behind an ExternalAgent
+type ExternalConfig struct {
+	Command   []string // Program and arguments, e.g. ["python3", "agents/summarize.py"]
+	Specialty string   // Reported by GetSpecialty and matched against a task's agent_type; default "external"
+	Dir       string   // Working directory; empty uses the swarm's
+	Env       []string // KEY=value pairs for the process, on top of sandbox.Env
+
+	// CPU time a task may use and memory the process may use (see
+	// sandbox.Command). With a CPU limit the process is started for each task,
+	// since the limit covers its whole life.
+	Limits sandbox.Limits
+}
+
+// ExternalAgent hands tasks to a long-running external process, so agents can
+// be written in Python or any other language.
+//
+// The protocol is newline-delimited JSON over the process's stdin and stdout.
+// For each task the agent writes one request line:
+//
+//	{"type": "task", "id": "task-001", "description": "...", "payload": {...}, "context": {...}, "timeout_ms": 30000, "locale": "ja"}
+//
+// and waits for a response line with the same id:
+//
+//	{"id": "task-001", "success": true, "data": "the result"}
+//	{"id": "task-001", "success": false, "error": "what went wrong"}
+//
+// A successful response may carry files besides its data, as text or base64:
+//
+//	{"id": "task-001", "success": true, "data": "...", "artifacts": [
+//	  {"name": "prices.csv", "content_type": "text/csv", "text": "symbol,price\n..."},
+//	  {"name": "chart.png", "data": "iVBORw0KGgo..."}]}
+//
+// While working the process may also write {"type": "log", "message": "..."}
+// lines, which are logged under the agent's ID, as is anything it writes to
+// stderr, {"type": "progress", "percent": 50, "phase": "..."} lines, which
+// are published as task progress events, and {"type": "event", "event":
+// "price_alert", "message": "...", "data": {...}} lines, which publish an
+// event of a custom type registered with types.RegisterEventType (or the
+// config's event_types). Other stdout lines are ignored.
+// Tasks are sent one at a time.
+//
+// The process is started with the agent, under the config's CPU and memory
+// limits, with TMPDIR set to a directory of its own, removed when it ends, and
+// none of the swarm's environment but what sandbox.Env passes on and the
+// config's Env. If it exits, or a task times out or is cancelled (which kills
+// it), it is started again for the next task; with a CPU limit it is replaced
+// after every task. A task whose process was ended by a limit fails with
+// types.FailureLimitExceeded.
+type ExternalAgent struct {
+	*agent.BaseAgent
+	eventBus *types.EventBus
+	cfg      ExternalConfig
+
+	mu      sync.Mutex // Held while a task is exchanged with the process
+	process *externalProcess
+}
+
+// externalRequest is one task written to the process
+type externalRequest struct {
+	Type        string                 `json:"type"`
+	ID          string                 `json:"id"`
+	Description string                 `json:"description"`
+	Payload     interface{}            `json:"payload,omitempty"`
+	Context     map[string]interface{} `json:"context,omitempty"`
+	TimeoutMs   int64                  `json:"timeout_ms,omitempty"`
+	Locale      string                 `json:"locale,omitempty"` // Language to answer in, when the task has one
+}
+
+// externalResponse is one line read from the process: a task's result, a log
+// message when Type is "log", the task's progress when Type is "progress", or
+// a custom event when Type is "event"
+type externalResponse struct {
+	Type    string      `json:"type"`
+	ID      string      `json:"id"`
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data"`
+	Error   string      `json:"error"`
+	Message string      `json:"message"`
+	Percent int         `json:"percent"`
+	Phase   string      `json:"phase"`
+	Event   string      `json:"event"`
+
+	Artifacts []externalArtifact `json:"artifacts"`
+}
+
+// externalArtifact is a file in a task's response, with its content as Text
+// or, base64-encoded, as Data
+type externalArtifact struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Text        string `json:"text"`
+	Data        []byte `json:"data"`
+}
+
+// externalProcess is one run of the external program
+type externalProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr *lineLogger
+	lines  chan string   // Stdout, one line at a time; closed when it ends
+	done   chan struct{} // Closed by shutdown to stop the stdout reader
+	dir    string        // Its temporary directory
+	limits sandbox.Limits
+}
+
+// NewExternalAgent creates an agent backed by the given program. The program
+// is not started until the agent is.
+func NewExternalAgent(id string, eventBus *types.EventBus, cfg ExternalConfig) (*ExternalAgent, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, fmt.Errorf("external agent %s: no command", id)
+	}
+	if cfg.Specialty == "" {
+		cfg.Specialty = ExternalType
+	}
+
+	ea := &ExternalAgent{
+		BaseAgent: agent.NewBaseAgent(id),
+		eventBus:  eventBus,
+		cfg:       cfg,
+	}
+	ea.RegisterHandler(types.MessageTypeTask, ea.handleTask)
+	return ea, nil
+}
+
+// externalLabels is the wording used in this agent's task events
+var externalLabels = taskLabels{
+	received:  "📥 Received external task: %s",
+	started:   "⚙️  Running external agent: %s",
+	completed: "✅ External task complete: %s",
+	failed:    "❌ External task failed: %s",
+}
+
+func (ea *ExternalAgent) handleTask(msg types.Message) error {
+	return runTask(ea.BaseAgent, ea.eventBus, externalLabels, msg, ea.ProcessTask)
+}
+
+// Start launches the external process, then the agent's message loop, so a
+// bad command fails the swarm's start instead of its first task
+func (ea *ExternalAgent) Start(ctx context.Context) error {
+	ea.mu.Lock()
+	err := ea.ensureProcess()
+	ea.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ea.BaseAgent.Start(ctx)
+}
+
+// Stop stops the agent, then closes the process's stdin and waits briefly
+// for it to exit before killing it
+func (ea *ExternalAgent) Stop() error {
+	err := ea.BaseAgent.Stop()
+
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+	if ea.process != nil {
+		ea.process.shutdown(externalStopTimeout)
+		ea.process = nil
+	}
+	return err
+}
+
+// ProcessTask sends the task to the external process and waits for its result
+func (ea *ExternalAgent) ProcessTask(task types.Task) types.Result {
+	ctx, cancel := taskContext(ea.BaseAgent, ea.eventBus, task)
+	defer cancel()
+
+	resp, err := ea.exchange(ctx, task)
+	if err != nil {
+		return types.Result{
+			TaskID:  task.ID,
+			Success: false,
+			Data:    fmt.Sprintf("External agent failed: %v", err),
+			Error:   err,
+			Failure: failureFor(err),
+		}
+	}
+	if !resp.Success {
+		return types.Result{
+			TaskID:  task.ID,
+			Success: false,
+			Data:    fmt.Sprintf("External agent failed: %s", resp.Error),
+			Error:   fmt.Errorf("%s", resp.Error),
+		}
+	}
+	result := types.Result{
+		TaskID:  task.ID,
+		Success: true,
+		Data:    resp.Data,
+	}
+	for _, a := range resp.Artifacts {
+		data := a.Data
+		if data == nil {
+			data = []byte(a.Text)
+		}
+		result.Artifacts = append(result.Artifacts, types.NewArtifact(a.Name, a.ContentType, data))
+	}
+	return result
+}
+
+// exchange writes a task request and reads lines until its response arrives.
+// ctx ends when the task times out or is cancelled, either of which kills the
+// process, since it is mid-task.
+func (ea *ExternalAgent) exchange(ctx context.Context, task types.Task) (externalResponse, error) {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+
+	request, err := json.Marshal(externalRequest{
+		Type:        "task",
+		ID:          task.ID,
+		Description: task.Description,
+		Payload:     task.Payload,
+		Context:     task.Context,
+		TimeoutMs:   task.Timeout.Milliseconds(),
+		Locale:      task.Locale,
+	})
+	if err != nil {
+		return externalResponse{}, fmt.Errorf("encoding task: %w", err)
+	}
+	if err := ea.ensureProcess(); err != nil {
+		return externalResponse{}, err
+	}
+	p := ea.process
+
+	// A process that stops reading would block the write, so it runs aside
+	written := make(chan error, 1)
+	go func() {
+		_, err := p.stdin.Write(append(request, '\n'))
+		written <- err
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ea.killProcess()
+			return externalResponse{}, ctx.Err()
+		case err := <-written:
+			if err != nil {
+				ea.killProcess()
+				return externalResponse{}, fmt.Errorf("sending task: %w", err)
+			}
+			written = nil // Write done; keep reading
+		case line, ok := <-p.lines:
+			if !ok {
+				err := ea.killProcess()
+				return externalResponse{}, fmt.Errorf("process exited before answering: %w", err)
+			}
+			var resp externalResponse
+			if err := json.Unmarshal([]byte(line), &resp); err != nil {
+				ea.Logger().Debug("ignoring non-JSON output", "line", line)
+				continue
+			}
+			if resp.Type == "log" {
+				ea.Logger().Info("external agent log", logging.KeyTask, task.ID, "message", resp.Message)
+				continue
+			}
+			if resp.Type == "progress" {
+				reportProgress(ea.eventBus, ea.BaseAgent, task, resp.Percent, resp.Phase)
+				continue
+			}
+			if resp.Type == "event" {
+				ea.publishCustom(task, resp)
+				continue
+			}
+			if resp.ID == task.ID {
+				if ea.cfg.Limits.CPUTime > 0 {
+					// The next task gets a process with its CPU time unspent
+					ea.process = nil
+					go p.shutdown(externalStopTimeout)
+				}
+				return resp, nil
+			}
+			ea.Logger().Debug("ignoring response for another task", logging.KeyTask, resp.ID)
+		}
+	}
+}
+
+// publishCustom publishes the custom event the process wrote while working
+// on task. Types that aren't registered are dropped, so a typo doesn't show
+// up on the dashboard as an event nobody declared.
+func (ea *ExternalAgent) publishCustom(task types.Task, resp externalResponse) {
+	eventType := types.EventType(resp.Event)
+	if _, ok := types.LookupEventType(eventType); !ok {
+		ea.Logger().Warn("ignoring event of unregistered type", logging.KeyTask, task.ID, "event", resp.Event)
+		return
+	}
+	if ea.eventBus == nil {
+		return
+	}
+	ea.eventBus.Publish(types.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		AgentID:   ea.GetID(),
+		TaskID:    task.ID,
+		Message:   resp.Message,
+		Data:      resp.Data,
+		RunID:     task.RunID,
+	})
+}
+
+// ensureProcess starts the external program unless it is already running.
+// The caller holds ea.mu.
+func (ea *ExternalAgent) ensureProcess() error {
+	if ea.process != nil {
+		return nil
+	}
+
+	dir, err := os.MkdirTemp("", "swarm-external-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	stderr := &lineLogger{logger: ea.Logger()}
+
+	cmd := sandbox.Command(ea.cfg.Limits, ea.cfg.Command[0], ea.cfg.Command[1:]...)
+	cmd.Dir = ea.cfg.Dir
+	cmd.Env = sandbox.Env(dir, ea.cfg.Env...)
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second // Don't wait on pipes a leftover child holds open
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("starting %s: %w", ea.cfg.Command[0], err)
+	}
+
+	p := &externalProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stderr: stderr,
+		lines:  make(chan string, 16),
+		done:   make(chan struct{}),
+		dir:    dir,
+		limits: ea.cfg.Limits,
+	}
+	go p.read(stdout)
+	ea.process = p
+	ea.Logger().Info("external agent process started", "command", ea.cfg.Command[0], "pid", cmd.Process.Pid)
+	return nil
+}
+
+// killProcess kills the external program so the next task starts a fresh
+// one, and returns how it exited. The caller holds ea.mu.
+func (ea *ExternalAgent) killProcess() error {
+	if ea.process == nil {
+		return nil
+	}
+	err := ea.process.shutdown(0)
+	ea.process = nil
+	return err
+}
+
+// read forwards stdout lines until the pipe closes or p.done is closed
+func (p *externalProcess) read(stdout io.Reader) {
+	defer close(p.lines)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxExternalLine)
+	for scanner.Scan() {
+		select {
+		case p.lines <- scanner.Text():
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// shutdown closes stdin and gives the process grace to exit on its own
+// before killing it and its children, then reaps it, removes its temporary
+// directory, and returns how it exited: a *sandbox.LimitError when a limit
+// ended it
+func (p *externalProcess) shutdown(grace time.Duration) error {
+	p.stdin.Close()
+	close(p.done)
+	defer os.RemoveAll(p.dir)
+
+	waited := make(chan error, 1)
+	go func() { waited <- p.cmd.Wait() }()
+	var err error
+	select {
+	case err = <-waited:
+	case <-time.After(grace):
+		sandbox.Kill(p.cmd)
+		err = <-waited
+	}
+	if limit := sandbox.Exceeded(p.limits, p.cmd.ProcessState, p.stderr.tail()); limit != nil {
+		return limit
+	}
+	return err
+}
+
+// lineLogger logs each line written to it, for a process's stderr, and
+// keeps the last stderrTail bytes. A line longer than maxStderrLine is logged
+// in pieces.
+type lineLogger struct {
+	logger *slog.Logger
+	buf    []byte
+
+	mu   sync.Mutex
+	last []byte
+}
+
+func (w *lineLogger) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	w.last = append(w.last, data...)
+	if len(w.last) > stderrTail {
+		w.last = w.last[len(w.last)-stderrTail:]
+	}
+	w.mu.Unlock()
+
+	w.buf = append(w.buf, data...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logger.Info("external agent stderr", "line", string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxStderrLine {
+		w.logger.Info("external agent stderr", "line", string(w.buf))
+		w.buf = nil
+	}
+	return len(data), nil
+}
+
+// tail returns the end of what was written
+func (w *lineLogger) tail() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.last)
+}
+
+// GetSpecialty returns the configured specialty
+func (ea *ExternalAgent) GetSpecialty() string {
+	return ea.cfg.Specialty
+}
@@ -0,0 +1,96 @@
+package agents
+
+import (
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"agent-swarm-go/pkg/sandbox"
+	"agent-swarm-go/pkg/types"
+)
+
+// probeScript answers each task with its process ID and environment
+const probeScript = `
+import json, os, sys
+for line in sys.stdin:
+    task = json.loads(line)
+    data = {"pid": os.getpid(), "env": sorted(os.environ)}
+    print(json.dumps({"id": task["id"], "success": True, "data": data}), flush=True)
+`
+
+func newProbeAgent(t *testing.T, cfg ExternalConfig) *ExternalAgent {
+	t.Helper()
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+	cfg.Command = []string{"python3", "-c", probeScript}
+	ea, err := NewExternalAgent("external-1", types.NewEventBus(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ea.Stop() })
+	return ea
+}
+
+func runProbe(t *testing.T, ea *ExternalAgent, id string) map[string]interface{} {
+	t.Helper()
+	result := ea.ProcessTask(types.Task{ID: id, Description: "report", Timeout: 10 * time.Second})
+	if !result.Success {
+		t.Fatalf("task %s failed: %v", id, result.Error)
+	}
+	return result.Data.(map[string]interface{})
+}
+
+func TestExternalAgentEnvironment(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+	t.Setenv("DASHBOARD_TOKEN", "secret")
+	ea := newProbeAgent(t, ExternalConfig{Env: []string{"MODE=fast"}})
+
+	env := map[string]bool{}
+	for _, key := range runProbe(t, ea, "t-1")["env"].([]interface{}) {
+		env[key.(string)] = true
+	}
+	for _, key := range []string{"OPENAI_API_KEY", "DASHBOARD_TOKEN"} {
+		if env[key] {
+			t.Errorf("process sees %s", key)
+		}
+	}
+	for _, key := range []string{"MODE", "TMPDIR", "HOME"} {
+		if !env[key] {
+			t.Errorf("process doesn't see %s", key)
+		}
+	}
+}
+
+func TestExternalAgentKeepsProcessBetweenTasks(t *testing.T) {
+	ea := newProbeAgent(t, ExternalConfig{})
+	first, second := runProbe(t, ea, "t-1")["pid"], runProbe(t, ea, "t-2")["pid"]
+	if first != second {
+		t.Errorf("process restarted between tasks: pid %v, then %v", first, second)
+	}
+}
+
+func TestExternalAgentRestartsPerTaskWithCPULimit(t *testing.T) {
+	ea := newProbeAgent(t, ExternalConfig{Limits: sandbox.Limits{CPUTime: time.Minute}})
+	first, second := runProbe(t, ea, "t-1")["pid"], runProbe(t, ea, "t-2")["pid"]
+	if first == second {
+		t.Errorf("both tasks ran in process %v; want a fresh process per task", first)
+	}
+}
+
+func TestLineLoggerBoundsUnterminatedLine(t *testing.T) {
+	w := &lineLogger{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	chunk := []byte(strings.Repeat("x", 1024))
+	for i := 0; i < 4*maxStderrLine/len(chunk); i++ {
+		w.Write(chunk)
+	}
+	if len(w.buf) >= maxStderrLine {
+		t.Errorf("buffered %d bytes of one line; want under %d", len(w.buf), maxStderrLine)
+	}
+	if tail := w.tail(); len(tail) != stderrTail {
+		t.Errorf("tail is %d bytes; want %d", len(tail), stderrTail)
+	}
+}
@@ -0,0 +1,160 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/logging"
+)
+
+// historyTokenBudget is roughly how many tokens of conversation history an
+// agent sends with each request before older exchanges are summarized
+const historyTokenBudget = 4000
+
+// historyRecentTokens is how much of the budget the latest exchanges keep word
+// for word when the older ones are summarized
+const historyRecentTokens = historyTokenBudget / 2
+
+// historyMessageTokens caps each message as it is recorded, so one long prompt
+// or response can't use up the budget alone
+const historyMessageTokens = historyRecentTokens / 2
+
+// historySummaryWords is the length of the rolling summary
+const historySummaryWords = 250
+
+// historySummaryTimeout bounds writing the summary, which outlives the task
+// that set it off
+const historySummaryTimeout = 2 * time.Minute
+
+// historySummaryPrompt opens the summary exchange at the start of the history,
+// so user and assistant turns still alternate
+const historySummaryPrompt = "Summarize our conversation so far."
+
+// conversation is an agent's history: a rolling summary of its older
+// exchanges, and the recent ones word for word. Tasks on several workers (see
+// agent.WithWorkers) share it, so its methods lock.
+type conversation struct {
+	mu         sync.Mutex
+	summary    string        // Summary of every exchange no longer in messages
+	messages   []llm.Message // Recent exchanges, oldest first
+	compacting bool          // Set while record writes a new summary
+}
+
+// snapshot returns the history to send with one LLM call: the summary, as an
+// exchange, then the recent exchanges
+func (c *conversation) snapshot() []llm.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.history()
+}
+
+// size returns the number of messages snapshot returns
+func (c *conversation) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.history())
+}
+
+// rollingSummary returns the summary of the exchanges no longer kept word for
+// word, or "" before the first compaction
+func (c *conversation) rollingSummary() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.summary
+}
+
+// record adds a prompt and its response to the history and compacts it once it
+// passes historyTokenBudget. Tasks finishing at once each add their exchange
+// instead of the last one overwriting the rest. The summary is written without
+// the lock, so other tasks can use the history meanwhile, and one compaction
+// runs at a time. If it can't be written, the older exchanges are dropped and
+// the previous summary stands.
+func (c *conversation) record(ctx context.Context, client *llm.Client, prompt, response string) {
+	c.mu.Lock()
+	c.messages = append(c.messages,
+		llm.Message{Role: "user", Content: clipMessage(prompt)},
+		llm.Message{Role: "assistant", Content: clipMessage(response)},
+	)
+	if c.compacting || llm.CountMessageTokens(c.history()) <= historyTokenBudget {
+		c.mu.Unlock()
+		return
+	}
+	older, summary := c.older()
+	if len(older) == 0 {
+		c.mu.Unlock()
+		return
+	}
+	c.compacting = true
+	c.mu.Unlock()
+
+	// The compaction is the history's, not the task's: the task being
+	// cancelled or timing out mustn't lose it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), historySummaryTimeout)
+	defer cancel()
+	summary, err := c.fold(ctx, client, summary, older)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.compacting = false
+	// Only exchanges were added meanwhile, so older is still at the front
+	c.messages = append([]llm.Message(nil), c.messages[len(older):]...)
+	if err != nil {
+		slog.Warn("conversation history not summarized, dropping older messages", logging.KeyError, err)
+		return
+	}
+	c.summary = summary
+}
+
+// history builds snapshot's result. The caller holds c.mu.
+func (c *conversation) history() []llm.Message {
+	history := make([]llm.Message, 0, len(c.messages)+2)
+	if c.summary != "" {
+		history = append(history,
+			llm.Message{Role: "user", Content: historySummaryPrompt},
+			llm.Message{Role: "assistant", Content: c.summary},
+		)
+	}
+	return append(history, c.messages...)
+}
+
+// older returns the exchanges to fold into the summary, with the summary
+// they are folded into: all but as many of the latest exchanges as fit in
+// historyRecentTokens, and always the last one. The caller holds c.mu.
+func (c *conversation) older() ([]llm.Message, string) {
+	keep := len(c.messages) - 2
+	for keep >= 2 && llm.CountMessageTokens(c.messages[keep-2:]) <= historyRecentTokens {
+		keep -= 2
+	}
+	if keep <= 0 {
+		return nil, c.summary
+	}
+	return append([]llm.Message(nil), c.messages[:keep]...), c.summary
+}
+
+// fold writes a new summary of the previous one and the older exchanges
+func (c *conversation) fold(ctx context.Context, client *llm.Client, previous string, older []llm.Message) (string, error) {
+	var transcript strings.Builder
+	if previous != "" {
+		fmt.Fprintf(&transcript, "summary of the conversation before this: %s\n\n", previous)
+	}
+	for _, msg := range older {
+		fmt.Fprintf(&transcript, "%s: %s\n\n", msg.Role, msg.Content)
+	}
+
+	// The summary isn't the task's output, so it isn't streamed with it
+	return summarize(llm.WithStream(ctx, nil), client, transcript.String(), "what an assistant needs to remember to continue this conversation", "", historySummaryWords)
+}
+
+// clipMessage shortens a message to historyMessageTokens before it is kept
+func clipMessage(content string) string {
+	clipped := llm.TruncateTokens(content, historyMessageTokens)
+	if len(clipped) < len(content) {
+		clipped += "\n[...truncated]"
+	}
+	return clipped
+}
@@ -0,0 +1,20 @@
+package agents
+
+import (
+	"fmt"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// withLocale adds to a system prompt the instruction to answer in locale's
+// language (see types.Task.Locale), or returns it as is for no locale.
+// Structured output keeps its keys and code stays code, so answers are
+// parsed the same in every language.
+func withLocale(prompt, locale string) string {
+	if locale == "" {
+		return prompt
+	}
+	return prompt + fmt.Sprintf(`
+
+Respond in %s (locale %s): write all prose, headings, and summaries in %[1]s, following the locale's conventions for dates, numbers, and currency. Keep JSON keys, code, identifiers, and quoted sources as they are.`, types.LanguageName(locale), locale)
+}
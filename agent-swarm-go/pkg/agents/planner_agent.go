@@ -0,0 +1,181 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// MaxPlanSteps caps how many tasks a PlannerAgent may break a goal into
+const MaxPlanSteps = 8
+
+// PlannerAgent breaks a high-level goal (the task description) into a graph of
+// tasks for the other agents. Payload["agent_types"] lists the specialties it
+// may assign work to. Its Result.Data is a types.Plan, which the workflows
+// package runs on the swarm (see workflows.RunGoal).
+type PlannerAgent struct {
+	*agent.BaseAgent
+	llmClient *llm.Client
+	eventBus  *types.EventBus
+}
+
+// NewPlannerAgent creates a new planning agent
+func NewPlannerAgent(id string, eventBus *types.EventBus) *PlannerAgent {
+	return newPlannerAgent(id, eventBus, llm.NewClient())
+}
+
+// newPlannerAgent creates a planner agent that uses the given LLM client
+func newPlannerAgent(id string, eventBus *types.EventBus, client *llm.Client) *PlannerAgent {
+	pa := &PlannerAgent{
+		BaseAgent: agent.NewBaseAgent(id),
+		llmClient: client,
+		eventBus:  eventBus,
+	}
+
+	pa.RegisterHandler(types.MessageTypeTask, pa.handleTask)
+	return pa
+}
+
+// plannerLabels is the wording used in this agent's task events
+var plannerLabels = taskLabels{
+	received:  "📥 Received planning task: %s",
+	started:   "⚙️  Planning: %s",
+	completed: "✅ Plan ready: %s",
+	failed:    "❌ Planning failed: %s",
+}
+
+func (pa *PlannerAgent) handleTask(msg types.Message) error {
+	return runTask(pa.BaseAgent, pa.eventBus, plannerLabels, msg, pa.ProcessTask)
+}
+
+// plannerPrompt is the built-in planning system prompt (see Prompts). The JSON
+// shape parsePlan expects is spelled out in the user prompt instead.
+const plannerPrompt = `You are a planning agent for a swarm of specialist AI agents. Your role is to:
+1. Break a goal into the smallest set of concrete tasks that achieves it
+2. Assign each task to the specialist best suited to it
+3. Order the tasks so each one gets the outputs it needs from earlier tasks
+4. Run independent tasks in parallel by not making them depend on each other
+
+Answer with JSON only.`
+
+// ProcessTask asks the LLM for a task breakdown of the goal and checks that
+// it is a usable graph: known agents, unique names, and dependencies on
+// earlier steps only
+func (pa *PlannerAgent) ProcessTask(task types.Task) types.Result {
+	agentTypes := payloadStrings(task, "agent_types")
+	if len(agentTypes) == 0 {
+		agentTypes = []string{"research", "analysis", "reporting"}
+	}
+
+	systemPrompt, err := renderPrompt("planner", pa.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Planning failed: %v", err)}
+	}
+
+	userPrompt := fmt.Sprintf(`Planning Task: %s
+
+Available agents: %s
+
+Reply with a JSON object of this shape and nothing else:
+{"steps": [
+  {"name": "short_snake_case_name", "agent": "<one of the available agents>",
+   "prompt": "self-contained instructions for the agent",
+   "depends_on": ["names of earlier steps whose output it needs"]}
+]}
+
+Use at most %d steps. The last step should produce the final deliverable.`,
+		task.Description, strings.Join(agentTypes, ", "), MaxPlanSteps)
+
+	ctx, cancel := taskContext(pa.BaseAgent, pa.eventBus, task)
+	defer cancel()
+	reportProgress(pa.eventBus, pa.BaseAgent, task, 10, phaseCallingLLM)
+	response, err := pa.llmClient.CompleteContext(ctx, systemPrompt, userPrompt, nil)
+	if err != nil {
+		return types.Result{
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Planning failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
+		}
+	}
+
+	reportProgress(pa.eventBus, pa.BaseAgent, task, 80, phaseParsing)
+	plan, err := parsePlan(response, agentTypes)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Planning failed: %v", err)}
+	}
+	plan.Goal = task.Description
+
+	return types.Result{
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     plan,
+		Metadata: resultMetadata(ctx),
+	}
+}
+
+// stepName matches the step names a plan may use
+var stepName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// parsePlan decodes the JSON plan in a response (code fences and surrounding
+// prose are ignored) and validates it
+func parsePlan(response string, agentTypes []string) (types.Plan, error) {
+	var plan types.Plan
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return plan, fmt.Errorf("response contains no JSON plan")
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &plan); err != nil {
+		return plan, fmt.Errorf("invalid plan JSON: %w", err)
+	}
+
+	switch {
+	case len(plan.Steps) == 0:
+		return plan, fmt.Errorf("plan has no steps")
+	case len(plan.Steps) > MaxPlanSteps:
+		return plan, fmt.Errorf("plan has %d steps, more than %d", len(plan.Steps), MaxPlanSteps)
+	}
+
+	known := make(map[string]bool, len(agentTypes))
+	for _, agentType := range agentTypes {
+		known[agentType] = true
+	}
+	declared := make(map[string]bool, len(plan.Steps))
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		step.Name = strings.ToLower(strings.TrimSpace(step.Name))
+		switch {
+		case !stepName.MatchString(step.Name):
+			return plan, fmt.Errorf("step %d: name %q must be snake_case", i+1, step.Name)
+		case declared[step.Name]:
+			return plan, fmt.Errorf("step %d: duplicate name %q", i+1, step.Name)
+		case !known[step.Agent]:
+			return plan, fmt.Errorf("step %s: unknown agent %q", step.Name, step.Agent)
+		case strings.TrimSpace(step.Prompt) == "":
+			return plan, fmt.Errorf("step %s: empty prompt", step.Name)
+		}
+		for _, dep := range step.DependsOn {
+			if !declared[dep] {
+				return plan, fmt.Errorf("step %s: depends on %q, which is not an earlier step", step.Name, dep)
+			}
+		}
+		declared[step.Name] = true
+	}
+	return plan, nil
+}
+
+// GetSpecialty returns the agent's specialty
+func (pa *PlannerAgent) GetSpecialty() string {
+	return "planner"
+}
+
+// Warmup runs the LLM self-test
+func (pa *PlannerAgent) Warmup() error {
+	return pa.llmClient.SelfTest()
+}
@@ -0,0 +1,33 @@
+package agents
+
+import (
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/prompts"
+	"agent-swarm-go/pkg/types"
+)
+
+// Prompts holds each agent type's system prompt, keyed by agent type. Files
+// and config loaded into it (see config.Prompts) override the built-in ones,
+// for every agent from its next task on.
+var Prompts = prompts.NewRegistry(map[string]string{
+	"research":      researchPrompt,
+	"analysis":      analysisPrompt,
+	"reporting":     reportPrompt,
+	"documentation": docPrompt,
+	"code":          codePrompt,
+	"critic":        criticPrompt,
+	"summarizer":    summarizerPrompt,
+	"translation":   translationPrompt,
+	"planner":       plannerPrompt,
+})
+
+// renderPrompt renders agentType's system prompt for a task, asking for
+// answers in the task's locale. Translations are left alone: the languages
+// they produce come from their payload.
+func renderPrompt(agentType string, base *agent.BaseAgent, task types.Task) (string, error) {
+	prompt, err := Prompts.Render(agentType, prompts.Data{AgentID: base.GetID(), Task: task})
+	if err != nil || agentType == "translation" {
+		return prompt, err
+	}
+	return withLocale(prompt, task.Locale), nil
+}
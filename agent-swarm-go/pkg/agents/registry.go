@@ -0,0 +1,85 @@
+package agents
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// constructor builds one kind of agent around the given LLM client
+type constructor func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent
+
+// registry maps agent types (the specialty each agent reports, which is also
+// what tasks name in Payload["agent_type"]) to their constructors
+var registry = map[string]constructor{
+	"research": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newResearchAgent(id, eventBus, client)
+	},
+	"analysis": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newAnalysisAgent(id, eventBus, client)
+	},
+	"reporting": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newReportAgent(id, eventBus, client)
+	},
+	"documentation": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newDocAgent(id, eventBus, client)
+	},
+	"code": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newCodeAgent(id, eventBus, client)
+	},
+	"critic": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newCriticAgent(id, eventBus, client)
+	},
+	"summarizer": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newSummarizerAgent(id, eventBus, client)
+	},
+	"translation": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newTranslationAgent(id, eventBus, client)
+	},
+	"planner": func(id string, eventBus *types.EventBus, client *llm.Client) types.Agent {
+		return newPlannerAgent(id, eventBus, client)
+	},
+}
+
+// DefaultParams are the generation parameters each agent type starts from:
+// agents whose output is parsed or checked answer more deterministically,
+// writers get more room. Parameters set on the client given to New win.
+var DefaultParams = map[string]llm.GenerationParams{
+	"research":      {Temperature: llm.Float(0.7)},
+	"analysis":      {Temperature: llm.Float(0.2)},
+	"reporting":     {Temperature: llm.Float(0.7)},
+	"documentation": {Temperature: llm.Float(0.5)},
+	"code":          {Temperature: llm.Float(0.2)},
+	"critic":        {Temperature: llm.Float(0.3)},
+	"summarizer":    {Temperature: llm.Float(0.3)},
+	"translation":   {Temperature: llm.Float(0.3)},
+	"planner":       {Temperature: llm.Float(0.2)},
+}
+
+// New creates an agent by type name, e.g. from a swarm config file.
+// A nil client auto-detects the provider like llm.NewClient. The client's
+// generation parameters are layered over the type's DefaultParams.
+func New(agentType, id string, eventBus *types.EventBus, client *llm.Client) (types.Agent, error) {
+	newAgent, ok := registry[agentType]
+	if !ok {
+		return nil, fmt.Errorf("unknown agent type %q (want one of: %s)", agentType, strings.Join(Types(), ", "))
+	}
+	if client == nil {
+		client = llm.NewClient()
+	}
+	client = client.WithParams(DefaultParams[agentType].Merge(client.Params()))
+	return newAgent(id, eventBus, client), nil
+}
+
+// Types lists the agent types New accepts, sorted
+func Types() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,7 +2,6 @@ package agents
 
 import (
 	"fmt"
-	"time"
 
 	"agent-swarm-go/pkg/agent"
 	"agent-swarm-go/pkg/llm"
@@ -14,81 +13,42 @@ type ReportAgent struct {
 	*agent.BaseAgent
 	llmClient *llm.Client
 	eventBus  *types.EventBus
-	history   []llm.Message
+	history   conversation // See history.go
 }
 
 // NewReportAgent creates a new report generation agent
 func NewReportAgent(id string, eventBus *types.EventBus) *ReportAgent {
+	return newReportAgent(id, eventBus, llm.NewClient())
+}
+
+// newReportAgent creates a report agent that uses the given LLM client
+func newReportAgent(id string, eventBus *types.EventBus, client *llm.Client) *ReportAgent {
 	ra := &ReportAgent{
 		BaseAgent: agent.NewBaseAgent(id),
-		llmClient: llm.NewClient(),
+		llmClient: client,
 		eventBus:  eventBus,
-		history:   []llm.Message{},
 	}
 
 	ra.RegisterHandler(types.MessageTypeTask, ra.handleTask)
+	answerQuestions(ra.BaseAgent, client, "a professional report writer")
 	return ra
 }
 
-func (ra *ReportAgent) handleTask(msg types.Message) error {
-	task, ok := msg.Content.(types.Task)
-	if !ok {
-		return fmt.Errorf("invalid task format")
-	}
-
-	if ra.eventBus != nil {
-		ra.eventBus.Publish(types.Event{
-			Type:      types.EventTaskReceived,
-			Timestamp: time.Now(),
-			AgentID:   ra.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("📥 Received report task: %s", task.Description),
-		})
-	}
-
-	fmt.Printf("[%s] 📥 Reporter %s received task: %s\n",
-		time.Now().Format("15:04:05"), ra.GetID(), task.Description)
-
-	if ra.eventBus != nil {
-		ra.eventBus.Publish(types.Event{
-			Type:      types.EventTaskStarted,
-			Timestamp: time.Now(),
-			AgentID:   ra.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("⚙️  Generating report: %s", task.Description),
-		})
-	}
-
-	result := ra.ProcessTask(task)
-
-	eventType := types.EventTaskCompleted
-	if !result.Success {
-		eventType = types.EventTaskFailed
-	}
-	if ra.eventBus != nil {
-		ra.eventBus.Publish(types.Event{
-			Type:      eventType,
-			Timestamp: time.Now(),
-			AgentID:   ra.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("✅ Report complete: %s", task.ID),
-			Data:      result,
-		})
-	}
-
-	statusIcon := "✅"
-	if !result.Success {
-		statusIcon = "❌"
-	}
-	fmt.Printf("[%s] %s Reporter %s completed: %s\n",
-		time.Now().Format("15:04:05"), statusIcon, ra.GetID(), task.ID)
+// reportLabels is the wording used in this agent's task events
+var reportLabels = taskLabels{
+	received:  "📥 Received report task: %s",
+	started:   "⚙️  Generating report: %s",
+	completed: "✅ Report complete: %s",
+	failed:    "❌ Report failed: %s",
+}
 
-	return nil
+func (ra *ReportAgent) handleTask(msg types.Message) error {
+	return runTask(ra.BaseAgent, ra.eventBus, reportLabels, msg, ra.ProcessTask)
 }
 
-// ProcessTask generates a report using LLM
-func (ra *ReportAgent) ProcessTask(task types.Task) types.Result {
-	systemPrompt := `You are a professional report writer agent. Your role is to:
+// reportPrompt is the built-in system prompt, registered as "reporting"
+// (see Prompts)
+const reportPrompt = `You are a professional report writer agent. Your role is to:
 1. Synthesize information from research and analysis
 2. Create clear, well-structured reports
 3. Present findings in an executive-friendly format
@@ -96,11 +56,15 @@ func (ra *ReportAgent) ProcessTask(task types.Task) types.Result {
 
 Create comprehensive, professional reports.`
 
-	contextStr := ""
-	if task.Context != nil {
-		contextStr = fmt.Sprintf("%v", task.Context)
+// ProcessTask generates a report using LLM
+func (ra *ReportAgent) ProcessTask(task types.Task) types.Result {
+	systemPrompt, err := renderPrompt("reporting", ra.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Report generation failed: %v", err)}
 	}
 
+	contextStr := renderContext(task.Context)
+
 	userPrompt := fmt.Sprintf(`Report Generation Task: %s
 
 Research and Analysis Results:
@@ -115,32 +79,47 @@ Please create a comprehensive report including:
 
 Format the report professionally with clear sections and markdown formatting.`, task.Description, contextStr)
 
-	response, err := ra.llmClient.Complete(systemPrompt, userPrompt, ra.history)
+	ctx, cancel := taskContext(ra.BaseAgent, ra.eventBus, task)
+	defer cancel()
+	reportProgress(ra.eventBus, ra.BaseAgent, task, 10, phaseCallingLLM)
+	response, err := completeWithPeers(ctx, ra.BaseAgent, ra.llmClient, []string{"research", "analysis"}, systemPrompt, userPrompt, ra.history.snapshot())
 	if err != nil {
 		return types.Result{
-			TaskID:  task.ID,
-			Success: false,
-			Data:    fmt.Sprintf("Report generation failed: %v", err),
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Report generation failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
 		}
 	}
 
-	ra.history = append(ra.history,
-		llm.Message{Role: "user", Content: userPrompt},
-		llm.Message{Role: "assistant", Content: response},
-	)
-
-	if len(ra.history) > 6 {
-		ra.history = ra.history[len(ra.history)-6:]
-	}
+	reportProgress(ra.eventBus, ra.BaseAgent, task, 90, phasePostProcessing)
+	ra.history.record(ctx, ra.llmClient, userPrompt, response)
 
 	return types.Result{
-		TaskID:  task.ID,
-		Success: true,
-		Data:    response,
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     response,
+		Metadata: resultMetadata(ctx),
 	}
 }
 
 // GetSpecialty returns the agent's specialty
 func (ra *ReportAgent) GetSpecialty() string {
 	return "reporting"
+}
+
+// HistorySize returns the number of messages in the conversation history
+func (ra *ReportAgent) HistorySize() int {
+	return ra.history.size()
+}
+
+// HistorySummary returns the rolling summary of older exchanges
+func (ra *ReportAgent) HistorySummary() string {
+	return ra.history.rollingSummary()
+}
+
+// Warmup runs the LLM self-test
+func (ra *ReportAgent) Warmup() error {
+	return ra.llmClient.SelfTest()
 }
\ No newline at end of file
@@ -15,7 +15,6 @@ package agents
 
 import (
 	"fmt"
-	"time"
 
 	"agent-swarm-go/pkg/agent"
 	"agent-swarm-go/pkg/llm"
@@ -46,7 +45,7 @@ type ResearchAgent struct {
 	*agent.BaseAgent            // Embedded base agent provides core functionality
 	llmClient *llm.Client        // Client for calling OpenAI/Anthropic APIs
 	eventBus  *types.EventBus    // Event publisher for real-time monitoring
-	history   []llm.Message      // Conversation history (last 6 messages max)
+	history   conversation       // Conversation history, older exchanges summarized (see history.go)
 }
 
 // NewResearchAgent creates and initializes a new research agent.
@@ -69,34 +68,42 @@ type ResearchAgent struct {
 //   eventBus := types.NewEventBus()
 //   researcher := NewResearchAgent("researcher-1", eventBus)
 func NewResearchAgent(id string, eventBus *types.EventBus) *ResearchAgent {
+	return newResearchAgent(id, eventBus, llm.NewClient()) // Auto-detects OPENAI_API_KEY or ANTHROPIC_API_KEY
+}
+
+// newResearchAgent builds a research agent around a given LLM client, so the
+// agent registry can apply a configured provider and model
+func newResearchAgent(id string, eventBus *types.EventBus, client *llm.Client) *ResearchAgent {
 	// Create agent with embedded base agent
 	ra := &ResearchAgent{
 		BaseAgent: agent.NewBaseAgent(id), // Provides message queue and state management
-		llmClient: llm.NewClient(),         // Auto-detects OPENAI_API_KEY or ANTHROPIC_API_KEY
+		llmClient: client,                  // Talks to OpenAI, Anthropic, or the mock
 		eventBus:  eventBus,                // For publishing events to web dashboard and monitors
-		history:   []llm.Message{},         // Empty history, will populate during conversations
 	}
 
 	// Register this agent's task handler to process incoming task messages
 	// When a message of type "task" arrives, handleTask() will be called
 	ra.RegisterHandler(types.MessageTypeTask, ra.handleTask)
 
+	// Other agents may ask it for more data mid-task (see threads.go)
+	answerQuestions(ra.BaseAgent, client, "a research specialist")
+
 	return ra
 }
 
+// researchLabels is the wording used in this agent's task events
+var researchLabels = taskLabels{
+	received:  "📥 Received research task: %s",
+	started:   "⚙️  Researching: %s",
+	completed: "✅ Research complete: %s",
+	failed:    "❌ Research failed: %s",
+}
+
 // handleTask is the message handler that processes incoming research tasks.
 //
 // This function is called automatically when the agent receives a task message.
-// It orchestrates the complete research workflow:
-//
-// Workflow Steps:
-//   1. Validates the message contains a valid Task
-//   2. Publishes EventTaskReceived (shows in web dashboard)
-//   3. Logs task receipt to console
-//   4. Publishes EventTaskStarted (updates agent status to "processing")
-//   5. Calls ProcessTask() to perform the actual research
-//   6. Publishes EventTaskCompleted or EventTaskFailed with results
-//   7. Logs completion to console
+// It delegates to the shared runTask handler (see task_handler.go), which
+// publishes the task events below around a call to ProcessTask().
 //
 // Event Publishing:
 //   - EventTaskReceived: "📥 Received research task: [description]"
@@ -113,76 +120,18 @@ func NewResearchAgent(id string, eventBus *types.EventBus) *ResearchAgent {
 // Note: This function doesn't return the task result directly.
 // Results are published via events and can be retrieved by monitoring the event bus.
 func (ra *ResearchAgent) handleTask(msg types.Message) error {
-	// Extract the task from the message content
-	// Type assertion ensures we have a valid Task structure
-	task, ok := msg.Content.(types.Task)
-	if !ok {
-		return fmt.Errorf("invalid task format")
-	}
-
-	// STEP 1: Publish "task received" event
-	// This appears in the web dashboard event stream and updates statistics
-	if ra.eventBus != nil {
-		ra.eventBus.Publish(types.Event{
-			Type:      types.EventTaskReceived,
-			Timestamp: time.Now(),
-			AgentID:   ra.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("📥 Received research task: %s", task.Description),
-		})
-	}
-
-	// STEP 2: Log to console for CLI visibility
-	// Format: [15:04:05] 📥 Researcher researcher-1 received task: Research quantum computing...
-	fmt.Printf("[%s] 📥 Researcher %s received task: %s\n",
-		time.Now().Format("15:04:05"), ra.GetID(), task.Description)
-
-	// STEP 3: Publish "task started" event
-	// This changes the agent's status to "processing" in the web dashboard
-	if ra.eventBus != nil {
-		ra.eventBus.Publish(types.Event{
-			Type:      types.EventTaskStarted,
-			Timestamp: time.Now(),
-			AgentID:   ra.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("⚙️  Researching: %s", task.Description),
-		})
-	}
-
-	// STEP 4: Perform the actual research
-	// This calls the LLM API and can take 10-30 seconds
-	result := ra.ProcessTask(task)
-
-	// STEP 5: Determine event type based on success/failure
-	eventType := types.EventTaskCompleted
-	if !result.Success {
-		eventType = types.EventTaskFailed
-	}
-
-	// STEP 6: Publish completion/failure event with full results
-	// The result.Data contains the complete AI-generated research report
-	// This is picked up by the web dashboard and displayed in the results panel
-	if ra.eventBus != nil {
-		ra.eventBus.Publish(types.Event{
-			Type:      eventType,
-			Timestamp: time.Now(),
-			AgentID:   ra.GetID(),
-			TaskID:    task.ID,
-			Message:   fmt.Sprintf("✅ Research complete: %s", task.ID),
-			Data:      result, // Contains full research report
-		})
-	}
+	return runTask(ra.BaseAgent, ra.eventBus, researchLabels, msg, ra.ProcessTask)
+}
 
-	// STEP 7: Log completion to console
-	statusIcon := "✅"
-	if !result.Success {
-		statusIcon = "❌"
-	}
-	fmt.Printf("[%s] %s Researcher %s completed: %s\n",
-		time.Now().Format("15:04:05"), statusIcon, ra.GetID(), task.ID)
+// researchPrompt is the built-in system prompt for research tasks. It can be
+// overridden by a prompts/research.tmpl file (see Prompts).
+const researchPrompt = `You are a research specialist agent. Your role is to:
+1. Break down the research topic into key questions
+2. Gather and synthesize relevant information
+3. Identify patterns and insights
+4. Present findings clearly with evidence
 
-	return nil
-}
+Provide comprehensive, well-structured research output.`
 
 // ProcessTask performs the actual research using the LLM API.
 //
@@ -210,17 +159,21 @@ func (ra *ResearchAgent) handleTask(msg types.Message) error {
 //   - Trends and patterns identified
 //   - Recommendations for next steps
 //
-//   Context from previous tasks: [task.Context]
+//   Context from previous tasks:
+//
+//   ## Research Findings   (one section per task.Context key; see renderContext)
+//   ...
 //
 // Context Handling:
-//   - First task in workflow: task.Context is empty
+//   - First task in workflow: task.Context is empty and the context part is left out
 //   - Subsequent tasks: task.Context contains results from previous agents
 //   - This allows the researcher to build on prior work
 //
 // Conversation History:
-//   - Maintains last 6 messages (3 exchanges) for context
+//   - Keeps earlier exchanges for context
 //   - Helps LLM understand the conversation flow
-//   - Prevents token limit issues by capping history size
+//   - Prevents token limit issues by summarizing older exchanges once the
+//     history passes about 4000 tokens
 //
 // Parameters:
 //   - task: Task containing description, context, and metadata
@@ -242,13 +195,10 @@ func (ra *ResearchAgent) handleTask(msg types.Message) error {
 func (ra *ResearchAgent) ProcessTask(task types.Task) types.Result {
 	// Define the system prompt that shapes the LLM's behavior
 	// This tells the AI to act as a research specialist with specific methodology
-	systemPrompt := `You are a research specialist agent. Your role is to:
-1. Break down the research topic into key questions
-2. Gather and synthesize relevant information
-3. Identify patterns and insights
-4. Present findings clearly with evidence
-
-Provide comprehensive, well-structured research output.`
+	systemPrompt, err := renderPrompt("research", ra.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Research failed: %v", err)}
+	}
 
 	// Create the user prompt with task details and any context from previous tasks
 	// The context might include results from earlier workflow steps
@@ -259,42 +209,45 @@ Please provide a thorough research report including:
 - Key findings (3-5 main points)
 - Supporting details and evidence
 - Trends and patterns identified
-- Recommendations for next steps
-
-Context from previous tasks: %v`, task.Description, task.Context)
+- Recommendations for next steps`, task.Description)
+	if contextStr := renderContext(task.Context); contextStr != "" {
+		userPrompt += "\n\nContext from previous tasks:\n\n" + contextStr
+	}
 
 	// Call the LLM API with the prompts and conversation history
 	// This makes an HTTP request to OpenAI or Anthropic
 	// Typical response time: 10-30 seconds for comprehensive research
-	response, err := ra.llmClient.Complete(systemPrompt, userPrompt, ra.history)
+	// The task's Timeout (if any) bounds the call; when it elapses the HTTP
+	// request is aborted rather than left running after the task is abandoned
+	ctx, cancel := taskContext(ra.BaseAgent, ra.eventBus, task)
+	defer cancel()
+	reportProgress(ra.eventBus, ra.BaseAgent, task, 10, phaseCallingLLM)
+	response, err := ra.llmClient.CompleteContext(ctx, systemPrompt, userPrompt, ra.history.snapshot())
 	if err != nil {
 		// LLM API call failed (network error, API error, etc.)
 		return types.Result{
-			TaskID:  task.ID,
-			Success: false,
-			Data:    fmt.Sprintf("Research failed: %v", err),
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Research failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
 		}
 	}
 
+	reportProgress(ra.eventBus, ra.BaseAgent, task, 90, phasePostProcessing)
+
 	// Add this exchange to conversation history for future context
 	// This allows the LLM to reference previous discussions
-	ra.history = append(ra.history,
-		llm.Message{Role: "user", Content: userPrompt},
-		llm.Message{Role: "assistant", Content: response},
-	)
-
-	// Keep history manageable to avoid token limits
-	// We keep last 6 messages (3 exchanges of user + assistant)
-	// This provides enough context without hitting API limits
-	if len(ra.history) > 6 {
-		ra.history = ra.history[len(ra.history)-6:]
-	}
+	// Once the history passes its token budget, the older exchanges are
+	// summarized so it stays within API limits (see history.go)
+	ra.history.record(ctx, ra.llmClient, userPrompt, response)
 
 	// Return successful result with the AI-generated research report
 	return types.Result{
-		TaskID:  task.ID,
-		Success: true,
-		Data:    response, // Full research report from LLM
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     response, // Full research report from LLM
+		Metadata: resultMetadata(ctx),
 	}
 }
 
@@ -308,3 +261,24 @@ Context from previous tasks: %v`, task.Description, task.Context)
 func (ra *ResearchAgent) GetSpecialty() string {
 	return "research"
 }
+
+// HistorySize returns the number of messages currently kept in the
+// conversation history. The web dashboard shows it in the agent
+// detail view.
+func (ra *ResearchAgent) HistorySize() int {
+	return ra.history.size()
+}
+
+// HistorySummary returns the rolling summary of the exchanges that no longer
+// fit in the history, shown alongside HistorySize
+func (ra *ResearchAgent) HistorySummary() string {
+	return ra.history.rollingSummary()
+}
+
+// Warmup verifies the agent's LLM client before it accepts tasks.
+//
+// Called by the swarm's warm pool when the agent is spawned dynamically, so a
+// bad API key or unreachable provider is caught before work is routed here.
+func (ra *ResearchAgent) Warmup() error {
+	return ra.llmClient.SelfTest()
+}
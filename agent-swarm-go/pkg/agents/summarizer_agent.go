@@ -0,0 +1,138 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/prompts"
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultSummaryWords is the summary length a SummarizerAgent aims for when
+// the task doesn't set Payload["max_words"]
+const DefaultSummaryWords = 150
+
+// SummarizerAgent condenses long research, analysis, or report output into a
+// short summary. The text to condense is taken from task.Context (the outputs
+// of the steps a workflow step depends on), or from the description itself
+// when there is no context.
+type SummarizerAgent struct {
+	*agent.BaseAgent
+	llmClient *llm.Client
+	eventBus  *types.EventBus
+}
+
+// NewSummarizerAgent creates a new summarization agent
+func NewSummarizerAgent(id string, eventBus *types.EventBus) *SummarizerAgent {
+	return newSummarizerAgent(id, eventBus, llm.NewClient())
+}
+
+// newSummarizerAgent creates a summarizer agent that uses the given LLM client
+func newSummarizerAgent(id string, eventBus *types.EventBus, client *llm.Client) *SummarizerAgent {
+	sa := &SummarizerAgent{
+		BaseAgent: agent.NewBaseAgent(id),
+		llmClient: client,
+		eventBus:  eventBus,
+	}
+
+	sa.RegisterHandler(types.MessageTypeTask, sa.handleTask)
+	return sa
+}
+
+// summarizerLabels is the wording used in this agent's task events
+var summarizerLabels = taskLabels{
+	received:  "📥 Received summary task: %s",
+	started:   "⚙️  Summarizing: %s",
+	completed: "✅ Summary complete: %s",
+	failed:    "❌ Summary failed: %s",
+}
+
+func (sa *SummarizerAgent) handleTask(msg types.Message) error {
+	return runTask(sa.BaseAgent, sa.eventBus, summarizerLabels, msg, sa.ProcessTask)
+}
+
+// ProcessTask summarizes the task's context, in at most Payload["max_words"]
+// words, with the description as guidance on what to keep
+func (sa *SummarizerAgent) ProcessTask(task types.Task) types.Result {
+	maxWords := DefaultSummaryWords
+	if n, ok := payloadInt(task, "max_words"); ok && n > 0 {
+		maxWords = n
+	}
+
+	text, focus := task.Description, ""
+	if rendered := renderContext(task.Context); rendered != "" {
+		text, focus = rendered, task.Description
+	}
+
+	ctx, cancel := taskContext(sa.BaseAgent, sa.eventBus, task)
+	defer cancel()
+	reportProgress(sa.eventBus, sa.BaseAgent, task, 10, phaseCallingLLM)
+	summary, err := summarize(ctx, sa.llmClient, text, focus, task.Locale, maxWords)
+	if err != nil {
+		return types.Result{
+			TaskID:   task.ID,
+			Success:  false,
+			Data:     fmt.Sprintf("Summary failed: %v", err),
+			Failure:  failureFor(err),
+			Metadata: resultMetadata(ctx),
+		}
+	}
+
+	return types.Result{
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     summary,
+		Metadata: resultMetadata(ctx),
+	}
+}
+
+// summarizerPrompt is the summarizer's built-in system prompt, which users
+// can override (see Prompts). History compaction uses it too.
+const summarizerPrompt = `You are a summarization agent. Your role is to:
+1. Keep the conclusions, key figures, decisions, and open questions
+2. Drop repetition, filler, and formatting that carries no information
+3. Never add facts that are not in the source text
+4. Stay within the requested length
+
+Write plain, dense prose or short bullet points.`
+
+// summarize condenses text to at most maxWords words. focus, if set, says
+// what the summary is for, and locale, if set, the language to write it in.
+// It is shared by SummarizerAgent and history compaction, so every summary
+// follows the same instructions.
+func summarize(ctx context.Context, client *llm.Client, text, focus, locale string, maxWords int) (string, error) {
+	// Rendered without agent or task, since history compaction shares it
+	systemPrompt, err := Prompts.Render("summarizer", prompts.Data{})
+	if err != nil {
+		return "", err
+	}
+	systemPrompt = withLocale(systemPrompt, locale)
+
+	if focus != "" {
+		focus = "\nFocus: " + focus
+	}
+	userPrompt := fmt.Sprintf(`Summarization Task: condense the text below to at most %d words.%s
+
+Text:
+%s`, maxWords, focus, text)
+
+	// Summaries stand alone, so no conversation history is passed
+	summary, err := client.CompleteContext(ctx, systemPrompt, userPrompt, nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(summary), nil
+}
+
+// GetSpecialty returns the agent's specialty
+func (sa *SummarizerAgent) GetSpecialty() string {
+	return "summarizer"
+}
+
+// Warmup runs the LLM self-test
+func (sa *SummarizerAgent) Warmup() error {
+	return sa.llmClient.SelfTest()
+}
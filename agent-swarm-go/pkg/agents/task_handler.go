@@ -0,0 +1,422 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/sandbox"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/validate"
+)
+
+// taskLabels holds the agent-specific wording used in task events.
+// Each format string receives a single argument: the task description for
+// received/started, and the task ID for completed/failed.
+type taskLabels struct {
+	received  string // e.g. "📥 Received research task: %s"
+	started   string // e.g. "⚙️  Researching: %s"
+	completed string // e.g. "✅ Research complete: %s"
+	failed    string // e.g. "❌ Research failed: %s"
+}
+
+// runTask is the task message handler shared by all LLM-backed agents.
+//
+// Workflow Steps:
+//   1. Validates the message contains a valid Task
+//   2. Publishes EventTaskReceived (shows in web dashboard)
+//   3. Logs task receipt (structured, via slog)
+//   4. Publishes EventTaskStarted (updates agent status to "processing")
+//   5. Calls process() to perform the agent's actual work, wrapped in any
+//      before/after-task hooks registered on the agent (see agent.BaseAgent.Use),
+//      and again to fix output that misses the task's OutputSpec (see checkOutput)
+//   6. Publishes EventTaskCompleted or EventTaskFailed with the full Result
+//      (or EventTaskFailed with FailureCancelled as soon as the task is cancelled;
+//      a task that ran past its Timeout fails with FailureTimeout)
+//   7. Logs completion (info on success, warn on failure)
+//
+// Results are not returned to the sender; they are published on the event
+// bus, where workflows and the web dashboard pick them up.
+func runTask(base *agent.BaseAgent, eventBus *types.EventBus, labels taskLabels, msg types.Message, process func(types.Task) types.Result) error {
+	// Type assertion ensures we have a valid Task structure
+	task, ok := msg.Content.(types.Task)
+	if !ok {
+		return fmt.Errorf("invalid task format")
+	}
+
+	publish := func(eventType types.EventType, message string, data interface{}) {
+		if eventBus == nil {
+			return
+		}
+		eventBus.Publish(types.Event{
+			Type:         eventType,
+			Timestamp:    time.Now(),
+			AgentID:      base.GetID(),
+			TaskID:       task.ID,
+			Message:      message,
+			Data:         data,
+			Dependencies: task.Dependencies, // Lets dashboards draw the task graph
+			RunID:        task.RunID,
+		})
+	}
+
+	publish(types.EventTaskReceived, fmt.Sprintf(labels.received, task.Description), nil)
+	base.Logger().Info("task received", logging.KeyTask, task.ID, "description", task.Description)
+
+	publish(types.EventTaskStarted, fmt.Sprintf(labels.started, task.Description), nil)
+
+	// Documents the context refers to (types.MemoryRef) are read from the
+	// run's memory, along with its notes
+	task.Context = base.Memory(task.RunID).Resolve(task.Context)
+
+	// This usually calls the LLM API and can take 10-30 seconds, so it runs
+	// in the background where a cancellation can cut the wait short
+	started := time.Now()
+	done := make(chan types.Result, 1)
+	go func() { done <- base.WrapTask(checkOutput(base, process))(task) }()
+
+	var result types.Result
+	returned := false
+	select {
+	case result = <-done:
+		returned = true
+	case <-base.TaskContextFor(task.ID).Done():
+		// The agent's LLM call runs under the same context (see taskContext),
+		// so it is being aborted too; its result is discarded
+		result = types.Result{
+			TaskID:  task.ID,
+			Success: false,
+			Data:    "Task cancelled",
+			Failure: types.FailureCancelled,
+		}
+	}
+
+	result.RunID = task.RunID
+	finished := time.Now()
+	result.Metadata.Started, result.Metadata.Finished = &started, &finished
+	if !task.Submitted.IsZero() {
+		submitted := task.Submitted
+		result.Metadata.Submitted = &submitted
+	}
+	result.Metadata.Locale = task.Locale
+
+	if result.Failure == types.FailureCancelled {
+		publish(types.EventTaskFailed, fmt.Sprintf("🛑 Task cancelled: %s", task.ID), result)
+		base.Logger().Info("task cancelled", logging.KeyTask, task.ID)
+
+		// Don't free this worker until the abandoned call returns
+		if !returned {
+			<-done
+		}
+		return nil
+	}
+
+	if result.Success {
+		publish(types.EventTaskCompleted, fmt.Sprintf(labels.completed, task.ID), result)
+		base.Logger().Info("task completed", logging.KeyTask, task.ID)
+	} else if result.Failure == types.FailureTimeout {
+		publish(types.EventTaskFailed, fmt.Sprintf("⏱️  Task timed out after %v: %s", task.Timeout, task.ID), result)
+		base.Logger().Warn("task timed out", logging.KeyTask, task.ID, "timeout", task.Timeout)
+	} else if result.Failure == types.FailureInvalidOutput {
+		publish(types.EventTaskFailed, fmt.Sprintf("🧾 Output didn't match the expected shape: %s", task.ID), result)
+		base.Logger().Warn("task output invalid", logging.KeyTask, task.ID, "detail", result.Data)
+	} else if result.Failure == types.FailureLimitExceeded {
+		publish(types.EventTaskFailed, fmt.Sprintf("⛔ Resource limit exceeded: %s", task.ID), result)
+		base.Logger().Warn("task exceeded resource limit", logging.KeyTask, task.ID, logging.KeyError, result.Error)
+	} else {
+		publish(types.EventTaskFailed, fmt.Sprintf(labels.failed, task.ID), result)
+		base.Logger().Warn("task failed", logging.KeyTask, task.ID, "detail", result.Data)
+	}
+
+	return nil
+}
+
+// checkOutput wraps process so that output missing the shape the task asks
+// for (Payload["output_spec"], see pkg/validate) goes back to the agent with
+// what was wrong, up to the spec's retries. Output that still doesn't match
+// fails the task with FailureInvalidOutput. The spec is also described in the
+// task itself, so the first answer has a fair chance.
+func checkOutput(base *agent.BaseAgent, process func(types.Task) types.Result) func(types.Task) types.Result {
+	return func(task types.Task) types.Result {
+		spec, err := validate.SpecFor(task)
+		if err != nil {
+			return types.Result{TaskID: task.ID, Success: false, Data: err.Error(), Failure: types.FailureInvalidOutput}
+		}
+		if spec == nil {
+			return process(task)
+		}
+
+		asked := task
+		asked.Description = task.Description + "\n\n" + spec.Describe()
+		result := process(asked)
+		metadata := result.Metadata
+		for fix := 1; result.Success; fix++ {
+			problem := spec.Check(result.Data)
+			if problem == nil {
+				break
+			}
+			if fix > spec.MaxRetries() {
+				result = types.Result{
+					TaskID:  task.ID,
+					Success: false,
+					Data:    fmt.Sprintf("output doesn't match the expected shape: %v\n\n%v", problem, result.Data),
+					Failure: types.FailureInvalidOutput,
+				}
+				break
+			}
+			base.Logger().Info("asking agent to fix its output", logging.KeyTask, task.ID, "fix", fix, "problem", problem)
+			result = process(fixOutputTask(asked, result.Data, problem))
+			metadata = addMetadata(metadata, result.Metadata)
+		}
+		result.Metadata = metadata
+		return result
+	}
+}
+
+// fixOutputTask asks for task again, with the output that missed its spec
+// and what was wrong with it
+func fixOutputTask(task types.Task, output interface{}, problem error) types.Task {
+	fixed := task
+	fixed.Context = make(map[string]interface{}, len(task.Context)+2)
+	for k, v := range task.Context {
+		fixed.Context[k] = v
+	}
+	fixed.Context["previous_output"] = output
+	fixed.Context["output_problems"] = problem.Error()
+	fixed.Description = task.Description + fmt.Sprintf("\n\nYour previous output (previous_output in the context) didn't have the required shape: %v. Fix your output: rewrite it so it does.", problem)
+	return fixed
+}
+
+// addMetadata totals the metadata of two attempts at a task; the later
+// attempt's route and model stand
+func addMetadata(a, b types.ResultMetadata) types.ResultMetadata {
+	if b.Provider != "" {
+		a.Provider, a.Model = b.Provider, b.Model
+	}
+	a.Attempts += b.Attempts
+	a.InputTokens += b.InputTokens
+	a.OutputTokens += b.OutputTokens
+	a.CostUSD += b.CostUSD
+	if b.Route != nil {
+		a.Route = b.Route
+	}
+	return a
+}
+
+// Phases agents report while working on a task (see reportProgress)
+const (
+	phaseCallingLLM     = "calling LLM"
+	phaseParsing        = "parsing response"
+	phasePostProcessing = "post-processing"
+)
+
+// reportProgress publishes how far an agent has got with task, for the
+// dashboard and CLI to show while a long task runs
+func reportProgress(eventBus *types.EventBus, base *agent.BaseAgent, task types.Task, percent int, phase string) {
+	base.Logger().Debug("task progress", logging.KeyTask, task.ID, "percent", percent, "phase", phase)
+	if eventBus == nil {
+		return
+	}
+	eventBus.Publish(types.Event{
+		Type:      types.EventTaskProgress,
+		Timestamp: time.Now(),
+		AgentID:   base.GetID(),
+		TaskID:    task.ID,
+		Message:   fmt.Sprintf("⏳ %s: %s (%d%%)", task.ID, phase, percent),
+		Data:      types.TaskProgress{Percent: percent, Phase: phase},
+		RunID:     task.RunID,
+	})
+}
+
+// taskContext returns the context an agent's LLM call runs under. It is
+// derived from the task's context on base, so cancelling the task or stopping
+// the agent aborts the call in flight; it also expires after task.Timeout, if
+// the task has one, and counts the API calls made under it for
+// resultMetadata. Payload["complexity"] ("simple" or "complex") tells a model
+// router which tier the task needs (see llm.Router). A task.Stream task's
+// output is published on eventBus as it is generated (see outputStream);
+// cancelling the context publishes whatever is left.
+func taskContext(base *agent.BaseAgent, eventBus *types.EventBus, task types.Task) (context.Context, context.CancelFunc) {
+	ctx, _ := llm.WithCallStats(agent.ContextWithTaskID(base.TaskContextFor(task.ID), task.ID))
+	if complexity := payloadString(task, "complexity"); complexity != "" {
+		ctx = llm.WithComplexity(ctx, complexity)
+	}
+	var stream *outputStream
+	if task.Stream && eventBus != nil {
+		stream = &outputStream{eventBus: eventBus, base: base, task: task}
+		ctx = llm.WithStream(ctx, stream.write)
+	}
+
+	var cancel context.CancelFunc
+	if task.Timeout <= 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+	}
+	if stream == nil {
+		return ctx, cancel
+	}
+	return ctx, func() {
+		stream.flush()
+		cancel()
+	}
+}
+
+// streamInterval is how often a streaming task's output is published at
+// most, so a fast model doesn't flood the event bus with one event per token
+const streamInterval = 50 * time.Millisecond
+
+// outputStream publishes a streaming task's output in EventTaskOutput events
+// as the model generates it, gathering what arrives within streamInterval
+// into one event
+type outputStream struct {
+	eventBus *types.EventBus
+	base     *agent.BaseAgent
+	task     types.Task
+
+	mu      sync.Mutex
+	pending strings.Builder
+	last    time.Time // When output was last published
+}
+
+// write takes the next chunk of output, publishing it along with any held
+// back if streamInterval has passed
+func (o *outputStream) write(chunk string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pending.WriteString(chunk)
+	if time.Since(o.last) >= streamInterval {
+		o.publish()
+	}
+}
+
+// flush publishes any output held back
+func (o *outputStream) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.publish()
+}
+
+// publish sends the pending output; o.mu must be held
+func (o *outputStream) publish() {
+	if o.pending.Len() == 0 {
+		return
+	}
+	text := o.pending.String()
+	o.pending.Reset()
+	o.last = time.Now()
+	o.eventBus.Publish(types.Event{
+		Type:      types.EventTaskOutput,
+		Timestamp: o.last,
+		AgentID:   o.base.GetID(),
+		TaskID:    o.task.ID,
+		Message:   fmt.Sprintf("✍️  %s: %d more characters of output", o.task.ID, len(text)),
+		Data:      types.TaskOutput{Text: text},
+		RunID:     o.task.RunID,
+	})
+}
+
+// resultMetadata describes the API calls made under a task's context (see
+// taskContext)
+func resultMetadata(ctx context.Context) types.ResultMetadata {
+	var metadata types.ResultMetadata
+	if stats := llm.CallStatsFrom(ctx); stats != nil {
+		metadata.Attempts = stats.Attempts()
+		metadata.InputTokens, metadata.OutputTokens = stats.Tokens()
+		metadata.CostUSD = stats.CostUSD()
+		metadata.Provider, metadata.Model = stats.Model()
+		if route := stats.Route(); route != nil {
+			metadata.Route = &types.ModelRoute{Tier: route.Tier, Model: route.Model, Reason: route.Reason}
+		}
+	}
+	return metadata
+}
+
+// failureFor classifies the error of an LLM call or external process for
+// Result.Failure
+func failureFor(err error) types.FailureClass {
+	var limit *sandbox.LimitError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return types.FailureTimeout
+	case errors.Is(err, context.Canceled):
+		return types.FailureCancelled
+	case errors.As(err, &limit):
+		return types.FailureLimitExceeded
+	}
+	return ""
+}
+
+// payloadString returns a string field of task.Payload, or "" when the payload
+// has no such field
+func payloadString(task types.Task, key string) string {
+	switch payload := task.Payload.(type) {
+	case map[string]interface{}:
+		if s, ok := payload[key].(string); ok {
+			return s
+		}
+	case map[string]string:
+		return payload[key]
+	}
+	return ""
+}
+
+// payloadInt returns a numeric field of task.Payload, which may arrive as an
+// int (Go callers), a float64 (JSON), or a string (CSV, workflow inputs).
+// ok is false when the field is missing or not a whole number.
+func payloadInt(task types.Task, key string) (n int, ok bool) {
+	var value interface{}
+	switch payload := task.Payload.(type) {
+	case map[string]interface{}:
+		value = payload[key]
+	case map[string]string:
+		value = payload[key]
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), v == float64(int(v))
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// payloadStrings returns a list field of task.Payload, given either as a list
+// or as one comma-separated string; blank entries are dropped
+func payloadStrings(task types.Task, key string) []string {
+	var items []string
+	switch payload := task.Payload.(type) {
+	case map[string]interface{}:
+		switch v := payload[key].(type) {
+		case []string:
+			items = v
+		case []interface{}:
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+		case string:
+			items = strings.Split(v, ",")
+		}
+	case map[string]string:
+		items = strings.Split(payload[key], ",")
+	}
+
+	var list []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
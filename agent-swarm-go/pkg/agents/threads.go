@@ -0,0 +1,96 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// maxPeerQuestions caps how many questions an agent asks other agents per task
+const maxPeerQuestions = 2
+
+// peerQuestionTimeout bounds the wait for another agent's answer. The other
+// agent answers between its own tasks, so this also covers its current task.
+const peerQuestionTimeout = 2 * time.Minute
+
+// peerInstructions is added to the system prompt of agents that may consult
+// others; it receives the agents they may ask and maxPeerQuestions
+const peerInstructions = `
+
+You can consult other agents in the swarm: %s. If you cannot complete the task well without information one of them could give you, reply with a single line of the form "ASK <agent>: <question>" and nothing else. You will get the answer and can then complete the task. Ask at most %d questions, and only when it matters.`
+
+// askLine matches a reply that is a question for another agent
+var askLine = regexp.MustCompile(`^ASK\s+([\w-]+)\s*:\s*(.+)`)
+
+// completeWithPeers runs a task's completion, letting the model consult the
+// given specialties mid-task. A reply like "ASK research: <question>" is put
+// to a research agent through the swarm, and the completion is repeated with
+// the answer added to the prompt. Follow-up questions to the same agent stay
+// in one thread.
+func completeWithPeers(ctx context.Context, base *agent.BaseAgent, client *llm.Client, peers []string, systemPrompt, userPrompt string, history []llm.Message) (string, error) {
+	systemPrompt += fmt.Sprintf(peerInstructions, strings.Join(peers, ", "), maxPeerQuestions)
+	threads := make(map[string]string) // Thread ID by the agent asked
+
+	for asked := 0; ; asked++ {
+		response, err := client.CompleteContext(ctx, systemPrompt, userPrompt, history)
+		if err != nil {
+			return "", err
+		}
+		m := askLine.FindStringSubmatch(strings.Trim(strings.TrimSpace(response), "`"))
+		if m == nil || asked > maxPeerQuestions {
+			return response, nil
+		}
+		if asked == maxPeerQuestions {
+			userPrompt += "\n\nYou have no questions left. Complete the task now with what you have."
+			continue
+		}
+
+		peer, question := m[1], strings.TrimSpace(m[2])
+		askCtx, cancel := context.WithTimeout(ctx, peerQuestionTimeout)
+		reply, err := base.Ask(askCtx, peer, threads[peer], question)
+		cancel()
+		if err != nil {
+			base.Logger().Warn("peer question unanswered", "to", peer, "error", err)
+			userPrompt += fmt.Sprintf("\n\nYou asked the %s agent: %s\nNo answer (%v). Complete the task with what you have.", peer, question, err)
+			continue
+		}
+		threads[peer] = reply.ThreadID
+		userPrompt += fmt.Sprintf("\n\nYou asked the %s agent: %s\nAnswer from %s: %s", peer, question, reply.From, reply.Text)
+	}
+}
+
+// answerQuestions lets an agent answer questions other agents ask it
+// mid-task. role describes the agent to the model, e.g. "a research
+// specialist". Questions are answered one at a time between tasks.
+func answerQuestions(base *agent.BaseAgent, client *llm.Client, role string) {
+	base.RegisterHandler(types.MessageTypeQuery, func(msg types.Message) error {
+		query, ok := msg.Content.(types.Query)
+		if !ok || query.Reply == nil {
+			return fmt.Errorf("invalid query format")
+		}
+
+		systemPrompt := fmt.Sprintf(`You are %s in a swarm of AI agents. Another agent is in the middle of a task and has a question for you.
+Answer it directly and concisely from your expertise. If you don't know, say so rather than guess.`, role)
+
+		userPrompt := fmt.Sprintf("Peer Question from %s:\n%s", query.From, query.Question)
+		if len(query.History) > 0 {
+			var b strings.Builder
+			for _, m := range query.History {
+				fmt.Fprintf(&b, "%s: %s\n\n", m.From, m.Text)
+			}
+			userPrompt += "\n\nEarlier in this conversation:\n" + strings.TrimSpace(b.String())
+		}
+
+		ctx, cancel := context.WithTimeout(base.Context(), peerQuestionTimeout)
+		defer cancel()
+		answer, err := client.CompleteContext(ctx, systemPrompt, userPrompt, nil)
+		query.Reply <- types.QueryAnswer{Text: answer, Err: err}
+		return nil
+	})
+}
@@ -0,0 +1,125 @@
+package agents
+
+import (
+	"fmt"
+	"strings"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// TranslationAgent translates text, usually a finished report, into the
+// languages listed in Payload["languages"] (a list or a comma-separated
+// string such as "Spanish, German"). The text is taken from task.Context, or
+// from the description when there is no context. Its Result.Data is a
+// types.Translations.
+type TranslationAgent struct {
+	*agent.BaseAgent
+	llmClient *llm.Client
+	eventBus  *types.EventBus
+}
+
+// NewTranslationAgent creates a new translation agent
+func NewTranslationAgent(id string, eventBus *types.EventBus) *TranslationAgent {
+	return newTranslationAgent(id, eventBus, llm.NewClient())
+}
+
+// newTranslationAgent creates a translation agent that uses the given LLM client
+func newTranslationAgent(id string, eventBus *types.EventBus, client *llm.Client) *TranslationAgent {
+	ta := &TranslationAgent{
+		BaseAgent: agent.NewBaseAgent(id),
+		llmClient: client,
+		eventBus:  eventBus,
+	}
+
+	ta.RegisterHandler(types.MessageTypeTask, ta.handleTask)
+	return ta
+}
+
+// translationLabels is the wording used in this agent's task events
+var translationLabels = taskLabels{
+	received:  "📥 Received translation task: %s",
+	started:   "⚙️  Translating: %s",
+	completed: "✅ Translation complete: %s",
+	failed:    "❌ Translation failed: %s",
+}
+
+func (ta *TranslationAgent) handleTask(msg types.Message) error {
+	return runTask(ta.BaseAgent, ta.eventBus, translationLabels, msg, ta.ProcessTask)
+}
+
+// translationPrompt is the built-in system prompt, used for every target
+// language (see Prompts)
+const translationPrompt = `You are a professional translation agent. Your role is to:
+1. Translate the text faithfully, without adding, dropping, or summarizing content
+2. Keep the markdown structure: headings, lists, tables, links, and code blocks
+3. Leave code, URLs, product names, and figures unchanged
+4. Use natural, professional phrasing for a business audience
+
+Reply with the translation only.`
+
+// ProcessTask translates the task's text into each requested language, one
+// LLM call per language. The task fails if any translation does.
+func (ta *TranslationAgent) ProcessTask(task types.Task) types.Result {
+	languages := payloadStrings(task, "languages")
+	if len(languages) == 0 {
+		return types.Result{TaskID: task.ID, Success: false, Data: `Translation failed: no target languages in Payload["languages"]`}
+	}
+
+	text := task.Description
+	if len(task.Context) == 1 {
+		for _, value := range task.Context {
+			text = fmt.Sprint(value)
+		}
+	} else if len(task.Context) > 1 {
+		text = renderContext(task.Context)
+	}
+
+	systemPrompt, err := renderPrompt("translation", ta.BaseAgent, task)
+	if err != nil {
+		return types.Result{TaskID: task.ID, Success: false, Data: fmt.Sprintf("Translation failed: %v", err)}
+	}
+
+	ctx, cancel := taskContext(ta.BaseAgent, ta.eventBus, task)
+	defer cancel()
+
+	translations := make(types.Translations, len(languages))
+	for i, language := range languages {
+		reportProgress(ta.eventBus, ta.BaseAgent, task, i*100/len(languages), "translating into "+language)
+		userPrompt := fmt.Sprintf(`Translation Task: translate the text below into %s.
+
+Text:
+%s`, language, text)
+
+		// Each language is translated from the original, with no history
+		translated, err := ta.llmClient.CompleteContext(ctx, systemPrompt, userPrompt, nil)
+		if err != nil {
+			return types.Result{
+				TaskID:   task.ID,
+				Success:  false,
+				Data:     fmt.Sprintf("Translation into %s failed: %v", language, err),
+				Failure:  failureFor(err),
+				Metadata: resultMetadata(ctx),
+			}
+		}
+		translations[language] = strings.TrimSpace(translated)
+	}
+
+	return types.Result{
+		TaskID:   task.ID,
+		Success:  true,
+		Data:     translations,
+		Metadata: resultMetadata(ctx),
+	}
+}
+
+// GetSpecialty returns the agent's specialty
+func (ta *TranslationAgent) GetSpecialty() string {
+	return "translation"
+}
+
+// Warmup runs the LLM self-test
+func (ta *TranslationAgent) Warmup() error {
+	return ta.llmClient.SelfTest()
+}
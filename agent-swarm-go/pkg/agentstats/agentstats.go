@@ -0,0 +1,229 @@
+// Package agentstats keeps running totals for each agent, such as tasks
+// processed, failures, average latency, and tokens used, in a JSON file, so
+// they survive restarts. They are served at /api/agents/{id}/stats and shown
+// in the CLI's Agent Details view.
+//
+// The totals are kept by observing the event bus for the results agents
+// report, whatever submitted the task:
+//
+//	st, err := agentstats.Open(agentstats.DefaultPath)
+//	s.GetEventBus().Observe(st.Record)
+//	go st.Run(ctx)   // Saves while there are changes
+//	defer st.Save() // And once more after the swarm stops
+package agentstats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultPath is where cmd/main.go keeps the statistics
+const DefaultPath = "agent-stats.json"
+
+// saveInterval is how often Run saves statistics that changed
+const saveInterval = 10 * time.Second
+
+// Stats are one agent's totals since its statistics were first kept
+type Stats struct {
+	AgentID        string        `json:"agent_id"`
+	Tasks          int           `json:"tasks"`           // Results reported, failures included
+	Failures       int           `json:"failures"`        // Results that weren't a success
+	TotalLatency   time.Duration `json:"total_latency"`   // Time spent on the tasks; nanoseconds in JSON
+	AverageLatency time.Duration `json:"average_latency"` // TotalLatency over Tasks; nanoseconds in JSON
+	InputTokens    int           `json:"input_tokens"`
+	OutputTokens   int           `json:"output_tokens"`
+	CostUSD        float64       `json:"cost_usd"` // Estimated at list price
+	FirstTask      time.Time     `json:"first_task"`
+	LastTask       time.Time     `json:"last_task"`
+}
+
+// TotalTokens returns the input and output tokens together
+func (s Stats) TotalTokens() int {
+	return s.InputTokens + s.OutputTokens
+}
+
+// FailureRate returns the share of tasks that failed, between 0 and 1
+func (s Stats) FailureRate() float64 {
+	if s.Tasks == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Tasks)
+}
+
+// Store holds every agent's statistics and the file they are saved to. It
+// is safe for concurrent use.
+type Store struct {
+	path string
+
+	mu     sync.Mutex
+	agents map[string]*Stats
+	dirty  bool // Changed since the last save
+
+	saveMu sync.Mutex // Keeps saves in order
+}
+
+// Open loads the statistics saved at path. A missing file starts empty; it
+// is created on the first save.
+func Open(path string) (*Store, error) {
+	st := &Store{path: path, agents: make(map[string]*Stats)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved []*Stats
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, stats := range saved {
+		if stats.AgentID != "" {
+			st.agents[stats.AgentID] = stats
+		}
+	}
+	return st, nil
+}
+
+// Record adds the result an agent reports in event, if it is one: an
+// EventTaskCompleted or EventTaskFailed event from the agent that worked on
+// the task. Failures the swarm reports on an agent's behalf, e.g. for a
+// task that was cancelled while queued, aren't the agent's, so they aren't
+// counted.
+func (st *Store) Record(event types.Event) {
+	if event.Type != types.EventTaskCompleted && event.Type != types.EventTaskFailed {
+		return
+	}
+	result, ok := event.Data.(types.Result)
+	if !ok || result.Metadata.Started == nil || event.AgentID == "" {
+		return
+	}
+	at := event.Timestamp
+	if result.Metadata.Finished != nil {
+		at = *result.Metadata.Finished
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	stats, ok := st.agents[event.AgentID]
+	if !ok {
+		stats = &Stats{AgentID: event.AgentID, FirstTask: at}
+		st.agents[event.AgentID] = stats
+	}
+	stats.Tasks++
+	if event.Type == types.EventTaskFailed || !result.Success {
+		stats.Failures++
+	}
+	stats.TotalLatency += result.Metadata.Duration()
+	stats.AverageLatency = stats.TotalLatency / time.Duration(stats.Tasks)
+	stats.InputTokens += result.Metadata.InputTokens
+	stats.OutputTokens += result.Metadata.OutputTokens
+	stats.CostUSD += result.Metadata.CostUSD
+	stats.LastTask = at
+	st.dirty = true
+}
+
+// Get returns an agent's statistics, and false if it has reported no
+// results yet
+func (st *Store) Get(agentID string) (Stats, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	stats, ok := st.agents[agentID]
+	if !ok {
+		return Stats{AgentID: agentID}, false
+	}
+	return *stats, true
+}
+
+// List returns every agent's statistics, by agent ID. Agents that have left
+// the swarm are still there.
+func (st *Store) List() []Stats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.list()
+}
+
+// list returns the statistics sorted by agent ID; st.mu must be held
+func (st *Store) list() []Stats {
+	list := make([]Stats, 0, len(st.agents))
+	for _, stats := range st.agents {
+		list = append(list, *stats)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].AgentID < list[j].AgentID })
+	return list
+}
+
+// Save writes the statistics to the store's file. The file is replaced in
+// one step, so a crash mid-write leaves the previous one.
+func (st *Store) Save() error {
+	st.saveMu.Lock()
+	defer st.saveMu.Unlock()
+
+	st.mu.Lock()
+	list := st.list()
+	st.dirty = false
+	st.mu.Unlock()
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(st.path), filepath.Base(st.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), st.path)
+}
+
+// Run saves the statistics every saveInterval while they change, until ctx
+// is done. Save once more on shutdown, after stopping the swarm, so the last
+// results are kept.
+func (st *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(saveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			st.saveIfDirty()
+		}
+	}
+}
+
+// saveIfDirty saves the statistics if they changed since the last save
+func (st *Store) saveIfDirty() {
+	st.mu.Lock()
+	dirty := st.dirty
+	st.mu.Unlock()
+	if !dirty {
+		return
+	}
+	if err := st.Save(); err != nil {
+		slog.Warn("saving agent statistics failed", "path", st.path, logging.KeyError, err)
+		st.mu.Lock()
+		st.dirty = true // Try again next time
+		st.mu.Unlock()
+	}
+}
@@ -0,0 +1,253 @@
+// Package archive keeps every completed workflow result on disk, one JSON
+// file per run, so reports from earlier sessions can be listed and reopened
+// from the CLI or the REST API.
+//
+// Artifacts the run produced are stored next to its file, in a directory
+// named after the workflow ID, and read back with Artifact.
+//
+// The archive records runs by watching the event bus for workflow_completed
+// events, whatever started the workflow:
+//
+//	a, err := archive.Open(archive.DefaultDir)
+//	go a.Run(ctx, s.GetEventBus())
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/redact"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/workflows"
+)
+
+// DefaultDir is where cmd/main.go keeps the archive
+const DefaultDir = "runs"
+
+// ErrNotFound is returned by Get for an unknown workflow ID
+var ErrNotFound = errors.New("run not found")
+
+// ErrArtifactNotFound is returned by Artifact for a run without the artifact
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// Summary describes an archived run without its step outputs
+type Summary struct {
+	WorkflowID string        `json:"workflow_id"`
+	Name       string        `json:"name,omitempty"`
+	Topic      string        `json:"topic"`
+	StartTime  time.Time     `json:"start_time"`
+	Duration   time.Duration `json:"duration"` // Nanoseconds in JSON
+	Steps      int           `json:"steps"`    // Number of steps that produced output
+}
+
+// Archive is a directory of workflow results
+type Archive struct {
+	dir string
+
+	mu    sync.Mutex
+	index map[string]Summary // By workflow ID
+}
+
+// Open opens the archive in dir, creating the directory if needed, and
+// indexes the runs already there
+func Open(dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	a := &Archive{dir: dir, index: make(map[string]Summary)}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		wr, err := a.load(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			_, err = a.path(wr.WorkflowID)
+		}
+		if err != nil {
+			slog.Warn("skipping unreadable archived run", "file", entry.Name(), logging.KeyError, err)
+			continue
+		}
+		a.index[wr.WorkflowID] = summarize(wr)
+	}
+	return a, nil
+}
+
+// summarize builds the index entry for a result
+func summarize(wr *workflows.WorkflowResult) Summary {
+	return Summary{
+		WorkflowID: wr.WorkflowID,
+		Name:       wr.Name,
+		Topic:      wr.Topic,
+		StartTime:  wr.StartTime,
+		Duration:   wr.Duration,
+		Steps:      len(wr.StepResults),
+	}
+}
+
+// path is the file a workflow's result is stored in. Workflow IDs are
+// generated by the swarm, but the ID in a request is not trusted.
+func (a *Archive) path(workflowID string) (string, error) {
+	if workflowID == "" || strings.ContainsAny(workflowID, `/\`) || strings.Contains(workflowID, "..") {
+		return "", fmt.Errorf("invalid workflow ID %q", workflowID)
+	}
+	return filepath.Join(a.dir, workflowID+".json"), nil
+}
+
+// load reads one archived result
+func (a *Archive) load(path string) (*workflows.WorkflowResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var wr workflows.WorkflowResult
+	if err := json.Unmarshal(data, &wr); err != nil {
+		return nil, err
+	}
+	return &wr, nil
+}
+
+// Save stores a result, replacing any earlier copy of the same run. Its
+// artifacts' content is written to files of their own; the run's file lists
+// them.
+func (a *Archive) Save(wr *workflows.WorkflowResult) error {
+	path, err := a.path(wr.WorkflowID)
+	if err != nil {
+		return err
+	}
+
+	// wr is shared with the event's other observers, so the listing goes in a copy
+	saved := *wr
+	saved.Artifacts = make([]types.Artifact, 0, len(wr.Artifacts))
+	for _, artifact := range wr.Artifacts {
+		if err := artifact.Validate(); err != nil {
+			return err
+		}
+		saved.Artifacts = append(saved.Artifacts, artifact.Listing())
+	}
+	if len(saved.Artifacts) == 0 {
+		saved.Artifacts = nil
+	}
+	data, err := json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = []byte(redact.String(string(data)))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(wr.Artifacts) > 0 {
+		dir := a.artifactDir(wr.WorkflowID)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		for _, artifact := range wr.Artifacts {
+			if err := os.WriteFile(filepath.Join(dir, artifact.Name), artifact.Data, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	a.index[wr.WorkflowID] = summarize(&saved)
+	return nil
+}
+
+// artifactDir is the directory a run's artifacts are stored in; workflowID
+// has passed path
+func (a *Archive) artifactDir(workflowID string) string {
+	return filepath.Join(a.dir, workflowID)
+}
+
+// List returns archived runs, newest first
+func (a *Archive) List() []Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	list := make([]Summary, 0, len(a.index))
+	for _, s := range a.index {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartTime.After(list[j].StartTime) })
+	return list
+}
+
+// Get loads an archived run, or returns ErrNotFound
+func (a *Archive) Get(workflowID string) (*workflows.WorkflowResult, error) {
+	a.mu.Lock()
+	_, ok := a.index[workflowID]
+	a.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	// Only indexed IDs get here, and those all passed path when saved or loaded
+	path, _ := a.path(workflowID)
+	return a.load(path)
+}
+
+// Artifact loads one of an archived run's artifacts, content included, or
+// returns ErrNotFound for an unknown run and ErrArtifactNotFound for an
+// unknown artifact
+func (a *Archive) Artifact(workflowID, name string) (types.Artifact, error) {
+	wr, err := a.Get(workflowID)
+	if err != nil {
+		return types.Artifact{}, err
+	}
+	for _, artifact := range wr.Artifacts {
+		if artifact.Name != name {
+			continue
+		}
+		if err := artifact.Validate(); err != nil { // The run's file may have been edited by hand
+			return types.Artifact{}, err
+		}
+		artifact.Data, err = os.ReadFile(filepath.Join(a.artifactDir(workflowID), name))
+		if errors.Is(err, os.ErrNotExist) {
+			return types.Artifact{}, ErrArtifactNotFound
+		}
+		return artifact, err
+	}
+	return types.Artifact{}, ErrArtifactNotFound
+}
+
+// Run saves the result of every workflow that completes on bus until ctx is done
+func (a *Archive) Run(ctx context.Context, bus *types.EventBus) {
+	events := bus.SubscribeWithBuffer(100)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Type != types.EventWorkflowDone {
+				continue
+			}
+			wr, ok := event.Data.(*workflows.WorkflowResult)
+			if !ok {
+				continue
+			}
+			if err := a.Save(wr); err != nil {
+				slog.Error("archiving workflow result failed", "workflow", wr.WorkflowID, logging.KeyError, err)
+				continue
+			}
+			slog.Info("workflow result archived", "workflow", wr.WorkflowID, "dir", a.dir)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Package batch runs a file of independent tasks through the swarm and
+// records how each one went:
+//
+//	tasks, err := batch.Load("tasks.json")
+//	summary := batch.Run(ctx, s, tasks, progress)
+//	summary.Write("tasks-summary.json")
+//
+// Task files are JSON (an array of tasks, or {"tasks": [...]}) or CSV with a
+// header row. Both use the field names of POST /api/tasks:
+//
+//	[
+//	  {"id": "q1", "description": "Summarize the Go 1.22 release", "agent_type": "research"},
+//	  {"description": "Draft a changelog entry", "timeout_seconds": 120}
+//	]
+//
+//	id,description,agent_type,priority,timeout_seconds,callback_url,requires
+//	q1,Summarize the Go 1.22 release,research,,,,web-search
+//
+// CSV columns other than those become payload fields.
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/validate"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// DefaultWait is how long Run waits for a task without a timeout of its own
+const DefaultWait = 10 * time.Minute
+
+// Entry is one task as written in a task file, or inline in other files
+// such as experiments (see pkg/experiment)
+type Entry struct {
+	ID          string                 `json:"id,omitempty" yaml:"id"` // Generated from the line number when empty
+	Description string                 `json:"description" yaml:"description"`
+	AgentType   string                 `json:"agent_type,omitempty" yaml:"agent_type"` // Preferred specialty, e.g. "research"
+	Priority    int                    `json:"priority,omitempty" yaml:"priority"`
+	TimeoutSecs float64                `json:"timeout_seconds,omitempty" yaml:"timeout_seconds"` // Agent gives up after this long; 0 means no limit
+	CallbackURL string                 `json:"callback_url,omitempty" yaml:"callback_url"`
+	Payload     map[string]interface{} `json:"payload,omitempty" yaml:"payload"`
+	Requires    []string               `json:"requires,omitempty" yaml:"requires"` // Capability tags; space-separated in CSV
+	Locale      string                 `json:"locale,omitempty" yaml:"locale"`     // Language tag the agent answers in, e.g. "ja"
+}
+
+// Load reads a task file, choosing the format by extension (.json or .csv),
+// and returns its tasks. Every entry is checked, and all problems are
+// reported together.
+func Load(path string) ([]types.Task, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		entries, err = decodeJSON(f)
+	case ".csv":
+		entries, err = decodeCSV(f)
+	default:
+		return nil, fmt.Errorf("%s: unsupported task file type %q (use .json or .csv)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s: no tasks", path)
+	}
+
+	tasks, err := ToTasks(entries)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tasks, nil
+}
+
+// decodeJSON accepts a bare array of entries or an object with a "tasks" array
+func decodeJSON(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err == nil {
+		return entries, nil
+	}
+	var wrapped struct {
+		Tasks []Entry `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid task JSON: %w", err)
+	}
+	return wrapped.Tasks, nil
+}
+
+// decodeCSV reads entries from a CSV file whose first row names the columns
+func decodeCSV(r io.Reader) ([]Entry, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid task CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var entries []Entry
+	var errs []string
+	for n, record := range records[1:] {
+		var e Entry
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			switch header[i] {
+			case "id":
+				e.ID = value
+			case "description":
+				e.Description = value
+			case "agent_type":
+				e.AgentType = value
+			case "priority":
+				p, err := strconv.Atoi(value)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("row %d: priority %q is not a number", n+2, value))
+				}
+				e.Priority = p
+			case "timeout_seconds":
+				secs, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("row %d: timeout_seconds %q is not a number", n+2, value))
+				}
+				e.TimeoutSecs = secs
+			case "callback_url":
+				e.CallbackURL = value
+			case "requires":
+				e.Requires = strings.Fields(value)
+			case "locale":
+				e.Locale = value
+			default:
+				if e.Payload == nil {
+					e.Payload = make(map[string]interface{})
+				}
+				e.Payload[header[i]] = value
+			}
+		}
+		entries = append(entries, e)
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	return entries, nil
+}
+
+// ToTasks validates entries and converts them to swarm tasks
+func ToTasks(entries []Entry) ([]types.Task, error) {
+	var errs []string
+	seen := make(map[string]bool)
+	tasks := make([]types.Task, 0, len(entries))
+
+	for i, e := range entries {
+		if e.ID == "" {
+			e.ID = fmt.Sprintf("batch-%03d", i+1)
+		}
+		label := fmt.Sprintf("task %d (%s)", i+1, e.ID)
+
+		if strings.TrimSpace(e.Description) == "" {
+			errs = append(errs, label+": description is required")
+		}
+		if seen[e.ID] {
+			errs = append(errs, label+": duplicate id")
+		}
+		seen[e.ID] = true
+		if e.TimeoutSecs < 0 {
+			errs = append(errs, label+": timeout_seconds must not be negative")
+		}
+		if e.CallbackURL != "" {
+			if err := webhook.ValidateURL(e.CallbackURL); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", label, err))
+			}
+		}
+		for _, tag := range e.Requires {
+			if strings.TrimSpace(tag) == "" {
+				errs = append(errs, label+": requires must not contain empty tags")
+				break
+			}
+		}
+		if err := types.ValidateLocale(e.Locale); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", label, err))
+		}
+
+		payload := make(map[string]interface{}, len(e.Payload)+1)
+		for k, v := range e.Payload {
+			payload[k] = v
+		}
+		if e.AgentType != "" {
+			payload["agent_type"] = e.AgentType
+		}
+
+		task := types.Task{
+			ID:          e.ID,
+			Description: e.Description,
+			Payload:     payload,
+			Priority:    e.Priority,
+			Timeout:     time.Duration(e.TimeoutSecs * float64(time.Second)),
+			CallbackURL: e.CallbackURL,
+			Requires:    e.Requires,
+			Locale:      e.Locale,
+		}
+		if _, err := validate.SpecFor(task); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", label, err))
+		}
+		tasks = append(tasks, task)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	return tasks, nil
+}
+
+// Outcome is how a single task of a batch went
+type Outcome struct {
+	TaskID      string        `json:"task_id"`
+	Description string        `json:"description"`
+	AgentID     string        `json:"agent_id,omitempty"`
+	Success     bool          `json:"success"`
+	Data        interface{}   `json:"data,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Duration    time.Duration `json:"duration"` // Nanoseconds in JSON; from dispatch to result
+}
+
+// Summary is the record of a whole batch run
+type Summary struct {
+	RunID     string        `json:"run_id"` // Stamped on every task of the batch
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Duration  time.Duration `json:"duration"` // Nanoseconds in JSON
+	Total     int           `json:"total"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Outcomes  []Outcome     `json:"outcomes"` // In task file order
+}
+
+// Write saves the summary as indented JSON
+func (s *Summary) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// SummaryPath returns where the summary of a task file goes by default:
+// tasks.json → tasks-summary.json, next to the task file
+func SummaryPath(taskFile string) string {
+	ext := filepath.Ext(taskFile)
+	return strings.TrimSuffix(taskFile, ext) + "-summary.json"
+}
+
+// ProgressFunc is called each time a task finishes, with the number done so far
+type ProgressFunc func(done, total int, o Outcome)
+
+// Run distributes every task to the swarm at once, letting the agents' inboxes
+// queue the work, and waits until each has completed, failed, or outlived its
+// wait (its own Timeout plus a minute, or DefaultWait). If ctx is cancelled
+// the remaining tasks are recorded as failed. The tasks form one run, with a
+// new RunID.
+func Run(ctx context.Context, s *swarm.Swarm, tasks []types.Task, progress ProgressFunc) *Summary {
+	summary := &Summary{RunID: types.NewRunID(), StartTime: time.Now(), Total: len(tasks)}
+	outcomes := make([]Outcome, len(tasks))
+	index := make(map[string]int, len(tasks))
+	deadlines := make(map[string]time.Time, len(tasks))
+	dispatched := make(map[string]time.Time, len(tasks))
+
+	finish := func(i int, o Outcome) {
+		if start, ok := dispatched[o.TaskID]; ok {
+			o.Duration = time.Since(start)
+		}
+		outcomes[i] = o
+		delete(index, o.TaskID)
+		if o.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+		if progress != nil {
+			progress(summary.Succeeded+summary.Failed, summary.Total, o)
+		}
+	}
+
+	// Subscribe before dispatching so no result can slip past
+	events := s.GetEventBus().SubscribeWithBuffer(len(tasks) + 100)
+	defer s.GetEventBus().Unsubscribe(events)
+
+	for i, task := range tasks {
+		task.RunID = summary.RunID
+		index[task.ID] = i
+		dispatched[task.ID] = time.Now()
+		if err := s.DistributeTaskWithPriority(task, types.PriorityLow); err != nil {
+			finish(i, Outcome{TaskID: task.ID, Description: task.Description, Error: err.Error()})
+			continue
+		}
+		wait := DefaultWait
+		if task.Timeout > 0 {
+			wait = task.Timeout + time.Minute
+		}
+		deadlines[task.ID] = time.Now().Add(wait)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for len(index) > 0 {
+		select {
+		case <-ctx.Done():
+			for id, i := range index {
+				finish(i, Outcome{TaskID: id, Description: tasks[i].Description, Error: "batch cancelled"})
+			}
+
+		case event := <-events:
+			i, ok := index[event.TaskID]
+			if !ok || (event.Type != types.EventTaskCompleted && event.Type != types.EventTaskFailed) {
+				continue
+			}
+			o := Outcome{
+				TaskID:      event.TaskID,
+				Description: tasks[i].Description,
+				AgentID:     event.AgentID,
+				Success:     event.Type == types.EventTaskCompleted,
+			}
+			if r, ok := event.Data.(types.Result); ok {
+				o.Success = r.Success
+				o.Data = r.Data
+				if r.Error != nil {
+					o.Error = r.Error.Error()
+				}
+			}
+			if !o.Success && o.Error == "" {
+				o.Error = event.Message
+			}
+			finish(i, o)
+
+		case now := <-ticker.C:
+			for id, i := range index {
+				if now.After(deadlines[id]) {
+					finish(i, Outcome{TaskID: id, Description: tasks[i].Description, Error: "no result before the batch gave up waiting"})
+				}
+			}
+		}
+	}
+
+	summary.Outcomes = outcomes
+	summary.EndTime = time.Now()
+	summary.Duration = summary.EndTime.Sub(summary.StartTime)
+	return summary
+}
@@ -0,0 +1,316 @@
+// Package bench measures how fast the swarm moves tasks, with synthetic agents
+// that simulate work instead of calling an LLM, so scheduler and queue changes
+// can be compared run against run:
+//
+//	report, err := bench.Run(ctx, bench.Config{Agents: 8, Tasks: 5000})
+//	report.Write(os.Stdout)
+//
+// Tasks are submitted through the swarm's queue as fast as it takes them; each
+// task's latency runs from just before it is submitted to its result.
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+)
+
+// Defaults for the Config fields left zero
+const (
+	DefaultAgents   = 4
+	DefaultTasks    = 1000
+	DefaultWorkTime = 10 * time.Millisecond
+)
+
+// Config describes a benchmark run
+type Config struct {
+	Agents    int            // Synthetic agents; default DefaultAgents
+	Tasks     int            // Tasks to push through the swarm; default DefaultTasks
+	WorkTime  time.Duration  // Simulated work per task; default DefaultWorkTime
+	Workers   int            // Tasks each agent handles at once; default 1
+	QueueSize int            // Submission queue capacity; 0 keeps the swarm's default
+	Strategy  swarm.Strategy // Load balancing; "" is the swarm's default
+}
+
+// withDefaults fills in the zero fields
+func (c Config) withDefaults() Config {
+	if c.Agents == 0 {
+		c.Agents = DefaultAgents
+	}
+	if c.Tasks == 0 {
+		c.Tasks = DefaultTasks
+	}
+	if c.WorkTime == 0 {
+		c.WorkTime = DefaultWorkTime
+	}
+	if c.Workers == 0 {
+		c.Workers = 1
+	}
+	if c.Strategy == "" {
+		c.Strategy = swarm.StrategyLeastLoaded
+	}
+	return c
+}
+
+// validate checks the settings, listing every problem
+func (c Config) validate() error {
+	var errs []error
+	if c.Agents < 1 {
+		errs = append(errs, fmt.Errorf("agents must be at least 1, got %d", c.Agents))
+	}
+	if c.Tasks < 1 {
+		errs = append(errs, fmt.Errorf("tasks must be at least 1, got %d", c.Tasks))
+	}
+	if c.WorkTime < 0 {
+		errs = append(errs, fmt.Errorf("work time must not be negative, got %v", c.WorkTime))
+	}
+	if c.Workers < 1 {
+		errs = append(errs, fmt.Errorf("workers must be at least 1, got %d", c.Workers))
+	}
+	if c.QueueSize < 0 {
+		errs = append(errs, fmt.Errorf("queue size must not be negative, got %d", c.QueueSize))
+	}
+	if _, err := swarm.ParseStrategy(string(c.Strategy)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Report is the machine-readable result of a benchmark run
+type Report struct {
+	Agents       int              `json:"agents"`
+	Tasks        int              `json:"tasks"`
+	WorkTimeMs   float64          `json:"work_time_ms"`
+	Workers      int              `json:"workers"`
+	Strategy     swarm.Strategy   `json:"strategy"`
+	GoVersion    string           `json:"go_version"`
+	NumCPU       int              `json:"num_cpu"`
+	StartTime    time.Time        `json:"start_time"`
+	DurationSecs float64          `json:"duration_seconds"` // First submission to last result
+	Succeeded    int              `json:"succeeded"`
+	Failed       int              `json:"failed"`     // Including tasks the queue didn't accept
+	Unfinished   int              `json:"unfinished"` // No result before the run was stopped
+	Throughput   float64          `json:"throughput_per_second"`
+	Latency      Latency          `json:"latency_ms"`
+	Queue        swarm.QueueStats `json:"queue"` // At the end of the run
+}
+
+// Latency summarizes the tasks' submission-to-result times, in milliseconds
+type Latency struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// Write writes the report as indented JSON
+func (r *Report) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Run starts a swarm of synthetic agents, pushes the tasks through it, and
+// reports how it went once every task has a result. If ctx is done first, the
+// tasks still waiting are counted as unfinished.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	s := swarm.NewSwarm(swarm.WithStrategy(cfg.Strategy), swarm.WithQueueSize(cfg.QueueSize))
+	for i := 0; i < cfg.Agents; i++ {
+		a := newSyntheticAgent(fmt.Sprintf("bench-%03d", i+1), s.GetEventBus(), cfg.WorkTime, cfg.Workers)
+		if err := s.AddAgent(a); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		mu        sync.Mutex
+		submitted = make(map[string]time.Time, cfg.Tasks)
+		latencies = make([]time.Duration, 0, cfg.Tasks)
+		last      time.Time
+		results   int
+		allIn     = make(chan struct{})
+	)
+	report := &Report{
+		Agents:     cfg.Agents,
+		Tasks:      cfg.Tasks,
+		WorkTimeMs: float64(cfg.WorkTime) / float64(time.Millisecond),
+		Workers:    cfg.Workers,
+		Strategy:   cfg.Strategy,
+		GoVersion:  runtime.Version(),
+		NumCPU:     runtime.NumCPU(),
+	}
+
+	// Observed rather than subscribed, so no result is dropped however fast
+	// they come
+	s.GetEventBus().Observe(func(event types.Event) {
+		if event.Type != types.EventTaskCompleted && event.Type != types.EventTaskFailed {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+
+		at, ok := submitted[event.TaskID]
+		if !ok {
+			return
+		}
+		delete(submitted, event.TaskID)
+		last = time.Now()
+		if r, ok := event.Data.(types.Result); ok && r.Success && event.Type == types.EventTaskCompleted {
+			report.Succeeded++
+			latencies = append(latencies, last.Sub(at))
+		} else {
+			report.Failed++
+		}
+		if results++; results == cfg.Tasks {
+			close(allIn)
+		}
+	})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := s.Start(runCtx); err != nil {
+		return nil, err
+	}
+	defer s.Stop()
+
+	runID := types.NewRunID()
+	report.StartTime = time.Now()
+	for i := 0; i < cfg.Tasks; i++ {
+		task := types.Task{ID: fmt.Sprintf("bench-task-%06d", i+1), Description: "Synthetic benchmark task", RunID: runID}
+
+		mu.Lock()
+		submitted[task.ID] = time.Now()
+		mu.Unlock()
+
+		if err := s.Submit(ctx, task); err != nil {
+			mu.Lock()
+			delete(submitted, task.ID)
+			report.Failed++
+			if results++; results == cfg.Tasks {
+				close(allIn)
+			}
+			mu.Unlock()
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	select {
+	case <-allIn:
+	case <-ctx.Done():
+	}
+	report.Queue = s.QueueStats()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	report.Unfinished = cfg.Tasks - report.Succeeded - report.Failed
+	if !last.IsZero() {
+		report.DurationSecs = last.Sub(report.StartTime).Seconds()
+	}
+	if report.DurationSecs > 0 {
+		report.Throughput = float64(report.Succeeded) / report.DurationSecs
+	}
+	report.Latency = summarize(latencies)
+	return report, nil
+}
+
+// summarize computes the latency statistics, all zero when there are none
+func summarize(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	// Nearest rank: the smallest latency at least p percent of tasks beat or match
+	percentile := func(p int) float64 {
+		rank := (p*len(latencies) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return ms(latencies[rank-1])
+	}
+	return Latency{
+		Mean: ms(total / time.Duration(len(latencies))),
+		P50:  percentile(50),
+		P95:  percentile(95),
+		P99:  percentile(99),
+		Max:  ms(latencies[len(latencies)-1]),
+	}
+}
+
+// syntheticAgent completes every task after sleeping for its work time
+type syntheticAgent struct {
+	*agent.BaseAgent
+	eventBus *types.EventBus
+	workTime time.Duration
+}
+
+func newSyntheticAgent(id string, eventBus *types.EventBus, workTime time.Duration, workers int) *syntheticAgent {
+	a := &syntheticAgent{
+		BaseAgent: agent.NewBaseAgent(id, agent.WithWorkers(workers)),
+		eventBus:  eventBus,
+		workTime:  workTime,
+	}
+	a.RegisterHandler(types.MessageTypeTask, a.handleTask)
+	return a
+}
+
+// GetSpecialty returns "synthetic"; benchmark tasks don't ask for a specialty
+func (a *syntheticAgent) GetSpecialty() string {
+	return "synthetic"
+}
+
+// handleTask publishes the events a real agent would, without the LLM call
+func (a *syntheticAgent) handleTask(msg types.Message) error {
+	task, ok := msg.Content.(types.Task)
+	if !ok {
+		return fmt.Errorf("invalid task format")
+	}
+
+	publish := func(eventType types.EventType, data interface{}) {
+		a.eventBus.Publish(types.Event{
+			Type:      eventType,
+			Timestamp: time.Now(),
+			AgentID:   a.GetID(),
+			TaskID:    task.ID,
+			Data:      data,
+			RunID:     task.RunID,
+		})
+	}
+
+	publish(types.EventTaskStarted, nil)
+	select {
+	case <-time.After(a.workTime):
+	case <-a.TaskContextFor(task.ID).Done():
+		publish(types.EventTaskFailed, types.Result{TaskID: task.ID, Data: "Task cancelled", Failure: types.FailureCancelled, RunID: task.RunID})
+		return nil
+	}
+	publish(types.EventTaskCompleted, types.Result{TaskID: task.ID, Success: true, Data: "done", RunID: task.RunID})
+	return nil
+}
@@ -63,6 +63,39 @@ func (c *CLI) ShowMainMenu() {
 	fmt.Println("7. Run Stress Test")
 	fmt.Println("   → Process many tasks simultaneously")
 	fmt.Println()
+	fmt.Println("8. Run Workflow")
+	fmt.Println("   → SWOT, competitor comparison, literature review, product brief, or a definition from ./workflows")
+	fmt.Println()
+	fmt.Println("9. Export Last Result")
+	fmt.Println("   → Save the last workflow's report as Markdown, HTML, or PDF")
+	fmt.Println()
+	fmt.Println("10. View Past Runs")
+	fmt.Println("   → Reopen reports from earlier sessions")
+	fmt.Println()
+	fmt.Println("11. Chat with an Agent")
+	fmt.Println("   → Ask one agent follow-up questions in a running conversation")
+	fmt.Println()
+	fmt.Println("12. Plan a Goal")
+	fmt.Println("   → Let the planner agent break a goal into tasks and run them")
+	fmt.Println()
+	fmt.Println("13. Run a Debate")
+	fmt.Println("   → Agents argue a question over rounds of critique; a judge writes the consensus")
+	fmt.Println()
+	fmt.Println("14. Record Session")
+	fmt.Println("   → Start or stop capturing submitted tasks into a scenario file in ./scenarios")
+	fmt.Println()
+	fmt.Println("15. Replay Scenario")
+	fmt.Println("   → Resubmit a recorded scenario and check its tasks still succeed")
+	fmt.Println()
+	fmt.Println("16. Export Session Transcript")
+	fmt.Println("   → Save this session's tasks, assignments, events, and results as Markdown or JSON")
+	fmt.Println()
+	fmt.Println("17. Attach to Workflow")
+	fmt.Println("   → Follow a running workflow's steps and live progress until it finishes")
+	fmt.Println()
+	fmt.Println("18. Run Chaos Test")
+	fmt.Println("   → Kill agents and delay or garble LLM responses, then report how the swarm recovered")
+	fmt.Println()
 	fmt.Println("0. Exit")
 	fmt.Println(strings.Repeat("═", 70))
 }
@@ -85,6 +118,27 @@ func (c *CLI) GetInputWithDefault(prompt, defaultValue string) string {
 	return input
 }
 
+// GetMultiline reads lines until one containing only "." and returns them
+// joined, without the terminating line
+func (c *CLI) GetMultiline(prompt string) string {
+	fmt.Println(prompt + " (finish with a line containing only .)")
+	var lines []string
+	for {
+		line, err := c.reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			break
+		}
+		if line != "" || err == nil {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // GetChoice prompts for a menu choice
 func (c *CLI) GetChoice(prompt string) string {
 	fmt.Print("\n" + prompt + " > ")
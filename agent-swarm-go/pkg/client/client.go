@@ -0,0 +1,471 @@
+// Package client is a Go SDK for talking to a remote agent swarm over its
+// REST and WebSocket API (the endpoints served by pkg/web).
+//
+// It lets other Go services submit tasks, fetch results, and stream live
+// events without importing the swarm, agents, or web server packages.
+//
+// # Usage Example
+//
+//	c := client.NewClient("http://localhost:8080")
+//	c.SetToken(os.Getenv("DASHBOARD_TOKEN")) // only if the dashboard requires auth
+//
+//	resp, err := c.SubmitTask(ctx, client.TaskRequest{Description: "Research quantum computing"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	// Wait for it to finish, up to 5 minutes
+//	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+//	defer cancel()
+//	result, err := c.AwaitResult(waitCtx, resp.TaskID)
+//
+//	// Or follow everything the swarm does as it happens
+//	events, err := c.StreamEvents(ctx)
+//	for event := range events {
+//	    if event.TaskID == resp.TaskID && event.Type == types.EventTaskCompleted {
+//	        result, _ := c.Result(ctx, resp.TaskID)
+//	        fmt.Println(result.Data)
+//	    }
+//	}
+//
+// # Retries
+//
+// Requests that fail with a network error, 429, or 5xx status are retried with
+// exponential backoff (3 retries starting at 500ms by default, see SetRetries).
+// SubmitTask assigns a task ID before the first attempt, so a retried
+// submission reuses the same ID. StreamEvents reconnects automatically until
+// its context is cancelled, and resumes after the last event it received, so
+// events sent while it was disconnected still arrive (as long as the server
+// has kept them).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/agentstats"
+	"agent-swarm-go/pkg/types"
+
+	"github.com/gorilla/websocket"
+)
+
+// streamIdleTimeout is how long StreamEvents waits without an event or a
+// server ping before it treats the connection as dead and reconnects. The
+// server pings every 30 seconds.
+const streamIdleTimeout = 90 * time.Second
+
+// AwaitResult checks for a result after resultPollStart at first, then less
+// often, up to resultPollMax between checks
+const (
+	resultPollStart = 250 * time.Millisecond
+	resultPollMax   = 5 * time.Second
+)
+
+// ErrNotFound is returned when the requested resource (e.g. a task result) doesn't exist yet
+var ErrNotFound = errors.New("not found")
+
+// TaskRequest describes a task to submit (mirrors the POST /api/tasks body)
+type TaskRequest struct {
+	ID             string                 `json:"id,omitempty"` // Generated by the client when empty
+	Description    string                 `json:"description"`
+	Payload        interface{}            `json:"payload,omitempty"`
+	Priority       int                    `json:"priority,omitempty"`
+	Context        map[string]interface{} `json:"context,omitempty"`
+	Dependencies   []string               `json:"dependencies,omitempty"`
+	TimeoutSecs    float64                `json:"timeout_seconds,omitempty"` // Agent gives up after this long; 0 means no limit
+	CallbackURL    string                 `json:"callback_url,omitempty"`    // The swarm POSTs the result here when the task finishes
+	RunID          string                 `json:"run_id,omitempty"`          // Groups the task with others of the same run
+	Requires       []string               `json:"requires,omitempty"`        // Capability tags the agent must all have
+	Urgent         bool                   `json:"urgent,omitempty"`          // Taken ahead of the agent's waiting work
+	Source         string                 `json:"source,omitempty"`          // Who is submitting, for fair queuing; default the client's address
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"` // Submitting the same key again returns the first task instead of running another
+	Locale         string                 `json:"locale,omitempty"`          // Language tag the agent answers in, e.g. "ja" or "pt-BR"
+}
+
+// SubmitResponse is returned after a task has been accepted
+type SubmitResponse struct {
+	TaskID string `json:"task_id"` // The first task's ID when the idempotency key was already used
+	Status string `json:"status"`  // "queued", or "duplicate" for a repeated idempotency key
+}
+
+// TaskResult is the outcome of a finished task
+type TaskResult struct {
+	TaskID      string      `json:"task_id"`
+	AgentID     string      `json:"agent_id"`
+	RunID       string      `json:"run_id,omitempty"`
+	Success     bool        `json:"success"`
+	Data        interface{} `json:"data"`
+	Error       string      `json:"error,omitempty"`
+	Failure     string      `json:"failure,omitempty"` // "limit_exceeded" for sandbox resource violations
+	CompletedAt time.Time   `json:"completed_at"`
+
+	Metadata types.ResultMetadata `json:"metadata"` // Timing, model, tokens, and attempts
+}
+
+// AgentInfo describes one agent as reported by GET /api/agents
+type AgentInfo struct {
+	ID         string `json:"id"`
+	State      string `json:"state"`
+	Specialty  string `json:"specialty"`   // What it does, e.g. "research"; tasks name it as agent_type
+	InboxDepth int    `json:"inbox_depth"` // Queued messages, plus one while it works on a task
+}
+
+// APIError is returned when the server answers with a non-success status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("swarm API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// Client talks to a remote swarm. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// NewClient creates a client for the swarm served at baseURL (e.g. "http://localhost:8080")
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		retryDelay: 500 * time.Millisecond,
+	}
+}
+
+// SetToken sets the bearer token sent with every request (DASHBOARD_TOKEN on the server)
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetHTTPClient replaces the underlying HTTP client (e.g. to change timeouts or TLS settings)
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetRetries configures how many times failed requests are retried and the initial backoff delay
+func (c *Client) SetRetries(maxRetries int, initialDelay time.Duration) {
+	c.maxRetries = maxRetries
+	c.retryDelay = initialDelay
+}
+
+// Status returns the state of every agent keyed by agent ID
+func (c *Client) Status(ctx context.Context) (map[string]string, error) {
+	var status map[string]string
+	err := c.do(ctx, http.MethodGet, "/api/status", nil, &status)
+	return status, err
+}
+
+// Agents returns every agent with its current state keyed by agent ID
+func (c *Client) Agents(ctx context.Context) (map[string]AgentInfo, error) {
+	var agents map[string]AgentInfo
+	err := c.do(ctx, http.MethodGet, "/api/agents", nil, &agents)
+	return agents, err
+}
+
+// AgentStats returns an agent's totals, kept across the server's restarts:
+// tasks, failures, average latency, tokens, and cost
+func (c *Client) AgentStats(ctx context.Context, id string) (*agentstats.Stats, error) {
+	var stats agentstats.Stats
+	if err := c.do(ctx, http.MethodGet, "/api/agents/"+url.PathEscape(id)+"/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// PauseAgent stops an agent from taking new tasks until ResumeAgent is
+// called; tasks already queued for it wait in its inbox. It returns the agent
+// as it is afterwards.
+func (c *Client) PauseAgent(ctx context.Context, id string) (*AgentInfo, error) {
+	return c.controlAgent(ctx, id, "pause")
+}
+
+// ResumeAgent lets a paused agent continue with its queued tasks
+func (c *Client) ResumeAgent(ctx context.Context, id string) (*AgentInfo, error) {
+	return c.controlAgent(ctx, id, "resume")
+}
+
+// controlAgent sends a pause or resume action for an agent
+func (c *Client) controlAgent(ctx context.Context, id, action string) (*AgentInfo, error) {
+	var agent AgentInfo
+	if err := c.do(ctx, http.MethodPost, "/api/agents/"+url.PathEscape(id)+"/"+action, nil, &agent); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// SubmitTask sends a task to the swarm for distribution
+func (c *Client) SubmitTask(ctx context.Context, task TaskRequest) (*SubmitResponse, error) {
+	if task.ID == "" {
+		// Assign the ID up front so retries don't create differently-named duplicates
+		task.ID = fmt.Sprintf("client-%d", time.Now().UnixNano())
+	}
+
+	var resp SubmitResponse
+	if err := c.do(ctx, http.MethodPost, "/api/tasks", task, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Result fetches the result of a finished task. It returns ErrNotFound while
+// the task is still queued or running.
+func (c *Client) Result(ctx context.Context, taskID string) (*TaskResult, error) {
+	var result TaskResult
+	if err := c.do(ctx, http.MethodGet, "/api/results/"+url.PathEscape(taskID), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AwaitResult waits for a task to finish and returns its result, checking
+// /api/results less often the longer the task runs. A failed task's result
+// is returned too, with Success false; the error is for the wait itself,
+// e.g. ctx's when it is done first. Set a deadline on ctx: a task that is
+// never submitted, or whose result the server has forgotten, is waited for
+// until then.
+func (c *Client) AwaitResult(ctx context.Context, taskID string) (*TaskResult, error) {
+	delay := resultPollStart
+	for {
+		result, err := c.Result(ctx, taskID)
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("waiting for task %s: %w", taskID, ctx.Err())
+		case <-timer.C:
+		}
+		if delay *= 2; delay > resultPollMax {
+			delay = resultPollMax
+		}
+	}
+}
+
+// StreamEvents connects to the swarm's WebSocket and delivers events on the
+// returned channel until ctx is cancelled, reconnecting after connection
+// failures. The channel is closed when the stream ends.
+//
+// The first message after every (re)connect has Type "initial_status" and
+// carries the current agent states in Data.
+func (c *Client) StreamEvents(ctx context.Context) (<-chan types.Event, error) {
+	conn, err := c.dialEvents(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan types.Event, 100)
+	go func() {
+		defer close(events)
+
+		var lastID uint64
+		delay := c.retryDelay
+		for {
+			lastID = c.readEvents(ctx, conn, events, lastID)
+			conn.Close()
+
+			// Reconnect with backoff until the context ends
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+
+				conn, err = c.dialEvents(ctx, lastID)
+				if err == nil {
+					delay = c.retryDelay
+					break
+				}
+				delay = nextDelay(delay)
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// readEvents forwards events from one WebSocket connection until it fails,
+// goes quiet, or ctx ends. It returns the stream ID of the last event
+// forwarded, starting from lastID.
+func (c *Client) readEvents(ctx context.Context, conn *websocket.Conn, events chan<- types.Event, lastID uint64) uint64 {
+	// Unblock ReadJSON when the caller cancels
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	// Server pings keep the deadline moving while no events arrive
+	conn.SetReadDeadline(time.Now().Add(streamIdleTimeout))
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(streamIdleTimeout))
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(10*time.Second))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return nil
+		}
+		return err
+	})
+
+	for {
+		var event struct {
+			ID uint64 `json:"id"` // Position in the stream; 0 for messages that aren't events
+			types.Event
+		}
+		if err := conn.ReadJSON(&event); err != nil {
+			return lastID
+		}
+		conn.SetReadDeadline(time.Now().Add(streamIdleTimeout))
+		select {
+		case events <- event.Event:
+			if event.ID > 0 {
+				lastID = event.ID
+			}
+		case <-ctx.Done():
+			return lastID
+		}
+	}
+}
+
+// dialEvents opens the /ws connection, converting the base URL scheme to
+// ws/wss. A lastID above 0 asks for the events after it first; otherwise
+// the stream starts live, without the history the dashboard is sent.
+func (c *Client) dialEvents(ctx context.Context, lastID uint64) (*websocket.Conn, error) {
+	wsURL := "ws" + strings.TrimPrefix(c.baseURL, "http") + "/ws?replay=0"
+	if lastID > 0 {
+		wsURL = fmt.Sprintf("%s&last_event_id=%d", wsURL, lastID)
+	}
+
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		if resp != nil {
+			return nil, &APIError{StatusCode: resp.StatusCode, Message: err.Error()}
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// do performs a JSON request with retries and decodes the response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	delay := c.retryDelay
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay = nextDelay(delay)
+		}
+
+		retry, err := c.doOnce(ctx, method, path, payload, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// doOnce performs a single request. The bool result reports whether the
+// failure is transient and worth retrying.
+func (c *Client) doOnce(ctx context.Context, method, path string, payload []byte, out interface{}) (bool, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return false, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Network errors are transient unless the caller gave up
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, fmt.Errorf("%s %s: %w", method, path, ErrNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errBody struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &errBody) == nil && errBody.Error != "" {
+			apiErr.Message = errBody.Error
+		}
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, apiErr
+	}
+
+	if out == nil {
+		return false, nil
+	}
+	return false, json.Unmarshal(data, out)
+}
+
+// nextDelay doubles a backoff delay, capped at 30 seconds
+func nextDelay(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > 30*time.Second {
+		delay = 30 * time.Second
+	}
+	return delay
+}
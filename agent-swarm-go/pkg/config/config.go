@@ -0,0 +1,1266 @@
+// Package config loads the swarm composition from a YAML or JSON file.
+//
+// A config file declares which agents to run (type, ID, LLM provider and
+// model, and how many), where the web dashboard listens, and rate limits, so the swarm
+// can be reshaped without editing cmd/main.go.
+//
+// # Example swarm.yaml
+//
+//	web:
+//	  port: 8080
+//	  address: 127.0.0.1       # default: every interface
+//	  tls_cert: certs/dashboard.pem   # serve HTTPS (optional)
+//	  tls_key: certs/dashboard-key.pem
+//	  api_keys:                # roles: viewer, operator, admin (see web.Role)
+//	    - name: ci
+//	      key_env: CI_API_KEY
+//	      role: operator
+//	      namespaces: [default] # only these swarms (see namespaces below); default every one
+//	  event_text_limit: 4096   # bytes of output per live event; default 8192
+//	rate_limits:
+//	  llm_requests_per_minute: 60
+//	load_balancing: round_robin # or least_loaded (default), random, first_available
+//	agents:
+//	  - type: research
+//	    count: 2              # researcher-1, researcher-2
+//	    capabilities: [web-search]  # tags tasks can require
+//	  - type: analysis
+//	    id: analyzer-1
+//	    provider: anthropic
+//	    model: claude-3-5-sonnet-20241022
+//	    temperature: 0         # generation parameters: temperature, top_p, max_tokens, stop
+//	  - type: reporting
+//	    max_tokens: 4000
+//	  - type: external        # a program speaking JSON over stdio (see agents.ExternalAgent)
+//	    id: sentiment-1
+//	    specialty: sentiment
+//	    command: [python3, agents/sentiment.py]
+//
+// Agents in other processes (optional; see Transport):
+//
+//	transport:
+//	  redis: localhost:6379
+//	  remote: [research]      # served by processes running the worker command
+//
+// A durable task queue shared by several swarms (optional; see QueueConfig):
+//
+//	queue:
+//	  redis: localhost:6379
+//	  name: tasks
+//	  instance: coordinator-a  # stable per swarm, so a restart requeues its tasks
+//	leader_election:           # only the leader fires cron schedules
+//	  redis: localhost:6379
+//
+// Or an in-memory queue shared fairly between task sources, such as API
+// clients (optional; see FairQueue):
+//
+//	queue:
+//	  fair:
+//	    weights: {ci: 3}       # ci gets three dispatches to every other source's one
+//	    quotas: {ci: 500}      # most tasks a source may have waiting
+//	    default_quota: 100
+//
+// Chat notifications (optional; see Notifications):
+//
+//	notifications:
+//	  slack_webhook_url: https://hooks.slack.com/services/...
+//	  cost_summary: 24h
+//
+// Retries of LLM requests that fail with a network error, 429, or 5xx
+// (optional; see LLMRetry):
+//
+//	llm_retry:
+//	  max_attempts: 5          # default 3
+//	  initial_backoff: 2s      # default 1s, doubling after each attempt
+//	  max_backoff: 1m          # default 30s
+//
+// Latency and failures for mock mode, so a demo without an API key exercises
+// timeouts, retries, and progress displays (optional; see MockSimulation):
+//
+//	mock_simulation:
+//	  latency:
+//	    distribution: normal   # fixed, uniform, normal, or exponential
+//	    mean: 4s
+//	    stddev: 2s
+//	    min: 500ms
+//	    max: 15s
+//	  error_rate: 0.1          # 503s, retried under llm_retry
+//	  fatal_rate: 0.02         # 400s, not retried
+//	  hang_rate: 0.01          # never answer; the task times out
+//
+// The HTTP client LLM requests go through, for every provider or one
+// (optional; see LLMHTTP):
+//
+//	llm_http:
+//	  timeout: 3m              # per attempt; default 2m
+//	  proxy: http://proxy.internal:3128
+//	  providers:
+//	    anthropic:
+//	      timeout: 5m
+//
+// Routing requests to a cheap or premium model by task complexity and prompt
+// size, for agents without an explicit model (optional; see ModelRouting):
+//
+//	model_routing:
+//	  enabled: true
+//	  max_cheap_tokens: 800    # default 1000
+//	  models:
+//	    openai: {cheap: gpt-4o-mini, premium: gpt-4o}
+//
+// Cached LLM responses, so repeated requests cost nothing (optional; see LLMCache):
+//
+//	llm_cache:
+//	  type: disk               # or memory
+//	  dir: .llm-cache
+//
+// Agents' system prompts, overriding the built-in ones (optional; see Prompts):
+//
+//	prompts:
+//	  dir: prompts             # research.tmpl, critic.tmpl, ... (the default when it exists)
+//	  templates:
+//	    translation: Translate marketing copy playfully.{{with .Task.Payload.glossary}} Glossary: {{.}}{{end}}
+//
+// More secrets to scrub from events, logs, and the dashboard, on top of API
+// keys and the values of *_KEY, *_TOKEN, *_SECRET, and *_PASSWORD environment
+// variables (optional; see Redaction):
+//
+//	redaction:
+//	  patterns: ['acme_[0-9a-f]{32}']  # regular expressions
+//	  env: [DB_DSN]                    # variables whose values are secret
+//
+// Event types that custom agents publish, and how the dashboard shows them
+// (optional; see types.RegisterEventType):
+//
+//	event_types:
+//	  - type: price_alert
+//	    label: Price alert
+//	    icon: 💰
+//	    color: "#ff9800"
+//
+// More swarms in the same process, each with its own agents and events, served
+// under /ns/{name}/ on the same dashboard (optional; see Namespace):
+//
+//	namespaces:
+//	  team-a:
+//	    agents:
+//	      - type: research
+//	    load_balancing: round_robin
+//
+// Load rejects unknown keys and reports every validation problem at once,
+// each prefixed with its location (e.g. "agents[1].type"). A Reloader applies
+// later edits to the file to the running swarm.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"agent-swarm-go/pkg/agents"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/notify"
+	"agent-swarm-go/pkg/prompts"
+	"agent-swarm-go/pkg/redact"
+	"agent-swarm-go/pkg/sandbox"
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/transport"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/web"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// DefaultPath is the config file used when none is given explicitly
+const DefaultPath = "swarm.yaml"
+
+// Config describes a swarm
+type Config struct {
+	Agents     []AgentConfig `yaml:"agents" json:"agents"`
+	Web        WebConfig     `yaml:"web" json:"web"`
+	RateLimits RateLimits    `yaml:"rate_limits" json:"rate_limits"`
+	LLMRetry   LLMRetry      `yaml:"llm_retry" json:"llm_retry"`
+	LLMCache   LLMCache      `yaml:"llm_cache" json:"llm_cache"`
+	LLMHTTP    LLMHTTP       `yaml:"llm_http" json:"llm_http"`
+
+	MockSimulation MockSimulation `yaml:"mock_simulation" json:"mock_simulation"`
+
+	ModelRouting ModelRouting `yaml:"model_routing" json:"model_routing"`
+
+	// How tasks are spread over agents that can take them (see swarm.Strategy);
+	// empty means least_loaded
+	LoadBalancing string `yaml:"load_balancing" json:"load_balancing"`
+
+	Queue          QueueConfig    `yaml:"queue" json:"queue"`
+	LeaderElection LeaderElection `yaml:"leader_election" json:"leader_election"`
+	Notifications  Notifications  `yaml:"notifications" json:"notifications"`
+	Webhooks       Webhooks       `yaml:"webhooks" json:"webhooks"`
+	Transport      Transport      `yaml:"transport" json:"transport"`
+	Prompts        Prompts        `yaml:"prompts" json:"prompts"`
+	Redaction      Redaction      `yaml:"redaction" json:"redaction"`
+
+	EventTypes []EventType `yaml:"event_types" json:"event_types"`
+
+	// Other swarms in the same process, by name, e.g. one per team
+	Namespaces map[string]Namespace `yaml:"namespaces" json:"namespaces"`
+}
+
+// Namespace is another swarm run by the same process, with agents and events
+// of its own and its own dashboard under /ns/{name}/ (see
+// web.Server.AddNamespace). The rest of the config is shared.
+type Namespace struct {
+	Agents        []AgentConfig `yaml:"agents" json:"agents"`
+	LoadBalancing string        `yaml:"load_balancing" json:"load_balancing"` // Default the top-level load_balancing
+}
+
+// AgentConfig declares one agent, or Count identical agents
+type AgentConfig struct {
+	Type     string `yaml:"type" json:"type"`         // Agent type, see agents.Types()
+	ID       string `yaml:"id" json:"id"`             // Agent ID; with Count > 1 it is a prefix ("researcher" → researcher-1, researcher-2)
+	Provider string `yaml:"provider" json:"provider"` // "openai", "anthropic", "mock", or empty to auto-detect
+	Model    string `yaml:"model" json:"model"`       // Empty uses the provider's default
+	Count    int    `yaml:"count" json:"count"`       // Number of agents; 0 means 1
+	Workers  int    `yaml:"workers" json:"workers"`   // Tasks each agent works on at once; 0 means 1
+
+	// Tags tasks can require, e.g. [web-search, finance]; the swarm hands an
+	// agent only tasks whose requires it has all of
+	Capabilities []string `yaml:"capabilities" json:"capabilities"`
+
+	// Temperature, top_p, max_tokens, and stop, over the agent type's
+	// defaults (see agents.DefaultParams)
+	llm.GenerationParams `yaml:",inline"`
+
+	// External agents only (type "external")
+	Command   []string `yaml:"command" json:"command"`     // Program and arguments
+	Specialty string   `yaml:"specialty" json:"specialty"` // Agent type tasks name to reach it; default "external"
+	Dir       string   `yaml:"dir" json:"dir"`             // Working directory; empty uses the swarm's
+	CPUTime   string   `yaml:"cpu_time" json:"cpu_time"`   // CPU time a task may use, e.g. "10m"; empty means no limit
+	MemoryMB  int      `yaml:"memory_mb" json:"memory_mb"` // Memory (address space) it may use; 0 means 2048, negative no limit
+	Env       []string `yaml:"env" json:"env"`             // KEY=value pairs for the process, or KEY to pass the swarm's value on
+}
+
+// WebConfig configures the dashboard server. DASHBOARD_ADDR, DASHBOARD_PORT,
+// DASHBOARD_TLS_CERT, and DASHBOARD_TLS_KEY override it (see ListenConfig).
+type WebConfig struct {
+	Port    int    `yaml:"port" json:"port"`
+	Address string `yaml:"address" json:"address"`   // Interface to bind, e.g. 127.0.0.1; empty binds every interface
+	TLSCert string `yaml:"tls_cert" json:"tls_cert"` // PEM certificate file; with tls_key, the dashboard serves HTTPS
+	TLSKey  string `yaml:"tls_key" json:"tls_key"`   // PEM private key file
+
+	APIKeys []APIKey `yaml:"api_keys" json:"api_keys"` // Role-scoped keys for the dashboard and API
+
+	// Bytes of a message or result output sent in each /ws event; longer ones
+	// are cut and fetched whole on demand. 0 means 8192, negative no limit.
+	EventTextLimit int `yaml:"event_text_limit" json:"event_text_limit"`
+}
+
+// APIKey grants a client a role on the dashboard and API (see web.Role). The
+// key itself is a secret, so it can come from an environment variable instead
+// of the file.
+type APIKey struct {
+	Name       string   `yaml:"name" json:"name"`             // Who holds the key, e.g. "ci"
+	Key        string   `yaml:"key" json:"key"`               // Sent as "Authorization: Bearer <key>"
+	KeyEnv     string   `yaml:"key_env" json:"key_env"`       // Or the environment variable holding it
+	Role       string   `yaml:"role" json:"role"`             // viewer, operator, or admin
+	Namespaces []string `yaml:"namespaces" json:"namespaces"` // Where the key is good ("default" for the main swarm); default every namespace
+}
+
+// Auth returns the dashboard's access control: DASHBOARD_TOKEN and the other
+// DASHBOARD_* variables, plus the API keys. A key_env that isn't set, or only
+// one of DASHBOARD_USER and DASHBOARD_PASSWORD, is an error rather than a key
+// nobody can use or a dashboard open to everyone.
+func (w WebConfig) Auth() (web.AuthConfig, error) {
+	auth := web.AuthConfigFromEnv()
+	if err := auth.Validate(); err != nil {
+		return auth, err
+	}
+	for _, k := range w.APIKeys {
+		key := k.Key
+		if k.KeyEnv != "" {
+			if key = os.Getenv(k.KeyEnv); key == "" {
+				return auth, fmt.Errorf("web.api_keys.%s: %s is not set", k.Name, k.KeyEnv)
+			}
+		}
+		role, _ := web.ParseRole(k.Role)
+		auth.Keys = append(auth.Keys, web.APIKey{Name: k.Name, Key: key, Role: role, Namespaces: k.Namespaces})
+	}
+	return auth, nil
+}
+
+// ListenConfig returns where the dashboard listens, with the DASHBOARD_*
+// environment variables taking precedence over the file
+func (w WebConfig) ListenConfig() web.ListenConfig {
+	return web.ListenConfig{
+		Address: w.Address,
+		Port:    w.Port,
+		TLSCert: w.TLSCert,
+		TLSKey:  w.TLSKey,
+	}.FromEnv()
+}
+
+// RateLimits caps outgoing work
+type RateLimits struct {
+	LLMRequestsPerMinute int `yaml:"llm_requests_per_minute" json:"llm_requests_per_minute"` // Across all agents; 0 means unlimited
+}
+
+// LLMRetry says how LLM requests that fail for a transient reason are retried
+// (see llm.RetryPolicy); unset fields keep llm.DefaultRetryPolicy's values
+type LLMRetry struct {
+	MaxAttempts    int    `yaml:"max_attempts" json:"max_attempts"`       // Attempts per request, the first included; 1 disables retries
+	InitialBackoff string `yaml:"initial_backoff" json:"initial_backoff"` // Wait before the first retry, e.g. "1s"; doubles after each
+	MaxBackoff     string `yaml:"max_backoff" json:"max_backoff"`         // Longest wait, Retry-After included, e.g. "30s"
+}
+
+// Policy returns the retry policy; Validate has checked the durations
+func (r LLMRetry) Policy() llm.RetryPolicy {
+	policy := llm.RetryPolicy{MaxAttempts: r.MaxAttempts}
+	policy.InitialBackoff, _ = time.ParseDuration(r.InitialBackoff)
+	policy.MaxBackoff, _ = time.ParseDuration(r.MaxBackoff)
+	return policy
+}
+
+// MockSimulation gives mock mode a provider's latency and failures (see
+// llm.Simulation); unset, mock responses arrive at once and never fail
+type MockSimulation struct {
+	Latency   MockLatency `yaml:"latency" json:"latency"`
+	ErrorRate float64     `yaml:"error_rate" json:"error_rate"` // Share of attempts failing with a 503, which is retried
+	FatalRate float64     `yaml:"fatal_rate" json:"fatal_rate"` // Share rejected with a 400, which fails the task
+	HangRate  float64     `yaml:"hang_rate" json:"hang_rate"`   // Share never answering, until the task times out
+}
+
+// MockLatency is the distribution mock response times are drawn from (see
+// llm.Latency); durations are strings such as "2s"
+type MockLatency struct {
+	Distribution string `yaml:"distribution" json:"distribution"` // fixed (default), uniform, normal, or exponential
+	Mean         string `yaml:"mean" json:"mean"`
+	StdDev       string `yaml:"stddev" json:"stddev"`
+	Min          string `yaml:"min" json:"min"`
+	Max          string `yaml:"max" json:"max"`
+}
+
+// Simulation returns the simulation; Validate has checked it
+func (m MockSimulation) Simulation() llm.Simulation {
+	sim := llm.Simulation{
+		Latency:   llm.Latency{Distribution: m.Latency.Distribution},
+		ErrorRate: m.ErrorRate,
+		FatalRate: m.FatalRate,
+		HangRate:  m.HangRate,
+	}
+	sim.Latency.Mean, _ = time.ParseDuration(m.Latency.Mean)
+	sim.Latency.StdDev, _ = time.ParseDuration(m.Latency.StdDev)
+	sim.Latency.Min, _ = time.ParseDuration(m.Latency.Min)
+	sim.Latency.Max, _ = time.ParseDuration(m.Latency.Max)
+	return sim
+}
+
+// LLMHTTP configures the pooled HTTP clients LLM requests go through (see
+// llm.SetHTTPSettings). The top-level settings apply to every provider;
+// Providers overrides them for one, field by field.
+type LLMHTTP struct {
+	HTTPSettings `yaml:",inline"`
+	Providers    map[string]HTTPSettings `yaml:"providers" json:"providers"` // "openai" or "anthropic" -> settings
+}
+
+// HTTPSettings are the settings of one HTTP client; unset fields keep
+// llm.DefaultHTTPSettings' values
+type HTTPSettings struct {
+	Timeout             string `yaml:"timeout" json:"timeout"`                                 // Per attempt, e.g. "2m"
+	MaxIdleConnsPerHost int    `yaml:"max_idle_conns_per_host" json:"max_idle_conns_per_host"` // Keep-alive connections kept open
+	Proxy               string `yaml:"proxy" json:"proxy"`                                     // e.g. "http://proxy:3128"; empty uses HTTPS_PROXY
+}
+
+// settings converts to llm.HTTPSettings; Validate has checked the timeout
+func (h HTTPSettings) settings() llm.HTTPSettings {
+	s := llm.HTTPSettings{MaxIdleConnsPerHost: h.MaxIdleConnsPerHost, Proxy: h.Proxy}
+	s.Timeout, _ = time.ParseDuration(h.Timeout)
+	return s
+}
+
+// overlay returns h with every field o sets replaced
+func (h HTTPSettings) overlay(o HTTPSettings) HTTPSettings {
+	if o.Timeout != "" {
+		h.Timeout = o.Timeout
+	}
+	if o.MaxIdleConnsPerHost != 0 {
+		h.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.Proxy != "" {
+		h.Proxy = o.Proxy
+	}
+	return h
+}
+
+// Apply sets up the HTTP client of every provider
+func (l LLMHTTP) Apply() error {
+	if err := llm.SetHTTPSettings("", l.settings()); err != nil {
+		return fmt.Errorf("llm_http: %w", err)
+	}
+	for provider, settings := range l.Providers {
+		if err := llm.SetHTTPSettings(provider, l.overlay(settings).settings()); err != nil {
+			return fmt.Errorf("llm_http.providers.%s: %w", provider, err)
+		}
+	}
+	return nil
+}
+
+// validate reports problems with one client's settings under prefix
+func (h HTTPSettings) validate(prefix string, add func(format string, args ...interface{})) {
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil {
+			add("%s.timeout: %v", prefix, err)
+		} else if d <= 0 {
+			add("%s.timeout: must be positive", prefix)
+		}
+	}
+	if h.MaxIdleConnsPerHost < 0 {
+		add("%s.max_idle_conns_per_host: must not be negative", prefix)
+	}
+	if h.Proxy != "" {
+		if _, err := llm.ParseProxy(h.Proxy); err != nil {
+			add("%s.proxy: %v", prefix, err)
+		}
+	}
+}
+
+// ModelRouting sends each LLM request to a cheap or premium model (see
+// llm.Router)
+type ModelRouting struct {
+	Enabled        bool                      `yaml:"enabled" json:"enabled"`
+	MaxCheapTokens int                       `yaml:"max_cheap_tokens" json:"max_cheap_tokens"` // Largest prompt for the cheap model; 0 means 1000
+	Models         map[string]llm.ModelTiers `yaml:"models" json:"models"`                     // By provider; default llm.DefaultModelTiers
+}
+
+// Router returns the configured router, or nil when routing is off
+func (m ModelRouting) Router() *llm.Router {
+	if !m.Enabled {
+		return nil
+	}
+	return &llm.Router{MaxCheapTokens: m.MaxCheapTokens, Models: m.Models}
+}
+
+// LLMCache answers repeated LLM requests (same provider, model, and messages)
+// from a cache instead of the API (see llm.SetCache)
+type LLMCache struct {
+	Type       string `yaml:"type" json:"type"`               // "memory", "disk", or empty for no cache
+	Dir        string `yaml:"dir" json:"dir"`                 // Disk cache directory; default ".llm-cache"
+	MaxEntries int    `yaml:"max_entries" json:"max_entries"` // Memory cache size; 0 means unlimited
+}
+
+// DefaultLLMCacheDir is where the disk cache lives when the config names no
+// directory
+const DefaultLLMCacheDir = ".llm-cache"
+
+// Cache creates the configured cache, or returns nil when caching is off
+func (l LLMCache) Cache() (llm.Cache, error) {
+	switch l.Type {
+	case "memory":
+		return llm.NewMemoryCache(l.MaxEntries), nil
+	case "disk":
+		dir := l.Dir
+		if dir == "" {
+			dir = DefaultLLMCacheDir
+		}
+		cache, err := llm.NewDiskCache(dir)
+		if err != nil {
+			return nil, fmt.Errorf("llm_cache.dir: %w", err)
+		}
+		return cache, nil
+	}
+	return nil, nil
+}
+
+// Transport connects a coordinator to worker processes through a broker
+// (see pkg/transport). The same section tells a worker where to take tasks.
+type Transport struct {
+	Redis  string   `yaml:"redis" json:"redis"`   // "host:port" or "redis://:password@host:6379/0"
+	Prefix string   `yaml:"prefix" json:"prefix"` // Key namespace shared by coordinator and workers; default "swarm"
+	Remote []string `yaml:"remote" json:"remote"` // Specialties run by workers; the coordinator adds a remote agent for each
+}
+
+// Enabled reports whether a broker is configured
+func (t Transport) Enabled() bool {
+	return t.Redis != ""
+}
+
+// Connect opens the broker connection
+func (t Transport) Connect() (transport.Transport, error) {
+	return transport.NewRedis(t.Redis, t.Prefix)
+}
+
+// RemoteAgents creates the coordinator's stand-ins for the workers, one per
+// Remote specialty, with IDs like "remote-research"
+func (t Transport) RemoteAgents(conn transport.Transport) []types.Agent {
+	remote := make([]types.Agent, 0, len(t.Remote))
+	for _, specialty := range t.Remote {
+		remote = append(remote, transport.NewRemoteAgent("remote-"+specialty, specialty, conn))
+	}
+	return remote
+}
+
+// QueueConfig configures the queue tasks wait in between Submit and an agent
+// (see swarm.Submit). By default it is in memory and lost with the process.
+type QueueConfig struct {
+	Size     int    `yaml:"size" json:"size"`         // Most tasks that may wait; 0 means 1000 in memory, unbounded in Redis
+	Redis    string `yaml:"redis" json:"redis"`       // Keep queued tasks in Redis, "host:port" or a redis:// URL
+	Name     string `yaml:"name" json:"name"`         // Swarms with the same Redis and name share one queue; default "tasks"
+	Instance string `yaml:"instance" json:"instance"` // This swarm's stable name, for requeueing its tasks after a crash; default the hostname
+
+	Fair *FairQueue `yaml:"fair" json:"fair"` // Share the in-memory queue fairly between task sources
+}
+
+// FairQueue shares the queue between the sources tasks come from, e.g. API
+// clients, by weight, and caps how many tasks each may have waiting (see
+// swarm.FairPolicy)
+type FairQueue struct {
+	Weights       map[string]int `yaml:"weights" json:"weights"`               // Source -> weight
+	DefaultWeight int            `yaml:"default_weight" json:"default_weight"` // Default 1
+	Quotas        map[string]int `yaml:"quotas" json:"quotas"`                 // Source -> most tasks queued
+	DefaultQuota  int            `yaml:"default_quota" json:"default_quota"`   // Default no limit
+}
+
+// LeaderElection picks one of several swarms sharing a queue to run the
+// cluster-wide work: cron schedules and the cost summary (see
+// swarm.WithLeaderElection). Set Redis or LockFile.
+type LeaderElection struct {
+	Redis    string `yaml:"redis" json:"redis"`         // Compete for a lease in Redis
+	LockFile string `yaml:"lock_file" json:"lock_file"` // Or for a file lock, for swarms on one machine
+	Node     string `yaml:"node" json:"node"`           // This swarm's name; default the hostname
+}
+
+// Enabled reports whether leader election is configured
+func (l LeaderElection) Enabled() bool {
+	return l.Redis != "" || l.LockFile != ""
+}
+
+// Notifications configures chat notifications (see pkg/notify). The webhook
+// URLs are secrets, so they can also come from SLACK_WEBHOOK_URL and
+// DISCORD_WEBHOOK_URL instead of the file.
+type Notifications struct {
+	SlackWebhookURL   string `yaml:"slack_webhook_url" json:"slack_webhook_url"`
+	DiscordWebhookURL string `yaml:"discord_webhook_url" json:"discord_webhook_url"`
+	CostSummary       string `yaml:"cost_summary" json:"cost_summary"` // How often to post LLM spend, e.g. "24h"; empty disables it
+}
+
+// Webhooks configures delivery of task and workflow callbacks and
+// notifications (see pkg/webhook)
+type Webhooks struct {
+	// Hosts on a loopback or private network deliveries may reach, e.g.
+	// [hooks.internal, 10.0.0.5]; other internal addresses are refused
+	AllowHosts []string `yaml:"allow_hosts" json:"allow_hosts"`
+}
+
+// Prompts overrides agents' system prompts, which are Go templates keyed by
+// agent type (see agents.Prompts and pkg/prompts)
+type Prompts struct {
+	Dir       string            `yaml:"dir" json:"dir"`             // Directory of <agent type>.tmpl files; default "prompts", if it exists
+	Templates map[string]string `yaml:"templates" json:"templates"` // Agent type -> template; wins over Dir
+}
+
+// Apply loads the overrides into agents.Prompts and returns how many there
+// were. A missing default directory is skipped, but not one named in Dir.
+func (p Prompts) Apply() (int, error) {
+	dir := p.Dir
+	if dir == "" {
+		dir = prompts.DefaultDir
+	}
+	loaded, err := agents.Prompts.LoadDir(dir)
+	if errors.Is(err, os.ErrNotExist) && p.Dir == "" {
+		err = nil
+	}
+	if err != nil {
+		return loaded, fmt.Errorf("prompts.dir: %w", err)
+	}
+
+	for name, text := range p.Templates {
+		if err := agents.Prompts.Override(name, text, "config"); err != nil {
+			return loaded, fmt.Errorf("prompts.templates.%s: %w", name, err)
+		}
+		loaded++
+	}
+	return loaded, nil
+}
+
+// Redaction adds to the secrets scrubbed from events, logs, and the
+// dashboard (see pkg/redact)
+type Redaction struct {
+	Patterns []string `yaml:"patterns" json:"patterns"` // Regular expressions, e.g. "acme_[0-9a-f]{32}"
+	Env      []string `yaml:"env" json:"env"`           // Environment variables whose values are secret
+}
+
+// ApplyRedaction scrubs the redaction section's secrets from now on, and the
+// secrets elsewhere in the config: API keys and webhook URLs. Validate has
+// checked the patterns.
+func (c *Config) ApplyRedaction() error {
+	if err := redact.AddPatterns(c.Redaction.Patterns...); err != nil {
+		return fmt.Errorf("redaction.patterns: %w", err)
+	}
+	env := append([]string(nil), c.Redaction.Env...)
+	secrets := []string{c.Notifications.SlackWebhookURL, c.Notifications.DiscordWebhookURL}
+	for _, k := range c.Web.APIKeys {
+		if k.KeyEnv != "" {
+			env = append(env, k.KeyEnv)
+		}
+		secrets = append(secrets, k.Key)
+	}
+	redact.AddEnv(env...)
+	redact.AddSecrets(secrets...)
+	return nil
+}
+
+// EventType declares a custom event type agents publish (see
+// types.EventTypeInfo)
+type EventType struct {
+	Type  string `yaml:"type" json:"type"`   // e.g. "price_alert"
+	Label string `yaml:"label" json:"label"` // e.g. "Price alert"
+	Icon  string `yaml:"icon" json:"icon"`   // e.g. "💰"
+	Color string `yaml:"color" json:"color"` // e.g. "#ff9800" or "orange"
+}
+
+// info returns the event type as types.RegisterEventType takes it
+func (e EventType) info() types.EventTypeInfo {
+	return types.EventTypeInfo{Type: types.EventType(e.Type), Label: e.Label, Icon: e.Icon, Color: e.Color}
+}
+
+// ApplyEventTypes registers the event_types section's custom event types, so
+// agents can publish them and the dashboard shows them. Validate has checked
+// them.
+func (c *Config) ApplyEventTypes() error {
+	for i, e := range c.EventTypes {
+		if err := types.RegisterEventType(e.info()); err != nil {
+			return fmt.Errorf("event_types[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Strategy returns the load-balancing strategy; Validate has checked the name
+func (c *Config) Strategy() swarm.Strategy {
+	strategy, _ := swarm.ParseStrategy(c.LoadBalancing)
+	return strategy
+}
+
+// SwarmOptions returns the options for swarm.NewSwarm, connecting to Redis
+// for the queue and leader election if they use it
+func (c *Config) SwarmOptions() ([]swarm.Option, error) {
+	opts := []swarm.Option{swarm.WithStrategy(c.Strategy())}
+
+	if q := c.Queue; q.Redis == "" {
+		opts = append(opts, swarm.WithQueueSize(q.Size))
+		if f := q.Fair; f != nil {
+			opts = append(opts, swarm.WithFairQueuing(swarm.FairPolicy{
+				Weights:       f.Weights,
+				DefaultWeight: f.DefaultWeight,
+				Quotas:        f.Quotas,
+				DefaultQuota:  f.DefaultQuota,
+			}))
+		}
+	} else {
+		name := q.Name
+		if name == "" {
+			name = "tasks"
+		}
+		instance, err := nodeName(q.Instance)
+		if err != nil {
+			return nil, fmt.Errorf("queue.instance: %w", err)
+		}
+		conn, err := transport.NewRedis(q.Redis, "")
+		if err != nil {
+			return nil, fmt.Errorf("queue: %w", err)
+		}
+		backend, err := conn.Queue(name, instance, q.Size)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("queue: %w", err)
+		}
+		opts = append(opts, swarm.WithQueueBackend(backend))
+	}
+
+	if l := c.LeaderElection; l.Enabled() {
+		node, err := nodeName(l.Node)
+		if err != nil {
+			return nil, fmt.Errorf("leader_election.node: %w", err)
+		}
+		if l.LockFile != "" {
+			opts = append(opts, swarm.WithLeaderElection(node, swarm.NewFileLock(l.LockFile, node)))
+		} else {
+			conn, err := transport.NewRedis(l.Redis, "")
+			if err != nil {
+				return nil, fmt.Errorf("leader_election: %w", err)
+			}
+			opts = append(opts, swarm.WithLeaderElection(node, conn.LeaderLock(node)))
+		}
+	}
+	return opts, nil
+}
+
+// nodeName returns name, or the hostname when it is empty
+func nodeName(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("not set and no hostname: %w", err)
+	}
+	return host, nil
+}
+
+// NotifyConfig returns the notifier settings, filling unset webhook URLs from
+// the environment
+func (n Notifications) NotifyConfig() notify.Config {
+	cfg := notify.Config{
+		SlackWebhookURL:   n.SlackWebhookURL,
+		DiscordWebhookURL: n.DiscordWebhookURL,
+	}
+	if cfg.SlackWebhookURL == "" {
+		cfg.SlackWebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	}
+	if cfg.DiscordWebhookURL == "" {
+		cfg.DiscordWebhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
+	}
+	// Validate has already rejected a malformed interval
+	cfg.CostSummaryInterval, _ = time.ParseDuration(n.CostSummary)
+	return cfg
+}
+
+// defaultIDPrefixes names agents whose config gives no ID, matching the IDs
+// the swarm has always used (researcher-1, analyzer-1, ...)
+var defaultIDPrefixes = map[string]string{
+	"research":      "researcher",
+	"analysis":      "analyzer",
+	"reporting":     "reporter",
+	"documentation": "documenter",
+	"code":          "coder",
+	"critic":        "critic",
+	"summarizer":    "summarizer",
+	"translation":   "translator",
+	"planner":       "planner",
+}
+
+// Default returns the built-in swarm: one research, analysis, reporting, and
+// documentation agent on port 8080
+func Default() *Config {
+	return &Config{
+		Agents: []AgentConfig{
+			{Type: "research"},
+			{Type: "analysis"},
+			{Type: "reporting"},
+			{Type: "documentation"},
+		},
+		Web: WebConfig{Port: 8080},
+	}
+}
+
+// Load reads and validates a config file. The format is chosen by extension:
+// .json is JSON, anything else is YAML. Omitted settings take their defaults.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Web: WebConfig{Port: 8080}}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadDefault loads path, or DefaultPath when path is empty. A missing
+// DefaultPath isn't an error: the built-in Default config is returned.
+func LoadDefault(path string) (*Config, error) {
+	if path = ResolvePath(path); path == "" {
+		return Default(), nil
+	}
+	return Load(path)
+}
+
+// ResolvePath returns the file LoadDefault reads for path: path itself, or
+// DefaultPath when path is empty, or "" when that is missing too and the
+// built-in Default config is used
+func ResolvePath(path string) string {
+	if path != "" {
+		return path
+	}
+	if _, err := os.Stat(DefaultPath); errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	return DefaultPath
+}
+
+// Validate reports every problem in the config, joined into one error
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(c.Agents) == 0 && len(c.Transport.Remote) == 0 {
+		add("agents: at least one agent is required")
+	}
+
+	seen := validateAgents("agents", c.Agents, add)
+	for _, name := range c.NamespaceNames() {
+		ns := c.Namespaces[name]
+		if err := web.ValidateNamespace(name); err != nil {
+			add("namespaces: %v", err)
+		}
+		if len(ns.Agents) == 0 {
+			add("namespaces.%s.agents: at least one agent is required", name)
+		}
+		validateAgents("namespaces."+name+".agents", ns.Agents, add)
+		if _, err := swarm.ParseStrategy(ns.LoadBalancing); err != nil {
+			add("namespaces.%s.load_balancing: %v", name, err)
+		}
+	}
+
+	if c.Web.Port < 1 || c.Web.Port > 65535 {
+		add("web.port: %d is not a valid port", c.Web.Port)
+	}
+	if strings.ContainsAny(c.Web.Address, "/ ") {
+		add("web.address: %q is not a host name or IP address", c.Web.Address)
+	}
+	if (c.Web.TLSCert == "") != (c.Web.TLSKey == "") {
+		add("web: tls_cert and tls_key go together")
+	}
+	keyNames := make(map[string]bool)
+	for i, k := range c.Web.APIKeys {
+		if k.Name == "" {
+			add("web.api_keys[%d]: name is required", i)
+		} else if keyNames[k.Name] {
+			add("web.api_keys: name %q is used twice", k.Name)
+		}
+		keyNames[k.Name] = true
+		if (k.Key == "") == (k.KeyEnv == "") {
+			add("web.api_keys[%d]: set one of key and key_env", i)
+		}
+		if _, err := web.ParseRole(k.Role); err != nil {
+			add("web.api_keys[%d].role: %v", i, err)
+		}
+		for _, ns := range k.Namespaces {
+			if _, ok := c.Namespaces[ns]; !ok && ns != web.DefaultNamespace {
+				add("web.api_keys[%d].namespaces: unknown namespace %q", i, ns)
+			}
+		}
+	}
+	if _, err := swarm.ParseStrategy(c.LoadBalancing); err != nil {
+		add("load_balancing: %v", err)
+	}
+	if c.Queue.Size < 0 {
+		add("queue.size: must not be negative")
+	}
+	if c.Queue.Redis == "" && (c.Queue.Name != "" || c.Queue.Instance != "") {
+		add("queue: name and instance need queue.redis")
+	}
+	if f := c.Queue.Fair; f != nil {
+		if c.Queue.Redis != "" {
+			add("queue.fair: only for the in-memory queue, not with queue.redis")
+		}
+		if f.DefaultWeight < 0 {
+			add("queue.fair.default_weight: must not be negative")
+		}
+		if f.DefaultQuota < 0 {
+			add("queue.fair.default_quota: must not be negative")
+		}
+		for source, weight := range f.Weights {
+			if weight < 1 {
+				add("queue.fair.weights.%s: must be at least 1", source)
+			}
+		}
+		for source, quota := range f.Quotas {
+			if quota < 0 {
+				add("queue.fair.quotas.%s: must not be negative", source)
+			}
+		}
+	}
+	if l := c.LeaderElection; l.Redis != "" && l.LockFile != "" {
+		add("leader_election: set redis or lock_file, not both")
+	} else if l.Node != "" && !l.Enabled() {
+		add("leader_election.node: needs redis or lock_file")
+	}
+	if c.RateLimits.LLMRequestsPerMinute < 0 {
+		add("rate_limits.llm_requests_per_minute: must not be negative")
+	}
+	if c.LLMRetry.MaxAttempts < 0 {
+		add("llm_retry.max_attempts: must not be negative")
+	}
+	for _, backoff := range []struct{ key, value string }{
+		{"initial_backoff", c.LLMRetry.InitialBackoff},
+		{"max_backoff", c.LLMRetry.MaxBackoff},
+	} {
+		if backoff.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(backoff.value); err != nil {
+			add("llm_retry.%s: %v", backoff.key, err)
+		} else if d <= 0 {
+			add("llm_retry.%s: must be positive", backoff.key)
+		}
+	}
+	durationsOK := true
+	for _, latency := range []struct{ key, value string }{
+		{"mean", c.MockSimulation.Latency.Mean},
+		{"stddev", c.MockSimulation.Latency.StdDev},
+		{"min", c.MockSimulation.Latency.Min},
+		{"max", c.MockSimulation.Latency.Max},
+	} {
+		if latency.value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(latency.value); err != nil {
+			add("mock_simulation.latency.%s: %v", latency.key, err)
+			durationsOK = false
+		}
+	}
+	if durationsOK {
+		if err := c.MockSimulation.Simulation().Validate(); err != nil {
+			add("mock_simulation: %v", err)
+		}
+	}
+	c.LLMHTTP.HTTPSettings.validate("llm_http", add)
+	providers := make([]string, 0, len(c.LLMHTTP.Providers))
+	for provider := range c.LLMHTTP.Providers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		switch provider {
+		case "openai", "anthropic":
+			c.LLMHTTP.Providers[provider].validate("llm_http.providers."+provider, add)
+		default:
+			add("llm_http.providers.%s: unknown provider (want openai or anthropic)", provider)
+		}
+	}
+	if c.ModelRouting.MaxCheapTokens < 0 {
+		add("model_routing.max_cheap_tokens: must not be negative")
+	}
+	routed := make([]string, 0, len(c.ModelRouting.Models))
+	for provider := range c.ModelRouting.Models {
+		routed = append(routed, provider)
+	}
+	sort.Strings(routed)
+	for _, provider := range routed {
+		tiers := c.ModelRouting.Models[provider]
+		switch {
+		case provider != "openai" && provider != "anthropic":
+			add("model_routing.models.%s: unknown provider (want openai or anthropic)", provider)
+		case tiers.Cheap == "" || tiers.Premium == "":
+			add("model_routing.models.%s: both cheap and premium are required", provider)
+		}
+	}
+	switch c.LLMCache.Type {
+	case "", "memory", "disk":
+	default:
+		add("llm_cache.type: unknown cache type %q (want memory or disk)", c.LLMCache.Type)
+	}
+	if c.LLMCache.MaxEntries < 0 {
+		add("llm_cache.max_entries: must not be negative")
+	}
+	if c.LLMCache.Type != "disk" && c.LLMCache.Dir != "" {
+		add("llm_cache.dir: only for the disk cache")
+	}
+
+	if url := c.Notifications.SlackWebhookURL; url != "" {
+		if err := webhook.ValidateURL(url); err != nil {
+			add("notifications.slack_webhook_url: %v", err)
+		}
+	}
+	if url := c.Notifications.DiscordWebhookURL; url != "" {
+		if err := webhook.ValidateURL(url); err != nil {
+			add("notifications.discord_webhook_url: %v", err)
+		}
+	}
+	if len(c.Transport.Remote) > 0 && !c.Transport.Enabled() {
+		add("transport.remote: needs transport.redis")
+	}
+	remote := map[string]bool{}
+	for i, specialty := range c.Transport.Remote {
+		if specialty == "" {
+			add("transport.remote[%d]: empty specialty", i)
+		} else if remote[specialty] {
+			add("transport.remote: duplicate specialty %q", specialty)
+		} else if seen["remote-"+specialty] {
+			add("transport.remote: remote-%s clashes with an agent ID", specialty)
+		}
+		remote[specialty] = true
+	}
+
+	promptNames := make([]string, 0, len(c.Prompts.Templates))
+	for name := range c.Prompts.Templates {
+		promptNames = append(promptNames, name)
+	}
+	sort.Strings(promptNames)
+	for _, name := range promptNames {
+		if err := agents.Prompts.Check(name, c.Prompts.Templates[name]); err != nil {
+			add("prompts.templates.%s: %v", name, err)
+		}
+	}
+
+	if c.Notifications.CostSummary != "" {
+		if d, err := time.ParseDuration(c.Notifications.CostSummary); err != nil {
+			add("notifications.cost_summary: %v", err)
+		} else if d <= 0 {
+			add("notifications.cost_summary: must be positive")
+		}
+	}
+
+	for i, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			add("redaction.patterns[%d]: %v", i, err)
+		}
+	}
+	for i, name := range c.Redaction.Env {
+		if name == "" {
+			add("redaction.env[%d]: must name an environment variable", i)
+		}
+	}
+
+	seenEventTypes := make(map[string]bool)
+	for i, e := range c.EventTypes {
+		if err := e.info().Validate(); err != nil {
+			add("event_types[%d]: %v", i, err)
+		} else if seenEventTypes[e.Type] {
+			add("event_types[%d]: event type %q is declared twice", i, e.Type)
+		}
+		seenEventTypes[e.Type] = true
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid swarm config:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// validateAgents checks a list of agents, reporting problems under key, and
+// returns the IDs they will have
+func validateAgents(key string, list []AgentConfig, add func(format string, args ...interface{})) map[string]bool {
+	agentTypes := append(agents.Types(), agents.ExternalType)
+	known := map[string]bool{}
+	for _, t := range agentTypes {
+		known[t] = true
+	}
+	for i, a := range list {
+		if !known[a.Type] {
+			add("%s[%d].type: unknown agent type %q (want one of: %s)", key, i, a.Type, strings.Join(agentTypes, ", "))
+		}
+		if a.Type == agents.ExternalType {
+			if len(a.Command) == 0 || a.Command[0] == "" {
+				add("%s[%d].command: required for external agents", key, i)
+			}
+			if a.CPUTime != "" {
+				if d, err := time.ParseDuration(a.CPUTime); err != nil || d <= 0 {
+					add("%s[%d].cpu_time: want a positive duration like \"10m\"", key, i)
+				}
+			}
+		} else if len(a.Command) > 0 || a.Specialty != "" || a.Dir != "" || a.CPUTime != "" || a.MemoryMB != 0 || len(a.Env) > 0 {
+			add("%s[%d]: command, specialty, dir, cpu_time, memory_mb, and env are only for external agents", key, i)
+		}
+		switch a.Provider {
+		case "", "openai", "anthropic", "mock":
+		default:
+			add("%s[%d].provider: unknown provider %q (want openai, anthropic, or mock)", key, i, a.Provider)
+		}
+		if a.Count < 0 {
+			add("%s[%d].count: must not be negative", key, i)
+		}
+		if a.Workers < 0 {
+			add("%s[%d].workers: must not be negative", key, i)
+		} else if a.Workers > 1 && a.Type == agents.ExternalType {
+			add("%s[%d].workers: external agents run one task at a time", key, i)
+		}
+		for j, tag := range a.Capabilities {
+			if strings.TrimSpace(tag) == "" {
+				add("%s[%d].capabilities[%d]: must not be empty", key, i, j)
+			}
+		}
+		if !a.GenerationParams.IsZero() && a.Type == agents.ExternalType {
+			add("%s[%d]: temperature, top_p, max_tokens, and stop are only for LLM agents", key, i)
+		} else if err := a.GenerationParams.Validate(); err != nil {
+			add("%s[%d]: %v", key, i, err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, spec := range expand(list) {
+		if seen[spec.ID] {
+			add("%s: duplicate agent ID %q", key, spec.ID)
+		}
+		seen[spec.ID] = true
+	}
+	return seen
+}
+
+// expand turns each AgentConfig into Count agents with concrete IDs
+func expand(list []AgentConfig) []AgentConfig {
+	var specs []AgentConfig
+	for _, a := range list {
+		count := a.Count
+		if count == 0 {
+			count = 1
+		}
+
+		prefix := a.ID
+		if prefix == "" {
+			prefix = defaultIDPrefixes[a.Type]
+			if prefix == "" {
+				prefix = a.Type
+			}
+		}
+
+		for n := 1; n <= count; n++ {
+			spec := a
+			spec.Count = 1
+			switch {
+			case a.ID != "" && count == 1:
+				spec.ID = a.ID // Used exactly as written
+			default:
+				spec.ID = fmt.Sprintf("%s-%d", prefix, n)
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// NewAgents creates every agent the config declares, each with an LLM client
+// for its provider and model. The agents are not added to a swarm.
+func (c *Config) NewAgents(eventBus *types.EventBus) ([]types.Agent, error) {
+	return newAgents(c.Agents, eventBus)
+}
+
+// NamespaceNames lists the namespaces, sorted
+func (c *Config) NamespaceNames() []string {
+	names := make([]string, 0, len(c.Namespaces))
+	for name := range c.Namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewNamespaceAgents creates the agents of one namespace, like NewAgents
+func (c *Config) NewNamespaceAgents(name string, eventBus *types.EventBus) ([]types.Agent, error) {
+	ns, ok := c.Namespaces[name]
+	if !ok {
+		return nil, fmt.Errorf("no namespace %q", name)
+	}
+	return newAgents(ns.Agents, eventBus)
+}
+
+// NamespaceOptions returns the options for a namespace's swarm.NewSwarm
+func (c *Config) NamespaceOptions(name string) []swarm.Option {
+	strategy := c.Strategy()
+	if ns := c.Namespaces[name]; ns.LoadBalancing != "" {
+		strategy, _ = swarm.ParseStrategy(ns.LoadBalancing)
+	}
+	return []swarm.Option{swarm.WithStrategy(strategy)}
+}
+
+// defaultExternalMemoryMB is the memory an external agent's process may use
+// unless memory_mb says otherwise
+const defaultExternalMemoryMB = 2048
+
+// externalLimits returns the limits an external agent's process runs under
+func (a AgentConfig) externalLimits() sandbox.Limits {
+	var limits sandbox.Limits
+	limits.CPUTime, _ = time.ParseDuration(a.CPUTime)
+	switch {
+	case a.MemoryMB == 0:
+		limits.Memory = defaultExternalMemoryMB << 20
+	case a.MemoryMB > 0:
+		limits.Memory = int64(a.MemoryMB) << 20
+	}
+	return limits
+}
+
+// externalEnv returns the variables an external agent's process gets on top
+// of sandbox.Env, with the swarm's values for those named without one
+func (a AgentConfig) externalEnv() []string {
+	var env []string
+	for _, kv := range a.Env {
+		if !strings.Contains(kv, "=") {
+			kv += "=" + os.Getenv(kv)
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// newAgents creates the agents of a list
+func newAgents(list []AgentConfig, eventBus *types.EventBus) ([]types.Agent, error) {
+	var created []types.Agent
+	for _, spec := range expand(list) {
+		if spec.Type == agents.ExternalType {
+			agent, err := agents.NewExternalAgent(spec.ID, eventBus, agents.ExternalConfig{
+				Command:   spec.Command,
+				Specialty: spec.Specialty,
+				Dir:       spec.Dir,
+				Env:       spec.externalEnv(),
+				Limits:    spec.externalLimits(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := setCapabilities(agent, spec); err != nil {
+				return nil, err
+			}
+			created = append(created, agent)
+			continue
+		}
+
+		client, err := llm.NewClientFor(spec.Provider, spec.Model)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", spec.ID, err)
+		}
+		agent, err := agents.New(spec.Type, spec.ID, eventBus, client.WithParams(spec.GenerationParams))
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", spec.ID, err)
+		}
+		if spec.Workers > 1 {
+			concurrent, ok := agent.(interface{ SetWorkers(int) error })
+			if !ok {
+				return nil, fmt.Errorf("agent %s: %s agents can't run tasks concurrently", spec.ID, spec.Type)
+			}
+			if err := concurrent.SetWorkers(spec.Workers); err != nil {
+				return nil, err
+			}
+		}
+		if err := setCapabilities(agent, spec); err != nil {
+			return nil, err
+		}
+		created = append(created, agent)
+	}
+	return created, nil
+}
+
+// setCapabilities gives an agent the capability tags its spec lists
+func setCapabilities(agent types.Agent, spec AgentConfig) error {
+	if len(spec.Capabilities) == 0 {
+		return nil
+	}
+	tagged, ok := agent.(interface{ SetCapabilities(...string) error })
+	if !ok {
+		return fmt.Errorf("agent %s: %s agents can't have capabilities", spec.ID, spec.Type)
+	}
+	return tagged.SetCapabilities(spec.Capabilities...)
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// ReloadInterval is how often a Reloader checks its file for changes
+const ReloadInterval = 2 * time.Second
+
+// Reloader applies edits to a swarm's config file while the swarm runs.
+// Agents added to the file are started, removed ones stopped, and ones whose
+// settings changed (model, provider, generation parameters, ...) replaced by a
+// new agent with the same ID; like DELETE /api/agents/{id}, stopping an agent
+// cancels its running and queued tasks. rate_limits, llm_retry,
+// model_routing, and mock_simulation take effect straight away. Other sections, such as web,
+// queue, or namespaces, are only read at start-up: a change to them is
+// reported, and applied by restarting.
+//
+// Each reload publishes an EventConfigChanged event with a
+// types.ConfigChange, or an EventConfigRejected event when the file no
+// longer loads; the swarm then keeps running as it was.
+type Reloader struct {
+	path  string
+	swarm *swarm.Swarm
+
+	mu      sync.Mutex
+	current *Config
+	data    []byte                 // The file as last loaded, to skip saves that change nothing
+	agents  map[string]AgentConfig // Agents started from the config, by ID, as they run
+	modTime time.Time
+}
+
+// NewReloader returns a Reloader for the config loaded from path, whose
+// agents s is running
+func NewReloader(path string, current *Config, s *swarm.Swarm) *Reloader {
+	r := &Reloader{
+		path:    path,
+		swarm:   s,
+		current: current,
+		agents:  make(map[string]AgentConfig),
+	}
+	for _, spec := range expand(current.Agents) {
+		r.agents[spec.ID] = spec
+	}
+	if info, err := os.Stat(path); err == nil {
+		r.modTime = info.ModTime()
+	}
+	r.data, _ = os.ReadFile(path)
+	return r
+}
+
+// Run checks the file every ReloadInterval and reloads it when it has been
+// saved, until ctx is done
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(r.path)
+		if err != nil || info.ModTime().Equal(r.modTime) {
+			continue // A missing file is usually an editor halfway through saving
+		}
+		r.modTime = info.ModTime()
+		r.Reload()
+	}
+}
+
+// Reload loads the file and applies what changed. The returned change is
+// empty when the file is as last loaded.
+func (r *Reloader) Reload() (types.ConfigChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	change := types.ConfigChange{Path: r.path}
+	data, err := os.ReadFile(r.path)
+	if err == nil && bytes.Equal(data, r.data) {
+		return change, nil
+	}
+	next, err := Load(r.path)
+	if err != nil {
+		slog.Warn("config reload rejected, keeping the running config", "path", r.path, logging.KeyError, err)
+		r.publish(types.EventConfigRejected, fmt.Sprintf("⚠️  Config not reloaded: %v", err), nil)
+		return change, err
+	}
+	r.data = data
+
+	r.applyAgents(next, &change)
+	r.applySettings(next, &change)
+	r.current = next
+
+	if change.Empty() {
+		return change, nil
+	}
+	slog.Info("config reloaded", "path", r.path,
+		"added", change.Added, "removed", change.Removed, "replaced", change.Replaced,
+		"applied", change.Applied, "restart", change.Restart)
+	for _, problem := range change.Errors {
+		slog.Warn("config reload: agent not changed", "path", r.path, logging.KeyError, problem)
+	}
+	r.publish(types.EventConfigChanged, change.Summary(), change)
+	return change, nil
+}
+
+// applyAgents brings the running agents in line with next's: removed ones
+// first, so an ID can move to another agent type, then replaced ones, then
+// new ones
+func (r *Reloader) applyAgents(next *Config, change *types.ConfigChange) {
+	wanted := make(map[string]AgentConfig)
+	for _, spec := range expand(next.Agents) {
+		wanted[spec.ID] = spec
+	}
+
+	for _, id := range sortedIDs(r.agents) {
+		if _, ok := wanted[id]; ok {
+			continue
+		}
+		if _, err := r.swarm.GetAgent(id); err != nil {
+			delete(r.agents, id) // Already removed, e.g. with DELETE /api/agents/{id}
+			continue
+		}
+		if err := r.swarm.RemoveAgent(id); err != nil {
+			change.Errors = append(change.Errors, fmt.Sprintf("removing %s: %v", id, err))
+			continue
+		}
+		delete(r.agents, id)
+		change.Removed = append(change.Removed, id)
+	}
+
+	for _, id := range sortedIDs(wanted) {
+		spec := wanted[id]
+		old, running := r.agents[id]
+		if running && reflect.DeepEqual(old, spec) {
+			continue
+		}
+
+		// Build the new agent before stopping the old one, so a spec that
+		// can't be built leaves the agent running
+		created, err := newAgents([]AgentConfig{spec}, r.swarm.GetEventBus())
+		if err != nil {
+			change.Errors = append(change.Errors, err.Error())
+			continue
+		}
+		if running {
+			if err := r.swarm.RemoveAgent(id); err != nil {
+				change.Errors = append(change.Errors, fmt.Sprintf("replacing %s: %v", id, err))
+				continue
+			}
+			delete(r.agents, id)
+		}
+		if err := r.swarm.JoinAgent(created[0]); err != nil {
+			change.Errors = append(change.Errors, fmt.Sprintf("starting %s: %v", id, err))
+			if running {
+				change.Removed = append(change.Removed, id)
+			}
+			continue
+		}
+		r.agents[id] = spec
+		if running {
+			change.Replaced = append(change.Replaced, id)
+		} else {
+			change.Added = append(change.Added, id)
+		}
+	}
+}
+
+// applySettings puts next's rate limit, retry policy, model routing, and mock
+// simulation in force, and lists the changed sections that need a restart
+func (r *Reloader) applySettings(next *Config, change *types.ConfigChange) {
+	cur := r.current
+	if cur.RateLimits != next.RateLimits {
+		llm.SetRateLimit(next.RateLimits.LLMRequestsPerMinute)
+		change.Applied = append(change.Applied, "rate_limits")
+	}
+	if cur.LLMRetry != next.LLMRetry {
+		llm.SetRetryPolicy(next.LLMRetry.Policy())
+		change.Applied = append(change.Applied, "llm_retry")
+	}
+	if !reflect.DeepEqual(cur.ModelRouting, next.ModelRouting) {
+		llm.SetRouter(next.ModelRouting.Router())
+		change.Applied = append(change.Applied, "model_routing")
+	}
+	if cur.MockSimulation != next.MockSimulation {
+		llm.SetSimulation(next.MockSimulation.Simulation())
+		change.Applied = append(change.Applied, "mock_simulation")
+	}
+	if !reflect.DeepEqual(cur.Webhooks, next.Webhooks) {
+		webhook.AllowHosts(next.Webhooks.AllowHosts)
+		change.Applied = append(change.Applied, "webhooks")
+	}
+
+	for _, section := range []struct {
+		key       string
+		cur, next interface{}
+	}{
+		{"web", cur.Web, next.Web},
+		{"llm_cache", cur.LLMCache, next.LLMCache},
+		{"llm_http", cur.LLMHTTP, next.LLMHTTP},
+		{"load_balancing", cur.LoadBalancing, next.LoadBalancing},
+		{"queue", cur.Queue, next.Queue},
+		{"leader_election", cur.LeaderElection, next.LeaderElection},
+		{"notifications", cur.Notifications, next.Notifications},
+		{"transport", cur.Transport, next.Transport},
+		{"prompts", cur.Prompts, next.Prompts},
+		{"redaction", cur.Redaction, next.Redaction},
+		{"event_types", cur.EventTypes, next.EventTypes},
+		{"namespaces", cur.Namespaces, next.Namespaces},
+	} {
+		if !reflect.DeepEqual(section.cur, section.next) {
+			change.Restart = append(change.Restart, section.key)
+		}
+	}
+}
+
+// publish sends a config event on the swarm's bus
+func (r *Reloader) publish(eventType types.EventType, message string, data interface{}) {
+	r.swarm.GetEventBus().Publish(types.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		AgentID:   "swarm",
+		Message:   message,
+		Data:      data,
+	})
+}
+
+// sortedIDs returns the keys of agents, sorted
+func sortedIDs(agents map[string]AgentConfig) []string {
+	ids := make([]string, 0, len(agents))
+	for id := range agents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,251 @@
+// Package experiment runs the same tasks against two variants of the swarm,
+// differing in system prompts or in provider and model, and compares their
+// results, latencies, and costs side by side:
+//
+//	exp, err := experiment.Load("terse-research.yaml")
+//	report, err := experiment.Run(ctx, cfg, exp, progress)
+//	report.Write(experiment.ReportPath("terse-research.yaml"))
+//
+// An experiment file is YAML (or JSON, by extension). Tasks use the fields of
+// a batch task file (see pkg/batch); each variant may set a provider, a
+// model, and prompt templates by agent type (see agents.Prompts):
+//
+//	name: terse research
+//	tasks:
+//	  - id: go-generics
+//	    description: Research Go generics adoption
+//	    agent_type: research
+//	variants:
+//	  - name: baseline
+//	  - name: terse
+//	    model: gpt-4o-mini
+//	    prompts:
+//	      research: You are a research specialist. Answer in at most five bullet points.
+package experiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"agent-swarm-go/pkg/agents"
+	"agent-swarm-go/pkg/batch"
+	"agent-swarm-go/pkg/config"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+)
+
+// Experiment is a task set and the two variants to run it against
+type Experiment struct {
+	Name     string        `yaml:"name" json:"name"`
+	Tasks    []batch.Entry `yaml:"tasks" json:"tasks"`
+	Variants []Variant     `yaml:"variants" json:"variants"` // Exactly two
+
+	tasks []types.Task // Tasks, checked and converted by Load
+}
+
+// Variant is one way of running the tasks. Unset fields keep what the swarm
+// config says.
+type Variant struct {
+	Name     string            `yaml:"name" json:"name"`
+	Provider string            `yaml:"provider" json:"provider,omitempty"` // For every LLM agent: "openai", "anthropic", or "mock"
+	Model    string            `yaml:"model" json:"model,omitempty"`       // For every LLM agent
+	Prompts  map[string]string `yaml:"prompts" json:"prompts,omitempty"`   // Agent type -> system prompt template
+}
+
+// Label names the variant in the report, e.g. "terse (gpt-4o-mini)"
+func (v Variant) Label() string {
+	var settings []string
+	for _, s := range []string{v.Provider, v.Model} {
+		if s != "" {
+			settings = append(settings, s)
+		}
+	}
+	if len(v.Prompts) > 0 {
+		names := make([]string, 0, len(v.Prompts))
+		for name := range v.Prompts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		settings = append(settings, "prompts: "+strings.Join(names, ", "))
+	}
+	if len(settings) == 0 {
+		return v.Name
+	}
+	return fmt.Sprintf("%s (%s)", v.Name, strings.Join(settings, "; "))
+}
+
+// Load reads and checks an experiment file. Every problem is reported
+// together.
+func Load(path string) (*Experiment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	exp := &Experiment{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(exp)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(exp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if exp.Name == "" {
+		exp.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if err := exp.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return exp, nil
+}
+
+// validate checks the variants and converts the tasks
+func (e *Experiment) validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(e.Tasks) == 0 {
+		add("tasks: at least one task is required")
+	} else if tasks, err := batch.ToTasks(e.Tasks); err != nil {
+		add("tasks: %v", err)
+	} else {
+		e.tasks = tasks
+	}
+
+	if len(e.Variants) != 2 {
+		add("variants: want exactly two, got %d", len(e.Variants))
+	}
+	seen := map[string]bool{}
+	for i, v := range e.Variants {
+		if v.Name == "" {
+			add("variants[%d].name: required", i)
+		} else if seen[v.Name] {
+			add("variants[%d].name: duplicate name %q", i, v.Name)
+		}
+		seen[v.Name] = true
+		switch v.Provider {
+		case "", "openai", "anthropic", "mock":
+		default:
+			add("variants[%d].provider: unknown provider %q (want openai, anthropic, or mock)", i, v.Provider)
+		}
+
+		names := make([]string, 0, len(v.Prompts))
+		for name := range v.Prompts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := agents.Prompts.Check(name, v.Prompts[name]); err != nil {
+				add("variants[%d].prompts.%s: %v", i, name, err)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid experiment:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// ProgressFunc is called each time a task of a variant finishes
+type ProgressFunc func(variant string, done, total int, o batch.Outcome)
+
+// Run runs the experiment's tasks against each variant in turn, each on a
+// fresh swarm built from cfg, so no agent carries history from one variant
+// into the other. A variant's prompts replace the swarm-wide templates only
+// while it runs, so Run must not share the process with a live swarm. Costs
+// are the LLM usage recorded while the variant ran.
+func Run(ctx context.Context, cfg *config.Config, exp *Experiment, progress ProgressFunc) (*Report, error) {
+	report := &Report{Name: exp.Name, StartTime: time.Now()}
+
+	for _, v := range exp.Variants {
+		var p batch.ProgressFunc
+		if progress != nil {
+			name := v.Name
+			p = func(done, total int, o batch.Outcome) { progress(name, done, total, o) }
+		}
+		result, err := runVariant(ctx, cfg, v, exp.tasks, p)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+		report.Variants = append(report.Variants, *result)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	report.EndTime = time.Now()
+	report.Tasks = compare(exp.tasks, report.Variants)
+	return report, nil
+}
+
+// runVariant runs the tasks on a swarm set up the way v says
+func runVariant(ctx context.Context, cfg *config.Config, v Variant, tasks []types.Task, progress batch.ProgressFunc) (*VariantResult, error) {
+	variantCfg := *cfg
+	variantCfg.Agents = make([]config.AgentConfig, len(cfg.Agents))
+	for i, a := range cfg.Agents {
+		if a.Type != agents.ExternalType {
+			if v.Provider != "" {
+				a.Provider = v.Provider
+			}
+			if v.Model != "" {
+				a.Model = v.Model
+			}
+		}
+		variantCfg.Agents[i] = a
+	}
+
+	for name, text := range v.Prompts {
+		restore, err := agents.Prompts.Swap(name, text, "experiment "+v.Name)
+		if err != nil {
+			return nil, fmt.Errorf("prompts.%s: %w", name, err)
+		}
+		defer restore()
+	}
+
+	s := swarm.NewSwarm(swarm.WithStrategy(variantCfg.Strategy()))
+	swarmAgents, err := variantCfg.NewAgents(s.GetEventBus())
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range swarmAgents {
+		if err := s.AddAgent(a); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Start(ctx); err != nil {
+		return nil, err
+	}
+	defer s.Stop()
+
+	before := llm.TotalUsage()
+	summary := batch.Run(ctx, s, tasks, progress)
+	return &VariantResult{
+		Variant: v,
+		Summary: summary,
+		Usage:   usageSince(before),
+	}, nil
+}
+
+// usageSince returns the LLM usage recorded since the snapshot before
+func usageSince(before []llm.Usage) llm.Usage {
+	return llm.SumUsage(llm.TotalUsage()).Sub(llm.SumUsage(before))
+}
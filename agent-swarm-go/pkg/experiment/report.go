@@ -0,0 +1,112 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/batch"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+)
+
+// Report is the record of an experiment run
+type Report struct {
+	Name      string           `json:"name"`
+	StartTime time.Time        `json:"start_time"`
+	EndTime   time.Time        `json:"end_time"`
+	Variants  []VariantResult  `json:"variants"` // In experiment file order
+	Tasks     []TaskComparison `json:"tasks"`    // In experiment file order
+}
+
+// VariantResult is how one variant did over the whole task set
+type VariantResult struct {
+	Variant Variant        `json:"variant"`
+	Summary *batch.Summary `json:"summary"`
+	Usage   llm.Usage      `json:"usage"` // Tokens and estimated cost; zero in mock mode
+}
+
+// MeanLatency is the average time from dispatch to result over the variant's
+// tasks
+func (r VariantResult) MeanLatency() time.Duration {
+	if len(r.Summary.Outcomes) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, o := range r.Summary.Outcomes {
+		total += o.Duration
+	}
+	return total / time.Duration(len(r.Summary.Outcomes))
+}
+
+// TaskComparison puts the variants' outcomes for one task next to each other
+type TaskComparison struct {
+	TaskID      string          `json:"task_id"`
+	Description string          `json:"description"`
+	Outcomes    []batch.Outcome `json:"outcomes"` // One per variant, in the order of Report.Variants
+}
+
+// compare lines up the variants' outcomes task by task
+func compare(tasks []types.Task, results []VariantResult) []TaskComparison {
+	comparisons := make([]TaskComparison, len(tasks))
+	for i, task := range tasks {
+		comparisons[i] = TaskComparison{TaskID: task.ID, Description: task.Description}
+		for _, r := range results {
+			comparisons[i].Outcomes = append(comparisons[i].Outcomes, r.Summary.Outcomes[i])
+		}
+	}
+	return comparisons
+}
+
+// Markdown renders the report: a table comparing the variants overall, then
+// each task's outputs one variant after the other
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Experiment: %s\n\n", r.Name)
+	fmt.Fprintf(&b, "Run %s, took %v.\n\n", r.StartTime.Format("2006-01-02 15:04:05"), r.EndTime.Sub(r.StartTime).Round(time.Second))
+
+	b.WriteString("| Variant | Succeeded | Failed | Mean latency | Tokens in | Tokens out | Est. cost |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, v := range r.Variants {
+		fmt.Fprintf(&b, "| %s | %d | %d | %v | %d | %d | $%.4f |\n",
+			v.Variant.Label(), v.Summary.Succeeded, v.Summary.Failed, v.MeanLatency().Round(time.Millisecond),
+			v.Usage.InputTokens, v.Usage.OutputTokens, v.Usage.CostUSD)
+	}
+
+	for _, t := range r.Tasks {
+		fmt.Fprintf(&b, "\n## %s: %s\n", t.TaskID, t.Description)
+		for i, o := range t.Outcomes {
+			fmt.Fprintf(&b, "\n### %s — %v\n\n", r.Variants[i].Variant.Name, o.Duration.Round(time.Millisecond))
+			if o.Success {
+				fmt.Fprintf(&b, "%v\n", o.Data)
+			} else {
+				fmt.Fprintf(&b, "❌ Failed: %s\n", o.Error)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Write saves the report as Markdown at path, and as indented JSON next to
+// it with a .json extension
+func (r *Report) Write(path string) error {
+	if err := os.WriteFile(path, []byte(r.Markdown()), 0644); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	jsonPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".json"
+	return os.WriteFile(jsonPath, append(data, '\n'), 0644)
+}
+
+// ReportPath returns where the report of an experiment file goes by default:
+// terse.yaml → terse-report.md (and terse-report.json), next to the file
+func ReportPath(experimentFile string) string {
+	ext := filepath.Ext(experimentFile)
+	return strings.TrimSuffix(experimentFile, ext) + "-report.md"
+}
@@ -0,0 +1,175 @@
+// Package export writes workflow results to files: Markdown, a standalone
+// HTML page, or a plain PDF, so a report can be kept or shared after the
+// console scrolls away.
+//
+//	paths, err := export.Write(result, export.DefaultDir, export.FormatMarkdown, export.FormatHTML)
+//
+// Files are named after the workflow and its start time, e.g.
+// exports/quantum-computing-20240405-101500.md.
+//
+// A Transcript records a whole CLI session instead: its tasks, the agents they
+// went to, every event, and the full results, written as Markdown or JSON.
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"agent-swarm-go/pkg/workflows"
+)
+
+// DefaultDir is where the CLI writes exports
+const DefaultDir = "exports"
+
+// Export formats, also used as file extensions
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+)
+
+// Section is one titled part of an exported result
+type Section struct {
+	Title string
+	Body  string // Markdown, as the agents wrote it
+}
+
+// Sections splits a result into the parts an export shows, in order: the
+// cover page, research, analysis, and report for the research workflow, or
+// each step that produced output (with its critic review or debate
+// transcript, if any) for a definition workflow; then any translations of the final report
+func Sections(wr *workflows.WorkflowResult) []Section {
+	var sections []Section
+	if len(wr.Steps) > 0 {
+		for _, step := range wr.Steps {
+			if out, ok := wr.StepResults[step]; ok {
+				if review, ok := wr.Reviews[step]; ok {
+					out += "\n\n**Review:** " + review.String()
+				}
+				sections = append(sections, Section{Title: stepTitle(step), Body: out})
+			}
+			if debate, ok := wr.Debates[step]; ok {
+				sections = append(sections, Section{Title: stepTitle(step) + " Debate", Body: debate.Markdown()})
+			}
+		}
+	} else {
+		if wr.Documentation != "" {
+			sections = append(sections, Section{Title: "Cover Page", Body: wr.Documentation})
+		}
+		sections = append(sections,
+			Section{Title: "Research Findings", Body: wr.StepResults["research"]},
+			Section{Title: "Analysis Insights", Body: wr.StepResults["analysis"]},
+			Section{Title: "Final Report", Body: wr.FinalReport},
+		)
+	}
+
+	for _, language := range wr.Languages() {
+		sections = append(sections, Section{Title: fmt.Sprintf("Final Report (%s)", language), Body: wr.Translations[language]})
+	}
+	return sections
+}
+
+// stepTitle turns a step name like "market_research" into "Market Research"
+func stepTitle(step string) string {
+	words := strings.FieldsFunc(step, func(r rune) bool { return r == '_' || r == '-' })
+	for i, w := range words {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// title is the heading of an exported document
+func title(wr *workflows.WorkflowResult) string {
+	if wr.Name != "" && wr.Name != wr.Topic {
+		return fmt.Sprintf("%s: %s", wr.Name, wr.Topic)
+	}
+	return wr.Topic
+}
+
+// Markdown renders the result as one Markdown document
+func Markdown(wr *workflows.WorkflowResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title(wr))
+	fmt.Fprintf(&b, "- Workflow ID: %s\n", wr.WorkflowID)
+	fmt.Fprintf(&b, "- Started: %s\n", wr.StartTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- Duration: %v\n", wr.Duration.Round(time.Millisecond))
+
+	for _, s := range Sections(wr) {
+		fmt.Fprintf(&b, "\n---\n\n## %s\n\n%s\n", s.Title, strings.TrimSpace(s.Body))
+	}
+	return b.String()
+}
+
+// Render returns the result in the given format
+func Render(wr *workflows.WorkflowResult, format string) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return []byte(Markdown(wr)), nil
+	case FormatHTML:
+		return []byte(HTML(wr)), nil
+	case FormatPDF:
+		return PDF(wr), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q (want %s, %s, or %s)", format, FormatMarkdown, FormatHTML, FormatPDF)
+	}
+}
+
+// Write saves the result in each format to dir, creating it if needed, and
+// returns the paths written. An unknown format fails before anything is written.
+func Write(wr *workflows.WorkflowResult, dir string, formats ...string) ([]string, error) {
+	for _, format := range formats {
+		switch format {
+		case FormatMarkdown, FormatHTML, FormatPDF:
+		default:
+			return nil, fmt.Errorf("unknown export format %q (want %s, %s, or %s)", format, FormatMarkdown, FormatHTML, FormatPDF)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	base := fmt.Sprintf("%s-%s", slug(title(wr)), wr.StartTime.Format("20060102-150405"))
+	var paths []string
+	for _, format := range formats {
+		data, err := Render(wr, format)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, base+"."+format)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// slug makes a file-name-safe version of s: lowercase letters and digits
+// separated by single dashes
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := strings.TrimSuffix(b.String(), "-")
+	if len(out) > 60 {
+		out = strings.TrimSuffix(out[:60], "-")
+	}
+	if out == "" {
+		out = "workflow"
+	}
+	return out
+}
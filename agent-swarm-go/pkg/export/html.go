@@ -0,0 +1,143 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/workflows"
+)
+
+// htmlStyle keeps exported pages readable without any external assets
+const htmlStyle = `body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif; max-width: 860px; margin: 40px auto; padding: 0 20px; line-height: 1.6; color: #1f2937; }
+h1 { border-bottom: 2px solid #6366f1; padding-bottom: 8px; }
+h2 { margin-top: 40px; color: #4338ca; }
+.meta { color: #6b7280; font-size: 0.9em; }
+pre { background: #f3f4f6; padding: 12px; border-radius: 6px; overflow-x: auto; }
+code { background: #f3f4f6; padding: 1px 4px; border-radius: 4px; }
+pre code { background: none; padding: 0; }
+hr { border: none; border-top: 1px solid #e5e7eb; margin: 32px 0; }`
+
+// HTML renders the result as a standalone HTML page
+func HTML(wr *workflows.WorkflowResult) string {
+	var b strings.Builder
+	t := html.EscapeString(title(wr))
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"UTF-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", t, htmlStyle)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", t)
+	fmt.Fprintf(&b, "<p class=\"meta\">Workflow %s · started %s · took %v</p>\n",
+		html.EscapeString(wr.WorkflowID), wr.StartTime.Format("2006-01-02 15:04:05"), wr.Duration.Round(time.Millisecond))
+
+	for _, s := range Sections(wr) {
+		fmt.Fprintf(&b, "<hr>\n<h2>%s</h2>\n", html.EscapeString(s.Title))
+		b.WriteString(markdownToHTML(s.Body))
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+var (
+	orderedItem = regexp.MustCompile(`^\d+[.)]\s+`)
+	boldText    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	italicText  = regexp.MustCompile(`(^|[^*])\*([^*\s][^*]*?)\*`)
+	inlineCode  = regexp.MustCompile("`([^`]+)`")
+	linkText    = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// markdownToHTML converts the Markdown agents produce (headings, lists, code
+// blocks, emphasis, links, paragraphs) to HTML. Anything else is kept as
+// escaped text.
+func markdownToHTML(md string) string {
+	var b strings.Builder
+	var paragraph []string
+	list := "" // "ul" or "ol" while inside a list
+	inCode := false
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			fmt.Fprintf(&b, "<p>%s</p>\n", strings.Join(paragraph, "<br>\n"))
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			fmt.Fprintf(&b, "</%s>\n", list)
+			list = ""
+		}
+	}
+	openList := func(kind string) {
+		if list != kind {
+			closeList()
+			fmt.Fprintf(&b, "<%s>\n", kind)
+			list = kind
+		}
+	}
+
+	for _, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				b.WriteString("</code></pre>\n")
+			} else {
+				flush()
+				closeList()
+				b.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+			closeList()
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			closeList()
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			if level > 4 {
+				level = 4
+			}
+			// Section titles are h2, so the agents' headings start at h3
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level+2, inline(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))), level+2)
+		case trimmed == "---" || trimmed == "***":
+			flush()
+			closeList()
+			b.WriteString("<hr>\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "• "):
+			flush()
+			openList("ul")
+			_, item, _ := strings.Cut(trimmed, " ")
+			fmt.Fprintf(&b, "<li>%s</li>\n", inline(item))
+		case orderedItem.MatchString(trimmed):
+			flush()
+			openList("ol")
+			fmt.Fprintf(&b, "<li>%s</li>\n", inline(orderedItem.ReplaceAllString(trimmed, "")))
+		default:
+			closeList()
+			paragraph = append(paragraph, inline(trimmed))
+		}
+	}
+	if inCode {
+		b.WriteString("</code></pre>\n")
+	}
+	flush()
+	closeList()
+	return b.String()
+}
+
+// inline escapes a line and applies code, bold, italic, and link markup
+func inline(text string) string {
+	text = html.EscapeString(text)
+	text = inlineCode.ReplaceAllString(text, "<code>$1</code>")
+	text = boldText.ReplaceAllString(text, "<strong>$1</strong>")
+	text = italicText.ReplaceAllString(text, "$1<em>$2</em>")
+	text = linkText.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	return text
+}
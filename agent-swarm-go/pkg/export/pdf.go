@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"agent-swarm-go/pkg/workflows"
+)
+
+// PDF page layout: US Letter in points, 10pt Courier (6pt per character)
+const (
+	pdfPageWidth  = 612
+	pdfPageHeight = 792
+	pdfMargin     = 54
+	pdfFontSize   = 10
+	pdfLeading    = 13
+	pdfLineChars  = (pdfPageWidth - 2*pdfMargin) / 6
+	pdfPageLines  = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// pdfLine is one line of PDF text; bold lines are headings
+type pdfLine struct {
+	text string
+	bold bool
+}
+
+// pdfReplacements maps common typography outside Latin-1 to the nearest
+// character the built-in PDF fonts have
+var pdfReplacements = strings.NewReplacer(
+	"‘", "'", "’", "'", "“", `"`, "”", `"`,
+	"–", "-", "—", "--", "•", "*", "…", "...", "→", "->",
+)
+
+// PDF renders the result as a plain-text PDF: the Markdown export laid out in
+// a monospaced font, with headings in bold. It needs no external tools, so
+// characters outside Latin-1 (such as emoji) are dropped.
+func PDF(wr *workflows.WorkflowResult) []byte {
+	var lines []pdfLine
+	for _, raw := range strings.Split(Markdown(wr), "\n") {
+		raw = strings.TrimRight(raw, " \t\r")
+		bold := strings.HasPrefix(raw, "#")
+		if bold {
+			raw = strings.TrimSpace(strings.TrimLeft(raw, "#"))
+		}
+		for _, wrapped := range wrapLine(pdfText(raw), pdfLineChars) {
+			lines = append(lines, pdfLine{text: wrapped, bold: bold})
+		}
+	}
+
+	var pages [][]pdfLine
+	for len(lines) > pdfPageLines {
+		pages = append(pages, lines[:pdfPageLines])
+		lines = lines[pdfPageLines:]
+	}
+	pages = append(pages, lines)
+	return buildPDF(pages)
+}
+
+// pdfText converts text to Latin-1 bytes, replacing or dropping the rest
+func pdfText(s string) string {
+	s = pdfReplacements.Replace(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b.WriteString("    ")
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// wrapLine splits a line into pieces of at most width bytes, at spaces where
+// possible, keeping list indentation on continuation lines
+func wrapLine(line string, width int) []string {
+	if len(line) <= width {
+		return []string{line}
+	}
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if strings.HasPrefix(line[indent:], "- ") || strings.HasPrefix(line[indent:], "* ") {
+		indent += 2
+	}
+	if indent > width/2 {
+		indent = 0
+	}
+
+	var out []string
+	for len(line) > width {
+		cut := strings.LastIndexByte(line[:width+1], ' ')
+		if cut <= indent {
+			cut = width
+		}
+		out = append(out, strings.TrimRight(line[:cut], " "))
+		line = strings.Repeat(" ", indent) + strings.TrimLeft(line[cut:], " ")
+	}
+	return append(out, line)
+}
+
+// pdfEscape escapes a string for a PDF literal
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
+
+// buildPDF writes a minimal PDF 1.4 file with one content stream per page,
+// using the standard Courier and Courier-Bold fonts
+func buildPDF(pages [][]pdfLine) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4: catalog, page tree, fonts; then a page and its content
+	// stream for every page
+	const firstPage = 5
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT\n%d TL\n%d %d Td\n", pdfLeading, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		font := ""
+		for _, line := range page {
+			want := "/F1"
+			if line.bold {
+				want = "/F2"
+			}
+			if want != font {
+				fmt.Fprintf(&content, "%s %d Tf\n", want, pdfFontSize)
+				font = want
+			}
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line.text))
+		}
+		content.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
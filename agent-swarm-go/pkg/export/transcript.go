@@ -0,0 +1,260 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/redact"
+	"agent-swarm-go/pkg/types"
+)
+
+// FormatJSON is the other format a transcript can be written in
+const FormatJSON = "json"
+
+// maxTranscriptEvents caps the events a transcript keeps, so a long session
+// with stress tests doesn't grow without bound
+const maxTranscriptEvents = 20000
+
+// Transcript is everything that happened in a session: the tasks submitted,
+// the agents they went to, the events published, and the full results. Feed
+// it with Swarm.ObserveSubmissions(t.Task) and EventBus.Observe(t.Event).
+type Transcript struct {
+	RunID   string
+	Started time.Time
+
+	mu            sync.Mutex
+	tasks         []*TranscriptTask
+	byID          map[string]*TranscriptTask // Latest task with each ID
+	events        []types.Event
+	droppedEvents int
+}
+
+// TranscriptTask is one submitted task and what became of it
+type TranscriptTask struct {
+	ID           string    `json:"id"`
+	Description  string    `json:"description"`
+	AgentType    string    `json:"agent_type,omitempty"` // Requested specialty
+	Lane         string    `json:"lane"`
+	Dependencies []string  `json:"dependencies,omitempty"`
+	RunID        string    `json:"run_id,omitempty"`
+	Submitted    time.Time `json:"submitted"`
+	AgentID      string    `json:"agent_id,omitempty"` // Agent that received it; the last one if it was redelivered
+
+	// What became of it; Status is "unfinished" until it completes or fails
+	Status   string                `json:"status"`
+	Output   interface{}           `json:"output,omitempty"`
+	Error    string                `json:"error,omitempty"`
+	Failure  string                `json:"failure,omitempty"` // Failure class, e.g. "limit_exceeded"
+	Metadata *types.ResultMetadata `json:"metadata,omitempty"`
+}
+
+// transcriptFile is the JSON form of a transcript
+type transcriptFile struct {
+	RunID         string            `json:"run_id"`
+	Started       time.Time         `json:"started"`
+	Exported      time.Time         `json:"exported"`
+	Tasks         []*TranscriptTask `json:"tasks"`
+	Events        []types.Event     `json:"events"`
+	DroppedEvents int               `json:"dropped_events,omitempty"` // Left out past the cap
+}
+
+// NewTranscript starts an empty transcript for the session with runID
+func NewTranscript(runID string) *Transcript {
+	return &Transcript{
+		RunID:   runID,
+		Started: time.Now(),
+		byID:    make(map[string]*TranscriptTask),
+	}
+}
+
+// Task notes a task accepted by the swarm
+func (t *Transcript) Task(task types.Task, priority types.MessagePriority) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := &TranscriptTask{
+		ID:           task.ID,
+		Description:  task.Description,
+		AgentType:    task.AgentType(),
+		Lane:         priority.String(),
+		Dependencies: task.Dependencies,
+		RunID:        task.RunID,
+		Submitted:    time.Now(),
+		Status:       "unfinished",
+	}
+	t.tasks = append(t.tasks, entry)
+	t.byID[task.ID] = entry
+}
+
+// Event notes an event, and the assignment or result it reports
+func (t *Transcript) Event(event types.Event) {
+	if event.Type == types.EventTaskOutput {
+		return // The task's result has all of its streamed output
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.events) < maxTranscriptEvents {
+		t.events = append(t.events, event)
+	} else {
+		t.droppedEvents++
+	}
+
+	task, ok := t.byID[event.TaskID]
+	if !ok {
+		return
+	}
+	switch event.Type {
+	case types.EventTaskReceived:
+		task.AgentID = event.AgentID
+	case types.EventTaskCompleted, types.EventTaskFailed, types.EventTaskDeadLettered:
+		task.Status, task.Output, task.Error, task.Failure, task.Metadata = "completed", event.Message, "", "", nil
+		if event.Type != types.EventTaskCompleted {
+			task.Status = "failed"
+		}
+		if result, ok := event.Data.(types.Result); ok {
+			if result.Data != nil {
+				task.Output = result.Data
+			}
+			if !result.Success {
+				task.Status = "failed"
+			}
+			if result.Error != nil {
+				task.Error = result.Error.Error()
+			}
+			task.Failure = string(result.Failure)
+			metadata := result.Metadata
+			task.Metadata = &metadata
+		}
+	}
+}
+
+// snapshot copies the transcript for rendering
+func (t *Transcript) snapshot() transcriptFile {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	file := transcriptFile{
+		RunID:         t.RunID,
+		Started:       t.Started,
+		Exported:      time.Now(),
+		Tasks:         make([]*TranscriptTask, len(t.tasks)),
+		Events:        append([]types.Event(nil), t.events...),
+		DroppedEvents: t.droppedEvents,
+	}
+	for i, task := range t.tasks {
+		copied := *task
+		file.Tasks[i] = &copied
+	}
+	return file
+}
+
+// Len returns how many tasks and events the transcript holds
+func (t *Transcript) Len() (tasks, events int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.tasks), len(t.events) + t.droppedEvents
+}
+
+// JSON renders the transcript as an indented JSON document
+func (t *Transcript) JSON() ([]byte, error) {
+	return json.MarshalIndent(t.snapshot(), "", "  ")
+}
+
+// Markdown renders the transcript as one Markdown document: each task with
+// its agent and full result, then the event log
+func (t *Transcript) Markdown() string {
+	file := t.snapshot()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Session %s\n\n", file.RunID)
+	fmt.Fprintf(&b, "- Started: %s\n", file.Started.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- Exported: %s\n", file.Exported.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- Tasks: %d\n", len(file.Tasks))
+	fmt.Fprintf(&b, "- Events: %d\n", len(file.Events)+file.DroppedEvents)
+
+	b.WriteString("\n---\n\n## Tasks\n")
+	if len(file.Tasks) == 0 {
+		b.WriteString("\nNo tasks were submitted.\n")
+	}
+	for _, task := range file.Tasks {
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n\n", task.ID, strings.TrimSpace(task.Description))
+		fmt.Fprintf(&b, "- Submitted: %s (%s lane)\n", task.Submitted.Format("15:04:05"), task.Lane)
+		if task.AgentType != "" {
+			fmt.Fprintf(&b, "- Requested specialty: %s\n", task.AgentType)
+		}
+		if len(task.Dependencies) > 0 {
+			fmt.Fprintf(&b, "- Depends on: %s\n", strings.Join(task.Dependencies, ", "))
+		}
+		if task.AgentID != "" {
+			fmt.Fprintf(&b, "- Agent: %s\n", task.AgentID)
+		}
+		fmt.Fprintf(&b, "- Status: %s\n", task.Status)
+		if task.Metadata != nil {
+			if d := task.Metadata.Duration().Round(time.Millisecond); d > 0 {
+				fmt.Fprintf(&b, "- Duration: %v\n", d)
+			}
+			if task.Metadata.Model != "" {
+				fmt.Fprintf(&b, "- Model: %s\n", task.Metadata.Model)
+			}
+		}
+		if task.Error != "" {
+			fmt.Fprintf(&b, "- Error: %s\n", task.Error)
+		}
+		if task.Output != nil {
+			fmt.Fprintf(&b, "\n#### Result\n\n%s\n", strings.TrimSpace(fmt.Sprint(task.Output)))
+		}
+	}
+
+	b.WriteString("\n---\n\n## Events\n\n")
+	for _, event := range file.Events {
+		fmt.Fprintf(&b, "- %s `%s`", event.Timestamp.Format("15:04:05.000"), event.Type)
+		if event.AgentID != "" {
+			fmt.Fprintf(&b, " %s", event.AgentID)
+		}
+		if event.TaskID != "" {
+			fmt.Fprintf(&b, " [%s]", event.TaskID)
+		}
+		if msg := strings.Join(strings.Fields(event.Message), " "); msg != "" {
+			fmt.Fprintf(&b, ": %s", msg)
+		}
+		b.WriteString("\n")
+	}
+	if file.DroppedEvents > 0 {
+		fmt.Fprintf(&b, "- … %d later events left out\n", file.DroppedEvents)
+	}
+	return b.String()
+}
+
+// Write saves the transcript to dir as Markdown (FormatMarkdown) or JSON
+// (FormatJSON), with secrets scrubbed (see pkg/redact), creating dir if
+// needed, and returns the path written
+func (t *Transcript) Write(dir, format string) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case FormatMarkdown:
+		data = []byte(t.Markdown())
+	case FormatJSON:
+		data, err = t.JSON()
+	default:
+		return "", fmt.Errorf("unknown transcript format %q (want %s or %s)", format, FormatMarkdown, FormatJSON)
+	}
+	if err != nil {
+		return "", err
+	}
+	data = []byte(redact.String(string(data)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("session-%s.%s", slug(t.RunID), format))
+	return path, os.WriteFile(path, data, 0644)
+}
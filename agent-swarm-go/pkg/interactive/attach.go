@@ -0,0 +1,96 @@
+package interactive
+
+import (
+	"fmt"
+	"strconv"
+
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+)
+
+// stepIcons marks each step status in the attached view
+var stepIcons = map[string]string{
+	swarm.StepPending:   "○",
+	swarm.TaskRunning:   "▶",
+	swarm.TaskCompleted: "✓",
+	swarm.TaskFailed:    "✗",
+}
+
+// attachWorkflow lists the workflows in progress, wherever they were started,
+// and follows one: its steps so far, then live progress until it finishes
+func (s *Session) attachWorkflow() {
+	s.cli.PrintSection("Attach to Workflow")
+
+	runs := s.swarm.ActiveWorkflows()
+	if len(runs) == 0 {
+		s.cli.PrintInfo("No workflows are running. Ones started from the dashboard, the API, or a schedule show up here.")
+		return
+	}
+	for i, run := range runs {
+		fmt.Printf("  %d. %s (%s) - %d/%d steps done, started %s\n",
+			i+1, run.Name, run.ID, run.Done(), len(run.Steps), run.Started.Format("15:04:05"))
+	}
+
+	num, err := strconv.Atoi(s.cli.GetInputWithDefault("\nWorkflow number", "1"))
+	if err != nil || num < 1 || num > len(runs) {
+		s.cli.PrintError("Invalid workflow number")
+		return
+	}
+
+	// Subscribed before the steps are read, so nothing falls in between
+	events := s.swarm.GetEventBus().SubscribeWithBuffer(1000)
+	defer s.swarm.GetEventBus().Unsubscribe(events)
+
+	run, ok := s.swarm.ActiveWorkflow(runs[num-1].ID)
+	if !ok {
+		s.cli.PrintInfo("That workflow has already finished; option 10 lists archived runs")
+		return
+	}
+	fmt.Printf("\n🧩 %s\n", run.Name)
+	for _, step := range run.Steps {
+		fmt.Printf("   %s %s [%s]\n", stepIcons[step.Status], step.Name, step.Status)
+	}
+	fmt.Println("\nFollowing live progress until the workflow finishes...")
+
+	done := run.Done()
+	for event := range events {
+		if event.TaskID == run.ID {
+			switch event.Type {
+			case types.EventWorkflowDone:
+				s.cli.PrintSuccess(fmt.Sprintf("Workflow completed: %d/%d steps done", done, len(run.Steps)))
+				s.cli.PrintInfo("Option 10 reopens its report")
+				return
+			case types.EventWorkflowFailed:
+				s.cli.PrintError(event.Message)
+				return
+			case types.EventBudgetExceeded:
+				fmt.Printf("   💸 %s\n", event.Message)
+			}
+			continue
+		}
+		if req, ok := event.Data.(types.ApprovalRequest); ok && req.WorkflowID == run.ID {
+			fmt.Printf("   ⏸  Step %s is waiting for approval on the dashboard\n", req.Step)
+			continue
+		}
+
+		step, ok := run.StepOf(event.TaskID)
+		if !ok {
+			continue
+		}
+		switch event.Type {
+		case types.EventTaskStarted:
+			fmt.Printf("   ▶ %s started on %s\n", step.Name, event.AgentID)
+		case types.EventTaskProgress:
+			if progress, ok := event.Data.(types.TaskProgress); ok {
+				fmt.Printf("   ⏳ %s: %s (%d%%)\n", step.Name, progress.Phase, progress.Percent)
+			}
+		case types.EventTaskCompleted, types.EventTaskFailed, types.EventTaskDeadLettered:
+			done++
+			icon, status := stepIcons[swarm.TaskCompleted], swarm.TaskCompleted
+			if r, ok := event.Data.(types.Result); event.Type != types.EventTaskCompleted || (ok && !r.Success) {
+				icon, status = stepIcons[swarm.TaskFailed], swarm.TaskFailed
+			}
+			fmt.Printf("   %s %s %s (%d/%d)\n", icon, step.Name, status, done, len(run.Steps))
+		}
+	}
+}
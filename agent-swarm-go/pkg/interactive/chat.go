@@ -0,0 +1,136 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// chatReplyTimeout is how long the chat waits for an agent to answer
+const chatReplyTimeout = 3 * time.Minute
+
+// chatHistoryTurns caps how many earlier exchanges are sent with each message
+const chatHistoryTurns = 10
+
+// chatTurn is one user message and the agent's reply
+type chatTurn struct {
+	User  string
+	Agent string
+}
+
+// chat opens a conversation with a single agent. Every message is sent to that
+// agent as a task carrying the conversation so far, so follow-up questions can
+// build on earlier answers.
+func (s *Session) chat() {
+	s.cli.PrintSection("Chat with an Agent")
+
+	agents := s.swarm.ListAgents()
+	if len(agents) == 0 {
+		s.cli.PrintError("No agents available")
+		return
+	}
+	sort.Strings(agents)
+
+	fmt.Println("Available agents:")
+	for i, agentID := range agents {
+		fmt.Printf("  %d. %s\n", i+1, agentID)
+	}
+	choice := s.cli.GetInputWithDefault("\nAgent number or ID", "1")
+	agentID := choice
+	if num, err := strconv.Atoi(choice); err == nil && num > 0 && num <= len(agents) {
+		agentID = agents[num-1]
+	}
+	agent, err := s.swarm.GetAgent(agentID)
+	if err != nil {
+		s.cli.PrintError(err.Error())
+		return
+	}
+	payload := map[string]interface{}{"chat": true, "agent_type": agent.GetSpecialty()}
+
+	s.cli.PrintInfo(fmt.Sprintf("Chatting with %s. Commands: /clear forgets the conversation, /exit returns to the menu", agentID))
+
+	var history []chatTurn
+
+	for n := 1; ; {
+		message := s.cli.GetInput("\nyou")
+		switch message {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return
+		case "/clear":
+			history = nil
+			s.cli.PrintInfo("Conversation cleared")
+			continue
+		}
+
+		task := types.Task{
+			ID:          fmt.Sprintf("chat-%s-%d-%d", agentID, time.Now().Unix(), n),
+			Description: message,
+			Priority:    1,
+			Payload:     payload,
+			RunID:       s.runID,
+		}
+		n++
+		if len(history) > 0 {
+			task.Context = map[string]interface{}{"conversation": formatConversation(history, agentID)}
+		}
+
+		if err := s.swarm.AssignTask(agentID, task); err != nil {
+			s.cli.PrintError(fmt.Sprintf("Could not send message: %v", err))
+			continue
+		}
+
+		fmt.Printf("%s is thinking...\n", agentID)
+		reply, ok := s.waitForReply(task.ID, chatReplyTimeout)
+		if !ok {
+			s.cli.PrintError(reply)
+			continue
+		}
+		fmt.Printf("\n%s:\n%s\n", agentID, reply)
+
+		history = append(history, chatTurn{User: message, Agent: reply})
+		if len(history) > chatHistoryTurns {
+			history = history[len(history)-chatHistoryTurns:]
+		}
+	}
+}
+
+// formatConversation renders earlier exchanges as a transcript for the agent
+func formatConversation(history []chatTurn, agentID string) string {
+	var b strings.Builder
+	for _, turn := range history {
+		fmt.Fprintf(&b, "User: %s\n%s: %s\n\n", turn.User, agentID, turn.Agent)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// waitForReply waits for a chat task to finish and returns the agent's answer,
+// or the reason there is none and false
+func (s *Session) waitForReply(taskID string, timeout time.Duration) (string, bool) {
+	progress := s.swarm.GetEventBus().Subscribe()
+	defer s.swarm.GetEventBus().Unsubscribe(progress)
+	go func() {
+		for event := range progress {
+			if event.TaskID != taskID || event.Type != types.EventTaskProgress {
+				continue
+			}
+			if p, ok := event.Data.(types.TaskProgress); ok {
+				fmt.Printf("   ⏳ %s (%d%%)\n", p.Phase, p.Percent)
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	result, err := s.swarm.AwaitResult(ctx, taskID)
+	if err != nil {
+		return fmt.Sprintf("No reply within %v", timeout), false
+	}
+	return fmt.Sprint(result.Data), result.Success
+}
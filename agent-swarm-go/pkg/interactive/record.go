@@ -0,0 +1,126 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/scenarios"
+)
+
+// replayWait is how long a replay waits for its tasks' results
+const replayWait = 5 * time.Minute
+
+// toggleRecording starts capturing every task submitted to the swarm into a
+// scenario, or stops and saves the one being recorded
+func (s *Session) toggleRecording() {
+	s.cli.PrintSection("Record Session")
+
+	if s.recorder != nil {
+		s.saveRecording()
+		return
+	}
+
+	name := s.cli.GetInputWithDefault("Scenario name", "session-"+time.Now().Format("20060102-150405"))
+	if name == "" || filepath.Base(name) != name || strings.HasPrefix(name, ".") {
+		s.cli.PrintError("Use a plain file name, without directories")
+		return
+	}
+
+	s.recording = name
+	s.recorder = scenarios.NewRecorder()
+	s.stopRecording = s.swarm.ObserveSubmissions(s.recorder.Record)
+	s.cli.PrintSuccess(fmt.Sprintf("Recording to %s", s.recordingPath()))
+	s.cli.PrintInfo("Every task submitted from now on is captured, including the dashboard's. Choose 14 again to stop and save.")
+}
+
+// saveRecording stops recording and saves what was captured
+func (s *Session) saveRecording() {
+	s.stopRecording()
+	recorder, path := s.recorder, s.recordingPath()
+	s.recorder, s.stopRecording = nil, nil
+
+	if recorder.Len() == 0 {
+		s.cli.PrintInfo("No tasks were submitted while recording; nothing saved")
+		return
+	}
+	scenario := recorder.Scenario(s.recording, fmt.Sprintf("Recorded in session %s on %s", s.runID, time.Now().Format("2006-01-02 15:04")))
+	if err := scenarios.SaveScenario(path, scenario); err != nil {
+		s.cli.PrintError(fmt.Sprintf("Saving the recording failed: %v", err))
+		return
+	}
+	s.cli.PrintSuccess(fmt.Sprintf("Saved %d task(s) to %s; replay it with option 15", recorder.Len(), path))
+}
+
+// recordingPath is where the scenario being recorded is saved
+func (s *Session) recordingPath() string {
+	return filepath.Join(scenarios.DefaultDir, s.recording+".json")
+}
+
+// replayScenario submits the tasks of a recorded scenario again and reports
+// how many succeeded
+func (s *Session) replayScenario() {
+	s.cli.PrintSection("Replay Scenario")
+
+	paths, _ := filepath.Glob(filepath.Join(scenarios.DefaultDir, "*.json"))
+	if len(paths) > 0 {
+		fmt.Printf("Scenarios in ./%s:\n", scenarios.DefaultDir)
+		for i, path := range paths {
+			fmt.Printf("  %d. %s\n", i+1, filepath.Base(path))
+		}
+	}
+
+	choice := s.cli.GetInput("\nEnter scenario number or file path")
+	path := choice
+	if num, err := strconv.Atoi(choice); err == nil && num > 0 && num <= len(paths) {
+		path = paths[num-1]
+	} else if choice == "" {
+		s.cli.PrintError("No scenario selected")
+		return
+	}
+
+	scenario, err := scenarios.LoadScenario(path)
+	if err != nil {
+		s.cli.PrintError(err.Error())
+		return
+	}
+	// Fresh task IDs, so results of an earlier replay aren't counted again
+	scenario = scenario.Replay(fmt.Sprintf("-replay-%d", time.Now().Unix()))
+
+	if err := scenarios.ExecuteScenario(s.swarm, scenario, func(current, total int) {
+		s.cli.PrintProgress(current, total, "Distributing")
+	}); err != nil {
+		s.cli.PrintError(fmt.Sprintf("Replay failed: %v", err))
+		return
+	}
+
+	s.cli.PrintInfo(fmt.Sprintf("Waiting up to %v for results...", replayWait))
+	ctx, cancel := context.WithTimeout(context.Background(), replayWait)
+	defer cancel()
+
+	succeeded, failed := 0, 0
+	for _, task := range scenario.Tasks {
+		result, err := s.swarm.AwaitResult(ctx, task.ID)
+		switch {
+		case err != nil:
+		case result.Success:
+			succeeded++
+		default:
+			failed++
+			s.cli.PrintError(fmt.Sprintf("%s failed: %v", task.ID, result.Data))
+		}
+	}
+
+	total := len(scenario.Tasks)
+	if pending := total - succeeded - failed; pending > 0 {
+		s.cli.PrintInfo(fmt.Sprintf("%d task(s) still running after %v", pending, replayWait))
+	}
+	if succeeded == total {
+		s.cli.PrintSuccess(fmt.Sprintf("Replay complete: all %d task(s) succeeded", total))
+	} else {
+		s.cli.PrintInfo(fmt.Sprintf("Replay complete: %d of %d task(s) succeeded", succeeded, total))
+	}
+}
@@ -0,0 +1,202 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores API responses by request, so a repeated request (the same
+// provider, model, and messages) is answered without another API call.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the response cached under key, if any
+	Get(key string) (string, bool)
+
+	// Put caches response under key
+	Put(key, response string) error
+}
+
+// CacheKey identifies a request: a SHA-256 hash of the provider, model,
+// generation parameters, and every message, system prompt included
+func CacheKey(provider, model string, params GenerationParams, messages []Message) string {
+	// Unset parameters leave the hash as it was before they existed, so
+	// existing disk caches stay valid
+	var p *GenerationParams
+	if !params.IsZero() {
+		p = &params
+	}
+
+	h := sha256.New()
+	// Encoding can't fail for strings; it keeps field boundaries unambiguous
+	json.NewEncoder(h).Encode(struct {
+		Provider string            `json:"provider"`
+		Model    string            `json:"model"`
+		Params   *GenerationParams `json:"params,omitempty"`
+		Messages []Message         `json:"messages"`
+	}{provider, model, p, messages})
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseCache is the cache every client in the process consults
+var responseCache struct {
+	mu     sync.RWMutex
+	cache  Cache
+	hits   int
+	misses int
+}
+
+// SetCache makes every client answer repeated requests from cache (nil turns
+// caching off). Mock-mode calls are never cached.
+func SetCache(cache Cache) {
+	responseCache.mu.Lock()
+	defer responseCache.mu.Unlock()
+	responseCache.cache = cache
+}
+
+// CacheStats returns how many requests were answered from the cache, and how
+// many had to call the API, since the process started
+func CacheStats() (hits, misses int) {
+	responseCache.mu.RLock()
+	defer responseCache.mu.RUnlock()
+	return responseCache.hits, responseCache.misses
+}
+
+// cachedResponse looks key up in the cache, if one is set
+func cachedResponse(key string) (string, bool) {
+	responseCache.mu.Lock()
+	defer responseCache.mu.Unlock()
+	if responseCache.cache == nil {
+		return "", false
+	}
+	response, ok := responseCache.cache.Get(key)
+	if ok {
+		responseCache.hits++
+	} else {
+		responseCache.misses++
+	}
+	return response, ok
+}
+
+// cacheResponse stores a response, if a cache is set
+func cacheResponse(key, response string) error {
+	responseCache.mu.RLock()
+	cache := responseCache.cache
+	responseCache.mu.RUnlock()
+	if cache == nil {
+		return nil
+	}
+	return cache.Put(key, response)
+}
+
+// MemoryCache keeps responses in memory, forgetting the oldest once it holds
+// its maximum
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	responses  map[string]string
+	order      []string // Keys, oldest first
+}
+
+// NewMemoryCache creates a cache of up to maxEntries responses (0 means
+// unlimited)
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{maxEntries: maxEntries, responses: make(map[string]string)}
+}
+
+// Get returns the response cached under key, if any
+func (c *MemoryCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response, ok := c.responses[key]
+	return response, ok
+}
+
+// Put caches response under key
+func (c *MemoryCache) Put(key, response string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.responses[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.responses[key] = response
+	for c.maxEntries > 0 && len(c.order) > c.maxEntries {
+		delete(c.responses, c.order[0])
+		c.order = c.order[1:]
+	}
+	return nil
+}
+
+// DiskCache keeps responses as JSON files in a directory, so they survive
+// restarts and can be shared by test runs
+type DiskCache struct {
+	dir string
+}
+
+// diskEntry is the file a DiskCache writes for one response
+type diskEntry struct {
+	Response string    `json:"response"`
+	Created  time.Time `json:"created"`
+}
+
+// NewDiskCache creates a cache in dir, creating the directory if needed
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// path returns the file for key, spread over subdirectories by its first two
+// characters so no directory grows too large
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the response cached under key, if any. An unreadable file
+// counts as a miss.
+func (c *DiskCache) Get(key string) (string, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	return entry.Response, true
+}
+
+// Put caches response under key. The file is written under a temporary name
+// and renamed, so a concurrent Get never reads half of it.
+func (c *DiskCache) Put(key, response string) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(diskEntry{Response: response, Created: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(tmp.Name()))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Corrupted is the character a corrupted response is filled with (see
+// Faults), so output checks can tell it apart from a real answer
+const Corrupted = '\uFFFD'
+
+// Faults are failures injected into LLM responses, for chaos testing. Rates
+// are shares of calls between 0 and 1. Responses answered from the cache or
+// from fixtures are left alone.
+type Faults struct {
+	DelayRate   float64       // Share of responses held back before returning
+	MaxDelay    time.Duration // A held-back response waits a random time up to this
+	CorruptRate float64       // Share of responses garbled: cut short and padded with Corrupted
+}
+
+// FaultCounts is how many faults have been injected since SetFaults
+type FaultCounts struct {
+	Delayed   int
+	Corrupted int
+}
+
+// injectedFaults are the faults every client in the process injects
+var injectedFaults struct {
+	mu     sync.Mutex
+	faults Faults
+	counts FaultCounts
+	rand   *rand.Rand
+}
+
+// SetFaults makes every client inject faults into its responses, until
+// SetFaults(Faults{}) turns them off. It resets the counts.
+func SetFaults(faults Faults) {
+	injectedFaults.mu.Lock()
+	defer injectedFaults.mu.Unlock()
+	injectedFaults.faults = faults
+	injectedFaults.counts = FaultCounts{}
+	if injectedFaults.rand == nil {
+		injectedFaults.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
+// InjectedFaults returns how many faults have been injected since SetFaults
+func InjectedFaults() FaultCounts {
+	injectedFaults.mu.Lock()
+	defer injectedFaults.mu.Unlock()
+	return injectedFaults.counts
+}
+
+// injectFaults returns response as the faults set with SetFaults have it:
+// late, garbled, or as it was. A delay is cut short when ctx is done.
+func injectFaults(ctx context.Context, response string) (string, error) {
+	injectedFaults.mu.Lock()
+	faults := injectedFaults.faults
+	var delay time.Duration
+	corrupt := false
+	if faults.DelayRate > 0 && faults.MaxDelay > 0 && injectedFaults.rand.Float64() < faults.DelayRate {
+		delay = time.Duration(injectedFaults.rand.Int63n(int64(faults.MaxDelay))) + 1
+		injectedFaults.counts.Delayed++
+	}
+	if faults.CorruptRate > 0 && injectedFaults.rand.Float64() < faults.CorruptRate {
+		corrupt = true
+		injectedFaults.counts.Corrupted++
+	}
+	injectedFaults.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return "", fmt.Errorf("delayed response: %w", ctx.Err())
+		}
+	}
+	if corrupt {
+		runes := []rune(response)
+		response = string(runes[:len(runes)/2]) + strings.Repeat(string(Corrupted), 16)
+	}
+	return response, nil
+}
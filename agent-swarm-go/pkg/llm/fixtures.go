@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FixtureMode selects whether clients record API responses to fixture files
+// or replay them instead of calling the API (see SetFixtures)
+type FixtureMode string
+
+const (
+	// FixturesOff calls the API (or the mock) as usual
+	FixturesOff FixtureMode = ""
+
+	// FixturesRecord calls the API and saves each response as a fixture
+	FixturesRecord FixtureMode = "record"
+
+	// FixturesReplay answers every request from its fixture, without an API
+	// key or network; a request with no fixture fails
+	FixturesReplay FixtureMode = "replay"
+)
+
+// DefaultFixtureDir is where fixtures are kept when LLM_FIXTURES_DIR is unset
+const DefaultFixtureDir = "testdata/llm-fixtures"
+
+// Fixture is one recorded request and its response, stored as
+// <fixture dir>/<key>.json. Provider and model are kept for reading; replay
+// matches on the messages alone, so fixtures recorded with one provider
+// replay under another, or with no API key at all.
+type Fixture struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Response string    `json:"response"`
+}
+
+// fixtures is the fixture setting every client in the process follows
+var fixtures struct {
+	mu   sync.RWMutex
+	mode FixtureMode
+	dir  string
+}
+
+// SetFixtures makes every client record responses to, or replay them from,
+// the fixture files in dir. Recording creates dir; replaying needs it.
+func SetFixtures(mode FixtureMode, dir string) error {
+	switch mode {
+	case FixturesOff:
+	case FixturesRecord:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	case FixturesReplay:
+		if info, err := os.Stat(dir); err != nil {
+			return err
+		} else if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+	default:
+		return fmt.Errorf("unknown fixture mode %q (want record or replay)", mode)
+	}
+
+	fixtures.mu.Lock()
+	defer fixtures.mu.Unlock()
+	fixtures.mode, fixtures.dir = mode, dir
+	return nil
+}
+
+// SetupFixtures applies LLM_FIXTURES (record or replay) and LLM_FIXTURES_DIR
+// (default DefaultFixtureDir) from the environment, and returns the mode
+func SetupFixtures() (FixtureMode, error) {
+	mode := FixtureMode(strings.ToLower(strings.TrimSpace(os.Getenv("LLM_FIXTURES"))))
+	dir := os.Getenv("LLM_FIXTURES_DIR")
+	if dir == "" {
+		dir = DefaultFixtureDir
+	}
+	if err := SetFixtures(mode, dir); err != nil {
+		return mode, fmt.Errorf("LLM_FIXTURES=%s: %w", mode, err)
+	}
+	return mode, nil
+}
+
+// fixtureSetting returns the current mode and directory
+func fixtureSetting() (FixtureMode, string) {
+	fixtures.mu.RLock()
+	defer fixtures.mu.RUnlock()
+	return fixtures.mode, fixtures.dir
+}
+
+// fixturePath returns the file holding the fixture for messages
+func fixturePath(dir string, messages []Message) string {
+	return filepath.Join(dir, CacheKey("", "", GenerationParams{}, messages)+".json")
+}
+
+// replayFixture returns the recorded response to messages
+func replayFixture(dir string, messages []Message) (string, error) {
+	path := fixturePath(dir, messages)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no LLM fixture for this request (%s); record one with LLM_FIXTURES=record", path)
+	} else if err != nil {
+		return "", err
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return f.Response, nil
+}
+
+// recordFixture saves a response as the fixture for messages, replacing any
+// earlier one
+func recordFixture(dir string, f Fixture) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fixturePath(dir, f.Messages), append(data, '\n'), 0o644)
+}
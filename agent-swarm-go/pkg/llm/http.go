@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// HTTPSettings configures the HTTP client requests to a provider go through.
+// Clients are shared by every agent, so connections to the API are kept alive
+// and reused rather than opened for each request.
+type HTTPSettings struct {
+	Timeout             time.Duration // Per attempt, reading the whole response included
+	MaxIdleConnsPerHost int           // Keep-alive connections kept open to the API
+	Proxy               string        // Proxy URL; empty uses HTTPS_PROXY/HTTP_PROXY from the environment
+}
+
+// DefaultHTTPSettings allow two minutes for a response, which covers long
+// completions, and keep up to ten idle connections
+var DefaultHTTPSettings = HTTPSettings{
+	Timeout:             2 * time.Minute,
+	MaxIdleConnsPerHost: 10,
+}
+
+// httpClients holds the client for each provider with its own settings; ""
+// is the one every other provider uses
+var httpClients = struct {
+	mu      sync.RWMutex
+	clients map[string]*http.Client
+}{clients: map[string]*http.Client{"": newHTTPClient(DefaultHTTPSettings, nil)}}
+
+// SetHTTPSettings changes the HTTP client for provider ("openai" or
+// "anthropic"), or for every provider without settings of its own when
+// provider is "". Zero fields keep DefaultHTTPSettings' values.
+func SetHTTPSettings(provider string, s HTTPSettings) error {
+	if s.Timeout <= 0 {
+		s.Timeout = DefaultHTTPSettings.Timeout
+	}
+	if s.MaxIdleConnsPerHost <= 0 {
+		s.MaxIdleConnsPerHost = DefaultHTTPSettings.MaxIdleConnsPerHost
+	}
+	var proxy *url.URL
+	if s.Proxy != "" {
+		var err error
+		if proxy, err = ParseProxy(s.Proxy); err != nil {
+			return err
+		}
+	}
+
+	httpClients.mu.Lock()
+	defer httpClients.mu.Unlock()
+	if old, ok := httpClients.clients[provider]; ok {
+		old.CloseIdleConnections()
+	}
+	httpClients.clients[provider] = newHTTPClient(s, proxy)
+	return nil
+}
+
+// ParseProxy checks a proxy URL: http, https, or socks5, with a host
+func ParseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: want an http, https, or socks5 URL", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: no host", raw)
+	}
+	return u, nil
+}
+
+// httpClientFor returns the client requests to provider go through
+func httpClientFor(provider string) *http.Client {
+	httpClients.mu.RLock()
+	defer httpClients.mu.RUnlock()
+	if client, ok := httpClients.clients[provider]; ok {
+		return client
+	}
+	return httpClients.clients[""]
+}
+
+// newHTTPClient builds a client with its own connection pool. proxy nil means
+// the environment's proxy, if any.
+func newHTTPClient(s HTTPSettings, proxy *url.URL) *http.Client {
+	proxyFunc := http.ProxyFromEnvironment
+	if proxy != nil {
+		proxyFunc = http.ProxyURL(proxy)
+	}
+	return &http.Client{
+		Timeout: s.Timeout,
+		Transport: &http.Transport{
+			Proxy: proxyFunc,
+			DialContext: (&net.Dialer{
+				Timeout:   10 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   s.MaxIdleConnsPerHost,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
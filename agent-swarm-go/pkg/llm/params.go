@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerationParams tune how the model writes its response. Unset fields leave
+// the provider's default in place, except that Anthropic, which requires a
+// token limit, gets defaultAnthropicMaxTokens.
+type GenerationParams struct {
+	Temperature *float64 `yaml:"temperature" json:"temperature,omitempty"` // 0-2 for OpenAI, 0-1 for Anthropic; lower is more deterministic
+	TopP        *float64 `yaml:"top_p" json:"top_p,omitempty"`             // Nucleus sampling, 0-1
+	MaxTokens   int      `yaml:"max_tokens" json:"max_tokens,omitempty"`   // Longest response, in tokens
+	Stop        []string `yaml:"stop" json:"stop,omitempty"`               // Sequences that end the response, at most 4
+}
+
+// defaultAnthropicMaxTokens is the response limit sent to Anthropic when none
+// is set
+const defaultAnthropicMaxTokens = 4096
+
+// maxStopSequences is the most stop sequences OpenAI accepts
+const maxStopSequences = 4
+
+// Float returns a pointer to v, for setting Temperature and TopP
+func Float(v float64) *float64 {
+	return &v
+}
+
+// IsZero reports whether no parameter is set
+func (p GenerationParams) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.MaxTokens == 0 && len(p.Stop) == 0
+}
+
+// Merge returns p with every parameter o sets replaced by o's
+func (p GenerationParams) Merge(o GenerationParams) GenerationParams {
+	if o.Temperature != nil {
+		p.Temperature = o.Temperature
+	}
+	if o.TopP != nil {
+		p.TopP = o.TopP
+	}
+	if o.MaxTokens != 0 {
+		p.MaxTokens = o.MaxTokens
+	}
+	if len(o.Stop) > 0 {
+		p.Stop = o.Stop
+	}
+	return p
+}
+
+// Validate checks the parameters are within what both providers accept
+func (p GenerationParams) Validate() error {
+	var problems []string
+	if t := p.Temperature; t != nil && (*t < 0 || *t > 2) {
+		problems = append(problems, fmt.Sprintf("temperature %v is outside 0-2", *t))
+	}
+	if t := p.TopP; t != nil && (*t < 0 || *t > 1) {
+		problems = append(problems, fmt.Sprintf("top_p %v is outside 0-1", *t))
+	}
+	if p.MaxTokens < 0 {
+		problems = append(problems, "max_tokens must not be negative")
+	}
+	if len(p.Stop) > maxStopSequences {
+		problems = append(problems, fmt.Sprintf("at most %d stop sequences, got %d", maxStopSequences, len(p.Stop)))
+	}
+	for _, s := range p.Stop {
+		if s == "" {
+			problems = append(problems, "stop sequences must not be empty")
+			break
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Params returns the generation parameters the client sends
+func (c *Client) Params() GenerationParams {
+	return c.params
+}
+
+// WithParams returns a copy of the client that sends its parameters with
+// those p sets replaced. The original client is unchanged.
+func (c *Client) WithParams(p GenerationParams) *Client {
+	copied := *c
+	copied.params = c.params.Merge(p)
+	return &copied
+}
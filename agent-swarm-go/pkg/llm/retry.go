@@ -0,0 +1,319 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy says how often a request that failed for a transient reason
+// (a network error, 429, or 5xx) is tried again, and how long to wait between
+// attempts. A Retry-After header from the provider wins over the backoff.
+type RetryPolicy struct {
+	MaxAttempts    int           // Attempts per request, the first included; 1 disables retries
+	InitialBackoff time.Duration // Wait before the second attempt; doubles for each one after
+	MaxBackoff     time.Duration // Longest wait between attempts, Retry-After included
+}
+
+// DefaultRetryPolicy tries a request three times, waiting 1s then 2s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// retryPolicy is the policy every client in the process follows
+var retryPolicy = struct {
+	mu     sync.RWMutex
+	policy RetryPolicy
+}{policy: DefaultRetryPolicy}
+
+// SetRetryPolicy changes how every client retries transient failures. Zero
+// fields keep DefaultRetryPolicy's values.
+func SetRetryPolicy(p RetryPolicy) {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+
+	retryPolicy.mu.Lock()
+	defer retryPolicy.mu.Unlock()
+	retryPolicy.policy = p
+}
+
+// currentRetryPolicy returns the policy in force
+func currentRetryPolicy() RetryPolicy {
+	retryPolicy.mu.RLock()
+	defer retryPolicy.mu.RUnlock()
+	return retryPolicy.policy
+}
+
+// APIError is a provider's answer to a request it didn't accept
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // From the Retry-After header; 0 when absent
+}
+
+// Error keeps the "API error: <body>" wording agents have always shown
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %s", e.Body)
+}
+
+// Temporary reports whether the request may succeed if sent again
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// CallStats counts the API calls made under a context, so an agent can report
+// what a task cost (see WithCallStats). It is safe for concurrent use.
+type CallStats struct {
+	mu       sync.Mutex
+	calls    int
+	attempts int
+	route    *Route // The first routing decision (see Router)
+	provider string // Who answered the last request, and with which model
+	model    string
+
+	inputTokens, outputTokens int
+	costUSD                   float64
+}
+
+type callStatsKey struct{}
+
+// WithCallStats returns a context whose API calls are counted in the returned
+// CallStats
+func WithCallStats(ctx context.Context) (context.Context, *CallStats) {
+	stats := &CallStats{}
+	return context.WithValue(ctx, callStatsKey{}, stats), stats
+}
+
+// Calls returns how many requests were sent to the API
+func (s *CallStats) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// Attempts returns how many HTTP attempts those requests took, retries
+// included
+func (s *CallStats) Attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+// record counts one request and its attempts
+func (s *CallStats) record(attempts int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	s.attempts += attempts
+}
+
+// Tokens returns the input and output tokens the API reported for the
+// requests; mock and cached responses count nothing
+func (s *CallStats) Tokens() (input, output int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inputTokens, s.outputTokens
+}
+
+// CostUSD returns the estimated cost of the requests at list price
+func (s *CallStats) CostUSD() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.costUSD
+}
+
+// recordTokens adds one request's token counts and cost
+func (s *CallStats) recordTokens(input, output int, cost float64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputTokens += input
+	s.outputTokens += output
+	s.costUSD += cost
+}
+
+// Route returns the router's decision for the first request, or nil when no
+// request was routed
+func (s *CallStats) Route() *Route {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.route
+}
+
+// recordRoute keeps the first routing decision; later requests under the same
+// context (e.g. summarizing history) are follow-ups to it
+func (s *CallStats) recordRoute(r Route) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.route == nil {
+		s.route = &r
+	}
+}
+
+// Model returns the provider and model that answered the last request: a
+// provider name, or "mock" or "replay" (see Client.GetProvider), and the
+// model, empty for the mock. Both are empty when nothing was requested.
+func (s *CallStats) Model() (provider, model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.provider, s.model
+}
+
+// recordModel notes who answers a request
+func (s *CallStats) recordModel(provider, model string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider, s.model = provider, model
+}
+
+// CallStatsFrom returns the CallStats attached to ctx, or nil
+func CallStatsFrom(ctx context.Context) *CallStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*CallStats)
+	return stats
+}
+
+// send sends the request newRequest builds and returns the response body,
+// retrying transient failures under the retry policy. newRequest is called
+// for each attempt, since a request body can only be read once.
+func (c *Client) send(ctx context.Context, newRequest func() (*http.Request, error)) ([]byte, error) {
+	var body []byte
+	err := c.sendWith(ctx, newRequest, func(r io.Reader) (err error) {
+		body, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// sendWith is send for a response read as it arrives, e.g. a stream: read is
+// given each successful attempt's body, and an error reading it counts as
+// the attempt failing
+func (c *Client) sendWith(ctx context.Context, newRequest func() (*http.Request, error), read func(io.Reader) error) error {
+	// Retries count against the swarm-wide request rate too
+	return retry(ctx, func() error { return c.sendOnce(newRequest, read) }, waitForRateLimit)
+}
+
+// retry makes attempts until one succeeds, one fails for good, or the retry
+// policy's attempts run out, and counts them in ctx's CallStats. Between
+// attempts it waits out the backoff, then calls beforeRetry if set.
+func retry(ctx context.Context, try func() error, beforeRetry func(context.Context) error) error {
+	policy := currentRetryPolicy()
+	backoff := policy.InitialBackoff
+
+	attempt := 0
+	defer func() { CallStatsFrom(ctx).record(attempt) }()
+
+	for {
+		attempt++
+		err := try()
+		if err == nil {
+			return nil
+		}
+
+		// Network errors and 429/5xx are worth another try; a cancelled
+		// context or a rejected request isn't
+		var apiErr *APIError
+		isAPIErr := errors.As(err, &apiErr)
+		retryable := ctx.Err() == nil && (!isAPIErr || apiErr.Temporary())
+		if !retryable || attempt >= policy.MaxAttempts {
+			if attempt > 1 {
+				return fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			return err
+		}
+
+		wait := backoff
+		if isAPIErr && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		if wait > policy.MaxBackoff {
+			wait = policy.MaxBackoff
+		}
+		backoff *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		if beforeRetry != nil {
+			if err := beforeRetry(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendOnce makes a single attempt, handing a successful response's body to
+// read
+func (c *Client) sendOnce(newRequest func() (*http.Request, error), read func(io.Reader) error) error {
+	req, err := newRequest()
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClientFor(c.provider).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return read(resp.Body)
+}
+
+// parseRetryAfter reads a Retry-After header, given in seconds or as an HTTP
+// date; it returns 0 when the header is absent or malformed
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
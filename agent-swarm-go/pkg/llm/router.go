@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Model tiers a Router chooses between
+const (
+	TierCheap   = "cheap"
+	TierPremium = "premium"
+)
+
+// ModelTiers names a provider's cheap and premium models
+type ModelTiers struct {
+	Cheap   string `yaml:"cheap" json:"cheap"`
+	Premium string `yaml:"premium" json:"premium"`
+}
+
+// DefaultModelTiers are the models a Router picks for each provider unless
+// told otherwise
+var DefaultModelTiers = map[string]ModelTiers{
+	"openai":    {Cheap: "gpt-4o-mini", Premium: "gpt-4o"},
+	"anthropic": {Cheap: "claude-3-5-haiku-20241022", Premium: "claude-3-5-sonnet-20241022"},
+}
+
+// DefaultMaxCheapTokens is the largest prompt a Router sends to the cheap
+// model when the task doesn't say how complex it is
+const DefaultMaxCheapTokens = 1000
+
+// Router sends each request to a provider's cheap or premium model. A task
+// can say which it needs with its complexity ("simple" or "complex", see
+// WithComplexity); otherwise prompts up to MaxCheapTokens go to the cheap
+// model. Clients created with an explicit model are never routed.
+type Router struct {
+	MaxCheapTokens int                   // 0 means DefaultMaxCheapTokens
+	Models         map[string]ModelTiers // By provider; missing providers use DefaultModelTiers
+}
+
+// Route is a Router's decision for one request
+type Route struct {
+	Tier   string `json:"tier"`   // TierCheap or TierPremium
+	Model  string `json:"model"`  // The model the request went to
+	Reason string `json:"reason"` // e.g. "prompt of 240 tokens, at most 1000"
+}
+
+// router is the router every client in the process follows; nil sends each
+// request to the client's own model
+var router struct {
+	mu     sync.RWMutex
+	router *Router
+}
+
+// SetRouter makes every client without an explicit model route requests
+// between cheap and premium models (nil turns routing off)
+func SetRouter(r *Router) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+	router.router = r
+}
+
+// currentRouter returns the router in force, or nil
+func currentRouter() *Router {
+	router.mu.RLock()
+	defer router.mu.RUnlock()
+	return router.router
+}
+
+// Route picks the model for a request to provider with messages, under a
+// task of the given complexity ("" when unknown). ok is false when the
+// router has no models for the provider.
+func (r *Router) Route(provider, complexity string, messages []Message) (route Route, ok bool) {
+	tiers, ok := r.Models[provider]
+	if !ok {
+		tiers, ok = DefaultModelTiers[provider]
+	}
+	if !ok {
+		return Route{}, false
+	}
+	maxCheap := r.MaxCheapTokens
+	if maxCheap <= 0 {
+		maxCheap = DefaultMaxCheapTokens
+	}
+
+	switch complexity {
+	case "simple":
+		route = Route{Tier: TierCheap, Reason: "task marked simple"}
+	case "complex":
+		route = Route{Tier: TierPremium, Reason: "task marked complex"}
+	default:
+		if tokens := CountMessageTokens(messages); tokens <= maxCheap {
+			route = Route{Tier: TierCheap, Reason: fmt.Sprintf("prompt of %d tokens, at most %d", tokens, maxCheap)}
+		} else {
+			route = Route{Tier: TierPremium, Reason: fmt.Sprintf("prompt of %d tokens, over %d", tokens, maxCheap)}
+		}
+	}
+	route.Model = tiers.Cheap
+	if route.Tier == TierPremium {
+		route.Model = tiers.Premium
+	}
+	return route, true
+}
+
+type complexityKey struct{}
+
+// WithComplexity returns a context whose requests a Router treats as coming
+// from a task of the given complexity: "simple", "complex", or "" to judge
+// by prompt size
+func WithComplexity(ctx context.Context, complexity string) context.Context {
+	return context.WithValue(ctx, complexityKey{}, complexity)
+}
+
+// complexityFrom returns the complexity attached to ctx, or ""
+func complexityFrom(ctx context.Context) string {
+	complexity, _ := ctx.Value(complexityKey{}).(string)
+	return complexity
+}
+
+// route returns the model a request with messages goes to, recording the
+// router's decision in the context's CallStats
+func (c *Client) route(ctx context.Context, messages []Message) string {
+	r := currentRouter()
+	if r == nil || c.pinned {
+		return c.model
+	}
+	decision, ok := r.Route(c.provider, complexityFrom(ctx), messages)
+	if !ok {
+		return c.model
+	}
+	CallStatsFrom(ctx).recordRoute(decision)
+	return decision.Model
+}
@@ -0,0 +1,241 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Latency distributions a Simulation can draw mock response times from
+const (
+	LatencyFixed       = "fixed"       // Always Mean
+	LatencyUniform     = "uniform"     // Anywhere between Min and Max
+	LatencyNormal      = "normal"      // Around Mean, StdDev either side
+	LatencyExponential = "exponential" // Mostly quick, with a long tail; averages Mean
+)
+
+// simulatedChunks is about how many pieces a simulated response streams in
+const simulatedChunks = 20
+
+// Latency is a distribution of response times. Draws are kept between Min
+// and Max; a Max of 0 leaves them unbounded above.
+type Latency struct {
+	Distribution string        // One of the Latency* constants; "" means LatencyFixed
+	Mean         time.Duration // For fixed, normal, and exponential
+	StdDev       time.Duration // For normal
+	Min          time.Duration
+	Max          time.Duration
+}
+
+// Validate reports a distribution that isn't known or bounds that can't hold
+func (l Latency) Validate() error {
+	switch l.Distribution {
+	case "", LatencyFixed, LatencyUniform, LatencyNormal, LatencyExponential:
+	default:
+		return fmt.Errorf("unknown latency distribution %q (want fixed, uniform, normal, or exponential)", l.Distribution)
+	}
+	if l.Mean < 0 || l.StdDev < 0 || l.Min < 0 || l.Max < 0 {
+		return fmt.Errorf("latency durations can't be negative")
+	}
+	if l.Max > 0 && l.Min > l.Max {
+		return fmt.Errorf("latency min %v is above max %v", l.Min, l.Max)
+	}
+	if l.Distribution == LatencyUniform && l.Max == 0 {
+		return fmt.Errorf("a uniform latency needs a max")
+	}
+	return nil
+}
+
+// draw returns a response time from the distribution
+func (l Latency) draw(r *rand.Rand) time.Duration {
+	var d time.Duration
+	switch l.Distribution {
+	case LatencyUniform:
+		d = l.Min
+		if l.Max > l.Min {
+			d += time.Duration(r.Int63n(int64(l.Max-l.Min) + 1))
+		}
+	case LatencyNormal:
+		d = l.Mean + time.Duration(r.NormFloat64()*float64(l.StdDev))
+	case LatencyExponential:
+		d = time.Duration(r.ExpFloat64() * float64(l.Mean))
+	default:
+		d = l.Mean
+	}
+	if d < l.Min {
+		d = l.Min
+	}
+	if l.Max > 0 && d > l.Max {
+		d = l.Max
+	}
+	return d
+}
+
+// Simulation makes mock mode behave like a real provider, so a demo without
+// an API key exercises timeouts, retries, and progress displays. Each mock
+// attempt takes a time drawn from Latency, streaming its text over that time
+// to a StreamFunc (see WithStream), and may fail. Rates are shares of
+// attempts between 0 and 1, and add up to at most 1:
+//
+//   - ErrorRate attempts fail like an overloaded provider (503) and are
+//     retried under the retry policy (see SetRetryPolicy)
+//   - FatalRate attempts are rejected like a bad request (400), which isn't
+//     retried, so the task fails
+//   - HangRate attempts never answer, until the request's context is done,
+//     e.g. when the task times out
+//
+// Real API calls, cached, and replayed responses are left alone.
+type Simulation struct {
+	Latency   Latency
+	ErrorRate float64
+	FatalRate float64
+	HangRate  float64
+}
+
+// Enabled reports whether the simulation changes anything
+func (s Simulation) Enabled() bool {
+	return s.Latency.Mean > 0 || s.Latency.Min > 0 || s.Latency.Max > 0 ||
+		s.ErrorRate > 0 || s.FatalRate > 0 || s.HangRate > 0
+}
+
+// Validate reports rates outside 0..1 or a latency that can't be drawn
+func (s Simulation) Validate() error {
+	for _, rate := range []struct {
+		name  string
+		value float64
+	}{{"error_rate", s.ErrorRate}, {"fatal_rate", s.FatalRate}, {"hang_rate", s.HangRate}} {
+		if rate.value < 0 || rate.value > 1 || math.IsNaN(rate.value) {
+			return fmt.Errorf("%s must be between 0 and 1", rate.name)
+		}
+	}
+	if s.ErrorRate+s.FatalRate+s.HangRate > 1 {
+		return fmt.Errorf("error_rate, fatal_rate, and hang_rate add up to more than 1")
+	}
+	return s.Latency.Validate()
+}
+
+// simulation is the Simulation every client in the process runs in mock mode
+var simulation struct {
+	mu   sync.Mutex
+	sim  Simulation
+	rand *rand.Rand
+}
+
+// SetSimulation makes every client in mock mode follow sim, until
+// SetSimulation(Simulation{}) turns it off. Validate it first.
+func SetSimulation(sim Simulation) {
+	simulation.mu.Lock()
+	defer simulation.mu.Unlock()
+	simulation.sim = sim
+	if simulation.rand == nil {
+		simulation.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
+// CurrentSimulation returns the simulation in force
+func CurrentSimulation() Simulation {
+	simulation.mu.Lock()
+	defer simulation.mu.Unlock()
+	return simulation.sim
+}
+
+// simulatedAttempt is how one mock attempt goes
+type simulatedAttempt struct {
+	latency time.Duration
+	err     error // nil for a response
+	hang    bool
+}
+
+// nextAttempt draws how the next mock attempt goes
+func nextAttempt() simulatedAttempt {
+	simulation.mu.Lock()
+	defer simulation.mu.Unlock()
+	sim := simulation.sim
+	r := simulation.rand
+
+	attempt := simulatedAttempt{latency: sim.Latency.draw(r)}
+	switch roll := r.Float64(); {
+	case roll < sim.HangRate:
+		attempt.hang = true
+	case roll < sim.HangRate+sim.FatalRate:
+		attempt.err = &APIError{StatusCode: http.StatusBadRequest, Body: "simulated bad request (mock simulation)"}
+	case roll < sim.HangRate+sim.FatalRate+sim.ErrorRate:
+		attempt.err = &APIError{StatusCode: http.StatusServiceUnavailable, Body: "simulated overload (mock simulation)"}
+	}
+	return attempt
+}
+
+// simulate returns a mock response as the simulation has it: late, streamed,
+// retried, or failed. Without a simulation it returns response at once.
+func simulate(ctx context.Context, response string) (string, error) {
+	if !CurrentSimulation().Enabled() {
+		return response, nil
+	}
+	err := retry(ctx, func() error {
+		attempt := nextAttempt()
+		if attempt.hang {
+			<-ctx.Done()
+			return fmt.Errorf("simulated hang (mock simulation): %w", ctx.Err())
+		}
+		if attempt.err != nil {
+			return sleepContext(ctx, attempt.latency, attempt.err)
+		}
+		return streamSimulated(ctx, response, attempt.latency)
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	return response, nil
+}
+
+// streamSimulated takes latency to hand response to ctx's StreamFunc in
+// pieces, or just waits it out when nothing streams
+func streamSimulated(ctx context.Context, response string, latency time.Duration) error {
+	fn := streamFrom(ctx)
+	if fn == nil {
+		return sleepContext(ctx, latency, nil)
+	}
+
+	words := strings.SplitAfter(response, " ")
+	per := (len(words) + simulatedChunks - 1) / simulatedChunks
+	if per < 1 {
+		per = 1
+	}
+	chunks := (len(words) + per - 1) / per
+	if chunks < 1 {
+		chunks = 1
+	}
+	interval := latency / time.Duration(chunks)
+	for i := 0; i < len(words); i += per {
+		if err := sleepContext(ctx, interval, nil); err != nil {
+			return err
+		}
+		end := i + per
+		if end > len(words) {
+			end = len(words)
+		}
+		fn(strings.Join(words[i:end], ""))
+	}
+	return nil
+}
+
+// sleepContext waits d and returns err, or returns ctx's error if it is done
+// first
+func sleepContext(ctx context.Context, d time.Duration, err error) error {
+	if d <= 0 {
+		return err
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StreamFunc receives a response's text as the model generates it, a chunk at
+// a time. Chunks arrive in order, on the goroutine making the request.
+type StreamFunc func(chunk string)
+
+type streamKey struct{}
+
+// WithStream returns a context whose requests stream the model's response to
+// fn as it arrives; CompleteContext still returns the whole response. Only
+// real API calls and simulated mock responses (see SetSimulation) stream:
+// other mock, cached, and replayed responses arrive at once and fn never
+// hears of them. A request retried after a failed attempt
+// streams again from the start. A nil fn turns streaming off, e.g. for a
+// follow-up request whose output isn't the task's.
+func WithStream(ctx context.Context, fn StreamFunc) context.Context {
+	return context.WithValue(ctx, streamKey{}, fn)
+}
+
+// streamFrom returns the StreamFunc attached to ctx, or nil
+func streamFrom(ctx context.Context) StreamFunc {
+	fn, _ := ctx.Value(streamKey{}).(StreamFunc)
+	return fn
+}
+
+// readSSE calls fn with the data of each server-sent event in r, until the
+// stream ends, fn fails, or OpenAI's closing "[DONE]"
+func readSSE(r io.Reader, fn func(data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // Event names, comments, and the blank lines between events
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+		if err := fn([]byte(data)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// openAIChunk is one event of a streamed OpenAI response. With
+// stream_options.include_usage, the last one has the usage and no choices.
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// readOpenAIStream reads a streamed OpenAI response from r, passing its text
+// to fn, and returns it as the non-streamed OpenAIResponse would have it
+func readOpenAIStream(r io.Reader, fn StreamFunc) (*OpenAIResponse, error) {
+	var text strings.Builder
+	resp := &OpenAIResponse{}
+	err := readSSE(r, func(data []byte) error {
+		var chunk openAIChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("reading response stream: %w", err)
+		}
+		if chunk.Usage != nil {
+			resp.Usage.PromptTokens = chunk.Usage.PromptTokens
+			resp.Usage.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			text.WriteString(chunk.Choices[0].Delta.Content)
+			fn(chunk.Choices[0].Delta.Content)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Choices = append(resp.Choices, struct {
+		Message Message `json:"message"`
+	}{Message: Message{Role: "assistant", Content: text.String()}})
+	return resp, nil
+}
+
+// anthropicEvent is one event of a streamed Anthropic response. The input
+// tokens come in message_start, the output tokens in message_delta, and the
+// text in content_block_delta events.
+type anthropicEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// readAnthropicStream reads a streamed Anthropic response from r, passing its
+// text to fn, and returns it as the non-streamed AnthropicResponse would have
+// it. An error event, e.g. when the API is overloaded mid-response, fails it.
+func readAnthropicStream(r io.Reader, fn StreamFunc) (*AnthropicResponse, error) {
+	var text strings.Builder
+	resp := &AnthropicResponse{}
+	err := readSSE(r, func(data []byte) error {
+		var event anthropicEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("reading response stream: %w", err)
+		}
+		switch event.Type {
+		case "message_start":
+			resp.Usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Text != "" {
+				text.WriteString(event.Delta.Text)
+				fn(event.Delta.Text)
+			}
+		case "message_delta":
+			resp.Usage.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("API error: %s", event.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Content = append(resp.Content, struct {
+		Text string `json:"text"`
+	}{Text: text.String()})
+	return resp, nil
+}
@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// messageOverhead is the framing each chat message costs on top of its
+// content (role and separators)
+const messageOverhead = 4
+
+// CountTokens estimates how many tokens text costs. It splits text the way
+// tiktoken's cl100k encoding does before merging, into words with the space
+// or symbol in front of them, runs of up to three digits, punctuation, and
+// whitespace, then charges each piece what a BPE vocabulary typically would.
+// It needs no vocabulary and is close enough to budget history with.
+func CountTokens(text string) int {
+	tokens := 0
+	scanTokens(text, func(_, cost int) bool {
+		tokens += cost
+		return true
+	})
+	return tokens
+}
+
+// CountMessageTokens estimates the tokens messages cost in a request
+func CountMessageTokens(messages []Message) int {
+	tokens := 0
+	for _, msg := range messages {
+		tokens += messageOverhead + CountTokens(msg.Content)
+	}
+	return tokens
+}
+
+// TruncateTokens returns the longest prefix of text, cut between pieces, that
+// CountTokens puts at no more than max tokens
+func TruncateTokens(text string, max int) string {
+	tokens, cut := 0, 0
+	scanTokens(text, func(end, cost int) bool {
+		if tokens+cost > max {
+			return false
+		}
+		tokens += cost
+		cut = end
+		return true
+	})
+	return text[:cut]
+}
+
+// scanTokens walks text one piece at a time, calling yield with the offset
+// where each piece ends and its estimated cost until yield returns false
+func scanTokens(text string, yield func(end, cost int) bool) {
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		j, cost := i+size, 1
+
+		switch {
+		case unicode.IsLetter(r):
+			j, cost = scanWord(text, i)
+		case !unicode.IsNumber(r) && r != '\r' && r != '\n' && startsWord(text[j:]):
+			// One space or symbol joins the word after it
+			j, cost = scanWord(text, j)
+		case unicode.IsNumber(r):
+			for digits := 1; digits < 3 && j < len(text); digits++ {
+				next, size := utf8.DecodeRuneInString(text[j:])
+				if !unicode.IsNumber(next) {
+					break
+				}
+				j += size
+			}
+		case unicode.IsSpace(r):
+			for j < len(text) {
+				next, size := utf8.DecodeRuneInString(text[j:])
+				if !unicode.IsSpace(next) {
+					break
+				}
+				j += size
+			}
+			// The last space before a word belongs to the word
+			if j-i > 1 && text[j-1] == ' ' && startsWord(text[j:]) {
+				j--
+			}
+		default:
+			// A run of punctuation, with any line breaks after it
+			symbols := 1
+			for j < len(text) {
+				next, size := utf8.DecodeRuneInString(text[j:])
+				if unicode.IsSpace(next) || unicode.IsLetter(next) || unicode.IsNumber(next) {
+					break
+				}
+				j += size
+				symbols++
+			}
+			for j < len(text) && (text[j] == '\r' || text[j] == '\n') {
+				j++
+			}
+			cost = (symbols + 2) / 3
+		}
+
+		i = j
+		if !yield(i, cost) {
+			return
+		}
+	}
+}
+
+// startsWord reports whether text begins with a letter
+func startsWord(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsLetter(r)
+}
+
+// scanWord returns where the letters starting at text[i:] end and what they
+// cost. Common words are one token and long ones are split about every eight
+// letters; ideographic scripts cost a token a character.
+func scanWord(text string, i int) (int, int) {
+	letters, wide := 0, 0
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		if r >= 0x2E80 { // CJK and later blocks
+			wide++
+		} else {
+			letters++
+		}
+		i += size
+	}
+
+	cost := wide
+	if letters > 0 {
+		cost += 1 + (letters-1)/8
+	}
+	return i, cost
+}
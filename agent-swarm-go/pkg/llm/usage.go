@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Usage is the token count and estimated cost of API calls for one model
+type Usage struct {
+	Model        string  `json:"model"`
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"` // Estimate from modelPrices; 0 for unknown models
+}
+
+// modelPrice is a model's list price in USD per million tokens
+type modelPrice struct {
+	input, output float64
+}
+
+// modelPrices holds list prices by model name prefix; the longest matching
+// prefix wins, so "gpt-4o-mini" isn't priced as "gpt-4"
+var modelPrices = map[string]modelPrice{
+	"gpt-4":             {30, 60},
+	"gpt-4-turbo":       {10, 30},
+	"gpt-4o":            {2.5, 10},
+	"gpt-4o-mini":       {0.15, 0.6},
+	"gpt-3.5-turbo":     {0.5, 1.5},
+	"claude-3-5-sonnet": {3, 15},
+	"claude-3-5-haiku":  {0.8, 4},
+	"claude-3-opus":     {15, 75},
+	"claude-3-haiku":    {0.25, 1.25},
+}
+
+// usageTotals accumulates Usage for every client in the process
+var usageTotals struct {
+	mu      sync.Mutex
+	byModel map[string]*Usage
+}
+
+// recordUsage adds one API call's token counts to the process totals, and to
+// the CallStats of ctx, if any
+func recordUsage(ctx context.Context, model string, inputTokens, outputTokens int) {
+	CallStatsFrom(ctx).recordTokens(inputTokens, outputTokens, estimateCost(model, inputTokens, outputTokens))
+
+	usageTotals.mu.Lock()
+	defer usageTotals.mu.Unlock()
+
+	if usageTotals.byModel == nil {
+		usageTotals.byModel = make(map[string]*Usage)
+	}
+	u := usageTotals.byModel[model]
+	if u == nil {
+		u = &Usage{Model: model}
+		usageTotals.byModel[model] = u
+	}
+	u.Requests++
+	u.InputTokens += inputTokens
+	u.OutputTokens += outputTokens
+	u.CostUSD += estimateCost(model, inputTokens, outputTokens)
+}
+
+// estimateCost prices a call at the model's list price
+func estimateCost(model string, inputTokens, outputTokens int) float64 {
+	var best string
+	for prefix := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0
+	}
+	price := modelPrices[best]
+	return (float64(inputTokens)*price.input + float64(outputTokens)*price.output) / 1e6
+}
+
+// TotalUsage returns the API usage of every client since the process
+// started, one entry per model, sorted by model name. Mock-mode calls aren't
+// counted.
+func TotalUsage() []Usage {
+	usageTotals.mu.Lock()
+	defer usageTotals.mu.Unlock()
+
+	list := make([]Usage, 0, len(usageTotals.byModel))
+	for _, u := range usageTotals.byModel {
+		list = append(list, *u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Model < list[j].Model })
+	return list
+}
+
+// SumUsage adds up per-model usage into a single total with no model name
+func SumUsage(usage []Usage) Usage {
+	var total Usage
+	for _, u := range usage {
+		total.Requests += u.Requests
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+		total.CostUSD += u.CostUSD
+	}
+	return total
+}
+
+// Sub returns the usage accumulated since an earlier snapshot of the same model
+func (u Usage) Sub(earlier Usage) Usage {
+	return Usage{
+		Model:        u.Model,
+		Requests:     u.Requests - earlier.Requests,
+		InputTokens:  u.InputTokens - earlier.InputTokens,
+		OutputTokens: u.OutputTokens - earlier.OutputTokens,
+		CostUSD:      u.CostUSD - earlier.CostUSD,
+	}
+}
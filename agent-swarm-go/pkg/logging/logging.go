@@ -0,0 +1,87 @@
+// Package logging configures the structured logger used by agents, the swarm,
+// and the web server.
+//
+// All components log through log/slog's default logger with consistent field
+// names (see the Key constants), so output can be filtered by agent or task and
+// shipped to log aggregation systems when the JSON handler is selected.
+//
+// # Configuration
+//
+// Setup reads two environment variables (both optional):
+//   - LOG_FORMAT: "text" (default) or "json"
+//   - LOG_LEVEL:  "debug", "info" (default), "warn", or "error"
+//
+// Logs are written to stderr so they never mix with machine-readable output
+// printed to stdout. Secrets are scrubbed from messages and attributes before
+// they are written (see pkg/redact).
+//
+// # Usage Example
+//
+//	logging.Setup()
+//	slog.Info("task received", logging.KeyAgent, "researcher-1", logging.KeyTask, "task-001")
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"agent-swarm-go/pkg/redact"
+)
+
+// Common field keys so every component logs agents and tasks the same way
+const (
+	KeyAgent = "agent"
+	KeyTask  = "task"
+	KeyError = "error"
+)
+
+// Setup installs the default slog logger configured from LOG_FORMAT and
+// LOG_LEVEL, writing to stderr, and returns it.
+func Setup() *slog.Logger {
+	return SetupWriter(os.Stderr, os.Getenv("LOG_FORMAT"), os.Getenv("LOG_LEVEL"))
+}
+
+// SetupWriter installs the default slog logger writing to w with the given
+// format ("text" or "json") and level name, and returns it.
+// Unknown formats fall back to text and unknown levels fall back to info.
+func SetupWriter(w io.Writer, format, level string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(level), ReplaceAttr: redactAttr}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "json") {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// redactAttr scrubs secrets from the message and every attribute value
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redact.String(a.Value.String()))
+	case slog.KindAny:
+		a.Value = slog.AnyValue(redact.Value(a.Value.Any()))
+	}
+	return a
+}
+
+// ParseLevel converts a level name to a slog.Level, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
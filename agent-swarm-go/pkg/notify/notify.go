@@ -0,0 +1,192 @@
+// Package notify posts workflow completions, failures, and LLM cost
+// summaries to a Slack or Discord channel through an incoming webhook, so a
+// long research job can be started and left alone.
+//
+// The notifier only watches the swarm's event bus; workflows don't need to
+// know it exists:
+//
+//	n := notify.New(notify.Config{SlackWebhookURL: os.Getenv("SLACK_WEBHOOK_URL")})
+//	go n.Run(ctx, s.GetEventBus())
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// discordMaxLength is the longest message Discord accepts
+const discordMaxLength = 2000
+
+// Config says where notifications go. Either URL, or both, may be set.
+type Config struct {
+	SlackWebhookURL     string        // Slack incoming webhook
+	DiscordWebhookURL   string        // Discord channel webhook
+	CostSummaryInterval time.Duration // How often to post LLM spend; 0 disables the summary
+
+	// IsLeader, when set, limits the cost summary to the times it returns
+	// true, so only one of several swarms posts it (see swarm.IsLeader)
+	IsLeader func() bool
+}
+
+// Notifier turns workflow events into chat messages
+type Notifier struct {
+	cfg Config
+
+	mu      sync.Mutex
+	running map[string]workflowRun // Workflows in progress, by workflow ID
+}
+
+// workflowRun is what the notifier remembers about a started workflow
+type workflowRun struct {
+	name    string
+	started time.Time
+	usage   llm.Usage // Process-wide LLM usage when the workflow started
+}
+
+// New creates a notifier
+func New(cfg Config) *Notifier {
+	return &Notifier{cfg: cfg, running: make(map[string]workflowRun)}
+}
+
+// Enabled reports whether any webhook is configured
+func (n *Notifier) Enabled() bool {
+	return n.cfg.SlackWebhookURL != "" || n.cfg.DiscordWebhookURL != ""
+}
+
+// Run posts notifications for the bus's workflow events, and the periodic
+// cost summary, until ctx is done
+func (n *Notifier) Run(ctx context.Context, bus *types.EventBus) {
+	events := bus.SubscribeWithBuffer(100)
+
+	var summary <-chan time.Time
+	if n.cfg.CostSummaryInterval > 0 {
+		ticker := time.NewTicker(n.cfg.CostSummaryInterval)
+		defer ticker.Stop()
+		summary = ticker.C
+	}
+	lastSummary := llm.TotalUsage()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			n.handleEvent(event)
+		case <-summary:
+			usage := llm.TotalUsage()
+			if n.cfg.IsLeader != nil && !n.cfg.IsLeader() {
+				lastSummary = usage
+				continue
+			}
+			if text := costSummary(usage, lastSummary, n.cfg.CostSummaryInterval); text != "" {
+				n.post(text)
+			}
+			lastSummary = usage
+		}
+	}
+}
+
+// handleEvent remembers started workflows and announces finished ones
+func (n *Notifier) handleEvent(event types.Event) {
+	switch event.Type {
+	case types.EventWorkflowStarted:
+		run := workflowRun{started: event.Timestamp, usage: llm.SumUsage(llm.TotalUsage())}
+		if plan, ok := event.Data.(types.WorkflowPlan); ok {
+			run.name = plan.Name
+		}
+		n.mu.Lock()
+		n.running[event.TaskID] = run
+		n.mu.Unlock()
+
+	case types.EventWorkflowDone, types.EventWorkflowFailed:
+		n.mu.Lock()
+		run, ok := n.running[event.TaskID]
+		delete(n.running, event.TaskID)
+		n.mu.Unlock()
+		if !ok {
+			run = workflowRun{started: event.Timestamp, usage: llm.SumUsage(llm.TotalUsage())}
+		}
+		n.post(workflowMessage(event, run))
+	}
+}
+
+// workflowMessage describes a finished workflow. Usage is the swarm-wide
+// spend while it ran, so it includes any work that overlapped it.
+func workflowMessage(event types.Event, run workflowRun) string {
+	name := run.name
+	if name == "" {
+		name = event.TaskID
+	}
+
+	var b strings.Builder
+	if event.Type == types.EventWorkflowDone {
+		fmt.Fprintf(&b, "🎉 Workflow completed: %s\n", name)
+	} else {
+		fmt.Fprintf(&b, "❌ Workflow failed: %s\n", name)
+		fmt.Fprintf(&b, "Reason: %s\n", strings.TrimPrefix(event.Message, "❌ Workflow failed: "))
+	}
+	fmt.Fprintf(&b, "Duration: %v\n", event.Timestamp.Sub(run.started).Round(time.Second))
+
+	spent := llm.SumUsage(llm.TotalUsage()).Sub(run.usage)
+	if spent.Requests > 0 {
+		fmt.Fprintf(&b, "LLM usage: %s\n", formatUsage(spent))
+	}
+	fmt.Fprintf(&b, "Workflow ID: %s", event.TaskID)
+	return b.String()
+}
+
+// costSummary describes LLM spend between two TotalUsage snapshots, one line
+// per model, or returns "" when nothing was spent
+func costSummary(now, before []llm.Usage, period time.Duration) string {
+	earlier := make(map[string]llm.Usage, len(before))
+	for _, u := range before {
+		earlier[u.Model] = u
+	}
+
+	var lines []string
+	var spent []llm.Usage
+	for _, u := range now {
+		delta := u.Sub(earlier[u.Model])
+		if delta.Requests == 0 {
+			continue
+		}
+		spent = append(spent, delta)
+		lines = append(lines, fmt.Sprintf("• %s: %s", u.Model, formatUsage(delta)))
+	}
+	if len(spent) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("💰 LLM cost summary (last %v)\n%s\nTotal: %s",
+		period, strings.Join(lines, "\n"), formatUsage(llm.SumUsage(spent)))
+}
+
+// formatUsage renders usage as "12 requests, 34567 tokens, ~$0.52"
+func formatUsage(u llm.Usage) string {
+	return fmt.Sprintf("%d requests, %d tokens, ~$%.2f", u.Requests, u.InputTokens+u.OutputTokens, u.CostUSD)
+}
+
+// post sends text to every configured webhook in the background
+func (n *Notifier) post(text string) {
+	if n.cfg.SlackWebhookURL != "" {
+		webhook.Send(n.cfg.SlackWebhookURL, map[string]string{"text": text}, "slack notification")
+	}
+	if n.cfg.DiscordWebhookURL != "" {
+		if runes := []rune(text); len(runes) > discordMaxLength {
+			text = string(runes[:discordMaxLength-1]) + "…"
+		}
+		webhook.Send(n.cfg.DiscordWebhookURL, map[string]string{"content": text}, "discord notification")
+	}
+	slog.Debug("notification posted", "text", text)
+}
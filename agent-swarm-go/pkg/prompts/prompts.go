@@ -0,0 +1,219 @@
+// Package prompts holds the system prompts agents send with each task as
+// text/template templates, so they can be tuned from files or the swarm
+// config without recompiling.
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultDir is where overrides are loaded from when the config names no
+// directory
+const DefaultDir = "prompts"
+
+// Ext is the extension of prompt files, which are named after the prompt they
+// override: research.tmpl, critic.tmpl, ...
+const Ext = ".tmpl"
+
+// Data is what a prompt template is rendered with, e.g. {{.Task.Description}}
+// or {{.Task.Payload.language}}
+type Data struct {
+	AgentID string     // Agent sending the prompt
+	Task    types.Task // Task it is working on; zero outside a task
+}
+
+// Registry holds named prompt templates. Every name has a built-in default,
+// which an override replaces. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	defaults  map[string]string
+	templates map[string]*template.Template
+	sources   map[string]string // Where each override came from; absent for defaults
+}
+
+// NewRegistry creates a registry of the given built-in prompts, keyed by name.
+// It panics if one of them isn't a valid template.
+func NewRegistry(defaults map[string]string) *Registry {
+	r := &Registry{
+		defaults:  defaults,
+		templates: make(map[string]*template.Template, len(defaults)),
+		sources:   make(map[string]string),
+	}
+	for name, text := range defaults {
+		r.templates[name] = template.Must(parse(name, text))
+	}
+	return r
+}
+
+// parse parses one prompt template
+func parse(name, text string) (*template.Template, error) {
+	return template.New(name).Parse(text)
+}
+
+// Names lists the prompts in the registry, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.defaults))
+	for name := range r.defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns name's built-in template text
+func (r *Registry) Default(name string) (string, bool) {
+	text, ok := r.defaults[name]
+	return text, ok
+}
+
+// Source returns where name's template came from: "built-in", or the file or
+// config that overrode it
+func (r *Registry) Source(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if source, ok := r.sources[name]; ok {
+		return source
+	}
+	return "built-in"
+}
+
+// Check reports whether text could override name, without changing anything
+func (r *Registry) Check(name, text string) error {
+	if _, ok := r.defaults[name]; !ok {
+		return fmt.Errorf("unknown prompt %q (want one of: %s)", name, strings.Join(r.Names(), ", "))
+	}
+	if _, err := parse(name, text); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Override replaces name's template with text; source says where it came from
+func (r *Registry) Override(name, text, source string) error {
+	if err := r.Check(name, text); err != nil {
+		return err
+	}
+	tmpl, _ := parse(name, text)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[name] = tmpl
+	r.sources[name] = source
+	return nil
+}
+
+// Swap overrides name's template with text for a while, e.g. to try a prompt
+// out, and returns a function that puts the previous template back
+func (r *Registry) Swap(name, text, source string) (restore func(), err error) {
+	if err := r.Check(name, text); err != nil {
+		return nil, err
+	}
+	tmpl, _ := parse(name, text)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.templates[name]
+	previousSource, overridden := r.sources[name]
+	r.templates[name] = tmpl
+	r.sources[name] = source
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.templates[name] = previous
+		if overridden {
+			r.sources[name] = previousSource
+		} else {
+			delete(r.sources, name)
+		}
+	}, nil
+}
+
+// LoadDir overrides a prompt for each <name>.tmpl file in dir and returns how
+// many it loaded. A file for a prompt the registry doesn't have is an error,
+// so a misnamed file doesn't go unnoticed.
+func (r *Registry) LoadDir(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var problems []string
+	loaded := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Ext {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		text, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		// Editors end files with a newline the built-in prompts don't have
+		trimmed := strings.TrimRight(string(text), "\r\n")
+		if err := r.Override(strings.TrimSuffix(entry.Name(), Ext), trimmed, path); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		loaded++
+	}
+	if len(problems) > 0 {
+		return loaded, errors.New(strings.Join(problems, "; "))
+	}
+	return loaded, nil
+}
+
+// Render executes name's template with data
+func (r *Registry) Render(name string, data Data) (string, error) {
+	r.mu.RLock()
+	tmpl, ok := r.templates[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown prompt %q", name)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering prompt %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// WriteDefaults writes each built-in prompt to dir as <name>.tmpl, as a
+// starting point for overrides, and returns the paths written. Files that
+// already exist are left alone.
+func (r *Registry) WriteDefaults(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, name := range r.Names() {
+		path := filepath.Join(dir, name+Ext)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		} else if err != nil {
+			return written, err
+		}
+		_, err = f.WriteString(r.defaults[name] + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
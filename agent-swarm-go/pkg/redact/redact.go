@@ -0,0 +1,369 @@
+// Package redact scrubs secrets, such as API keys, tokens, and webhook URLs,
+// out of everything the swarm shows, where it leaves the process: log lines,
+// the dashboard's API responses and live events, webhook deliveries, archived
+// runs, and transcripts. Events on the bus itself are left as they are.
+//
+// Two kinds of secret are caught and replaced with Placeholder:
+//   - Values of environment variables that hold secrets: names ending in
+//     _KEY, _TOKEN, _SECRET, or _PASSWORD, or containing WEBHOOK, plus any
+//     added with AddEnv
+//   - Text matching a pattern: the built-in ones (OpenAI and Anthropic keys,
+//     bearer tokens, Slack tokens and webhooks, Discord webhooks, AWS access
+//     key IDs) plus any added with AddPatterns, e.g. from the config's
+//     redaction section
+//
+// # Usage Example
+//
+//	redact.AddPatterns(`acme_[0-9a-f]{32}`)
+//	redact.String("calling with sk-proj-abcdefghijklmnopqrstuvwx") // "calling with [REDACTED]"
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces every secret found
+const Placeholder = "[REDACTED]"
+
+// minSecretLength is the shortest value taken as a secret; shorter ones, like
+// "true" in a *_TOKEN variable, would scrub ordinary words
+const minSecretLength = 8
+
+// maxDepth bounds how deep Value walks, in case of cycles
+const maxDepth = 32
+
+// builtinPatterns match well-known credential formats
+var builtinPatterns = []string{
+	`sk-[A-Za-z0-9_-]{20,}`,                  // OpenAI and Anthropic (sk-ant-...) API keys
+	`(?i)bearer [A-Za-z0-9._~+/=-]{16,}`,     // Authorization headers
+	`xox[abposr]-[A-Za-z0-9-]{10,}`,          // Slack tokens
+	`https://hooks\.slack\.com/services/\S+`, // Slack incoming webhooks
+	`https://(?:ptb\.|canary\.)?discord(?:app)?\.com/api/webhooks/\S+`,
+	`AKIA[0-9A-Z]{16}`, // AWS access key IDs
+}
+
+// secretEnvSuffixes mark environment variables holding secrets
+var secretEnvSuffixes = []string{"_KEY", "_TOKEN", "_SECRET", "_PASSWORD"}
+
+// secrets are what every caller in the process scrubs
+var secrets struct {
+	mu       sync.RWMutex
+	patterns []string
+	values   map[string]bool
+	combined *regexp.Regexp // Every pattern and value, matched in one pass
+}
+
+func init() {
+	secrets.patterns = append(secrets.patterns, builtinPatterns...)
+	secrets.values = make(map[string]bool)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if isSecretEnv(name) {
+			addValue(value)
+		}
+	}
+	rebuild()
+}
+
+// isSecretEnv reports whether the environment variable name looks like it
+// holds a secret
+func isSecretEnv(name string) bool {
+	name = strings.ToUpper(name)
+	if strings.Contains(name, "WEBHOOK") {
+		return true
+	}
+	for _, suffix := range secretEnvSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// addValue notes a literal secret; secrets.mu must be held or not yet shared
+func addValue(value string) {
+	if value = strings.TrimSpace(value); len(value) >= minSecretLength {
+		secrets.values[value] = true
+	}
+}
+
+// rebuild compiles the combined expression; secrets.mu must be held. Longer
+// values come first, so one containing another is scrubbed whole.
+func rebuild() {
+	values := make([]string, 0, len(secrets.values))
+	for value := range secrets.values {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	alternatives := make([]string, 0, len(values)+len(secrets.patterns))
+	for _, value := range values {
+		alternatives = append(alternatives, regexp.QuoteMeta(value))
+	}
+	for _, pattern := range secrets.patterns {
+		alternatives = append(alternatives, "(?:"+pattern+")")
+	}
+	secrets.combined = regexp.MustCompile(strings.Join(alternatives, "|"))
+}
+
+// AddPatterns scrubs text matching any of the regular expressions from now
+// on. Nothing is added if one of them doesn't compile.
+func AddPatterns(patterns ...string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("redaction pattern %q: %w", pattern, err)
+		}
+	}
+
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	secrets.patterns = append(secrets.patterns, patterns...)
+	rebuild()
+	return nil
+}
+
+// AddSecrets scrubs the given values from now on, e.g. keys read from a
+// config file. Values shorter than eight characters are ignored.
+func AddSecrets(values ...string) {
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	for _, value := range values {
+		addValue(value)
+	}
+	rebuild()
+}
+
+// AddEnv scrubs the values of the named environment variables from now on,
+// for secrets whose names don't give them away
+func AddEnv(names ...string) {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, os.Getenv(name))
+	}
+	AddSecrets(values...)
+}
+
+// current returns the combined expression
+func current() *regexp.Regexp {
+	secrets.mu.RLock()
+	defer secrets.mu.RUnlock()
+	return secrets.combined
+}
+
+// String returns s with every secret replaced by Placeholder
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	return current().ReplaceAllString(s, Placeholder)
+}
+
+// redactedError is an error whose message had secrets in it. Unwrap keeps
+// errors.Is and errors.As working on the original.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Error returns err with secrets scrubbed from its message, or err itself if
+// it has none
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if scrubbed := String(msg); scrubbed != msg {
+		return &redactedError{msg: scrubbed, err: err}
+	}
+	return err
+}
+
+// errorType is the reflect.Type of the error interface
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// rawMessageType is the reflect.Type of json.RawMessage, the only bytes
+// scrubbed, as text
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// Value returns v with secrets scrubbed from every string it holds: in
+// exported struct fields, map values, slices, arrays, pointers, and errors.
+// Byte slices other than json.RawMessage, such as artifact content, are left
+// alone, since they may be binary. v itself is never modified; the parts holding a secret are copied, and if
+// there are none v is returned as is.
+func Value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return String(v)
+	case error:
+		return Error(v)
+	}
+	re := current()
+	if scrubbed, changed := scrub(re, reflect.ValueOf(v), 0); changed {
+		return scrubbed.Interface()
+	}
+	return v
+}
+
+// scrub returns a copy of v with secrets replaced and true, or v and false
+// when it holds none
+func scrub(re *regexp.Regexp, v reflect.Value, depth int) (reflect.Value, bool) {
+	if depth > maxDepth {
+		return v, false
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		scrubbed := re.ReplaceAllString(s, Placeholder)
+		if scrubbed == s {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(scrubbed)
+		return out, true
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		elem := v.Elem()
+		var out reflect.Value
+		if err, ok := elem.Interface().(error); ok && v.Type().Implements(errorType) {
+			scrubbed := Error(err)
+			if scrubbed == err {
+				return v, false
+			}
+			out = reflect.ValueOf(scrubbed)
+		} else {
+			var changed bool
+			if out, changed = scrub(re, elem, depth+1); !changed {
+				return v, false
+			}
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(out)
+		return copied, true
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, false
+		}
+		out, changed := scrub(re, v.Elem(), depth+1)
+		if !changed {
+			return v, false
+		}
+		copied := reflect.New(v.Elem().Type())
+		copied.Elem().Set(out)
+		return copied, true
+
+	case reflect.Struct:
+		var copied reflect.Value
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			out, changed := scrub(re, v.Field(i), depth+1)
+			if !changed {
+				continue
+			}
+			if !copied.IsValid() {
+				copied = reflect.New(t).Elem()
+				copied.Set(v)
+			}
+			copied.Field(i).Set(out)
+		}
+		if !copied.IsValid() {
+			return v, false
+		}
+		return copied, true
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, false
+		}
+		var copied reflect.Value
+		iter := v.MapRange()
+		for iter.Next() {
+			out, changed := scrub(re, iter.Value(), depth+1)
+			if !changed {
+				continue
+			}
+			if !copied.IsValid() {
+				copied = reflect.MakeMapWithSize(v.Type(), v.Len())
+				copyIter := v.MapRange()
+				for copyIter.Next() {
+					copied.SetMapIndex(copyIter.Key(), copyIter.Value())
+				}
+			}
+			copied.SetMapIndex(iter.Key(), out)
+		}
+		if !copied.IsValid() {
+			return v, false
+		}
+		return copied, true
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, false
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Type() != rawMessageType {
+				return v, false
+			}
+			s := string(v.Bytes())
+			scrubbed := re.ReplaceAllString(s, Placeholder)
+			if scrubbed == s {
+				return v, false
+			}
+			return reflect.ValueOf([]byte(scrubbed)).Convert(v.Type()), true
+		}
+		var copied reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			out, changed := scrub(re, v.Index(i), depth+1)
+			if !changed {
+				continue
+			}
+			if !copied.IsValid() {
+				copied = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+				reflect.Copy(copied, v)
+			}
+			copied.Index(i).Set(out)
+		}
+		if !copied.IsValid() {
+			return v, false
+		}
+		return copied, true
+
+	case reflect.Array:
+		var copied reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			out, changed := scrub(re, v.Index(i), depth+1)
+			if !changed {
+				continue
+			}
+			if !copied.IsValid() {
+				copied = reflect.New(v.Type()).Elem()
+				copied.Set(v)
+			}
+			copied.Index(i).Set(out)
+		}
+		if !copied.IsValid() {
+			return v, false
+		}
+		return copied, true
+	}
+	return v, false
+}
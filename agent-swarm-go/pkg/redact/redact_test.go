@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testKey = "sk-proj-abcdefghijklmnopqrstuvwx"
+
+func TestStringScrubsBuiltinPatterns(t *testing.T) {
+	got := String("calling with " + testKey)
+	if got != "calling with "+Placeholder {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func TestAddSecretsIgnoresShortValues(t *testing.T) {
+	AddSecrets("true", "hunter2-long-secret")
+	if got := String("true hunter2-long-secret"); got != "true "+Placeholder {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func TestValueScrubsNestedStringsWithoutModifyingInput(t *testing.T) {
+	type payload struct {
+		Note  string
+		Tags  []string
+		Extra map[string]interface{}
+	}
+	in := payload{Note: "key " + testKey, Tags: []string{testKey}, Extra: map[string]interface{}{"k": testKey}}
+
+	out := Value(in).(payload)
+	if strings.Contains(out.Note, testKey) || out.Tags[0] != Placeholder || out.Extra["k"] != Placeholder {
+		t.Errorf("Value left a secret: %+v", out)
+	}
+	if in.Tags[0] != testKey || in.Extra["k"] != testKey {
+		t.Error("Value modified its input")
+	}
+}
+
+func TestValueLeavesBytesButScrubsRawJSON(t *testing.T) {
+	type artifact struct {
+		Name string
+		Data []byte
+	}
+	in := artifact{Name: testKey, Data: []byte("\x89PNG " + testKey)}
+	out := Value(in).(artifact)
+	if out.Name != Placeholder {
+		t.Errorf("Name = %q; want it scrubbed", out.Name)
+	}
+	if string(out.Data) != string(in.Data) {
+		t.Errorf("Data = %q; want it untouched", out.Data)
+	}
+
+	raw := Value(json.RawMessage(`{"key":"` + testKey + `"}`)).(json.RawMessage)
+	if strings.Contains(string(raw), testKey) {
+		t.Errorf("RawMessage = %s; want it scrubbed", raw)
+	}
+}
+
+func TestErrorKeepsUnwrapping(t *testing.T) {
+	base := errors.New("auth failed for " + testKey)
+	err := Error(base)
+	if strings.Contains(err.Error(), testKey) {
+		t.Errorf("Error = %q", err)
+	}
+	if !errors.Is(err, base) {
+		t.Error("scrubbed error no longer wraps the original")
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package sandbox
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// limitedCommand runs the program directly. CPU and memory rlimits are not
+// available on this platform, so neither is enforced.
+func limitedCommand(ctx context.Context, limits Limits, name string, args []string) *exec.Cmd {
+	return exec.CommandContext(ctx, name, args...)
+}
+
+// killGroup kills cmd's process; there are no process groups to kill here
+func killGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// classify cannot attribute failures to CPU or memory limits on this platform
+func classify(limits Limits, state *os.ProcessState, stderr string) *LimitError {
+	return nil
+}
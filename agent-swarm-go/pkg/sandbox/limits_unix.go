@@ -0,0 +1,67 @@
+//go:build unix
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// limitedCommand starts the program through /bin/sh so the CPU and memory
+// rlimits are applied to the child only, then execs it in place (same PID)
+func limitedCommand(ctx context.Context, limits Limits, name string, args []string) *exec.Cmd {
+	var cmd *exec.Cmd
+	script := ""
+	if limits.CPUTime > 0 {
+		// ulimit -t has one-second granularity; round up so short limits still apply
+		script += fmt.Sprintf("ulimit -t %d; ", int64((limits.CPUTime+999_999_999)/1_000_000_000))
+	}
+	if limits.Memory > 0 {
+		script += fmt.Sprintf("ulimit -v %d; ", limits.Memory/1024)
+	}
+	if script == "" {
+		cmd = exec.CommandContext(ctx, name, args...)
+	} else {
+		shellArgs := append([]string{"-c", script + `exec "$0" "$@"`, name}, args...)
+		cmd = exec.CommandContext(ctx, "/bin/sh", shellArgs...)
+	}
+
+	// Kill the whole process group on timeout so grandchildren don't linger
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return killGroup(cmd)
+	}
+	return cmd
+}
+
+// killGroup kills the process group limitedCommand put cmd in
+func killGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// classify maps the way a process died, given its state and the end of its
+// stderr, onto the limit that killed it
+func classify(limits Limits, state *os.ProcessState, stderr string) *LimitError {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok {
+		return nil
+	}
+
+	if limits.CPUTime > 0 && status.Signaled() {
+		// The kernel sends SIGXCPU at the soft limit and SIGKILL at the hard one.
+		// Rusage accounting lags the kernel's check slightly, hence the slack.
+		cpu := state.UserTime() + state.SystemTime()
+		if sig := status.Signal(); sig == syscall.SIGXCPU || (sig == syscall.SIGKILL && cpu >= limits.CPUTime*9/10) {
+			return &LimitError{Limit: LimitCPU, Value: limits.CPUTime.String()}
+		}
+	}
+
+	if limits.Memory > 0 && (status.Signaled() || status.ExitStatus() != 0) && looksLikeOOM(stderr) {
+		return &LimitError{Limit: LimitMemory, Value: fmt.Sprintf("%d MiB", limits.Memory>>20)}
+	}
+
+	return nil
+}
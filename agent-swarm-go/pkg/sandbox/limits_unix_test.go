@@ -0,0 +1,73 @@
+//go:build unix
+
+package sandbox
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// runLimited runs a command under limits and returns the limit that ended it
+func runLimited(t *testing.T, limits Limits, name string, args ...string) *LimitError {
+	t.Helper()
+	var stderr bytes.Buffer
+	cmd := Command(limits, name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Wait()
+	return Exceeded(limits, cmd.ProcessState, stderr.String())
+}
+
+func TestCPULimitStopsBusyLoop(t *testing.T) {
+	limits := Limits{CPUTime: time.Second}
+	limit := runLimited(t, limits, "/bin/sh", "-c", "while :; do :; done")
+	if limit == nil || limit.Limit != LimitCPU {
+		t.Errorf("Exceeded = %v; want the CPU limit", limit)
+	}
+}
+
+func TestMemoryLimitStopsAllocation(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+	limits := Limits{Memory: 256 << 20}
+	limit := runLimited(t, limits, "python3", "-c", "b = bytearray(1 << 30)")
+	if limit == nil || limit.Limit != LimitMemory {
+		t.Errorf("Exceeded = %v; want the memory limit", limit)
+	}
+}
+
+func TestOrdinaryFailureIsNotALimit(t *testing.T) {
+	limits := Limits{CPUTime: time.Minute, Memory: 256 << 20}
+	if limit := runLimited(t, limits, "/bin/sh", "-c", "echo oops >&2; exit 1"); limit != nil {
+		t.Errorf("Exceeded = %v; want nil", limit)
+	}
+}
+
+func TestKillStopsChildren(t *testing.T) {
+	// Wait returns once stdout closes, which needs the sleep gone as well
+	var stdout bytes.Buffer
+	cmd := Command(Limits{}, "/bin/sh", "-c", "sleep 60 & wait")
+	cmd.Stdout = &stdout
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+
+	if err := Kill(cmd); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process or its child still running after Kill")
+	}
+}
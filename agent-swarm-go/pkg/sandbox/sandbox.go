@@ -0,0 +1,120 @@
+// Package sandbox runs external processes for external-process agents under
+// enforceable resource limits.
+//
+// A process gets:
+//   - a CPU time limit (RLIMIT_CPU)
+//   - a memory limit (RLIMIT_AS, the process's address space)
+//   - its own process group, so it is killed along with its children
+//   - an environment with none of the swarm's secrets (see Env)
+//
+// Exceeding a limit is reported as a *LimitError, which agents turn into a
+// types.Result with Failure set to types.FailureLimitExceeded, so violations
+// show up as their own failure class rather than as ordinary task errors.
+//
+// # Usage Example
+//
+//	cmd := sandbox.Command(limits, "python3", "agent.py")
+//	cmd.Env = sandbox.Env(dir)
+//	...
+//	err := cmd.Wait()
+//	if limit := sandbox.Exceeded(limits, cmd.ProcessState, stderrTail); limit != nil {
+//		err = limit
+//	}
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Limit names used in LimitError
+const (
+	LimitCPU    = "cpu"
+	LimitMemory = "memory"
+)
+
+// Limits bounds the resources a process may use. A zero field means no limit.
+type Limits struct {
+	CPUTime time.Duration // Total user+system CPU time
+	Memory  int64         // Address space in bytes
+}
+
+// LimitError reports that a process was stopped for exceeding one of its limits
+type LimitError struct {
+	Limit string // LimitCPU or LimitMemory
+	Value string // The configured limit, human readable
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit exceeded (%s)", e.Limit, e.Value)
+}
+
+// Command returns a command that runs name with args under limits' CPU and
+// memory limits, in a process group of its own. The caller gives the process
+// its environment (see Env) and temporary directory; Kill stops it with its
+// children, and Exceeded tells whether a limit ended it. Note that the CPU
+// limit covers the whole life of the process.
+func Command(limits Limits, name string, args ...string) *exec.Cmd {
+	return limitedCommand(context.Background(), limits, name, args)
+}
+
+// Kill kills a process started from Command, and any children it left
+func Kill(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return killGroup(cmd)
+}
+
+// Exceeded returns the limit that ended a process started from Command, given
+// its state once waited for and the end of what it wrote to stderr, or nil
+// when it wasn't a limit
+func Exceeded(limits Limits, state *os.ProcessState, stderr string) *LimitError {
+	if state == nil {
+		return nil
+	}
+	return classify(limits, state, stderr)
+}
+
+// envAllowed are the variables Env passes on from the swarm's environment
+var envAllowed = []string{"PATH", "LANG"}
+
+// Env returns the environment for a sandboxed process whose temporary
+// directory is dir: PATH and LANG from the swarm's environment, TMPDIR and
+// HOME set to dir, then extra KEY=value pairs, which take precedence. Nothing
+// else is passed on, so API keys and dashboard credentials stay out of reach.
+func Env(dir string, extra ...string) []string {
+	var env []string
+	for _, key := range envAllowed {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	env = append(env, "TMPDIR="+dir, "HOME="+dir)
+	return append(env, extra...)
+}
+
+// memoryErrorHints are stderr fragments printed by common runtimes when an
+// allocation fails under RLIMIT_AS
+var memoryErrorHints = []string{
+	"cannot allocate memory",
+	"out of memory",
+	"memoryerror",
+	"bad_alloc",
+	"failed to reserve",
+}
+
+// looksLikeOOM reports whether stderr suggests the process ran out of memory
+func looksLikeOOM(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	for _, hint := range memoryErrorHints {
+		if strings.Contains(stderr, hint) {
+			return true
+		}
+	}
+	return false
+}
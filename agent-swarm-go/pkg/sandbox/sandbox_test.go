@@ -0,0 +1,31 @@
+package sandbox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvPassesOnlyAllowedVariables(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+	t.Setenv("PATH", "/usr/bin")
+
+	env := Env("/tmp/task", "MODE=fast")
+	joined := strings.Join(env, "\n")
+	if strings.Contains(joined, "OPENAI_API_KEY") {
+		t.Errorf("Env = %v; want no API key", env)
+	}
+	for _, want := range []string{"PATH=/usr/bin", "TMPDIR=/tmp/task", "HOME=/tmp/task", "MODE=fast"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Env = %v; want %s", env, want)
+		}
+	}
+}
+
+func TestLooksLikeOOM(t *testing.T) {
+	if !looksLikeOOM("Traceback ...\nMemoryError") {
+		t.Error("Python's MemoryError not recognised")
+	}
+	if looksLikeOOM("ValueError: bad input") {
+		t.Error("ordinary error taken for running out of memory")
+	}
+}
@@ -0,0 +1,210 @@
+package scenarios
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/llm"
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/validate"
+)
+
+// ChaosConfig is how often a chaos test injects each kind of failure. Rates
+// are shares between 0 and 1.
+type ChaosConfig struct {
+	KillRate    float64       // Chance that the agent given a task dies on it
+	DelayRate   float64       // Share of LLM responses held back
+	MaxDelay    time.Duration // Longest a held-back response waits
+	CorruptRate float64       // Share of LLM responses garbled
+}
+
+// ChaosReport is how a chaos test went: the stress test's numbers, the
+// failures injected, and what the swarm did to recover
+type ChaosReport struct {
+	StressReport
+	Killed       []string // Agents killed, in order
+	Delayed      int      // LLM responses held back
+	Corrupted    int      // LLM responses garbled
+	Redelivered  int      // Deliveries of tasks whose agent went quiet to another agent
+	DeadLettered int      // Tasks given up on after too many deliveries
+	Invalid      int      // Tasks failed with output still garbled after the fix attempts
+}
+
+// String renders the report for the terminal
+func (r *ChaosReport) String() string {
+	var b strings.Builder
+	b.WriteString(r.StressReport.String())
+	killed := "none"
+	if len(r.Killed) > 0 {
+		killed = strings.Join(r.Killed, ", ")
+	}
+	fmt.Fprintf(&b, "  Injected: %d agent(s) killed (%s), %d LLM response(s) delayed, %d corrupted\n", len(r.Killed), killed, r.Delayed, r.Corrupted)
+	fmt.Fprintf(&b, "  Recovery: %d redelivery(ies), %d dead-lettered, %d with output never fixed\n", r.Redelivered, r.DeadLettered, r.Invalid)
+	return b.String()
+}
+
+// ChaosScenario creates taskCount tasks for a chaos test. Their output must
+// not be garbled (see llm.Corrupted), so a corrupted LLM response is caught
+// by output validation and the agent is asked to fix it, up to twice.
+func ChaosScenario(taskCount int) *Scenario {
+	scenario := StressTestScenario(taskCount)
+	scenario.Name = "Chaos Test"
+	scenario.Description = fmt.Sprintf("Process %d tasks while agents die and LLM responses arrive late or garbled", taskCount)
+
+	retries := 2
+	spec := validate.OutputSpec{Pattern: fmt.Sprintf(`^[^\x{%X}]*$`, llm.Corrupted), Retries: &retries}
+	for i := range scenario.Tasks {
+		scenario.Tasks[i].ID = fmt.Sprintf("chaos-%04d", i+1)
+		scenario.Tasks[i].Description = fmt.Sprintf("Chaos test task #%d", i+1)
+		scenario.Tasks[i].Payload.(map[string]interface{})[validate.PayloadKey] = spec
+	}
+	return scenario
+}
+
+// middlewareUser is implemented by agents that take task middleware (every
+// agent embedding agent.BaseAgent)
+type middlewareUser interface {
+	Use(middleware ...agent.TaskMiddleware)
+}
+
+// chaosRuns holds the chaos tests in progress by run ID; the middleware
+// installed on agents only acts on their tasks
+var chaosRuns sync.Map
+
+// chaosAgents records the agents that already have the chaos middleware
+var chaosAgents sync.Map
+
+// chaosRun is one chaos test in progress
+type chaosRun struct {
+	s    *swarm.Swarm
+	cfg  ChaosConfig
+	done chan struct{} // Closed when the test ends, releasing the hung tasks
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	killed []string
+	dead   map[string]bool
+}
+
+// RunChaosTest runs the scenario as a stress test (see RunStressTest) while
+// injecting failures at the configured rates, to check that the swarm's
+// retry and supervision recover from them:
+//
+//   - A killed agent is paused and its task hangs without a word, as a
+//     crashed agent's would. Only a visibility timeout (see
+//     swarm.SetVisibilityTimeout) gets the task redelivered to another agent.
+//     The last agent standing is never killed.
+//   - Delayed and corrupted LLM responses are injected with llm.SetFaults,
+//     into every LLM call in the process while the test runs.
+//
+// When the test ends, killed agents are resumed and their hung tasks fail.
+// Only agents embedding agent.BaseAgent can be killed.
+func RunChaosTest(ctx context.Context, s *swarm.Swarm, scenario *Scenario, cfg ChaosConfig) (*ChaosReport, error) {
+	runID := types.NewRunID()
+	run := &chaosRun{
+		s:    s,
+		cfg:  cfg,
+		done: make(chan struct{}),
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		dead: make(map[string]bool),
+	}
+	for _, id := range s.ListAgents() {
+		a, err := s.GetAgent(id)
+		if err != nil {
+			continue
+		}
+		if user, ok := a.(middlewareUser); ok {
+			if _, installed := chaosAgents.LoadOrStore(a, true); !installed {
+				user.Use(chaosMiddleware(id))
+			}
+		}
+	}
+
+	chaosRuns.Store(runID, run)
+	llm.SetFaults(llm.Faults{DelayRate: cfg.DelayRate, MaxDelay: cfg.MaxDelay, CorruptRate: cfg.CorruptRate})
+
+	report := &ChaosReport{}
+	stress, err := runStress(ctx, s, scenario, runID, func(event types.Event) {
+		if event.RunID != runID {
+			return
+		}
+		switch event.Type {
+		case types.EventTaskRedelivered:
+			report.Redelivered++
+		case types.EventTaskDeadLettered:
+			report.DeadLettered++
+		case types.EventTaskFailed:
+			if r, ok := event.Data.(types.Result); ok && r.Failure == types.FailureInvalidOutput {
+				report.Invalid++
+			}
+		}
+	})
+
+	faults := llm.InjectedFaults()
+	llm.SetFaults(llm.Faults{})
+	chaosRuns.Delete(runID)
+	close(run.done)
+
+	run.mu.Lock()
+	report.Killed = append(report.Killed, run.killed...)
+	run.mu.Unlock()
+	for _, id := range report.Killed {
+		s.ResumeAgent(id)
+	}
+
+	report.StressReport = *stress
+	report.Delayed, report.Corrupted = faults.Delayed, faults.Corrupted
+	return report, err
+}
+
+// chaosMiddleware kills the agent with ID agentID on the tasks of chaos runs,
+// at their kill rate
+func chaosMiddleware(agentID string) agent.TaskMiddleware {
+	return func(next agent.TaskFunc) agent.TaskFunc {
+		return func(task types.Task) types.Result {
+			value, ok := chaosRuns.Load(task.RunID)
+			if !ok {
+				return next(task)
+			}
+			run := value.(*chaosRun)
+			if !run.kill(agentID) {
+				return next(task)
+			}
+
+			// Dead agents don't report anything until the test ends
+			<-run.done
+			return types.Result{
+				TaskID:  task.ID,
+				Success: false,
+				Data:    fmt.Sprintf("Agent %s was killed by a chaos test", agentID),
+				Failure: types.FailureCancelled,
+			}
+		}
+	}
+}
+
+// kill decides whether agentID dies on the task it is starting, and pauses
+// it if so. An agent that is already dead stays dead.
+func (r *chaosRun) kill(agentID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dead[agentID] {
+		return true
+	}
+	if r.rand.Float64() >= r.cfg.KillRate || len(r.dead)+1 >= len(r.s.ListAgents()) {
+		return false
+	}
+	if err := r.s.PauseAgent(agentID); err != nil {
+		return false
+	}
+	r.dead[agentID] = true
+	r.killed = append(r.killed, agentID)
+	return true
+}
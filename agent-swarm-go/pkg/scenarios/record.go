@@ -0,0 +1,209 @@
+package scenarios
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultDir is where recorded scenarios are saved and looked for
+const DefaultDir = "scenarios"
+
+// Recorder collects submitted tasks into a scenario that can be saved with
+// SaveScenario and replayed with ExecuteScenario, e.g. to repeat a demo or a
+// regression run. Pass its Record method to swarm.ObserveSubmissions.
+type Recorder struct {
+	mu    sync.Mutex
+	tasks []types.Task
+	lanes map[types.MessagePriority]bool
+}
+
+// NewRecorder returns an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{lanes: make(map[types.MessagePriority]bool)}
+}
+
+// Record adds a task submitted on the given lane. What only made sense for
+// the original submission (its run, callback URL, idempotency key, and
+// source) is left out, so a replay runs afresh.
+func (r *Recorder) Record(task types.Task, priority types.MessagePriority) {
+	task.RunID = ""
+	task.CallbackURL = ""
+	task.IdempotencyKey = ""
+	task.Source = ""
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks = append(r.tasks, task)
+	r.lanes[priority] = true
+}
+
+// Len returns how many tasks have been recorded
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.tasks)
+}
+
+// Scenario returns the recorded tasks as a scenario, in submission order. It
+// uses the lane the tasks were submitted on when they all shared one.
+func (r *Recorder) Scenario(name, description string) *Scenario {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scenario := CustomScenario(name, description, append([]types.Task(nil), r.tasks...))
+	if len(r.lanes) == 1 {
+		for lane := range r.lanes {
+			scenario.Priority = lane
+		}
+	}
+	return scenario
+}
+
+// Replay returns a copy of the scenario whose task IDs, and the
+// dependencies on them, end in suffix, so a replay's results aren't mistaken
+// for an earlier run's (see swarm.AwaitResult)
+func (sc *Scenario) Replay(suffix string) *Scenario {
+	ids := make(map[string]bool, len(sc.Tasks))
+	for _, task := range sc.Tasks {
+		ids[task.ID] = true
+	}
+
+	replay := *sc
+	replay.Tasks = make([]types.Task, len(sc.Tasks))
+	for i, task := range sc.Tasks {
+		task.ID += suffix
+		if task.Dependencies != nil {
+			deps := make([]string, len(task.Dependencies))
+			for j, dep := range task.Dependencies {
+				if ids[dep] {
+					dep += suffix
+				}
+				deps[j] = dep
+			}
+			task.Dependencies = deps
+		}
+		replay.Tasks[i] = task
+	}
+	return &replay
+}
+
+// scenarioFile is the JSON form of a scenario. Tasks use the field names of
+// POST /api/tasks.
+type scenarioFile struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Lane        string     `json:"lane,omitempty"` // Inbox lane, e.g. "low"; default lets the agents decide
+	Tasks       []taskFile `json:"tasks"`
+}
+
+type taskFile struct {
+	ID           string                 `json:"id"`
+	Description  string                 `json:"description"`
+	Payload      interface{}            `json:"payload,omitempty"`
+	Priority     int                    `json:"priority,omitempty"`
+	Context      map[string]interface{} `json:"context,omitempty"`
+	Dependencies []string               `json:"dependencies,omitempty"`
+	TimeoutSecs  float64                `json:"timeout_seconds,omitempty"`
+	Requires     []string               `json:"requires,omitempty"`
+	Locale       string                 `json:"locale,omitempty"`
+}
+
+// SaveScenario writes a scenario to path as JSON, creating its directory if
+// needed
+func SaveScenario(path string, scenario *Scenario) error {
+	file := scenarioFile{
+		Name:        scenario.Name,
+		Description: scenario.Description,
+		Tasks:       make([]taskFile, len(scenario.Tasks)),
+	}
+	if scenario.Priority != types.PriorityDefault {
+		file.Lane = scenario.Priority.String()
+	}
+	for i, task := range scenario.Tasks {
+		file.Tasks[i] = taskFile{
+			ID:           task.ID,
+			Description:  task.Description,
+			Payload:      task.Payload,
+			Priority:     task.Priority,
+			Context:      task.Context,
+			Dependencies: task.Dependencies,
+			TimeoutSecs:  task.Timeout.Seconds(),
+			Requires:     task.Requires,
+			Locale:       task.Locale,
+		}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding scenario %s: %w", scenario.Name, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadScenario reads a scenario saved by SaveScenario
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file scenarioFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(file.Tasks) == 0 {
+		return nil, fmt.Errorf("%s: scenario has no tasks", path)
+	}
+	lane, err := parseLane(file.Lane)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	scenario := &Scenario{
+		Name:        file.Name,
+		Description: file.Description,
+		Tasks:       make([]types.Task, len(file.Tasks)),
+		Priority:    lane,
+	}
+	if scenario.Name == "" {
+		scenario.Name = filepath.Base(path)
+	}
+	for i, task := range file.Tasks {
+		if task.ID == "" || task.Description == "" {
+			return nil, fmt.Errorf("%s: task %d needs an id and a description", path, i+1)
+		}
+		scenario.Tasks[i] = types.Task{
+			ID:           task.ID,
+			Description:  task.Description,
+			Payload:      task.Payload,
+			Priority:     task.Priority,
+			Context:      task.Context,
+			Dependencies: task.Dependencies,
+			Timeout:      time.Duration(task.TimeoutSecs * float64(time.Second)),
+			Requires:     task.Requires,
+			Locale:       task.Locale,
+		}
+	}
+	return scenario, nil
+}
+
+// parseLane returns the inbox lane with the given name; "" is the default lane
+func parseLane(name string) (types.MessagePriority, error) {
+	if name == "" {
+		return types.PriorityDefault, nil
+	}
+	for lane := types.PriorityDefault; lane <= types.PriorityUrgent; lane++ {
+		if lane.String() == name {
+			return lane, nil
+		}
+	}
+	return types.PriorityDefault, fmt.Errorf("unknown lane %q", name)
+}
@@ -1,11 +1,13 @@
 package scenarios
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"agent-swarm-go/pkg/swarm"
 	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/workflows"
 )
 
 // Scenario represents a pre-configured workflow scenario
@@ -13,6 +15,7 @@ type Scenario struct {
 	Name        string
 	Description string
 	Tasks       []types.Task
+	Priority    types.MessagePriority // Inbox lane used when distributing the tasks
 }
 
 // ResearchAnalysisScenario creates a research and analysis workflow
@@ -25,20 +28,20 @@ func ResearchAnalysisScenario(topic string) *Scenario {
 				ID:          "research-001",
 				Description: fmt.Sprintf("Research the topic: %s. Provide comprehensive findings with key insights, trends, and supporting evidence.", topic),
 				Priority:    1,
-				Payload:     map[string]interface{}{"type": "research", "topic": topic, "agent_type": "researcher"},
+				Payload:     map[string]interface{}{"type": "research", "topic": topic, "agent_type": "research"},
 			},
 			{
 				ID:          "analyze-001",
 				Description: fmt.Sprintf("Analyze the research findings on %s. Identify patterns, correlations, and generate actionable insights.", topic),
 				Priority:    2,
-				Payload:     map[string]interface{}{"type": "analysis", "topic": topic, "agent_type": "analyzer"},
+				Payload:     map[string]interface{}{"type": "analysis", "topic": topic, "agent_type": "analysis"},
 				Dependencies: []string{"research-001"},
 			},
 			{
 				ID:          "report-001",
 				Description: fmt.Sprintf("Generate a comprehensive executive report on %s based on research and analysis.", topic),
 				Priority:    3,
-				Payload:     map[string]interface{}{"type": "report", "topic": topic, "agent_type": "reporter"},
+				Payload:     map[string]interface{}{"type": "report", "topic": topic, "agent_type": "reporting"},
 				Dependencies: []string{"research-001", "analyze-001"},
 			},
 		},
@@ -81,9 +84,31 @@ func StressTestScenario(taskCount int) *Scenario {
 		Name:        "Stress Test",
 		Description: fmt.Sprintf("Process %d tasks to test swarm capacity", taskCount),
 		Tasks:       tasks,
+		Priority:    types.PriorityLow, // Bulk work must not delay control messages or urgent tasks
 	}
 }
 
+// TemplateScenario turns a built-in workflow template (see
+// workflows.Templates), e.g. "swot", into a scenario with one task per step.
+// params fill the template's inputs; omitted ones take their defaults. Unlike
+// a run of the template by a workflows.Engine, the tasks don't see each
+// other's output.
+func TemplateScenario(id string, params map[string]interface{}) (*Scenario, error) {
+	def, err := workflows.LoadTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make(map[string]string, len(params))
+	for name, value := range params {
+		inputs[name] = fmt.Sprint(value)
+	}
+	tasks, err := def.Tasks(inputs)
+	if err != nil {
+		return nil, err
+	}
+	return &Scenario{Name: def.Name, Description: def.Description, Tasks: tasks}, nil
+}
+
 // CustomScenario creates a custom scenario from user input
 func CustomScenario(name, description string, tasks []types.Task) *Scenario {
 	return &Scenario{
@@ -95,6 +120,12 @@ func CustomScenario(name, description string, tasks []types.Task) *Scenario {
 
 // ExecuteScenario runs a scenario through the swarm
 func ExecuteScenario(s *swarm.Swarm, scenario *Scenario, progressCallback func(int, int)) error {
+	return executeScenario(context.Background(), s, scenario, progressCallback, nil)
+}
+
+// executeScenario distributes the scenario's tasks until ctx is done, calling
+// beforeSubmit (if set) with each task just before it is submitted
+func executeScenario(ctx context.Context, s *swarm.Swarm, scenario *Scenario, progressCallback func(int, int), beforeSubmit func(types.Task)) error {
 	fmt.Printf("\n🚀 Executing Scenario: %s\n", scenario.Name)
 	fmt.Printf("   %s\n", scenario.Description)
 	fmt.Printf("   Total tasks: %d\n\n", len(scenario.Tasks))
@@ -103,11 +134,18 @@ func ExecuteScenario(s *swarm.Swarm, scenario *Scenario, progressCallback func(i
 	total := len(scenario.Tasks)
 
 	for i, task := range scenario.Tasks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		fmt.Printf("[%s] Distributing task: %s\n",
 			time.Now().Format("15:04:05"),
 			task.Description)
 
-		if err := s.DistributeTask(task); err != nil {
+		if beforeSubmit != nil {
+			beforeSubmit(task)
+		}
+		// Submit waits for room rather than failing when every inbox is full
+		if err := s.SubmitWithPriority(ctx, task, scenario.Priority); err != nil {
 			return fmt.Errorf("failed to distribute task %s: %w", task.ID, err)
 		}
 
@@ -129,8 +167,15 @@ func ExecuteScenario(s *swarm.Swarm, scenario *Scenario, progressCallback func(i
 	return nil
 }
 
-// GetScenarioTemplate returns a pre-defined scenario by name
+// GetScenarioTemplate returns a pre-defined scenario by name: "research",
+// "parallel", "stress", "chaos", or a built-in workflow template ("swot",
+// "competitor_comparison", "literature_review", "product_brief"; see
+// TemplateScenario)
 func GetScenarioTemplate(name string, params map[string]interface{}) *Scenario {
+	if scenario, err := TemplateScenario(name, params); err == nil {
+		return scenario
+	}
+
 	switch name {
 	case "research":
 		topic := "artificial intelligence"
@@ -153,6 +198,13 @@ func GetScenarioTemplate(name string, params map[string]interface{}) *Scenario {
 		}
 		return StressTestScenario(count)
 
+	case "chaos":
+		count := 20
+		if c, ok := params["count"].(int); ok {
+			count = c
+		}
+		return ChaosScenario(count)
+
 	default:
 		return &Scenario{
 			Name:        "Default Scenario",
@@ -0,0 +1,207 @@
+package scenarios
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+)
+
+// StressWait is how long a stress test keeps waiting for results once its
+// last task has been submitted; tasks without a result by then count as
+// unfinished
+const StressWait = 2 * time.Minute
+
+// StressReport is how a stress test went, measured from the swarm's task
+// events
+type StressReport struct {
+	RunID      string        // Stamped on every task of the test
+	Total      int           // Tasks in the test
+	Succeeded  int           // Tasks that completed successfully
+	Failed     int           // Tasks that failed or could not be submitted
+	Unfinished int           // Tasks with no result before the test stopped waiting
+	Duration   time.Duration // From the first submission to the last result
+	Throughput float64       // Succeeded tasks per second over Duration
+	P50        time.Duration // Latency percentiles, submission to result, over succeeded tasks
+	P95        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+
+	// Latency split into time waiting for an agent (in the queue and the
+	// agent's inbox) and time the agent spent on the task, as reported in
+	// the results' metadata, over succeeded tasks
+	WaitP50       time.Duration
+	WaitP95       time.Duration
+	ProcessingP50 time.Duration
+	ProcessingP95 time.Duration
+}
+
+// String renders the report for the terminal
+func (r *StressReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  Tasks: %d (%d succeeded, %d failed, %d unfinished)\n", r.Total, r.Succeeded, r.Failed, r.Unfinished)
+	fmt.Fprintf(&b, "  Time: %v\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "  Throughput: %.2f tasks/sec\n", r.Throughput)
+	if r.Succeeded > 0 {
+		fmt.Fprintf(&b, "  Latency: p50 %v, p95 %v, p99 %v, max %v\n",
+			r.P50.Round(time.Microsecond), r.P95.Round(time.Microsecond), r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+		fmt.Fprintf(&b, "  Queue wait: p50 %v, p95 %v\n", r.WaitP50.Round(time.Microsecond), r.WaitP95.Round(time.Microsecond))
+		fmt.Fprintf(&b, "  Processing: p50 %v, p95 %v\n", r.ProcessingP50.Round(time.Microsecond), r.ProcessingP95.Round(time.Microsecond))
+	}
+	return b.String()
+}
+
+// RunStressTest distributes the scenario's tasks as one run and waits for
+// their results, up to StressWait after the last submission or until ctx is
+// done. Each task's latency runs from just before it is submitted to its
+// completion or failure event; its queue wait and processing time come from
+// its result.
+func RunStressTest(ctx context.Context, s *swarm.Swarm, scenario *Scenario) (*StressReport, error) {
+	return runStress(ctx, s, scenario, types.NewRunID(), nil)
+}
+
+// runStress is RunStressTest for a run ID chosen by the caller, calling
+// observe (if set) with every event seen while it waits
+func runStress(ctx context.Context, s *swarm.Swarm, scenario *Scenario, runID string, observe func(types.Event)) (*StressReport, error) {
+	report := &StressReport{RunID: runID, Total: len(scenario.Tasks)}
+	for i := range scenario.Tasks {
+		scenario.Tasks[i].RunID = report.RunID
+	}
+
+	var (
+		mu        sync.Mutex
+		submitted = make(map[string]time.Time, len(scenario.Tasks))
+		finished  = make(map[string]bool, len(scenario.Tasks))
+		latencies []time.Duration
+		waits     []time.Duration
+		processed []time.Duration
+		start     time.Time
+		last      time.Time
+	)
+
+	// Subscribe before distributing so no result can slip past
+	events := s.GetEventBus().SubscribeWithBuffer(len(scenario.Tasks) + 100)
+	defer s.GetEventBus().Unsubscribe(events)
+
+	distributed := make(chan error, 1)
+	go func() {
+		distributed <- executeScenario(ctx, s, scenario, nil, func(task types.Task) {
+			mu.Lock()
+			defer mu.Unlock()
+			submitted[task.ID] = time.Now()
+			if start.IsZero() {
+				start = submitted[task.ID]
+			}
+		})
+	}()
+
+	// record notes a task's result and reports whether every submitted task
+	// has one
+	record := func(event types.Event) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		at, ok := submitted[event.TaskID]
+		if ok && !finished[event.TaskID] && event.RunID == report.RunID &&
+			(event.Type == types.EventTaskCompleted || event.Type == types.EventTaskFailed) {
+			finished[event.TaskID] = true
+			last = time.Now()
+
+			success := event.Type == types.EventTaskCompleted
+			result, hasResult := event.Data.(types.Result)
+			if hasResult && !result.Success {
+				success = false
+			}
+			if success {
+				report.Succeeded++
+				latencies = append(latencies, last.Sub(at))
+				if hasResult {
+					waits = append(waits, result.Metadata.QueueWait())
+					processed = append(processed, result.Metadata.Duration())
+				}
+			} else {
+				report.Failed++
+			}
+		}
+		return len(finished) == len(submitted)
+	}
+
+	var (
+		distErr  error
+		deadline <-chan time.Time
+		pending  = distributed
+	)
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			distErr = ctx.Err()
+			break wait
+		case distErr = <-pending:
+			pending = nil
+			timer := time.NewTimer(StressWait)
+			defer timer.Stop()
+			deadline = timer.C
+			if record(types.Event{}) { // Every result may already be in
+				break wait
+			}
+		case <-deadline:
+			break wait
+		case event := <-events:
+			if observe != nil {
+				observe(event)
+			}
+			if record(event) && pending == nil {
+				break wait
+			}
+		}
+	}
+	if pending != nil {
+		<-pending // ctx is done, so distribution stops at the next task
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Tasks never submitted (distribution stopped early) count as failed
+	report.Failed += report.Total - len(submitted)
+	report.Unfinished = len(submitted) - len(finished)
+	if !last.IsZero() {
+		report.Duration = last.Sub(start)
+	}
+	if report.Duration > 0 {
+		report.Throughput = float64(report.Succeeded) / report.Duration.Seconds()
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50 = percentile(latencies, 50)
+	report.P95 = percentile(latencies, 95)
+	report.P99 = percentile(latencies, 99)
+	if len(latencies) > 0 {
+		report.Max = latencies[len(latencies)-1]
+	}
+
+	sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+	sort.Slice(processed, func(i, j int) bool { return processed[i] < processed[j] })
+	report.WaitP50, report.WaitP95 = percentile(waits, 50), percentile(waits, 95)
+	report.ProcessingP50, report.ProcessingP95 = percentile(processed, 50), percentile(processed, 95)
+	return report, distErr
+}
+
+// percentile returns the nearest-rank pth percentile of sorted, or 0 when it
+// is empty
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
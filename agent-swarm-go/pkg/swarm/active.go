@@ -0,0 +1,136 @@
+package swarm
+
+import (
+	"sort"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// StepPending is the status of a workflow step whose task hasn't started
+const StepPending = "pending"
+
+// ActiveWorkflow is a workflow run that has started and not yet finished,
+// whoever started it: the CLI, the dashboard, a schedule, or a resume
+type ActiveWorkflow struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	RunID   string         `json:"run_id,omitempty"`
+	Started time.Time      `json:"started"`
+	Steps   []StepProgress `json:"steps"`
+}
+
+// StepProgress is one step of an ActiveWorkflow
+type StepProgress struct {
+	types.WorkflowStep
+	Status string `json:"status"` // StepPending, TaskRunning, TaskCompleted, or TaskFailed
+}
+
+// Done returns how many of the workflow's steps have finished
+func (w ActiveWorkflow) Done() int {
+	done := 0
+	for _, step := range w.Steps {
+		if step.Status == TaskCompleted || step.Status == TaskFailed {
+			done++
+		}
+	}
+	return done
+}
+
+// StepOf returns the step run by the task with taskID, if it is one of the
+// workflow's
+func (w ActiveWorkflow) StepOf(taskID string) (StepProgress, bool) {
+	for _, step := range w.Steps {
+		if step.TaskID == taskID {
+			return step, true
+		}
+	}
+	return StepProgress{}, false
+}
+
+// trackWorkflow follows workflow runs from their plan, announced by
+// EventWorkflowStarted, to EventWorkflowDone or EventWorkflowFailed, and
+// their steps' tasks in between. It observes the event bus, so no step is
+// missed.
+func (s *Swarm) trackWorkflow(event types.Event) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	switch event.Type {
+	case types.EventWorkflowStarted:
+		plan, ok := event.Data.(types.WorkflowPlan)
+		if !ok {
+			return
+		}
+		run := &ActiveWorkflow{ID: plan.WorkflowID, Name: plan.Name, RunID: event.RunID, Started: event.Timestamp}
+		for _, step := range plan.Steps {
+			run.Steps = append(run.Steps, StepProgress{WorkflowStep: step, Status: StepPending})
+			s.stepRuns[step.TaskID] = plan.WorkflowID
+		}
+		s.active[plan.WorkflowID] = run
+		return
+	case types.EventWorkflowDone, types.EventWorkflowFailed:
+		if run, ok := s.active[event.TaskID]; ok {
+			for _, step := range run.Steps {
+				delete(s.stepRuns, step.TaskID)
+			}
+			delete(s.active, event.TaskID)
+		}
+		return
+	}
+
+	var status string
+	switch event.Type {
+	case types.EventTaskStarted:
+		status = TaskRunning
+	case types.EventTaskCompleted:
+		status = TaskCompleted
+		if r, ok := event.Data.(types.Result); ok && !r.Success {
+			status = TaskFailed
+		}
+	case types.EventTaskFailed, types.EventTaskDeadLettered:
+		status = TaskFailed
+	default:
+		return
+	}
+	run, ok := s.active[s.stepRuns[event.TaskID]]
+	if !ok {
+		return
+	}
+	for i := range run.Steps {
+		if run.Steps[i].TaskID == event.TaskID {
+			run.Steps[i].Status = status
+		}
+	}
+}
+
+// ActiveWorkflows returns the workflow runs in progress, oldest first, with
+// the status of each step
+func (s *Swarm) ActiveWorkflows() []ActiveWorkflow {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	list := make([]ActiveWorkflow, 0, len(s.active))
+	for _, run := range s.active {
+		copied := *run
+		copied.Steps = append([]StepProgress(nil), run.Steps...)
+		list = append(list, copied)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.Before(list[j].Started) })
+	return list
+}
+
+// ActiveWorkflow returns the workflow run with the given ID, if it is in
+// progress
+func (s *Swarm) ActiveWorkflow(id string) (ActiveWorkflow, bool) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+
+	run, ok := s.active[id]
+	if !ok {
+		return ActiveWorkflow{}, false
+	}
+	copied := *run
+	copied.Steps = append([]StepProgress(nil), run.Steps...)
+	return copied, true
+}
@@ -0,0 +1,89 @@
+package swarm
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// pendingApproval is a workflow step waiting for a human decision
+type pendingApproval struct {
+	request  types.ApprovalRequest
+	decision chan types.ApprovalDecision
+}
+
+// RequestApproval registers a request for a human decision and announces it
+// with an approval_requested event. The returned channel receives the
+// decision passed to ResolveApproval; the caller is responsible for resolving
+// the request itself when its deadline passes.
+func (s *Swarm) RequestApproval(req types.ApprovalRequest) <-chan types.ApprovalDecision {
+	pending := &pendingApproval{
+		request:  req,
+		decision: make(chan types.ApprovalDecision, 1),
+	}
+
+	s.approvalsMu.Lock()
+	s.approvals[req.ID] = pending
+	s.approvalsMu.Unlock()
+
+	slog.Info("approval requested", "approval", req.ID, "step", req.Step)
+	s.eventBus.Publish(types.Event{
+		Type:      types.EventApprovalRequested,
+		Timestamp: time.Now(),
+		AgentID:   "approval",
+		TaskID:    req.ID,
+		Message:   fmt.Sprintf("✋ Waiting for approval: %s", req.Step),
+		Data:      req,
+		RunID:     req.RunID,
+	})
+	return pending.decision
+}
+
+// ResolveApproval delivers a decision for a pending request. Only the first
+// decision counts: later ones (e.g. the CLI after the dashboard) get an error.
+func (s *Swarm) ResolveApproval(decision types.ApprovalDecision) error {
+	s.approvalsMu.Lock()
+	pending, ok := s.approvals[decision.ID]
+	delete(s.approvals, decision.ID)
+	s.approvalsMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no pending approval %s", decision.ID)
+	}
+	pending.decision <- decision
+
+	verdict := "✅ Approved"
+	if !decision.Approved {
+		verdict = "🚫 Rejected"
+	}
+	slog.Info("approval resolved", "approval", decision.ID, "approved", decision.Approved, "by", decision.By)
+	s.eventBus.Publish(types.Event{
+		Type:      types.EventApprovalResolved,
+		Timestamp: time.Now(),
+		AgentID:   "approval",
+		TaskID:    decision.ID,
+		Message:   fmt.Sprintf("%s by %s: %s", verdict, decision.By, pending.request.Step),
+		Data:      decision,
+		RunID:     pending.request.RunID,
+	})
+	return nil
+}
+
+// PendingApprovals returns the requests still waiting for a decision, the
+// soonest deadline first
+func (s *Swarm) PendingApprovals() []types.ApprovalRequest {
+	s.approvalsMu.Lock()
+	defer s.approvalsMu.Unlock()
+
+	requests := make([]types.ApprovalRequest, 0, len(s.approvals))
+	for _, pending := range s.approvals {
+		requests = append(requests, pending.request)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Deadline.Before(requests[j].Deadline)
+	})
+	return requests
+}
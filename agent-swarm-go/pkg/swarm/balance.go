@@ -0,0 +1,116 @@
+package swarm
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// Strategy decides which of the agents able to take a task receives it
+type Strategy string
+
+const (
+	// StrategyLeastLoaded picks the agent with the smallest inbox depth (see
+	// agent.BaseAgent.InboxDepth), the first by ID on a tie. It is the default.
+	StrategyLeastLoaded Strategy = "least_loaded"
+
+	// StrategyRoundRobin takes turns through the agents, keeping a separate
+	// turn for each requested specialty
+	StrategyRoundRobin Strategy = "round_robin"
+
+	// StrategyRandom picks any of the agents at random
+	StrategyRandom Strategy = "random"
+
+	// StrategyFirstAvailable picks the first agent by ID, so work piles up
+	// on one agent until it stops taking tasks. Useful for tests and demos.
+	StrategyFirstAvailable Strategy = "first_available"
+)
+
+// Strategies lists the load-balancing strategies, the default first
+func Strategies() []Strategy {
+	return []Strategy{StrategyLeastLoaded, StrategyRoundRobin, StrategyRandom, StrategyFirstAvailable}
+}
+
+// ParseStrategy returns the strategy with the given name; "" is the default
+func ParseStrategy(name string) (Strategy, error) {
+	if name == "" {
+		return StrategyLeastLoaded, nil
+	}
+	names := make([]string, 0, len(Strategies()))
+	for _, strategy := range Strategies() {
+		if string(strategy) == name {
+			return strategy, nil
+		}
+		names = append(names, string(strategy))
+	}
+	return "", fmt.Errorf("unknown load-balancing strategy %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// Option configures a Swarm at construction
+type Option func(*Swarm)
+
+// WithStrategy sets how DistributeTask chooses between agents
+func WithStrategy(strategy Strategy) Option {
+	return func(s *Swarm) { s.strategy = strategy }
+}
+
+// loadReporter is implemented by agents that report how much work they have
+// (every agent embedding agent.BaseAgent)
+type loadReporter interface {
+	InboxDepth() int
+}
+
+// inboxDepth returns an agent's reported load, or 0 if it doesn't report one
+func inboxDepth(agent types.Agent) int {
+	if r, ok := agent.(loadReporter); ok {
+		return r.InboxDepth()
+	}
+	return 0
+}
+
+// Strategy returns how the swarm chooses between agents
+func (s *Swarm) Strategy() Strategy {
+	return s.strategy
+}
+
+// InboxDepths returns every agent's reported load, keyed by agent ID
+func (s *Swarm) InboxDepths() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	depths := make(map[string]int, len(s.agents))
+	for id, agent := range s.agents {
+		depths[id] = inboxDepth(agent)
+	}
+	return depths
+}
+
+// pick chooses one of candidates with the swarm's strategy. key separates
+// round-robin turns, so each specialty is rotated through on its own.
+func (s *Swarm) pick(candidates []types.Agent, key string) types.Agent {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].GetID() < candidates[j].GetID() })
+
+	switch s.strategy {
+	case StrategyRoundRobin:
+		s.balanceMu.Lock()
+		defer s.balanceMu.Unlock()
+		next := s.turns[key] % len(candidates)
+		s.turns[key] = next + 1
+		return candidates[next]
+	case StrategyRandom:
+		return candidates[rand.Intn(len(candidates))]
+	case StrategyFirstAvailable:
+		return candidates[0]
+	default:
+		best, bestDepth := candidates[0], inboxDepth(candidates[0])
+		for _, agent := range candidates[1:] {
+			if depth := inboxDepth(agent); depth < bestDepth {
+				best, bestDepth = agent, depth
+			}
+		}
+		return best
+	}
+}
@@ -0,0 +1,70 @@
+package swarm
+
+import (
+	"time"
+
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// TaskCallback is the JSON body POSTed to a task's CallbackURL when it
+// completes or fails
+type TaskCallback struct {
+	Event       string      `json:"event"` // "task_completed" or "task_failed"
+	TaskID      string      `json:"task_id"`
+	AgentID     string      `json:"agent_id"`
+	RunID       string      `json:"run_id,omitempty"`
+	Success     bool        `json:"success"`
+	Data        interface{} `json:"data"`
+	Error       string      `json:"error,omitempty"`
+	Failure     string      `json:"failure,omitempty"` // Failure class, e.g. "timeout"
+	CompletedAt time.Time   `json:"completed_at"`
+}
+
+// registerCallback remembers where to send a task's result
+func (s *Swarm) registerCallback(task types.Task) {
+	if task.CallbackURL == "" {
+		return
+	}
+	s.callbacksMu.Lock()
+	s.callbacks[task.ID] = task.CallbackURL
+	s.callbacksMu.Unlock()
+}
+
+// dropCallback forgets a task's callback, e.g. when it couldn't be dispatched
+func (s *Swarm) dropCallback(taskID string) {
+	s.callbacksMu.Lock()
+	delete(s.callbacks, taskID)
+	s.callbacksMu.Unlock()
+}
+
+// fireCallback delivers a finished task's result to its callback URL, if it
+// registered one. Each task's callback fires once.
+func (s *Swarm) fireCallback(event types.Event) {
+	s.callbacksMu.Lock()
+	target, ok := s.callbacks[event.TaskID]
+	delete(s.callbacks, event.TaskID)
+	s.callbacksMu.Unlock()
+	if !ok {
+		return
+	}
+
+	payload := TaskCallback{
+		Event:       string(event.Type),
+		TaskID:      event.TaskID,
+		AgentID:     event.AgentID,
+		RunID:       event.RunID,
+		Success:     event.Type == types.EventTaskCompleted,
+		Data:        event.Message,
+		CompletedAt: event.Timestamp,
+	}
+	if r, ok := event.Data.(types.Result); ok {
+		payload.Success = r.Success
+		payload.Data = r.Data
+		if r.Error != nil {
+			payload.Error = r.Error.Error()
+		}
+		payload.Failure = string(r.Failure)
+	}
+	webhook.Send(target, payload, "task "+event.TaskID)
+}
@@ -0,0 +1,82 @@
+package swarm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// ErrNoCapableAgent is returned when no agent in the swarm, busy or not, has
+// every capability a task requires
+var ErrNoCapableAgent = errors.New("no agent has the capabilities the task requires")
+
+// capabilitiesOf returns an agent's capability tags, or nil if it doesn't
+// declare any (see agent.WithCapabilities)
+func capabilitiesOf(agent types.Agent) []string {
+	if tagged, ok := agent.(interface{ GetCapabilities() []string }); ok {
+		return tagged.GetCapabilities()
+	}
+	return nil
+}
+
+// capable reports whether the agent has every tag in required
+func capable(agent types.Agent, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	have := make(map[string]bool)
+	for _, tag := range capabilitiesOf(agent) {
+		have[tag] = true
+	}
+	for _, tag := range required {
+		if !have[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// anyCapable reports whether some agent, in any state, could take a task
+// requiring these tags. Callers hold s.mu.
+func (s *Swarm) anyCapable(required []string) bool {
+	for _, agent := range s.agents {
+		if capable(agent, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities lists the distinct capability tags of the swarm's agents, sorted
+func (s *Swarm) Capabilities() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, agent := range s.agents {
+		for _, tag := range capabilitiesOf(agent) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Routable returns ErrNoCapableAgent if no agent, busy or not, has every
+// capability task requires, so a caller can refuse the task before queueing it
+func (s *Swarm) Routable(task types.Task) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.anyCapable(task.Requires) {
+		return fmt.Errorf("task %s requires %s: %w", task.ID, strings.Join(task.Requires, ", "), ErrNoCapableAgent)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package swarm
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// pausable is implemented by agents that can be paused (every agent embedding
+// agent.BaseAgent)
+type pausable interface {
+	Pause() error
+	Resume() error
+}
+
+// cancellable is implemented by agents that can cancel a task
+type cancellable interface {
+	CancelTask(taskID string) bool
+}
+
+// PauseAgent stops an agent from taking new tasks until ResumeAgent is
+// called. Tasks already queued for it wait in its inbox; new tasks are routed
+// to other agents.
+func (s *Swarm) PauseAgent(id string) error {
+	agent, err := s.GetAgent(id)
+	if err != nil {
+		return err
+	}
+	p, ok := agent.(pausable)
+	if !ok {
+		return fmt.Errorf("agent %s cannot be paused", id)
+	}
+	if err := p.Pause(); err != nil {
+		return err
+	}
+
+	slog.Info("agent paused", logging.KeyAgent, id)
+	s.publishControl(types.EventAgentPaused, id, fmt.Sprintf("⏸️  Agent %s paused", id))
+	return nil
+}
+
+// ResumeAgent lets a paused agent continue with its queued tasks
+func (s *Swarm) ResumeAgent(id string) error {
+	agent, err := s.GetAgent(id)
+	if err != nil {
+		return err
+	}
+	p, ok := agent.(pausable)
+	if !ok {
+		return fmt.Errorf("agent %s cannot be resumed", id)
+	}
+	if err := p.Resume(); err != nil {
+		return err
+	}
+
+	slog.Info("agent resumed", logging.KeyAgent, id)
+	s.publishControl(types.EventAgentResumed, id, fmt.Sprintf("▶️  Agent %s resumed", id))
+	return nil
+}
+
+// cancelExpiry is how long a cancelled queued task is watched for; a
+// cancellation still unmatched by then is forgotten
+const cancelExpiry = 10 * time.Minute
+
+// CancelTask cancels a task. A running task is interrupted by the agent
+// handling it, which reports the cancellation itself; a queued task is
+// dropped when it reaches the front of its agent's inbox, or of the swarm's
+// queue, and the swarm reports it as failed right away, so waiting workflows
+// are released. Only the agents the task was handed to are told, so a later
+// task reusing the ID isn't dropped by the others.
+func (s *Swarm) CancelTask(taskID string) error {
+	runID := s.runOf(taskID)
+	holder, pending := s.holderOf(taskID)
+
+	// A redelivered task may be with a second agent too
+	holders := []string{holder}
+	if redelivered := s.inflightAgent(taskID); redelivered != holder {
+		holders = append(holders, redelivered)
+	}
+
+	running, held := false, false
+	for _, agentID := range holders {
+		if agentID == "" {
+			continue
+		}
+		held = true
+		if s.cancelOn(agentID, taskID) {
+			running = true
+		}
+	}
+	if !held && pending {
+		// Still in the swarm's queue: runQueue drops it when it comes up
+		s.markCancelled(taskID)
+	}
+
+	// Stop visibility-timeout redelivery either way
+	s.completeInflight(taskID)
+	s.dropPending(taskID)
+	slog.Info("task cancelled", logging.KeyTask, taskID, "running", running)
+
+	if !running {
+		message := fmt.Sprintf("🛑 Task cancelled before it started: %s", taskID)
+		s.eventBus.Publish(types.Event{
+			Type:      types.EventTaskFailed,
+			Timestamp: time.Now(),
+			AgentID:   "swarm",
+			TaskID:    taskID,
+			Message:   message,
+			Data: types.Result{
+				TaskID:  taskID,
+				Success: false,
+				Data:    message,
+				Failure: types.FailureCancelled,
+				RunID:   runID,
+			},
+			RunID: runID,
+		})
+	}
+	return nil
+}
+
+// cancelOn cancels taskID on one agent and reports whether it was running
+// there
+func (s *Swarm) cancelOn(agentID, taskID string) bool {
+	s.mu.RLock()
+	agent, ok := s.agents[agentID]
+	s.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	c, ok := agent.(cancellable)
+	return ok && c.CancelTask(taskID)
+}
+
+// markCancelled remembers a task cancelled while it waited in the swarm's
+// queue, and forgets cancellations that have expired unmatched
+func (s *Swarm) markCancelled(taskID string) {
+	s.cancelledMu.Lock()
+	defer s.cancelledMu.Unlock()
+
+	now := time.Now()
+	for id, at := range s.cancelled {
+		if now.Sub(at) > cancelExpiry {
+			delete(s.cancelled, id)
+		}
+	}
+	s.cancelled[taskID] = now
+}
+
+// takeCancelled reports whether a task coming out of the swarm's queue was
+// cancelled there, and forgets the cancellation
+func (s *Swarm) takeCancelled(taskID string) bool {
+	s.cancelledMu.Lock()
+	defer s.cancelledMu.Unlock()
+
+	at, ok := s.cancelled[taskID]
+	if !ok {
+		return false
+	}
+	delete(s.cancelled, taskID)
+	return time.Since(at) <= cancelExpiry
+}
+
+// publishControl announces a pause/resume
+func (s *Swarm) publishControl(eventType types.EventType, agentID, message string) {
+	s.eventBus.Publish(types.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		AgentID:   agentID,
+		Message:   message,
+	})
+}
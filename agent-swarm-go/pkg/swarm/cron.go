@@ -0,0 +1,165 @@
+package swarm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed five-field cron expression:
+//
+//	┌ minute (0-59)
+//	│ ┌ hour (0-23)
+//	│ │ ┌ day of month (1-31)
+//	│ │ │ ┌ month (1-12)
+//	│ │ │ │ ┌ day of week (0-6, Sunday = 0 or 7)
+//	* * * * *
+//
+// Each field accepts *, a value, a range (1-5), a list (1,15) and a step
+// (*/15, 0-30/10). The shortcuts @hourly, @daily, @weekly and @monthly are
+// also accepted. As in standard cron, when both day fields are restricted a
+// time matches if either does.
+type CronSchedule struct {
+	expr                          string
+	minute, hour, dom, month, dow []bool
+	domAny, dowAny                bool
+}
+
+// cronShortcuts expands the @ forms
+var cronShortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseCron parses a cron expression
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if expanded, ok := cronShortcuts[strings.ToLower(expr)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	c := &CronSchedule{expr: expr}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron %q: minute: %w", expr, err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron %q: hour: %w", expr, err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron %q: day of month: %w", expr, err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron %q: month: %w", expr, err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron %q: day of week: %w", expr, err)
+	}
+	if c.dow[7] {
+		c.dow[0] = true // 7 is Sunday too
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+// parseCronField returns a lookup table of the values one field allows
+func parseCronField(field string, min, max int) ([]bool, error) {
+	allowed := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("bad step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("bad value %q", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("bad range %q", part)
+				}
+			} else if step > 1 {
+				hi = max // "5/15" means from 5 to the end in steps of 15
+			}
+		}
+		if lo < min || hi > max {
+			return nil, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		if lo > hi {
+			return nil, fmt.Errorf("range %q runs backwards", part)
+		}
+
+		for v := lo; v <= hi; v += step {
+			allowed[v] = true
+		}
+	}
+	return allowed, nil
+}
+
+// String returns the expression as written
+func (c *CronSchedule) String() string {
+	return c.expr
+}
+
+// Next returns the first time after t that matches the schedule, in t's
+// location, or the zero time if nothing matches within five years (e.g. "0 0 30 2 *").
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !c.month[t.Month()] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day-of-month / day-of-week rule
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom[t.Day()]
+	dow := c.dow[int(t.Weekday())]
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
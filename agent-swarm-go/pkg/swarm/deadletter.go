@@ -0,0 +1,167 @@
+package swarm
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// maxDeadLetters is how many dead-lettered tasks are kept; the oldest are
+// forgotten first
+const maxDeadLetters = 1000
+
+// ErrNotDeadLettered is returned for a task ID that isn't in the dead letters
+var ErrNotDeadLettered = errors.New("no such dead-lettered task")
+
+// DeadLetter describes a task no agent could take, kept so it can be
+// inspected and re-driven instead of being lost
+type DeadLetter struct {
+	TaskID       string    `json:"task_id"`
+	Description  string    `json:"description,omitempty"`
+	AgentType    string    `json:"agent_type,omitempty"`
+	Requires     []string  `json:"requires,omitempty"`
+	RunID        string    `json:"run_id,omitempty"`
+	Lane         string    `json:"lane"`     // Inbox lane it was sent on
+	Reason       string    `json:"reason"`   // Why it couldn't be delivered
+	Attempts     int       `json:"attempts"` // Times it has been dead-lettered, counting re-drives that failed again
+	DeadLettered time.Time `json:"dead_lettered_at"`
+}
+
+// deadLetter is a DeadLetter with the task itself, for re-driving
+type deadLetter struct {
+	DeadLetter
+	task     types.Task
+	priority types.MessagePriority
+}
+
+// deadLetterTask keeps a task that couldn't be delivered. A task already
+// dead-lettered under the same ID is replaced, counting the attempt.
+func (s *Swarm) deadLetterTask(task types.Task, priority types.MessagePriority, reason error) {
+	entry := &deadLetter{
+		DeadLetter: DeadLetter{
+			TaskID:       task.ID,
+			Description:  task.Description,
+			AgentType:    task.AgentType(),
+			Requires:     task.Requires,
+			RunID:        task.RunID,
+			Lane:         priority.String(),
+			Reason:       reason.Error(),
+			Attempts:     1,
+			DeadLettered: time.Now(),
+		},
+		task:     task,
+		priority: priority,
+	}
+
+	s.deadMu.Lock()
+	for i, old := range s.deadLetters {
+		if old.TaskID == task.ID {
+			entry.Attempts += old.Attempts
+			s.deadLetters = append(s.deadLetters[:i], s.deadLetters[i+1:]...)
+			break
+		}
+	}
+	s.deadLetters = append(s.deadLetters, entry)
+	if excess := len(s.deadLetters) - maxDeadLetters; excess > 0 {
+		s.deadLetters = append(s.deadLetters[:0], s.deadLetters[excess:]...)
+	}
+	s.deadMu.Unlock()
+
+	slog.Warn("task dead-lettered", logging.KeyTask, task.ID, "reason", entry.Reason, "attempts", entry.Attempts)
+	s.eventBus.Publish(types.Event{
+		Type:      types.EventTaskDeadLettered,
+		Timestamp: entry.DeadLettered,
+		AgentID:   "swarm",
+		TaskID:    task.ID,
+		Message:   fmt.Sprintf("📭 Task %s dead-lettered: %s", task.ID, entry.Reason),
+		Data:      entry.DeadLetter,
+		RunID:     task.RunID,
+	})
+}
+
+// DeadLetters lists the tasks no agent could take, newest first
+func (s *Swarm) DeadLetters() []DeadLetter {
+	s.deadMu.Lock()
+	defer s.deadMu.Unlock()
+
+	list := make([]DeadLetter, len(s.deadLetters))
+	for i, entry := range s.deadLetters {
+		list[i] = entry.DeadLetter
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].DeadLettered.After(list[j].DeadLettered) })
+	return list
+}
+
+// takeDeadLetter removes a dead-lettered task and returns it
+func (s *Swarm) takeDeadLetter(taskID string) (*deadLetter, bool) {
+	s.deadMu.Lock()
+	defer s.deadMu.Unlock()
+
+	for i, entry := range s.deadLetters {
+		if entry.TaskID == taskID {
+			s.deadLetters = append(s.deadLetters[:i], s.deadLetters[i+1:]...)
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// Redrive submits a dead-lettered task again through the queue, on the lane
+// it was first sent on. Its earlier result, if it had one, is forgotten so
+// AwaitResult waits for the new one. If it still can't be delivered it is
+// dead-lettered again.
+func (s *Swarm) Redrive(taskID string) error {
+	entry, ok := s.takeDeadLetter(taskID)
+	if !ok {
+		return fmt.Errorf("task %s: %w", taskID, ErrNotDeadLettered)
+	}
+
+	s.forgetResult(taskID)
+	if err := s.SubmitNoWaitWithPriority(entry.task, entry.priority); err != nil {
+		// Still undeliverable; keep it where it was
+		s.deadMu.Lock()
+		s.deadLetters = append(s.deadLetters, entry)
+		s.deadMu.Unlock()
+		return fmt.Errorf("re-driving task %s: %w", taskID, err)
+	}
+	slog.Info("dead-lettered task re-driven", logging.KeyTask, taskID)
+	return nil
+}
+
+// RedriveAll re-drives every dead-lettered task, oldest first, and returns
+// how many were submitted. It stops at the first that can't be, e.g. when
+// the queue is full.
+func (s *Swarm) RedriveAll() (int, error) {
+	s.deadMu.Lock()
+	ids := make([]string, len(s.deadLetters))
+	for i, entry := range s.deadLetters {
+		ids[i] = entry.TaskID
+	}
+	s.deadMu.Unlock()
+
+	n := 0
+	for _, id := range ids {
+		err := s.Redrive(id)
+		if errors.Is(err, ErrNotDeadLettered) {
+			continue // Re-driven or discarded meanwhile
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// DiscardDeadLetter forgets a dead-lettered task without re-driving it
+func (s *Swarm) DiscardDeadLetter(taskID string) error {
+	if _, ok := s.takeDeadLetter(taskID); !ok {
+		return fmt.Errorf("task %s: %w", taskID, ErrNotDeadLettered)
+	}
+	return nil
+}
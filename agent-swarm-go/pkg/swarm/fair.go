@@ -0,0 +1,238 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultSource is the source of tasks submitted without one (see types.Task.Source)
+const DefaultSource = "default"
+
+// ErrSourceQuota is returned by SubmitNoWait when the task's source already
+// has as many tasks queued as its quota allows
+var ErrSourceQuota = errors.New("source has its quota of tasks queued")
+
+// FairPolicy shares the submission queue between task sources, so one client
+// submitting a flood of tasks can't starve the others (see WithFairQueuing).
+//
+// Sources are served by weighted fair queuing: while several have tasks
+// waiting, each is dispatched in proportion to its weight, and within a source
+// tasks keep their submission order. A quota caps how many tasks a source may
+// have waiting; its submissions beyond that wait (Submit) or are rejected
+// (SubmitNoWait) while other sources carry on.
+type FairPolicy struct {
+	Weights       map[string]int // Source -> weight
+	DefaultWeight int            // For sources not in Weights; 0 means 1
+	Quotas        map[string]int // Source -> most tasks it may have queued
+	DefaultQuota  int            // For sources not in Quotas; 0 means no limit beyond the queue's
+}
+
+// WithFairQueuing schedules the in-memory submission queue fairly between task
+// sources instead of first come, first served. It has no effect with
+// WithQueueBackend.
+func WithFairQueuing(policy FairPolicy) Option {
+	return func(s *Swarm) { s.fair = &policy }
+}
+
+// weight returns a source's weight
+func (p FairPolicy) weight(source string) int {
+	if w, ok := p.Weights[source]; ok && w > 0 {
+		return w
+	}
+	if p.DefaultWeight > 0 {
+		return p.DefaultWeight
+	}
+	return 1
+}
+
+// quota returns the most tasks a source may have queued, or 0 for no limit
+func (p FairPolicy) quota(source string) int {
+	if q, ok := p.Quotas[source]; ok {
+		return q
+	}
+	return p.DefaultQuota
+}
+
+// sourceOf returns the source a task is scheduled under
+func sourceOf(task types.Task) string {
+	if task.Source == "" {
+		return DefaultSource
+	}
+	return task.Source
+}
+
+// fairQueue is the QueueBackend used with WithFairQueuing: a FIFO per source,
+// served by stride scheduling. Each source has a pass that advances by
+// 1/weight for every task taken from it; Pop takes from the waiting source
+// with the lowest pass, so heavier sources are served more often.
+type fairQueue struct {
+	policy   FairPolicy
+	capacity int
+
+	mu      sync.Mutex
+	sources map[string]*fairSource
+	length  int
+	vtime   float64       // Pass of the last task taken, where newly busy sources start
+	changed chan struct{} // Closed and replaced whenever a task is added or taken
+}
+
+// fairSource is one source's waiting tasks
+type fairSource struct {
+	tasks []QueuedTask
+	pass  float64
+}
+
+func newFairQueue(policy FairPolicy, capacity int) *fairQueue {
+	return &fairQueue{
+		policy:   policy,
+		capacity: capacity,
+		sources:  make(map[string]*fairSource),
+		changed:  make(chan struct{}),
+	}
+}
+
+// add queues item if there is room, or returns why not. The caller holds q.mu.
+func (q *fairQueue) add(item QueuedTask) error {
+	if q.length >= q.capacity {
+		return ErrQueueFull
+	}
+	name := sourceOf(item.Task)
+	src := q.sources[name]
+	if quota := q.policy.quota(name); quota > 0 && src != nil && len(src.tasks) >= quota {
+		return fmt.Errorf("%s: %w (%d)", name, ErrSourceQuota, quota)
+	}
+
+	if src == nil {
+		src = &fairSource{}
+		q.sources[name] = src
+	}
+	if len(src.tasks) == 0 && src.pass < q.vtime {
+		// An idle source doesn't bank credit for the time it had nothing queued
+		src.pass = q.vtime
+	}
+	src.tasks = append(src.tasks, item)
+	q.length++
+	q.notify()
+	return nil
+}
+
+// notify wakes everyone waiting for a change. The caller holds q.mu.
+func (q *fairQueue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+func (q *fairQueue) Push(ctx context.Context, item QueuedTask) error {
+	for {
+		q.mu.Lock()
+		err := q.add(item)
+		changed := q.changed
+		q.mu.Unlock()
+		if !errors.Is(err, ErrQueueFull) && !errors.Is(err, ErrSourceQuota) {
+			return err
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (q *fairQueue) TryPush(item QueuedTask) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.add(item)
+}
+
+func (q *fairQueue) Pop(ctx context.Context) (QueuedTask, error) {
+	for {
+		q.mu.Lock()
+		if item, ok := q.take(); ok {
+			q.mu.Unlock()
+			return item, nil
+		}
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return QueuedTask{}, ctx.Err()
+		}
+	}
+}
+
+// take removes the next task in fair order. The caller holds q.mu.
+func (q *fairQueue) take() (QueuedTask, bool) {
+	var (
+		next *fairSource
+		name string
+	)
+	for n, src := range q.sources {
+		if len(src.tasks) == 0 {
+			if src.pass <= q.vtime {
+				delete(q.sources, n) // Would start again from vtime anyway
+			}
+			continue
+		}
+		// Lowest pass first; on a tie, the longest-waiting head task
+		if next == nil || src.pass < next.pass ||
+			(src.pass == next.pass && src.tasks[0].Submitted.Before(next.tasks[0].Submitted)) {
+			next, name = src, n
+		}
+	}
+	if next == nil {
+		return QueuedTask{}, false
+	}
+
+	item := next.tasks[0]
+	next.tasks = next.tasks[1:]
+	q.vtime = next.pass
+	next.pass += 1 / float64(q.policy.weight(name))
+	if len(next.tasks) == 0 {
+		next.tasks = nil
+	}
+	q.length--
+	q.notify()
+	return item, true
+}
+
+func (q *fairQueue) Ack(QueuedTask) error { return nil }
+
+func (q *fairQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.length
+}
+
+func (q *fairQueue) Cap() int { return q.capacity }
+
+// depths returns how many tasks each source has waiting, sorted by source
+func (q *fairQueue) depths() []SourceDepth {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var list []SourceDepth
+	for name, src := range q.sources {
+		if len(src.tasks) > 0 {
+			list = append(list, SourceDepth{Source: name, Depth: len(src.tasks), Weight: q.policy.weight(name), Quota: q.policy.quota(name)})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Source < list[j].Source })
+	return list
+}
+
+// SourceDepth is one source's share of the queue with WithFairQueuing
+type SourceDepth struct {
+	Source string `json:"source"`
+	Depth  int    `json:"depth"`           // Tasks waiting
+	Weight int    `json:"weight"`          // Share of dispatches while others wait too
+	Quota  int    `json:"quota,omitempty"` // Most it may have waiting; 0 means no limit
+}
@@ -0,0 +1,144 @@
+package swarm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// ErrDuplicateTask matches every *DuplicateTaskError
+var ErrDuplicateTask = errors.New("task already submitted")
+
+// keyRetention is how long a succeeded task's idempotency key keeps
+// answering duplicate submissions with its result
+const keyRetention = 24 * time.Hour
+
+// DuplicateTaskError is returned by Submit, DistributeTask, AssignTask, and
+// their variants for a task whose idempotency key (types.Task.IdempotencyKey)
+// was already submitted. Nothing is queued and no LLM is called again:
+// AwaitResult(TaskID) returns the first task's result, at once if it has
+// finished.
+//
+// A key only matches tasks from the same submitter (types.Task.Source), so
+// one client can't reach another's task by reusing or guessing its key. A
+// key is released when its task fails or is dead-lettered, so a retry runs,
+// and 24 hours after its task succeeded. Keys are held in memory by each
+// swarm, so namespaces don't share them, nor does a durable queue.
+type DuplicateTaskError struct {
+	Key    string
+	TaskID string // The task first submitted with Key
+}
+
+func (e *DuplicateTaskError) Error() string {
+	return fmt.Sprintf("idempotency key %q was already submitted as task %s", e.Key, e.TaskID)
+}
+
+func (e *DuplicateTaskError) Unwrap() error { return ErrDuplicateTask }
+
+// taskKey is an idempotency key as submitted by one source
+type taskKey struct {
+	source string
+	key    string
+}
+
+// keyOf returns task's idempotency key, scoped to its source, and whether it
+// has one
+func keyOf(task types.Task) (taskKey, bool) {
+	return taskKey{source: task.Source, key: task.IdempotencyKey}, task.IdempotencyKey != ""
+}
+
+// keyedTask is the task holding an idempotency key
+type keyedTask struct {
+	taskID   string
+	result   *types.Result // Set once the task succeeds
+	finished time.Time
+}
+
+// claimKey reserves task's idempotency key for it, or returns a
+// *DuplicateTaskError when another task holds the key. Tasks without a key
+// are always accepted.
+func (s *Swarm) claimKey(task types.Task) error {
+	key, ok := keyOf(task)
+	if !ok {
+		return nil
+	}
+
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.keysPruned) > time.Minute {
+		s.pruneKeys(now)
+	}
+
+	if keyed, ok := s.keys[key]; ok {
+		if keyed.result != nil {
+			// Its result may have left the registry already; AwaitResult still finds it
+			s.resultsMu.Lock()
+			s.settle(keyed.taskID, *keyed.result, now)
+			s.resultsMu.Unlock()
+		}
+		return &DuplicateTaskError{Key: key.key, TaskID: keyed.taskID}
+	}
+	s.keys[key] = &keyedTask{taskID: task.ID}
+	s.keyTasks[task.ID] = key
+	return nil
+}
+
+// releaseKey frees the key claimed by a task that was then not accepted
+func (s *Swarm) releaseKey(task types.Task) {
+	key, ok := keyOf(task)
+	if !ok {
+		return
+	}
+
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	if keyed, ok := s.keys[key]; ok && keyed.taskID == task.ID && keyed.result == nil {
+		delete(s.keys, key)
+		delete(s.keyTasks, task.ID)
+	}
+}
+
+// settleKey records a keyed task's result when it succeeds, or frees its key
+// when it fails or is dead-lettered. It observes the event bus, so no
+// outcome is missed.
+func (s *Swarm) settleKey(event types.Event) {
+	switch event.Type {
+	case types.EventTaskCompleted, types.EventTaskFailed, types.EventTaskDeadLettered:
+	default:
+		return
+	}
+
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	key, ok := s.keyTasks[event.TaskID]
+	if !ok {
+		return
+	}
+	delete(s.keyTasks, event.TaskID)
+
+	result := resultFromEvent(event)
+	if event.Type != types.EventTaskCompleted || !result.Success {
+		delete(s.keys, key)
+		return
+	}
+	keyed := s.keys[key]
+	keyed.result = &result
+	keyed.finished = time.Now()
+}
+
+// pruneKeys forgets keys whose task succeeded more than keyRetention ago.
+// Callers hold keysMu.
+func (s *Swarm) pruneKeys(now time.Time) {
+	for key, keyed := range s.keys {
+		if keyed.result != nil && now.Sub(keyed.finished) > keyRetention {
+			delete(s.keys, key)
+		}
+	}
+	s.keysPruned = now
+}
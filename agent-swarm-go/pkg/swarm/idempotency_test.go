@@ -0,0 +1,73 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+func TestIdempotencyKeyAnswersDuplicateWithFirstResult(t *testing.T) {
+	agent := &fakeAgent{id: "agent-1", complete: true}
+	s := newTestSwarm(t, VisibilityConfig{}, agent)
+
+	first := types.Task{ID: "task-1", Source: "ci", IdempotencyKey: "weekly"}
+	if err := s.DistributeTaskWithPriority(first, types.PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+	err := s.DistributeTaskWithPriority(types.Task{ID: "task-2", Source: "ci", IdempotencyKey: "weekly"}, types.PriorityNormal)
+	var duplicate *DuplicateTaskError
+	if !errors.As(err, &duplicate) || duplicate.TaskID != "task-1" || !errors.Is(err, ErrDuplicateTask) {
+		t.Fatalf("second submission: %v; want a duplicate of task-1", err)
+	}
+	if got := agent.received(); len(got) != 1 {
+		t.Errorf("agent received %v; want task-1 only", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := s.AwaitResult(ctx, duplicate.TaskID)
+	if err != nil || !result.Success {
+		t.Errorf("AwaitResult(task-1) = %+v, %v; want its success", result, err)
+	}
+}
+
+func TestIdempotencyKeyIsScopedToSource(t *testing.T) {
+	agent := &fakeAgent{id: "agent-1"}
+	s := newTestSwarm(t, VisibilityConfig{}, agent)
+
+	for _, task := range []types.Task{
+		{ID: "task-1", Source: "team-a", IdempotencyKey: "report"},
+		{ID: "task-2", Source: "team-b", IdempotencyKey: "report"},
+	} {
+		if err := s.DistributeTaskWithPriority(task, types.PriorityNormal); err != nil {
+			t.Fatalf("%s from %s: %v", task.ID, task.Source, err)
+		}
+	}
+	if got := agent.received(); len(got) != 2 {
+		t.Errorf("agent received %v; want both tasks", got)
+	}
+}
+
+func TestIdempotencyKeyIsReleasedWhenTaskFails(t *testing.T) {
+	agent := &fakeAgent{id: "agent-1"}
+	s := newTestSwarm(t, VisibilityConfig{}, agent)
+
+	task := types.Task{ID: "task-1", Source: "ci", IdempotencyKey: "retry-me"}
+	if err := s.DistributeTaskWithPriority(task, types.PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+	s.GetEventBus().Publish(types.Event{
+		Type:    types.EventTaskFailed,
+		AgentID: "agent-1",
+		TaskID:  "task-1",
+		Data:    types.Result{TaskID: "task-1", Success: false},
+	})
+
+	task.ID = "task-2"
+	if err := s.DistributeTaskWithPriority(task, types.PriorityNormal); err != nil {
+		t.Errorf("retry after failure: %v; want it accepted", err)
+	}
+}
@@ -0,0 +1,115 @@
+package swarm
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+)
+
+// DefaultLeaseTTL is how long a leader's lease lasts without renewal, and so
+// roughly how long a crashed leader goes unreplaced
+const DefaultLeaseTTL = 15 * time.Second
+
+// LeaderLock is a lease that at most one node holds at a time (see
+// WithLeaderElection)
+type LeaderLock interface {
+	// Acquire takes the lease for ttl, or renews it if this node already
+	// holds it, and reports whether this node holds it afterwards
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+
+	// Release gives the lease up if this node holds it
+	Release(ctx context.Context) error
+}
+
+// LeaderStatus is this swarm's part in leader election
+type LeaderStatus struct {
+	Node     string     `json:"node"`
+	Election bool       `json:"election"`        // false for a lone swarm, which always leads
+	Leader   bool       `json:"leader"`          // Whether this swarm runs the cluster-wide work
+	Since    *time.Time `json:"since,omitempty"` // When this swarm last became leader
+}
+
+// WithLeaderElection makes the swarm one of several nodes sharing a queue,
+// of which only the holder of lock runs cluster-wide work: cron schedules and
+// anything else gated on IsLeader. Without it the swarm always leads.
+func WithLeaderElection(node string, lock LeaderLock) Option {
+	return func(s *Swarm) {
+		s.leaderNode = node
+		s.leaderLock = lock
+	}
+}
+
+// IsLeader reports whether this swarm should run cluster-wide work
+func (s *Swarm) IsLeader() bool {
+	if s.leaderLock == nil {
+		return true
+	}
+	return s.leading.Load()
+}
+
+// Leadership returns this swarm's part in leader election
+func (s *Swarm) Leadership() LeaderStatus {
+	s.leaderMu.Lock()
+	defer s.leaderMu.Unlock()
+	status := LeaderStatus{
+		Node:     s.leaderNode,
+		Election: s.leaderLock != nil,
+		Leader:   s.IsLeader(),
+	}
+	if !s.leaderSince.IsZero() {
+		since := s.leaderSince
+		status.Since = &since
+	}
+	return status
+}
+
+// runElection keeps trying for the lease, renewing it at a third of its TTL
+// while held, until ctx is done; then it releases the lease so another node
+// can take over at once. An error renewing counts as losing the lease, so a
+// node cut off from the lock stops leading before its lease runs out.
+func (s *Swarm) runElection(ctx context.Context) {
+	ticker := time.NewTicker(DefaultLeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, DefaultLeaseTTL/3)
+		held, err := s.leaderLock.Acquire(attemptCtx, DefaultLeaseTTL)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("leader election failed", "node", s.leaderNode, logging.KeyError, err)
+		}
+		s.setLeading(held && err == nil)
+
+		select {
+		case <-ctx.Done():
+			s.setLeading(false)
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.leaderLock.Release(releaseCtx); err != nil {
+				slog.Warn("releasing leadership failed", "node", s.leaderNode, logging.KeyError, err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setLeading records a change of leadership
+func (s *Swarm) setLeading(leading bool) {
+	if s.leading.Swap(leading) == leading {
+		return
+	}
+	s.leaderMu.Lock()
+	if leading {
+		s.leaderSince = time.Now()
+	}
+	s.leaderMu.Unlock()
+
+	if leading {
+		slog.Info("this node is now the leader", "node", s.leaderNode)
+	} else {
+		slog.Info("this node is no longer the leader", "node", s.leaderNode)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !unix
+
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// fileLock can't lock on this platform; every Acquire fails, so the node
+// never leads
+type fileLock struct{}
+
+// NewFileLock returns a LeaderLock that always fails here: file locks need a
+// Unix system. Use a Redis lock instead.
+func NewFileLock(path, node string) LeaderLock {
+	return fileLock{}
+}
+
+func (fileLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	return false, fmt.Errorf("file leader locks need a Unix system")
+}
+
+func (fileLock) Release(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,67 @@
+//go:build unix
+
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// fileLock is a LeaderLock held as an flock on a file. The lock lasts until
+// it is released or the process exits, so ttl doesn't apply.
+type fileLock struct {
+	path string
+	node string
+
+	mu   sync.Mutex
+	file *os.File // Open while the lock is held
+}
+
+// NewFileLock returns a LeaderLock for nodes on one machine, or sharing a
+// filesystem with working flock. The holder writes node into the file.
+func NewFileLock(path, node string) LeaderLock {
+	return &fileLock{path: path, node: node}
+}
+
+func (l *fileLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		return true, nil
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, nil
+		}
+		return false, fmt.Errorf("locking %s: %w", l.path, err)
+	}
+
+	// Say who leads, for whoever looks at the file
+	f.Truncate(0)
+	f.WriteAt([]byte(l.node+"\n"), 0)
+	l.file = f
+	return true, nil
+}
+
+func (l *fileLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	l.file.Close()
+	l.file = nil
+	return err
+}
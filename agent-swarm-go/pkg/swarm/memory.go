@@ -0,0 +1,58 @@
+package swarm
+
+import "agent-swarm-go/pkg/types"
+
+// maxRunMemories is how many runs' memories the swarm keeps; the one used
+// least recently is forgotten first
+const maxRunMemories = 64
+
+// remembering is implemented by agents that can use their run's memory
+// (every agent embedding agent.BaseAgent). Agents that don't, such as those
+// in another process, get their tasks with memory references resolved.
+type remembering interface {
+	SetMemoryStore(store types.MemoryStore)
+}
+
+// Memory returns the memory the agents of run runID share, created empty on
+// first use, or nil for "". It implements types.MemoryStore; agents call it
+// through BaseAgent.Memory. A workflow can file documents there for its
+// later steps to reference with types.MemoryRef instead of copying them into
+// every task's Context.
+func (s *Swarm) Memory(runID string) *types.RunMemory {
+	if runID == "" {
+		return nil
+	}
+	s.memoriesMu.Lock()
+	defer s.memoriesMu.Unlock()
+
+	memory, ok := s.memories[runID]
+	if !ok {
+		memory = types.NewRunMemory()
+		s.memories[runID] = memory
+	}
+	s.touchMemory(runID)
+	for len(s.memoryOrder) > maxRunMemories {
+		delete(s.memories, s.memoryOrder[0])
+		s.memoryOrder = s.memoryOrder[1:]
+	}
+	return memory
+}
+
+// memoryOf returns run runID's memory if it has one, without creating it
+func (s *Swarm) memoryOf(runID string) *types.RunMemory {
+	s.memoriesMu.Lock()
+	defer s.memoriesMu.Unlock()
+	return s.memories[runID]
+}
+
+// touchMemory moves runID to the end of the use order; callers hold
+// memoriesMu
+func (s *Swarm) touchMemory(runID string) {
+	for i, id := range s.memoryOrder {
+		if id == runID {
+			s.memoryOrder = append(s.memoryOrder[:i], s.memoryOrder[i+1:]...)
+			break
+		}
+	}
+	s.memoryOrder = append(s.memoryOrder, runID)
+}
@@ -0,0 +1,333 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultQueueSize is the submission queue's capacity unless WithQueueSize
+// says otherwise
+const DefaultQueueSize = 1000
+
+// queueRetryInterval is how long the queue waits before offering a task to
+// the agents again after every inbox that could take it was full
+const queueRetryInterval = 100 * time.Millisecond
+
+// ErrQueueFull is returned by SubmitNoWait when the submission queue has no room
+var ErrQueueFull = errors.New("task queue is full")
+
+// QueuedTask is a submitted task waiting to be distributed
+type QueuedTask struct {
+	Task      types.Task            `json:"task"`
+	Priority  types.MessagePriority `json:"priority"`
+	Submitted time.Time             `json:"submitted"`
+
+	// Receipt is set by a backend's Pop to identify the task in Ack
+	Receipt string `json:"-"`
+}
+
+// QueueBackend stores submitted tasks until the swarm distributes them. The
+// default keeps them in memory; a durable backend (such as
+// transport.RedisQueue) lets queued tasks survive a crash and lets several
+// swarms share one queue.
+type QueueBackend interface {
+	// Push adds a task, waiting for room until ctx is done
+	Push(ctx context.Context, item QueuedTask) error
+
+	// TryPush adds a task, or returns ErrQueueFull at once
+	TryPush(item QueuedTask) error
+
+	// Pop waits for the oldest task until ctx is done
+	Pop(ctx context.Context) (QueuedTask, error)
+
+	// Ack reports that a popped task was handed to an agent, so a durable
+	// backend can forget it; until then it is redelivered after a crash
+	Ack(item QueuedTask) error
+
+	// Len returns how many tasks are waiting
+	Len() int
+
+	// Cap returns the most tasks that can wait, or 0 for no limit
+	Cap() int
+}
+
+// WithQueueSize sets the in-memory submission queue's capacity (see Submit)
+func WithQueueSize(size int) Option {
+	return func(s *Swarm) {
+		if size > 0 {
+			s.queueSize = size
+		}
+	}
+}
+
+// WithQueueBackend stores submitted tasks in backend instead of memory
+func WithQueueBackend(backend QueueBackend) Option {
+	return func(s *Swarm) { s.queue = backend }
+}
+
+// memoryQueue is the default QueueBackend: a buffered channel
+type memoryQueue chan QueuedTask
+
+func (q memoryQueue) Push(ctx context.Context, item QueuedTask) error {
+	select {
+	case q <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q memoryQueue) TryPush(item QueuedTask) error {
+	select {
+	case q <- item:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q memoryQueue) Pop(ctx context.Context) (QueuedTask, error) {
+	select {
+	case item := <-q:
+		return item, nil
+	case <-ctx.Done():
+		return QueuedTask{}, ctx.Err()
+	}
+}
+
+func (q memoryQueue) Ack(QueuedTask) error { return nil }
+func (q memoryQueue) Len() int             { return len(q) }
+func (q memoryQueue) Cap() int             { return cap(q) }
+
+// queueStats counts what has passed through the submission queue
+type queueStats struct {
+	submitted  atomic.Int64
+	dispatched atomic.Int64
+	rejected   atomic.Int64
+	blocked    atomic.Int64 // Submit calls currently waiting for room
+	waitTotal  atomic.Int64 // Nanoseconds from submission to dispatch, summed
+	urgent     atomic.Int64 // Urgent tasks accepted
+	preempted  atomic.Int64 // Urgent tasks dispatched while others were queued
+}
+
+// QueueStats describes the submission queue, as served at /api/queue
+type QueueStats struct {
+	Capacity   int     `json:"capacity"`   // 0 means no limit
+	Depth      int     `json:"depth"`      // Tasks waiting to be distributed, by every swarm sharing the queue
+	Submitted  int64   `json:"submitted"`  // Tasks accepted since the swarm was created
+	Dispatched int64   `json:"dispatched"` // Tasks handed to an agent
+	Rejected   int64   `json:"rejected"`   // Full queue for SubmitNoWait, or Submit's context done first
+	Blocked    int64   `json:"blocked"`    // Submit calls waiting for room right now
+	AvgWaitMs  float64 `json:"avg_wait_ms"`
+
+	// Tasks waiting per source, with WithFairQueuing
+	Sources []SourceDepth `json:"sources,omitempty"`
+
+	// Urgent tasks (see types.PriorityUrgent) and how often they got ahead of others
+	Urgent           int64 `json:"urgent"`            // Urgent tasks accepted
+	Preempted        int64 `json:"preempted"`         // Dispatched ahead of tasks waiting in the queue
+	InboxPreemptions int64 `json:"inbox_preemptions"` // Taken by an agent ahead of messages waiting in its inbox
+}
+
+// Submit queues a task for distribution, waiting for room while the queue is
+// full or until ctx is done. Unlike DistributeTask it doesn't fail when every
+// agent's inbox is full: the queue holds the task and keeps offering it until
+// an agent takes it. Tasks are distributed in submission order once the swarm
+// is started, except that PriorityUrgent tasks skip ahead (see urgent.go).
+// A task whose idempotency key was already submitted is not queued again
+// (see DuplicateTaskError).
+func (s *Swarm) Submit(ctx context.Context, task types.Task) error {
+	return s.SubmitWithPriority(ctx, task, types.PriorityDefault)
+}
+
+// SubmitWithPriority is Submit with an inbox lane, as for
+// DistributeTaskWithPriority
+func (s *Swarm) SubmitWithPriority(ctx context.Context, task types.Task, priority types.MessagePriority) error {
+	task = stampSubmitted(task)
+	if err := s.claimKey(task); err != nil {
+		return err
+	}
+	item := QueuedTask{Task: task, Priority: priority, Submitted: task.Submitted}
+	queue := s.queueFor(priority)
+	err := queue.TryPush(item)
+	if errors.Is(err, ErrQueueFull) || errors.Is(err, ErrSourceQuota) {
+		s.queueStats.blocked.Add(1)
+		err = queue.Push(ctx, item)
+		s.queueStats.blocked.Add(-1)
+	}
+	if err != nil {
+		s.queueStats.rejected.Add(1)
+		s.releaseKey(task)
+		return err
+	}
+	s.queued(item)
+	s.submitted(task, priority)
+	return nil
+}
+
+// SubmitNoWait queues a task like Submit but returns ErrQueueFull at once
+// instead of waiting for room, or ErrSourceQuota when its source has its
+// quota of tasks queued (see WithFairQueuing)
+func (s *Swarm) SubmitNoWait(task types.Task) error {
+	return s.SubmitNoWaitWithPriority(task, types.PriorityDefault)
+}
+
+// SubmitNoWaitWithPriority is SubmitNoWait with an inbox lane
+func (s *Swarm) SubmitNoWaitWithPriority(task types.Task, priority types.MessagePriority) error {
+	task = stampSubmitted(task)
+	if err := s.claimKey(task); err != nil {
+		return err
+	}
+	item := QueuedTask{Task: task, Priority: priority, Submitted: task.Submitted}
+	if err := s.queueFor(priority).TryPush(item); err != nil {
+		s.queueStats.rejected.Add(1)
+		s.releaseKey(task)
+		return err
+	}
+	s.queued(item)
+	s.submitted(task, priority)
+	return nil
+}
+
+// stampSubmitted sets task.Submitted to now, unless the submitter already
+// has, e.g. a gateway that accepted the task earlier. Agents report it in
+// Result.Metadata, so a result tells queue wait and processing time apart.
+func stampSubmitted(task types.Task) types.Task {
+	if task.Submitted.IsZero() {
+		task.Submitted = time.Now()
+	}
+	return task
+}
+
+// queued counts and tracks a task just accepted by a queue
+func (s *Swarm) queued(item QueuedTask) {
+	s.queueStats.submitted.Add(1)
+	if item.Priority == types.PriorityUrgent {
+		s.queueStats.urgent.Add(1)
+	}
+	s.trackPending(item, "")
+}
+
+// QueueStats returns the submission queue's current depth and counters
+func (s *Swarm) QueueStats() QueueStats {
+	stats := QueueStats{
+		Capacity:         s.queue.Cap(),
+		Depth:            s.queue.Len() + s.urgent.Len(),
+		Submitted:        s.queueStats.submitted.Load(),
+		Dispatched:       s.queueStats.dispatched.Load(),
+		Rejected:         s.queueStats.rejected.Load(),
+		Blocked:          s.queueStats.blocked.Load(),
+		Urgent:           s.queueStats.urgent.Load(),
+		Preempted:        s.queueStats.preempted.Load(),
+		InboxPreemptions: s.inboxPreemptions(),
+	}
+	if fair, ok := s.queue.(*fairQueue); ok {
+		stats.Sources = fair.depths()
+	}
+	if stats.Dispatched > 0 {
+		avg := time.Duration(s.queueStats.waitTotal.Load() / stats.Dispatched)
+		stats.AvgWaitMs = float64(avg.Microseconds()) / 1000
+	}
+	return stats
+}
+
+// runQueue distributes submitted tasks one at a time, retrying each until an
+// agent accepts it. It runs until ctx is done; tasks still queued then are
+// dropped with the swarm unless the backend is durable.
+func (s *Swarm) runQueue(ctx context.Context) {
+	for {
+		item, err := s.queue.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("reading task queue failed", logging.KeyError, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		if !s.distributeQueued(ctx, item) {
+			return
+		}
+		if err := s.queue.Ack(item); err != nil {
+			slog.Warn("acknowledging queued task failed", logging.KeyTask, item.Task.ID, logging.KeyError, err)
+		}
+	}
+}
+
+// distributeQueued keeps offering a queued task to the agents until one takes
+// it, and reports false if ctx was done first. A task no agent has the
+// capabilities for fails at once instead, and is dead-lettered.
+func (s *Swarm) distributeQueued(ctx context.Context, item QueuedTask) bool {
+	for attempt := 1; ; attempt++ {
+		// Cancelled while it waited (CancelTask has reported it already)
+		if s.takeCancelled(item.Task.ID) {
+			return true
+		}
+
+		err := s.distribute(item.Task, item.Priority)
+		if err == nil {
+			s.queueStats.dispatched.Add(1)
+			s.queueStats.waitTotal.Add(int64(time.Since(item.Submitted)))
+			// Cancelled just as it was handed over: pass it on to the agent
+			if s.takeCancelled(item.Task.ID) {
+				if agentID, _ := s.holderOf(item.Task.ID); agentID != "" {
+					s.cancelOn(agentID, item.Task.ID)
+				}
+				s.completeInflight(item.Task.ID)
+				s.dropPending(item.Task.ID)
+			}
+			return true
+		}
+		if errors.Is(err, ErrNoCapableAgent) {
+			// Waiting would hold up every task behind this one
+			s.failUnroutable(item.Task, err)
+			s.deadLetterTask(item.Task, item.Priority, err)
+			return true
+		}
+		if attempt == 1 {
+			slog.Debug("queued task waiting for an agent", logging.KeyTask, item.Task.ID, logging.KeyError, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(queueRetryInterval):
+		}
+	}
+}
+
+// failUnroutable fails a queued task that no agent has the capabilities for,
+// notifying its callback like any other failure
+func (s *Swarm) failUnroutable(task types.Task, err error) {
+	slog.Warn("queued task failed: no capable agent", logging.KeyTask, task.ID, "requires", task.Requires)
+	s.registerCallback(task)
+	message := fmt.Sprintf("🚫 No agent can take task %s: %v", task.ID, err)
+	s.eventBus.Publish(types.Event{
+		Type:      types.EventTaskFailed,
+		Timestamp: time.Now(),
+		AgentID:   "swarm",
+		TaskID:    task.ID,
+		Message:   message,
+		Data: types.Result{
+			TaskID:  task.ID,
+			Success: false,
+			Data:    message,
+			Error:   err,
+			Failure: types.FailureNoCapableAgent,
+			RunID:   task.RunID,
+		},
+		RunID: task.RunID,
+	})
+}
@@ -0,0 +1,134 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// resultRetention is how long a finished task's result stays available to
+// AwaitResult
+const resultRetention = 10 * time.Minute
+
+// resultFuture is a task's result, set once when the task finishes
+type resultFuture struct {
+	done     chan struct{} // Closed once result is set
+	result   types.Result
+	finished time.Time
+	waiters  int // AwaitResult calls waiting on it
+}
+
+// AwaitResult waits until the task finishes and returns its result, or
+// ctx's error if ctx is done first. It doesn't matter whether it is called
+// before or after the task is distributed: the swarm records every task's
+// result as it is published and keeps it for 10 minutes, so a fast result
+// isn't missed. A failed task's result has Success false and the failure
+// message as Data.
+//
+// Results are matched by task ID, so task IDs must be unique.
+func (s *Swarm) AwaitResult(ctx context.Context, taskID string) (types.Result, error) {
+	s.resultsMu.Lock()
+	future := s.future(taskID)
+	future.waiters++
+	s.resultsMu.Unlock()
+
+	defer func() {
+		s.resultsMu.Lock()
+		defer s.resultsMu.Unlock()
+		future.waiters--
+		// Nobody is waiting any more for a result that may never come
+		if future.waiters == 0 && future.finished.IsZero() && s.results[taskID] == future {
+			delete(s.results, taskID)
+		}
+	}()
+
+	select {
+	case <-future.done:
+		return future.result, nil
+	case <-ctx.Done():
+		return types.Result{}, fmt.Errorf("task %s: %w", taskID, ctx.Err())
+	}
+}
+
+// future returns the task's result future, creating it if needed. Callers
+// hold resultsMu.
+func (s *Swarm) future(taskID string) *resultFuture {
+	future, ok := s.results[taskID]
+	if !ok {
+		future = &resultFuture{done: make(chan struct{})}
+		s.results[taskID] = future
+	}
+	return future
+}
+
+// recordResult settles a task's result future from its completion or
+// failure event. It observes the event bus, so no result is dropped.
+func (s *Swarm) recordResult(event types.Event) {
+	if event.TaskID == "" || (event.Type != types.EventTaskCompleted && event.Type != types.EventTaskFailed) {
+		return
+	}
+
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.resultsPruned) > time.Minute {
+		s.pruneResults(now)
+	}
+
+	s.settle(event.TaskID, resultFromEvent(event), now)
+}
+
+// settle sets a task's result, unless it has one already: only the first
+// outcome counts. Callers hold resultsMu.
+func (s *Swarm) settle(taskID string, result types.Result, now time.Time) {
+	future := s.future(taskID)
+	if !future.finished.IsZero() {
+		return
+	}
+	future.result = result
+	future.finished = now
+	close(future.done)
+}
+
+// forgetResult drops a finished task's result, so a task run again under
+// the same ID can be awaited afresh
+func (s *Swarm) forgetResult(taskID string) {
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+
+	if future, ok := s.results[taskID]; ok && !future.finished.IsZero() {
+		delete(s.results, taskID)
+	}
+}
+
+// pruneResults forgets results older than resultRetention. Callers hold
+// resultsMu.
+func (s *Swarm) pruneResults(now time.Time) {
+	for id, future := range s.results {
+		if !future.finished.IsZero() && now.Sub(future.finished) > resultRetention {
+			delete(s.results, id)
+		}
+	}
+	s.resultsPruned = now
+}
+
+// resultFromEvent returns the result a completion or failure event carries
+func resultFromEvent(event types.Event) types.Result {
+	result, ok := event.Data.(types.Result)
+	if event.Type == types.EventTaskCompleted {
+		if !ok {
+			return types.Result{TaskID: event.TaskID, Success: true, Data: event.Message, RunID: event.RunID}
+		}
+		return result
+	}
+
+	// What a failed task spent still counts against a workflow's budget
+	failed := types.Result{TaskID: event.TaskID, Success: false, Data: event.Message, RunID: event.RunID}
+	if ok {
+		failed.Error, failed.Failure, failed.Metadata = result.Error, result.Failure, result.Metadata
+	}
+	return failed
+}
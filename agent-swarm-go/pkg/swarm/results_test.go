@@ -0,0 +1,76 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+func publishResult(s *Swarm, eventType types.EventType, taskID, message string) {
+	s.GetEventBus().Publish(types.Event{Type: eventType, TaskID: taskID, Message: message})
+}
+
+func TestAwaitResultBeforeAndAfterFinish(t *testing.T) {
+	s := NewSwarm()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	early := make(chan types.Result, 1)
+	go func() {
+		result, _ := s.AwaitResult(ctx, "task-1")
+		early <- result
+	}()
+	time.Sleep(10 * time.Millisecond)
+	publishResult(s, types.EventTaskCompleted, "task-1", "done")
+
+	if result := <-early; !result.Success || result.Data != "done" {
+		t.Errorf("waiting caller got %+v; want the success", result)
+	}
+	if result, err := s.AwaitResult(ctx, "task-1"); err != nil || result.Data != "done" {
+		t.Errorf("late caller got %+v, %v; want the kept result", result, err)
+	}
+}
+
+func TestFirstOutcomeWins(t *testing.T) {
+	s := NewSwarm()
+	publishResult(s, types.EventTaskFailed, "task-1", "timed out")
+	publishResult(s, types.EventTaskCompleted, "task-1", "done")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := s.AwaitResult(ctx, "task-1")
+	if err != nil || result.Success || result.Data != "timed out" {
+		t.Errorf("got %+v, %v; want the failure", result, err)
+	}
+}
+
+func TestAbandonedWaitIsForgotten(t *testing.T) {
+	s := NewSwarm()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.AwaitResult(ctx, "task-1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v; want context.Canceled", err)
+	}
+
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+	if _, ok := s.results["task-1"]; ok {
+		t.Error("result future kept after its only waiter gave up")
+	}
+}
+
+func TestOldResultsArePruned(t *testing.T) {
+	s := NewSwarm()
+	publishResult(s, types.EventTaskCompleted, "task-1", "done")
+
+	s.resultsMu.Lock()
+	s.pruneResults(time.Now().Add(resultRetention + time.Minute))
+	_, ok := s.results["task-1"]
+	s.resultsMu.Unlock()
+	if ok {
+		t.Error("result kept past resultRetention")
+	}
+}
@@ -0,0 +1,257 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// schedulerTick is how often the scheduler checks for due schedules
+const schedulerTick = 10 * time.Second
+
+// Schedule is a task or workflow run on a cron expression. Schedules are kept
+// in the store file set with SetScheduleStore, so they survive restarts.
+type Schedule struct {
+	ID          string            `json:"id"`
+	Cron        string            `json:"cron"`                 // See CronSchedule
+	Description string            `json:"description"`          // Task description, or a label for a workflow
+	AgentType   string            `json:"agent_type,omitempty"` // Specialty the task is routed to
+	Priority    int               `json:"priority,omitempty"`   // Task priority
+	TimeoutSecs float64           `json:"timeout_seconds,omitempty"`
+	CallbackURL string            `json:"callback_url,omitempty"` // Receives each task's result; workflows use their definition's callback_url
+	Workflow    string            `json:"workflow,omitempty"`     // Workflow definition file; when set, runs the workflow instead of a task
+	Inputs      map[string]string `json:"inputs,omitempty"`       // Workflow inputs
+	NextRun     time.Time         `json:"next_run"`
+	LastRun     time.Time         `json:"last_run"`
+	LastStatus  string            `json:"last_status,omitempty"` // "dispatched", "running", "completed", or an error
+}
+
+// WorkflowRunner runs a workflow definition file with the given inputs and
+// returns when it finishes. pkg/swarm can't depend on pkg/workflows, so
+// cmd/main.go provides one with SetWorkflowRunner.
+type WorkflowRunner func(path string, inputs map[string]string) error
+
+// scheduleEntry is a schedule with its parsed cron expression
+type scheduleEntry struct {
+	Schedule
+	cron *CronSchedule
+}
+
+// SetScheduleStore loads the schedules saved in path (a missing file is an
+// empty list) and saves every later change there
+func (s *Swarm) SetScheduleStore(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var saved []Schedule
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+	s.scheduleStore = path
+
+	now := time.Now()
+	for _, sched := range saved {
+		cron, err := ParseCron(sched.Cron)
+		if err != nil {
+			slog.Warn("skipping saved schedule", "schedule", sched.ID, logging.KeyError, err)
+			continue
+		}
+		// Runs missed while the swarm was down are skipped, not caught up
+		if sched.NextRun.Before(now) {
+			sched.NextRun = cron.Next(now)
+		}
+		s.schedules[sched.ID] = &scheduleEntry{Schedule: sched, cron: cron}
+	}
+	slog.Info("schedules loaded", "path", path, "count", len(s.schedules))
+	return nil
+}
+
+// SetWorkflowRunner sets how schedules with a Workflow are run. Without a
+// runner such schedules fail with an error status.
+func (s *Swarm) SetWorkflowRunner(runner WorkflowRunner) {
+	s.schedulesMu.Lock()
+	s.workflowRunner = runner
+	s.schedulesMu.Unlock()
+}
+
+// AddSchedule validates and stores a schedule, generating an ID when it has
+// none, and returns it with NextRun filled in
+func (s *Swarm) AddSchedule(sched Schedule) (Schedule, error) {
+	cron, err := ParseCron(sched.Cron)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if sched.Workflow == "" && sched.Description == "" {
+		return Schedule{}, fmt.Errorf("schedule needs a task description or a workflow")
+	}
+	sched.NextRun = cron.Next(time.Now())
+	if sched.NextRun.IsZero() {
+		return Schedule{}, fmt.Errorf("cron %q never matches", sched.Cron)
+	}
+	if sched.CallbackURL != "" {
+		if err := webhook.ValidateURL(sched.CallbackURL); err != nil {
+			return Schedule{}, err
+		}
+	}
+
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	if sched.ID == "" {
+		sched.ID = fmt.Sprintf("schedule-%d", time.Now().UnixNano())
+	}
+	if _, exists := s.schedules[sched.ID]; exists {
+		return Schedule{}, fmt.Errorf("schedule %s already exists", sched.ID)
+	}
+	s.schedules[sched.ID] = &scheduleEntry{Schedule: sched, cron: cron}
+	s.saveSchedulesLocked()
+
+	slog.Info("schedule added", "schedule", sched.ID, "cron", sched.Cron, "next_run", sched.NextRun)
+	return sched, nil
+}
+
+// RemoveSchedule deletes a schedule
+func (s *Swarm) RemoveSchedule(id string) error {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	if _, ok := s.schedules[id]; !ok {
+		return fmt.Errorf("schedule %s not found", id)
+	}
+	delete(s.schedules, id)
+	s.saveSchedulesLocked()
+
+	slog.Info("schedule removed", "schedule", id)
+	return nil
+}
+
+// Schedules returns all schedules, the next to run first
+func (s *Swarm) Schedules() []Schedule {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	list := make([]Schedule, 0, len(s.schedules))
+	for _, entry := range s.schedules {
+		list = append(list, entry.Schedule)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].NextRun.Before(list[j].NextRun) })
+	return list
+}
+
+// runScheduler fires due schedules until ctx is done. Only the leader fires
+// them; a node that takes over fires any run the old leader missed at once.
+func (s *Swarm) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if s.IsLeader() {
+				s.fireDue(now)
+			}
+		}
+	}
+}
+
+// fireDue starts every schedule whose next run has come and advances it
+func (s *Swarm) fireDue(now time.Time) {
+	var due []Schedule
+	s.schedulesMu.Lock()
+	runner := s.workflowRunner
+	for _, entry := range s.schedules {
+		if entry.NextRun.IsZero() || now.Before(entry.NextRun) {
+			continue
+		}
+		entry.LastRun = now
+		entry.NextRun = entry.cron.Next(now)
+		due = append(due, entry.Schedule)
+	}
+	s.schedulesMu.Unlock()
+
+	for _, sched := range due {
+		if sched.Workflow != "" {
+			s.setScheduleStatus(sched.ID, "running")
+			go s.runScheduledWorkflow(sched, runner)
+			continue
+		}
+
+		task := types.Task{
+			ID:          fmt.Sprintf("%s-%d", sched.ID, now.Unix()),
+			Description: sched.Description,
+			Priority:    sched.Priority,
+			Payload:     map[string]interface{}{"agent_type": sched.AgentType, "schedule": sched.ID},
+			Timeout:     time.Duration(sched.TimeoutSecs * float64(time.Second)),
+			CallbackURL: sched.CallbackURL,
+		}
+		status := "dispatched"
+		if err := s.DistributeTask(task); err != nil {
+			status = err.Error()
+		}
+		slog.Info("scheduled task fired", "schedule", sched.ID, logging.KeyTask, task.ID, "status", status)
+		s.setScheduleStatus(sched.ID, status)
+	}
+}
+
+// runScheduledWorkflow runs a schedule's workflow and records how it ended
+func (s *Swarm) runScheduledWorkflow(sched Schedule, runner WorkflowRunner) {
+	status := "completed"
+	if runner == nil {
+		status = "no workflow runner configured"
+	} else if err := runner(sched.Workflow, sched.Inputs); err != nil {
+		status = err.Error()
+	}
+	slog.Info("scheduled workflow finished", "schedule", sched.ID, "workflow", sched.Workflow, "status", status)
+	s.setScheduleStatus(sched.ID, status)
+}
+
+// setScheduleStatus records a schedule's last status and saves the store
+func (s *Swarm) setScheduleStatus(id, status string) {
+	s.schedulesMu.Lock()
+	defer s.schedulesMu.Unlock()
+
+	if entry, ok := s.schedules[id]; ok {
+		entry.LastStatus = status
+		s.saveSchedulesLocked()
+	}
+}
+
+// saveSchedulesLocked writes the schedules to the store file, if one is set.
+// The caller holds schedulesMu.
+func (s *Swarm) saveSchedulesLocked() {
+	if s.scheduleStore == "" {
+		return
+	}
+
+	list := make([]Schedule, 0, len(s.schedules))
+	for _, entry := range s.schedules {
+		list = append(list, entry.Schedule)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err == nil {
+		err = os.WriteFile(s.scheduleStore, data, 0644)
+	}
+	if err != nil {
+		slog.Error("saving schedules failed", "path", s.scheduleStore, logging.KeyError, err)
+	}
+}
@@ -0,0 +1,309 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// WorkflowPayloadKey is the task payload key naming the workflow run a task
+// belongs to. Restore leaves such tasks to the resumed workflow instead of
+// queueing them again.
+const WorkflowPayloadKey = "workflow_id"
+
+// Snapshot is a checkpoint of a swarm: which agents it had, the tasks that
+// hadn't finished, and how far each running workflow had got. Save one with
+// SaveSnapshot and hand it to Restore on a fresh swarm to pick a long run up
+// after a crash or deploy.
+type Snapshot struct {
+	Taken     time.Time          `json:"taken"`
+	Agents    []AgentRecord      `json:"agents"`
+	Tasks     []SnapshotTask     `json:"tasks"`     // Unfinished tasks, oldest first
+	Workflows []WorkflowProgress `json:"workflows"` // Unfinished workflow runs, oldest first
+}
+
+// AgentRecord is an agent's registration in a Snapshot
+type AgentRecord struct {
+	ID        string `json:"id"`
+	Specialty string `json:"specialty,omitempty"`
+	Paused    bool   `json:"paused,omitempty"`
+}
+
+// SnapshotTask is an unfinished task in a Snapshot
+type SnapshotTask struct {
+	QueuedTask
+	AgentID string `json:"agent_id,omitempty"` // Agent the task had been handed to; empty while it was queued
+}
+
+// WorkflowProgress is how far a workflow run has got. The workflow engine
+// records it after every step so a restored swarm can resume the run without
+// redoing finished steps.
+type WorkflowProgress struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	RunID      string                 `json:"run_id,omitempty"`
+	Definition json.RawMessage        `json:"definition"` // The workflow definition being run, as JSON
+	Inputs     map[string]string      `json:"inputs"`     // Resolved inputs
+	Completed  map[string]interface{} `json:"completed"`  // Output of each finished step by name; nil for a skipped optional step
+	Started    time.Time              `json:"started"`
+	Updated    time.Time              `json:"updated"`
+}
+
+// AgentBuilder creates the agent with the given ID and specialty, for
+// Restore to bring back agents the new swarm doesn't have yet
+type AgentBuilder func(id, specialty string) (types.Agent, error)
+
+// pendingTask is a task accepted by the swarm that hasn't finished
+type pendingTask struct {
+	item    QueuedTask
+	agentID string
+}
+
+// trackPending records an accepted task, or the agent it was handed to. A
+// task keeps the submission time it was first seen with.
+func (s *Swarm) trackPending(item QueuedTask, agentID string) {
+	s.recordTask(item, agentID)
+
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	if s.finishedEarly(item.Task.ID) {
+		return
+	}
+	if entry, ok := s.pending[item.Task.ID]; ok {
+		entry.agentID = agentID
+		return
+	}
+	s.pending[item.Task.ID] = &pendingTask{item: item, agentID: agentID}
+}
+
+// dropPending forgets a task that completed, failed, or was cancelled
+func (s *Swarm) dropPending(taskID string) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	delete(s.pending, taskID)
+}
+
+// holderOf returns the agent an unfinished task was handed to, "" while it
+// waits in the queue, and whether the task is unfinished at all
+func (s *Swarm) holderOf(taskID string) (string, bool) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	if entry, ok := s.pending[taskID]; ok {
+		return entry.agentID, true
+	}
+	return "", false
+}
+
+// runOf returns the RunID of an unfinished task, or ""
+func (s *Swarm) runOf(taskID string) string {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	if entry, ok := s.pending[taskID]; ok {
+		return entry.item.Task.RunID
+	}
+	return ""
+}
+
+// RecordWorkflow saves a workflow run's progress for the next Snapshot,
+// replacing what was recorded for the same run before
+func (s *Swarm) RecordWorkflow(progress WorkflowProgress) {
+	progress.Updated = time.Now()
+
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	s.workflows[progress.ID] = progress
+}
+
+// FinishWorkflow forgets a workflow run that completed or failed
+func (s *Swarm) FinishWorkflow(id string) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	delete(s.workflows, id)
+}
+
+// Workflows returns the progress of every unfinished workflow run, oldest
+// first. After Restore these are the runs to resume.
+func (s *Swarm) Workflows() []WorkflowProgress {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	list := make([]WorkflowProgress, 0, len(s.workflows))
+	for _, progress := range s.workflows {
+		list = append(list, progress)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.Before(list[j].Started) })
+	return list
+}
+
+// Snapshot captures the swarm's agents, unfinished tasks, and workflow
+// progress
+func (s *Swarm) Snapshot() *Snapshot {
+	snap := &Snapshot{Taken: time.Now(), Workflows: s.Workflows()}
+
+	s.mu.RLock()
+	for id, agent := range s.agents {
+		snap.Agents = append(snap.Agents, AgentRecord{
+			ID:        id,
+			Specialty: agent.GetSpecialty(),
+			Paused:    agent.GetState() == types.StatePaused,
+		})
+	}
+	s.mu.RUnlock()
+	sort.Slice(snap.Agents, func(i, j int) bool { return snap.Agents[i].ID < snap.Agents[j].ID })
+
+	s.snapshotMu.Lock()
+	for _, entry := range s.pending {
+		snap.Tasks = append(snap.Tasks, SnapshotTask{QueuedTask: entry.item, AgentID: entry.agentID})
+	}
+	s.snapshotMu.Unlock()
+	sort.Slice(snap.Tasks, func(i, j int) bool { return snap.Tasks[i].Submitted.Before(snap.Tasks[j].Submitted) })
+
+	return snap
+}
+
+// SaveSnapshot writes a Snapshot of the swarm to path as JSON. The file is
+// replaced in one step, so a crash mid-write leaves the previous checkpoint.
+func (s *Swarm) SaveSnapshot(path string) error {
+	data, err := json.MarshalIndent(s.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot reads a Snapshot written by SaveSnapshot. A missing file is
+// reported with an error satisfying errors.Is(err, os.ErrNotExist).
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// RunCheckpoints saves a Snapshot to path every interval until ctx is done.
+// Save once more on shutdown, before stopping the swarm, so the last
+// checkpoint still has the tasks that were running.
+func (s *Swarm) RunCheckpoints(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SaveSnapshot(path); err != nil {
+				slog.Warn("saving checkpoint failed", "path", path, logging.KeyError, err)
+			}
+		}
+	}
+}
+
+// Restore brings a swarm back to a Snapshot. Call it on a new swarm before
+// Start, once the agents it always has are added:
+//
+//   - Agents in the snapshot the swarm doesn't have are created with build
+//     (nil skips them), and agents that were paused are paused again.
+//   - Unfinished tasks are queued again, in submission order, and run from
+//     the start. Tasks of a workflow in the snapshot are left to that
+//     workflow, and tasks still waiting in a durable queue backend are
+//     already there.
+//   - Workflow progress is kept for Workflows; resume each run with the
+//     workflow engine.
+func (s *Swarm) Restore(snap *Snapshot, build AgentBuilder) error {
+	var errs []error
+
+	for _, record := range snap.Agents {
+		if _, err := s.GetAgent(record.ID); err != nil {
+			if build == nil {
+				slog.Warn("agent in snapshot not restored", logging.KeyAgent, record.ID, "specialty", record.Specialty)
+				continue
+			}
+			agent, err := build(record.ID, record.Specialty)
+			if err == nil {
+				err = s.AddAgent(agent)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("agent %s: %w", record.ID, err))
+				continue
+			}
+		}
+		if record.Paused {
+			if err := s.PauseAgent(record.ID); err != nil {
+				errs = append(errs, fmt.Errorf("agent %s: %w", record.ID, err))
+			}
+		}
+	}
+
+	resumed := make(map[string]bool, len(snap.Workflows))
+	s.snapshotMu.Lock()
+	for _, progress := range snap.Workflows {
+		s.workflows[progress.ID] = progress
+		resumed[progress.ID] = true
+	}
+	s.snapshotMu.Unlock()
+
+	inMemory := false
+	switch s.queue.(type) {
+	case memoryQueue, *fairQueue:
+		inMemory = true
+	}
+	queued := 0
+	for _, t := range snap.Tasks {
+		// A durable backend still holds the tasks it hadn't handed out,
+		// except urgent ones, which are only ever queued in memory
+		urgent := t.Priority == types.PriorityUrgent
+		if resumed[workflowOf(t.Task)] || (t.AgentID == "" && !inMemory && !urgent) {
+			continue
+		}
+		if t.Task.Submitted.IsZero() {
+			t.Task.Submitted = t.Submitted // Taken before tasks carried it
+		}
+		if err := s.queueFor(t.Priority).TryPush(t.QueuedTask); err != nil {
+			errs = append(errs, fmt.Errorf("task %s: %w", t.Task.ID, err))
+			continue
+		}
+		s.queued(t.QueuedTask)
+		queued++
+	}
+
+	slog.Info("swarm restored from snapshot", "taken", snap.Taken, "agents", len(snap.Agents), "tasks", queued, "workflows", len(snap.Workflows))
+	return errors.Join(errs...)
+}
+
+// workflowOf returns the ID of the workflow run task belongs to, or ""
+func workflowOf(task types.Task) string {
+	if payload, ok := task.Payload.(map[string]interface{}); ok {
+		id, _ := payload[WorkflowPayloadKey].(string)
+		return id
+	}
+	return ""
+}
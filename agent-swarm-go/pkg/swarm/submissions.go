@@ -0,0 +1,39 @@
+package swarm
+
+import "agent-swarm-go/pkg/types"
+
+// ObserveSubmissions has fn called with every task the swarm accepts from
+// Submit, DistributeTask, AssignTask, or their variants, in the order
+// accepted, e.g. to record a session for replay. Rejected and duplicate
+// submissions, and tasks restored from a snapshot, are not observed. fn runs
+// on the submitter's goroutine, so it must be quick. Call the returned
+// function to stop observing.
+func (s *Swarm) ObserveSubmissions(fn func(task types.Task, priority types.MessagePriority)) (stop func()) {
+	s.submittedMu.Lock()
+	defer s.submittedMu.Unlock()
+
+	id := s.nextObserver
+	s.nextObserver++
+	s.submittedObservers[id] = fn
+	return func() {
+		s.submittedMu.Lock()
+		defer s.submittedMu.Unlock()
+		delete(s.submittedObservers, id)
+	}
+}
+
+// submitted tells the submission observers about a task just accepted
+func (s *Swarm) submitted(task types.Task, priority types.MessagePriority) {
+	s.submittedMu.Lock()
+	observers := make([]func(types.Task, types.MessagePriority), 0, len(s.submittedObservers))
+	for id := 0; id < s.nextObserver; id++ {
+		if fn, ok := s.submittedObservers[id]; ok {
+			observers = append(observers, fn)
+		}
+	}
+	s.submittedMu.Unlock()
+
+	for _, fn := range observers {
+		fn(task, priority)
+	}
+}
@@ -3,8 +3,14 @@ package swarm
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"agent-swarm-go/pkg/logging"
 	"agent-swarm-go/pkg/types"
 )
 
@@ -15,14 +21,153 @@ type Swarm struct {
 	ctx      context.Context
 	cancel   context.CancelFunc
 	eventBus *types.EventBus
+
+	// In-flight tracking for visibility timeouts (see visibility.go)
+	inflight   map[string]*inflightTask
+	inflightMu sync.Mutex
+	visibility VisibilityConfig
+
+	// Tasks being handed to an agent, and whether each finished meanwhile
+	// (see handover)
+	dispatching   map[string]bool
+	dispatchingMu sync.Mutex
+
+	// Warm pools for dynamically spawned agents by specialty (see warmpool.go)
+	pools   map[string]*warmPool
+	poolsMu sync.Mutex
+
+	// Workflow steps waiting for a human decision (see approval.go)
+	approvals   map[string]*pendingApproval
+	approvalsMu sync.Mutex
+
+	// Cron schedules (see scheduler.go)
+	schedules      map[string]*scheduleEntry
+	schedulesMu    sync.Mutex
+	scheduleStore  string
+	workflowRunner WorkflowRunner
+
+	// Callback URLs of unfinished tasks, keyed by task ID (see callbacks.go)
+	callbacks   map[string]string
+	callbacksMu sync.Mutex
+
+	// Load balancing (see balance.go)
+	strategy  Strategy
+	turns     map[string]int // Next round-robin position, by requested specialty
+	balanceMu sync.Mutex
+
+	// Conversations between agents, by ID and in the order started (see threads.go)
+	threads     map[string]*types.Thread
+	threadOrder []string
+	threadsMu   sync.Mutex
+
+	// Bounded submission queue (see queue.go)
+	queue      QueueBackend
+	queueSize  int
+	queueStats queueStats
+	urgent     memoryQueue // Urgent tasks, distributed ahead of the queue (see urgent.go)
+	fair       *FairPolicy // Share the queue between task sources (see fair.go)
+
+	// Leader election among swarms sharing a queue (see leader.go)
+	leaderNode  string
+	leaderLock  LeaderLock
+	leading     atomic.Bool
+	leaderSince time.Time
+	leaderMu    sync.Mutex
+
+	// Unfinished tasks and workflow runs, for Snapshot (see snapshot.go)
+	pending    map[string]*pendingTask
+	workflows  map[string]WorkflowProgress
+	snapshotMu sync.Mutex
+
+	// Recent tasks and their status, in the order accepted (see tasks.go)
+	tasks     map[string]*TaskInfo
+	taskOrder []string
+	tasksMu   sync.Mutex
+
+	// Task results for AwaitResult, by task ID (see results.go)
+	results       map[string]*resultFuture
+	resultsPruned time.Time
+	resultsMu     sync.Mutex
+
+	// Queued tasks cancelled before an agent took them, by task ID (see control.go)
+	cancelled   map[string]time.Time
+	cancelledMu sync.Mutex
+
+	// Idempotency keys, and the key of each keyed task by task ID (see idempotency.go)
+	keys       map[taskKey]*keyedTask
+	keyTasks   map[string]taskKey
+	keysPruned time.Time
+	keysMu     sync.Mutex
+
+	// Tasks no agent could take, oldest first (see deadletter.go)
+	deadLetters []*deadLetter
+	deadMu      sync.Mutex
+
+	// Workflow runs in progress by ID, and the run of each step's task (see active.go)
+	active   map[string]*ActiveWorkflow
+	stepRuns map[string]string
+	activeMu sync.Mutex
+
+	// Called with each accepted task, by registration order (see submissions.go)
+	submittedObservers map[int]func(types.Task, types.MessagePriority)
+	nextObserver       int
+	submittedMu        sync.Mutex
+
+	// Memory shared by each run's agents, by run ID, and the runs used least
+	// recently first (see memory.go)
+	memories    map[string]*types.RunMemory
+	memoryOrder []string
+	memoriesMu  sync.Mutex
 }
 
-// NewSwarm creates a new agent swarm
-func NewSwarm() *Swarm {
-	return &Swarm{
+// NewSwarm creates a new agent swarm. Without options, tasks go to the
+// least-loaded agent (see WithStrategy) and up to DefaultQueueSize submitted
+// tasks can wait in the queue (see WithQueueSize).
+func NewSwarm(opts ...Option) *Swarm {
+	eventBus := types.NewEventBus()
+	s := &Swarm{
 		agents:   make(map[string]types.Agent),
-		eventBus: types.NewEventBus(),
+		eventBus: eventBus,
+		inflight: make(map[string]*inflightTask),
+		pools:    make(map[string]*warmPool),
+
+		dispatching: make(map[string]bool),
+
+		approvals: make(map[string]*pendingApproval),
+		schedules: make(map[string]*scheduleEntry),
+		callbacks: make(map[string]string),
+		threads:   make(map[string]*types.Thread),
+		pending:   make(map[string]*pendingTask),
+		workflows: make(map[string]WorkflowProgress),
+		tasks:     make(map[string]*TaskInfo),
+		results:   make(map[string]*resultFuture),
+		keys:      make(map[taskKey]*keyedTask),
+		keyTasks:  make(map[string]taskKey),
+		cancelled: make(map[string]time.Time),
+
+		active:             make(map[string]*ActiveWorkflow),
+		stepRuns:           make(map[string]string),
+		submittedObservers: make(map[int]func(types.Task, types.MessagePriority)),
+		memories:           make(map[string]*types.RunMemory),
+
+		strategy:  StrategyLeastLoaded,
+		turns:     make(map[string]int),
+		queueSize: DefaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if s.queue == nil && s.fair != nil {
+		s.queue = newFairQueue(*s.fair, s.queueSize)
+	} else if s.queue == nil {
+		s.queue = make(memoryQueue, s.queueSize)
+	}
+	s.urgent = make(memoryQueue, s.queueSize)
+	eventBus.Observe(s.handleEvent)
+	eventBus.Observe(s.recordResult)
+	eventBus.Observe(s.settleKey)
+	eventBus.Observe(s.trackWorkflow)
+	return s
 }
 
 // GetEventBus returns the event bus for subscribing to swarm events
@@ -41,24 +186,52 @@ func (s *Swarm) AddAgent(agent types.Agent) error {
 	}
 
 	s.agents[id] = agent
+	if t, ok := agent.(threaded); ok {
+		t.SetThreadRouter(s)
+	}
+	if m, ok := agent.(remembering); ok {
+		m.SetMemoryStore(s)
+	}
+	slog.Debug("agent added to swarm", logging.KeyAgent, id)
+	return nil
+}
+
+// JoinAgent adds an agent to the swarm and, if the swarm is already running,
+// starts it so it can take tasks straight away
+func (s *Swarm) JoinAgent(agent types.Agent) error {
+	if err := s.AddAgent(agent); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	ctx := s.ctx
+	s.mu.RUnlock()
+	if ctx != nil {
+		if err := agent.Start(ctx); err != nil {
+			s.RemoveAgent(agent.GetID())
+			return fmt.Errorf("error starting agent %s: %w", agent.GetID(), err)
+		}
+	}
 	return nil
 }
 
 // RemoveAgent removes an agent from the swarm
 func (s *Swarm) RemoveAgent(id string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	agent, exists := s.agents[id]
 	if !exists {
+		s.mu.Unlock()
 		return fmt.Errorf("agent with ID %s not found", id)
 	}
+	delete(s.agents, id)
+	s.mu.Unlock()
 
+	// Stop waits for the agent's tasks to finish, and they may need the swarm
 	if err := agent.Stop(); err != nil {
 		return fmt.Errorf("error stopping agent %s: %w", id, err)
 	}
 
-	delete(s.agents, id)
+	slog.Debug("agent removed from swarm", logging.KeyAgent, id)
 	return nil
 }
 
@@ -102,6 +275,21 @@ func (s *Swarm) Start(ctx context.Context) error {
 		}
 	}
 
+	// Redeliver tasks whose visibility timeout expires
+	go s.monitor(s.ctx)
+
+	// Campaign for leadership when other swarms share the queue
+	if s.leaderLock != nil {
+		go s.runElection(s.ctx)
+	}
+
+	// Fire cron schedules as they come due, on the leader only
+	go s.runScheduler(s.ctx)
+
+	// Hand submitted tasks to agents as their inboxes have room
+	go s.runQueue(s.ctx)
+	go s.runUrgent(s.ctx)
+
 	return nil
 }
 
@@ -112,12 +300,18 @@ func (s *Swarm) Stop() error {
 		s.cancel()
 	}
 	s.mu.Unlock()
+	s.closePools()
 
+	// Stop the agents outside the lock; their tasks may need the swarm to finish
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	agents := make(map[string]types.Agent, len(s.agents))
+	for id, agent := range s.agents {
+		agents[id] = agent
+	}
+	s.mu.RUnlock()
 
 	var errs []error
-	for id, agent := range s.agents {
+	for id, agent := range agents {
 		if err := agent.Stop(); err != nil {
 			errs = append(errs, fmt.Errorf("error stopping agent %s: %w", id, err))
 		}
@@ -173,23 +367,228 @@ func (s *Swarm) SendMessage(from, to string, content interface{}, msgType types.
 
 // DistributeTask distributes a task to an available agent
 func (s *Swarm) DistributeTask(task types.Task) error {
+	return s.DistributeTaskWithPriority(task, types.PriorityDefault)
+}
+
+// DistributeTaskWithPriority distributes a task to an available agent on the
+// given inbox lane. Use PriorityUrgent for tasks that should preempt queued
+// work and PriorityLow for bulk work that should not delay anything else.
+// A task no agent can take is dead-lettered (see DeadLetters) as well as
+// returning the error. A task whose idempotency key was already submitted
+// isn't distributed again (see DuplicateTaskError).
+func (s *Swarm) DistributeTaskWithPriority(task types.Task, priority types.MessagePriority) error {
+	task = stampSubmitted(task)
+	if err := s.claimKey(task); err != nil {
+		return err
+	}
+	if err := s.distribute(task, priority); err != nil {
+		s.releaseKey(task)
+		s.deadLetterTask(task, priority, err)
+		return err
+	}
+	s.submitted(task, priority)
+	return nil
+}
+
+// distribute hands a task to an available agent and starts tracking it
+func (s *Swarm) distribute(task types.Task, priority types.MessagePriority) error {
+	// Registered first so even an instant result finds its callback
+	s.registerCallback(task)
+	defer s.handover(task.ID)()
+	agentID, err := s.dispatch(task, priority, "")
+	if err != nil {
+		s.dropCallback(task.ID)
+		return err
+	}
+
+	s.trackInflight(task, priority, agentID)
+	s.trackPending(QueuedTask{Task: task, Priority: priority, Submitted: task.Submitted}, agentID)
+	return nil
+}
+
+// AssignTask sends a task to one particular agent instead of letting the swarm
+// pick, e.g. to keep a conversation with the same agent. It fails if the
+// agent is unknown, paused, or stopped.
+func (s *Swarm) AssignTask(agentID string, task types.Task) error {
+	s.mu.RLock()
+	agent, exists := s.agents[agentID]
+	s.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("agent with ID %s not found", agentID)
+	}
+	if state := agent.GetState(); state != types.StateIdle && state != types.StateProcessing {
+		return fmt.Errorf("agent %s is %s", agentID, state)
+	}
+
+	task = stampSubmitted(task)
+	if err := s.claimKey(task); err != nil {
+		return err
+	}
+	s.registerCallback(task)
+	defer s.handover(task.ID)()
+	if err := s.send(agent, task, types.PriorityDefault); err != nil {
+		s.dropCallback(task.ID)
+		s.releaseKey(task)
+		return err
+	}
+
+	s.trackInflight(task, types.PriorityDefault, agentID)
+	s.trackPending(QueuedTask{Task: task, Priority: types.PriorityDefault, Submitted: task.Submitted}, agentID)
+	s.submitted(task, types.PriorityDefault)
+	return nil
+}
+
+// dispatch sends a task to an available agent, skipping the agent with ID
+// exclude when another one is available, and returns the chosen agent's ID.
+//
+// Only agents with every capability in task.Requires are considered. When the
+// task requests a specialty (Payload["agent_type"]) an agent with that
+// specialty is preferred; if none is available any agent may take the task.
+// Among the agents that qualify, the swarm's Strategy chooses.
+func (s *Swarm) dispatch(task types.Task, priority types.MessagePriority, exclude string) (string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// Find an idle agent
+	var fallback types.Agent
+	var matching, others []types.Agent
+	wanted := task.AgentType()
+
+	// Only agents that are running and not paused take tasks
 	for _, agent := range s.agents {
 		if agent.GetState() == types.StateIdle || agent.GetState() == types.StateProcessing {
-			msg := types.Message{
-				From:    "swarm",
-				To:      agent.GetID(),
-				Content: task,
-				Type:    types.MessageTypeTask,
+			if !capable(agent, task.Requires) {
+				continue
+			}
+			if agent.GetID() == exclude {
+				fallback = agent
+				continue
 			}
-			return agent.SendMessage(msg)
+			if wanted == "" || agent.GetSpecialty() == wanted {
+				matching = append(matching, agent)
+			} else {
+				others = append(others, agent)
+			}
+		}
+	}
+
+	if len(matching) > 0 {
+		agent := s.pick(matching, wanted)
+		return agent.GetID(), s.send(agent, task, priority)
+	}
+
+	// Nobody has the requested specialty: any other available agent will do
+	if len(others) > 0 {
+		agent := s.pick(others, "")
+		return agent.GetID(), s.send(agent, task, priority)
+	}
+
+	// Only the excluded agent is available: better to retry there than not at all
+	if fallback != nil {
+		return fallback.GetID(), s.send(fallback, task, priority)
+	}
+
+	if len(task.Requires) > 0 && !s.anyCapable(task.Requires) {
+		return "", fmt.Errorf("task %s requires %s: %w", task.ID, strings.Join(task.Requires, ", "), ErrNoCapableAgent)
+	}
+	slog.Warn("no available agents to handle task", logging.KeyTask, task.ID)
+	return "", fmt.Errorf("no available agents to handle task")
+}
+
+// HasSpecialty reports whether any agent in the swarm declares the given specialty
+func (s *Swarm) HasSpecialty(specialty string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, agent := range s.agents {
+		if agent.GetSpecialty() == specialty {
+			return true
+		}
+	}
+	return false
+}
+
+// Specialties lists the distinct specialties of the swarm's agents, sorted
+func (s *Swarm) Specialties() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var specialties []string
+	for _, agent := range s.agents {
+		if specialty := agent.GetSpecialty(); specialty != "" && !seen[specialty] {
+			seen[specialty] = true
+			specialties = append(specialties, specialty)
+		}
+	}
+	sort.Strings(specialties)
+	return specialties
+}
+
+// AgentSpecialties returns every agent's specialty, keyed by agent ID
+func (s *Swarm) AgentSpecialties() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	specialties := make(map[string]string, len(s.agents))
+	for id, agent := range s.agents {
+		specialties[id] = agent.GetSpecialty()
+	}
+	return specialties
+}
+
+// send delivers a task message to a specific agent on the given lane
+func (s *Swarm) send(agent types.Agent, task types.Task, priority types.MessagePriority) error {
+	slog.Debug("task distributed", logging.KeyTask, task.ID, logging.KeyAgent, agent.GetID(), "lane", priority)
+	if _, ok := agent.(remembering); !ok {
+		task.Context = s.memoryOf(task.RunID).Resolve(task.Context) // e.g. an agent in another process
+	}
+	msg := types.Message{
+		From:     "swarm",
+		To:       agent.GetID(),
+		Content:  task,
+		Type:     types.MessageTypeTask,
+		Priority: priority,
+	}
+	return agent.SendMessage(msg)
+}
+
+// monitor periodically checks in-flight tasks for expired visibility
+// timeouts. It runs until ctx is done.
+func (s *Swarm) monitor(ctx context.Context) {
+	ticker := time.NewTicker(visibilityCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.redeliverExpired(now)
 		}
 	}
+}
+
+// handleEvent updates swarm bookkeeping from a single event. It observes the
+// event bus (see types.EventBus.Observe), so no completion is missed when the
+// bus is busy.
+func (s *Swarm) handleEvent(event types.Event) {
+	if event.TaskID == "" {
+		return
+	}
 
-	return fmt.Errorf("no available agents to handle task")
+	switch event.Type {
+	case types.EventTaskStarted:
+		s.touchInflight(event.TaskID, event.AgentID)
+		s.updateTask(event)
+	case types.EventTaskProgress, types.EventTaskOutput:
+		s.touchInflight(event.TaskID, event.AgentID)
+	case types.EventTaskCompleted, types.EventTaskFailed:
+		s.noteFinished(event.TaskID)
+		s.updateTask(event)
+		s.completeInflight(event.TaskID)
+		s.dropPending(event.TaskID)
+		s.fireCallback(event)
+	}
 }
 
 // GetSwarmStatus returns the status of all agents
@@ -0,0 +1,176 @@
+package swarm
+
+import (
+	"sort"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// maxTaskHistory is how many tasks Tasks remembers; the oldest finished tasks
+// are forgotten first
+const maxTaskHistory = 1000
+
+// Task statuses reported by Tasks
+const (
+	TaskQueued    = "queued"    // Accepted, not yet started by an agent
+	TaskRunning   = "running"   // An agent is working on it
+	TaskCompleted = "completed" // Finished successfully
+	TaskFailed    = "failed"    // Finished with an error, timed out, or was cancelled
+)
+
+// TaskInfo describes a task the swarm has accepted
+type TaskInfo struct {
+	ID           string     `json:"id"`
+	Description  string     `json:"description,omitempty"`
+	Status       string     `json:"status"`
+	AgentID      string     `json:"agent_id,omitempty"` // Agent the task was handed to; empty while it waits in the queue
+	RunID        string     `json:"run_id,omitempty"`
+	Locale       string     `json:"locale,omitempty"` // Language the agent was asked to answer in
+	Submitted    time.Time  `json:"submitted"`
+	Started      *time.Time `json:"started,omitempty"`
+	Finished     *time.Time `json:"finished,omitempty"`
+	WaitSecs     float64    `json:"wait_seconds,omitempty"`     // Submitted to Started, or to now while queued
+	DurationSecs float64    `json:"duration_seconds,omitempty"` // Started to Finished, or to now while running
+	Error        string     `json:"error,omitempty"`
+}
+
+// TaskFilter selects tasks from Tasks. Empty fields match every task.
+type TaskFilter struct {
+	Status  string // One of TaskQueued, TaskRunning, TaskCompleted, TaskFailed
+	AgentID string
+}
+
+// ValidTaskStatus reports whether status is one a TaskFilter can select
+func ValidTaskStatus(status string) bool {
+	switch status {
+	case TaskQueued, TaskRunning, TaskCompleted, TaskFailed:
+		return true
+	}
+	return false
+}
+
+// recordTask notes an accepted task, or the agent it was handed to. A task
+// ID accepted again after it finished starts a new entry.
+func (s *Swarm) recordTask(item QueuedTask, agentID string) {
+	s.tasksMu.Lock()
+	defer s.tasksMu.Unlock()
+
+	if s.finishedEarly(item.Task.ID) {
+		return
+	}
+	if info, ok := s.tasks[item.Task.ID]; ok && info.Finished == nil {
+		if agentID != "" {
+			info.AgentID = agentID
+		}
+		return
+	}
+
+	if _, ok := s.tasks[item.Task.ID]; !ok {
+		s.taskOrder = append(s.taskOrder, item.Task.ID)
+	}
+	s.tasks[item.Task.ID] = &TaskInfo{
+		ID:          item.Task.ID,
+		Description: item.Task.Description,
+		Status:      TaskQueued,
+		AgentID:     agentID,
+		RunID:       item.Task.RunID,
+		Locale:      item.Task.Locale,
+		Submitted:   item.Submitted,
+	}
+	s.pruneTasks()
+}
+
+// pruneTasks forgets the oldest finished tasks beyond maxTaskHistory. Tasks
+// still queued or running are always kept. Callers hold tasksMu.
+func (s *Swarm) pruneTasks() {
+	excess := len(s.tasks) - maxTaskHistory
+	if excess <= 0 {
+		return
+	}
+
+	kept := s.taskOrder[:0]
+	for _, id := range s.taskOrder {
+		if excess > 0 && s.tasks[id].Finished != nil {
+			delete(s.tasks, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.taskOrder = kept
+}
+
+// updateTask moves a task along from a started, completed, or failed event.
+// Tasks first seen here (handed to agents outside the swarm) are added.
+func (s *Swarm) updateTask(event types.Event) {
+	s.tasksMu.Lock()
+	defer s.tasksMu.Unlock()
+
+	info, ok := s.tasks[event.TaskID]
+	if !ok {
+		info = &TaskInfo{ID: event.TaskID, Status: TaskQueued, RunID: event.RunID, Submitted: event.Timestamp}
+		s.tasks[event.TaskID] = info
+		s.taskOrder = append(s.taskOrder, event.TaskID)
+		defer s.pruneTasks()
+	}
+	if info.Finished != nil {
+		return
+	}
+	if event.AgentID != "" && event.AgentID != "swarm" {
+		info.AgentID = event.AgentID
+	}
+
+	at := event.Timestamp
+	switch event.Type {
+	case types.EventTaskStarted:
+		info.Status = TaskRunning
+		info.Started = &at
+	case types.EventTaskCompleted, types.EventTaskFailed:
+		info.Status = TaskCompleted
+		if event.Type == types.EventTaskFailed {
+			info.Status = TaskFailed
+		}
+		if r, ok := event.Data.(types.Result); ok {
+			if !r.Success {
+				info.Status = TaskFailed
+			}
+			if r.Error != nil {
+				info.Error = r.Error.Error()
+			}
+		}
+		info.Finished = &at
+	}
+}
+
+// Tasks lists the tasks the swarm knows about, newest first: those queued or
+// running now and the most recent finished ones
+func (s *Swarm) Tasks(filter TaskFilter) []TaskInfo {
+	now := time.Now()
+
+	s.tasksMu.Lock()
+	list := make([]TaskInfo, 0, len(s.tasks))
+	for _, info := range s.tasks {
+		if (filter.Status != "" && info.Status != filter.Status) || (filter.AgentID != "" && info.AgentID != filter.AgentID) {
+			continue
+		}
+		task := *info
+		switch {
+		case task.Started != nil && task.Finished != nil:
+			task.DurationSecs = task.Finished.Sub(*task.Started).Seconds()
+		case task.Started != nil:
+			task.DurationSecs = now.Sub(*task.Started).Seconds()
+		}
+		switch {
+		case task.Started != nil:
+			task.WaitSecs = task.Started.Sub(task.Submitted).Seconds()
+		case task.Finished == nil:
+			task.WaitSecs = now.Sub(task.Submitted).Seconds()
+		}
+		list = append(list, task)
+	}
+	s.tasksMu.Unlock()
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Submitted.After(list[j].Submitted) })
+	return list
+}
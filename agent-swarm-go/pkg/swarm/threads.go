@@ -0,0 +1,223 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// maxThreads is how many threads the swarm remembers; the oldest are
+// forgotten first
+const maxThreads = 200
+
+// threaded is implemented by agents that can take part in threads (every
+// agent embedding agent.BaseAgent)
+type threaded interface {
+	SetThreadRouter(router types.ThreadRouter)
+	AnswersQuestions() bool
+}
+
+// Ask routes a question from one agent to another and waits for the answer.
+// It implements types.ThreadRouter; agents call it through BaseAgent.Ask.
+//
+// q.To is an agent ID or a specialty; for a specialty the first idle agent
+// with it is asked, or the first busy one if none is idle. The question goes
+// on the high lane of the agent's inbox, so it is answered as soon as the
+// agent finishes its current message. Both the question and the answer are
+// recorded in the thread and published as thread_message events.
+func (s *Swarm) Ask(ctx context.Context, q types.ThreadQuestion) (types.ThreadMessage, error) {
+	target, err := s.answerer(q.From, q.To)
+	if err != nil {
+		return types.ThreadMessage{}, err
+	}
+
+	question := types.ThreadMessage{From: q.From, To: target.GetID(), Text: q.Text, Timestamp: time.Now()}
+	history, err := s.addToThread(q.ThreadID, q.TaskID, &question)
+	if err != nil {
+		return types.ThreadMessage{}, err
+	}
+
+	reply := make(chan types.QueryAnswer, 1)
+	err = target.SendMessage(types.Message{
+		From: q.From,
+		To:   question.To,
+		Type: types.MessageTypeQuery,
+		Content: types.Query{
+			ThreadID: question.ThreadID,
+			From:     q.From,
+			Question: q.Text,
+			History:  history,
+			Reply:    reply,
+		},
+		Priority: types.PriorityHigh,
+	})
+	if err != nil {
+		return types.ThreadMessage{}, fmt.Errorf("asking %s: %w", question.To, err)
+	}
+
+	select {
+	case answer := <-reply:
+		if answer.Err != nil {
+			return types.ThreadMessage{}, fmt.Errorf("%s could not answer: %w", question.To, answer.Err)
+		}
+		message := types.ThreadMessage{ThreadID: question.ThreadID, From: question.To, To: q.From, Text: answer.Text, Timestamp: time.Now()}
+		if _, err := s.addToThread(question.ThreadID, q.TaskID, &message); err != nil {
+			return types.ThreadMessage{}, err
+		}
+		return message, nil
+	case <-ctx.Done():
+		return types.ThreadMessage{}, fmt.Errorf("no answer from %s: %w", question.To, ctx.Err())
+	}
+}
+
+// answerer picks the agent to put a question to: the agent with ID to, or
+// otherwise an agent with the specialty to, preferring idle ones. An agent
+// never answers its own questions.
+func (s *Swarm) answerer(from, to string) (types.Agent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if agent, ok := s.agents[to]; ok {
+		if to == from {
+			return nil, fmt.Errorf("agent %s cannot ask itself", from)
+		}
+		if t, ok := agent.(threaded); !ok || !t.AnswersQuestions() {
+			return nil, fmt.Errorf("agent %s does not answer questions", to)
+		}
+		return agent, nil
+	}
+
+	ids := make([]string, 0, len(s.agents))
+	for id := range s.agents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var busy types.Agent
+	for _, id := range ids {
+		agent := s.agents[id]
+		if id == from || agent.GetSpecialty() != to {
+			continue
+		}
+		if t, ok := agent.(threaded); !ok || !t.AnswersQuestions() {
+			continue
+		}
+		switch agent.GetState() {
+		case types.StateIdle:
+			return agent, nil
+		case types.StateProcessing:
+			if busy == nil {
+				busy = agent
+			}
+		}
+	}
+	if busy != nil {
+		return busy, nil
+	}
+	return nil, fmt.Errorf("no agent %q to answer questions", to)
+}
+
+// addToThread appends a message to its thread, starting a new thread when
+// threadID is empty, and announces it. It sets msg.ThreadID and returns the
+// messages that came before it.
+func (s *Swarm) addToThread(threadID, taskID string, msg *types.ThreadMessage) ([]types.ThreadMessage, error) {
+	s.threadsMu.Lock()
+	thread, ok := s.threads[threadID]
+	switch {
+	case threadID == "":
+		thread = &types.Thread{
+			ID:           fmt.Sprintf("thread-%d", time.Now().UnixNano()),
+			TaskID:       taskID,
+			Participants: []string{msg.From, msg.To},
+			Started:      msg.Timestamp,
+		}
+		s.threads[thread.ID] = thread
+		s.threadOrder = append(s.threadOrder, thread.ID)
+		if len(s.threadOrder) > maxThreads {
+			delete(s.threads, s.threadOrder[0])
+			s.threadOrder = s.threadOrder[1:]
+		}
+	case !ok:
+		s.threadsMu.Unlock()
+		return nil, fmt.Errorf("no thread %s", threadID)
+	}
+
+	for _, agentID := range []string{msg.From, msg.To} {
+		if !containsAgent(thread.Participants, agentID) {
+			thread.Participants = append(thread.Participants, agentID)
+		}
+	}
+	history := append([]types.ThreadMessage(nil), thread.Messages...)
+	msg.ThreadID = thread.ID
+	thread.Messages = append(thread.Messages, *msg)
+	s.threadsMu.Unlock()
+
+	slog.Info("thread message", "thread", msg.ThreadID, logging.KeyAgent, msg.From, "to", msg.To)
+	s.eventBus.Publish(types.Event{
+		Type:      types.EventThreadMessage,
+		Timestamp: msg.Timestamp,
+		AgentID:   msg.From,
+		TaskID:    taskID,
+		Message:   fmt.Sprintf("💬 %s → %s: %s", msg.From, msg.To, truncate(msg.Text, 120)),
+		Data:      *msg,
+		RunID:     s.runOf(taskID),
+	})
+	return history, nil
+}
+
+// containsAgent reports whether ids contains id
+func containsAgent(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate flattens s onto one line and shortens it to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "…"
+}
+
+// Threads returns the conversations between agents, newest first
+func (s *Swarm) Threads() []types.Thread {
+	s.threadsMu.Lock()
+	defer s.threadsMu.Unlock()
+
+	threads := make([]types.Thread, 0, len(s.threadOrder))
+	for i := len(s.threadOrder) - 1; i >= 0; i-- {
+		threads = append(threads, copyThread(s.threads[s.threadOrder[i]]))
+	}
+	return threads
+}
+
+// Thread returns one conversation by ID
+func (s *Swarm) Thread(id string) (types.Thread, bool) {
+	s.threadsMu.Lock()
+	defer s.threadsMu.Unlock()
+
+	thread, ok := s.threads[id]
+	if !ok {
+		return types.Thread{}, false
+	}
+	return copyThread(thread), true
+}
+
+// copyThread copies a thread so callers can't race with new messages
+func copyThread(t *types.Thread) types.Thread {
+	c := *t
+	c.Participants = append([]string(nil), t.Participants...)
+	c.Messages = append([]types.ThreadMessage(nil), t.Messages...)
+	return c
+}
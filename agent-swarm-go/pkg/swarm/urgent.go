@@ -0,0 +1,64 @@
+package swarm
+
+import (
+	"context"
+	"log/slog"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// Urgent tasks (types.PriorityUrgent) skip the submission queue: they wait in
+// a queue of their own, held in memory even when the swarm uses a durable
+// backend, which is distributed alongside the main one rather than behind it.
+// On the agent they go on the urgent lane, so they are taken ahead of
+// everything already in its inbox. QueueStats counts how often either
+// happens.
+
+// queueFor returns the queue a task submitted with priority waits in
+func (s *Swarm) queueFor(priority types.MessagePriority) QueueBackend {
+	if priority == types.PriorityUrgent {
+		return s.urgent
+	}
+	return s.queue
+}
+
+// runUrgent distributes urgent tasks as they are submitted, without waiting
+// for the tasks queued before them. It runs until ctx is done.
+func (s *Swarm) runUrgent(ctx context.Context) {
+	for {
+		item, err := s.urgent.Pop(ctx)
+		if err != nil {
+			return
+		}
+
+		ahead := s.queue.Len()
+		if !s.distributeQueued(ctx, item) {
+			return
+		}
+		if ahead > 0 {
+			s.queueStats.preempted.Add(1)
+			slog.Debug("urgent task jumped the queue", logging.KeyTask, item.Task.ID, "ahead", ahead)
+		}
+	}
+}
+
+// preemptionCounter is implemented by agents that count the urgent messages
+// they took ahead of waiting ones (every agent embedding agent.BaseAgent)
+type preemptionCounter interface {
+	Preemptions() int64
+}
+
+// inboxPreemptions sums the agents' preemption counts
+func (s *Swarm) inboxPreemptions() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var total int64
+	for _, agent := range s.agents {
+		if c, ok := agent.(preemptionCounter); ok {
+			total += c.Preemptions()
+		}
+	}
+	return total
+}
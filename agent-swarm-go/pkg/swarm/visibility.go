@@ -0,0 +1,238 @@
+package swarm
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// visibilityCheckInterval is how often the monitor looks for expired tasks
+const visibilityCheckInterval = time.Second
+
+// defaultMaxReceives is used when VisibilityConfig.MaxReceives is not set
+const defaultMaxReceives = 3
+
+// VisibilityConfig controls SQS-style visibility timeouts for dispatched tasks.
+//
+// Once a task is handed to an agent it becomes invisible to the rest of the
+// swarm for Timeout. The clock is reset whenever the agent reports progress
+// (EventTaskStarted, EventTaskProgress, or EventTaskOutput). If neither a completion nor a failure event arrives in
+// time, the task becomes visible again after RetryDelay and is redelivered,
+// preferably to a different agent. This guards against agents that hang or
+// die mid-task without ever reporting failure.
+//
+// After MaxReceives deliveries the swarm gives up, publishes EventTaskFailed
+// on the task's behalf so waiting workflows are released, and dead-letters
+// the task (see DeadLetters).
+type VisibilityConfig struct {
+	Timeout     time.Duration // Time without progress before redelivery (0 disables tracking)
+	MaxReceives int           // Total deliveries allowed per task (default 3)
+	RetryDelay  time.Duration // Base delay before an expired task is redelivered, multiplied by attempt
+}
+
+// inflightTask is a dispatched task awaiting a completion or failure event
+type inflightTask struct {
+	task      types.Task
+	priority  types.MessagePriority
+	agentID   string    // Agent currently holding the task
+	receives  int       // Number of deliveries so far
+	deadline  time.Time // When the visibility timeout expires (zero while waiting for redelivery)
+	visibleAt time.Time // When an expired task is redelivered (zero while invisible)
+}
+
+// SetVisibilityTimeout enables (or, with a zero Timeout, disables) visibility
+// timeout tracking for tasks distributed from now on.
+func (s *Swarm) SetVisibilityTimeout(cfg VisibilityConfig) {
+	if cfg.MaxReceives <= 0 {
+		cfg.MaxReceives = defaultMaxReceives
+	}
+
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	s.visibility = cfg
+}
+
+// VisibilityTimeout returns the visibility timeout settings in effect; a
+// zero Timeout means tasks aren't tracked
+func (s *Swarm) VisibilityTimeout() VisibilityConfig {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	return s.visibility
+}
+
+// InflightCount returns the number of dispatched tasks still awaiting completion
+func (s *Swarm) InflightCount() int {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	return len(s.inflight)
+}
+
+// handover marks a task as being handed to an agent until the returned
+// function is called, once the handover is recorded. An agent may finish the
+// task before then; its completion only finds what was recorded already, so
+// recording the rest must be skipped (see finishedEarly).
+func (s *Swarm) handover(taskID string) func() {
+	s.dispatchingMu.Lock()
+	s.dispatching[taskID] = false
+	s.dispatchingMu.Unlock()
+
+	return func() {
+		s.dispatchingMu.Lock()
+		delete(s.dispatching, taskID)
+		s.dispatchingMu.Unlock()
+	}
+}
+
+// noteFinished records that a task finished, in case it is still being handed
+// over. It is called before the task's bookkeeping is cleared.
+func (s *Swarm) noteFinished(taskID string) {
+	s.dispatchingMu.Lock()
+	defer s.dispatchingMu.Unlock()
+	if _, ok := s.dispatching[taskID]; ok {
+		s.dispatching[taskID] = true
+	}
+}
+
+// finishedEarly reports whether a task finished while being handed over, so
+// recording the handover would bring back what its completion cleared.
+// Callers hold the lock of what they record, which the completion takes
+// after noteFinished.
+func (s *Swarm) finishedEarly(taskID string) bool {
+	s.dispatchingMu.Lock()
+	defer s.dispatchingMu.Unlock()
+	return s.dispatching[taskID]
+}
+
+// trackInflight records a freshly dispatched task
+func (s *Swarm) trackInflight(task types.Task, priority types.MessagePriority, agentID string) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	if s.visibility.Timeout <= 0 || s.finishedEarly(task.ID) {
+		return
+	}
+
+	s.inflight[task.ID] = &inflightTask{
+		task:     task,
+		priority: priority,
+		agentID:  agentID,
+		receives: 1,
+		deadline: time.Now().Add(s.visibility.Timeout),
+	}
+}
+
+// touchInflight extends the visibility timeout when an agent reports progress
+func (s *Swarm) touchInflight(taskID, agentID string) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	if entry, ok := s.inflight[taskID]; ok && entry.visibleAt.IsZero() {
+		entry.agentID = agentID
+		entry.deadline = time.Now().Add(s.visibility.Timeout)
+	}
+}
+
+// inflightAgent returns the agent a task was last delivered to, or "" when
+// it isn't tracked
+func (s *Swarm) inflightAgent(taskID string) string {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	if entry, ok := s.inflight[taskID]; ok {
+		return entry.agentID
+	}
+	return ""
+}
+
+// completeInflight stops tracking a task that completed or failed
+func (s *Swarm) completeInflight(taskID string) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	delete(s.inflight, taskID)
+}
+
+// redeliverExpired moves expired tasks to the delayed retry queue, redelivers
+// tasks whose retry delay has elapsed, and fails tasks that exhausted their
+// receive budget.
+func (s *Swarm) redeliverExpired(now time.Time) {
+	var due, exhausted []*inflightTask
+
+	s.inflightMu.Lock()
+	cfg := s.visibility
+	for id, entry := range s.inflight {
+		if entry.visibleAt.IsZero() && now.After(entry.deadline) {
+			if entry.receives >= cfg.MaxReceives {
+				delete(s.inflight, id)
+				exhausted = append(exhausted, entry)
+				continue
+			}
+			// Timed out: make the task visible again after a backoff
+			entry.deadline = time.Time{}
+			entry.visibleAt = now.Add(cfg.RetryDelay * time.Duration(entry.receives))
+			slog.Warn("task visibility timeout expired", logging.KeyTask, id, logging.KeyAgent, entry.agentID, "receives", entry.receives)
+		}
+		if !entry.visibleAt.IsZero() && !now.Before(entry.visibleAt) {
+			due = append(due, entry)
+		}
+	}
+	s.inflightMu.Unlock()
+
+	for _, entry := range due {
+		s.redeliver(entry, now)
+	}
+
+	for _, entry := range exhausted {
+		message := fmt.Sprintf("⏱️  Task %s abandoned after %d deliveries without completing", entry.task.ID, entry.receives)
+		slog.Error("task abandoned after visibility timeouts", logging.KeyTask, entry.task.ID, "receives", entry.receives)
+		s.eventBus.Publish(types.Event{
+			Type:      types.EventTaskFailed,
+			Timestamp: now,
+			AgentID:   "swarm",
+			TaskID:    entry.task.ID,
+			Message:   message,
+			Data: types.Result{
+				TaskID:  entry.task.ID,
+				Success: false,
+				Data:    message,
+				RunID:   entry.task.RunID,
+			},
+			RunID: entry.task.RunID,
+		})
+		s.deadLetterTask(entry.task, entry.priority, fmt.Errorf("abandoned after %d deliveries without completing", entry.receives))
+	}
+}
+
+// redeliver dispatches a visible task again, preferring a different agent
+func (s *Swarm) redeliver(entry *inflightTask, now time.Time) {
+	s.inflightMu.Lock()
+	previous := entry.agentID
+	s.inflightMu.Unlock()
+
+	agentID, err := s.dispatch(entry.task, entry.priority, previous)
+
+	s.inflightMu.Lock()
+	if err != nil {
+		// No agent could take it; stay visible and try again on a later tick
+		entry.visibleAt = now.Add(s.visibility.RetryDelay)
+		s.inflightMu.Unlock()
+		slog.Warn("task redelivery failed", logging.KeyTask, entry.task.ID, logging.KeyError, err)
+		return
+	}
+	entry.agentID = agentID
+	entry.receives++
+	entry.visibleAt = time.Time{}
+	entry.deadline = now.Add(s.visibility.Timeout)
+	receives := entry.receives
+	s.inflightMu.Unlock()
+
+	s.eventBus.Publish(types.Event{
+		Type:      types.EventTaskRedelivered,
+		Timestamp: now,
+		AgentID:   agentID,
+		TaskID:    entry.task.ID,
+		Message:   fmt.Sprintf("🔁 Redelivered task %s (delivery %d, previously on %s)", entry.task.ID, receives, previous),
+		RunID:     entry.task.RunID,
+	})
+}
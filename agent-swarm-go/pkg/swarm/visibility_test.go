@@ -0,0 +1,143 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// fakeAgent is an idle agent that records the tasks it is sent and, when
+// complete is set, finishes each one before SendMessage returns
+type fakeAgent struct {
+	id       string
+	eventBus *types.EventBus
+	complete bool
+
+	mu    sync.Mutex
+	tasks []string
+}
+
+func (a *fakeAgent) GetID() string                          { return a.id }
+func (a *fakeAgent) Start(ctx context.Context) error        { return nil }
+func (a *fakeAgent) Stop() error                            { return nil }
+func (a *fakeAgent) ReceiveMessage() (*types.Message, bool) { return nil, false }
+func (a *fakeAgent) ProcessTask(task types.Task) types.Result {
+	return types.Result{TaskID: task.ID, Success: true}
+}
+func (a *fakeAgent) GetState() types.AgentState { return types.StateIdle }
+func (a *fakeAgent) GetSpecialty() string       { return "" }
+
+func (a *fakeAgent) SendMessage(msg types.Message) error {
+	task := msg.Content.(types.Task)
+	a.mu.Lock()
+	a.tasks = append(a.tasks, task.ID)
+	a.mu.Unlock()
+	if a.complete {
+		a.eventBus.Publish(types.Event{
+			Type:      types.EventTaskCompleted,
+			Timestamp: time.Now(),
+			AgentID:   a.id,
+			TaskID:    task.ID,
+			Data:      types.Result{TaskID: task.ID, Success: true},
+		})
+	}
+	return nil
+}
+
+func (a *fakeAgent) received() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.tasks...)
+}
+
+func newTestSwarm(t *testing.T, cfg VisibilityConfig, agents ...*fakeAgent) *Swarm {
+	t.Helper()
+	s := NewSwarm()
+	s.SetVisibilityTimeout(cfg)
+	for _, a := range agents {
+		a.eventBus = s.GetEventBus()
+		if err := s.AddAgent(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestCompletionSettlesTaskWhileSubscribersLag(t *testing.T) {
+	agent := &fakeAgent{id: "agent-1", complete: true}
+	s := newTestSwarm(t, VisibilityConfig{Timeout: time.Minute}, agent)
+	s.GetEventBus().SubscribeWithBuffer(1) // Never read, so it fills at once
+
+	for i := 0; i < 50; i++ {
+		if err := s.DistributeTaskWithPriority(types.Task{ID: fmt.Sprintf("task-%d", i)}, types.PriorityNormal); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := s.InflightCount(); n != 0 {
+		t.Errorf("%d tasks still in flight; want 0", n)
+	}
+	if queued := s.Tasks(TaskFilter{Status: TaskQueued}); len(queued) != 0 {
+		t.Errorf("%d tasks still queued; want 0", len(queued))
+	}
+	if done := s.Tasks(TaskFilter{Status: TaskCompleted}); len(done) != 50 {
+		t.Errorf("%d tasks completed; want 50", len(done))
+	}
+	if _, pending := s.holderOf("task-0"); pending {
+		t.Error("task-0 still pending after completing")
+	}
+}
+
+func TestRedeliversToAnotherAgentAfterTimeout(t *testing.T) {
+	first, second := &fakeAgent{id: "agent-1"}, &fakeAgent{id: "agent-2"}
+	s := newTestSwarm(t, VisibilityConfig{Timeout: time.Second, MaxReceives: 2}, first, second)
+	events := s.GetEventBus().Subscribe()
+
+	if err := s.AssignTask("agent-1", types.Task{ID: "task-1"}); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(2 * time.Second)
+	s.redeliverExpired(later) // Times out, visible again at once (no RetryDelay)
+	s.redeliverExpired(later) // Redelivered
+
+	if got := second.received(); len(got) != 1 || got[0] != "task-1" {
+		t.Fatalf("agent-2 received %v; want [task-1]", got)
+	}
+	if event := <-events; event.Type != types.EventTaskRedelivered || event.AgentID != "agent-2" {
+		t.Errorf("got %s event for %s; want %s for agent-2", event.Type, event.AgentID, types.EventTaskRedelivered)
+	}
+
+	// The second delivery times out too, which exhausts MaxReceives
+	s.redeliverExpired(later.Add(2 * time.Second))
+	if n := s.InflightCount(); n != 0 {
+		t.Errorf("%d tasks still in flight; want 0", n)
+	}
+	if dead := s.DeadLetters(); len(dead) != 1 || dead[0].TaskID != "task-1" {
+		t.Errorf("dead letters %+v; want task-1", dead)
+	}
+}
+
+func TestProgressExtendsVisibilityTimeout(t *testing.T) {
+	agent := &fakeAgent{id: "agent-1"}
+	s := newTestSwarm(t, VisibilityConfig{Timeout: time.Minute}, agent)
+
+	if err := s.AssignTask("agent-1", types.Task{ID: "task-1"}); err != nil {
+		t.Fatal(err)
+	}
+	s.inflightMu.Lock()
+	s.inflight["task-1"].deadline = time.Now() // About to expire
+	s.inflightMu.Unlock()
+
+	s.GetEventBus().Publish(types.Event{Type: types.EventTaskProgress, AgentID: "agent-1", TaskID: "task-1"})
+	s.redeliverExpired(time.Now().Add(time.Second))
+	if got := agent.received(); len(got) != 1 {
+		t.Errorf("agent received %v; want task-1 once", got)
+	}
+	if s.inflightAgent("task-1") != "agent-1" {
+		t.Error("task-1 no longer with agent-1")
+	}
+}
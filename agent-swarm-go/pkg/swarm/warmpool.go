@@ -0,0 +1,182 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// warmupTimeout bounds how long a new agent's self-test may take
+const warmupTimeout = 30 * time.Second
+
+// AgentFactory creates a new, not yet started agent with the given ID
+type AgentFactory func(id string) types.Agent
+
+// WarmPoolConfig describes how to spawn agents of one specialty on demand.
+//
+// Agents created by Factory are warmed up before they join the swarm: if the
+// agent implements Warmup() error (all LLM-backed agents do, running an LLM
+// self-test) it must succeed, otherwise the agent is discarded and never
+// receives a task. Up to Size warmed agents are kept ready so that a burst of
+// SpawnAgent calls doesn't pay the warm-up latency on the first task.
+type WarmPoolConfig struct {
+	Specialty string       // Specialty the spawned agents declare, e.g. "research"
+	Factory   AgentFactory // Builds a new agent
+	Size      int          // Warmed agents kept ready (0 spawns cold, still warmed up)
+}
+
+// warmPool holds pre-warmed agents for one specialty
+type warmPool struct {
+	cfg    WarmPoolConfig
+	ready  chan types.Agent // Warmed agents waiting to be spawned
+	seq    atomic.Int64     // Suffix for generated agent IDs
+	cancel context.CancelFunc
+}
+
+// SetWarmPool registers (or replaces) the warm pool for cfg.Specialty and
+// starts filling it in the background.
+func (s *Swarm) SetWarmPool(cfg WarmPoolConfig) error {
+	if cfg.Specialty == "" || cfg.Factory == nil {
+		return fmt.Errorf("warm pool needs a specialty and a factory")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &warmPool{
+		cfg:    cfg,
+		ready:  make(chan types.Agent, cfg.Size),
+		cancel: cancel,
+	}
+
+	s.poolsMu.Lock()
+	if old, ok := s.pools[cfg.Specialty]; ok {
+		old.close()
+	}
+	s.pools[cfg.Specialty] = pool
+	s.poolsMu.Unlock()
+
+	if cfg.Size > 0 {
+		go pool.fill(ctx)
+	}
+	return nil
+}
+
+// WarmCount returns how many warmed agents are ready for a specialty
+func (s *Swarm) WarmCount(specialty string) int {
+	s.poolsMu.Lock()
+	defer s.poolsMu.Unlock()
+
+	if pool, ok := s.pools[specialty]; ok {
+		return len(pool.ready)
+	}
+	return 0
+}
+
+// SpawnAgent adds a new agent of the given specialty to the running swarm and
+// returns its ID. A pre-warmed agent is used when one is ready; otherwise one
+// is created and warmed up synchronously. Autoscalers should call this rather
+// than AddAgent so new agents never take work before passing their self-test.
+func (s *Swarm) SpawnAgent(specialty string) (string, error) {
+	s.poolsMu.Lock()
+	pool, ok := s.pools[specialty]
+	s.poolsMu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("no warm pool configured for specialty %s", specialty)
+	}
+
+	var agent types.Agent
+	select {
+	case agent = <-pool.ready:
+	default:
+		// Pool drained by a burst: pay the warm-up cost now
+		var err error
+		if agent, err = pool.create(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.JoinAgent(agent); err != nil {
+		return "", err
+	}
+
+	slog.Info("agent spawned", logging.KeyAgent, agent.GetID(), "specialty", specialty, "warm_ready", len(pool.ready))
+	return agent.GetID(), nil
+}
+
+// closePools stops refilling every warm pool
+func (s *Swarm) closePools() {
+	s.poolsMu.Lock()
+	defer s.poolsMu.Unlock()
+
+	for _, pool := range s.pools {
+		pool.close()
+	}
+}
+
+// fill keeps the pool topped up until ctx is cancelled. Sends block while the
+// pool is full, so a new agent is only warmed once one has been taken.
+func (p *warmPool) fill(ctx context.Context) {
+	for {
+		agent, err := p.create()
+		if err != nil {
+			// Misconfigured agents are dropped; back off so a broken config
+			// doesn't spin on self-tests
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Second):
+				continue
+			}
+		}
+
+		select {
+		case p.ready <- agent:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// create builds a new agent and runs its warm-up self-test
+func (p *warmPool) create() (types.Agent, error) {
+	id := fmt.Sprintf("%s-warm-%d", p.cfg.Specialty, p.seq.Add(1))
+	agent := p.cfg.Factory(id)
+
+	if err := warmup(agent); err != nil {
+		slog.Warn("agent failed warm-up, discarding", logging.KeyAgent, id, logging.KeyError, err)
+		return nil, fmt.Errorf("agent %s failed warm-up: %w", id, err)
+	}
+	if specialty := agent.GetSpecialty(); specialty != p.cfg.Specialty {
+		return nil, fmt.Errorf("agent %s has specialty %q, pool expects %q", id, specialty, p.cfg.Specialty)
+	}
+
+	slog.Debug("agent warmed up", logging.KeyAgent, id)
+	return agent, nil
+}
+
+// warmup runs the agent's optional Warmup method with a timeout
+func warmup(agent types.Agent) error {
+	warmer, ok := agent.(interface{ Warmup() error })
+	if !ok {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- warmer.Warmup() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(warmupTimeout):
+		return fmt.Errorf("warm-up timed out after %v", warmupTimeout)
+	}
+}
+
+// close stops the pool's background fill
+func (p *warmPool) close() {
+	p.cancel()
+}
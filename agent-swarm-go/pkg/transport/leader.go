@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Scripts keep the check of who holds the lease and the change to it atomic
+const (
+	acquireScript = `local holder = redis.call('GET', KEYS[1])
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if not holder then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0`
+
+	releaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+)
+
+// RedisLock is a swarm.LeaderLock held as the key <prefix>:leader, whose
+// value is the holder's node name and which expires unless renewed
+type RedisLock struct {
+	redis *Redis
+	key   string
+	node  string
+}
+
+// LeaderLock returns the lease that swarms using this Redis prefix compete
+// for as node
+func (r *Redis) LeaderLock(node string) *RedisLock {
+	return &RedisLock{redis: r, key: r.prefix + ":leader", node: node}
+}
+
+// Acquire takes the lease if it is free, or renews it if node holds it
+func (l *RedisLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	reply, err := l.redis.do(ctx, "EVAL", acquireScript, "1", l.key, l.node, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	held, _ := reply.(int64)
+	return held == 1, nil
+}
+
+// Release deletes the lease if node holds it
+func (l *RedisLock) Release(ctx context.Context) error {
+	_, err := l.redis.do(ctx, "EVAL", releaseScript, "1", l.key, l.node)
+	return err
+}
@@ -0,0 +1,174 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/swarm"
+)
+
+// queueFullPoll is how often Push checks a full queue for room
+const queueFullPoll = 100 * time.Millisecond
+
+// RedisQueue is a swarm.QueueBackend kept in a Redis list, so submitted tasks
+// survive a crash of the swarm and every swarm using the same queue name
+// shares the work.
+//
+// Pop moves a task onto a processing list named after the instance, and Ack
+// removes it once an agent has it. Tasks left there by a crash are put back
+// at the head of the queue when the instance opens the queue again, so give
+// each swarm a stable instance name. Tasks already handed to an agent are not
+// covered.
+type RedisQueue struct {
+	redis      *Redis
+	key        string // <prefix>:queue:<name>
+	processing string // <prefix>:queue:<name>:processing:<instance>
+	capacity   int
+}
+
+// Queue opens the task queue called name for this swarm instance. A capacity
+// above 0 bounds how many tasks may wait; 0 leaves it unbounded. Tasks the
+// instance had popped but not acknowledged are requeued first.
+func (r *Redis) Queue(name, instance string, capacity int) (*RedisQueue, error) {
+	if name == "" || instance == "" {
+		return nil, fmt.Errorf("redis queue needs a name and an instance")
+	}
+	q := &RedisQueue{
+		redis:      r,
+		key:        r.prefix + ":queue:" + name,
+		processing: r.prefix + ":queue:" + name + ":processing:" + instance,
+		capacity:   capacity,
+	}
+	if err := q.recover(context.Background()); err != nil {
+		return nil, fmt.Errorf("recovering queue %s: %w", name, err)
+	}
+	return q, nil
+}
+
+// recoverScript moves the whole processing list onto the queue in one step,
+// so a crash can neither lose nor duplicate the tasks on it. The processing
+// list is newest first, and Pop takes from the right.
+const recoverScript = `local items = redis.call('LRANGE', KEYS[2], 0, -1)
+for i = 1, #items, 1000 do
+	redis.call('RPUSH', KEYS[1], unpack(items, i, math.min(i + 999, #items)))
+end
+redis.call('DEL', KEYS[2])
+return #items`
+
+// recover puts unacknowledged tasks back where the next Pop takes them, in
+// the order they were originally queued
+func (q *RedisQueue) recover(ctx context.Context) error {
+	reply, err := q.redis.do(ctx, "EVAL", recoverScript, "2", q.key, q.processing)
+	if err != nil {
+		return err
+	}
+	if n, _ := reply.(int64); n > 0 {
+		slog.Info("requeued unacknowledged tasks", "queue", q.key, "count", n)
+	}
+	return nil
+}
+
+// Push queues a task, polling for room until ctx is done if the queue is full
+func (q *RedisQueue) Push(ctx context.Context, item swarm.QueuedTask) error {
+	for {
+		err := q.TryPush(item)
+		if err != swarm.ErrQueueFull {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(queueFullPoll):
+		}
+	}
+}
+
+// TryPush queues a task, or returns swarm.ErrQueueFull if the queue is at
+// capacity. Swarms sharing the queue may overshoot it by a task or two.
+func (q *RedisQueue) TryPush(item swarm.QueuedTask) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if q.capacity > 0 {
+		n, err := q.length(ctx)
+		if err != nil {
+			return err
+		}
+		if n >= q.capacity {
+			return swarm.ErrQueueFull
+		}
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("encoding task %s: %w", item.Task.ID, err)
+	}
+	_, err = q.redis.do(ctx, "LPUSH", q.key, string(data))
+	return err
+}
+
+// Pop waits for the oldest task, moving it to the processing list
+func (q *RedisQueue) Pop(ctx context.Context) (swarm.QueuedTask, error) {
+	args := []string{"BRPOPLPUSH", q.key, q.processing, strconv.Itoa(int(popWait / time.Second))}
+	for {
+		if err := ctx.Err(); err != nil {
+			return swarm.QueuedTask{}, err
+		}
+		reply, err := q.redis.doWait(ctx, popWait, args...)
+		if err != nil {
+			return swarm.QueuedTask{}, err
+		}
+		// A nil reply means the wait ran out with nothing queued
+		value, ok := reply.(string)
+		if !ok {
+			continue
+		}
+
+		var item swarm.QueuedTask
+		if err := json.Unmarshal([]byte(value), &item); err != nil {
+			// It can never be distributed, so don't let it come back
+			q.redis.do(ctx, "LREM", q.processing, "1", value)
+			slog.Error("dropping undecodable queued task", "queue", q.key, logging.KeyError, err)
+			continue
+		}
+		item.Receipt = value
+		return item, nil
+	}
+}
+
+// Ack removes a distributed task from the processing list
+func (q *RedisQueue) Ack(item swarm.QueuedTask) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	_, err := q.redis.do(ctx, "LREM", q.processing, "1", item.Receipt)
+	return err
+}
+
+// Len returns how many tasks are waiting, or 0 if Redis can't be reached
+func (q *RedisQueue) Len() int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	n, err := q.length(ctx)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Cap returns the queue's capacity, or 0 if it is unbounded
+func (q *RedisQueue) Cap() int {
+	return q.capacity
+}
+
+func (q *RedisQueue) length(ctx context.Context) (int, error) {
+	reply, err := q.redis.do(ctx, "LLEN", q.key)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return int(n), nil
+}
@@ -0,0 +1,356 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// DefaultPrefix namespaces the swarm's keys in Redis, so several swarms can
+// share one server
+const DefaultPrefix = "swarm"
+
+const (
+	// redisTimeout bounds connecting and each ordinary command
+	redisTimeout = 5 * time.Second
+
+	// popWait is how long one BRPOP blocks; PopTask and PopEvent repeat it
+	// so they notice a cancelled context
+	popWait = time.Second
+)
+
+// Redis is a Transport backed by Redis lists: LPUSH to queue and BRPOP to
+// take, so each task goes to exactly one worker. Keys are
+// <prefix>:tasks:<specialty> and <prefix>:events.
+//
+// It speaks the Redis protocol itself rather than pulling in a client
+// library, and supports only the handful of commands it needs.
+type Redis struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+
+	mu     sync.Mutex
+	idle   []*redisConn // Connections free for the next command
+	closed bool
+}
+
+// NewRedis connects to the Redis server at address, either "host:port" or a
+// URL like "redis://:password@host:6379/0". An empty prefix uses
+// DefaultPrefix. The connection is checked before NewRedis returns.
+func NewRedis(address, prefix string) (*Redis, error) {
+	r := &Redis{prefix: prefix}
+	if r.prefix == "" {
+		r.prefix = DefaultPrefix
+	}
+
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis URL: %w", err)
+		}
+		if u.Scheme != "redis" {
+			return nil, fmt.Errorf("invalid redis URL: scheme must be redis, not %q", u.Scheme)
+		}
+		r.addr = u.Host
+		if password, ok := u.User.Password(); ok {
+			r.password = password
+		}
+		if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+			if r.db, err = strconv.Atoi(db); err != nil {
+				return nil, fmt.Errorf("invalid redis URL: database %q is not a number", db)
+			}
+		}
+	} else {
+		r.addr = address
+	}
+	if r.addr == "" {
+		return nil, fmt.Errorf("no redis address")
+	}
+	if _, _, err := net.SplitHostPort(r.addr); err != nil {
+		r.addr = net.JoinHostPort(r.addr, "6379")
+	}
+
+	if _, err := r.do(context.Background(), "PING"); err != nil {
+		return nil, fmt.Errorf("connecting to redis at %s: %w", r.addr, err)
+	}
+	return r, nil
+}
+
+// taskKey is the list holding tasks for a specialty
+func (r *Redis) taskKey(specialty string) string {
+	return r.prefix + ":tasks:" + specialty
+}
+
+// eventKey is the list holding events for the coordinator
+func (r *Redis) eventKey() string {
+	return r.prefix + ":events"
+}
+
+// PushTask queues a task for any worker serving specialty
+func (r *Redis) PushTask(ctx context.Context, specialty string, task types.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("encoding task %s: %w", task.ID, err)
+	}
+	_, err = r.do(ctx, "LPUSH", r.taskKey(specialty), string(data))
+	return err
+}
+
+// PopTask waits for a task for one of the specialties
+func (r *Redis) PopTask(ctx context.Context, specialties []string) (types.Task, error) {
+	keys := make([]string, len(specialties))
+	for i, specialty := range specialties {
+		keys[i] = r.taskKey(specialty)
+	}
+	data, err := r.pop(ctx, keys)
+	if err != nil {
+		return types.Task{}, err
+	}
+
+	var task types.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return types.Task{}, fmt.Errorf("decoding task: %w", err)
+	}
+	return task, nil
+}
+
+// Pending returns how many tasks are waiting for specialty
+func (r *Redis) Pending(ctx context.Context, specialty string) (int, error) {
+	reply, err := r.do(ctx, "LLEN", r.taskKey(specialty))
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return int(n), nil
+}
+
+// PushEvent sends a worker's task event to the coordinator
+func (r *Redis) PushEvent(ctx context.Context, event types.Event) error {
+	data, err := encodeEvent(event)
+	if err != nil {
+		return err
+	}
+	_, err = r.do(ctx, "LPUSH", r.eventKey(), string(data))
+	return err
+}
+
+// PopEvent waits for the next worker event
+func (r *Redis) PopEvent(ctx context.Context) (types.Event, error) {
+	data, err := r.pop(ctx, []string{r.eventKey()})
+	if err != nil {
+		return types.Event{}, err
+	}
+	return decodeEvent(data)
+}
+
+// Close closes the idle connections; ones in use close as they finish
+func (r *Redis) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	for _, c := range r.idle {
+		c.Close()
+	}
+	r.idle = nil
+	return nil
+}
+
+// pop BRPOPs from keys in popWait slices until a value arrives or ctx is done
+func (r *Redis) pop(ctx context.Context, keys []string) ([]byte, error) {
+	args := append([]string{"BRPOP"}, keys...)
+	args = append(args, strconv.Itoa(int(popWait/time.Second)))
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reply, err := r.doWait(ctx, popWait, args...)
+		if err != nil {
+			return nil, err
+		}
+		// A nil reply means the wait ran out with nothing queued
+		if pair, ok := reply.([]interface{}); ok && len(pair) == 2 {
+			value, _ := pair[1].(string)
+			return []byte(value), nil
+		}
+	}
+}
+
+// do runs one command on a pooled connection
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	return r.doWait(ctx, 0, args...)
+}
+
+// doWait runs a command that the server may hold for up to wait before
+// replying
+func (r *Redis) doWait(ctx context.Context, wait time.Duration, args ...string) (interface{}, error) {
+	c, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait + redisTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) && wait == 0 {
+		deadline = d
+	}
+	reply, err := c.do(deadline, args...)
+
+	// A server error leaves the connection usable; anything else may not
+	var serverErr redisError
+	if err == nil || errors.As(err, &serverErr) {
+		r.put(c)
+	} else {
+		c.Close()
+	}
+	return reply, err
+}
+
+// get takes an idle connection, or dials a new one
+func (r *Redis) get(ctx context.Context) (*redisConn, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("redis transport is closed")
+	}
+	if n := len(r.idle); n > 0 {
+		c := r.idle[n-1]
+		r.idle = r.idle[:n-1]
+		r.mu.Unlock()
+		return c, nil
+	}
+	r.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: redisTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{Conn: conn, r: bufio.NewReader(conn)}
+
+	deadline := time.Now().Add(redisTimeout)
+	if r.password != "" {
+		if _, err := c.do(deadline, "AUTH", r.password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		if _, err := c.do(deadline, "SELECT", strconv.Itoa(r.db)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// put returns a connection to the idle list
+func (r *Redis) put(c *redisConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		c.Close()
+		return
+	}
+	r.idle = append(r.idle, c)
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is one connection speaking RESP, the Redis protocol
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// do sends a command and reads its reply. Replies are a string, int64,
+// []interface{}, or nil.
+func (c *redisConn) do(deadline time.Time, args ...string) (interface{}, error) {
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := c.Write(b.Bytes()); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// read parses one reply
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err // $-1 is a nil bulk string
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err // *-1 is a nil array
+		}
+		// Read every element even after an error one, so the next reply
+		// starts where it should
+		items := make([]interface{}, n)
+		var first error
+		for i := range items {
+			items[i], err = c.read()
+			var serverErr redisError
+			if err != nil && !errors.As(err, &serverErr) {
+				return nil, err
+			}
+			if err != nil && first == nil {
+				first = err
+			}
+		}
+		if first != nil {
+			return nil, first
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,19 @@
+package transport
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestReadConsumesArrayAfterErrorElement(t *testing.T) {
+	replies := "*3\r\n:1\r\n-ERR wrong type\r\n$3\r\nfoo\r\n+PONG\r\n"
+	c := &redisConn{r: bufio.NewReader(strings.NewReader(replies))}
+
+	if _, err := c.read(); err == nil || err.Error() != "redis: ERR wrong type" {
+		t.Errorf("array reply error = %v; want the element's error", err)
+	}
+	if reply, err := c.read(); reply != "PONG" || err != nil {
+		t.Errorf("next reply = %v, %v; want PONG", reply, err)
+	}
+}
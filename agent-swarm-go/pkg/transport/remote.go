@@ -0,0 +1,137 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// RemoteAgent stands in the swarm for the workers serving one specialty.
+// Tasks sent to it are pushed to the transport; their events come back
+// through Relay under the ID of the worker agent that ran them.
+//
+// Remote tasks can't be paused or cancelled from the coordinator, and remote
+// agents don't answer other agents' questions.
+type RemoteAgent struct {
+	id        string
+	specialty string
+	transport Transport
+
+	mu    sync.RWMutex
+	state types.AgentState
+}
+
+// NewRemoteAgent creates the stand-in for workers serving specialty
+func NewRemoteAgent(id, specialty string, t Transport) *RemoteAgent {
+	return &RemoteAgent{
+		id:        id,
+		specialty: specialty,
+		transport: t,
+		state:     types.StateIdle,
+	}
+}
+
+// GetID returns the agent's ID
+func (ra *RemoteAgent) GetID() string {
+	return ra.id
+}
+
+// GetSpecialty returns the specialty the workers serve
+func (ra *RemoteAgent) GetSpecialty() string {
+	return ra.specialty
+}
+
+// Start marks the agent ready; the workers run on their own
+func (ra *RemoteAgent) Start(ctx context.Context) error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.state = types.StateIdle
+	return nil
+}
+
+// Stop stops sending tasks to the workers. Tasks already queued stay queued.
+func (ra *RemoteAgent) Stop() error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.state = types.StateStopped
+	return nil
+}
+
+// SendMessage pushes a task message to the workers. Other messages are
+// rejected, since workers only take tasks.
+func (ra *RemoteAgent) SendMessage(msg types.Message) error {
+	if ra.GetState() == types.StateStopped {
+		return fmt.Errorf("agent %s is stopped", ra.id)
+	}
+	task, ok := msg.Content.(types.Task)
+	if !ok || msg.Type != types.MessageTypeTask {
+		return fmt.Errorf("remote agent %s only takes tasks", ra.id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := ra.transport.PushTask(ctx, ra.specialty, task); err != nil {
+		return fmt.Errorf("queueing task %s for remote %s workers: %w", task.ID, ra.specialty, err)
+	}
+	return nil
+}
+
+// ReceiveMessage never has a message; replies come through Relay
+func (ra *RemoteAgent) ReceiveMessage() (*types.Message, bool) {
+	return nil, false
+}
+
+// ProcessTask can't run a task in this process. Tasks reach the workers
+// through SendMessage.
+func (ra *RemoteAgent) ProcessTask(task types.Task) types.Result {
+	return types.Result{
+		TaskID:  task.ID,
+		Success: false,
+		Data:    fmt.Sprintf("Remote agent %s runs tasks on workers; distribute the task instead", ra.id),
+	}
+}
+
+// GetState returns idle until the agent is stopped
+func (ra *RemoteAgent) GetState() types.AgentState {
+	ra.mu.RLock()
+	defer ra.mu.RUnlock()
+	return ra.state
+}
+
+// InboxDepth returns how many tasks are queued for the workers, for the
+// swarm's least-loaded balancing
+func (ra *RemoteAgent) InboxDepth() int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	n, err := ra.transport.Pending(ctx, ra.specialty)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Relay publishes worker events on the coordinator's event bus until ctx is
+// done. Run one per coordinator: each event is delivered only once.
+func Relay(ctx context.Context, t Transport, eventBus *types.EventBus) {
+	for {
+		event, err := t.PopEvent(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("relaying worker events failed", logging.KeyError, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		eventBus.Publish(event)
+	}
+}
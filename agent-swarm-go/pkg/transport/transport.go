@@ -0,0 +1,201 @@
+// Package transport lets agents run in other processes or on other machines.
+//
+// A coordinator (the usual swarm binary) adds a RemoteAgent for each
+// specialty that workers serve. Tasks the swarm routes to a RemoteAgent are
+// pushed to a broker instead of an inbox; any worker process serving that
+// specialty pops one, runs it on a local agent, and pushes the task's events
+// back. Relay republishes those events on the coordinator's event bus, so
+// workflows, the dashboard, and callbacks can't tell remote agents from local
+// ones.
+//
+//	coordinator                     broker                    worker(s)
+//	swarm → RemoteAgent ──push──▶ tasks:<specialty> ──pop──▶ Worker → agent
+//	event bus ◀── Relay ◀──pop─── events ◀─────────push──── task events
+//
+// Redis is the supported broker (see NewRedis). Every worker serving a
+// specialty competes for its tasks, so adding workers scales that specialty
+// out. A task lost with a crashed worker is redelivered by the swarm's
+// visibility timeout, if one is set.
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// Transport carries tasks from a coordinator to workers and events back
+type Transport interface {
+	// PushTask queues a task for any worker serving specialty
+	PushTask(ctx context.Context, specialty string, task types.Task) error
+
+	// PopTask waits for a task for one of the specialties, oldest first,
+	// until ctx is done
+	PopTask(ctx context.Context, specialties []string) (types.Task, error)
+
+	// Pending returns how many tasks are waiting for specialty
+	Pending(ctx context.Context, specialty string) (int, error)
+
+	// PushEvent sends a worker's task event to the coordinator
+	PushEvent(ctx context.Context, event types.Event) error
+
+	// PopEvent waits for the next worker event until ctx is done
+	PopEvent(ctx context.Context) (types.Event, error)
+
+	// Close releases the broker connections
+	Close() error
+}
+
+// wireEvent is an Event as it crosses the broker. Results travel as a
+// wireResult so their error and typed data survive the trip.
+type wireEvent struct {
+	Type         types.EventType     `json:"type"`
+	Timestamp    time.Time           `json:"timestamp"`
+	AgentID      string              `json:"agent_id"`
+	TaskID       string              `json:"task_id,omitempty"`
+	Message      string              `json:"message"`
+	Dependencies []string            `json:"dependencies,omitempty"`
+	RunID        string              `json:"run_id,omitempty"`
+	Result       *wireResult         `json:"result,omitempty"`
+	Progress     *types.TaskProgress `json:"progress,omitempty"`
+	Output       *types.TaskOutput   `json:"output,omitempty"`
+}
+
+// wireResult is a types.Result as it crosses the broker
+type wireResult struct {
+	TaskID   string               `json:"task_id"`
+	Success  bool                 `json:"success"`
+	Data     json.RawMessage      `json:"data,omitempty"`
+	DataType string               `json:"data_type,omitempty"` // Go type of Data when workflows rely on it
+	Error    string               `json:"error,omitempty"`
+	Failure  types.FailureClass   `json:"failure,omitempty"`
+	Metadata types.ResultMetadata `json:"metadata"`
+
+	Artifacts []types.Artifact `json:"artifacts,omitempty"`
+}
+
+// Data types that workflows type-assert, restored on the coordinator
+const (
+	dataReview       = "review"
+	dataPlan         = "plan"
+	dataTranslations = "translations"
+)
+
+// encodeEvent turns an event into its wire form
+func encodeEvent(event types.Event) ([]byte, error) {
+	wire := wireEvent{
+		Type:         event.Type,
+		Timestamp:    event.Timestamp,
+		AgentID:      event.AgentID,
+		TaskID:       event.TaskID,
+		Message:      event.Message,
+		Dependencies: event.Dependencies,
+		RunID:        event.RunID,
+	}
+	if result, ok := event.Data.(types.Result); ok {
+		wr := &wireResult{
+			TaskID:   result.TaskID,
+			Success:  result.Success,
+			Failure:  result.Failure,
+			Metadata: result.Metadata,
+
+			Artifacts: result.Artifacts,
+		}
+		if result.Error != nil {
+			wr.Error = result.Error.Error()
+		}
+		switch result.Data.(type) {
+		case types.Review:
+			wr.DataType = dataReview
+		case types.Plan:
+			wr.DataType = dataPlan
+		case types.Translations:
+			wr.DataType = dataTranslations
+		}
+		data, err := json.Marshal(result.Data)
+		if err != nil {
+			return nil, fmt.Errorf("encoding result of %s: %w", result.TaskID, err)
+		}
+		wr.Data = data
+		wire.Result = wr
+	}
+	if progress, ok := event.Data.(types.TaskProgress); ok {
+		wire.Progress = &progress
+	}
+	if output, ok := event.Data.(types.TaskOutput); ok {
+		wire.Output = &output
+	}
+	return json.Marshal(wire)
+}
+
+// decodeEvent restores an event from its wire form
+func decodeEvent(data []byte) (types.Event, error) {
+	var wire wireEvent
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return types.Event{}, fmt.Errorf("decoding event: %w", err)
+	}
+	event := types.Event{
+		Type:         wire.Type,
+		Timestamp:    wire.Timestamp,
+		AgentID:      wire.AgentID,
+		TaskID:       wire.TaskID,
+		Message:      wire.Message,
+		Dependencies: wire.Dependencies,
+		RunID:        wire.RunID,
+	}
+	if wr := wire.Result; wr != nil {
+		result := types.Result{
+			TaskID:   wr.TaskID,
+			Success:  wr.Success,
+			Failure:  wr.Failure,
+			Metadata: wr.Metadata,
+			RunID:    wire.RunID,
+
+			Artifacts: wr.Artifacts,
+		}
+		if wr.Error != "" {
+			result.Error = errors.New(wr.Error)
+		}
+		if len(wr.Data) > 0 {
+			var err error
+			if result.Data, err = decodeData(wr.DataType, wr.Data); err != nil {
+				return types.Event{}, fmt.Errorf("decoding result of %s: %w", wr.TaskID, err)
+			}
+		}
+		event.Data = result
+	}
+	if wire.Progress != nil {
+		event.Data = *wire.Progress
+	}
+	if wire.Output != nil {
+		event.Data = *wire.Output
+	}
+	return event, nil
+}
+
+// decodeData unmarshals result data into its original type when it is one
+// workflows rely on, and into plain JSON values otherwise
+func decodeData(dataType string, data json.RawMessage) (interface{}, error) {
+	switch dataType {
+	case dataReview:
+		var review types.Review
+		err := json.Unmarshal(data, &review)
+		return review, err
+	case dataPlan:
+		var plan types.Plan
+		err := json.Unmarshal(data, &plan)
+		return plan, err
+	case dataTranslations:
+		var translations types.Translations
+		err := json.Unmarshal(data, &translations)
+		return translations, err
+	default:
+		var value interface{}
+		err := json.Unmarshal(data, &value)
+		return value, err
+	}
+}
@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/agent"
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+)
+
+// Worker runs tasks from a Transport on local agents, for a coordinator
+// elsewhere
+type Worker struct {
+	transport Transport
+	agents    []types.Agent
+}
+
+// NewWorker creates a worker serving each agent's specialty
+func NewWorker(t Transport, agents ...types.Agent) *Worker {
+	return &Worker{transport: t, agents: agents}
+}
+
+// Run starts the agents and serves tasks until ctx is done, then stops them.
+// Each agent takes one task at a time for its own specialty, so two agents
+// of the same type work on two tasks at once.
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, a := range w.agents {
+		specialty := a.GetSpecialty()
+		if specialty == "" {
+			slog.Warn("agent has no specialty; it won't get remote tasks", logging.KeyAgent, a.GetID())
+			continue
+		}
+		if err := a.Start(ctx); err != nil {
+			return fmt.Errorf("starting agent %s: %w", a.GetID(), err)
+		}
+		defer a.Stop()
+
+		wg.Add(1)
+		go func(a types.Agent) {
+			defer wg.Done()
+			w.serve(ctx, a, specialty)
+		}(a)
+		slog.Info("worker serving tasks", logging.KeyAgent, a.GetID(), "specialty", specialty)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// serve pops and runs tasks for one agent until ctx is done
+func (w *Worker) serve(ctx context.Context, a types.Agent, specialty string) {
+	process := agent.TaskFunc(a.ProcessTask)
+	if hooked, ok := a.(interface {
+		WrapTask(agent.TaskFunc) agent.TaskFunc
+	}); ok {
+		process = hooked.WrapTask(process)
+	}
+
+	for {
+		task, err := w.transport.PopTask(ctx, []string{specialty})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("waiting for remote tasks failed", logging.KeyAgent, a.GetID(), logging.KeyError, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		slog.Info("remote task received", logging.KeyAgent, a.GetID(), logging.KeyTask, task.ID)
+		w.publish(a, task, types.EventTaskStarted, fmt.Sprintf("⚙️  Working on: %s", task.Description), nil)
+
+		result := process(task)
+		if result.Success {
+			w.publish(a, task, types.EventTaskCompleted, fmt.Sprintf("✅ Task complete: %s", task.ID), result)
+		} else {
+			w.publish(a, task, types.EventTaskFailed, fmt.Sprintf("❌ Task failed: %s", task.ID), result)
+		}
+	}
+}
+
+// publish sends a task event to the coordinator. It isn't tied to the run's
+// context so a result finished during shutdown still gets through.
+func (w *Worker) publish(a types.Agent, task types.Task, eventType types.EventType, message string, result interface{}) {
+	event := types.Event{
+		Type:         eventType,
+		Timestamp:    time.Now(),
+		AgentID:      a.GetID(),
+		TaskID:       task.ID,
+		Message:      message,
+		Data:         result,
+		Dependencies: task.Dependencies,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := w.transport.PushEvent(ctx, event); err != nil {
+		slog.Error("sending task event to coordinator failed", logging.KeyAgent, a.GetID(), logging.KeyTask, task.ID, logging.KeyError, err)
+	}
+}
+
+// ForwardProgress sends the progress and streamed output events agents
+// publish on bus, the local bus they were built with, on to the coordinator
+// until ctx is done
+func (w *Worker) ForwardProgress(ctx context.Context, bus *types.EventBus) {
+	events := bus.Subscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			if event.Type != types.EventTaskProgress && event.Type != types.EventTaskOutput {
+				continue
+			}
+			pushCtx, cancel := context.WithTimeout(ctx, redisTimeout)
+			if err := w.transport.PushEvent(pushCtx, event); err != nil && ctx.Err() == nil {
+				slog.Warn("sending task progress to coordinator failed", logging.KeyAgent, event.AgentID, logging.KeyTask, event.TaskID, logging.KeyError, err)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// maxArtifactName bounds an artifact's name, which becomes a file name
+const maxArtifactName = 128
+
+// Artifact is a named file a task produces besides its output, e.g. a CSV
+// table, a JSON blob, or a generated source file. Agents return them in
+// Result.Artifacts; workflow runs keep them (see workflows.WorkflowResult)
+// and the run archive stores them, served at /api/runs/{id}/artifacts.
+type Artifact struct {
+	Name        string `json:"name"`           // File name, e.g. "prices.csv"; unique within a run
+	ContentType string `json:"content_type"`   // MIME type, e.g. "text/csv"
+	Size        int    `json:"size"`           // Bytes in Data
+	Step        string `json:"step,omitempty"` // Workflow step that produced it
+	Data        []byte `json:"data,omitempty"` // The content, base64 in JSON; nil in listings
+}
+
+// NewArtifact returns an artifact holding data. An empty contentType is
+// guessed from the name's extension, falling back to plain text for text and
+// application/octet-stream for anything else.
+func NewArtifact(name, contentType string, data []byte) Artifact {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+		if isText(data) {
+			contentType = "text/plain; charset=utf-8"
+		}
+	}
+	return Artifact{Name: name, ContentType: contentType, Size: len(data), Data: data}
+}
+
+// isText reports whether data looks like UTF-8 text
+func isText(data []byte) bool {
+	return !strings.ContainsRune(string(data), '\x00') && strings.ToValidUTF8(string(data), "�") == string(data)
+}
+
+// Listing returns the artifact without its content, with Size filled in
+func (a Artifact) Listing() Artifact {
+	if a.Data != nil {
+		a.Size = len(a.Data)
+	}
+	a.Data = nil
+	return a
+}
+
+// Validate reports whether the artifact's name can be stored as a file: not
+// empty, no directories, and no leading dot
+func (a Artifact) Validate() error {
+	switch {
+	case a.Name == "":
+		return fmt.Errorf("artifact has no name")
+	case len(a.Name) > maxArtifactName:
+		return fmt.Errorf("artifact %q: name is longer than %d bytes", a.Name, maxArtifactName)
+	case strings.ContainsAny(a.Name, `/\`+"\x00"), strings.HasPrefix(a.Name, "."):
+		return fmt.Errorf("artifact %q: names can't contain slashes or start with a dot", a.Name)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// EventTypeInfo describes how the dashboard shows events of a custom type
+type EventTypeInfo struct {
+	Type  EventType `json:"type"`
+	Label string    `json:"label,omitempty"` // Short description, e.g. "Price alert"
+	Icon  string    `json:"icon,omitempty"`  // An emoji or two; the dashboard's 📌 if empty
+	Color string    `json:"color,omitempty"` // The event's border, e.g. "#ff9800" or "orange"
+}
+
+// builtinEventTypes are the event types the swarm itself publishes; custom
+// types can't take their names
+var builtinEventTypes = map[EventType]bool{
+	EventTaskReceived: true, EventTaskStarted: true, EventTaskProgress: true,
+	EventTaskOutput: true, EventTaskCompleted: true, EventTaskFailed: true,
+	EventTaskRedelivered: true, EventTaskDeadLettered: true,
+	EventWorkflowStarted: true, EventWorkflowDone: true, EventWorkflowFailed: true,
+	EventBudgetExceeded: true, EventApprovalRequested: true, EventApprovalResolved: true,
+	EventAgentIdle: true, EventAgentBusy: true, EventAgentPaused: true, EventAgentResumed: true,
+	EventMessage: true, EventBroadcast: true, EventThreadMessage: true,
+	EventConfigChanged: true, EventConfigRejected: true,
+}
+
+var (
+	// eventTypeName is what a custom type's name may hold: it ends up in CSS
+	// class names and URLs
+	eventTypeName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+	// eventTypeColor is a hex color or a CSS color name
+	eventTypeColor = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[a-zA-Z]{1,32})$`)
+)
+
+// maxIconLength bounds an icon, in characters; some emoji take several
+const maxIconLength = 8
+
+// customEventTypes are the types registered with RegisterEventType, for
+// every caller in the process
+var customEventTypes struct {
+	mu    sync.RWMutex
+	types map[EventType]EventTypeInfo
+}
+
+// Validate reports what's wrong with info as a custom event type, if anything
+func (info EventTypeInfo) Validate() error {
+	if !eventTypeName.MatchString(string(info.Type)) {
+		return fmt.Errorf("event type %q: names are up to 64 lowercase letters, digits, '_', '.', or '-'", info.Type)
+	}
+	if builtinEventTypes[info.Type] {
+		return fmt.Errorf("event type %q is built in", info.Type)
+	}
+	if info.Color != "" && !eventTypeColor.MatchString(info.Color) {
+		return fmt.Errorf("event type %q: color %q is neither a hex color nor a color name", info.Type, info.Color)
+	}
+	if utf8.RuneCountInString(info.Icon) > maxIconLength {
+		return fmt.Errorf("event type %q: icon is longer than %d characters", info.Type, maxIconLength)
+	}
+	return nil
+}
+
+// RegisterEventType adds a custom event type that agents can publish, with
+// how the dashboard should show it. Registering a type again replaces its
+// display. The built-in types can't be registered.
+func RegisterEventType(info EventTypeInfo) error {
+	if err := info.Validate(); err != nil {
+		return err
+	}
+
+	customEventTypes.mu.Lock()
+	defer customEventTypes.mu.Unlock()
+	if customEventTypes.types == nil {
+		customEventTypes.types = make(map[EventType]EventTypeInfo)
+	}
+	customEventTypes.types[info.Type] = info
+	return nil
+}
+
+// LookupEventType returns how a custom event type is shown, and false if it
+// isn't registered
+func LookupEventType(t EventType) (EventTypeInfo, bool) {
+	customEventTypes.mu.RLock()
+	defer customEventTypes.mu.RUnlock()
+	info, ok := customEventTypes.types[t]
+	return info, ok
+}
+
+// EventTypes returns the registered custom event types, by name
+func EventTypes() []EventTypeInfo {
+	customEventTypes.mu.RLock()
+	defer customEventTypes.mu.RUnlock()
+	list := make([]EventTypeInfo, 0, len(customEventTypes.types))
+	for _, info := range customEventTypes.types {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
@@ -1,34 +1,197 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
 
 // EventType defines types of events that occur in the swarm
 type EventType string
 
 const (
-	EventTaskReceived  EventType = "task_received"
-	EventTaskStarted   EventType = "task_started"
-	EventTaskCompleted EventType = "task_completed"
-	EventTaskFailed    EventType = "task_failed"
-	EventAgentIdle     EventType = "agent_idle"
-	EventAgentBusy     EventType = "agent_busy"
-	EventMessage       EventType = "message"
-	EventBroadcast     EventType = "broadcast"
+	EventTaskReceived      EventType = "task_received"
+	EventTaskStarted       EventType = "task_started"
+	EventTaskProgress      EventType = "task_progress"
+	EventTaskOutput        EventType = "task_output"
+	EventTaskCompleted     EventType = "task_completed"
+	EventTaskFailed        EventType = "task_failed"
+	EventTaskRedelivered   EventType = "task_redelivered"
+	EventTaskDeadLettered  EventType = "task_dead_lettered"
+	EventWorkflowStarted   EventType = "workflow_started"
+	EventWorkflowDone      EventType = "workflow_completed"
+	EventWorkflowFailed    EventType = "workflow_failed"
+	EventBudgetExceeded    EventType = "budget_exceeded"
+	EventApprovalRequested EventType = "approval_requested"
+	EventApprovalResolved  EventType = "approval_resolved"
+	EventAgentIdle         EventType = "agent_idle"
+	EventAgentBusy         EventType = "agent_busy"
+	EventAgentPaused       EventType = "agent_paused"
+	EventAgentResumed      EventType = "agent_resumed"
+	EventMessage           EventType = "message"
+	EventBroadcast         EventType = "broadcast"
+	EventThreadMessage     EventType = "thread_message"
+	EventConfigChanged     EventType = "config_changed"
+	EventConfigRejected    EventType = "config_rejected"
 )
 
 // Event represents something that happened in the swarm
 type Event struct {
-	Type      EventType   `json:"type"`
-	Timestamp time.Time   `json:"timestamp"`
-	AgentID   string      `json:"agent_id"`
-	TaskID    string      `json:"task_id,omitempty"`
-	Message   string      `json:"message"`
-	Data      interface{} `json:"data,omitempty"`
+	Type         EventType   `json:"type"`
+	Timestamp    time.Time   `json:"timestamp"`
+	AgentID      string      `json:"agent_id"`
+	TaskID       string      `json:"task_id,omitempty"`
+	Message      string      `json:"message"`
+	Data         interface{} `json:"data,omitempty"`
+	Dependencies []string    `json:"dependencies,omitempty"` // IDs of tasks this event's task depends on
+	RunID        string      `json:"run_id,omitempty"`       // Workflow run or CLI session the event belongs to
+}
+
+// TaskProgress is the Data of an EventTaskProgress event: how far an agent
+// has got with a long task
+type TaskProgress struct {
+	Percent int    `json:"percent"` // 0-100, an estimate
+	Phase   string `json:"phase"`   // What the agent is doing, e.g. "calling LLM"
+}
+
+// TaskOutput is the Data of an EventTaskOutput event: the next piece of a
+// streaming task's output (see Task.Stream), as the model generates it
+type TaskOutput struct {
+	Text string `json:"text"`
+}
+
+// BudgetStatus is the Data of an EventBudgetExceeded event: what a workflow
+// run has spent, its limits, and what happens now
+type BudgetStatus struct {
+	WorkflowID string  `json:"workflow_id"`
+	Tokens     int     `json:"tokens"`
+	CostUSD    float64 `json:"cost_usd"`
+	MaxTokens  int     `json:"max_tokens,omitempty"`   // 0 means no token limit
+	MaxCostUSD float64 `json:"max_cost_usd,omitempty"` // 0 means no cost limit
+	Action     string  `json:"action"`                 // "abort" or "downgrade"
+}
+
+// Limits describes the budget, e.g. "$0.5000 / 40000 tokens"
+func (b BudgetStatus) Limits() string {
+	var limits []string
+	if b.MaxCostUSD > 0 {
+		limits = append(limits, fmt.Sprintf("$%.4f", b.MaxCostUSD))
+	}
+	if b.MaxTokens > 0 {
+		limits = append(limits, fmt.Sprintf("%d tokens", b.MaxTokens))
+	}
+	return strings.Join(limits, " / ")
+}
+
+// WorkflowPlan is the Data of an EventWorkflowStarted event: the workflow's
+// task graph, published before any step runs so dashboards can render the
+// pending steps
+type WorkflowPlan struct {
+	WorkflowID string         `json:"workflow_id"`
+	Name       string         `json:"name"`
+	Steps      []WorkflowStep `json:"steps"`
+}
+
+// WorkflowStep is one node of a WorkflowPlan
+type WorkflowStep struct {
+	TaskID       string   `json:"task_id"`
+	Name         string   `json:"name"`
+	AgentType    string   `json:"agent_type,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// ApprovalRequest is the Data of an EventApprovalRequested event: a workflow
+// is paused until a human approves, edits, or rejects Output
+type ApprovalRequest struct {
+	ID            string    `json:"id"`
+	WorkflowID    string    `json:"workflow_id"`
+	RunID         string    `json:"run_id,omitempty"`
+	Step          string    `json:"step"`
+	Instructions  string    `json:"instructions,omitempty"` // What the reviewer should check
+	Output        string    `json:"output"`                 // Intermediate output awaiting review
+	DefaultAction string    `json:"default_action"`         // "approve" or "reject", taken at Deadline
+	Deadline      time.Time `json:"deadline"`
+}
+
+// ApprovalDecision resolves an ApprovalRequest and is the Data of an
+// EventApprovalResolved event
+type ApprovalDecision struct {
+	ID       string `json:"id"`
+	Approved bool   `json:"approved"`
+	Output   string `json:"output,omitempty"` // Edited output; empty keeps the original
+	By       string `json:"by"`               // "cli", "dashboard", or "timeout"
+}
+
+// Thread is a conversation between agents, started when one asks another a
+// question mid-task (see ThreadRouter)
+type Thread struct {
+	ID           string          `json:"id"`
+	TaskID       string          `json:"task_id,omitempty"` // Task the asking agent was working on
+	Participants []string        `json:"participants"`      // Agent IDs, the asker first
+	Messages     []ThreadMessage `json:"messages"`
+	Started      time.Time       `json:"started"`
+}
+
+// ThreadMessage is one question or answer in a Thread, and the Data of an
+// EventThreadMessage event
+type ThreadMessage struct {
+	ThreadID  string    `json:"thread_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
-// EventBus manages event distribution
+// ConfigChange is the Data of an EventConfigChanged event: what reloading the
+// swarm config file changed in the running swarm
+type ConfigChange struct {
+	Path     string   `json:"path"`
+	Added    []string `json:"added,omitempty"`    // IDs of agents started
+	Removed  []string `json:"removed,omitempty"`  // IDs of agents stopped
+	Replaced []string `json:"replaced,omitempty"` // IDs of agents restarted with new settings, e.g. another model
+	Applied  []string `json:"applied,omitempty"`  // Settings in force straight away, e.g. "rate_limits"
+	Restart  []string `json:"restart,omitempty"`  // Changed settings that only take effect on a restart
+	Errors   []string `json:"errors,omitempty"`   // Agents that couldn't be changed; they stay as they were
+}
+
+// Empty reports whether the reload changed nothing
+func (c ConfigChange) Empty() bool {
+	return len(c.Added)+len(c.Removed)+len(c.Replaced)+len(c.Applied)+len(c.Restart)+len(c.Errors) == 0
+}
+
+// Summary describes the change in a line, e.g. "🔧 swarm.yaml reloaded:
+// added researcher-2; applied rate_limits"
+func (c ConfigChange) Summary() string {
+	var parts []string
+	for _, part := range []struct {
+		what string
+		list []string
+	}{
+		{"added", c.Added},
+		{"removed", c.Removed},
+		{"replaced", c.Replaced},
+		{"applied", c.Applied},
+		{"restart needed for", c.Restart},
+		{"failed", c.Errors},
+	} {
+		if len(part.list) > 0 {
+			parts = append(parts, part.what+" "+strings.Join(part.list, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("🔧 %s reloaded: nothing changed", c.Path)
+	}
+	return fmt.Sprintf("🔧 %s reloaded: %s", c.Path, strings.Join(parts, "; "))
+}
+
+// EventBus manages event distribution. Subscribers get events through a
+// buffered channel and miss them when it is full; observers are called for
+// every event.
 type EventBus struct {
 	subscribers []chan Event
+	observers   []func(Event)
+	mu          sync.RWMutex
 }
 
 // NewEventBus creates a new event bus
@@ -40,13 +203,52 @@ func NewEventBus() *EventBus {
 
 // Subscribe creates a new event subscription
 func (eb *EventBus) Subscribe() chan Event {
-	ch := make(chan Event, 100)
+	return eb.SubscribeWithBuffer(100)
+}
+
+// SubscribeWithBuffer creates a new event subscription with a custom buffer size.
+// Use a larger buffer for subscribers that must not miss events under load.
+func (eb *EventBus) SubscribeWithBuffer(size int) chan Event {
+	ch := make(chan Event, size)
+	eb.mu.Lock()
 	eb.subscribers = append(eb.subscribers, ch)
+	eb.mu.Unlock()
 	return ch
 }
 
-// Publish sends an event to all subscribers
+// Unsubscribe ends a subscription: ch gets no more events and is closed
+func (eb *EventBus) Unsubscribe(ch chan Event) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	for i, sub := range eb.subscribers {
+		if sub == ch {
+			eb.subscribers = append(eb.subscribers[:i], eb.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// Observe has fn called with every event as it is published, before any
+// subscriber gets it. Nothing is dropped, but fn runs on the publisher's
+// goroutine, so it must be quick and must not publish events itself.
+func (eb *EventBus) Observe(fn func(Event)) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.observers = append(eb.observers, fn)
+}
+
+// Publish sends an event to all observers and subscribers. Events carry
+// secrets as they were produced; the dashboard, logs, webhooks, archives, and
+// transcripts scrub them on the way out (see pkg/redact).
 func (eb *EventBus) Publish(event Event) {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	for _, fn := range eb.observers {
+		fn(event)
+	}
 	for _, ch := range eb.subscribers {
 		select {
 		case ch <- event:
@@ -58,7 +260,11 @@ func (eb *EventBus) Publish(event Event) {
 
 // Close closes all subscriber channels
 func (eb *EventBus) Close() {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
 	for _, ch := range eb.subscribers {
 		close(ch)
 	}
-}
\ No newline at end of file
+	eb.subscribers = nil
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// localeTag is a BCP 47 language tag, loosely: a language, then optional
+// script, region, or variant subtags, e.g. "ja", "pt-BR", or "zh-Hant"
+var localeTag = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// languageNames are the English names of common languages, by ISO 639-1 code,
+// so prompts can say "Japanese" rather than "ja"
+var languageNames = map[string]string{
+	"ar": "Arabic", "bn": "Bengali", "cs": "Czech", "da": "Danish",
+	"de": "German", "el": "Greek", "en": "English", "es": "Spanish",
+	"fa": "Persian", "fi": "Finnish", "fr": "French", "he": "Hebrew",
+	"hi": "Hindi", "hu": "Hungarian", "id": "Indonesian", "it": "Italian",
+	"ja": "Japanese", "ko": "Korean", "ms": "Malay", "nl": "Dutch",
+	"no": "Norwegian", "pl": "Polish", "pt": "Portuguese", "ro": "Romanian",
+	"ru": "Russian", "sv": "Swedish", "sw": "Swahili", "th": "Thai",
+	"tr": "Turkish", "uk": "Ukrainian", "vi": "Vietnamese", "zh": "Chinese",
+}
+
+// ValidateLocale reports whether locale looks like a BCP 47 language tag,
+// e.g. "ja" or "pt-BR". The empty locale is valid.
+func ValidateLocale(locale string) error {
+	if locale != "" && !localeTag.MatchString(locale) {
+		return fmt.Errorf("locale %q: want a language tag such as \"ja\" or \"pt-BR\"", locale)
+	}
+	return nil
+}
+
+// LanguageName returns the English name of locale's language, e.g.
+// "Japanese" for "ja-JP", or the locale itself for a language it doesn't know
+func LanguageName(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	if name, ok := languageNames[strings.ToLower(language)]; ok {
+		return name
+	}
+	return locale
+}
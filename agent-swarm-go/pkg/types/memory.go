@@ -0,0 +1,245 @@
+package types
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMemoryDocuments caps the documents one run's memory keeps; storing
+// another drops the one stored longest ago
+const maxMemoryDocuments = 256
+
+// SharedNotesKey is the Task.Context key under which an agent finds its run's
+// notes (see RunMemory.Set) when there are any
+const SharedNotesKey = "shared_notes"
+
+// Document is a text kept in a run's memory, e.g. a researcher's raw notes
+type Document struct {
+	Name    string    `json:"name"`
+	Author  string    `json:"author,omitempty"` // Agent or workflow that stored it
+	Text    string    `json:"text"`
+	Updated time.Time `json:"updated"`
+}
+
+// MemoryRef stands in a Task.Context for a document of the task's run, so a
+// long text isn't copied into every task that needs it. The agent gets the
+// document's text in its place (see RunMemory.Resolve).
+type MemoryRef struct {
+	Document string `json:"memory_document"`
+}
+
+// MemoryStore hands out the memory of each run; the swarm implements it and
+// hands it to each agent it adds
+type MemoryStore interface {
+	// Memory returns the memory shared by the tasks of run runID, created
+	// empty on first use, or nil for "" (a task outside any run)
+	Memory(runID string) *RunMemory
+}
+
+// RunMemory is what the agents of one run (see Task.RunID) share while it
+// lasts: notes under keys, and documents by name. Any agent of the run can
+// read and write it; the last write wins. A nil RunMemory is empty and
+// ignores writes, so callers needn't check for a task outside any run.
+type RunMemory struct {
+	mu     sync.RWMutex
+	values map[string]string
+	docs   map[string]Document
+	order  []string // Document names, stored longest ago first
+}
+
+// NewRunMemory returns an empty RunMemory
+func NewRunMemory() *RunMemory {
+	return &RunMemory{values: make(map[string]string), docs: make(map[string]Document)}
+}
+
+// Set notes value under key; an empty value deletes the note
+func (m *RunMemory) Set(key, value string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value == "" {
+		delete(m.values, key)
+		return
+	}
+	m.values[key] = value
+}
+
+// Get returns the note under key
+func (m *RunMemory) Get(key string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Values returns a copy of every note
+func (m *RunMemory) Values() map[string]string {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make(map[string]string, len(m.values))
+	for k, v := range m.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Put stores doc, replacing any document of the same name. Updated is set to
+// now when zero.
+func (m *RunMemory) Put(doc Document) {
+	if m == nil {
+		return
+	}
+	if doc.Updated.IsZero() {
+		doc.Updated = time.Now()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.docs[doc.Name]; ok {
+		m.forget(doc.Name)
+	}
+	m.docs[doc.Name] = doc
+	m.order = append(m.order, doc.Name)
+	for len(m.order) > maxMemoryDocuments {
+		delete(m.docs, m.order[0])
+		m.order = m.order[1:]
+	}
+}
+
+// forget drops name from the storage order; callers hold mu
+func (m *RunMemory) forget(name string) {
+	for i, stored := range m.order {
+		if stored == name {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// Document returns the document called name
+func (m *RunMemory) Document(name string) (Document, bool) {
+	if m == nil {
+		return Document{}, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	doc, ok := m.docs[name]
+	return doc, ok
+}
+
+// Documents returns every document, stored longest ago first
+func (m *RunMemory) Documents() []Document {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	docs := make([]Document, 0, len(m.order))
+	for _, name := range m.order {
+		docs = append(docs, m.docs[name])
+	}
+	return docs
+}
+
+// Search returns the documents whose name or text contains every word of
+// query, ignoring case, most mentions first
+func (m *RunMemory) Search(query string) []Document {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		doc      Document
+		mentions int
+	}
+	var hits []hit
+	for _, doc := range m.Documents() {
+		text := strings.ToLower(doc.Name + " " + doc.Text)
+		mentions := 0
+		for _, word := range words {
+			n := strings.Count(text, word)
+			if n == 0 {
+				mentions = 0
+				break
+			}
+			mentions += n
+		}
+		if mentions > 0 {
+			hits = append(hits, hit{doc, mentions})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].mentions > hits[j].mentions })
+
+	docs := make([]Document, len(hits))
+	for i, h := range hits {
+		docs[i] = h.doc
+	}
+	return docs
+}
+
+// Resolve returns context with each MemoryRef replaced by its document's
+// text, and the run's notes under SharedNotesKey. A reference to a document
+// the memory doesn't have is left out. context itself is not changed.
+func (m *RunMemory) Resolve(context map[string]interface{}) map[string]interface{} {
+	notes := m.Values()
+	if len(notes) == 0 && !hasRefs(context) {
+		return context
+	}
+
+	resolved := make(map[string]interface{}, len(context)+1)
+	for key, value := range context {
+		ref, ok := memoryRef(value)
+		if !ok {
+			resolved[key] = value
+			continue
+		}
+		if doc, found := m.Document(ref.Document); found {
+			resolved[key] = doc.Text
+		}
+	}
+	if len(notes) > 0 {
+		if _, taken := resolved[SharedNotesKey]; !taken {
+			resolved[SharedNotesKey] = notes
+		}
+	}
+	return resolved
+}
+
+// hasRefs reports whether context holds any MemoryRef
+func hasRefs(context map[string]interface{}) bool {
+	for _, value := range context {
+		if _, ok := memoryRef(value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryRef returns value as a MemoryRef, including one that has been
+// through JSON (a snapshot or a durable queue) and come back as a map
+func memoryRef(value interface{}) (MemoryRef, bool) {
+	switch v := value.(type) {
+	case MemoryRef:
+		return v, true
+	case *MemoryRef:
+		if v != nil {
+			return *v, true
+		}
+	case map[string]interface{}:
+		if name, ok := v["memory_document"].(string); ok && len(v) == 1 {
+			return MemoryRef{Document: name}, true
+		}
+	}
+	return MemoryRef{}, false
+}
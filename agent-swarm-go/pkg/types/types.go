@@ -1,13 +1,51 @@
 package types
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Message represents communication between agents
 type Message struct {
-	From    string
-	To      string
-	Content interface{}
-	Type    MessageType
+	From     string
+	To       string
+	Content  interface{}
+	Type     MessageType
+	Priority MessagePriority // Inbox lane; zero value lets the agent decide
+}
+
+// MessagePriority selects which inbox lane a message is queued on
+type MessagePriority int
+
+const (
+	// PriorityDefault lets the receiving agent pick a lane: control messages
+	// go to the high lane, tasks go to the normal lane
+	PriorityDefault MessagePriority = iota
+	PriorityHigh
+	PriorityNormal
+	PriorityLow
+	// PriorityUrgent is taken ahead of everything an agent has waiting, even
+	// the high lane, and skips ahead of the swarm's submission queue
+	PriorityUrgent
+)
+
+// String returns the lane name for logging
+func (p MessagePriority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	case PriorityUrgent:
+		return "urgent"
+	default:
+		return "default"
+	}
 }
 
 // MessageType defines the type of message
@@ -20,14 +58,90 @@ const (
 	MessageTypeBroadcast MessageType = "broadcast"
 )
 
+// ThreadRouter carries questions between agents; the swarm implements it and
+// hands it to each agent it adds
+type ThreadRouter interface {
+	// Ask delivers a question and waits for the answer, or until ctx is done
+	Ask(ctx context.Context, q ThreadQuestion) (ThreadMessage, error)
+}
+
+// ThreadQuestion is a question one agent asks another
+type ThreadQuestion struct {
+	ThreadID string // Continues an existing thread; empty starts a new one
+	TaskID   string // Task the asker is working on
+	From     string // Asking agent's ID
+	To       string // Agent ID, or a specialty such as "research" to ask any agent that has it
+	Text     string
+}
+
+// Query is the Content of a MessageTypeQuery message: a question for the
+// receiving agent, which sends its answer on Reply
+type Query struct {
+	ThreadID string
+	From     string
+	Question string
+	History  []ThreadMessage // Earlier messages in the thread, oldest first
+	Reply    chan<- QueryAnswer
+}
+
+// QueryAnswer is an agent's answer to a Query
+type QueryAnswer struct {
+	Text string
+	Err  error
+}
+
 // Task represents work to be done by an agent
 type Task struct {
-	ID           string
-	Description  string
-	Payload      interface{}
-	Priority     int
-	Context      map[string]interface{} // Results from previous tasks
-	Dependencies []string               // IDs of tasks that must complete first
+	ID             string
+	Description    string
+	Payload        interface{}
+	Priority       int
+	Context        map[string]interface{} // Results from previous tasks
+	Dependencies   []string               // IDs of tasks that must complete first
+	Timeout        time.Duration          // Longest an agent may work on the task; 0 means no limit
+	CallbackURL    string                 // Receives the result as a JSON POST when the task finishes (see pkg/webhook)
+	RunID          string                 // Workflow run or CLI session the task belongs to (see NewRunID); "" for a standalone task
+	Requires       []string               // Capability tags the agent must all have, e.g. "web-search"; none means any agent
+	Source         string                 // Who submitted the task, e.g. an API client, for fair queuing (see swarm.WithFairQueuing)
+	IdempotencyKey string                 // Submissions with the same key and Source run once; later ones get the first one's result (see swarm.DuplicateTaskError)
+	Stream         bool                   // Publish the model's output as it is generated, in EventTaskOutput events
+	Locale         string                 // Language tag agents answer in, e.g. "ja" or "pt-BR" (see ValidateLocale); "" leaves it to the prompt
+	Submitted      time.Time              // When the swarm accepted the task; set by swarm.Submit, DistributeTask, and AssignTask when zero
+}
+
+// NewRunID returns an ID for a workflow run or CLI session. Tasks, events, and
+// results carry their run's ID so they can be filtered and correlated.
+func NewRunID() string {
+	return fmt.Sprintf("run-%d", time.Now().UnixNano())
+}
+
+// AgentType returns the agent specialty requested in Payload["agent_type"],
+// or "" when the task can be handled by any agent
+func (t Task) AgentType() string {
+	switch payload := t.Payload.(type) {
+	case map[string]interface{}:
+		if agentType, ok := payload["agent_type"].(string); ok {
+			return agentType
+		}
+	case map[string]string:
+		return payload["agent_type"]
+	}
+	return ""
+}
+
+// ConversationID returns the conversation named in Payload["conversation_id"],
+// or "". An agent handling several tasks at once runs those of one
+// conversation in order.
+func (t Task) ConversationID() string {
+	switch payload := t.Payload.(type) {
+	case map[string]interface{}:
+		if id, ok := payload["conversation_id"].(string); ok {
+			return id
+		}
+	case map[string]string:
+		return payload["conversation_id"]
+	}
+	return ""
 }
 
 // Result represents the output of an agent's work
@@ -36,14 +150,154 @@ type Result struct {
 	Success bool
 	Data    interface{}
 	Error   error
+	Failure FailureClass // Why an unsuccessful task failed; empty for ordinary errors
+	RunID   string       // The task's RunID
+
+	Metadata  ResultMetadata // How the agent produced the result
+	Artifacts []Artifact     // Files produced besides Data, e.g. a CSV table
+}
+
+// ResultMetadata records how an agent produced a Result
+type ResultMetadata struct {
+	Submitted    *time.Time  `json:"submitted,omitempty"`     // When the swarm accepted the task; nil for a task handed straight to the agent
+	Started      *time.Time  `json:"started,omitempty"`       // When the agent started on the task
+	Finished     *time.Time  `json:"finished,omitempty"`      // When it had the result
+	Provider     string      `json:"provider,omitempty"`      // LLM provider that answered, e.g. "openai"; "mock" without an API key
+	Model        string      `json:"model,omitempty"`         // Model that answered the last request; empty for the mock
+	Attempts     int         `json:"attempts,omitempty"`      // LLM API attempts, retries included; 0 when no API was called
+	Route        *ModelRoute `json:"route,omitempty"`         // Which model tier the request was routed to; nil when routing is off
+	InputTokens  int         `json:"input_tokens,omitempty"`  // As reported by the API; 0 for mock and cached responses
+	OutputTokens int         `json:"output_tokens,omitempty"` // As reported by the API
+	CostUSD      float64     `json:"cost_usd,omitempty"`      // Estimated at list price (see llm.Usage)
+	Locale       string      `json:"locale,omitempty"`        // The task's Locale, which the agent was asked to answer in
+}
+
+// Duration returns how long the agent worked on the task, or 0 when that
+// wasn't recorded
+func (m ResultMetadata) Duration() time.Duration {
+	if m.Started == nil || m.Finished == nil {
+		return 0
+	}
+	return m.Finished.Sub(*m.Started)
+}
+
+// QueueWait returns how long the task waited between being submitted and an
+// agent starting on it, in the swarm's queue and the agent's inbox, or 0 when
+// that wasn't recorded
+func (m ResultMetadata) QueueWait() time.Duration {
+	if m.Submitted == nil || m.Started == nil || m.Started.Before(*m.Submitted) {
+		return 0
+	}
+	return m.Started.Sub(*m.Submitted)
+}
+
+// ModelRoute records why a task's LLM request went to the model it did (see
+// llm.Router)
+type ModelRoute struct {
+	Tier   string `json:"tier"` // "cheap" or "premium"
+	Model  string `json:"model"`
+	Reason string `json:"reason"` // e.g. "task marked complex"
+}
+
+// Review is a critic's verdict on another agent's output, returned as the
+// Data of a CriticAgent's Result
+type Review struct {
+	Accuracy     int    `json:"accuracy"`     // 1-10
+	Completeness int    `json:"completeness"` // 1-10
+	Approved     bool   `json:"approved"`
+	Feedback     string `json:"feedback"` // What to fix; kept when approved too
+}
+
+// String summarizes the review on one line
+func (r Review) String() string {
+	verdict := "revise"
+	if r.Approved {
+		verdict = "approved"
+	}
+	return fmt.Sprintf("%s (accuracy %d/10, completeness %d/10): %s", verdict, r.Accuracy, r.Completeness, r.Feedback)
 }
 
+// Translations maps a language name to a translated text. It is the Data of a
+// TranslationAgent's Result.
+type Translations map[string]string
+
+// String renders every translation under a heading, sorted by language
+func (t Translations) String() string {
+	languages := make([]string, 0, len(t))
+	for language := range t {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	var b strings.Builder
+	for _, language := range languages {
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", language, t[language])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Plan is a goal broken down into tasks by a PlannerAgent, returned as the
+// Data of its Result
+type Plan struct {
+	Goal  string        `json:"goal"`
+	Steps []PlannedStep `json:"steps"`
+}
+
+// PlannedStep is one task of a Plan
+type PlannedStep struct {
+	Name      string   `json:"name"`
+	Agent     string   `json:"agent"` // Agent specialty, e.g. "research"
+	Prompt    string   `json:"prompt"`
+	DependsOn []string `json:"depends_on,omitempty"` // Names of earlier steps whose output this step needs
+}
+
+// String lists the plan's steps, one per line
+func (p Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plan for: %s", p.Goal)
+	for i, step := range p.Steps {
+		fmt.Fprintf(&b, "\n%d. %s (%s): %s", i+1, step.Name, step.Agent, step.Prompt)
+		if len(step.DependsOn) > 0 {
+			fmt.Fprintf(&b, " [after %s]", strings.Join(step.DependsOn, ", "))
+		}
+	}
+	return b.String()
+}
+
+// FailureClass distinguishes kinds of task failure
+type FailureClass string
+
+const (
+	// FailureLimitExceeded means a sandboxed run hit its CPU, memory, or
+	// wall-clock limit (see pkg/sandbox)
+	FailureLimitExceeded FailureClass = "limit_exceeded"
+
+	// FailureCancelled means the task was cancelled before it finished
+	FailureCancelled FailureClass = "cancelled"
+
+	// FailureTimeout means the agent gave up when the task's Timeout elapsed
+	FailureTimeout FailureClass = "timeout"
+
+	// FailureRejected means a before-task hook refused the task, so it was
+	// never processed (see agent.BaseAgent.BeforeTask)
+	FailureRejected FailureClass = "rejected"
+
+	// FailureInvalidOutput means the agent's output still didn't have the
+	// shape the task asked for after its fix-up retries (see pkg/validate)
+	FailureInvalidOutput FailureClass = "invalid_output"
+
+	// FailureNoCapableAgent means no agent in the swarm has every capability
+	// the task requires (see Task.Requires), so it was never handed out
+	FailureNoCapableAgent FailureClass = "no_capable_agent"
+)
+
 // AgentState represents the current state of an agent
 type AgentState string
 
 const (
 	StateIdle       AgentState = "idle"
 	StateProcessing AgentState = "processing"
+	StatePaused     AgentState = "paused"
 	StateStopped    AgentState = "stopped"
 )
 
@@ -69,4 +323,8 @@ type Agent interface {
 
 	// GetState returns the current state of the agent
 	GetState() AgentState
+
+	// GetSpecialty returns what kind of work the agent does, e.g. "research".
+	// Tasks name it as their agent_type to be routed to the agent.
+	GetSpecialty() string
 }
\ No newline at end of file
@@ -0,0 +1,372 @@
+// Package validate checks that an agent's output has the shape a task asked
+// for, so a malformed LLM response fails (and is retried) at the agent rather
+// than breaking whatever consumes it later.
+//
+// A task asks for a shape with an OutputSpec in Payload["output_spec"]:
+//
+//	{"description": "List three risks as JSON",
+//	 "payload": {"output_spec": {
+//	   "schema": {"type": "array", "minItems": 3, "items": {"type": "object", "required": ["risk", "severity"]}}}}}
+//
+//	{"description": "Write the launch memo",
+//	 "payload": {"output_spec": {"sections": ["Summary", "Risks", "Next Steps"]}}}
+//
+// Schemas are a subset of JSON Schema: type, properties, required, items,
+// enum, minItems, maxItems, minLength, and maxLength. JSON is read from the
+// output as is, or from a fenced ```json block in it.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// PayloadKey is the Task.Payload key holding a task's OutputSpec
+const PayloadKey = "output_spec"
+
+// DefaultRetries is how many times an agent is asked to fix output that
+// doesn't match its spec, unless the spec says otherwise
+const DefaultRetries = 1
+
+// OutputSpec is the shape an agent's output must have. Every check that is
+// set must pass.
+type OutputSpec struct {
+	Schema   map[string]interface{} `yaml:"schema" json:"schema,omitempty"`     // The output is JSON matching this schema
+	Pattern  string                 `yaml:"pattern" json:"pattern,omitempty"`   // A regular expression the output must match
+	Sections []string               `yaml:"sections" json:"sections,omitempty"` // Markdown headings the output must have, in any order
+	Retries  *int                   `yaml:"retries" json:"retries,omitempty"`   // Fix attempts after a mismatch; nil means DefaultRetries, 0 none
+
+	pattern *regexp.Regexp // Compiled by Compile
+}
+
+// Compile checks the spec itself: the pattern must compile and the schema
+// use only supported keywords
+func (s *OutputSpec) Compile() error {
+	var problems []string
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("pattern: %v", err))
+		}
+		s.pattern = re
+	}
+	if s.Schema != nil {
+		// Go callers may build schemas from []string and ints; checking works
+		// on the decoded-JSON form
+		if data, err := json.Marshal(s.Schema); err != nil {
+			problems = append(problems, fmt.Sprintf("schema: %v", err))
+		} else if err := json.Unmarshal(data, &s.Schema); err != nil {
+			problems = append(problems, fmt.Sprintf("schema: %v", err))
+		} else if err := checkSchema("schema", s.Schema); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	for i, section := range s.Sections {
+		if strings.TrimSpace(section) == "" {
+			problems = append(problems, fmt.Sprintf("sections[%d]: must not be empty", i))
+		}
+	}
+	if s.Retries != nil && *s.Retries < 0 {
+		problems = append(problems, "retries: must not be negative")
+	}
+	if s.Schema == nil && s.Pattern == "" && len(s.Sections) == 0 {
+		problems = append(problems, "set schema, pattern, or sections")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid output spec: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// MaxRetries returns how many fix attempts the spec allows
+func (s *OutputSpec) MaxRetries() int {
+	if s.Retries == nil {
+		return DefaultRetries
+	}
+	return *s.Retries
+}
+
+// SpecFor returns the spec in task's payload, compiled, or nil when the task
+// has none. It accepts an OutputSpec (Go callers) or its JSON form (the API,
+// batch files, and workflow steps).
+func SpecFor(task types.Task) (*OutputSpec, error) {
+	var raw interface{}
+	switch payload := task.Payload.(type) {
+	case map[string]interface{}:
+		raw = payload[PayloadKey]
+	default:
+		return nil, nil
+	}
+
+	var spec OutputSpec
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case OutputSpec:
+		spec = v
+	case *OutputSpec:
+		spec = *v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output spec: %w", err)
+		}
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("invalid output spec: %w", err)
+		}
+	}
+	if err := spec.Compile(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Check reports every way output misses the spec; nil means it matches.
+// Output that isn't a string is checked as its JSON encoding.
+func (s *OutputSpec) Check(output interface{}) error {
+	text, ok := output.(string)
+	if !ok {
+		data, err := json.Marshal(output)
+		if err != nil {
+			return fmt.Errorf("output can't be encoded as JSON: %w", err)
+		}
+		text = string(data)
+	}
+
+	var problems []string
+	if s.Schema != nil {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(extractJSON(text)), &doc); err != nil {
+			problems = append(problems, fmt.Sprintf("output is not valid JSON: %v", err))
+		} else {
+			problems = append(problems, matchSchema("$", s.Schema, doc)...)
+		}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(text) {
+		problems = append(problems, fmt.Sprintf("output doesn't match the pattern %s", s.Pattern))
+	}
+	if missing := missingSections(text, s.Sections); len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing sections: %s", strings.Join(missing, ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Describe tells the model what shape is wanted; agents add it to the task
+func (s *OutputSpec) Describe() string {
+	var parts []string
+	if s.Schema != nil {
+		schema, _ := json.Marshal(s.Schema)
+		parts = append(parts, fmt.Sprintf("Respond with JSON only, matching this JSON Schema: %s", schema))
+	}
+	if s.Pattern != "" {
+		parts = append(parts, fmt.Sprintf("The response must match the regular expression %s", s.Pattern))
+	}
+	if len(s.Sections) > 0 {
+		parts = append(parts, fmt.Sprintf("Use Markdown headings for each of these sections: %s", strings.Join(s.Sections, ", ")))
+	}
+	return strings.Join(parts, ". ") + "."
+}
+
+// fencedJSON finds a ```json (or bare ```) block
+var fencedJSON = regexp.MustCompile("(?s)```(?:json)?\\s*\n(.*?)```")
+
+// extractJSON returns the JSON in text: a fenced block if it has one, else
+// the whole text
+func extractJSON(text string) string {
+	if m := fencedJSON.FindStringSubmatch(text); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return strings.TrimSpace(text)
+}
+
+// headingPattern matches a Markdown ATX heading line
+var headingPattern = regexp.MustCompile(`(?m)^#{1,6}\s+(.+?)\s*#*\s*$`)
+
+// missingSections returns the sections text has no heading for. Headings
+// match case-insensitively, ignoring surrounding punctuation such as "1." or
+// a trailing colon.
+func missingSections(text string, sections []string) []string {
+	if len(sections) == 0 {
+		return nil
+	}
+	headings := map[string]bool{}
+	for _, m := range headingPattern.FindAllStringSubmatch(text, -1) {
+		headings[normalizeHeading(m[1])] = true
+	}
+	var missing []string
+	for _, section := range sections {
+		if !headings[normalizeHeading(section)] {
+			missing = append(missing, section)
+		}
+	}
+	return missing
+}
+
+// normalizeHeading lowercases a heading and trims numbering and punctuation
+func normalizeHeading(heading string) string {
+	heading = strings.ToLower(strings.TrimSpace(heading))
+	heading = strings.TrimLeft(heading, "0123456789.) ")
+	return strings.Trim(heading, " :*_")
+}
+
+// schemaKeywords are the JSON Schema keywords Check understands; others are
+// rejected by Compile rather than silently ignored
+var schemaKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "items": true, "enum": true,
+	"minItems": true, "maxItems": true, "minLength": true, "maxLength": true,
+	"description": true, "title": true, "$schema": true,
+}
+
+// checkSchema rejects unsupported keywords anywhere in a schema
+func checkSchema(path string, schema map[string]interface{}) error {
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !schemaKeywords[key] {
+			return fmt.Errorf("%s: unsupported keyword %q", path, key)
+		}
+	}
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, ok := props[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s.properties.%s: must be a schema object", path, name)
+			}
+			if err := checkSchema(path+".properties."+name, sub); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		sub, ok := items.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s.items: must be a schema object", path)
+		}
+		return checkSchema(path+".items", sub)
+	}
+	return nil
+}
+
+// matchSchema returns the ways value at path misses schema
+func matchSchema(path string, schema map[string]interface{}, value interface{}) []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if want, ok := schema["type"].(string); ok && !hasType(value, want) {
+		add("want %s, got %s", want, typeName(value))
+		return problems
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			if fmt.Sprint(option) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("%v is not one of %v", value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := v[fmt.Sprint(name)]; !ok {
+					add("missing required field %q", name)
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]interface{}); ok {
+			names := make([]string, 0, len(props))
+			for name := range props {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if field, ok := v[name]; ok {
+					if sub, ok := props[name].(map[string]interface{}); ok {
+						problems = append(problems, matchSchema(path+"."+name, sub, field)...)
+					}
+				}
+			}
+		}
+	case []interface{}:
+		if min, ok := number(schema["minItems"]); ok && float64(len(v)) < min {
+			add("want at least %v items, got %d", min, len(v))
+		}
+		if max, ok := number(schema["maxItems"]); ok && float64(len(v)) > max {
+			add("want at most %v items, got %d", max, len(v))
+		}
+		if sub, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				problems = append(problems, matchSchema(fmt.Sprintf("%s[%d]", path, i), sub, item)...)
+			}
+		}
+	case string:
+		if min, ok := number(schema["minLength"]); ok && float64(len([]rune(v))) < min {
+			add("want at least %v characters, got %d", min, len([]rune(v)))
+		}
+		if max, ok := number(schema["maxLength"]); ok && float64(len([]rune(v))) > max {
+			add("want at most %v characters, got %d", max, len([]rune(v)))
+		}
+	}
+	return problems
+}
+
+// hasType reports whether a decoded JSON value has the JSON Schema type want
+func hasType(value interface{}, want string) bool {
+	switch want {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return typeName(value) == want
+}
+
+// typeName is the JSON Schema type of a decoded JSON value
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// number reads a numeric schema keyword
+func number(v interface{}) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
@@ -0,0 +1,176 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// agentEventLimit is how many recent events are kept per agent
+const agentEventLimit = 50
+
+// AgentDetail is the JSON body of GET /api/agents/{id}/detail
+type AgentDetail struct {
+	ID             string              `json:"id"`
+	State          string              `json:"state"`
+	Specialty      string              `json:"specialty,omitempty"`
+	Capabilities   []string            `json:"capabilities,omitempty"`    // Tags tasks can require
+	CurrentTask    string              `json:"current_task,omitempty"`    // Task the agent is working on, if any
+	Progress       *types.TaskProgress `json:"progress,omitempty"`        // Latest progress reported on CurrentTask
+	HistorySize    *int                `json:"history_size,omitempty"`    // Conversation history length, for agents that keep one
+	HistorySummary string              `json:"history_summary,omitempty"` // Rolling summary of older exchanges
+	LastResult     *TaskResult         `json:"last_result,omitempty"`
+	Events         []types.Event       `json:"events"` // Most recent first
+}
+
+// agentActivity is what the server has observed about one agent
+type agentActivity struct {
+	events      []types.Event // Oldest first, capped at agentEventLimit
+	currentTask string
+	progress    *types.TaskProgress // Latest progress on currentTask
+	lastResult  *TaskResult
+}
+
+// activityLog tracks per-agent events for the agent detail view
+type activityLog struct {
+	mu     sync.Mutex
+	agents map[string]*agentActivity
+}
+
+func newActivityLog() *activityLog {
+	return &activityLog{agents: make(map[string]*agentActivity)}
+}
+
+// record files an event under its agent
+func (l *activityLog) record(event types.Event) {
+	if event.AgentID == "" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	activity, ok := l.agents[event.AgentID]
+	if !ok {
+		activity = &agentActivity{}
+		l.agents[event.AgentID] = activity
+	}
+
+	activity.events = append(activity.events, event)
+	if len(activity.events) > agentEventLimit {
+		activity.events = activity.events[len(activity.events)-agentEventLimit:]
+	}
+
+	switch event.Type {
+	case types.EventTaskStarted:
+		activity.currentTask = event.TaskID
+		activity.progress = nil
+	case types.EventTaskProgress:
+		if progress, ok := event.Data.(types.TaskProgress); ok && activity.currentTask == event.TaskID {
+			activity.progress = &progress
+		}
+	case types.EventTaskCompleted, types.EventTaskFailed:
+		if activity.currentTask == event.TaskID {
+			activity.currentTask = ""
+			activity.progress = nil
+		}
+		result := taskResultFromEvent(event)
+		activity.lastResult = &result
+	}
+}
+
+// snapshot copies an agent's activity into detail
+func (l *activityLog) snapshot(agentID string, detail *AgentDetail) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	detail.Events = []types.Event{}
+	activity, ok := l.agents[agentID]
+	if !ok {
+		return
+	}
+
+	detail.CurrentTask = activity.currentTask
+	detail.Progress = activity.progress
+	detail.LastResult = activity.lastResult
+	for i := len(activity.events) - 1; i >= 0; i-- {
+		detail.Events = append(detail.Events, activity.events[i])
+	}
+}
+
+// handleAgentDetail serves GET /api/agents/{id}/detail and
+// /api/agents/{id}/stats, removes the agent for DELETE /api/agents/{id}, and
+// pauses or resumes it for POST (see controlAgent)
+func (s *Server) handleAgentDetail(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodDelete:
+		s.removeAgent(w, r)
+		return
+	case http.MethodPost:
+		s.controlAgent(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/stats") {
+		s.handleAgentStats(w, r)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/api/agents/")
+	agentID, ok := strings.CutSuffix(rest, "/detail")
+	if !ok || agentID == "" || strings.Contains(agentID, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/agents/{id}/detail")
+		return
+	}
+
+	agent, err := s.swarm.GetAgent(agentID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("agent %s not found", agentID))
+		return
+	}
+
+	detail := AgentDetail{
+		ID:        agentID,
+		State:     string(agent.GetState()),
+		Specialty: agent.GetSpecialty(),
+	}
+	if tagged, ok := agent.(interface{ GetCapabilities() []string }); ok {
+		detail.Capabilities = tagged.GetCapabilities()
+	}
+	if conversational, ok := agent.(interface{ HistorySize() int }); ok {
+		size := conversational.HistorySize()
+		detail.HistorySize = &size
+	}
+	if summarized, ok := agent.(interface{ HistorySummary() string }); ok {
+		detail.HistorySummary = summarized.HistorySummary()
+	}
+	s.activity.snapshot(agentID, &detail)
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// handleAgentStats serves an agent's statistics, kept across restarts (see
+// pkg/agentstats): GET /api/agents/{id}/stats. An agent that has left the
+// swarm still has its statistics; one in the swarm that hasn't finished a
+// task yet has zeros.
+func (s *Server) handleAgentStats(w http.ResponseWriter, r *http.Request) {
+	if s.agentStats == nil {
+		writeError(w, http.StatusNotImplemented, "agent statistics are not enabled on this server")
+		return
+	}
+	agentID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/agents/"), "/stats")
+	if agentID == "" || strings.Contains(agentID, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/agents/{id}/stats")
+		return
+	}
+
+	stats, ok := s.agentStats.Get(agentID)
+	if !ok {
+		if _, err := s.swarm.GetAgent(agentID); err != nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("agent %s not found", agentID))
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
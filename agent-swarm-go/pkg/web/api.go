@@ -0,0 +1,565 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/archive"
+	"agent-swarm-go/pkg/redact"
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/validate"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// maxResults is how many finished task results GET /api/results keeps; the
+// oldest are forgotten first
+const maxResults = 1000
+
+// SubmitTaskRequest is the JSON body accepted by POST /api/tasks
+type SubmitTaskRequest struct {
+	ID             string                 `json:"id,omitempty"` // Generated when empty
+	Description    string                 `json:"description"`
+	Payload        interface{}            `json:"payload,omitempty"`
+	Priority       int                    `json:"priority,omitempty"`
+	Context        map[string]interface{} `json:"context,omitempty"`
+	Dependencies   []string               `json:"dependencies,omitempty"`
+	TimeoutSecs    float64                `json:"timeout_seconds,omitempty"` // Agent gives up after this long; 0 means no limit
+	CallbackURL    string                 `json:"callback_url,omitempty"`    // POSTed the result when the task finishes
+	RunID          string                 `json:"run_id,omitempty"`          // Groups the task with others of the same run
+	Requires       []string               `json:"requires,omitempty"`        // Capability tags the agent must all have
+	Urgent         bool                   `json:"urgent,omitempty"`          // Taken ahead of the agent's waiting work
+	Source         string                 `json:"source,omitempty"`          // Who is submitting, for fair queuing; admins only (see sourceOf)
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"` // Or the Idempotency-Key header; a repeat gets the first task back
+	Locale         string                 `json:"locale,omitempty"`          // Language tag the agent answers in, e.g. "ja" or "pt-BR"
+}
+
+// SubmitTaskResponse is returned by POST /api/tasks
+type SubmitTaskResponse struct {
+	TaskID string `json:"task_id"`
+	Status string `json:"status"` // "queued", or "duplicate" for a repeated idempotency key
+}
+
+// TaskResult is the JSON shape of a finished task served by GET /api/results/{taskID}
+type TaskResult struct {
+	TaskID      string      `json:"task_id"`
+	AgentID     string      `json:"agent_id"`
+	RunID       string      `json:"run_id,omitempty"`
+	Success     bool        `json:"success"`
+	Data        interface{} `json:"data"`
+	Error       string      `json:"error,omitempty"`
+	Failure     string      `json:"failure,omitempty"` // Failure class, e.g. "limit_exceeded"
+	CompletedAt time.Time   `json:"completed_at"`
+
+	Metadata types.ResultMetadata `json:"metadata"` // Timing, model, tokens, and attempts
+}
+
+// errorResponse is the JSON body of every API error
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON encodes v as the JSON response body with the given status code,
+// with secrets scrubbed (see pkg/redact)
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var body bytes.Buffer
+	json.NewEncoder(&body).Encode(v)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	io.WriteString(w, redact.String(body.String()))
+}
+
+// writeError sends a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// handleTasks lists tasks (GET) or accepts a new one (POST)
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listTasks(w, r)
+	case http.MethodPost:
+		s.submitTask(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list tasks or POST to submit one")
+	}
+}
+
+// listTasks serves the swarm's recent tasks, newest first:
+// GET /api/tasks?status=running&agent=researcher-1
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	filter := swarm.TaskFilter{
+		Status:  r.URL.Query().Get("status"),
+		AgentID: r.URL.Query().Get("agent"),
+	}
+	if filter.Status != "" && !swarm.ValidTaskStatus(filter.Status) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown status %q (want queued, running, completed, or failed)", filter.Status))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.swarm.Tasks(filter))
+}
+
+// submitTask queues a task from a POST /api/tasks body on the swarm
+func (s *Server) submitTask(w http.ResponseWriter, r *http.Request) {
+	var req SubmitTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid task JSON: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.Description) == "" {
+		writeError(w, http.StatusBadRequest, "description is required")
+		return
+	}
+	if req.TimeoutSecs < 0 {
+		writeError(w, http.StatusBadRequest, "timeout_seconds must not be negative")
+		return
+	}
+	if req.CallbackURL != "" {
+		if err := webhook.ValidateURL(req.CallbackURL); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	for _, tag := range req.Requires {
+		if strings.TrimSpace(tag) == "" {
+			writeError(w, http.StatusBadRequest, "requires must not contain empty tags")
+			return
+		}
+	}
+	if err := types.ValidateLocale(req.Locale); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("api-%d", time.Now().UnixNano())
+	}
+	// Fair queuing goes by who is asking; only an admin may name another source
+	if req.Source == "" || !roleOf(r).Allows(RoleAdmin) {
+		req.Source = sourceOf(r)
+	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = r.Header.Get("Idempotency-Key")
+	}
+
+	task := types.Task{
+		ID:           req.ID,
+		Description:  req.Description,
+		Payload:      req.Payload,
+		Priority:     req.Priority,
+		Context:      req.Context,
+		Dependencies: req.Dependencies,
+		Timeout:      time.Duration(req.TimeoutSecs * float64(time.Second)),
+		CallbackURL:  req.CallbackURL,
+		RunID:        req.RunID,
+		Requires:     req.Requires,
+		Source:       req.Source,
+		Locale:       req.Locale,
+
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	if _, err := validate.SpecFor(task); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	priority := types.PriorityDefault
+	if req.Urgent {
+		priority = types.PriorityUrgent
+	}
+	if err := s.swarm.Routable(task); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	err := s.swarm.SubmitNoWaitWithPriority(task, priority)
+	var duplicate *swarm.DuplicateTaskError
+	if errors.As(err, &duplicate) {
+		// The same request again, e.g. a client retry: point it at the first task
+		writeJSON(w, http.StatusOK, SubmitTaskResponse{TaskID: duplicate.TaskID, Status: "duplicate"})
+		return
+	}
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, swarm.ErrSourceQuota) {
+			status = http.StatusTooManyRequests
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, SubmitTaskResponse{TaskID: task.ID, Status: "queued"})
+}
+
+// sourceOf returns the fair-queuing source of the tasks r submits: the name
+// its client authenticated under, or else the client's address
+func sourceOf(r *http.Request) string {
+	if name := clientName(r); name != "" {
+		return name
+	}
+	return clientHost(r)
+}
+
+// clientHost returns the host part of the request's remote address
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// handleResult serves the result of a finished task: GET /api/results/{taskID},
+// or GET /api/results/{taskID}/download to save it as a file
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	taskID := strings.TrimPrefix(r.URL.Path, "/api/results/")
+	if id, ok := strings.CutSuffix(taskID, "/download"); ok && id != "" && !strings.Contains(id, "/") {
+		s.handleResultDownload(w, r, id)
+		return
+	}
+	if taskID == "" || strings.Contains(taskID, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/results/{taskID}")
+		return
+	}
+
+	s.resultsMu.RLock()
+	result, ok := s.results[taskID]
+	s.resultsMu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no result for task %s yet", taskID))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// recordResult stores the result carried by a completion or failure event.
+// It observes the event bus (see NewServer), so no result is missed.
+func (s *Server) recordResult(event types.Event) {
+	if event.Type != types.EventTaskCompleted && event.Type != types.EventTaskFailed {
+		return
+	}
+
+	result := taskResultFromEvent(event)
+
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+
+	if _, ok := s.results[event.TaskID]; !ok {
+		s.resultOrder = append(s.resultOrder, event.TaskID)
+	}
+	s.results[event.TaskID] = result
+	for len(s.resultOrder) > maxResults {
+		delete(s.results, s.resultOrder[0])
+		s.resultOrder = s.resultOrder[1:]
+	}
+}
+
+// taskResultFromEvent builds a TaskResult from a completed or failed event
+func taskResultFromEvent(event types.Event) TaskResult {
+	result := TaskResult{
+		TaskID:      event.TaskID,
+		AgentID:     event.AgentID,
+		RunID:       event.RunID,
+		Success:     event.Type == types.EventTaskCompleted,
+		Data:        event.Message,
+		CompletedAt: event.Timestamp,
+	}
+	if r, ok := event.Data.(types.Result); ok {
+		result.Success = r.Success
+		result.Data = r.Data
+		if r.Error != nil {
+			result.Error = r.Error.Error()
+		}
+		result.Failure = string(r.Failure)
+		result.Metadata = r.Metadata
+	}
+	return result
+}
+
+// handleApprovals lists the workflow steps waiting for a human decision
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list pending approvals")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.swarm.PendingApprovals())
+}
+
+// handleThreads lists the conversations between agents, newest first
+func (s *Server) handleThreads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list agent threads")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.swarm.Threads())
+}
+
+// handleQueue reports the swarm's submission queue
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to read queue stats")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.swarm.QueueStats())
+}
+
+// handleLeader reports whether this swarm leads its cluster
+func (s *Server) handleLeader(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to read leadership")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.swarm.Leadership())
+}
+
+// handleEventTypes lists the custom event types agents may publish, with how
+// the dashboard shows them
+func (s *Server) handleEventTypes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list event types")
+		return
+	}
+	writeJSON(w, http.StatusOK, types.EventTypes())
+}
+
+// handleThreadDetail returns one conversation between agents
+func (s *Server) handleThreadDetail(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/threads/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/threads/{threadID}")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to read a thread")
+		return
+	}
+
+	thread, ok := s.swarm.Thread(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no thread %s", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, thread)
+}
+
+// handleSchedules lists cron schedules (GET) or adds one (POST, a swarm.Schedule)
+func (s *Server) handleSchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.swarm.Schedules())
+	case http.MethodPost:
+		var sched swarm.Schedule
+		if err := json.NewDecoder(r.Body).Decode(&sched); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid schedule JSON: %v", err))
+			return
+		}
+		added, err := s.swarm.AddSchedule(sched)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, added)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list schedules or POST to add one")
+	}
+}
+
+// handleScheduleDetail removes a schedule: DELETE /api/schedules/{id}
+func (s *Server) handleScheduleDetail(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/schedules/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/schedules/{id}")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "use DELETE to remove a schedule")
+		return
+	}
+	if err := s.swarm.RemoveSchedule(id); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRuns lists archived workflow runs, newest first: GET /api/runs?limit=20
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list past runs")
+		return
+	}
+	if s.archive == nil {
+		writeError(w, http.StatusNotFound, "the run archive is not enabled")
+		return
+	}
+
+	runs := s.archive.List()
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		if n < len(runs) {
+			runs = runs[:n]
+		}
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// handleRunDetail serves one archived run in full: GET /api/runs/{workflowID}.
+// Its artifacts are under /api/runs/{workflowID}/artifacts.
+func (s *Server) handleRunDetail(w http.ResponseWriter, r *http.Request) {
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/runs/"), "/")
+	if rest == "artifacts" || strings.HasPrefix(rest, "artifacts/") {
+		s.handleRunArtifacts(w, r, id, strings.TrimPrefix(strings.TrimPrefix(rest, "artifacts"), "/"))
+		return
+	}
+	if id == "" || rest != "" {
+		writeError(w, http.StatusNotFound, "expected /api/runs/{workflowID}")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to read a past run")
+		return
+	}
+	if s.archive == nil {
+		writeError(w, http.StatusNotFound, "the run archive is not enabled")
+		return
+	}
+
+	run, err := s.archive.Get(id)
+	if errors.Is(err, archive.ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no archived run %s", id))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// handleRunArtifacts lists an archived run's artifacts, GET
+// /api/runs/{workflowID}/artifacts, or serves one as a file, GET
+// /api/runs/{workflowID}/artifacts/{name}
+func (s *Server) handleRunArtifacts(w http.ResponseWriter, r *http.Request, id, name string) {
+	if id == "" || strings.Contains(name, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/runs/{workflowID}/artifacts/{name}")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to read artifacts")
+		return
+	}
+	if s.archive == nil {
+		writeError(w, http.StatusNotFound, "the run archive is not enabled")
+		return
+	}
+
+	if name == "" {
+		run, err := s.archive.Get(id)
+		if errors.Is(err, archive.ErrNotFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("no archived run %s", id))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		artifacts := run.Artifacts
+		if artifacts == nil {
+			artifacts = []types.Artifact{}
+		}
+		writeJSON(w, http.StatusOK, artifacts)
+		return
+	}
+
+	artifact, err := s.archive.Artifact(id, name)
+	switch {
+	case errors.Is(err, archive.ErrNotFound):
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no archived run %s", id))
+		return
+	case errors.Is(err, archive.ErrArtifactNotFound):
+		writeError(w, http.StatusNotFound, fmt.Sprintf("run %s has no artifact %s", id, name))
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Sent as a download, so an HTML artifact can't run in the dashboard's origin
+	w.Header().Set("Content-Type", artifact.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", unsafeFilename.ReplaceAllString(artifact.Name, "-")))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(artifact.Data)
+}
+
+// handleDeadLetters lists the tasks no agent could take, newest first:
+// GET /api/deadletters
+func (s *Server) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list dead-lettered tasks")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.swarm.DeadLetters())
+}
+
+// handleDeadLetterDetail re-drives dead-lettered tasks,
+// POST /api/deadletters/{taskID}/redrive or POST /api/deadletters/redrive for
+// all of them, or discards one, DELETE /api/deadletters/{taskID}
+func (s *Server) handleDeadLetterDetail(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/deadletters/")
+	if path == "redrive" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "use POST to re-drive dead-lettered tasks")
+			return
+		}
+		n, err := s.swarm.RedriveAll()
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("%d re-driven before: %v", n, err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"redriven": n})
+		return
+	}
+
+	id, redrive := strings.CutSuffix(path, "/redrive")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/deadletters/{taskID}[/redrive]")
+		return
+	}
+	switch {
+	case redrive && r.Method == http.MethodPost:
+		if err := s.swarm.Redrive(id); err != nil {
+			writeError(w, deadLetterStatus(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, SubmitTaskResponse{TaskID: id, Status: "queued"})
+	case !redrive && r.Method == http.MethodDelete:
+		if err := s.swarm.DiscardDeadLetter(id); err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "use POST .../redrive to re-drive a task or DELETE to discard it")
+	}
+}
+
+// deadLetterStatus is the HTTP status for a failed re-drive: 404 for an
+// unknown task, else 503 (e.g. the queue had no room)
+func deadLetterStatus(err error) int {
+	if errors.Is(err, swarm.ErrNotDeadLettered) {
+		return http.StatusNotFound
+	}
+	return http.StatusServiceUnavailable
+}
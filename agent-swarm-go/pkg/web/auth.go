@@ -0,0 +1,280 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// authCookieName is the cookie that carries the dashboard token after a
+// browser has authenticated once with ?token=...
+const authCookieName = "swarm_token"
+
+// AuthConfig configures access control for the dashboard, WebSocket, and API.
+//
+// Two mechanisms are supported and may be enabled together:
+//   - Token: clients send "Authorization: Bearer <token>", or open the
+//     dashboard once as /?token=<token>, which stores the token in an HttpOnly
+//     cookie so the page's own /ws and /api/* requests are authenticated too
+//   - Basic auth: username and password, prompted for by the browser
+//
+// Both grant the admin role. API keys (Keys) are sent like the token but carry
+// a role of their own, so clients can be given less than full control.
+//
+// When nothing is configured, authentication is disabled and every request is
+// allowed (the previous behavior, fine for a laptop, not for a shared host).
+type AuthConfig struct {
+	Token    string   // DASHBOARD_TOKEN
+	Username string   // DASHBOARD_USER
+	Password string   // DASHBOARD_PASSWORD
+	Keys     []APIKey // Role-scoped API keys
+}
+
+// Role is what an authenticated client may do. Each role may do everything
+// the ones before it may:
+//   - viewer: see the dashboard and read the API
+//   - operator: also submit, cancel, approve, and re-drive tasks, and manage
+//     schedules and research runs
+//   - admin: also add, remove, pause, and resume agents
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+// ParseRole checks a role name
+func ParseRole(name string) (Role, error) {
+	switch role := Role(name); role {
+	case RoleViewer, RoleOperator, RoleAdmin:
+		return role, nil
+	}
+	return "", fmt.Errorf("unknown role %q (want viewer, operator, or admin)", name)
+}
+
+// rank orders the roles; an unknown role ranks below viewer
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// Allows reports whether the role may do what need may
+func (r Role) Allows(need Role) bool {
+	return r.rank() >= need.rank()
+}
+
+// APIKey is a secret a client sends as "Authorization: Bearer <key>", and
+// the role it grants
+type APIKey struct {
+	Name string // Who holds the key, for logs
+	Key  string
+	Role Role
+
+	// Namespaces the key is good for (see Server.AddNamespace), including
+	// DefaultNamespace for the server's own swarm; empty means every one
+	Namespaces []string
+}
+
+// AuthConfigFromEnv reads DASHBOARD_TOKEN, DASHBOARD_USER, and DASHBOARD_PASSWORD
+func AuthConfigFromEnv() AuthConfig {
+	return AuthConfig{
+		Token:    os.Getenv("DASHBOARD_TOKEN"),
+		Username: os.Getenv("DASHBOARD_USER"),
+		Password: os.Getenv("DASHBOARD_PASSWORD"),
+	}
+}
+
+// Enabled reports whether any authentication mechanism is configured
+func (c AuthConfig) Enabled() bool {
+	return c.Token != "" || c.basicEnabled() || len(c.Keys) > 0
+}
+
+func (c AuthConfig) basicEnabled() bool {
+	return c.Username != "" && c.Password != ""
+}
+
+// Validate reports a half-configured mechanism, which would otherwise leave
+// the dashboard open to everyone: a basic auth user without a password, or the
+// other way round
+func (c AuthConfig) Validate() error {
+	if (c.Username == "") != (c.Password == "") {
+		return errors.New("dashboard basic auth needs both DASHBOARD_USER and DASHBOARD_PASSWORD")
+	}
+	return nil
+}
+
+// identity is who an authenticated client is
+type identity struct {
+	Role       Role
+	Name       string   // The API key's name or the basic auth user; "" for the dashboard token or without authentication
+	Namespaces []string // Where the API key is good; empty means every namespace
+}
+
+// allowedIn reports whether the client may use namespace
+func (id identity) allowedIn(namespace string) bool {
+	if len(id.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range id.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate checks the request against the configured mechanisms.
+// It returns the client's identity, with no role when the request isn't
+// allowed; a token passed as ?token= is also reported so the caller can
+// persist it in a cookie. With authentication disabled every client is an
+// admin.
+func (c AuthConfig) authenticate(r *http.Request) (id identity, queryToken string) {
+	if !c.Enabled() {
+		return identity{Role: RoleAdmin}, ""
+	}
+
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		if id := c.tokenIdentity(strings.TrimPrefix(header, "Bearer ")); id.Role != "" {
+			return id, ""
+		}
+	}
+	if cookie, err := r.Cookie(authCookieName); err == nil {
+		if id := c.tokenIdentity(cookie.Value); id.Role != "" {
+			return id, ""
+		}
+	}
+	if token := r.URL.Query().Get("token"); token != "" {
+		if id := c.tokenIdentity(token); id.Role != "" {
+			return id, token
+		}
+	}
+
+	if c.basicEnabled() {
+		if user, pass, found := r.BasicAuth(); found && secureEqual(user, c.Username) && secureEqual(pass, c.Password) {
+			return identity{Role: RoleAdmin, Name: c.Username}, ""
+		}
+	}
+
+	return identity{}, ""
+}
+
+// tokenIdentity returns who a bearer token belongs to, with no role if it is
+// neither the dashboard token nor an API key
+func (c AuthConfig) tokenIdentity(token string) identity {
+	if c.Token != "" && secureEqual(token, c.Token) {
+		return identity{Role: RoleAdmin}
+	}
+	for _, key := range c.Keys {
+		if key.Key != "" && secureEqual(token, key.Key) {
+			return identity{Role: key.Role, Name: key.Name, Namespaces: key.Namespaces}
+		}
+	}
+	return identity{}
+}
+
+// secureEqual compares two secrets in constant time
+func secureEqual(given, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
+
+// identityKey is the context key under which a request carries its client's
+// identity
+type identityKey struct{}
+
+// identityOf returns who sent r
+func identityOf(r *http.Request) identity {
+	id, _ := r.Context().Value(identityKey{}).(identity)
+	return id
+}
+
+// roleOf returns the role of the client that sent r
+func roleOf(r *http.Request) Role {
+	return identityOf(r).Role
+}
+
+// clientName returns the name the client that sent r authenticated under, or
+// "" when it has none (see identity)
+func clientName(r *http.Request) string {
+	return identityOf(r).Name
+}
+
+// requireAuth wraps a handler so it only runs for authenticated requests:
+// reads (GET and HEAD) for any role, and everything else for operators
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return s.requireRole(RoleOperator, next)
+}
+
+// requireRole is requireAuth with the role that requests other than reads need
+func (s *Server) requireRole(write Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, queryToken := s.auth.authenticate(r)
+		if id.Role == "" {
+			if s.auth.basicEnabled() {
+				w.Header().Set("WWW-Authenticate", `Basic realm="Agent Swarm Dashboard"`)
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Remember a token given in the URL so the dashboard's own requests
+		// (WebSocket, API polling) don't need to carry it
+		if queryToken != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     authCookieName,
+				Value:    queryToken,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+
+		if !id.allowedIn(s.namespace()) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("not allowed in namespace %s", s.namespace()))
+			return
+		}
+
+		need := write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			need = RoleViewer
+		}
+		if !id.Role.Allows(need) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("%s role required", need))
+			return
+		}
+		if need != RoleViewer && s.auth.Enabled() {
+			// Changes are logged with who made them
+			slog.Info("api write", "method", r.Method, "path", r.URL.Path, "client", id.Name, "role", id.Role, "remote", clientHost(r))
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	}
+}
+
+// checkOrigin decides whether a browser on another site may open a WebSocket.
+// It may not: with authentication on the browser would send the dashboard's
+// cookie or basic auth credentials along, and with it off any site could
+// drive a dashboard on localhost. Clients that send no Origin, i.e. aren't
+// browsers, are let through.
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agent-swarm-go/pkg/swarm"
+)
+
+const (
+	viewerKey = "viewer-key"
+	teamKey   = "team-a-key"
+)
+
+// newAuthServer serves a swarm with a team-a namespace, a viewer key good
+// everywhere, and an operator key good only in team-a
+func newAuthServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer(swarm.NewSwarm())
+	if _, err := s.AddNamespace("team-a", swarm.NewSwarm()); err != nil {
+		t.Fatal(err)
+	}
+	s.SetAuth(AuthConfig{
+		Token: "admin-token",
+		Keys: []APIKey{
+			{Name: "dash", Key: viewerKey, Role: RoleViewer},
+			{Name: "team-a-ci", Key: teamKey, Role: RoleOperator, Namespaces: []string{"team-a"}},
+		},
+	})
+	return s
+}
+
+// serve sends a request with key as its bearer token and returns the status
+func serve(s *Server, method, path, key string) int {
+	r := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestRolesLimitRequests(t *testing.T) {
+	s := newAuthServer(t)
+	tests := []struct {
+		method, path, key string
+		want              int
+	}{
+		{"GET", "/api/me", "", http.StatusUnauthorized},
+		{"GET", "/api/me", "wrong", http.StatusUnauthorized},
+		{"GET", "/api/me", viewerKey, http.StatusOK},
+		{"POST", "/api/tasks", viewerKey, http.StatusForbidden},
+		{"GET", "/api/agents", viewerKey, http.StatusOK},
+		{"POST", "/api/agents", teamKey, http.StatusForbidden}, // Admin only, even in team-a
+		{"POST", "/api/me", "admin-token", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		path := tt.path
+		if tt.key == teamKey {
+			path = "/ns/team-a" + path
+		}
+		if got := serve(s, tt.method, path, tt.key); got != tt.want {
+			t.Errorf("%s %s with %q = %d; want %d", tt.method, path, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestKeyScopedToNamespace(t *testing.T) {
+	s := newAuthServer(t)
+	if got := serve(s, "GET", "/ns/team-a/api/me", teamKey); got != http.StatusOK {
+		t.Errorf("team-a key in team-a = %d; want 200", got)
+	}
+	if got := serve(s, "GET", "/api/me", teamKey); got != http.StatusForbidden {
+		t.Errorf("team-a key in the default namespace = %d; want 403", got)
+	}
+	if got := serve(s, "GET", "/ns/team-a/api/me", viewerKey); got != http.StatusOK {
+		t.Errorf("unscoped key in team-a = %d; want 200", got)
+	}
+}
+
+func TestQueryTokenCookie(t *testing.T) {
+	s := newAuthServer(t)
+	for _, target := range []string{"http://dash.example/api/me?token=admin-token", "https://dash.example/api/me?token=admin-token"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", target, nil)
+		s.Handler().ServeHTTP(w, r)
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != authCookieName {
+			t.Fatalf("%s set cookies %v; want %s", target, cookies, authCookieName)
+		}
+		if c := cookies[0]; !c.HttpOnly || c.Secure != (r.TLS != nil) {
+			t.Errorf("%s: HttpOnly %v, Secure %v; want HttpOnly, Secure only over TLS", target, c.HttpOnly, c.Secure)
+		}
+	}
+}
+
+func TestCheckOriginWithoutAuth(t *testing.T) {
+	s := NewServer(swarm.NewSwarm())
+	s.SetAuth(AuthConfig{})
+	for origin, want := range map[string]bool{
+		"":                      true, // Not a browser
+		"http://localhost:8080": true,
+		"https://evil.example":  false,
+		"http://localhost:9999": false,
+	} {
+		r := httptest.NewRequest("GET", "http://localhost:8080/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := s.checkOrigin(r); got != want {
+			t.Errorf("checkOrigin from %q = %v; want %v", origin, got, want)
+		}
+	}
+}
+
+func TestValidateRejectsHalfBasicAuth(t *testing.T) {
+	if err := (AuthConfig{Username: "admin"}).Validate(); err == nil {
+		t.Error("user without password accepted")
+	}
+	if err := (AuthConfig{Username: "admin", Password: "pw"}).Validate(); err != nil {
+		t.Errorf("complete basic auth rejected: %v", err)
+	}
+}
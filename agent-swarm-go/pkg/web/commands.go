@@ -0,0 +1,131 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/redact"
+	"agent-swarm-go/pkg/types"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsClient is a connected WebSocket client. The broadcaster and the command
+// replies write from different goroutines, which gorilla/websocket does not
+// allow on the same connection, so writes are serialized here.
+type wsClient struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// writeJSON sends v to the client, with secrets scrubbed (see pkg/redact)
+func (c *wsClient) writeJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.conn.WriteMessage(websocket.TextMessage, []byte(redact.String(string(data))))
+}
+
+// Command is a control message sent by a client over /ws, or as the body of
+// POST /api/commands:
+//
+//	{"id": "1", "command": "pause", "agent_id": "researcher-1"}
+//	{"id": "2", "command": "resume", "agent_id": "researcher-1"}
+//	{"id": "3", "command": "cancel", "task_id": "research-1712345678"}
+//	{"id": "4", "command": "approve", "approval_id": "review-1712345678", "output": "edited text"}
+//	{"id": "5", "command": "reject", "approval_id": "review-1712345678"}
+//
+// Pausing and resuming agents needs the admin role, the others the operator
+// role (see Role).
+//
+// Every command is answered with a CommandResult carrying the same ID. The
+// resulting state change also arrives as a regular event (agent_paused,
+// agent_resumed, task_failed, or approval_resolved).
+type Command struct {
+	ID         string `json:"id,omitempty"` // Echoed in the reply so clients can match it
+	Command    string `json:"command"`      // "pause", "resume", "cancel", "approve", or "reject"
+	AgentID    string `json:"agent_id,omitempty"`
+	TaskID     string `json:"task_id,omitempty"`
+	ApprovalID string `json:"approval_id,omitempty"`
+	Output     string `json:"output,omitempty"` // approve: replaces the reviewed output when set
+}
+
+// CommandResult is the reply to a Command
+type CommandResult struct {
+	Type    string `json:"type"` // Always "command_result"
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleCommand decodes and executes one command message from a client with
+// the given role
+func (s *Server) handleCommand(data []byte, id identity) CommandResult {
+	var cmd Command
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return CommandResult{Type: "command_result", OK: false, Error: fmt.Sprintf("invalid command JSON: %v", err)}
+	}
+
+	result := CommandResult{Type: "command_result", ID: cmd.ID, Command: cmd.Command, OK: true}
+	if need := commandRole(cmd.Command); !id.Role.Allows(need) {
+		result.OK = false
+		result.Error = fmt.Sprintf("%s role required", need)
+		return result
+	}
+	if s.auth.Enabled() {
+		slog.Info("dashboard command", "command", cmd.Command, logging.KeyAgent, cmd.AgentID, logging.KeyTask, cmd.TaskID, "client", id.Name, "role", id.Role)
+	}
+	if err := s.executeCommand(cmd); err != nil {
+		result.OK = false
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// commandRole returns the role a command needs
+func commandRole(command string) Role {
+	if command == "pause" || command == "resume" {
+		return RoleAdmin
+	}
+	return RoleOperator
+}
+
+// executeCommand carries out a command against the swarm
+func (s *Server) executeCommand(cmd Command) error {
+	switch cmd.Command {
+	case "pause", "resume":
+		if cmd.AgentID == "" {
+			return fmt.Errorf("%s requires agent_id", cmd.Command)
+		}
+		if cmd.Command == "pause" {
+			return s.swarm.PauseAgent(cmd.AgentID)
+		}
+		return s.swarm.ResumeAgent(cmd.AgentID)
+	case "cancel":
+		if cmd.TaskID == "" {
+			return fmt.Errorf("cancel requires task_id")
+		}
+		return s.swarm.CancelTask(cmd.TaskID)
+	case "approve", "reject":
+		if cmd.ApprovalID == "" {
+			return fmt.Errorf("%s requires approval_id", cmd.Command)
+		}
+		return s.swarm.ResolveApproval(types.ApprovalDecision{
+			ID:       cmd.ApprovalID,
+			Approved: cmd.Command == "approve",
+			Output:   cmd.Output,
+			By:       "dashboard",
+		})
+	default:
+		return fmt.Errorf("unknown command %q", cmd.Command)
+	}
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Download formats for GET /api/results/{taskID}/download?format=
+const (
+	downloadMarkdown = "md"
+	downloadJSON     = "json"
+)
+
+// unsafeFilename matches the characters replaced in a download's file name
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// handleResultDownload serves a finished task's result as a file:
+// GET /api/results/{taskID}/download?format=md (default) or format=json
+func (s *Server) handleResultDownload(w http.ResponseWriter, r *http.Request, taskID string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to download a result")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = downloadMarkdown
+	}
+	if format != downloadMarkdown && format != downloadJSON {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown format %q (want %s or %s)", format, downloadMarkdown, downloadJSON))
+		return
+	}
+
+	s.resultsMu.RLock()
+	result, ok := s.results[taskID]
+	s.resultsMu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no result for task %s yet", taskID))
+		return
+	}
+
+	var body []byte
+	contentType := "text/markdown; charset=utf-8"
+	if format == downloadJSON {
+		var err error
+		if body, err = json.MarshalIndent(result, "", "  "); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		contentType = "application/json"
+	} else {
+		body = []byte(resultMarkdown(result))
+	}
+
+	filename := unsafeFilename.ReplaceAllString(taskID, "-") + "." + format
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// resultMarkdown renders a task result as a Markdown document: a short header
+// describing the task, then the agent's output as written
+func resultMarkdown(result TaskResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Task %s\n\n", result.TaskID)
+	fmt.Fprintf(&b, "- Agent: %s\n", result.AgentID)
+	if result.RunID != "" {
+		fmt.Fprintf(&b, "- Run: %s\n", result.RunID)
+	}
+	fmt.Fprintf(&b, "- Completed: %s\n", result.CompletedAt.Format("2006-01-02 15:04:05"))
+	if result.Success {
+		b.WriteString("- Status: succeeded\n")
+	} else {
+		status := "failed"
+		if result.Failure != "" {
+			status += " (" + result.Failure + ")"
+		}
+		fmt.Fprintf(&b, "- Status: %s\n", status)
+	}
+	if result.Error != "" {
+		fmt.Fprintf(&b, "- Error: %s\n", result.Error)
+	}
+	if d := result.Metadata.Duration(); d > 0 {
+		fmt.Fprintf(&b, "- Duration: %s\n", d.Round(time.Millisecond))
+	}
+	if m := result.Metadata; m.Provider != "" {
+		fmt.Fprintf(&b, "- Model: %s\n", strings.TrimSpace(m.Provider+" "+m.Model))
+	}
+	if m := result.Metadata; m.InputTokens+m.OutputTokens > 0 {
+		fmt.Fprintf(&b, "- Tokens: %d in, %d out ($%.4f)\n", m.InputTokens, m.OutputTokens, m.CostUSD)
+	}
+	if result.Metadata.Attempts > 1 {
+		fmt.Fprintf(&b, "- Attempts: %d\n", result.Metadata.Attempts)
+	}
+
+	b.WriteString("\n---\n\n")
+	switch data := result.Data.(type) {
+	case nil:
+	case string:
+		b.WriteString(strings.TrimSpace(data))
+	case fmt.Stringer:
+		b.WriteString(strings.TrimSpace(data.String()))
+	default:
+		// Structured output an agent didn't render as text
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			fmt.Fprintf(&b, "%v", data)
+		} else {
+			fmt.Fprintf(&b, "```json\n%s\n```", encoded)
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+// ListenConfig says where the dashboard listens and whether it serves HTTPS
+type ListenConfig struct {
+	Address string // Interface to bind, e.g. "127.0.0.1"; empty binds every interface
+	Port    int
+	TLSCert string // PEM certificate file; with TLSKey, the dashboard serves HTTPS
+	TLSKey  string // PEM private key file
+}
+
+// FromEnv overrides the settings with DASHBOARD_ADDR, DASHBOARD_PORT,
+// DASHBOARD_TLS_CERT, and DASHBOARD_TLS_KEY where they are set
+func (lc ListenConfig) FromEnv() ListenConfig {
+	if addr := os.Getenv("DASHBOARD_ADDR"); addr != "" {
+		lc.Address = addr
+	}
+	if port, err := strconv.Atoi(os.Getenv("DASHBOARD_PORT")); err == nil {
+		lc.Port = port
+	}
+	if cert := os.Getenv("DASHBOARD_TLS_CERT"); cert != "" {
+		lc.TLSCert = cert
+	}
+	if key := os.Getenv("DASHBOARD_TLS_KEY"); key != "" {
+		lc.TLSKey = key
+	}
+	return lc
+}
+
+// TLS reports whether the dashboard serves HTTPS
+func (lc ListenConfig) TLS() bool {
+	return lc.TLSCert != "" && lc.TLSKey != ""
+}
+
+// Addr is the host:port to listen on
+func (lc ListenConfig) Addr() string {
+	return net.JoinHostPort(lc.Address, strconv.Itoa(lc.Port))
+}
+
+// URL is where to open the dashboard, with localhost standing in for every
+// interface
+func (lc ListenConfig) URL() string {
+	host := lc.Address
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	scheme := "http"
+	if lc.TLS() {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(lc.Port))
+}
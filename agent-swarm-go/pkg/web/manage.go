@@ -0,0 +1,126 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/swarm"
+)
+
+// AddAgentRequest is the JSON body accepted by POST /api/agents
+type AddAgentRequest struct {
+	Type string `json:"type"`         // Agent type, e.g. "research"
+	ID   string `json:"id,omitempty"` // Generated when empty
+}
+
+// SetAgentBuilder lets admins add agents with POST /api/agents, building them
+// with build. Without it the endpoint is disabled. Call before Start.
+func (s *Server) SetAgentBuilder(build swarm.AgentBuilder) {
+	s.buildAgent = build
+}
+
+// addAgent builds an agent from a POST /api/agents body and adds it to the
+// running swarm
+func (s *Server) addAgent(w http.ResponseWriter, r *http.Request) {
+	if s.buildAgent == nil {
+		writeError(w, http.StatusNotImplemented, "adding agents is not enabled on this server")
+		return
+	}
+
+	var req AddAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid agent JSON: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.Type) == "" {
+		writeError(w, http.StatusBadRequest, "type is required")
+		return
+	}
+	if strings.Contains(req.ID, "/") {
+		writeError(w, http.StatusBadRequest, "id must not contain /")
+		return
+	}
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("%s-%d", req.Type, time.Now().UnixNano())
+	}
+	if _, err := s.swarm.GetAgent(req.ID); err == nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("agent %s already exists", req.ID))
+		return
+	}
+
+	agent, err := s.buildAgent(req.ID, req.Type)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.swarm.JoinAgent(agent); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"id": agent.GetID()})
+}
+
+// removeAgent stops an agent and takes it out of the swarm: DELETE /api/agents/{id}
+func (s *Server) removeAgent(w http.ResponseWriter, r *http.Request) {
+	agentID := strings.TrimPrefix(r.URL.Path, "/api/agents/")
+	if agentID == "" || strings.Contains(agentID, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/agents/{id}")
+		return
+	}
+	if _, err := s.swarm.GetAgent(agentID); err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("agent %s not found", agentID))
+		return
+	}
+
+	if err := s.swarm.RemoveAgent(agentID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// controlAgent pauses or resumes an agent: POST /api/agents/{id}/pause or
+// /api/agents/{id}/resume. A paused agent keeps its queued tasks until it is
+// resumed. The answer is the agent as GET /api/agents lists it, state
+// afterwards and inbox depth included.
+func (s *Server) controlAgent(w http.ResponseWriter, r *http.Request) {
+	agentID, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/agents/"), "/")
+	if agentID == "" || (action != "pause" && action != "resume") {
+		writeError(w, http.StatusNotFound, "expected /api/agents/{id}/pause or /api/agents/{id}/resume")
+		return
+	}
+	agent, err := s.swarm.GetAgent(agentID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("agent %s not found", agentID))
+		return
+	}
+
+	control := s.swarm.PauseAgent
+	if action == "resume" {
+		control = s.swarm.ResumeAgent
+	}
+	// Stopped agents, and agents that can't be paused, stay as they are
+	if err := control(agentID); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":          agentID,
+		"state":       string(agent.GetState()),
+		"specialty":   agent.GetSpecialty(),
+		"inbox_depth": s.swarm.InboxDepths()[agentID],
+	})
+}
+
+// handleMe tells the dashboard which role its client has, so it can hide the
+// controls it may not use: GET /api/me
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]Role{"role": roleOf(r)})
+}
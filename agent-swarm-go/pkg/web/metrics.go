@@ -0,0 +1,175 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+const (
+	// metricsBucketSize is the resolution of the metrics history
+	metricsBucketSize = time.Minute
+	// metricsRetention is how many buckets are kept (24 hours at one per minute)
+	metricsRetention = 24 * 60
+	// defaultMetricsWindow is how many buckets /api/metrics/history returns by default
+	defaultMetricsWindow = 60
+)
+
+// MetricsBucket aggregates task outcomes for one minute
+type MetricsBucket struct {
+	Start          time.Time `json:"start"`
+	Completed      int       `json:"completed"`
+	Failed         int       `json:"failed"`
+	AvgLatencyMs   float64   `json:"avg_latency_ms"`    // Started → completed/failed, averaged over tasks with a known start
+	AvgQueueWaitMs float64   `json:"avg_queue_wait_ms"` // Submitted → started, averaged over results that report both
+
+	latencyTotal time.Duration
+	latencyCount int
+	waitTotal    time.Duration
+	waitCount    int
+}
+
+// MetricsHistory is the JSON body of GET /api/metrics/history
+type MetricsHistory struct {
+	BucketSeconds  int             `json:"bucket_seconds"`
+	Buckets        []MetricsBucket `json:"buckets"` // Oldest first, one per bucket including empty ones
+	TotalCompleted int             `json:"total_completed"`
+	TotalFailed    int             `json:"total_failed"`
+}
+
+// metricsRecorder keeps per-minute task metrics in memory so the dashboard
+// can show history that survives a page reload
+type metricsRecorder struct {
+	mu             sync.Mutex
+	buckets        []*MetricsBucket     // Oldest first, only buckets that saw activity
+	started        map[string]time.Time // Task ID -> first task_started time
+	totalCompleted int
+	totalFailed    int
+}
+
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{started: make(map[string]time.Time)}
+}
+
+// record updates the metrics from a swarm event
+func (m *metricsRecorder) record(event types.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch event.Type {
+	case types.EventTaskStarted:
+		// Keep the first start so redelivered tasks count their full latency
+		if _, ok := m.started[event.TaskID]; !ok {
+			m.started[event.TaskID] = event.Timestamp
+		}
+
+	case types.EventTaskCompleted, types.EventTaskFailed:
+		bucket := m.bucketFor(event.Timestamp)
+		if event.Type == types.EventTaskCompleted {
+			bucket.Completed++
+			m.totalCompleted++
+		} else {
+			bucket.Failed++
+			m.totalFailed++
+		}
+		if start, ok := m.started[event.TaskID]; ok {
+			delete(m.started, event.TaskID)
+			bucket.latencyTotal += event.Timestamp.Sub(start)
+			bucket.latencyCount++
+			bucket.AvgLatencyMs = float64(bucket.latencyTotal.Milliseconds()) / float64(bucket.latencyCount)
+		}
+		if result, ok := event.Data.(types.Result); ok && result.Metadata.Submitted != nil && result.Metadata.Started != nil {
+			bucket.waitTotal += result.Metadata.QueueWait()
+			bucket.waitCount++
+			bucket.AvgQueueWaitMs = float64(bucket.waitTotal.Milliseconds()) / float64(bucket.waitCount)
+		}
+		m.prune(event.Timestamp)
+	}
+}
+
+// bucketFor returns the bucket covering t, creating it if needed.
+// Caller must hold m.mu.
+func (m *metricsRecorder) bucketFor(t time.Time) *MetricsBucket {
+	start := t.Truncate(metricsBucketSize)
+	for i := len(m.buckets) - 1; i >= 0; i-- {
+		if m.buckets[i].Start.Equal(start) {
+			return m.buckets[i]
+		}
+		if m.buckets[i].Start.Before(start) {
+			break
+		}
+	}
+
+	bucket := &MetricsBucket{Start: start}
+	m.buckets = append(m.buckets, bucket)
+	// Events normally arrive in order; keep the slice sorted if one doesn't
+	for i := len(m.buckets) - 1; i > 0 && m.buckets[i].Start.Before(m.buckets[i-1].Start); i-- {
+		m.buckets[i], m.buckets[i-1] = m.buckets[i-1], m.buckets[i]
+	}
+	return bucket
+}
+
+// prune drops buckets past the retention window and start times of tasks
+// that never finished. Caller must hold m.mu.
+func (m *metricsRecorder) prune(now time.Time) {
+	cutoff := now.Add(-metricsRetention * metricsBucketSize)
+
+	keep := 0
+	for keep < len(m.buckets) && m.buckets[keep].Start.Before(cutoff) {
+		keep++
+	}
+	m.buckets = m.buckets[keep:]
+
+	for taskID, start := range m.started {
+		if start.Before(cutoff) {
+			delete(m.started, taskID)
+		}
+	}
+}
+
+// history returns the last window buckets ending at now, filling gaps with empty buckets
+func (m *metricsRecorder) history(now time.Time, window int) MetricsHistory {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	end := now.Truncate(metricsBucketSize)
+	first := end.Add(-time.Duration(window-1) * metricsBucketSize)
+
+	byStart := make(map[time.Time]*MetricsBucket, len(m.buckets))
+	for _, bucket := range m.buckets {
+		byStart[bucket.Start] = bucket
+	}
+
+	history := MetricsHistory{
+		BucketSeconds:  int(metricsBucketSize / time.Second),
+		Buckets:        make([]MetricsBucket, 0, window),
+		TotalCompleted: m.totalCompleted,
+		TotalFailed:    m.totalFailed,
+	}
+	for start := first; !start.After(end); start = start.Add(metricsBucketSize) {
+		if bucket, ok := byStart[start]; ok {
+			history.Buckets = append(history.Buckets, *bucket)
+		} else {
+			history.Buckets = append(history.Buckets, MetricsBucket{Start: start})
+		}
+	}
+	return history
+}
+
+// handleMetricsHistory serves GET /api/metrics/history?minutes=N (default 60, max 1440)
+func (s *Server) handleMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	window := defaultMetricsWindow
+	if raw := r.URL.Query().Get("minutes"); raw != "" {
+		minutes, err := strconv.Atoi(raw)
+		if err != nil || minutes < 1 || minutes > metricsRetention {
+			writeError(w, http.StatusBadRequest, "minutes must be between 1 and 1440")
+			return
+		}
+		window = minutes
+	}
+
+	writeJSON(w, http.StatusOK, s.metrics.history(time.Now(), window))
+}
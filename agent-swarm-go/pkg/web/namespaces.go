@@ -0,0 +1,104 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"agent-swarm-go/pkg/swarm"
+)
+
+// DefaultNamespace names the swarm the server was created with
+const DefaultNamespace = "default"
+
+// namespacePrefix is where each namespace's dashboard and API are served
+const namespacePrefix = "/ns/"
+
+// namespaceName is what a namespace may be called, so it can go in a URL path
+var namespaceName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Namespace is one entry of GET /api/namespaces
+type Namespace struct {
+	Name string `json:"name"`
+	Path string `json:"path"` // Where its dashboard is served
+}
+
+// AddNamespace serves another swarm of the same process, e.g. one team's, as
+// a namespace: its dashboard and the whole API are served under
+// /ns/{name}/, from its own agents and events. The dashboard lets users
+// switch between namespaces. Access control is shared with s, including
+// later SetAuth calls, except that an API key scoped to other namespaces (see
+// APIKey.Namespaces) isn't accepted.
+//
+// The returned Server is the namespace's, for SetAgentBuilder and
+// SetArchive. Call before Start.
+func (s *Server) AddNamespace(name string, ns *swarm.Swarm) (*Server, error) {
+	if err := ValidateNamespace(name); err != nil {
+		return nil, err
+	}
+	if _, exists := s.namespaces[name]; exists {
+		return nil, fmt.Errorf("namespace %q already exists", name)
+	}
+
+	child := NewServer(ns)
+	child.auth = s.auth
+	child.textLimit.Store(s.textLimit.Load())
+	child.root = s
+	child.name = name
+	s.namespaces[name] = child
+
+	prefix := namespacePrefix + name
+	s.mux.Handle(prefix+"/", http.StripPrefix(prefix, child.mux))
+	return child, nil
+}
+
+// ValidateNamespace checks that name can be a namespace's
+func ValidateNamespace(name string) error {
+	if !namespaceName.MatchString(name) {
+		return fmt.Errorf("namespace %q: use lowercase letters, digits, - and _", name)
+	}
+	if name == DefaultNamespace {
+		return fmt.Errorf("namespace %q is the server's own swarm", name)
+	}
+	return nil
+}
+
+// namespace returns the name of the namespace s serves
+func (s *Server) namespace() string {
+	if s.name == "" {
+		return DefaultNamespace
+	}
+	return s.name
+}
+
+// Namespaces lists the default namespace and every added one, by name
+func (s *Server) Namespaces() []Namespace {
+	root := s
+	if s.root != nil {
+		root = s.root
+	}
+
+	list := []Namespace{{Name: DefaultNamespace, Path: "/"}}
+	for name := range root.namespaces {
+		list = append(list, Namespace{Name: name, Path: namespacePrefix + name + "/"})
+	}
+	sort.Slice(list[1:], func(i, j int) bool { return list[i+1].Name < list[j+1].Name })
+	return list
+}
+
+// handleNamespaces lists the namespaces the client may use: GET /api/namespaces
+func (s *Server) handleNamespaces(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list namespaces")
+		return
+	}
+	id := identityOf(r)
+	list := []Namespace{}
+	for _, ns := range s.Namespaces() {
+		if id.allowedIn(ns.Name) {
+			list = append(list, ns)
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
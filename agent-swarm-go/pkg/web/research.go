@@ -0,0 +1,265 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/workflows"
+)
+
+// maxResearchRuns is how many research runs started over HTTP are
+// remembered; the oldest finished runs are forgotten first. Finished runs
+// stay in the run archive (/api/runs) when it is enabled.
+const maxResearchRuns = 100
+
+// Research run and step statuses
+const (
+	researchPending   = "pending" // Steps only
+	researchRunning   = "running"
+	researchCompleted = "completed"
+	researchFailed    = "failed"
+)
+
+// ResearchRequest is the JSON body of POST /api/workflows/research
+type ResearchRequest struct {
+	Topic           string   `json:"topic"`
+	Languages       []string `json:"languages,omitempty"`            // Translate the report into these; needs a translation agent
+	StepTimeoutSecs float64  `json:"step_timeout_seconds,omitempty"` // Per step; 0 means workflows.DefaultStepTimeout
+	Locale          string   `json:"locale,omitempty"`               // Language tag the agents write in, e.g. "ja"
+}
+
+// ResearchRun is the JSON shape of a research workflow started over HTTP,
+// served by GET /api/workflows/research/{workflowID}
+type ResearchRun struct {
+	WorkflowID string         `json:"workflow_id"`
+	RunID      string         `json:"run_id"` // Filter the /ws event stream on this to follow the run live
+	Topic      string         `json:"topic"`
+	Status     string         `json:"status"` // running, completed, or failed
+	Steps      []ResearchStep `json:"steps"`  // Empty until the workflow announces its plan
+	Started    time.Time      `json:"started"`
+	Finished   *time.Time     `json:"finished,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// ResearchStep is the progress of one step of a ResearchRun
+type ResearchStep struct {
+	Name   string `json:"name"`
+	TaskID string `json:"task_id"`
+	Status string `json:"status"` // pending, running, completed, or failed
+}
+
+// researchRun is a ResearchRun and, once it finishes, its result
+type researchRun struct {
+	ResearchRun
+	result *workflows.WorkflowResult
+}
+
+// researchRuns tracks the research workflows started over HTTP
+type researchRuns struct {
+	mu    sync.Mutex
+	runs  map[string]*researchRun // By workflow ID
+	order []string                // Workflow IDs, oldest first
+	byRun map[string]string       // Run ID -> workflow ID
+}
+
+func newResearchRuns() *researchRuns {
+	return &researchRuns{runs: make(map[string]*researchRun), byRun: make(map[string]string)}
+}
+
+// start records a run that is about to begin
+func (rr *researchRuns) start(run ResearchRun) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.runs[run.WorkflowID] = &researchRun{ResearchRun: run}
+	rr.byRun[run.RunID] = run.WorkflowID
+	rr.order = append(rr.order, run.WorkflowID)
+
+	excess := len(rr.runs) - maxResearchRuns
+	kept := rr.order[:0]
+	for _, id := range rr.order {
+		if old := rr.runs[id]; excess > 0 && old.Finished != nil {
+			delete(rr.runs, id)
+			delete(rr.byRun, old.RunID)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	rr.order = kept
+}
+
+// finish records how a run ended
+func (rr *researchRuns) finish(workflowID string, result *workflows.WorkflowResult, err error) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	run, ok := rr.runs[workflowID]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	run.Finished = &now
+	run.result = result
+	if err != nil {
+		run.Status = researchFailed
+		run.Error = err.Error()
+		return
+	}
+	run.Status = researchCompleted
+}
+
+// record updates a run's steps from its workflow and task events
+func (rr *researchRuns) record(event types.Event) {
+	if event.RunID == "" {
+		return
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	run, ok := rr.runs[rr.byRun[event.RunID]]
+	if !ok {
+		return
+	}
+
+	var status string
+	switch event.Type {
+	case types.EventWorkflowStarted:
+		if plan, ok := event.Data.(types.WorkflowPlan); ok && plan.WorkflowID == run.WorkflowID {
+			run.Steps = make([]ResearchStep, len(plan.Steps))
+			for i, step := range plan.Steps {
+				run.Steps[i] = ResearchStep{Name: step.Name, TaskID: step.TaskID, Status: researchPending}
+			}
+		}
+		return
+	case types.EventTaskStarted:
+		status = researchRunning
+	case types.EventTaskCompleted:
+		status = researchCompleted
+	case types.EventTaskFailed:
+		status = researchFailed
+	default:
+		return
+	}
+	for i := range run.Steps {
+		if run.Steps[i].TaskID == event.TaskID {
+			run.Steps[i].Status = status
+		}
+	}
+}
+
+// get returns a copy of a run and its result, if it has finished
+func (rr *researchRuns) get(workflowID string) (ResearchRun, *workflows.WorkflowResult, bool) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	run, ok := rr.runs[workflowID]
+	if !ok {
+		return ResearchRun{}, nil, false
+	}
+	copied := run.ResearchRun
+	copied.Steps = append([]ResearchStep{}, run.Steps...)
+	return copied, run.result, true
+}
+
+// handleResearch starts a research workflow: POST /api/workflows/research.
+// It answers at once with the run; follow it with GET
+// /api/workflows/research/{workflowID} or the /ws stream.
+func (s *Server) handleResearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST to start a research workflow")
+		return
+	}
+
+	var req ResearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid research JSON: %v", err))
+		return
+	}
+	topic := strings.TrimSpace(req.Topic)
+	if topic == "" {
+		writeError(w, http.StatusBadRequest, "topic is required")
+		return
+	}
+	if req.StepTimeoutSecs < 0 {
+		writeError(w, http.StatusBadRequest, "step_timeout_seconds must not be negative")
+		return
+	}
+	if len(req.Languages) > 0 && !s.swarm.HasSpecialty("translation") {
+		writeError(w, http.StatusBadRequest, "languages need a translation agent in the swarm")
+		return
+	}
+	if err := types.ValidateLocale(req.Locale); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	run := ResearchRun{
+		WorkflowID: fmt.Sprintf("workflow-%d", time.Now().UnixNano()),
+		RunID:      types.NewRunID(),
+		Topic:      topic,
+		Status:     researchRunning,
+		Steps:      []ResearchStep{},
+		Started:    time.Now(),
+	}
+	workflow := workflows.NewResearchWorkflow(s.swarm)
+	workflow.SetWorkflowID(run.WorkflowID)
+	workflow.SetRunID(run.RunID)
+	workflow.SetLanguages(req.Languages...)
+	workflow.SetLocale(req.Locale)
+	workflow.SetStepTimeout(time.Duration(req.StepTimeoutSecs * float64(time.Second)))
+
+	s.research.start(run)
+	go func() {
+		result, err := workflow.Execute(topic)
+		if err != nil {
+			slog.Warn("research workflow failed", "workflow", run.WorkflowID, logging.KeyError, err)
+		}
+		s.research.finish(run.WorkflowID, result, err)
+	}()
+
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// handleResearchDetail serves a research run's progress,
+// GET /api/workflows/research/{workflowID}, or its finished result,
+// GET /api/workflows/research/{workflowID}/report
+func (s *Server) handleResearchDetail(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/workflows/research/")
+	id, report := strings.CutSuffix(id, "/report")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "expected /api/workflows/research/{workflowID}[/report]")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to read a research run")
+		return
+	}
+
+	run, result, ok := s.research.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no research run %s", id))
+		return
+	}
+	if !report {
+		writeJSON(w, http.StatusOK, run)
+		return
+	}
+
+	switch run.Status {
+	case researchRunning:
+		writeError(w, http.StatusConflict, fmt.Sprintf("research run %s is still running", id))
+	case researchFailed:
+		writeError(w, http.StatusConflict, fmt.Sprintf("research run %s failed: %s", id, run.Error))
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
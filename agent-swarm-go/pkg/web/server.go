@@ -1,40 +1,75 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"agent-swarm-go/pkg/agentstats"
+	"agent-swarm-go/pkg/archive"
+	"agent-swarm-go/pkg/logging"
 	"agent-swarm-go/pkg/swarm"
 	"agent-swarm-go/pkg/types"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for demo
-	},
-}
-
 // Server manages the web dashboard
 type Server struct {
 	swarm       *swarm.Swarm
-	clients     map[*websocket.Conn]bool
-	clientsMu   sync.RWMutex
+	clients     map[*websocket.Conn]*wsClient
+	sseClients  map[*sseClient]struct{} // Streams of /api/events (see sse.go)
+	clientsMu   sync.RWMutex            // Guards clients and sseClients
 	eventStream chan types.Event
+	auth        AuthConfig
+	results     map[string]TaskResult // Finished task results by task ID (see api.go)
+	resultOrder []string              // Task IDs in results, recorded longest ago first
+	resultsMu   sync.RWMutex
+	metrics     *metricsRecorder   // Per-minute task history (see metrics.go)
+	activity    *activityLog       // Recent events per agent (see agent_detail.go)
+	archive     *archive.Archive   // Past workflow runs; nil disables /api/runs
+	agentStats  *agentstats.Store  // Per-agent totals; nil disables /api/agents/{id}/stats
+	research    *researchRuns      // Research workflows started over HTTP (see research.go)
+	events      *eventLog          // Numbered recent events for resuming /ws (see stream.go)
+	textLimit   atomic.Int64       // Bytes of text per /ws event (see SetEventTextLimit)
+	buildAgent  swarm.AgentBuilder // Builds agents added over the API; nil disables it (see manage.go)
+	namespaces  map[string]*Server // Other swarms served under /ns/{name}/ (see namespaces.go)
+	root        *Server            // The server a namespace's is mounted on; nil for that server
+	name        string             // The namespace's name; "" for the server it is mounted on
+	mux         *http.ServeMux
+	httpServer  *http.Server // Set by Start; nil until then
+	httpMu      sync.Mutex
 }
 
-// NewServer creates a new web server
+// NewServer creates a new web server.
+// Authentication is configured from DASHBOARD_TOKEN / DASHBOARD_USER /
+// DASHBOARD_PASSWORD; use SetAuth to override.
 func NewServer(s *swarm.Swarm) *Server {
 	server := &Server{
 		swarm:       s,
-		clients:     make(map[*websocket.Conn]bool),
+		clients:     make(map[*websocket.Conn]*wsClient),
+		sseClients:  make(map[*sseClient]struct{}),
 		eventStream: s.GetEventBus().Subscribe(),
+		auth:        AuthConfigFromEnv(),
+		results:     make(map[string]TaskResult),
+		metrics:     newMetricsRecorder(),
+		activity:    newActivityLog(),
+		research:    newResearchRuns(),
+		events:      &eventLog{},
+		mux:         http.NewServeMux(),
+		namespaces:  make(map[string]*Server),
 	}
+	server.textLimit.Store(DefaultEventTextLimit)
+	server.routes()
+
+	// Results are recorded as they are published, not from the event stream,
+	// which drops events when it falls behind
+	s.GetEventBus().Observe(server.recordResult)
 
 	// Start broadcasting events to websocket clients
 	go server.broadcastEvents()
@@ -42,16 +77,131 @@ func NewServer(s *swarm.Swarm) *Server {
 	return server
 }
 
-// Start starts the web server
+// SetAuth replaces the authentication configuration, for the namespaces too.
+// Call before Start.
+func (s *Server) SetAuth(auth AuthConfig) {
+	s.auth = auth
+	for _, ns := range s.namespaces {
+		ns.SetAuth(auth)
+	}
+}
+
+// SetArchive serves past workflow runs from a at /api/runs. Call before Start.
+func (s *Server) SetArchive(a *archive.Archive) {
+	s.archive = a
+}
+
+// SetAgentStats serves each agent's statistics from st at
+// /api/agents/{id}/stats. Call before Start.
+func (s *Server) SetAgentStats(st *agentstats.Store) {
+	s.agentStats = st
+}
+
+// Handler returns the server's routes, e.g. to mount the dashboard in
+// another HTTP server instead of calling Start
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// routes registers every endpoint on the server's own mux
+func (s *Server) routes() {
+	s.mux.HandleFunc("/", s.requireAuth(s.handleIndex))
+	s.mux.HandleFunc("/ws", s.requireAuth(s.handleWebSocket))
+	s.mux.HandleFunc("/api/events", s.requireAuth(s.handleEvents))
+	s.mux.HandleFunc("/api/commands", s.requireAuth(s.handleCommands))
+	s.mux.HandleFunc("/api/status", s.requireAuth(s.handleStatus))
+	s.mux.HandleFunc("/api/me", s.requireAuth(s.handleMe))
+	s.mux.HandleFunc("/api/namespaces", s.requireAuth(s.handleNamespaces))
+	s.mux.HandleFunc("/api/agents", s.requireRole(RoleAdmin, s.handleAgents))
+	s.mux.HandleFunc("/api/agents/", s.requireRole(RoleAdmin, s.handleAgentDetail))
+	s.mux.HandleFunc("/api/tasks", s.requireAuth(s.handleTasks))
+	s.mux.HandleFunc("/api/results/", s.requireAuth(s.handleResult))
+	s.mux.HandleFunc("/api/metrics/history", s.requireAuth(s.handleMetricsHistory))
+	s.mux.HandleFunc("/api/queue", s.requireAuth(s.handleQueue))
+	s.mux.HandleFunc("/api/deadletters", s.requireAuth(s.handleDeadLetters))
+	s.mux.HandleFunc("/api/deadletters/", s.requireAuth(s.handleDeadLetterDetail))
+	s.mux.HandleFunc("/api/leader", s.requireAuth(s.handleLeader))
+	s.mux.HandleFunc("/api/event-types", s.requireAuth(s.handleEventTypes))
+	s.mux.HandleFunc("/api/approvals", s.requireAuth(s.handleApprovals))
+	s.mux.HandleFunc("/api/threads", s.requireAuth(s.handleThreads))
+	s.mux.HandleFunc("/api/threads/", s.requireAuth(s.handleThreadDetail))
+	s.mux.HandleFunc("/api/schedules", s.requireAuth(s.handleSchedules))
+	s.mux.HandleFunc("/api/schedules/", s.requireAuth(s.handleScheduleDetail))
+	s.mux.HandleFunc("/api/runs", s.requireAuth(s.handleRuns))
+	s.mux.HandleFunc("/api/runs/", s.requireAuth(s.handleRunDetail))
+	s.mux.HandleFunc("/api/workflows/research", s.requireAuth(s.handleResearch))
+	s.mux.HandleFunc("/api/workflows/research/", s.requireAuth(s.handleResearchDetail))
+}
+
+// Start serves the dashboard on port on every interface; see Serve
 func (s *Server) Start(port int) error {
-	http.HandleFunc("/", s.handleIndex)
-	http.HandleFunc("/ws", s.handleWebSocket)
-	http.HandleFunc("/api/status", s.handleStatus)
-	http.HandleFunc("/api/agents", s.handleAgents)
-
-	addr := fmt.Sprintf(":%d", port)
-	log.Printf("🌐 Web dashboard starting at http://localhost%s\n", addr)
-	return http.ListenAndServe(addr, nil)
+	return s.Serve(ListenConfig{Port: port})
+}
+
+// Serve serves the dashboard where lc says, over HTTPS when it names a
+// certificate and key, until Shutdown is called, when it returns nil
+func (s *Server) Serve(lc ListenConfig) error {
+	if (lc.TLSCert == "") != (lc.TLSKey == "") {
+		return errors.New("dashboard TLS needs both a certificate and a key")
+	}
+	if err := s.auth.Validate(); err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: lc.Addr(), Handler: s.mux}
+	// Shutdown doesn't track WebSocket connections, so close them itself
+	srv.RegisterOnShutdown(s.closeClients)
+
+	s.httpMu.Lock()
+	s.httpServer = srv
+	s.httpMu.Unlock()
+
+	slog.Info("web dashboard starting", "url", lc.URL(), "auth", s.auth.Enabled(), "tls", lc.TLS())
+	var err error
+	if lc.TLS() {
+		err = srv.ListenAndServeTLS(lc.TLSCert, lc.TLSKey)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the server gracefully: it releases the port, closes the
+// WebSocket clients, and waits for in-flight requests to finish until ctx is
+// done. Call it before stopping the swarm so those requests still find it
+// running.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.httpMu.Lock()
+	srv := s.httpServer
+	s.httpMu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	return srv.Shutdown(ctx)
+}
+
+// closeClients tells every WebSocket client the server and its namespaces
+// are going away and disconnects it
+func (s *Server) closeClients() {
+	for _, ns := range s.namespaces {
+		ns.closeClients()
+	}
+
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	goingAway := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	for conn := range s.clients {
+		conn.WriteControl(websocket.CloseMessage, goingAway, time.Now().Add(writeWait))
+		conn.Close()
+		delete(s.clients, conn)
+	}
+	for client := range s.sseClients {
+		s.dropSSE(client)
+	}
 }
 
 // handleIndex serves the main dashboard HTML
@@ -122,6 +272,25 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
         }
         .agent-status.idle { background: #10b981; }
         .agent-status.processing { background: #f59e0b; }
+        .agent-status.paused { background: #64748b; }
+        .agent-queue { font-size: 0.8em; opacity: 0.8; margin-top: 6px; }
+        .agent-specialty { font-size: 0.8em; opacity: 0.8; margin-bottom: 5px; }
+        .agent-progress { font-size: 0.8em; margin-top: 6px; }
+        .progress-track { background: #1e293b; border-radius: 3px; height: 6px; margin-top: 3px; overflow: hidden; }
+        .progress-fill { background: #f59e0b; height: 100%; transition: width 0.3s; }
+        .agent-control {
+            margin-top: 8px;
+            background: #334155;
+            color: #e2e8f0;
+            border: none;
+            border-radius: 4px;
+            padding: 4px 10px;
+            cursor: pointer;
+            font-size: 0.85em;
+        }
+        .agent-control:hover { background: #475569; }
+        .agent-control.danger { background: #7f1d1d; }
+        body.role-viewer .agent-control, body.role-operator .agent-control.admin-only { display: none; }
         .event-log {
             height: 500px;
             overflow-y: auto;
@@ -148,6 +317,8 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
         .event.started { border-left-color: #f59e0b; }
         .event.completed { border-left-color: #10b981; }
         .event.failed { border-left-color: #ef4444; }
+        .event.redelivered { border-left-color: #a855f7; }
+        .event.limit_exceeded { border-left-color: #f97316; background: #2a1f17; }
         .event-time { color: #64748b; font-size: 0.85em; }
         .event-agent { color: #60a5fa; font-weight: bold; }
         .event-message { margin-top: 5px; }
@@ -210,6 +381,14 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
             color: #64748b;
             font-size: 0.9em;
         }
+        .task-result-download {
+            float: right;
+            margin: 10px 0 0 8px;
+            color: #60a5fa;
+            font-size: 0.85em;
+            text-decoration: none;
+        }
+        .task-result-download:hover { text-decoration: underline; }
         .task-result-content {
             background: #0f172a;
             padding: 15px;
@@ -222,6 +401,11 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
             max-height: 400px;
             overflow-y: auto;
         }
+        .task-result-meta {
+            color: #94a3b8;
+            font-size: 0.85em;
+            margin-top: 10px;
+        }
         .task-result-agent {
             display: inline-block;
             background: #334155;
@@ -236,6 +420,168 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
             margin-top: 5px;
             font-style: italic;
         }
+        .agent-tabs {
+            display: flex;
+            flex-wrap: wrap;
+            gap: 8px;
+            margin-bottom: 15px;
+        }
+        .agent-tab {
+            background: #334155;
+            color: #e2e8f0;
+            border: none;
+            border-radius: 6px;
+            padding: 8px 14px;
+            cursor: pointer;
+        }
+        .agent-tab.active { background: #3b82f6; }
+        .agent-detail-summary {
+            display: grid;
+            grid-template-columns: repeat(4, 1fr);
+            gap: 10px;
+            margin-bottom: 15px;
+        }
+        .agent-detail-field {
+            background: #0f172a;
+            border-radius: 6px;
+            padding: 10px;
+        }
+        .agent-detail-label { color: #94a3b8; font-size: 0.8em; }
+        .agent-detail-value { margin-top: 4px; word-wrap: break-word; }
+        .agent-card { cursor: pointer; }
+        .charts {
+            display: grid;
+            grid-template-columns: repeat(4, 1fr);
+            gap: 15px;
+        }
+        .chart {
+            background: #0f172a;
+            border-radius: 8px;
+            padding: 12px;
+        }
+        .chart-title { color: #94a3b8; font-size: 0.9em; margin-bottom: 8px; }
+        .chart-value { color: #e2e8f0; font-weight: bold; float: right; }
+        .chart svg { width: 100%; height: 120px; display: block; }
+        .event.workflow_started { border-left-color: #6366f1; }
+        .event.workflow_completed { border-left-color: #10b981; }
+        .event.workflow_failed { border-left-color: #ef4444; }
+        .event.budget_exceeded { border-left-color: #f59e0b; }
+        .event.config_changed { border-left-color: #6366f1; }
+        .event.config_rejected { border-left-color: #ef4444; }
+        .workflow {
+            background: #0f172a;
+            border-radius: 8px;
+            padding: 15px;
+            margin-bottom: 15px;
+        }
+        .workflow-title {
+            color: #60a5fa;
+            font-weight: bold;
+            margin-bottom: 12px;
+        }
+        .workflow-graph {
+            position: relative;
+            display: flex;
+            gap: 60px;
+            align-items: center;
+            overflow-x: auto;
+            padding: 10px 0;
+        }
+        .workflow-artifacts {
+            margin-top: 10px;
+            font-size: 0.85em;
+            color: #94a3b8;
+        }
+        .workflow-artifacts a { color: #60a5fa; }
+        .workflow-graph svg {
+            position: absolute;
+            top: 0;
+            left: 0;
+            pointer-events: none;
+        }
+        .graph-column {
+            display: flex;
+            flex-direction: column;
+            gap: 20px;
+        }
+        .graph-node {
+            position: relative;
+            z-index: 1;
+            background: #1e293b;
+            border: 2px solid #475569;
+            border-radius: 8px;
+            padding: 10px 14px;
+            min-width: 140px;
+        }
+        .graph-node-name { font-weight: bold; }
+        .graph-node-meta { color: #94a3b8; font-size: 0.8em; margin-top: 4px; }
+        .graph-node.pending { border-color: #475569; color: #94a3b8; }
+        .graph-node.running { border-color: #f59e0b; box-shadow: 0 0 12px rgba(245, 158, 11, 0.4); }
+        .graph-node.done { border-color: #10b981; }
+        .graph-node.failed { border-color: #ef4444; }
+        .approval {
+            background: #0f172a;
+            border-left: 4px solid #f59e0b;
+            border-radius: 8px;
+            padding: 15px;
+            margin-bottom: 15px;
+        }
+        .approval-title { color: #fbbf24; font-weight: bold; }
+        .approval-meta { color: #94a3b8; font-size: 0.85em; margin: 6px 0 10px; }
+        .approval textarea {
+            width: 100%;
+            min-height: 160px;
+            background: #1e293b;
+            color: #e2e8f0;
+            border: 1px solid #475569;
+            border-radius: 6px;
+            padding: 10px;
+            font-family: inherit;
+            box-sizing: border-box;
+            margin-bottom: 10px;
+        }
+        .thread {
+            background: #0f172a;
+            border-left: 4px solid #8b5cf6;
+            border-radius: 8px;
+            padding: 12px 15px;
+            margin-bottom: 12px;
+        }
+        .thread-title { color: #c4b5fd; font-weight: bold; }
+        .thread-meta { color: #94a3b8; font-size: 0.85em; margin: 4px 0 8px; }
+        .thread-message { margin: 6px 0; white-space: pre-wrap; font-size: 0.9em; }
+        .thread-from { color: #a78bfa; font-weight: bold; }
+        .schedule-table { width: 100%; border-collapse: collapse; font-size: 0.9em; }
+        .schedule-table th { text-align: left; color: #94a3b8; font-weight: normal; padding: 6px; border-bottom: 1px solid #334155; }
+        .schedule-table td { padding: 6px; border-bottom: 1px solid #1e293b; vertical-align: top; }
+        .schedule-table code { color: #a5b4fc; }
+        .schedule-form { display: flex; gap: 8px; margin-top: 12px; flex-wrap: wrap; }
+        .schedule-form input {
+            background: #1e293b;
+            color: #e2e8f0;
+            border: 1px solid #475569;
+            border-radius: 6px;
+            padding: 6px 8px;
+        }
+        .run-filter {
+            float: right;
+            background: #0f172a;
+            color: #e2e8f0;
+            border: 1px solid #475569;
+            border-radius: 6px;
+            padding: 4px 8px;
+            font-size: 0.6em;
+        }
+        .namespace-filter {
+            margin-top: 12px;
+            background: #0f172a;
+            color: #e2e8f0;
+            border: 1px solid #475569;
+            border-radius: 6px;
+            padding: 4px 8px;
+        }
+        .event.approval_requested { border-left-color: #f59e0b; }
+        .event.approval_resolved { border-left-color: #10b981; }
     </style>
 </head>
 <body>
@@ -244,6 +590,7 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
     <div class="header">
         <h1>🚀 Agent Swarm Dashboard</h1>
         <p>Real-time Multi-Agent System Monitoring</p>
+        <select class="namespace-filter" id="namespace-filter" title="Switch to another namespace's swarm" hidden></select>
     </div>
 
     <div class="stats">
@@ -271,45 +618,168 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
             <div class="agent-grid" id="agents"></div>
         </div>
         <div class="panel">
-            <h2>📊 Live Event Stream</h2>
+            <h2>📊 Live Event Stream
+                <select class="run-filter" id="run-filter" title="Show one workflow run or CLI session">
+                    <option value="">All runs</option>
+                </select>
+            </h2>
             <div class="event-log" id="event-log"></div>
         </div>
     </div>
 
+    <div class="panel" style="margin-top: 20px; display: none;" id="approvals-panel">
+        <h2>✋ Waiting for Approval</h2>
+        <div id="approvals"></div>
+    </div>
+
+    <div class="panel" style="margin-top: 20px; display: none;" id="threads-panel">
+        <h2>💬 Agent Threads</h2>
+        <div id="threads" style="max-height: 500px; overflow-y: auto;"></div>
+    </div>
+
+    <div class="panel" style="margin-top: 20px;" id="schedules-panel">
+        <h2>⏰ Schedules</h2>
+        <div id="schedules"><p style="color: #94a3b8;">No schedules yet.</p></div>
+        <div class="schedule-form">
+            <input id="schedule-cron" placeholder="0 9 * * 1-5" size="14">
+            <input id="schedule-agent" placeholder="agent type (e.g. research)" size="22">
+            <input id="schedule-description" placeholder="task description, or leave empty for a workflow" style="flex: 1; min-width: 200px;">
+            <input id="schedule-workflow" placeholder="workflows/research.yaml" size="24">
+            <button class="agent-control" onclick="addSchedule()">➕ Add</button>
+        </div>
+    </div>
+
+    <div class="panel" style="margin-top: 20px; display: none;" id="deadletters-panel">
+        <h2>📭 Dead Letters
+            <button class="agent-control" style="float: right;" onclick="redriveAll()">🔁 Re-drive all</button>
+        </h2>
+        <div id="deadletters"></div>
+    </div>
+
+    <div class="panel" style="margin-top: 20px;" id="agent-detail-panel">
+        <h2>🔎 Agent Detail</h2>
+        <div class="agent-tabs" id="agent-tabs"></div>
+        <div id="agent-detail"><p style="color: #94a3b8;">Select an agent to see its events and last result.</p></div>
+    </div>
+
+    <div class="panel" style="margin-top: 20px;" id="metrics-panel">
+        <h2>📈 History (last 60 minutes)</h2>
+        <div class="charts">
+            <div class="chart">
+                <div class="chart-title">Tasks / minute <span class="chart-value" id="chart-throughput-value"></span></div>
+                <svg id="chart-throughput" viewBox="0 0 300 120" preserveAspectRatio="none"></svg>
+            </div>
+            <div class="chart">
+                <div class="chart-title">Average latency <span class="chart-value" id="chart-latency-value"></span></div>
+                <svg id="chart-latency" viewBox="0 0 300 120" preserveAspectRatio="none"></svg>
+            </div>
+            <div class="chart">
+                <div class="chart-title">Average queue wait <span class="chart-value" id="chart-wait-value"></span></div>
+                <svg id="chart-wait" viewBox="0 0 300 120" preserveAspectRatio="none"></svg>
+            </div>
+            <div class="chart">
+                <div class="chart-title">Failures / minute <span class="chart-value" id="chart-failures-value"></span></div>
+                <svg id="chart-failures" viewBox="0 0 300 120" preserveAspectRatio="none"></svg>
+            </div>
+        </div>
+    </div>
+
+    <div class="panel" style="margin-top: 20px;" id="workflow-panel">
+        <h2>🔀 Workflow Graph</h2>
+        <div id="workflows" style="max-height: 600px; overflow-y: auto;"></div>
+    </div>
+
     <div class="panel" style="margin-top: 20px;" id="results-panel">
         <h2>📝 Task Results</h2>
         <div id="task-results" style="max-height: 600px; overflow-y: auto;"></div>
     </div>
 
     <script>
+        // A namespace's dashboard is served under /ns/{name}/, and so is its API
+        const base = (window.location.pathname.match(/^\/ns\/[^\/]+/) || [''])[0];
         let ws;
         let tasksCompleted = 0;
         let tasksProcessing = 0;
         let agents = {};
+        let workflows = {};      // workflow ID -> {name, steps: {taskID -> step}, order: [taskID]}
+        let taskToWorkflow = {}; // task ID -> workflow ID
+        let selectedAgent = null;
+        let detailRefresh = null;
+        let approvals = {};      // approval ID -> pending ApprovalRequest
+        let threads = {};        // thread ID -> Thread (agent-to-agent conversation)
+        let progress = {};       // agent ID -> {task ID -> latest TaskProgress}
+        let runs = {};           // run IDs seen so far, for the run filter
+        let runFilter = '';      // Run ID the event stream and results are limited to; '' shows all
+        let lastEventId = 0;     // ID of the last event received, to pick up where we left off after a reconnect
+        let eventTypes = {};     // Custom event type -> {label, icon, color}, from /api/event-types
+
+        let events;              // EventSource for /api/events, used where WebSockets are blocked
+        let wsFailures = 0;      // WebSocket connections in a row that never opened
 
         function connect() {
-            ws = new WebSocket('ws://' + window.location.host + '/ws');
+            if (wsFailures >= 2) {
+                connectSSE();
+                return;
+            }
+            const resume = lastEventId ? '?last_event_id=' + lastEventId : '';
+            const scheme = window.location.protocol === 'https:' ? 'wss://' : 'ws://';
+            ws = new WebSocket(scheme + window.location.host + base + '/ws' + resume);
+            let opened = false;
 
             ws.onopen = () => {
-                document.getElementById('connection-status').textContent = '● Connected';
-                document.getElementById('connection-status').className = 'connection-status connected';
-                loadInitialStatus();
+                opened = true;
+                wsFailures = 0;
+                onConnected();
             };
 
-            ws.onmessage = (event) => {
-                const data = JSON.parse(event.data);
-                handleEvent(data);
-            };
+            ws.onmessage = (event) => handleMessage(JSON.parse(event.data));
 
             ws.onclose = () => {
-                document.getElementById('connection-status').textContent = '● Disconnected';
-                document.getElementById('connection-status').className = 'connection-status disconnected';
+                if (!opened) wsFailures++;
+                onDisconnected();
                 setTimeout(connect, 3000);
             };
         }
 
+        // connectSSE follows the event stream over Server-Sent Events instead,
+        // for proxies that block WebSockets; commands then go over HTTP
+        function connectSSE() {
+            const resume = lastEventId ? '?last_event_id=' + lastEventId : '';
+            events = new EventSource(base + '/api/events' + resume);
+            events.onopen = onConnected;
+            events.onmessage = (event) => handleMessage(JSON.parse(event.data));
+            events.onerror = () => {
+                onDisconnected();
+                // The browser retries by itself unless the server refused the stream
+                if (events.readyState === EventSource.CLOSED) setTimeout(connectSSE, 3000);
+            };
+        }
+
+        function onConnected() {
+            document.getElementById('connection-status').textContent = '● Connected';
+            document.getElementById('connection-status').className = 'connection-status connected';
+            loadInitialStatus();
+            loadApprovals();
+            loadThreads();
+            loadDeadLetters();
+        }
+
+        function onDisconnected() {
+            document.getElementById('connection-status').textContent = '● Disconnected';
+            document.getElementById('connection-status').className = 'connection-status disconnected';
+        }
+
+        function handleMessage(data) {
+            if (data.type === 'command_result') {
+                if (!data.ok) alert(data.command + ' failed: ' + data.error);
+                return;
+            }
+            if (data.id) lastEventId = data.id;
+            handleEvent(data);
+        }
+
         function loadInitialStatus() {
-            fetch('/api/agents')
+            fetch(base + '/api/agents')
                 .then(r => r.json())
                 .then(data => {
                     agents = data;
@@ -317,8 +787,49 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
                 });
         }
 
+        let commandSeq = 0;
+
+        // sendCommand asks the server to pause/resume an agent or cancel a task
+        function sendCommand(command, args) {
+            const body = JSON.stringify(Object.assign({ id: String(++commandSeq), command: command }, args));
+            if (events) {
+                fetch(base + '/api/commands', { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: body })
+                    .then(r => r.json())
+                    .then(handleMessage);
+                return;
+            }
+            if (!ws || ws.readyState !== WebSocket.OPEN) return;
+            ws.send(body);
+        }
+
         function handleEvent(event) {
+            addRun(event.run_id);
             addEventToLog(event);
+            updateWorkflowGraph(event);
+            // History sent on connect fills the log, graph, and results;
+            // agent states, approvals, and threads are fetched instead
+            if (event.replayed) {
+                if (event.type === 'task_completed' && event.data) addTaskResult(event);
+                return;
+            }
+
+            if (event.type === 'approval_requested' && event.data) {
+                approvals[event.data.id] = event.data;
+                renderApprovals();
+            }
+            if (event.type === 'approval_resolved' && approvals[event.task_id]) {
+                delete approvals[event.task_id];
+                renderApprovals();
+            }
+            if (event.type === 'thread_message' && event.data) {
+                addThreadMessage(event);
+            }
+            if (event.type === 'task_dead_lettered') {
+                loadDeadLetters();
+            }
+            if (event.agent_id === selectedAgent) {
+                scheduleAgentDetail();
+            }
 
             if (event.type === 'task_completed') {
                 tasksCompleted++;
@@ -329,6 +840,16 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
                 }
             }
 
+            // Agents the reload added, removed, or replaced
+            if (event.type === 'config_changed') {
+                loadInitialStatus();
+            }
+
+            if ((event.type === 'agent_paused' || event.type === 'agent_resumed') && agents[event.agent_id]) {
+                agents[event.agent_id].state = event.type === 'agent_paused' ? 'paused' : 'processing';
+                updateAgentsDisplay();
+            }
+
             if (event.type === 'task_started') {
                 tasksProcessing++;
                 document.getElementById('tasks-processing').textContent = tasksProcessing;
@@ -338,16 +859,279 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
                 }
             }
 
+            if (event.type === 'task_progress' && event.data) {
+                progress[event.agent_id] = progress[event.agent_id] || {};
+                progress[event.agent_id][event.task_id] = event.data;
+                updateAgentsDisplay();
+            }
+
             if (event.type === 'task_completed' || event.type === 'task_failed') {
                 tasksProcessing = Math.max(0, tasksProcessing - 1);
                 document.getElementById('tasks-processing').textContent = tasksProcessing;
+                if (progress[event.agent_id] && progress[event.agent_id][event.task_id]) {
+                    delete progress[event.agent_id][event.task_id];
+                    updateAgentsDisplay();
+                }
+            }
+        }
+
+        // loadEventTypes fetches how custom event types are shown
+        function loadEventTypes() {
+            return fetch(base + '/api/event-types')
+                .then(r => r.json())
+                .then(list => {
+                    eventTypes = {};
+                    (list || []).forEach(info => { eventTypes[info.type] = info; });
+                });
+        }
+
+        function loadApprovals() {
+            fetch(base + '/api/approvals')
+                .then(r => r.json())
+                .then(list => {
+                    approvals = {};
+                    (list || []).forEach(req => { approvals[req.id] = req; });
+                    renderApprovals();
+                });
+        }
+
+        // renderApprovals shows each paused workflow step with its output in an
+        // editable box; approving sends the (possibly edited) text back
+        function renderApprovals() {
+            const ids = Object.keys(approvals);
+            const panel = document.getElementById('approvals-panel');
+            const container = document.getElementById('approvals');
+            panel.style.display = ids.length > 0 ? '' : 'none';
+
+            // Keep in-progress edits when another request arrives
+            const drafts = {};
+            container.querySelectorAll('textarea').forEach(t => { drafts[t.dataset.id] = t.value; });
+
+            container.innerHTML = '';
+            ids.forEach(id => {
+                const req = approvals[id];
+                const div = document.createElement('div');
+                div.className = 'approval';
+                div.innerHTML = '<div class="approval-title">' + escapeHtml(req.step) + '</div>' +
+                    '<div class="approval-meta">' + (req.instructions ? escapeHtml(req.instructions) + '<br>' : '') +
+                    'Will ' + escapeHtml(req.default_action) + ' automatically at ' +
+                    new Date(req.deadline).toLocaleTimeString() + '</div>';
+
+                const text = document.createElement('textarea');
+                text.dataset.id = id;
+                text.value = id in drafts ? drafts[id] : req.output;
+                div.appendChild(text);
+
+                const approve = document.createElement('button');
+                approve.className = 'agent-control';
+                approve.textContent = '✅ Approve';
+                approve.onclick = () => {
+                    const edited = text.value !== req.output ? text.value : '';
+                    sendCommand('approve', { approval_id: id, output: edited });
+                };
+                const reject = document.createElement('button');
+                reject.className = 'agent-control danger';
+                reject.textContent = '🚫 Reject';
+                reject.onclick = () => sendCommand('reject', { approval_id: id });
+
+                div.appendChild(approve);
+                div.appendChild(reject);
+                container.appendChild(div);
+            });
+        }
+
+        function loadThreads() {
+            fetch(base + '/api/threads')
+                .then(r => r.json())
+                .then(list => {
+                    threads = {};
+                    (list || []).forEach(t => { threads[t.id] = t; });
+                    renderThreads();
+                });
+        }
+
+        function addThreadMessage(event) {
+            const msg = event.data;
+            let thread = threads[msg.thread_id];
+            if (!thread) {
+                thread = threads[msg.thread_id] = {
+                    id: msg.thread_id, task_id: event.task_id, participants: [msg.from, msg.to],
+                    messages: [], started: msg.timestamp
+                };
             }
+            [msg.from, msg.to].forEach(id => {
+                if (!thread.participants.includes(id)) thread.participants.push(id);
+            });
+            thread.messages.push(msg);
+            renderThreads();
+        }
+
+        // renderThreads shows the newest conversations between agents, each
+        // message with its sender
+        function renderThreads() {
+            const list = Object.values(threads)
+                .sort((a, b) => new Date(b.started) - new Date(a.started))
+                .slice(0, 20);
+            document.getElementById('threads-panel').style.display = list.length > 0 ? '' : 'none';
+
+            document.getElementById('threads').innerHTML = list.map(t =>
+                '<div class="thread"><div class="thread-title">' + t.participants.map(escapeHtml).join(' ⇄ ') + '</div>' +
+                '<div class="thread-meta">' + escapeHtml(t.id) + (t.task_id ? ' · task ' + escapeHtml(t.task_id) : '') +
+                ' · ' + new Date(t.started).toLocaleTimeString() + '</div>' +
+                t.messages.map(m => '<div class="thread-message"><span class="thread-from">' + escapeHtml(m.from) +
+                    ' → ' + escapeHtml(m.to) + ':</span> ' + escapeHtml(m.text) + '</div>').join('') +
+                '</div>'
+            ).join('');
+        }
+
+        function loadSchedules() {
+            fetch(base + '/api/schedules')
+                .then(r => r.json())
+                .then(renderSchedules);
+        }
+
+        // relativeTime formats a timestamp as "in 5m" or "3h ago"
+        function relativeTime(ts) {
+            const t = new Date(ts);
+            if (!ts || t.getFullYear() < 2000) return '—';
+            let secs = Math.round((t - Date.now()) / 1000);
+            const future = secs >= 0;
+            secs = Math.abs(secs);
+            let text;
+            if (secs < 60) text = secs + 's';
+            else if (secs < 3600) text = Math.round(secs / 60) + 'm';
+            else if (secs < 86400) text = Math.round(secs / 3600) + 'h';
+            else text = Math.round(secs / 86400) + 'd';
+            return future ? 'in ' + text : text + ' ago';
+        }
+
+        function renderSchedules(list) {
+            const container = document.getElementById('schedules');
+            if (!list || list.length === 0) {
+                container.innerHTML = '<p style="color: #94a3b8;">No schedules yet.</p>';
+                return;
+            }
+
+            let html = '<table class="schedule-table"><tr><th>Cron</th><th>Runs</th><th>Next run</th><th>Last run</th><th>Status</th><th></th></tr>';
+            list.forEach(sched => {
+                const target = sched.workflow
+                    ? '🧩 ' + escapeHtml(sched.workflow)
+                    : (sched.agent_type ? '[' + escapeHtml(sched.agent_type) + '] ' : '') + escapeHtml(sched.description);
+                html += '<tr><td><code>' + escapeHtml(sched.cron) + '</code></td>' +
+                    '<td>' + target + '</td>' +
+                    '<td title="' + new Date(sched.next_run).toLocaleString() + '">' + relativeTime(sched.next_run) + '</td>' +
+                    '<td>' + relativeTime(sched.last_run) + '</td>' +
+                    '<td>' + escapeHtml(sched.last_status || '') + '</td>' +
+                    '<td><button class="agent-control danger" data-id="' + escapeHtml(sched.id) + '">🗑</button></td></tr>';
+            });
+            container.innerHTML = html + '</table>';
+            container.querySelectorAll('button[data-id]').forEach(btn => {
+                btn.onclick = () => removeSchedule(btn.dataset.id);
+            });
+        }
+
+        function addSchedule() {
+            const value = id => document.getElementById(id).value.trim();
+            const sched = {
+                cron: value('schedule-cron'),
+                agent_type: value('schedule-agent'),
+                description: value('schedule-description'),
+                workflow: value('schedule-workflow')
+            };
+            fetch(base + '/api/schedules', { method: 'POST', body: JSON.stringify(sched) })
+                .then(r => r.json().then(body => ({ ok: r.ok, body: body })))
+                .then(res => {
+                    if (!res.ok) {
+                        alert('Adding schedule failed: ' + res.body.error);
+                        return;
+                    }
+                    ['schedule-cron', 'schedule-agent', 'schedule-description', 'schedule-workflow']
+                        .forEach(id => { document.getElementById(id).value = ''; });
+                    loadSchedules();
+                });
+        }
+
+        function removeSchedule(id) {
+            if (!confirm('Remove schedule ' + id + '?')) return;
+            fetch(base + '/api/schedules/' + encodeURIComponent(id), { method: 'DELETE' }).then(loadSchedules);
+        }
+
+        function loadDeadLetters() {
+            fetch(base + '/api/deadletters')
+                .then(r => r.json())
+                .then(renderDeadLetters);
+        }
+
+        // renderDeadLetters lists the tasks no agent could take, each with
+        // buttons to queue it again or drop it; the panel hides when empty
+        function renderDeadLetters(list) {
+            list = list || [];
+            document.getElementById('deadletters-panel').style.display = list.length > 0 ? '' : 'none';
+
+            const container = document.getElementById('deadletters');
+            let html = '<table class="schedule-table"><tr><th>Task</th><th>Reason</th><th>Attempts</th><th>When</th><th></th></tr>';
+            list.forEach(dl => {
+                const target = (dl.agent_type ? '[' + escapeHtml(dl.agent_type) + '] ' : '') +
+                    (dl.requires ? '{' + dl.requires.map(escapeHtml).join(', ') + '} ' : '');
+                html += '<tr><td><code>' + escapeHtml(dl.task_id) + '</code><br>' + target + escapeHtml(dl.description || '') + '</td>' +
+                    '<td>' + escapeHtml(dl.reason) + '</td>' +
+                    '<td>' + dl.attempts + '</td>' +
+                    '<td title="' + new Date(dl.dead_lettered_at).toLocaleString() + '">' + relativeTime(dl.dead_lettered_at) + '</td>' +
+                    '<td><button class="agent-control" data-redrive="' + escapeHtml(dl.task_id) + '">🔁</button>' +
+                    '<button class="agent-control danger" data-discard="' + escapeHtml(dl.task_id) + '">🗑</button></td></tr>';
+            });
+            container.innerHTML = html + '</table>';
+            container.querySelectorAll('button[data-redrive]').forEach(btn => {
+                btn.onclick = () => deadLetterAction(base + '/api/deadletters/' + encodeURIComponent(btn.dataset.redrive) + '/redrive', 'POST');
+            });
+            container.querySelectorAll('button[data-discard]').forEach(btn => {
+                btn.onclick = () => {
+                    if (!confirm('Discard task ' + btn.dataset.discard + '?')) return;
+                    deadLetterAction(base + '/api/deadletters/' + encodeURIComponent(btn.dataset.discard), 'DELETE');
+                };
+            });
+        }
+
+        function redriveAll() {
+            deadLetterAction(base + '/api/deadletters/redrive', 'POST');
+        }
+
+        function deadLetterAction(url, method) {
+            fetch(url, { method: method })
+                .then(r => {
+                    if (!r.ok) return r.json().then(body => alert('Dead letter ' + method + ' failed: ' + body.error));
+                })
+                .then(loadDeadLetters);
+        }
+
+        // addRun offers a newly seen run ID in the run filter
+        function addRun(runID) {
+            if (!runID || runs[runID]) return;
+            runs[runID] = true;
+            const option = document.createElement('option');
+            option.value = runID;
+            option.textContent = runID;
+            document.getElementById('run-filter').appendChild(option);
+        }
+
+        // applyRunFilter shows only the events and results of the selected run
+        function applyRunFilter() {
+            runFilter = document.getElementById('run-filter').value;
+            document.querySelectorAll('#event-log .event, #task-results .task-result').forEach(el => {
+                el.style.display = inRunFilter(el.dataset.run) ? '' : 'none';
+            });
+        }
+
+        function inRunFilter(runID) {
+            return !runFilter || runID === runFilter;
         }
 
         function addTaskResult(event) {
             const resultsDiv = document.getElementById('task-results');
             const resultDiv = document.createElement('div');
             resultDiv.className = 'task-result';
+            resultDiv.dataset.run = event.run_id || '';
+            if (!inRunFilter(resultDiv.dataset.run)) resultDiv.style.display = 'none';
 
             const time = new Date(event.timestamp).toLocaleTimeString();
 
@@ -363,7 +1147,24 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
                 '<div class="task-result-time">' + time + '</div>' +
                 '</div>' +
                 '<div class="task-result-content">' + escapeHtml(resultContent) + '</div>' +
-                '<div class="task-result-agent">Task: ' + event.task_id + '</div>';
+                resultMeta(event.data && event.data.Metadata) +
+                '<div class="task-result-agent">Task: ' + event.task_id + '</div>' +
+                (event.run_id ? ' <div class="task-result-agent">Run: ' + escapeHtml(event.run_id) + '</div>' : '') +
+                downloadLink(event.task_id, 'json', '⬇ JSON') +
+                downloadLink(event.task_id, 'md', '⬇ Markdown');
+
+            // Big outputs arrive cut short; the whole result is fetched on request
+            if (event.truncated) {
+                const more = document.createElement('a');
+                more.className = 'task-result-download';
+                more.href = '#';
+                more.textContent = '⤢ Full result';
+                more.onclick = (e) => {
+                    e.preventDefault();
+                    loadFullResult(event.task_id, resultDiv.querySelector('.task-result-content'), more);
+                };
+                resultDiv.appendChild(more);
+            }
 
             resultsDiv.insertBefore(resultDiv, resultsDiv.firstChild);
 
@@ -373,6 +1174,48 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
             }
         }
 
+        // resultMeta describes how a result was produced: time taken, model, tokens, and attempts
+        function resultMeta(meta) {
+            if (!meta) return '';
+            const parts = [];
+            if (meta.submitted && meta.started) {
+                parts.push('⏳ waited ' + Math.max(0, (new Date(meta.started) - new Date(meta.submitted)) / 1000).toFixed(1) + 's');
+            }
+            if (meta.started && meta.finished) {
+                parts.push('⏱ ' + ((new Date(meta.finished) - new Date(meta.started)) / 1000).toFixed(1) + 's');
+            }
+            if (meta.provider) parts.push('🧠 ' + meta.provider + (meta.model ? ' ' + meta.model : ''));
+            if (meta.locale) parts.push('🌐 ' + meta.locale);
+            if (meta.input_tokens || meta.output_tokens) {
+                parts.push('🔤 ' + (meta.input_tokens || 0) + ' in / ' + (meta.output_tokens || 0) + ' out');
+            }
+            if (meta.cost_usd) parts.push('$' + meta.cost_usd.toFixed(4));
+            if (meta.attempts > 1) parts.push('🔁 ' + meta.attempts + ' attempts');
+            if (!parts.length) return '';
+            return '<div class="task-result-meta">' + escapeHtml(parts.join(' · ')) + '</div>';
+        }
+
+        // downloadLink saves a task's full result as a file (see /api/results/{id}/download)
+        // loadFullResult replaces an output cut short in its event with the
+        // whole result from the API
+        function loadFullResult(taskID, content, link) {
+            fetch(base + '/api/results/' + encodeURIComponent(taskID))
+                .then(r => r.json().then(body => ({ ok: r.ok, body: body })))
+                .then(({ ok, body }) => {
+                    if (!ok) {
+                        alert(body.error);
+                        return;
+                    }
+                    content.textContent = typeof body.data === 'string' ? body.data : JSON.stringify(body.data, null, 2);
+                    link.remove();
+                });
+        }
+
+        function downloadLink(taskID, format, label) {
+            const href = base + '/api/results/' + encodeURIComponent(taskID) + '/download?format=' + format;
+            return '<a class="task-result-download" href="' + href + '" download>' + label + '</a>';
+        }
+
         function escapeHtml(text) {
             const div = document.createElement('div');
             div.textContent = text;
@@ -383,14 +1226,42 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
             const log = document.getElementById('event-log');
             const eventDiv = document.createElement('div');
             eventDiv.className = 'event ' + event.type.replace('task_', '');
+            eventDiv.dataset.run = event.run_id || '';
+            if (!inRunFilter(eventDiv.dataset.run)) eventDiv.style.display = 'none';
+            const limitExceeded = event.type === 'task_failed' && event.data && event.data.Failure === 'limit_exceeded';
+            if (limitExceeded) {
+                eventDiv.className = 'event limit_exceeded';
+            }
 
             const time = new Date(event.timestamp).toLocaleTimeString();
-            const icon = {
+            let icon = {
                 'task_received': '📥',
                 'task_started': '⚙️',
+                'task_progress': '⏳',
                 'task_completed': '✅',
-                'task_failed': '❌'
+                'task_failed': '❌',
+                'task_redelivered': '🔁',
+                'task_dead_lettered': '📭',
+                'agent_paused': '⏸️',
+                'agent_resumed': '▶️',
+                'workflow_started': '🔀',
+                'workflow_completed': '🎉',
+                'workflow_failed': '❌',
+                'budget_exceeded': '💸',
+                'approval_requested': '✋',
+                'approval_resolved': '👍',
+                'config_changed': '🔧',
+                'config_rejected': '⚠️'
             }[event.type] || '📌';
+            if (limitExceeded) {
+                icon = '⛔';
+            }
+            const custom = eventTypes[event.type];
+            if (custom) {
+                icon = escapeHtml(custom.icon || icon);
+                if (custom.color) eventDiv.style.borderLeftColor = custom.color;
+                if (custom.label) eventDiv.title = custom.label;
+            }
 
             eventDiv.innerHTML = ` + "`" + `
                 <div class="event-time">${time}</div>
@@ -406,6 +1277,346 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
             }
         }
 
+        // Maps task events onto graph node states
+        const nodeStatus = {
+            'task_received': 'pending',
+            'task_redelivered': 'pending',
+            'task_started': 'running',
+            'task_completed': 'done',
+            'task_failed': 'failed',
+            'approval_requested': 'running',
+            'approval_resolved': 'done'
+        };
+
+        function updateWorkflowGraph(event) {
+            if (event.type === 'workflow_started' && event.data) {
+                const plan = event.data;
+                const wf = { name: plan.name, steps: {}, order: [] };
+                (plan.steps || []).forEach(step => {
+                    wf.steps[step.task_id] = {
+                        name: step.name,
+                        agentType: step.agent_type || '',
+                        deps: step.dependencies || [],
+                        status: 'pending',
+                        agent: ''
+                    };
+                    wf.order.push(step.task_id);
+                    taskToWorkflow[step.task_id] = plan.workflow_id;
+                });
+                workflows[plan.workflow_id] = wf;
+                renderWorkflows();
+                return;
+            }
+
+            if (event.type === 'workflow_completed' || event.type === 'workflow_failed') {
+                if (workflows[event.task_id]) {
+                    workflows[event.task_id].finished = event.type === 'workflow_completed' ? 'done' : 'failed';
+                    workflows[event.task_id].artifacts = (event.data && event.data.artifacts) || [];
+                    renderWorkflows();
+                }
+                return;
+            }
+
+            let status = nodeStatus[event.type];
+            if (!status || !event.task_id) return;
+            if (event.type === 'approval_resolved' && event.data && !event.data.approved) {
+                status = 'failed';
+            }
+
+            let wfID = taskToWorkflow[event.task_id];
+            if (!wfID) {
+                // Tasks with dependencies but no announced plan go into an ad-hoc graph
+                if (!event.dependencies || event.dependencies.length === 0) return;
+                wfID = 'ad-hoc';
+                if (!workflows[wfID]) {
+                    workflows[wfID] = { name: 'Ad-hoc tasks', steps: {}, order: [] };
+                }
+                const wf = workflows[wfID];
+                event.dependencies.forEach(dep => {
+                    if (!wf.steps[dep]) {
+                        wf.steps[dep] = { name: dep, agentType: '', deps: [], status: 'pending', agent: '' };
+                        wf.order.push(dep);
+                        taskToWorkflow[dep] = wfID;
+                    }
+                });
+                wf.steps[event.task_id] = { name: event.task_id, agentType: '', deps: event.dependencies, status: 'pending', agent: '' };
+                wf.order.push(event.task_id);
+                taskToWorkflow[event.task_id] = wfID;
+            }
+
+            const step = workflows[wfID].steps[event.task_id];
+            if (event.dependencies && event.dependencies.length > 0) {
+                step.deps = event.dependencies;
+            }
+            step.status = status;
+            if (event.agent_id && event.agent_id !== 'swarm') {
+                step.agent = event.agent_id;
+            }
+            renderWorkflows();
+        }
+
+        // depthOf returns the length of the longest dependency chain leading to a step
+        function depthOf(wf, taskID, seen) {
+            const step = wf.steps[taskID];
+            if (!step || seen[taskID]) return 0;
+            seen[taskID] = true;
+            let depth = 0;
+            step.deps.forEach(dep => {
+                if (wf.steps[dep]) depth = Math.max(depth, depthOf(wf, dep, seen) + 1);
+            });
+            delete seen[taskID];
+            return depth;
+        }
+
+        function renderWorkflows() {
+            const container = document.getElementById('workflows');
+            container.innerHTML = '';
+
+            // Newest workflow first, keep only the last 5
+            const ids = Object.keys(workflows).reverse().slice(0, 5);
+            Object.keys(workflows).forEach(id => {
+                if (!ids.includes(id)) {
+                    workflows[id].order.forEach(taskID => delete taskToWorkflow[taskID]);
+                    delete workflows[id];
+                }
+            });
+
+            ids.forEach(id => {
+                const wf = workflows[id];
+                const wfDiv = document.createElement('div');
+                wfDiv.className = 'workflow';
+
+                const suffix = wf.finished === 'done' ? ' — 🎉 completed' : (wf.finished === 'failed' ? ' — ❌ failed' : '');
+                const title = document.createElement('div');
+                title.className = 'workflow-title';
+                title.textContent = wf.name + suffix;
+                wfDiv.appendChild(title);
+
+                const graph = document.createElement('div');
+                graph.className = 'workflow-graph';
+
+                // Lay nodes out in columns by dependency depth
+                const columns = [];
+                wf.order.forEach(taskID => {
+                    const depth = depthOf(wf, taskID, {});
+                    (columns[depth] = columns[depth] || []).push(taskID);
+                });
+
+                const nodes = {};
+                columns.forEach(column => {
+                    const colDiv = document.createElement('div');
+                    colDiv.className = 'graph-column';
+                    (column || []).forEach(taskID => {
+                        const step = wf.steps[taskID];
+                        const node = document.createElement('div');
+                        node.className = 'graph-node ' + step.status;
+                        node.title = taskID;
+                        node.innerHTML = '<div class="graph-node-name">' + escapeHtml(step.name) + '</div>' +
+                            '<div class="graph-node-meta">' + escapeHtml(step.status + (step.agent ? ' · ' + step.agent : '')) + '</div>';
+                        colDiv.appendChild(node);
+                        nodes[taskID] = node;
+                    });
+                    graph.appendChild(colDiv);
+                });
+
+                wfDiv.appendChild(graph);
+                if (wf.artifacts && wf.artifacts.length > 0) {
+                    wfDiv.appendChild(artifactLinks(id, wf.artifacts));
+                }
+                container.appendChild(wfDiv);
+                drawEdges(graph, wf, nodes);
+            });
+        }
+
+        // artifactLinks lists the files a finished run produced, each a download
+        // from the run archive
+        function artifactLinks(workflowID, artifacts) {
+            const div = document.createElement('div');
+            div.className = 'workflow-artifacts';
+            div.textContent = '📎 ';
+            artifacts.forEach((artifact, i) => {
+                const link = document.createElement('a');
+                link.href = base + '/api/runs/' + encodeURIComponent(workflowID) + '/artifacts/' + encodeURIComponent(artifact.name);
+                link.download = artifact.name;
+                link.textContent = artifact.name;
+                link.title = artifact.content_type + ', ' + artifact.size + ' bytes, from ' + artifact.step;
+                if (i > 0) div.appendChild(document.createTextNode(' · '));
+                div.appendChild(link);
+            });
+            return div;
+        }
+
+        // drawEdges connects each node to its dependencies once the layout is known
+        function drawEdges(graph, wf, nodes) {
+            const svgNS = 'http://www.w3.org/2000/svg';
+            const svg = document.createElementNS(svgNS, 'svg');
+            svg.setAttribute('width', graph.scrollWidth);
+            svg.setAttribute('height', graph.scrollHeight);
+
+            const origin = graph.getBoundingClientRect();
+            Object.keys(nodes).forEach(taskID => {
+                wf.steps[taskID].deps.forEach(dep => {
+                    if (!nodes[dep]) return;
+                    const from = nodes[dep].getBoundingClientRect();
+                    const to = nodes[taskID].getBoundingClientRect();
+                    const line = document.createElementNS(svgNS, 'line');
+                    line.setAttribute('x1', from.right - origin.left + graph.scrollLeft);
+                    line.setAttribute('y1', from.top + from.height / 2 - origin.top);
+                    line.setAttribute('x2', to.left - origin.left + graph.scrollLeft);
+                    line.setAttribute('y2', to.top + to.height / 2 - origin.top);
+                    line.setAttribute('stroke', wf.steps[dep].status === 'done' ? '#10b981' : '#475569');
+                    line.setAttribute('stroke-width', '2');
+                    svg.appendChild(line);
+                });
+            });
+
+            graph.insertBefore(svg, graph.firstChild);
+        }
+
+        // loadMetricsHistory refreshes the charts and restores the totals that
+        // would otherwise reset on page reload
+        function loadMetricsHistory() {
+            fetch(base + '/api/metrics/history?minutes=60')
+                .then(r => r.json())
+                .then(history => {
+                    tasksCompleted = history.total_completed;
+                    document.getElementById('tasks-completed').textContent = tasksCompleted;
+
+                    const buckets = history.buckets || [];
+                    const throughput = buckets.map(b => b.completed + b.failed);
+                    const failures = buckets.map(b => b.failed);
+                    const latency = buckets.map(b => b.avg_latency_ms / 1000);
+                    const wait = buckets.map(b => b.avg_queue_wait_ms / 1000);
+
+                    drawBars('chart-throughput', throughput, '#60a5fa');
+                    drawLine('chart-latency', latency, '#f59e0b');
+                    drawLine('chart-wait', wait, '#a78bfa');
+                    drawBars('chart-failures', failures, '#ef4444');
+
+                    const last = buckets.length - 1;
+                    const recentLatency = latency.filter(v => v > 0);
+                    const recentWait = wait.filter(v => v > 0);
+                    document.getElementById('chart-throughput-value').textContent = last >= 0 ? throughput[last] + '/min' : '';
+                    document.getElementById('chart-latency-value').textContent = recentLatency.length ? recentLatency[recentLatency.length - 1].toFixed(1) + 's' : '–';
+                    document.getElementById('chart-wait-value').textContent = recentWait.length ? recentWait[recentWait.length - 1].toFixed(1) + 's' : '–';
+                    document.getElementById('chart-failures-value').textContent = history.total_failed + ' total';
+                });
+        }
+
+        function drawBars(id, values, color) {
+            const svg = document.getElementById(id);
+            const max = Math.max(1, ...values);
+            const width = 300 / Math.max(1, values.length);
+            let html = '';
+            values.forEach((v, i) => {
+                const h = (v / max) * 110;
+                html += '<rect x="' + (i * width) + '" y="' + (120 - h) + '" width="' + Math.max(1, width - 1) +
+                    '" height="' + h + '" fill="' + color + '"><title>' + v + '</title></rect>';
+            });
+            svg.innerHTML = html;
+        }
+
+        function drawLine(id, values, color) {
+            const svg = document.getElementById(id);
+            const max = Math.max(1, ...values);
+            const step = 300 / Math.max(1, values.length - 1);
+            const points = values.map((v, i) => (i * step) + ',' + (120 - (v / max) * 110)).join(' ');
+            svg.innerHTML = '<polyline points="' + points + '" fill="none" stroke="' + color + '" stroke-width="2"/>';
+        }
+
+        function renderAgentTabs() {
+            const tabs = document.getElementById('agent-tabs');
+            tabs.innerHTML = '';
+            Object.keys(agents).sort().forEach(id => {
+                const tab = document.createElement('button');
+                tab.className = 'agent-tab' + (id === selectedAgent ? ' active' : '');
+                tab.textContent = id;
+                tab.onclick = () => selectAgent(id);
+                tabs.appendChild(tab);
+            });
+        }
+
+        function selectAgent(id) {
+            selectedAgent = id;
+            renderAgentTabs();
+            loadAgentDetail();
+        }
+
+        // scheduleAgentDetail coalesces the bursts of events a task produces into one reload
+        function scheduleAgentDetail() {
+            if (detailRefresh) return;
+            detailRefresh = setTimeout(() => {
+                detailRefresh = null;
+                loadAgentDetail();
+            }, 500);
+        }
+
+        function loadAgentDetail() {
+            if (!selectedAgent) return;
+            fetch(base + '/api/agents/' + encodeURIComponent(selectedAgent) + '/detail')
+                .then(r => r.json())
+                .then(renderAgentDetail);
+        }
+
+        function renderAgentDetail(detail) {
+            const container = document.getElementById('agent-detail');
+            if (detail.error) {
+                container.innerHTML = '<p style="color: #ef4444;">' + escapeHtml(detail.error) + '</p>';
+                return;
+            }
+
+            const field = (label, value) => '<div class="agent-detail-field">' +
+                '<div class="agent-detail-label">' + label + '</div>' +
+                '<div class="agent-detail-value">' + escapeHtml(String(value)) + '</div></div>';
+
+            let html = '<div class="agent-detail-summary">' +
+                field('State', detail.state + (detail.specialty ? ' · ' + detail.specialty : '')) +
+                field('Current task', (detail.current_task || '—') +
+                    (detail.progress ? ' · ' + detail.progress.phase + ' (' + detail.progress.percent + '%)' : '')) +
+                field('History size', detail.history_size === undefined ? 'n/a' : detail.history_size + ' messages') +
+                (detail.capabilities ? field('Capabilities', detail.capabilities.join(', ')) : '') +
+                field('Events', detail.events.length) +
+                '</div>';
+
+            if (detail.history_summary) {
+                html += '<div class="task-result" style="border-left-color: #64748b;">' +
+                    '<div class="task-result-header"><div class="task-result-title">History summary</div></div>' +
+                    '<div class="task-result-content">' + escapeHtml(detail.history_summary) + '</div>' +
+                    '</div>';
+            }
+
+            if (detail.last_result) {
+                const r = detail.last_result;
+                html += '<div class="task-result" style="border-left-color: ' + (r.success ? '#10b981' : '#ef4444') + ';">' +
+                    '<div class="task-result-header">' +
+                    '<div class="task-result-title">Last result: ' + escapeHtml(r.task_id) + '</div>' +
+                    '<div class="task-result-time">' + new Date(r.completed_at).toLocaleTimeString() + '</div>' +
+                    '</div>' +
+                    '<div class="task-result-content">' + escapeHtml(typeof r.data === 'string' ? r.data : JSON.stringify(r.data, null, 2)) + '</div>' +
+                    '</div>';
+            }
+
+            if (detail.current_task) {
+                html += '<button class="agent-control danger" id="cancel-task" style="margin-bottom: 15px;">🛑 Cancel ' +
+                    escapeHtml(detail.current_task) + '</button>';
+            }
+
+            html += '<div class="event-log" style="height: 300px;">';
+            detail.events.forEach(event => {
+                html += '<div class="event ' + event.type.replace('task_', '') + '">' +
+                    '<div class="event-time">' + new Date(event.timestamp).toLocaleTimeString() + '</div>' +
+                    '<div class="event-message">' + escapeHtml(event.message) + '</div></div>';
+            });
+            html += '</div>';
+
+            container.innerHTML = html;
+
+            const cancel = document.getElementById('cancel-task');
+            if (cancel) {
+                cancel.onclick = () => sendCommand('cancel', { task_id: detail.current_task });
+            }
+        }
+
         function updateAgentsDisplay() {
             const container = document.getElementById('agents');
             container.innerHTML = '';
@@ -415,21 +1626,67 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
                 const card = document.createElement('div');
                 card.className = 'agent-card' + (agent.state === 'processing' ? ' busy' : '');
 
-                const statusClass = agent.state === 'idle' ? 'idle' : 'processing';
+                card.onclick = () => selectAgent(id);
+
+                const statusClass = agent.state === 'idle' ? 'idle' : (agent.state === 'paused' ? 'paused' : 'processing');
                 card.innerHTML = ` + "`" + `
                     <div class="agent-name">${id}</div>
+                    ${agent.specialty ? ` + "`" + `<div class="agent-specialty">${escapeHtml(agent.specialty)}</div>` + "`" + ` : ''}
                     <div class="agent-status ${statusClass}">${agent.state}</div>
+                    ${agent.inbox_depth ? ` + "`" + `<div class="agent-queue">${agent.inbox_depth} queued</div>` + "`" + ` : ''}
+                    ${Object.entries(progress[id] || {}).map(([taskID, p]) => ` + "`" + `
+                        <div class="agent-progress" title="${taskID}">${p.phase} (${p.percent}%)
+                            <div class="progress-track"><div class="progress-fill" style="width: ${p.percent}%"></div></div>
+                        </div>` + "`" + `).join('')}
                 ` + "`" + `;
 
+                const control = document.createElement('button');
+                control.className = 'agent-control admin-only';
+                control.textContent = agent.state === 'paused' ? '▶️ Resume' : '⏸️ Pause';
+                control.onclick = (e) => {
+                    e.stopPropagation();
+                    sendCommand(agent.state === 'paused' ? 'resume' : 'pause', { agent_id: id });
+                };
+                card.appendChild(control);
+
                 container.appendChild(card);
                 if (agent.state === 'processing') activeCount++;
             }
 
+            renderAgentTabs();
             document.getElementById('total-agents').textContent = Object.keys(agents).length;
             document.getElementById('active-agents').textContent = activeCount;
         }
 
-        connect();
+        // Hide the controls this client's role may not use
+        fetch(base + '/api/me')
+            .then(r => r.json())
+            .then(me => document.body.classList.add('role-' + me.role));
+
+        // Offer the other namespaces, when there are any
+        fetch('/api/namespaces')
+            .then(r => r.json())
+            .then(list => {
+                if (list.length < 2) return;
+                const select = document.getElementById('namespace-filter');
+                list.forEach(ns => {
+                    const option = document.createElement('option');
+                    option.value = ns.path;
+                    option.textContent = '🗂️ ' + ns.name;
+                    option.selected = ns.path.replace(/\/+$/, '') === base;
+                    select.appendChild(option);
+                });
+                select.hidden = false;
+                select.onchange = () => { window.location.href = select.value; };
+            });
+
+        document.getElementById('run-filter').addEventListener('change', applyRunFilter);
+        loadEventTypes().finally(connect); // Before the replayed events arrive
+        setInterval(loadInitialStatus, 5000); // Keeps queue depths current
+        loadMetricsHistory();
+        setInterval(loadMetricsHistory, 30000);
+        loadSchedules();
+        setInterval(loadSchedules, 30000);
     </script>
 </body>
 </html>`
@@ -440,32 +1697,37 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 
 // handleWebSocket handles WebSocket connections
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkOrigin}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+		slog.Warn("websocket upgrade failed", "remote", r.RemoteAddr, logging.KeyError, err)
 		return
 	}
 
-	s.clientsMu.Lock()
-	s.clients[conn] = true
-	s.clientsMu.Unlock()
+	client := &wsClient{conn: conn}
+	id := identityOf(r)
 
 	// Send initial status
-	status := s.swarm.GetSwarmStatus()
-	agents := make(map[string]interface{})
-	for id, state := range status {
-		agents[id] = map[string]interface{}{
-			"state": string(state),
-		}
+	client.writeJSON(s.initialStatus())
+
+	// A reconnecting client first gets the events it missed, and a new one
+	// recent history. The broadcaster is held off meanwhile, so none are sent
+	// twice or skipped.
+	s.clientsMu.Lock()
+	backlog := s.backlog(r)
+	for _, event := range backlog {
+		client.writeJSON(event)
 	}
-	conn.WriteJSON(map[string]interface{}{
-		"type": "initial_status",
-		"data": agents,
-	})
+	s.clients[conn] = client
+	s.clientsMu.Unlock()
 
-	// Keep connection alive
+	// Read control commands until the connection closes or stops answering pings
+	watchLiveness(conn)
+	done := make(chan struct{})
+	go keepAlive(conn, done)
 	go func() {
 		defer func() {
+			close(done)
 			s.clientsMu.Lock()
 			delete(s.clients, conn)
 			s.clientsMu.Unlock()
@@ -473,14 +1735,43 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}()
 
 		for {
-			_, _, err := conn.ReadMessage()
+			_, data, err := conn.ReadMessage()
 			if err != nil {
 				break
 			}
+			conn.SetReadDeadline(time.Now().Add(pongWait))
+			client.writeJSON(s.handleCommand(data, id))
 		}
 	}()
 }
 
+// initialStatus is the first message of an event stream: every agent's
+// state and specialty
+func (s *Server) initialStatus() map[string]interface{} {
+	status := s.swarm.GetSwarmStatus()
+	specialties := s.swarm.AgentSpecialties()
+	agents := make(map[string]interface{})
+	for id, state := range status {
+		agents[id] = map[string]interface{}{
+			"state":     string(state),
+			"specialty": specialties[id],
+		}
+	}
+	return map[string]interface{}{
+		"type": "initial_status",
+		"data": agents,
+	}
+}
+
+// backlog returns the events a client connecting to an event stream is sent
+// first: those it missed, when reconnecting, or recent history
+func (s *Server) backlog(r *http.Request) []StreamEvent {
+	if last := lastEventID(r); last > 0 {
+		return s.events.since(last)
+	}
+	return s.events.recent(replayCount(r))
+}
+
 // handleStatus returns current swarm status
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	status := s.swarm.GetSwarmStatus()
@@ -488,15 +1779,24 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
-// handleAgents returns all agents with their current status
+// handleAgents returns all agents with their current status, or adds one
+// (POST, see addAgent)
 func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.addAgent(w, r)
+		return
+	}
 	status := s.swarm.GetSwarmStatus()
+	depths := s.swarm.InboxDepths()
+	specialties := s.swarm.AgentSpecialties()
 	agents := make(map[string]interface{})
 
 	for id, state := range status {
 		agents[id] = map[string]interface{}{
-			"state": string(state),
-			"id":    id,
+			"state":       string(state),
+			"id":          id,
+			"specialty":   specialties[id],
+			"inbox_depth": depths[id],
 		}
 	}
 
@@ -504,22 +1804,52 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(agents)
 }
 
-// broadcastEvents sends events to all connected WebSocket clients
+// broadcastEvents records task history and sends events to all connected WebSocket clients
 func (s *Server) broadcastEvents() {
 	for event := range s.eventStream {
+		// Streamed output is for the terminal; the result has all of it
+		if event.Type == types.EventTaskOutput {
+			continue
+		}
+		s.metrics.record(event)
+		s.activity.record(event)
+		s.research.record(event)
+
+		var failed []*websocket.Conn
+		var behind []*sseClient
 		s.clientsMu.RLock()
-		for client := range s.clients {
-			err := client.WriteJSON(event)
+		numbered := s.events.add(streamEvent(event, int(s.textLimit.Load())))
+		for conn, client := range s.clients {
+			err := client.writeJSON(numbered)
 			if err != nil {
-				log.Printf("WebSocket write error: %v", err)
-				client.Close()
-				s.clientsMu.Lock()
-				delete(s.clients, client)
-				s.clientsMu.Unlock()
+				slog.Warn("websocket write failed", "remote", conn.RemoteAddr().String(), logging.KeyError, err)
+				failed = append(failed, conn)
+			}
+		}
+		for client := range s.sseClients {
+			select {
+			case client.events <- numbered:
+			default:
+				behind = append(behind, client)
 			}
 		}
 		s.clientsMu.RUnlock()
 
+		// Drop broken connections (can't take the write lock while holding the read lock)
+		for _, conn := range failed {
+			conn.Close()
+			s.clientsMu.Lock()
+			delete(s.clients, conn)
+			s.clientsMu.Unlock()
+		}
+		// and event streams that fell behind; they reconnect and catch up
+		for _, client := range behind {
+			slog.Warn("event stream fell behind, dropping it", "remote", client.remote)
+			s.clientsMu.Lock()
+			s.dropSSE(client)
+			s.clientsMu.Unlock()
+		}
+
 		// Small delay to avoid overwhelming clients
 		time.Sleep(10 * time.Millisecond)
 	}
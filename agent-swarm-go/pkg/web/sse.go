@@ -0,0 +1,154 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/redact"
+)
+
+const (
+	// sseBuffer is how many events an /api/events client may fall behind
+	// before it is dropped; it reconnects and catches up from the event log
+	sseBuffer = 256
+	// sseKeepAlive is how often an idle /api/events stream gets a comment
+	// line, so proxies don't take it for a dead connection
+	sseKeepAlive = 15 * time.Second
+	// sseRetry is how long a browser waits before reconnecting, in ms
+	sseRetry = 3000
+	// maxCommandBody bounds a POST /api/commands body
+	maxCommandBody = 64 << 10
+)
+
+// sseClient is one /api/events stream
+type sseClient struct {
+	events chan StreamEvent // Closed when the client is dropped
+	remote string
+}
+
+// dropSSE stops sending events to client and ends its stream; s.clientsMu
+// must be held for writing
+func (s *Server) dropSSE(client *sseClient) {
+	if _, ok := s.sseClients[client]; ok {
+		delete(s.sseClients, client)
+		close(client.events)
+	}
+}
+
+// handleEvents streams the swarm's events as Server-Sent Events, for clients
+// behind proxies that block WebSockets: GET /api/events. Each event's data is
+// the same JSON as a /ws message, and its SSE id is the event's ID, so a
+// browser's EventSource resumes where it left off on its own (Last-Event-ID);
+// ?last_event_id= and ?replay= work as on /ws. The first message is the
+// initial_status. Commands go to POST /api/commands instead.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET to stream events")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Keeps nginx from holding events back
+	w.WriteHeader(http.StatusOK)
+
+	send := func(id uint64, v interface{}) error {
+		rc.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := writeSSE(w, id, v); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+	if err := send(0, s.initialStatus()); err != nil {
+		return
+	}
+
+	// As on /ws, the backlog is sent with the broadcaster held off, so no
+	// event is sent twice or skipped
+	client := &sseClient{events: make(chan StreamEvent, sseBuffer), remote: r.RemoteAddr}
+	s.clientsMu.Lock()
+	for _, event := range s.backlog(r) {
+		if err := send(event.ID, event); err != nil {
+			s.clientsMu.Unlock()
+			return
+		}
+	}
+	s.sseClients[client] = struct{}{}
+	s.clientsMu.Unlock()
+
+	defer func() {
+		s.clientsMu.Lock()
+		s.dropSSE(client)
+		s.clientsMu.Unlock()
+	}()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-client.events:
+			if !ok {
+				return // Fell behind, or the server is shutting down
+			}
+			if err := send(event.ID, event); err != nil {
+				slog.Warn("event stream write failed", "remote", client.remote, logging.KeyError, err)
+				return
+			}
+		case <-keepAlive.C:
+			rc.SetWriteDeadline(time.Now().Add(writeWait))
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes v as one server-sent event with secrets scrubbed (see
+// pkg/redact); an id of 0 is left out
+func writeSSE(w io.Writer, id uint64, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if id > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", redact.String(string(data)))
+	return err
+}
+
+// handleCommands carries out one Command sent over HTTP, for clients that
+// follow /api/events rather than /ws: POST /api/commands. The reply is the
+// CommandResult /ws would send, with status 400 when it failed.
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST to send a command")
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxCommandBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := s.handleCommand(data, identityOf(r))
+	status := http.StatusOK
+	if !result.OK {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, result)
+}
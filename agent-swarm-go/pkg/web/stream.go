@@ -0,0 +1,249 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/workflows"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// eventLogSize is how many recent events are kept for clients resuming
+	// the /ws stream
+	eventLogSize = 1000
+	// defaultReplay is how many recent events a newly connected /ws client
+	// is sent, so a freshly opened dashboard shows recent activity
+	defaultReplay = 100
+	// pingInterval is how often the server pings each /ws client
+	pingInterval = 30 * time.Second
+	// pongWait is how long a client may go without answering a ping (or
+	// sending anything) before it is considered gone
+	pongWait = 2 * pingInterval
+	// writeWait bounds each write to a client, so one stalled connection
+	// can't hold up the broadcast to the others
+	writeWait = 10 * time.Second
+	// DefaultEventTextLimit is how many bytes of a message or result output
+	// an event sent on /ws carries, unless SetEventTextLimit says otherwise
+	DefaultEventTextLimit = 8192
+)
+
+// StreamEvent is an event as sent on /ws: the swarm event plus its position
+// in the stream. A client that reconnects with ?last_event_id=ID is sent the
+// events it missed after ID before the live stream continues. A new client is
+// first sent recent history, marked Replayed.
+//
+// A message or result output longer than the server's limit is cut, with a
+// marker saying how much is left out, and the event is marked Truncated. The
+// whole result is at GET /api/results/{task_id}.
+type StreamEvent struct {
+	ID        uint64 `json:"id"`
+	Replayed  bool   `json:"replayed,omitempty"`  // Sent on connect from history; it may no longer reflect the swarm's state
+	Truncated bool   `json:"truncated,omitempty"` // Message or result output cut to the limit
+	types.Event
+}
+
+// SetEventTextLimit caps the bytes of a message or result output sent in each
+// /ws event, for the namespaces too; 0 restores DefaultEventTextLimit and a
+// negative limit sends events whole. Big reports shipped verbatim to every
+// client make the dashboard sluggish; it fetches them in full when asked.
+func (s *Server) SetEventTextLimit(limit int) {
+	if limit == 0 {
+		limit = DefaultEventTextLimit
+	}
+	s.textLimit.Store(int64(limit))
+	for _, ns := range s.namespaces {
+		ns.SetEventTextLimit(limit)
+	}
+}
+
+// streamEvent prepares event for /ws, cutting its message and result output
+// to limit bytes each; limit < 0 leaves them whole. Artifacts are listed
+// without their content, which the dashboard downloads from the run archive.
+func streamEvent(event types.Event, limit int) StreamEvent {
+	switch data := event.Data.(type) {
+	case types.Result:
+		data.Artifacts = artifactListing(data.Artifacts)
+		event.Data = data
+	case *workflows.WorkflowResult:
+		if len(data.Artifacts) > 0 {
+			listed := *data // Shared with the event's other observers
+			listed.Artifacts = artifactListing(data.Artifacts)
+			event.Data = &listed
+		}
+	}
+	if limit < 0 {
+		return StreamEvent{Event: event}
+	}
+
+	var cut, cutData bool
+	event.Message, cut = truncateText(event.Message, limit, event.TaskID)
+	switch data := event.Data.(type) {
+	case string:
+		event.Data, cutData = truncateText(data, limit, event.TaskID)
+	case types.Result:
+		data.Data, cutData = truncateOutput(data.Data, limit, event.TaskID)
+		event.Data = data
+	}
+	return StreamEvent{Event: event, Truncated: cut || cutData}
+}
+
+// artifactListing returns artifacts without their content
+func artifactListing(artifacts []types.Artifact) []types.Artifact {
+	if len(artifacts) == 0 {
+		return artifacts
+	}
+	listed := make([]types.Artifact, len(artifacts))
+	for i, artifact := range artifacts {
+		listed[i] = artifact.Listing()
+	}
+	return listed
+}
+
+// truncateOutput cuts a result's output to limit bytes. Output that isn't
+// text is measured as JSON and, if too long, sent as cut JSON text.
+func truncateOutput(output interface{}, limit int, taskID string) (interface{}, bool) {
+	switch output := output.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return truncateText(output, limit, taskID)
+	}
+	encoded, err := json.Marshal(output)
+	if err != nil || len(encoded) <= limit {
+		return output, false
+	}
+	return truncateText(string(encoded), limit, taskID)
+}
+
+// truncateText cuts text to limit bytes, on a character boundary, and
+// appends a marker pointing at the full result
+func truncateText(text string, limit int, taskID string) (string, bool) {
+	if len(text) <= limit {
+		return text, false
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	marker := fmt.Sprintf("\n… [truncated: %d of %d bytes shown", cut, len(text))
+	if taskID != "" {
+		marker += "; full result at /api/results/" + taskID
+	}
+	return text[:cut] + marker + "]", true
+}
+
+// eventLog numbers the events sent on /ws and keeps the most recent ones
+type eventLog struct {
+	mu     sync.Mutex
+	events []StreamEvent // Oldest first, at most eventLogSize
+	nextID uint64
+}
+
+// add numbers event and keeps it
+func (l *eventLog) add(event StreamEvent) StreamEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	numbered := event
+	numbered.ID = l.nextID
+	l.events = append(l.events, numbered)
+	if len(l.events) > eventLogSize {
+		l.events = append(l.events[:0], l.events[len(l.events)-eventLogSize:]...)
+	}
+	return numbered
+}
+
+// since returns the kept events after the one with ID lastID, oldest first.
+// An ID the log hasn't issued (e.g. from before a server restart) gets
+// nothing, since the client's view can't be continued.
+func (l *eventLog) since(lastID uint64) []StreamEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lastID >= l.nextID {
+		return nil
+	}
+	for i, event := range l.events {
+		if event.ID > lastID {
+			return append([]StreamEvent{}, l.events[i:]...)
+		}
+	}
+	return nil
+}
+
+// recent returns up to n of the latest kept events, oldest first, marked
+// Replayed
+func (l *eventLog) recent(n int) []StreamEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n > len(l.events) {
+		n = len(l.events)
+	}
+	replay := append([]StreamEvent{}, l.events[len(l.events)-n:]...)
+	for i := range replay {
+		replay[i].Replayed = true
+	}
+	return replay
+}
+
+// replayCount reads how many recent events a new client wants from
+// ?replay=N, defaulting to defaultReplay; 0 turns the replay off
+func replayCount(r *http.Request) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("replay"))
+	if err != nil || n < 0 {
+		return defaultReplay
+	}
+	return n
+}
+
+// lastEventID reads the ?last_event_id= a reconnecting client sends, or the
+// Last-Event-ID header a browser's EventSource sends; 0 means a fresh
+// connection
+func lastEventID(r *http.Request) uint64 {
+	raw := r.URL.Query().Get("last_event_id")
+	if raw == "" {
+		raw = r.Header.Get("Last-Event-ID")
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// watchLiveness gives conn a read deadline of pongWait, pushed back on every
+// pong, so a client that stops answering pings fails its next read and is
+// dropped. Call it before the connection's reader starts.
+func watchLiveness(conn *websocket.Conn) {
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+}
+
+// keepAlive pings the client every pingInterval until done is closed
+func keepAlive(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package webhook delivers JSON payloads to external HTTP endpoints, retrying
+// transient failures, so the swarm can notify other systems when work finishes.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/redact"
+)
+
+// Delivery defaults: four attempts, waiting 1s, 2s, then 4s between them
+const (
+	DefaultAttempts = 4
+	DefaultDelay    = time.Second
+)
+
+// ErrInternalAddress is returned for a delivery to a loopback, link-local,
+// or private address whose host hasn't been allowed (see AllowHosts)
+var ErrInternalAddress = errors.New("refusing to deliver to an internal address")
+
+// httpClient bounds each attempt so a slow receiver can't hold a delivery
+// forever. It dials through dial, goes through no proxy (which would reach
+// addresses dial never sees), and follows no redirects, so a 3xx fails the
+// delivery.
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         dial,
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// allowed holds the hosts on internal networks deliveries may reach
+var allowed struct {
+	mu    sync.RWMutex
+	hosts map[string]bool
+}
+
+// AllowHosts lets deliveries reach the given hosts (names or IP addresses, as
+// they appear in URLs) even though they are on a loopback, link-local, or
+// private network. Every other such address is refused once its name is
+// resolved, so a task's callback URL can't be used to reach services inside
+// the network, such as a cloud metadata endpoint.
+func AllowHosts(hosts []string) {
+	allowed.mu.Lock()
+	defer allowed.mu.Unlock()
+	allowed.hosts = make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		allowed.hosts[strings.ToLower(h)] = true
+	}
+}
+
+// hostAllowed reports whether host was passed to AllowHosts
+func hostAllowed(host string) bool {
+	allowed.mu.RLock()
+	defer allowed.mu.RUnlock()
+	return allowed.hosts[strings.ToLower(host)]
+}
+
+// dialer checks every address it connects to, after name resolution, so a
+// name can't be pointed at an internal address
+var dialer = &net.Dialer{Timeout: 10 * time.Second, Control: refuseInternal}
+
+// dial connects to addr, refusing internal addresses unless its host is allowed
+func dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if host, _, err := net.SplitHostPort(addr); err == nil && hostAllowed(host) {
+		return (&net.Dialer{Timeout: dialer.Timeout}).DialContext(ctx, network, addr)
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// refuseInternal is the dialer's Control: it fails a connection about to be
+// made to an internal address
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || internal(ip) {
+		return fmt.Errorf("%w %s", ErrInternalAddress, host)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which isn't public either
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// internal reports whether ip is loopback, link-local, private, or otherwise
+// not a public unicast address
+func internal(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || sharedAddressSpace.Contains(ip)
+}
+
+// ValidateURL checks that target is an absolute http or https URL. Where it
+// leads is checked when it is delivered to (see AllowHosts).
+func ValidateURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http or https URL", target)
+	}
+	return nil
+}
+
+// Post sends payload as JSON to target. Network errors, 429 and 5xx
+// responses are retried with exponential backoff up to DefaultAttempts;
+// any other non-2xx response fails immediately. Secrets are scrubbed from
+// the body (see pkg/redact).
+func Post(ctx context.Context, target string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	body := []byte(redact.String(string(data)))
+
+	delay := DefaultDelay
+	var lastErr error
+	for attempt := 1; attempt <= DefaultAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		retry, err := postOnce(ctx, target, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || errors.Is(err, ErrInternalAddress) {
+			break
+		}
+		slog.Debug("webhook attempt failed", "host", host(target), "attempt", attempt, logging.KeyError, err)
+	}
+	return lastErr
+}
+
+// postOnce makes a single delivery attempt. The bool result reports whether
+// the failure is worth retrying.
+func postOnce(ctx context.Context, target string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "agent-swarm-go")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// *url.Error repeats the full URL; keep only the cause
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return ctx.Err() == nil, fmt.Errorf("POST %s: %w", host(target), err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("POST %s: %s", host(target), resp.Status)
+}
+
+// host returns the host part of target for logs and errors; chat webhook
+// URLs carry their secret in the path
+func host(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "webhook"
+}
+
+// Send delivers payload in the background and logs the outcome; what names
+// the delivery in the log (e.g. "task task-1")
+func Send(target string, payload interface{}, what string) {
+	go func() {
+		if err := Post(context.Background(), target, payload); err != nil {
+			slog.Error("webhook delivery failed", "host", host(target), "for", what, logging.KeyError, err)
+			return
+		}
+		slog.Info("webhook delivered", "host", host(target), "for", what)
+	}()
+}
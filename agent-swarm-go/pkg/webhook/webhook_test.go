@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestInternalAddresses(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "10.1.2.3", "192.168.0.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fd00::1", "fe80::1"} {
+		if !internal(net.ParseIP(addr)) {
+			t.Errorf("%s is not internal; want it refused", addr)
+		}
+	}
+	for _, addr := range []string{"8.8.8.8", "2001:4860:4860::8888"} {
+		if internal(net.ParseIP(addr)) {
+			t.Errorf("%s is internal; want it allowed", addr)
+		}
+	}
+}
+
+func TestPostRefusesInternalAddress(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	// localhost resolves to loopback, so the name doesn't get around the check
+	target := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	for _, url := range []string{srv.URL, target} {
+		if err := Post(context.Background(), url, map[string]string{"status": "done"}); !errors.Is(err, ErrInternalAddress) {
+			t.Errorf("Post(%s) = %v; want ErrInternalAddress", url, err)
+		}
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("server got %d requests; want none", n)
+	}
+}
+
+func TestAllowHostsReachesInternalAddress(t *testing.T) {
+	AllowHosts([]string{"127.0.0.1"})
+	t.Cleanup(func() { AllowHosts(nil) })
+
+	body := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body <- string(data)
+	}))
+	defer srv.Close()
+
+	const key = "sk-proj-abcdefghijklmnopqrstuvwx"
+	if err := Post(context.Background(), srv.URL, map[string]string{"note": "used " + key}); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-body; strings.Contains(got, key) {
+		t.Errorf("delivered body %s; want the key scrubbed", got)
+	}
+}
+
+func TestPostDoesNotFollowRedirects(t *testing.T) {
+	AllowHosts([]string{"127.0.0.1"})
+	t.Cleanup(func() { AllowHosts(nil) })
+
+	var followed atomic.Bool
+	inside := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed.Store(true)
+	}))
+	defer inside.Close()
+	srv := httptest.NewServer(http.RedirectHandler(inside.URL, http.StatusFound))
+	defer srv.Close()
+
+	if err := Post(context.Background(), srv.URL, map[string]string{}); err == nil {
+		t.Error("Post succeeded on a redirect; want it to fail")
+	}
+	if followed.Load() {
+		t.Error("redirect was followed")
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for url, ok := range map[string]bool{
+		"https://hooks.example.com/x": true,
+		"http://10.0.0.1/hook":        true, // Refused on delivery, not here
+		"ftp://example.com/x":         false,
+		"/relative":                   false,
+	} {
+		if err := ValidateURL(url); (err == nil) != ok {
+			t.Errorf("ValidateURL(%s) = %v; want ok %v", url, err, ok)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"path/filepath"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// collectArtifacts adds the files a finished step produced to the run: those
+// its agent returned in result.Artifacts and, with an artifact block, the
+// step's output. A name already taken by an earlier step is replaced.
+func (wr *WorkflowResult) collectArtifacts(step StepDefinition, result types.Result) {
+	for _, artifact := range result.Artifacts {
+		if err := artifact.Validate(); err != nil {
+			fmt.Printf("⚠️  %s: artifact skipped: %v\n", step.Name, err)
+			continue
+		}
+		if artifact.ContentType == "" {
+			artifact = types.NewArtifact(artifact.Name, "", artifact.Data)
+		}
+		wr.addArtifact(step.Name, artifact)
+	}
+
+	spec := step.Artifact
+	if spec == nil || result.Data == nil {
+		return
+	}
+	contentType := spec.ContentType
+	var data []byte
+	if text, ok := result.Data.(string); ok {
+		data = []byte(text)
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(result.Data, "", "  "); err != nil {
+			fmt.Printf("⚠️  %s: artifact %s not saved: %v\n", step.Name, spec.Name, err)
+			return
+		}
+		if contentType == "" && mime.TypeByExtension(filepath.Ext(spec.Name)) == "" {
+			contentType = "application/json"
+		}
+	}
+	wr.addArtifact(step.Name, types.NewArtifact(spec.Name, contentType, data))
+}
+
+// addArtifact keeps artifact as produced by step, replacing any other of the
+// same name
+func (wr *WorkflowResult) addArtifact(step string, artifact types.Artifact) {
+	artifact.Step = step
+	artifact.Size = len(artifact.Data)
+	for i, kept := range wr.Artifacts {
+		if kept.Name == artifact.Name {
+			wr.Artifacts[i] = artifact
+			return
+		}
+	}
+	wr.Artifacts = append(wr.Artifacts, artifact)
+}
+
+// displayArtifacts lists the files the run produced
+func (wr *WorkflowResult) displayArtifacts() {
+	if len(wr.Artifacts) == 0 {
+		return
+	}
+	fmt.Println("\n📎 Artifacts:")
+	for _, artifact := range wr.Artifacts {
+		fmt.Printf("   • %s (%s, %d bytes, from %s)\n", artifact.Name, artifact.ContentType, artifact.Size, artifact.Step)
+	}
+}
@@ -0,0 +1,134 @@
+package workflows
+
+import (
+	"fmt"
+	"sync"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// What a workflow does once its budget is spent
+const (
+	BudgetAbort     = "abort"     // Default: fail the workflow before starting another step
+	BudgetDowngrade = "downgrade" // Keep going, with every further task marked simple for the model router
+)
+
+// Budget caps what one run of a workflow may spend on LLM calls, counted from
+// the token usage agents report in Result.Metadata. Reviews, revisions, and
+// debate rounds count; mock and cached responses cost nothing.
+//
+//	budget:
+//	  max_cost_usd: 0.50
+//	  max_tokens: 40000
+//	  on_exceeded: downgrade   # or abort (default)
+//
+// Downgrading marks later tasks "complexity: simple", which sends them to the
+// cheap model when model routing is on (see llm.Router).
+type Budget struct {
+	MaxTokens  int     `yaml:"max_tokens" json:"max_tokens"`     // Input plus output tokens; 0 means no limit
+	MaxCostUSD float64 `yaml:"max_cost_usd" json:"max_cost_usd"` // Estimated at list price; 0 means no limit
+	OnExceeded string  `yaml:"on_exceeded" json:"on_exceeded"`   // BudgetAbort (default) or BudgetDowngrade
+}
+
+// validate reports problems with the budget
+func (b *Budget) validate(add func(format string, args ...interface{})) {
+	if b.MaxTokens < 0 {
+		add("budget.max_tokens: must not be negative")
+	}
+	if b.MaxCostUSD < 0 {
+		add("budget.max_cost_usd: must not be negative")
+	}
+	if b.MaxTokens == 0 && b.MaxCostUSD == 0 {
+		add("budget: set max_tokens, max_cost_usd, or both")
+	}
+	switch b.OnExceeded {
+	case "", BudgetAbort, BudgetDowngrade:
+	default:
+		add("budget.on_exceeded: unknown action %q (want abort or downgrade)", b.OnExceeded)
+	}
+}
+
+// action returns what to do once the budget is spent
+func (b *Budget) action() string {
+	if b.OnExceeded == "" {
+		return BudgetAbort
+	}
+	return b.OnExceeded
+}
+
+// spending adds up what a workflow run has spent. Steps run in parallel, so
+// it is safe for concurrent use.
+type spending struct {
+	mu         sync.Mutex
+	workflowID string
+	budget     *Budget // nil when the workflow has none
+	tokens     int
+	costUSD    float64
+	exceeded   bool
+}
+
+// add counts a finished task's usage. It reports whether this task took the
+// run over its budget, which happens once per run.
+func (s *spending) add(m types.ResultMetadata) (crossed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens += m.InputTokens + m.OutputTokens
+	s.costUSD += m.CostUSD
+	if s.budget == nil || s.exceeded {
+		return false
+	}
+	if (s.budget.MaxTokens > 0 && s.tokens > s.budget.MaxTokens) || (s.budget.MaxCostUSD > 0 && s.costUSD > s.budget.MaxCostUSD) {
+		s.exceeded = true
+		return true
+	}
+	return false
+}
+
+// status describes the spending for an EventBudgetExceeded event
+func (s *spending) status() types.BudgetStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return types.BudgetStatus{
+		WorkflowID: s.workflowID,
+		Tokens:     s.tokens,
+		CostUSD:    s.costUSD,
+		MaxTokens:  s.budget.MaxTokens,
+		MaxCostUSD: s.budget.MaxCostUSD,
+		Action:     s.budget.action(),
+	}
+}
+
+// total returns the tokens and estimated cost spent so far
+func (s *spending) total() (int, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens, s.costUSD
+}
+
+// over reports whether the run has gone over its budget and should do action
+func (s *spending) over(action string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exceeded && s.budget.action() == action
+}
+
+// downgrade marks task simple if the run has spent its budget and downgrades
+// rather than aborts
+func (s *spending) downgrade(task types.Task) types.Task {
+	p, ok := task.Payload.(map[string]interface{})
+	if !ok || !s.over(BudgetDowngrade) {
+		return task
+	}
+	payload := make(map[string]interface{}, len(p)+1)
+	for k, v := range p {
+		payload[k] = v
+	}
+	payload["complexity"] = "simple"
+	task.Payload = payload
+	return task
+}
+
+// exceededError is the error a workflow fails with when its budget runs out
+func exceededError(status types.BudgetStatus) error {
+	return fmt.Errorf("budget exceeded: spent %d tokens ($%.4f) of %s", status.Tokens, status.CostUSD, status.Limits())
+}
@@ -0,0 +1,225 @@
+package workflows
+
+import (
+	"time"
+
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/validate"
+)
+
+// Builder assembles a workflow Definition in Go code:
+//
+//	def, err := workflows.New("Launch Review").
+//		Input("product", "Acme Drone").
+//		Step("research", "research", "Research the market for {{.product}}.").
+//		Parallel(
+//			workflows.Branch("risks", "analysis", "List launch risks for {{.product}}."),
+//			workflows.Branch("pricing", "analysis", "Suggest pricing for {{.product}}."),
+//		).
+//		Then("report", "reporting", "Write a launch review for {{.product}}.").
+//		Build()
+//
+// Each Then or Parallel step depends on every step of the stage before it;
+// Step starts a new chain with no dependencies. The result is an ordinary
+// Definition, validated and run by an Engine like one loaded from a file.
+type Builder struct {
+	def   Definition
+	stage []string // Names of the steps the next Then or Parallel depends on
+}
+
+// StepOption adjusts a step added by a Builder
+type StepOption func(*StepDefinition)
+
+// New starts a workflow with the given name
+func New(name string) *Builder {
+	return &Builder{def: Definition{Name: name}}
+}
+
+// Describe sets the workflow's description
+func (b *Builder) Describe(description string) *Builder {
+	b.def.Description = description
+	return b
+}
+
+// Input declares a prompt input; an empty default makes it required
+func (b *Builder) Input(name, defaultValue string) *Builder {
+	b.def.Inputs = append(b.def.Inputs, InputSpec{Name: name, Default: defaultValue})
+	return b
+}
+
+// StepTimeout sets the timeout for steps that don't set their own
+func (b *Builder) StepTimeout(timeout time.Duration) *Builder {
+	b.def.StepTimeout = Duration(timeout)
+	return b
+}
+
+// Callback sets a URL that receives a WorkflowCallback POST when a run finishes
+func (b *Builder) Callback(url string) *Builder {
+	b.def.CallbackURL = url
+	return b
+}
+
+// Budget caps what each run spends on LLM calls (see Budget)
+func (b *Builder) Budget(budget Budget) *Builder {
+	b.def.Budget = &budget
+	return b
+}
+
+// Translate has the final report translated into the given languages
+func (b *Builder) Translate(languages ...string) *Builder {
+	b.def.Languages = append(b.def.Languages, languages...)
+	return b
+}
+
+// Locale has the agents write in locale's language, e.g. "ja" (see
+// types.Task.Locale)
+func (b *Builder) Locale(locale string) *Builder {
+	b.def.Locale = locale
+	return b
+}
+
+// Step adds a step with no dependencies and makes it the current stage
+func (b *Builder) Step(name, agent, prompt string, opts ...StepOption) *Builder {
+	return b.add(nil, Branch(name, agent, prompt, opts...))
+}
+
+// Then adds a step that runs after the current stage and becomes the new stage
+func (b *Builder) Then(name, agent, prompt string, opts ...StepOption) *Builder {
+	return b.add(b.stage, Branch(name, agent, prompt, opts...))
+}
+
+// Parallel adds steps that all run after the current stage, concurrently.
+// Together they become the new stage, so a following Then waits for all of them.
+func (b *Builder) Parallel(steps ...StepDefinition) *Builder {
+	return b.add(b.stage, steps...)
+}
+
+// add appends steps depending on deps (plus any After option) and makes them
+// the current stage
+func (b *Builder) add(deps []string, steps ...StepDefinition) *Builder {
+	stage := make([]string, 0, len(steps))
+	for _, step := range steps {
+		merged := append([]string(nil), deps...)
+		for _, dep := range step.DependsOn {
+			if !containsString(merged, dep) {
+				merged = append(merged, dep)
+			}
+		}
+		step.DependsOn = merged
+		b.def.Steps = append(b.def.Steps, step)
+		stage = append(stage, step.Name)
+	}
+	b.stage = stage
+	return b
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Approve adds an approval step that reviews the current stage's output
+// before the next step runs, and makes it the current stage. Use WithTimeout
+// and OnTimeout to bound the wait; the prompt, if any, is shown to the reviewer.
+func (b *Builder) Approve(name, instructions string, opts ...StepOption) *Builder {
+	step := Branch(name, "", instructions, opts...)
+	step.Type = StepApproval
+	return b.add(b.stage, step)
+}
+
+// Debate adds a debate step that runs after the current stage and makes it
+// the current stage. Each debater type answers the prompt and revises its
+// answer over rounds of critique (0 uses DefaultDebateRounds); the judge type
+// then writes the consensus answer.
+func (b *Builder) Debate(name, judge, prompt string, debaters []string, rounds int, opts ...StepOption) *Builder {
+	step := Branch(name, judge, prompt, opts...)
+	step.Type = StepDebate
+	step.Debate = &Debate{Debaters: debaters, Rounds: rounds}
+	return b.add(b.stage, step)
+}
+
+// Branch describes a step for Parallel
+func Branch(name, agent, prompt string, opts ...StepOption) StepDefinition {
+	step := StepDefinition{Name: name, Agent: agent, Prompt: prompt}
+	for _, opt := range opts {
+		opt(&step)
+	}
+	return step
+}
+
+// WithTimeout sets a step's timeout
+func WithTimeout(timeout time.Duration) StepOption {
+	return func(s *StepDefinition) { s.Timeout = Duration(timeout) }
+}
+
+// WithContextKey sets the key the step's output is passed to dependents under
+func WithContextKey(key string) StepOption {
+	return func(s *StepDefinition) { s.ContextKey = key }
+}
+
+// Optional lets the workflow continue when the step fails
+func Optional() StepOption {
+	return func(s *StepDefinition) { s.Optional = true }
+}
+
+// OnTimeout sets what an approval step does when nobody decides in time:
+// "approve" (the default) or "reject"
+func OnTimeout(action string) StepOption {
+	return func(s *StepDefinition) { s.OnTimeout = action }
+}
+
+// Reviewed has a critic agent check the step's output, redoing the step with
+// its feedback up to maxRevisions times; zeros take the defaults
+func Reviewed(minScore, maxRevisions int) StepOption {
+	return func(s *StepDefinition) { s.Review = &Review{MinScore: minScore, MaxRevisions: maxRevisions} }
+}
+
+// ExpectOutput makes the step's output match spec, asking the agent to fix
+// output that doesn't (see pkg/validate)
+func ExpectOutput(spec validate.OutputSpec) StepOption {
+	return func(s *StepDefinition) { s.Output = &spec }
+}
+
+// SaveArtifact saves the step's output as a file of the run; an empty
+// contentType is guessed from the name
+func SaveArtifact(name, contentType string) StepOption {
+	return func(s *StepDefinition) { s.Artifact = &ArtifactSpec{Name: name, ContentType: contentType} }
+}
+
+// InLocale has the step's agent write in locale's language, whatever the
+// workflow's locale
+func InLocale(locale string) StepOption {
+	return func(s *StepDefinition) { s.Locale = locale }
+}
+
+// After adds dependencies on earlier steps besides the current stage, e.g.
+// to give a report the research output as well as the analysis
+func After(steps ...string) StepOption {
+	return func(s *StepDefinition) { s.DependsOn = append(s.DependsOn, steps...) }
+}
+
+// Build validates the workflow and returns its Definition
+func (b *Builder) Build() (*Definition, error) {
+	def := b.def
+	def.Inputs = append([]InputSpec(nil), b.def.Inputs...)
+	def.Steps = append([]StepDefinition(nil), b.def.Steps...)
+	def.Languages = append([]string(nil), b.def.Languages...)
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Run builds the workflow and runs it on the swarm
+func (b *Builder) Run(s *swarm.Swarm, inputs map[string]string) (*WorkflowResult, error) {
+	def, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(s).Run(def, inputs)
+}
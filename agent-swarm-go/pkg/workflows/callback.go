@@ -0,0 +1,40 @@
+package workflows
+
+import "time"
+
+// WorkflowCallback is the JSON body POSTed to a definition's callback_url
+// when a run finishes
+type WorkflowCallback struct {
+	Event        string            `json:"event"` // "workflow_completed" or "workflow_failed"
+	WorkflowID   string            `json:"workflow_id"`
+	RunID        string            `json:"run_id,omitempty"`
+	Name         string            `json:"name"`
+	Success      bool              `json:"success"`
+	Error        string            `json:"error,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	CompletedAt  time.Time         `json:"completed_at"`
+	DurationSecs float64           `json:"duration_seconds"`
+	Steps        map[string]string `json:"steps"` // Output of each finished step
+	FinalReport  string            `json:"final_report,omitempty"`
+}
+
+// newWorkflowCallback describes a finished run; err is the run's error, if any
+func newWorkflowCallback(wr *WorkflowResult, err error) WorkflowCallback {
+	cb := WorkflowCallback{
+		Event:        "workflow_completed",
+		WorkflowID:   wr.WorkflowID,
+		RunID:        wr.RunID,
+		Name:         wr.Name,
+		Success:      err == nil,
+		StartedAt:    wr.StartTime,
+		CompletedAt:  wr.EndTime,
+		DurationSecs: wr.Duration.Seconds(),
+		Steps:        wr.StepResults,
+		FinalReport:  wr.FinalReport,
+	}
+	if err != nil {
+		cb.Event = "workflow_failed"
+		cb.Error = err.Error()
+	}
+	return cb
+}
@@ -0,0 +1,162 @@
+package workflows
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// DebateTranscript records what each debater said in every round of a debate step
+type DebateTranscript struct {
+	Debaters []string      `json:"debaters"` // Agent specialties, as in Debate.Debaters
+	Rounds   []DebateRound `json:"rounds"`   // The opening answers, then one entry per critique round
+}
+
+// DebateRound is one round of a debate step: the opening answers (round 0)
+// or the answers revised after a round of critique
+type DebateRound struct {
+	Round   int      `json:"round"`
+	Answers []string `json:"answers"` // One per debater; empty if the debater failed the round
+}
+
+// Markdown renders the transcript with a heading per round
+func (t DebateTranscript) Markdown() string {
+	var b strings.Builder
+	for _, round := range t.Rounds {
+		if round.Round == 0 {
+			b.WriteString("### Opening Answers\n\n")
+		} else {
+			fmt.Fprintf(&b, "### Round %d\n\n", round.Round)
+		}
+		b.WriteString(formatAnswers(t.Debaters, round.Answers, -1))
+		b.WriteString("\n\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// runDebateStep runs a debate step. Each debater answers the step's prompt on
+// its own, then revises its answer after reading the others' for every round,
+// and finally the step's agent judges the debate. A debater that fails a
+// round keeps its previous answer; the debate fails if fewer than two
+// debaters ever answer.
+func (e *Engine) runDebateStep(i int, step StepDefinition, task types.Task) stepOutcome {
+	debaters := step.Debate.Debaters
+	rounds := step.Debate.Rounds
+	if rounds <= 0 {
+		rounds = DefaultDebateRounds
+	}
+	question := task.Description
+
+	opening := e.debateRound(task, debaters, 0, func(int) (string, map[string]interface{}) {
+		return question + "\n\nAnswer independently: state your position and the reasoning behind it.", nil
+	})
+	transcript := &DebateTranscript{Debaters: debaters, Rounds: []DebateRound{{Round: 0, Answers: opening}}}
+	answered := 0
+	for _, answer := range opening {
+		if answer != "" {
+			answered++
+		}
+	}
+	if answered < 2 {
+		return stepOutcome{index: i, result: types.Result{TaskID: task.ID, Data: fmt.Sprintf("debate needs at least two answers, got %d", answered)}}
+	}
+	fmt.Printf("🗣️  %s: %d opening answers\n", step.Name, answered)
+
+	latest := opening
+	for round := 1; round <= rounds; round++ {
+		previous := latest
+		revised := e.debateRound(task, debaters, round, func(j int) (string, map[string]interface{}) {
+			return fmt.Sprintf("Debate round %d of %d on: %s\n\n"+
+					"Critique the other debaters' answers in the context: point out errors, gaps, and weak reasoning. "+
+					"Then give your revised answer, keeping what still holds in yours and conceding what doesn't.",
+					round, rounds, question),
+				map[string]interface{}{
+					"your_answer":   previous[j],
+					"other_answers": formatAnswers(debaters, previous, j),
+				}
+		})
+		latest = make([]string, len(debaters))
+		for j := range debaters {
+			latest[j] = revised[j]
+			if latest[j] == "" {
+				latest[j] = previous[j]
+			}
+		}
+		transcript.Rounds = append(transcript.Rounds, DebateRound{Round: round, Answers: revised})
+		fmt.Printf("🗣️  %s: round %d/%d done\n", step.Name, round, rounds)
+	}
+
+	judge := task
+	judge.Description = fmt.Sprintf("Judge the debate on: %s\n\n"+
+		"The context holds each debater's final answer after %d round(s) of critique. "+
+		"Weigh their arguments and write the consensus answer: what they agree on, how each disagreement "+
+		"is resolved and why, and any questions that remain open.", question, rounds)
+	judge.Context = make(map[string]interface{}, len(task.Context)+1)
+	for k, v := range task.Context {
+		judge.Context[k] = v
+	}
+	judge.Context["debate_answers"] = formatAnswers(debaters, latest, -1)
+
+	return stepOutcome{index: i, result: e.runTask(judge), debate: transcript}
+}
+
+// debateRound sends every debater that has a say in the round its task at
+// once and collects the answers, "" for each debater that failed. prompt
+// gives debater j's task description and extra context.
+func (e *Engine) debateRound(task types.Task, debaters []string, round int, prompt func(j int) (string, map[string]interface{})) []string {
+	answers := make([]string, len(debaters))
+	var wg sync.WaitGroup
+	for j, debater := range debaters {
+		description, extra := prompt(j)
+		if round > 0 && extra["your_answer"] == "" {
+			continue // Never answered, so there is nothing to revise
+		}
+
+		t := task
+		t.ID = fmt.Sprintf("%s-d%d-r%d", task.ID, j+1, round)
+		t.Description = description
+		payload := map[string]interface{}{"agent_type": debater}
+		if base, ok := task.Payload.(map[string]interface{}); ok {
+			for k, v := range base {
+				if k != "agent_type" {
+					payload[k] = v
+				}
+			}
+		}
+		t.Payload = payload
+		t.Context = make(map[string]interface{}, len(task.Context)+len(extra))
+		for k, v := range task.Context {
+			t.Context[k] = v
+		}
+		for k, v := range extra {
+			t.Context[k] = v
+		}
+
+		wg.Add(1)
+		go func(j int, t types.Task) {
+			defer wg.Done()
+			if result := e.runTask(t); result.Success {
+				answers[j] = fmt.Sprintf("%v", result.Data)
+			} else {
+				fmt.Printf("⚠️  Debater %d (%s) failed round %d: %v\n", j+1, debaters[j], round, result.Data)
+			}
+		}(j, t)
+	}
+	wg.Wait()
+	return answers
+}
+
+// formatAnswers labels each debater's answer, leaving out debater skip (-1
+// for none) and debaters without an answer
+func formatAnswers(debaters, answers []string, skip int) string {
+	var b strings.Builder
+	for j, answer := range answers {
+		if j == skip || answer == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "Debater %d (%s):\n%s\n\n", j+1, debaters[j], answer)
+	}
+	return strings.TrimSpace(b.String())
+}
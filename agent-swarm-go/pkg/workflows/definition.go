@@ -0,0 +1,521 @@
+package workflows
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/validate"
+	"agent-swarm-go/pkg/webhook"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDefinitionsDir is where the CLI looks for workflow definition files
+const DefaultDefinitionsDir = "workflows"
+
+// DefaultApprovalTimeout is how long an approval step waits for a human
+const DefaultApprovalTimeout = 10 * time.Minute
+
+// Step types
+const (
+	StepAgent    = "agent"    // Default: the prompt is sent to an agent
+	StepApproval = "approval" // Pauses until a human approves, edits, or rejects the output of the steps it depends on
+	StepDebate   = "debate"   // Several agents answer the prompt and critique each other; the step's agent judges
+)
+
+// Definition is a workflow declared in a YAML or JSON file and run by an
+// Engine, so a new pipeline doesn't need its own Go file:
+//
+//	name: Competitor Scan
+//	inputs:
+//	  - name: company
+//	    default: Acme Corp
+//	steps:
+//	  - name: research
+//	    agent: research
+//	    prompt: Research the main competitors of {{.company}}.
+//	    timeout: 90s
+//	  - name: report
+//	    agent: reporting
+//	    prompt: Write a competitive landscape report for {{.company}}.
+//	    depends_on: [research]
+//
+// Prompts are Go templates over the inputs. A step receives the output of
+// each step it depends on in its Task.Context, keyed by ContextKey.
+//
+// A step with type: approval runs no agent. It shows the output of the steps
+// it depends on to a human (CLI or dashboard), who approves it, edits it, or
+// rejects it; its prompt is the reviewer's instructions. Steps that depend on
+// the approval receive the approved text under the reviewed step's context
+// key. Without a decision within timeout (default DefaultApprovalTimeout),
+// on_timeout ("approve" or "reject", default approve) is applied.
+//
+// An agent step with a review block gets an automated QA pass: a critic agent
+// scores the step's output, and output it doesn't approve is sent back to the
+// step's agent with the critic's feedback, up to max_revisions times:
+//
+//	steps:
+//	  - name: report
+//	    agent: reporting
+//	    prompt: Write a competitive landscape report for {{.company}}.
+//	    review:
+//	      min_score: 8     # Accuracy and completeness out of 10; default 7
+//	      max_revisions: 2 # Default 1
+//
+// If the output is still not approved after the last revision, the latest
+// draft is kept and the workflow goes on.
+//
+// An agent step with an output block must produce output of that shape (see
+// pkg/validate); the agent is asked to fix output that doesn't match, and
+// the step fails if it still doesn't:
+//
+//	steps:
+//	  - name: risks
+//	    agent: analysis
+//	    prompt: List the top launch risks for {{.company}} as JSON.
+//	    output:
+//	      schema: {type: array, minItems: 3, items: {type: object, required: [risk, severity]}}
+//	      # or: sections: [Summary, Risks]; pattern: "(?i)recommendation"
+//
+// A step with type: debate puts its prompt to each agent type in debaters.
+// They answer independently, then see each other's answers and revise their
+// own for the given number of rounds. The step's agent then acts as judge and
+// writes the consensus answer, which is the step's output:
+//
+//	steps:
+//	  - name: verdict
+//	    type: debate
+//	    agent: reporting            # The judge
+//	    prompt: Should {{.company}} open a European office this year?
+//	    debate:
+//	      debaters: [research, analysis]
+//	      rounds: 2                 # Default 1
+//
+// A step with an artifact block saves its output as a file of the run, kept
+// with the run in the archive; agents may also return files of their own in
+// Result.Artifacts (see WorkflowResult.Artifacts):
+//
+//	steps:
+//	  - name: risks
+//	    agent: analysis
+//	    prompt: List the top launch risks for {{.company}} as JSON.
+//	    artifact:
+//	      name: risks.json
+//	      content_type: application/json # Default: guessed from the name
+//
+// With languages set, a translation agent translates the final report into
+// each language once the last step is done (see WorkflowResult.Translations).
+//
+// With locale set, e.g. "ja" or "pt-BR", every step's agent writes in that
+// language (see types.Task.Locale); a step's own locale overrides it, and
+// Engine.SetLocale overrides it for one run.
+//
+// A budget caps what one run spends on LLM calls; once it is exceeded the
+// workflow fails or carries on with cheaper models (see Budget).
+type Definition struct {
+	Name        string           `yaml:"name" json:"name"`
+	Description string           `yaml:"description" json:"description"`
+	Inputs      []InputSpec      `yaml:"inputs" json:"inputs"`
+	StepTimeout Duration         `yaml:"step_timeout" json:"step_timeout"` // Default for steps without a timeout; 0 uses DefaultStepTimeout
+	CallbackURL string           `yaml:"callback_url" json:"callback_url"` // Receives a WorkflowCallback POST when a run finishes
+	Languages   []string         `yaml:"languages" json:"languages"`       // Translate the final report into these, e.g. [Spanish, German]
+	Locale      string           `yaml:"locale" json:"locale"`             // Language tag the agents write in, e.g. "ja"; empty leaves it to the prompts
+	Budget      *Budget          `yaml:"budget" json:"budget"`             // Caps LLM spend per run; nil means no limit
+	Steps       []StepDefinition `yaml:"steps" json:"steps"`
+
+	prompts map[string]*template.Template // Parsed by Validate, keyed by step name
+}
+
+// InputSpec declares a value the workflow asks for before it runs
+type InputSpec struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Default     string `yaml:"default" json:"default"` // Empty means the input is required
+}
+
+// StepDefinition is one step of a Definition: an agent task or an approval
+type StepDefinition struct {
+	Name       string   `yaml:"name" json:"name"`
+	Type       string   `yaml:"type" json:"type"`               // StepAgent (default) or StepApproval
+	Agent      string   `yaml:"agent" json:"agent"`             // Agent specialty, e.g. "research"
+	Prompt     string   `yaml:"prompt" json:"prompt"`           // Task description template
+	DependsOn  []string `yaml:"depends_on" json:"depends_on"`   // Steps that must finish first; they must be declared earlier
+	ContextKey string   `yaml:"context_key" json:"context_key"` // Key for this step's output in dependents' Task.Context; defaults to Name
+	Timeout    Duration `yaml:"timeout" json:"timeout"`         // 0 uses the workflow's StepTimeout, or DefaultApprovalTimeout for approvals
+	Optional   bool     `yaml:"optional" json:"optional"`       // A failure is reported but doesn't fail the workflow
+	OnTimeout  string   `yaml:"on_timeout" json:"on_timeout"`   // Approval steps: "approve" (default) or "reject"
+	Review     *Review  `yaml:"review" json:"review"`           // Agent steps: have a critic check the output
+	Debate     *Debate  `yaml:"debate" json:"debate"`           // Debate steps: who debates and for how long
+	Locale     string   `yaml:"locale" json:"locale"`           // Overrides the workflow's locale for this step
+
+	Output   *validate.OutputSpec `yaml:"output" json:"output"`     // Agent steps: the shape the output must have
+	Artifact *ArtifactSpec        `yaml:"artifact" json:"artifact"` // Save the step's output as a file of the run
+}
+
+// ArtifactSpec saves a step's output as an artifact of the run (see
+// types.Artifact). Text is saved as is, other output as indented JSON.
+type ArtifactSpec struct {
+	Name        string `yaml:"name" json:"name"`                 // File name, e.g. "report.md"
+	ContentType string `yaml:"content_type" json:"content_type"` // Empty guesses from the name's extension
+}
+
+// Review configures the critic pass of an agent step
+type Review struct {
+	MinScore     int `yaml:"min_score" json:"min_score"`         // 0 uses agents.DefaultMinScore
+	MaxRevisions int `yaml:"max_revisions" json:"max_revisions"` // 0 uses DefaultMaxRevisions
+}
+
+// DefaultMaxRevisions is how many times a reviewed step is redone by default
+const DefaultMaxRevisions = 1
+
+// Debate configures a debate step
+type Debate struct {
+	Debaters []string `yaml:"debaters" json:"debaters"` // Agent specialties, one answer each; a type may appear more than once
+	Rounds   int      `yaml:"rounds" json:"rounds"`     // Critique rounds after the opening answers; 0 uses DefaultDebateRounds
+}
+
+// DefaultDebateRounds is how many critique rounds a debate has by default
+const DefaultDebateRounds = 1
+
+// Duration is a time.Duration written as a string like "90s" or "2m"
+type Duration time.Duration
+
+// UnmarshalText parses a duration string for both YAML and JSON
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText writes the duration in the same form UnmarshalText reads
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadDefinition reads and validates a workflow file. The format is chosen by
+// extension: .json is JSON, anything else is YAML. Unknown keys are rejected.
+func LoadDefinition(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDefinition(path, data)
+}
+
+// parseDefinition decodes and validates the contents of the workflow file at
+// path, which is only used for its extension and in errors
+func parseDefinition(path string, data []byte) (*Definition, error) {
+	var err error
+	def := &Definition{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(def)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(def)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, nil
+}
+
+// LoadDefinitions loads every .yaml, .yml and .json file in dir, sorted by
+// workflow name. A file that fails to load fails the whole call.
+func LoadDefinitions(dir string) ([]*Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []*Definition
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		def, err := LoadDefinition(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+// Validate reports every problem in the definition, joined into one error,
+// and prepares the prompt templates for Engine.Run
+func (d *Definition) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if strings.TrimSpace(d.Name) == "" {
+		add("name: required")
+	}
+	if d.StepTimeout < 0 {
+		add("step_timeout: must not be negative")
+	}
+	if d.CallbackURL != "" {
+		if err := webhook.ValidateURL(d.CallbackURL); err != nil {
+			add("callback_url: %v", err)
+		}
+	}
+
+	if d.Budget != nil {
+		d.Budget.validate(add)
+	}
+
+	languages := map[string]bool{}
+	for i, language := range d.Languages {
+		key := strings.ToLower(strings.TrimSpace(language))
+		if key == "" {
+			add("languages[%d]: must not be empty", i)
+		} else if languages[key] {
+			add("languages[%d]: duplicate language %q", i, language)
+		}
+		languages[key] = true
+	}
+
+	if err := types.ValidateLocale(d.Locale); err != nil {
+		add("locale: %v", err)
+	}
+
+	inputs := map[string]bool{}
+	for i, in := range d.Inputs {
+		if in.Name == "" {
+			add("inputs[%d].name: required", i)
+		} else if inputs[in.Name] {
+			add("inputs[%d].name: duplicate input %q", i, in.Name)
+		}
+		inputs[in.Name] = true
+	}
+
+	if len(d.Steps) == 0 {
+		add("steps: at least one step is required")
+	}
+
+	d.prompts = make(map[string]*template.Template)
+	declared := map[string]bool{}
+	artifacts := map[string]bool{}
+	for i, step := range d.Steps {
+		switch {
+		case step.Name == "":
+			add("steps[%d].name: required", i)
+		case declared[step.Name]:
+			add("steps[%d].name: duplicate step %q", i, step.Name)
+		}
+
+		approval := false
+		switch step.Type {
+		case "", StepAgent:
+			if step.Agent == "" {
+				add("steps[%d].agent: required", i)
+			}
+			if step.OnTimeout != "" {
+				add("steps[%d].on_timeout: only valid for approval steps", i)
+			}
+			if step.Debate != nil {
+				add("steps[%d].debate: only valid for debate steps", i)
+			}
+			if step.Output != nil {
+				if err := step.Output.Compile(); err != nil {
+					add("steps[%d].output: %v", i, err)
+				}
+			}
+			if r := step.Review; r != nil {
+				if r.MinScore < 0 || r.MinScore > 10 {
+					add("steps[%d].review.min_score: must be between 1 and 10", i)
+				}
+				if r.MaxRevisions < 0 {
+					add("steps[%d].review.max_revisions: must not be negative", i)
+				}
+			}
+		case StepApproval:
+			approval = true
+			if step.Agent != "" {
+				add("steps[%d].agent: approval steps don't run an agent", i)
+			}
+			if len(step.DependsOn) == 0 {
+				add("steps[%d].depends_on: an approval step needs a step to review", i)
+			}
+			if step.Review != nil {
+				add("steps[%d].review: only valid for agent steps", i)
+			}
+			if step.Output != nil {
+				add("steps[%d].output: only valid for agent steps", i)
+			}
+			if step.Debate != nil {
+				add("steps[%d].debate: only valid for debate steps", i)
+			}
+			switch step.OnTimeout {
+			case "", "approve", "reject":
+			default:
+				add("steps[%d].on_timeout: %q is not approve or reject", i, step.OnTimeout)
+			}
+		case StepDebate:
+			if step.Agent == "" {
+				add("steps[%d].agent: required (the judge)", i)
+			}
+			if step.OnTimeout != "" {
+				add("steps[%d].on_timeout: only valid for approval steps", i)
+			}
+			if step.Review != nil {
+				add("steps[%d].review: only valid for agent steps", i)
+			}
+			if step.Output != nil {
+				add("steps[%d].output: only valid for agent steps", i)
+			}
+			switch d := step.Debate; {
+			case d == nil || len(d.Debaters) < 2:
+				add("steps[%d].debate.debaters: a debate needs at least two debaters", i)
+			case d.Rounds < 0:
+				add("steps[%d].debate.rounds: must not be negative", i)
+			}
+			if d := step.Debate; d != nil {
+				for j, debater := range d.Debaters {
+					if strings.TrimSpace(debater) == "" {
+						add("steps[%d].debate.debaters[%d]: must not be empty", i, j)
+					}
+				}
+			}
+		default:
+			add("steps[%d].type: unknown step type %q (want %s, %s, or %s)", i, step.Type, StepAgent, StepApproval, StepDebate)
+		}
+		if step.Timeout < 0 {
+			add("steps[%d].timeout: must not be negative", i)
+		}
+		if err := types.ValidateLocale(step.Locale); err != nil {
+			add("steps[%d].locale: %v", i, err)
+		}
+		if a := step.Artifact; a != nil {
+			if err := (types.Artifact{Name: a.Name}).Validate(); err != nil {
+				add("steps[%d].artifact.name: %v", i, err)
+			} else if artifacts[a.Name] {
+				add("steps[%d].artifact.name: another step saves %q", i, a.Name)
+			}
+			artifacts[a.Name] = true
+		}
+
+		// Dependencies must point backwards, which also rules out cycles
+		for _, dep := range step.DependsOn {
+			if !declared[dep] {
+				add("steps[%d].depends_on: %q is not a step declared before %q", i, dep, step.Name)
+			}
+		}
+
+		if strings.TrimSpace(step.Prompt) == "" {
+			if !approval {
+				add("steps[%d].prompt: required", i)
+			}
+		} else if tmpl, err := template.New(step.Name).Option("missingkey=error").Parse(step.Prompt); err != nil {
+			add("steps[%d].prompt: %v", i, err)
+		} else {
+			d.prompts[step.Name] = tmpl
+		}
+
+		declared[step.Name] = true
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid workflow definition:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// resolveInputs fills in defaults and checks that every required input is set
+func (d *Definition) resolveInputs(given map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(d.Inputs))
+	var missing []string
+	for _, in := range d.Inputs {
+		value := given[in.Name]
+		if value == "" {
+			value = in.Default
+		}
+		if value == "" {
+			missing = append(missing, in.Name)
+		}
+		values[in.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("workflow %q: missing required input(s): %s", d.Name, strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// Tasks renders the definition's steps as tasks to distribute without an
+// Engine, e.g. as a scenario: one per step, with the step's prompt, agent
+// type, timeout, and dependencies. Nothing passes a step's output on to the
+// steps that depend on it, so approval and debate steps, which need that, are
+// rejected.
+func (d *Definition) Tasks(inputs map[string]string) ([]types.Task, error) {
+	values, err := d.resolveInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	stepTimeout := time.Duration(d.StepTimeout)
+	if stepTimeout <= 0 {
+		stepTimeout = DefaultStepTimeout
+	}
+
+	stamp := time.Now().UnixNano()
+	taskIDs := make(map[string]string, len(d.Steps))
+	for _, step := range d.Steps {
+		taskIDs[step.Name] = fmt.Sprintf("%s-%d", step.Name, stamp)
+	}
+
+	tasks := make([]types.Task, 0, len(d.Steps))
+	for i, step := range d.Steps {
+		if step.Type != "" && step.Type != StepAgent {
+			return nil, fmt.Errorf("workflow %q: step %s is a %s step, which only an Engine can run", d.Name, step.Name, step.Type)
+		}
+		task, err := buildTask(d, i, values, taskIDs, nil, stepTimeout)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// contextKey returns the key a step's output is stored under in its
+// dependents' Task.Context. An approval of a single step passes the approved
+// text on under that step's key, so it takes the original's place.
+func (d *Definition) contextKey(step StepDefinition) string {
+	if step.ContextKey != "" {
+		return step.ContextKey
+	}
+	if step.Type == StepApproval && len(step.DependsOn) == 1 {
+		return d.contextKey(d.step(step.DependsOn[0]))
+	}
+	return step.Name
+}
+
+// isApproval reports whether the step waits for a human instead of an agent
+func (s StepDefinition) isApproval() bool {
+	return s.Type == StepApproval
+}
@@ -0,0 +1,640 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/agents"
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+	"agent-swarm-go/pkg/validate"
+	"agent-swarm-go/pkg/webhook"
+)
+
+// Engine runs workflow Definitions on a swarm. Steps start as soon as the
+// steps they depend on have finished, so independent steps run in parallel.
+// An Engine runs one workflow at a time; create one per concurrent run.
+type Engine struct {
+	swarm     *swarm.Swarm
+	reviewer  Reviewer
+	runID     string      // Run every workflow belongs to; "" gives each its own (see SetRunID)
+	locale    string      // See SetLocale
+	activeRun string      // RunID of the workflow in progress
+	runLocale string      // Locale of the workflow in progress, for steps without their own
+	spending  *spending   // Of the run in progress, against its Budget
+	live      *liveOutput // See SetStreaming
+}
+
+// Reviewer asks a human to decide an approval step, e.g. on the terminal. It
+// competes with the dashboard: whichever decides first wins. The returned
+// decision's ID is filled in by the engine.
+type Reviewer func(req types.ApprovalRequest) types.ApprovalDecision
+
+// NewEngine creates an engine that distributes steps to the given swarm
+func NewEngine(s *swarm.Swarm) *Engine {
+	return &Engine{swarm: s}
+}
+
+// SetReviewer sets a reviewer asked for every approval step alongside the
+// dashboard. Without one, approvals are decided on the dashboard (or by
+// their timeout).
+func (e *Engine) SetReviewer(reviewer Reviewer) {
+	e.reviewer = reviewer
+}
+
+// SetStreaming shows each step on the terminal as it runs, when on: the
+// model's output as it is generated, or a spinner until some arrives
+func (e *Engine) SetStreaming(on bool) {
+	e.live = nil
+	if on {
+		e.live = newLiveOutput(os.Stdout)
+	}
+}
+
+// SetRunID makes the engine's workflows part of run id, e.g. the CLI session
+// that starts them, instead of each getting a RunID of its own
+func (e *Engine) SetRunID(id string) {
+	e.runID = id
+}
+
+// SetLocale has the agents of every workflow the engine runs write in
+// locale's language, e.g. "ja", instead of the definition's locale. Steps
+// with a locale of their own keep it.
+func (e *Engine) SetLocale(locale string) {
+	e.locale = locale
+}
+
+// stepOutcome is what a step's goroutine reports back to Run
+type stepOutcome struct {
+	index  int
+	result types.Result
+	review *types.Review     // Last critic verdict of a reviewed step
+	debate *DebateTranscript // Transcript of a debate step
+}
+
+// Run executes a validated definition with the given inputs (missing inputs
+// take their defaults). It returns when every step has finished, or as soon
+// as a required step fails.
+func (e *Engine) Run(def *Definition, inputs map[string]string) (*WorkflowResult, error) {
+	if def.prompts == nil {
+		if err := def.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	values, err := def.resolveInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.run(def, values, nil)
+}
+
+// run executes a definition and posts its callback. resume, when not nil, is
+// the recorded progress of an interrupted run to pick up (see Resume).
+func (e *Engine) run(def *Definition, values map[string]string, resume *swarm.WorkflowProgress) (*WorkflowResult, error) {
+	workflowResult, err := e.execute(def, values, resume)
+	if def.CallbackURL != "" {
+		webhook.Send(def.CallbackURL, newWorkflowCallback(workflowResult, err), "workflow "+workflowResult.WorkflowID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return workflowResult, nil
+}
+
+// execute runs the steps of a definition whose inputs are resolved, skipping
+// those resume records as finished. On failure it returns the partial result
+// alongside the error.
+func (e *Engine) execute(def *Definition, values map[string]string, resume *swarm.WorkflowProgress) (*WorkflowResult, error) {
+	if resume != nil {
+		fmt.Printf("\n🧩 Resuming Workflow: %s (%d of %d steps done)\n", def.Name, len(resume.Completed), len(def.Steps))
+	} else {
+		fmt.Printf("\n🧩 Starting Workflow: %s\n", def.Name)
+	}
+	fmt.Println("=" + strings.Repeat("=", 60) + "=")
+
+	workflowResult := &WorkflowResult{
+		WorkflowID:  fmt.Sprintf("workflow-%d", time.Now().UnixNano()),
+		Name:        def.Name,
+		Topic:       values["topic"],
+		StartTime:   time.Now(),
+		StepResults: make(map[string]string),
+	}
+	if workflowResult.Topic == "" {
+		workflowResult.Topic = def.Name
+	}
+	switch {
+	case resume != nil:
+		workflowResult.WorkflowID, workflowResult.StartTime, workflowResult.RunID = resume.ID, resume.Started, resume.RunID
+	case e.runID != "":
+		workflowResult.RunID = e.runID
+	default:
+		workflowResult.RunID = types.NewRunID()
+	}
+	e.activeRun = workflowResult.RunID
+	e.runLocale = e.locale
+	if e.runLocale == "" {
+		e.runLocale = def.Locale
+	}
+	e.spending = &spending{workflowID: workflowResult.WorkflowID, budget: def.Budget}
+
+	stepTimeout := time.Duration(def.StepTimeout)
+	if stepTimeout <= 0 {
+		stepTimeout = DefaultStepTimeout
+	}
+
+	// Task IDs are assigned up front so the whole graph can be announced
+	// before the first step runs. Nanoseconds keep them unique when several
+	// workflows share step names.
+	stamp := time.Now().UnixNano()
+	taskIDs := make(map[string]string, len(def.Steps))
+	plan := types.WorkflowPlan{WorkflowID: workflowResult.WorkflowID, Name: def.Name}
+	for _, step := range def.Steps {
+		taskIDs[step.Name] = fmt.Sprintf("%s-%d", step.Name, stamp)
+		workflowResult.Steps = append(workflowResult.Steps, step.Name)
+	}
+	for _, step := range def.Steps {
+		plan.Steps = append(plan.Steps, types.WorkflowStep{
+			TaskID:       taskIDs[step.Name],
+			Name:         step.Name,
+			AgentType:    planAgentType(step),
+			Dependencies: depTaskIDs(step, taskIDs),
+		})
+	}
+	e.publish(types.EventWorkflowStarted, workflowResult.WorkflowID, fmt.Sprintf("🧩 Workflow started: %s", def.Name), plan)
+
+	// outputs holds the Data of every finished step; a failed optional step
+	// is finished with no output
+	outputs := make(map[string]interface{}, len(def.Steps))
+	started := make([]bool, len(def.Steps))
+	outcomes := make(chan stepOutcome, len(def.Steps))
+	running := 0
+	if resume != nil {
+		for i, step := range def.Steps {
+			if out, ok := resume.Completed[step.Name]; ok {
+				outputs[step.Name], started[i] = out, true
+				if out != nil {
+					workflowResult.StepResults[step.Name] = fmt.Sprintf("%v", out)
+					workflowResult.collectArtifacts(step, types.Result{Data: out}) // Files the agent returned aren't resumed
+				}
+			}
+		}
+	}
+	recordProgress := progressRecorder(e.swarm, def, workflowResult, values)
+	recordProgress(outputs)
+
+	for len(outputs) < len(def.Steps) {
+		// Steps already running are left to finish, as when a step fails
+		if e.spending.over(BudgetAbort) {
+			return e.fail(workflowResult, exceededError(e.spending.status()))
+		}
+
+		for i, step := range def.Steps {
+			if started[i] || !depsDone(step, outputs) {
+				continue
+			}
+
+			task, err := buildTask(def, i, values, taskIDs, outputs, stepTimeout)
+			if err != nil {
+				return e.fail(workflowResult, err)
+			}
+
+			started[i] = true
+			running++
+
+			if step.isApproval() {
+				fmt.Printf("\n✋ Step %d/%d: %s (waiting for approval)\n", i+1, len(def.Steps), step.Name)
+				req := types.ApprovalRequest{
+					ID:            task.ID,
+					WorkflowID:    workflowResult.WorkflowID,
+					Step:          step.Name,
+					Instructions:  task.Description,
+					Output:        reviewText(def, step, outputs),
+					DefaultAction: "approve",
+					Deadline:      time.Now().Add(task.Timeout),
+					RunID:         e.activeRun,
+				}
+				if step.OnTimeout == "reject" {
+					req.DefaultAction = "reject"
+				}
+				go func(i int) {
+					outcomes <- stepOutcome{index: i, result: e.awaitApproval(req)}
+				}(i)
+				continue
+			}
+
+			if step.Type == StepDebate {
+				fmt.Printf("\n🗣️  Step %d/%d: %s (debate: %s; judge: %s)\n", i+1, len(def.Steps), step.Name,
+					strings.Join(step.Debate.Debaters, " vs "), step.Agent)
+				go func(i int, task types.Task) {
+					outcomes <- e.runDebateStep(i, def.Steps[i], task)
+				}(i, task)
+				continue
+			}
+
+			fmt.Printf("\n▶️  Step %d/%d: %s (%s)\n", i+1, len(def.Steps), step.Name, step.Agent)
+			go func(i int, task types.Task) {
+				outcomes <- e.runAgentStep(i, def.Steps[i], task)
+			}(i, task)
+		}
+
+		if running == 0 {
+			// Validate guarantees every dependency is declared earlier, so
+			// this can only happen if a definition was changed after validation
+			return e.fail(workflowResult, fmt.Errorf("workflow %q has steps that can never run", def.Name))
+		}
+
+		outcome := <-outcomes
+		running--
+		step := def.Steps[outcome.index]
+
+		if !outcome.result.Success {
+			if !step.Optional {
+				return e.fail(workflowResult, fmt.Errorf("step %s failed: %v", step.Name, outcome.result.Data))
+			}
+			fmt.Printf("⚠️  Optional step %s skipped: %v\n", step.Name, outcome.result.Data)
+			outputs[step.Name] = nil
+			recordProgress(outputs)
+			continue
+		}
+
+		outputs[step.Name] = outcome.result.Data
+		recordProgress(outputs)
+		workflowResult.StepResults[step.Name] = fmt.Sprintf("%v", outcome.result.Data)
+		workflowResult.collectArtifacts(step, outcome.result)
+		if outcome.review != nil {
+			if workflowResult.Reviews == nil {
+				workflowResult.Reviews = make(map[string]types.Review)
+			}
+			workflowResult.Reviews[step.Name] = *outcome.review
+		}
+		if outcome.debate != nil {
+			if workflowResult.Debates == nil {
+				workflowResult.Debates = make(map[string]DebateTranscript)
+			}
+			workflowResult.Debates[step.Name] = *outcome.debate
+		}
+		fmt.Printf("✅ %s completed\n", step.Name)
+	}
+
+	// The last declared step that produced output is the workflow's result
+	for i := len(def.Steps) - 1; i >= 0; i-- {
+		if out, ok := workflowResult.StepResults[def.Steps[i].Name]; ok {
+			workflowResult.FinalReport = out
+			break
+		}
+	}
+
+	translateReport(e.swarm, workflowResult, def.Languages, stepTimeout)
+
+	workflowResult.EndTime = time.Now()
+	workflowResult.Duration = workflowResult.EndTime.Sub(workflowResult.StartTime)
+	workflowResult.Tokens, workflowResult.CostUSD = e.spending.total()
+	e.swarm.FinishWorkflow(workflowResult.WorkflowID)
+	fmt.Printf("\n🎉 Workflow completed in %v\n", workflowResult.Duration)
+	e.publish(types.EventWorkflowDone, workflowResult.WorkflowID,
+		fmt.Sprintf("🎉 Workflow completed in %v", workflowResult.Duration.Round(time.Second)), workflowResult)
+
+	return workflowResult, nil
+}
+
+// runAgentStep runs an agent step to completion. A step with a review block
+// is checked by a critic after each attempt and redone with the critic's
+// feedback until approved or out of revisions.
+func (e *Engine) runAgentStep(i int, step StepDefinition, task types.Task) stepOutcome {
+	result := e.runTask(task)
+	if !result.Success || step.Review == nil {
+		return stepOutcome{index: i, result: result}
+	}
+	if !e.swarm.HasSpecialty("critic") {
+		fmt.Printf("⚠️  %s not reviewed: no critic agent in the swarm\n", step.Name)
+		return stepOutcome{index: i, result: result}
+	}
+
+	maxRevisions := step.Review.MaxRevisions
+	if maxRevisions <= 0 {
+		maxRevisions = DefaultMaxRevisions
+	}
+
+	attempt := task
+	for revision := 0; ; revision++ {
+		reviewTask := agents.ReviewTask(attempt, result.Data, step.Review.MinScore)
+		reviewTask.Context[agents.ReviewKeyTask] = task.Description // Revisions are judged by the step's prompt, not the revision request
+		verdict := e.runTask(reviewTask)
+		review, ok := verdict.Data.(types.Review)
+		if !verdict.Success || !ok {
+			// A broken review doesn't throw away good output
+			fmt.Printf("⚠️  Review of %s failed, keeping its output: %v\n", step.Name, verdict.Data)
+			return stepOutcome{index: i, result: result}
+		}
+		if review.Approved {
+			fmt.Printf("🔎 %s %s\n", step.Name, review)
+			return stepOutcome{index: i, result: result, review: &review}
+		}
+		if revision == maxRevisions {
+			fmt.Printf("⚠️  %s still not approved after %d revision(s), keeping the latest draft\n", step.Name, maxRevisions)
+			return stepOutcome{index: i, result: result, review: &review}
+		}
+
+		fmt.Printf("🔁 %s sent back for revision %d/%d: %s\n", step.Name, revision+1, maxRevisions, review)
+		attempt = revisionTask(task, revision+1, result.Data, review)
+		revised := e.runTask(attempt)
+		if !revised.Success {
+			fmt.Printf("⚠️  Revision of %s failed, keeping the previous draft: %v\n", step.Name, revised.Data)
+			return stepOutcome{index: i, result: result, review: &review}
+		}
+		result = revised
+	}
+}
+
+// revisionTask redoes task with the previous draft and the critic's feedback
+// in its context
+func revisionTask(task types.Task, n int, draft interface{}, review types.Review) types.Task {
+	revised := task
+	revised.ID = fmt.Sprintf("%s-rev%d", task.ID, n)
+	revised.Context = make(map[string]interface{}, len(task.Context)+2)
+	for k, v := range task.Context {
+		revised.Context[k] = v
+	}
+	revised.Context["previous_draft"] = draft
+	revised.Context["reviewer_feedback"] = review.Feedback
+	revised.Description = task.Description + "\n\nRevise the previous draft in the context to address the reviewer's feedback."
+	return revised
+}
+
+// runTask distributes a task and waits for its result, counting what it
+// cost against the run's budget
+func (e *Engine) runTask(task types.Task) types.Result {
+	task = e.spending.downgrade(e.stamp(task))
+
+	if err := e.swarm.DistributeTask(task); err != nil {
+		return types.Result{TaskID: task.ID, Data: fmt.Sprintf("failed to distribute task: %v", err)}
+	}
+	result := awaitResultTo(os.Stdout, e.live, e.swarm, task.ID, task.Timeout+stepWaitGrace)
+
+	if e.spending.add(result.Metadata) {
+		status := e.spending.status()
+		message := fmt.Sprintf("💸 Workflow budget exceeded: spent %d tokens ($%.4f) of %s", status.Tokens, status.CostUSD, status.Limits())
+		if status.Action == BudgetDowngrade {
+			message += "; later tasks are marked simple"
+		}
+		fmt.Println(message)
+		e.publish(types.EventBudgetExceeded, status.WorkflowID, message, status)
+	}
+	return result
+}
+
+// stamp marks a task as part of the workflow in progress: its RunID, its
+// locale unless the step has one, and the workflow ID a restored swarm
+// leaves the task to (see Resume). Its output streams if the engine shows it
+// (see SetStreaming).
+func (e *Engine) stamp(task types.Task) types.Task {
+	task.RunID = e.activeRun
+	task.Stream = e.live != nil
+	if task.Locale == "" {
+		task.Locale = e.runLocale
+	}
+	p, ok := task.Payload.(map[string]interface{})
+	if !ok {
+		return task
+	}
+	payload := make(map[string]interface{}, len(p)+1)
+	for k, v := range p {
+		payload[k] = v
+	}
+	payload[swarm.WorkflowPayloadKey] = e.spending.workflowID
+	task.Payload = payload
+	return task
+}
+
+// buildTask renders step i's prompt and gathers its dependencies' outputs
+func buildTask(def *Definition, i int, values, taskIDs map[string]string, outputs map[string]interface{}, stepTimeout time.Duration) (types.Task, error) {
+	step := def.Steps[i]
+
+	var prompt strings.Builder
+	if tmpl := def.prompts[step.Name]; tmpl != nil {
+		if err := tmpl.Execute(&prompt, values); err != nil {
+			return types.Task{}, fmt.Errorf("step %s: rendering prompt: %w", step.Name, err)
+		}
+	}
+
+	payload := map[string]interface{}{"type": step.Name, "agent_type": step.Agent, "workflow": def.Name}
+	for name, value := range values {
+		payload[name] = value
+	}
+	if step.Output != nil {
+		payload[validate.PayloadKey] = *step.Output
+	}
+
+	taskContext := make(map[string]interface{})
+	for _, dep := range step.DependsOn {
+		if out := outputs[dep]; out != nil {
+			taskContext[def.contextKey(def.step(dep))] = out
+		}
+	}
+
+	timeout := time.Duration(step.Timeout)
+	if timeout <= 0 && step.isApproval() {
+		timeout = DefaultApprovalTimeout
+	} else if timeout <= 0 {
+		timeout = stepTimeout
+	}
+
+	return types.Task{
+		ID:           taskIDs[step.Name],
+		Description:  prompt.String(),
+		Payload:      payload,
+		Priority:     i + 1,
+		Context:      taskContext,
+		Dependencies: depTaskIDs(step, taskIDs),
+		Timeout:      timeout,
+		Locale:       step.Locale,
+	}, nil
+}
+
+// awaitApproval waits for a human decision on req, from the reviewer or the
+// dashboard, and applies the default action at the deadline. An approval's
+// result is the (possibly edited) reviewed text; a rejection fails the step.
+func (e *Engine) awaitApproval(req types.ApprovalRequest) types.Result {
+	decisions := e.swarm.RequestApproval(req)
+
+	var reviewerDone chan struct{}
+	if e.reviewer != nil {
+		reviewerDone = make(chan struct{})
+		go func() {
+			defer close(reviewerDone)
+			if e.live != nil {
+				e.live.hold()
+				defer e.live.release()
+			}
+			decision := e.reviewer(req)
+			decision.ID = req.ID
+			decision.By = "cli"
+			e.swarm.ResolveApproval(decision) // Fails harmlessly if already decided
+		}()
+	}
+
+	var decision types.ApprovalDecision
+	select {
+	case decision = <-decisions:
+	case <-time.After(time.Until(req.Deadline)):
+		e.swarm.ResolveApproval(types.ApprovalDecision{
+			ID:       req.ID,
+			Approved: req.DefaultAction == "approve",
+			By:       "timeout",
+		})
+		decision = <-decisions
+	}
+
+	// Don't leave the reviewer reading the terminal once the step is decided
+	if reviewerDone != nil && decision.By != "cli" {
+		select {
+		case <-reviewerDone:
+		default:
+			fmt.Printf("\n✋ %s was decided by %s (approved: %v). Press Enter to continue.\n", req.Step, decision.By, decision.Approved)
+			<-reviewerDone
+		}
+	}
+
+	if !decision.Approved {
+		return types.Result{TaskID: req.ID, Success: false, Data: fmt.Sprintf("rejected by %s", decision.By)}
+	}
+	output := req.Output
+	if strings.TrimSpace(decision.Output) != "" {
+		output = decision.Output
+	}
+	return types.Result{TaskID: req.ID, Success: true, Data: output}
+}
+
+// reviewText is what an approval step shows the reviewer: the output of the
+// step it depends on, or of each of them under a heading
+func reviewText(def *Definition, step StepDefinition, outputs map[string]interface{}) string {
+	if len(step.DependsOn) == 1 {
+		if out := outputs[step.DependsOn[0]]; out != nil {
+			return fmt.Sprintf("%v", out)
+		}
+		return ""
+	}
+	var b strings.Builder
+	for _, dep := range step.DependsOn {
+		if out := outputs[dep]; out != nil {
+			fmt.Fprintf(&b, "## %s\n\n%v\n\n", dep, out)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// step returns the step with the given name
+func (d *Definition) step(name string) StepDefinition {
+	for _, step := range d.Steps {
+		if step.Name == name {
+			return step
+		}
+	}
+	return StepDefinition{}
+}
+
+// depsDone reports whether every dependency of step has finished
+func depsDone(step StepDefinition, outputs map[string]interface{}) bool {
+	for _, dep := range step.DependsOn {
+		if _, ok := outputs[dep]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// planAgentType is what a step shows as its agent in the workflow graph
+func planAgentType(step StepDefinition) string {
+	if step.isApproval() {
+		return "approval"
+	}
+	return step.Agent
+}
+
+// depTaskIDs maps a step's dependencies to their task IDs
+func depTaskIDs(step StepDefinition, taskIDs map[string]string) []string {
+	var ids []string
+	for _, dep := range step.DependsOn {
+		ids = append(ids, taskIDs[dep])
+	}
+	return ids
+}
+
+// fail announces a failed workflow and returns its partial result with the error
+func (e *Engine) fail(wr *WorkflowResult, err error) (*WorkflowResult, error) {
+	wr.EndTime = time.Now()
+	wr.Duration = wr.EndTime.Sub(wr.StartTime)
+	wr.Tokens, wr.CostUSD = e.spending.total()
+	e.swarm.FinishWorkflow(wr.WorkflowID)
+	e.publish(types.EventWorkflowFailed, wr.WorkflowID, fmt.Sprintf("❌ Workflow failed: %v", err), nil)
+	return wr, err
+}
+
+// publish sends a workflow-level event; the workflow ID goes in TaskID so
+// dashboards can correlate the started/completed/failed events
+func (e *Engine) publish(eventType types.EventType, workflowID, message string, data interface{}) {
+	e.swarm.GetEventBus().Publish(types.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		AgentID:   "workflow",
+		TaskID:    workflowID,
+		Message:   message,
+		Data:      data,
+		RunID:     e.activeRun,
+	})
+}
+
+// awaitResult waits for a distributed task to finish, printing its progress
+// meanwhile. A task with no result within timeout fails with "Task timeout".
+func awaitResult(s *swarm.Swarm, taskID string, timeout time.Duration) types.Result {
+	return awaitResultTo(os.Stdout, nil, s, taskID, timeout)
+}
+
+// awaitResultTo is awaitResult printing the progress to out, or, with live
+// set, showing the task on it as it runs
+func awaitResultTo(out io.Writer, live *liveOutput, s *swarm.Swarm, taskID string, timeout time.Duration) types.Result {
+	if live == nil {
+		progress := s.GetEventBus().Subscribe()
+		defer s.GetEventBus().Unsubscribe(progress)
+		go func() {
+			for event := range progress {
+				if event.TaskID == taskID && event.Type == types.EventTaskProgress {
+					printProgress(out, event)
+				}
+			}
+		}()
+	} else {
+		// Output comes in many small events, none of which may be missed
+		events := s.GetEventBus().SubscribeWithBuffer(1000)
+		defer s.GetEventBus().Unsubscribe(events)
+		live.begin(taskID)
+		defer live.end(taskID)
+		go func() {
+			for event := range events {
+				if event.TaskID == taskID && (event.Type == types.EventTaskProgress || event.Type == types.EventTaskOutput) {
+					live.event(taskID, event)
+				}
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	result, err := s.AwaitResult(ctx, taskID)
+	if err != nil {
+		return types.Result{TaskID: taskID, Success: false, Data: "Task timeout"}
+	}
+	return result
+}
+
+// printProgress shows a step's progress while it is waited on
+func printProgress(out io.Writer, event types.Event) {
+	if progress, ok := event.Data.(types.TaskProgress); ok {
+		fmt.Fprintf(out, "   ⏳ %s: %s (%d%%)\n", event.AgentID, progress.Phase, progress.Percent)
+	}
+}
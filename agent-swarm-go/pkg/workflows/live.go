@@ -0,0 +1,150 @@
+package workflows
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agent-swarm-go/pkg/types"
+)
+
+// spinnerFrames animate the spinner shown while no output is streaming
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is how often the spinner moves
+const spinnerInterval = 100 * time.Millisecond
+
+// liveOutput shows the steps a workflow waits on as they run: the model's
+// output as it is generated (see types.Task.Stream), or a spinner with each
+// step's latest progress while there is none, e.g. before the first token or
+// with a provider that doesn't stream (the mock, the cache, and fixtures).
+// Steps running side by side share the terminal: the first to produce output
+// streams it until it finishes, and the others stay behind the spinner.
+// Nothing is shown while the terminal is held for a reviewer (see hold).
+type liveOutput struct {
+	out io.Writer
+
+	mu        sync.Mutex
+	waiting   map[string]string // Status line of each step waited on, by task ID
+	streaming string            // Task whose output is being printed; "" while the spinner shows
+	spinning  bool              // A spinner line is on screen
+	frame     int
+	held      int           // Reviewers reading the terminal
+	stop      chan struct{} // Closed to stop the spinner; nil while it isn't running
+}
+
+// newLiveOutput returns a liveOutput printing to out
+func newLiveOutput(out io.Writer) *liveOutput {
+	return &liveOutput{out: out, waiting: make(map[string]string)}
+}
+
+// begin starts showing the task with taskID
+func (l *liveOutput) begin(taskID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waiting[taskID] = taskID
+	if l.stop == nil {
+		l.stop = make(chan struct{})
+		go l.spin(l.stop)
+	}
+}
+
+// end stops showing the task with taskID, ending its output's line
+func (l *liveOutput) end(taskID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.waiting, taskID)
+	if l.streaming == taskID {
+		fmt.Fprint(l.out, "\n\n")
+		l.streaming = ""
+	}
+	if len(l.waiting) == 0 && l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+		l.clearSpinner()
+	}
+}
+
+// event shows one of the events of the task with taskID: a chunk of its
+// output, or its progress on the spinner's line
+func (l *liveOutput) event(taskID string, event types.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.waiting[taskID]; !ok {
+		return // Arrived after the task finished
+	}
+
+	switch data := event.Data.(type) {
+	case types.TaskProgress:
+		l.waiting[taskID] = fmt.Sprintf("%s: %s (%d%%)", event.AgentID, data.Phase, data.Percent)
+	case types.TaskOutput:
+		if l.held > 0 {
+			return
+		}
+		if l.streaming == "" {
+			l.clearSpinner()
+			l.streaming = taskID
+			fmt.Fprintf(l.out, "   ✍️  %s:\n", event.AgentID)
+		}
+		if l.streaming == taskID {
+			fmt.Fprint(l.out, data.Text)
+		}
+	}
+}
+
+// hold keeps the terminal still while a reviewer reads it and types, until
+// release is called. A step streaming meanwhile is cut off.
+func (l *liveOutput) hold() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held++
+	l.clearSpinner()
+	if l.streaming != "" {
+		fmt.Fprint(l.out, "\n\n")
+		l.streaming = ""
+	}
+}
+
+// release undoes hold
+func (l *liveOutput) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held--
+}
+
+// spin redraws the spinner until stop is closed
+func (l *liveOutput) spin(stop chan struct{}) {
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		l.mu.Lock()
+		if l.streaming == "" && l.held == 0 && len(l.waiting) > 0 {
+			statuses := make([]string, 0, len(l.waiting))
+			for _, status := range l.waiting {
+				statuses = append(statuses, status)
+			}
+			sort.Strings(statuses)
+			l.frame = (l.frame + 1) % len(spinnerFrames)
+			fmt.Fprintf(l.out, "\r\033[K   %s %s", spinnerFrames[l.frame], strings.Join(statuses, " · "))
+			l.spinning = true
+		}
+		l.mu.Unlock()
+	}
+}
+
+// clearSpinner erases the spinner's line; l.mu must be held
+func (l *liveOutput) clearSpinner() {
+	if l.spinning {
+		fmt.Fprint(l.out, "\r\033[K")
+		l.spinning = false
+	}
+}
@@ -0,0 +1,86 @@
+package workflows
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+)
+
+// PlanGoal asks the swarm's planner agent to break a goal into tasks for the
+// other agents and returns the plan as a validated Definition, ready for
+// Engine.Run. The planner may only assign work to specialties the swarm has.
+func PlanGoal(s *swarm.Swarm, goal string) (*Definition, error) {
+	if strings.TrimSpace(goal) == "" {
+		return nil, fmt.Errorf("goal is empty")
+	}
+	if !s.HasSpecialty("planner") {
+		return nil, fmt.Errorf("no planner agent in the swarm")
+	}
+
+	var agentTypes []string
+	for _, specialty := range s.Specialties() {
+		if specialty != "planner" {
+			agentTypes = append(agentTypes, specialty)
+		}
+	}
+	if len(agentTypes) == 0 {
+		return nil, fmt.Errorf("no agents to carry out a plan")
+	}
+
+	task := types.Task{
+		ID:          fmt.Sprintf("plan-%d", time.Now().UnixNano()),
+		Description: goal,
+		Payload:     map[string]interface{}{"type": "planning", "agent_type": "planner", "agent_types": agentTypes},
+		Timeout:     DefaultStepTimeout,
+	}
+
+	if err := s.DistributeTask(task); err != nil {
+		return nil, err
+	}
+	result := awaitResult(s, task.ID, task.Timeout+stepWaitGrace)
+	if !result.Success {
+		return nil, fmt.Errorf("%v", result.Data)
+	}
+	plan, ok := result.Data.(types.Plan)
+	if !ok {
+		return nil, fmt.Errorf("planner returned %T, not a plan", result.Data)
+	}
+
+	def := PlanDefinition(plan)
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	return def, nil
+}
+
+// PlanDefinition converts a planner's plan into a workflow definition. The
+// prompts are written by the LLM, not a person, so any template braces in
+// them are kept as literal text. The goal becomes the run's topic.
+func PlanDefinition(plan types.Plan) *Definition {
+	def := &Definition{
+		Name:        "Plan: " + plan.Goal,
+		Description: "Generated by the planner agent",
+		Inputs:      []InputSpec{{Name: "topic", Description: "Goal", Default: plan.Goal}},
+	}
+	for _, step := range plan.Steps {
+		def.Steps = append(def.Steps, StepDefinition{
+			Name:      step.Name,
+			Agent:     step.Agent,
+			Prompt:    strings.ReplaceAll(step.Prompt, "{{", `{{"{{"}}`),
+			DependsOn: step.DependsOn,
+		})
+	}
+	return def
+}
+
+// RunGoal plans a goal and runs the plan on the swarm
+func RunGoal(s *swarm.Swarm, goal string) (*WorkflowResult, error) {
+	def, err := PlanGoal(s, goal)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(s).Run(def, nil)
+}
@@ -2,164 +2,329 @@ package workflows
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"agent-swarm-go/pkg/swarm"
 	"agent-swarm-go/pkg/types"
 )
 
+// DefaultStepTimeout is how long each workflow step may run
+const DefaultStepTimeout = 60 * time.Second
+
+// stepWaitGrace is how much longer than a step's timeout the workflow waits,
+// so the agent's own timeout failure arrives before the workflow gives up
+const stepWaitGrace = 5 * time.Second
+
+// Documents the research workflow files in its run's memory (see
+// swarm.Memory), which later steps reference instead of carrying copies
+const (
+	MemoryResearchNotes    = "research_notes"
+	MemoryAnalysisInsights = "analysis_insights"
+)
+
 // ResearchWorkflow handles sequential research → analysis → report workflow
 type ResearchWorkflow struct {
-	swarm   *swarm.Swarm
-	results map[string]types.Result
+	swarm       *swarm.Swarm
+	results     map[string]types.Result
+	stepTimeout time.Duration
+	languages   []string    // Translate the report into these; see SetLanguages
+	locale      string      // See SetLocale
+	runID       string      // See SetRunID
+	workflowID  string      // See SetWorkflowID
+	out         io.Writer   // Progress messages; see SetOutput
+	live        *liveOutput // See SetStreaming
 }
 
 // NewResearchWorkflow creates a new research workflow handler
 func NewResearchWorkflow(s *swarm.Swarm) *ResearchWorkflow {
 	return &ResearchWorkflow{
-		swarm:   s,
-		results: make(map[string]types.Result),
+		swarm:       s,
+		results:     make(map[string]types.Result),
+		stepTimeout: DefaultStepTimeout,
+		out:         os.Stdout,
+	}
+}
+
+// SetStepTimeout changes how long each step may run (default
+// DefaultStepTimeout). It is set as every step's Task.Timeout, so an agent
+// stops working on a step once the workflow has stopped waiting for it.
+func (rw *ResearchWorkflow) SetStepTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		rw.stepTimeout = timeout
+	}
+}
+
+// SetLanguages has the final report translated into each language (e.g.
+// "Spanish", "German") by a translation agent after it is written
+func (rw *ResearchWorkflow) SetLanguages(languages ...string) {
+	rw.languages = languages
+}
+
+// SetLocale has the agents write in locale's language, e.g. "ja" or "pt-BR"
+// (see types.Task.Locale)
+func (rw *ResearchWorkflow) SetLocale(locale string) {
+	rw.locale = locale
+}
+
+// SetRunID makes the workflow part of run id, e.g. the CLI session that
+// starts it, instead of getting a RunID of its own
+func (rw *ResearchWorkflow) SetRunID(id string) {
+	rw.runID = id
+}
+
+// SetWorkflowID gives the next Execute this WorkflowID instead of a generated
+// one, so a caller can hand the ID out before the workflow finishes
+func (rw *ResearchWorkflow) SetWorkflowID(id string) {
+	rw.workflowID = id
+}
+
+// SetOutput sends the progress messages Execute prints to w instead of
+// stdout, e.g. stderr when stdout carries the results
+func (rw *ResearchWorkflow) SetOutput(w io.Writer) {
+	rw.out = w
+}
+
+// SetStreaming shows each step as it runs, when on: the model's output as it
+// is generated, or a spinner until some arrives. It prints to the writer set
+// with SetOutput, so set that first.
+func (rw *ResearchWorkflow) SetStreaming(on bool) {
+	rw.live = nil
+	if on {
+		rw.live = newLiveOutput(rw.out)
 	}
 }
 
 // Execute runs a complete research workflow with proper context passing
 func (rw *ResearchWorkflow) Execute(topic string) (*WorkflowResult, error) {
-	fmt.Printf("\n🔬 Starting Research Workflow for: %s\n", topic)
-	fmt.Println("=" + string(make([]byte, 60)) + "=")
+	fmt.Fprintf(rw.out, "\n🔬 Starting Research Workflow for: %s\n", topic)
+	fmt.Fprintln(rw.out, "="+string(make([]byte, 60))+"=")
 
 	workflowResult := &WorkflowResult{
+		WorkflowID: fmt.Sprintf("workflow-%d", time.Now().UnixNano()),
 		Topic:      topic,
 		StartTime:  time.Now(),
 		StepResults: make(map[string]string),
+		RunID:      rw.runID,
+	}
+	if workflowResult.RunID == "" {
+		workflowResult.RunID = types.NewRunID()
+	}
+	if rw.workflowID != "" {
+		workflowResult.WorkflowID = rw.workflowID
+	}
+
+	// Task IDs are assigned up front so the whole graph can be announced
+	// before the first step runs; nanoseconds keep concurrent runs apart
+	stamp := time.Now().UnixNano()
+	researchID := fmt.Sprintf("research-%d", stamp)
+	analysisID := fmt.Sprintf("analyze-%d", stamp)
+	reportID := fmt.Sprintf("report-%d", stamp)
+	docsID := fmt.Sprintf("docs-%d", stamp)
+	withDocs := rw.swarm.HasSpecialty("documentation")
+
+	plan := types.WorkflowPlan{
+		WorkflowID: workflowResult.WorkflowID,
+		Name:       fmt.Sprintf("Research: %s", topic),
+		Steps: []types.WorkflowStep{
+			{TaskID: researchID, Name: "research", AgentType: "research"},
+			{TaskID: analysisID, Name: "analysis", AgentType: "analysis", Dependencies: []string{researchID}},
+			{TaskID: reportID, Name: "report", AgentType: "reporting", Dependencies: []string{researchID, analysisID}},
+		},
+	}
+	if withDocs {
+		plan.Steps = append(plan.Steps, types.WorkflowStep{
+			TaskID: docsID, Name: "documentation", AgentType: "documentation",
+			Dependencies: []string{researchID, analysisID, reportID},
+		})
 	}
+	rw.publish(types.EventWorkflowStarted, workflowResult, fmt.Sprintf("🔬 Workflow started: %s", plan.Name), plan)
+
+	memory := rw.swarm.Memory(workflowResult.RunID)
 
 	// Step 1: Research
-	fmt.Println("\n📚 Step 1/3: Research Phase")
+	fmt.Fprintln(rw.out, "\n📚 Step 1/3: Research Phase")
 	researchTask := types.Task{
-		ID:          fmt.Sprintf("research-%d", time.Now().Unix()),
+		ID:          researchID,
 		Description: fmt.Sprintf("Research the topic: %s. Provide comprehensive findings with key insights, trends, and supporting evidence.", topic),
-		Payload:     map[string]interface{}{"type": "research", "topic": topic},
+		Payload:     map[string]interface{}{"type": "research", "topic": topic, "agent_type": "research"},
 		Priority:    1,
 		Context:     make(map[string]interface{}),
+		Timeout:     rw.stepTimeout,
+		RunID:       workflowResult.RunID,
+		Locale:      rw.locale,
+		Stream:      rw.live != nil,
 	}
 
 	if err := rw.swarm.DistributeTask(researchTask); err != nil {
-		return nil, fmt.Errorf("failed to distribute research task: %w", err)
+		return rw.fail(workflowResult, fmt.Errorf("failed to distribute research task: %w", err))
 	}
 
 	// Wait for research to complete
-	researchResult := rw.waitForTaskCompletion(researchTask.ID, 60*time.Second)
+	researchResult := rw.waitForTaskCompletion(researchTask.ID, rw.stepTimeout+stepWaitGrace)
 	if !researchResult.Success {
-		return nil, fmt.Errorf("research failed: %v", researchResult.Data)
+		return rw.fail(workflowResult, fmt.Errorf("research failed: %v", researchResult.Data))
 	}
 
 	workflowResult.StepResults["research"] = fmt.Sprintf("%v", researchResult.Data)
-	fmt.Println("✅ Research completed")
+	memory.Put(types.Document{Name: MemoryResearchNotes, Author: "research", Text: workflowResult.StepResults["research"]})
+	workflowResult.collectArtifacts(StepDefinition{Name: "research"}, researchResult)
+	fmt.Fprintln(rw.out, "✅ Research completed")
 
 	// Step 2: Analysis
-	fmt.Println("\n📊 Step 2/3: Analysis Phase")
+	fmt.Fprintln(rw.out, "\n📊 Step 2/3: Analysis Phase")
 	analysisTask := types.Task{
-		ID:          fmt.Sprintf("analyze-%d", time.Now().Unix()),
+		ID:          analysisID,
 		Description: fmt.Sprintf("Analyze the research findings on %s. Identify patterns, correlations, and generate actionable insights.", topic),
-		Payload:     map[string]interface{}{"type": "analysis", "topic": topic},
+		Payload:     map[string]interface{}{"type": "analysis", "topic": topic, "agent_type": "analysis"},
 		Priority:    2,
 		Context: map[string]interface{}{
-			"research_findings": researchResult.Data,
+			"research_findings": types.MemoryRef{Document: MemoryResearchNotes},
 		},
 		Dependencies: []string{researchTask.ID},
+		Timeout:      rw.stepTimeout,
+		RunID:        workflowResult.RunID,
+		Locale:       rw.locale,
+		Stream:       rw.live != nil,
 	}
 
 	if err := rw.swarm.DistributeTask(analysisTask); err != nil {
-		return nil, fmt.Errorf("failed to distribute analysis task: %w", err)
+		return rw.fail(workflowResult, fmt.Errorf("failed to distribute analysis task: %w", err))
 	}
 
-	analysisResult := rw.waitForTaskCompletion(analysisTask.ID, 60*time.Second)
+	analysisResult := rw.waitForTaskCompletion(analysisTask.ID, rw.stepTimeout+stepWaitGrace)
 	if !analysisResult.Success {
-		return nil, fmt.Errorf("analysis failed: %v", analysisResult.Data)
+		return rw.fail(workflowResult, fmt.Errorf("analysis failed: %v", analysisResult.Data))
 	}
 
 	workflowResult.StepResults["analysis"] = fmt.Sprintf("%v", analysisResult.Data)
-	fmt.Println("✅ Analysis completed")
+	memory.Put(types.Document{Name: MemoryAnalysisInsights, Author: "analysis", Text: workflowResult.StepResults["analysis"]})
+	workflowResult.collectArtifacts(StepDefinition{Name: "analysis"}, analysisResult)
+	fmt.Fprintln(rw.out, "✅ Analysis completed")
 
 	// Step 3: Report Generation
-	fmt.Println("\n📝 Step 3/3: Report Generation Phase")
+	fmt.Fprintln(rw.out, "\n📝 Step 3/3: Report Generation Phase")
 	reportTask := types.Task{
-		ID:          fmt.Sprintf("report-%d", time.Now().Unix()),
+		ID:          reportID,
 		Description: fmt.Sprintf("Generate a comprehensive executive report on %s based on research and analysis.", topic),
-		Payload:     map[string]interface{}{"type": "report", "topic": topic},
+		Payload:     map[string]interface{}{"type": "report", "topic": topic, "agent_type": "reporting"},
 		Priority:    3,
 		Context: map[string]interface{}{
-			"research_findings": researchResult.Data,
-			"analysis_insights": analysisResult.Data,
+			"research_findings": types.MemoryRef{Document: MemoryResearchNotes},
+			"analysis_insights": types.MemoryRef{Document: MemoryAnalysisInsights},
 		},
 		Dependencies: []string{researchTask.ID, analysisTask.ID},
+		Timeout:      rw.stepTimeout,
+		RunID:        workflowResult.RunID,
+		Locale:       rw.locale,
+		Stream:       rw.live != nil,
 	}
 
 	if err := rw.swarm.DistributeTask(reportTask); err != nil {
-		return nil, fmt.Errorf("failed to distribute report task: %w", err)
+		return rw.fail(workflowResult, fmt.Errorf("failed to distribute report task: %w", err))
 	}
 
-	reportResult := rw.waitForTaskCompletion(reportTask.ID, 60*time.Second)
+	reportResult := rw.waitForTaskCompletion(reportTask.ID, rw.stepTimeout+stepWaitGrace)
 	if !reportResult.Success {
-		return nil, fmt.Errorf("report generation failed: %v", reportResult.Data)
+		return rw.fail(workflowResult, fmt.Errorf("report generation failed: %v", reportResult.Data))
 	}
 
 	workflowResult.StepResults["report"] = fmt.Sprintf("%v", reportResult.Data)
+	workflowResult.collectArtifacts(StepDefinition{Name: "report"}, reportResult)
 	workflowResult.FinalReport = fmt.Sprintf("%v", reportResult.Data)
+	fmt.Fprintln(rw.out, "✅ Report generated")
+
+	// Optional Step 4: Documentation cover page, only when a DocAgent is registered.
+	// A documentation failure doesn't fail the workflow; the report is still valid.
+	if withDocs {
+		fmt.Fprintln(rw.out, "\n📘 Documenting the run")
+		docTask := types.Task{
+			ID:          docsID,
+			Description: fmt.Sprintf("Document the completed research workflow on %s.", topic),
+			Payload:     map[string]interface{}{"type": "documentation", "topic": topic, "agent_type": "documentation"},
+			Priority:    4,
+			Context: map[string]interface{}{
+				"research_findings": types.MemoryRef{Document: MemoryResearchNotes},
+				"analysis_insights": types.MemoryRef{Document: MemoryAnalysisInsights},
+				"final_report":      reportResult.Data,
+			},
+			Dependencies: []string{researchTask.ID, analysisTask.ID, reportTask.ID},
+			Timeout:      rw.stepTimeout,
+			RunID:        workflowResult.RunID,
+			Locale:       rw.locale,
+			Stream:       rw.live != nil,
+		}
+
+		if err := rw.swarm.DistributeTask(docTask); err != nil {
+			fmt.Fprintf(rw.out, "⚠️  Documentation skipped: %v\n", err)
+		} else if docResult := rw.waitForTaskCompletion(docTask.ID, rw.stepTimeout+stepWaitGrace); docResult.Success {
+			workflowResult.Documentation = fmt.Sprintf("%v", docResult.Data)
+			workflowResult.collectArtifacts(StepDefinition{Name: "documentation"}, docResult)
+			fmt.Fprintln(rw.out, "✅ Documentation written")
+		} else {
+			fmt.Fprintf(rw.out, "⚠️  Documentation skipped: %v\n", docResult.Data)
+		}
+	}
+
+	translateReport(rw.swarm, workflowResult, rw.languages, rw.stepTimeout)
+
 	workflowResult.EndTime = time.Now()
 	workflowResult.Duration = workflowResult.EndTime.Sub(workflowResult.StartTime)
-
-	fmt.Println("✅ Report generated")
-	fmt.Printf("\n🎉 Workflow completed in %v\n", workflowResult.Duration)
+	fmt.Fprintf(rw.out, "\n🎉 Workflow completed in %v\n", workflowResult.Duration)
+	rw.publish(types.EventWorkflowDone, workflowResult,
+		fmt.Sprintf("🎉 Workflow completed in %v", workflowResult.Duration.Round(time.Second)), workflowResult)
 
 	return workflowResult, nil
 }
 
-// waitForTaskCompletion waits for a task to complete and returns its result
-func (rw *ResearchWorkflow) waitForTaskCompletion(taskID string, timeout time.Duration) types.Result {
-	// Subscribe to events to monitor task completion
-	eventChan := rw.swarm.GetEventBus().Subscribe()
-
-	deadline := time.Now().Add(timeout)
+// fail announces a failed workflow and returns the error
+func (rw *ResearchWorkflow) fail(wr *WorkflowResult, err error) (*WorkflowResult, error) {
+	rw.publish(types.EventWorkflowFailed, wr, fmt.Sprintf("❌ Workflow failed: %v", err), nil)
+	return nil, err
+}
 
-	for {
-		if time.Now().After(deadline) {
-			return types.Result{
-				TaskID:  taskID,
-				Success: false,
-				Data:    "Task timeout",
-			}
-		}
+// publish sends a workflow-level event; the workflow ID goes in TaskID so
+// dashboards can correlate the started/completed/failed events
+func (rw *ResearchWorkflow) publish(eventType types.EventType, wr *WorkflowResult, message string, data interface{}) {
+	rw.swarm.GetEventBus().Publish(types.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		AgentID:   "workflow",
+		TaskID:    wr.WorkflowID,
+		Message:   message,
+		Data:      data,
+		RunID:     wr.RunID,
+	})
+}
 
-		select {
-		case event := <-eventChan:
-			if event.TaskID == taskID {
-				if event.Type == types.EventTaskCompleted {
-					if result, ok := event.Data.(types.Result); ok {
-						return result
-					}
-				} else if event.Type == types.EventTaskFailed {
-					return types.Result{
-						TaskID:  taskID,
-						Success: false,
-						Data:    event.Message,
-					}
-				}
-			}
-		case <-time.After(100 * time.Millisecond):
-			// Continue checking
-		}
-	}
+// waitForTaskCompletion waits for a task to complete and returns its result
+func (rw *ResearchWorkflow) waitForTaskCompletion(taskID string, timeout time.Duration) types.Result {
+	return awaitResultTo(rw.out, rw.live, rw.swarm, taskID, timeout)
 }
 
-// WorkflowResult contains the complete results of a research workflow
+// WorkflowResult contains the complete results of a workflow. It is the Data
+// of the workflow's EventWorkflowDone event, which is how pkg/archive keeps it.
 type WorkflowResult struct {
-	Topic       string
-	StartTime   time.Time
-	EndTime     time.Time
-	Duration    time.Duration
-	StepResults map[string]string
-	FinalReport string
+	WorkflowID    string                      `json:"workflow_id"`
+	Name          string                      `json:"name,omitempty"` // Definition name; empty for the research workflow
+	RunID         string                      `json:"run_id,omitempty"`
+	Topic         string                      `json:"topic"`
+	StartTime     time.Time                   `json:"start_time"`
+	EndTime       time.Time                   `json:"end_time"`
+	Duration      time.Duration               `json:"duration"` // Nanoseconds in JSON
+	StepResults   map[string]string           `json:"step_results"`
+	Steps         []string                    `json:"steps,omitempty"` // Step names in order for definition workflows; nil for the research workflow
+	FinalReport   string                      `json:"final_report"`
+	Documentation string                      `json:"documentation,omitempty"` // Cover page written by a DocAgent, empty when none is registered
+	Reviews       map[string]types.Review     `json:"reviews,omitempty"`       // Critic verdicts of reviewed definition steps, by step name
+	Translations  map[string]string           `json:"translations,omitempty"`  // FinalReport by language, when translations were requested
+	Debates       map[string]DebateTranscript `json:"debates,omitempty"`       // Transcripts of debate steps, by step name
+	Artifacts     []types.Artifact            `json:"artifacts,omitempty"`     // Files the steps produced; archived runs list them without their content
+	Tokens        int                         `json:"tokens,omitempty"`        // LLM tokens spent by definition workflows' tasks
+	CostUSD       float64                     `json:"cost_usd,omitempty"`      // Their estimated cost at list price
 }
 
 // Display prints the workflow results in a readable format
@@ -167,8 +332,47 @@ func (wr *WorkflowResult) Display() {
 	fmt.Println("\n" + string(make([]byte, 70)))
 	fmt.Println("📋 WORKFLOW RESULTS")
 	fmt.Println(string(make([]byte, 70)))
+	if wr.Name != "" {
+		fmt.Printf("\n🧩 Workflow: %s\n", wr.Name)
+	}
 	fmt.Printf("\n📌 Topic: %s\n", wr.Topic)
-	fmt.Printf("⏱️  Duration: %v\n\n", wr.Duration)
+	fmt.Printf("⏱️  Duration: %v\n", wr.Duration)
+	if wr.Tokens > 0 {
+		fmt.Printf("💰 LLM spend: %d tokens ($%.4f)\n", wr.Tokens, wr.CostUSD)
+	}
+	fmt.Println()
+
+	// Definition workflows have their own steps: print each one in order
+	if len(wr.Steps) > 0 {
+		for _, step := range wr.Steps {
+			out, ok := wr.StepResults[step]
+			if !ok {
+				continue // Optional step that failed
+			}
+			fmt.Println("\n" + "=" + string(make([]byte, 68)) + "=")
+			fmt.Printf("▶️  %s\n", strings.ToUpper(step))
+			fmt.Println("=" + string(make([]byte, 68)) + "=")
+			wr.printWrapped(out, 70)
+			if review, ok := wr.Reviews[step]; ok {
+				fmt.Printf("\n🔎 Review: %s\n", review)
+			}
+			if debate, ok := wr.Debates[step]; ok {
+				fmt.Printf("\n🗣️  Consensus of %s after %d round(s) of critique\n", strings.Join(debate.Debaters, ", "), len(debate.Rounds)-1)
+			}
+		}
+		wr.displayTranslations()
+		wr.displayArtifacts()
+		fmt.Println("\n" + string(make([]byte, 70)))
+		return
+	}
+
+	if wr.Documentation != "" {
+		fmt.Println("=" + string(make([]byte, 68)) + "=")
+		fmt.Println("📘 COVER PAGE")
+		fmt.Println("=" + string(make([]byte, 68)) + "=")
+		wr.printWrapped(wr.Documentation, 70)
+		fmt.Println()
+	}
 
 	fmt.Println("=" + string(make([]byte, 68)) + "=")
 	fmt.Println("📚 RESEARCH FINDINGS")
@@ -184,10 +388,32 @@ func (wr *WorkflowResult) Display() {
 	fmt.Println("📝 FINAL REPORT")
 	fmt.Println("=" + string(make([]byte, 68)) + "=")
 	wr.printWrapped(wr.FinalReport, 70)
+	wr.displayTranslations()
+	wr.displayArtifacts()
 
 	fmt.Println("\n" + string(make([]byte, 70)))
 }
 
+// displayTranslations prints each translation of the final report, sorted by language
+func (wr *WorkflowResult) displayTranslations() {
+	for _, language := range wr.Languages() {
+		fmt.Println("\n" + "=" + string(make([]byte, 68)) + "=")
+		fmt.Printf("🌍 FINAL REPORT (%s)\n", strings.ToUpper(language))
+		fmt.Println("=" + string(make([]byte, 68)) + "=")
+		wr.printWrapped(wr.Translations[language], 70)
+	}
+}
+
+// Languages lists the languages the final report was translated into, sorted
+func (wr *WorkflowResult) Languages() []string {
+	languages := make([]string, 0, len(wr.Translations))
+	for language := range wr.Translations {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
 func (wr *WorkflowResult) printWrapped(text string, width int) {
 	// Simple word wrap for better readability
 	words := []rune(text)
@@ -0,0 +1,53 @@
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"agent-swarm-go/pkg/logging"
+	"agent-swarm-go/pkg/swarm"
+)
+
+// progressRecorder returns a function that records a run's finished steps
+// with the swarm, so a swarm Snapshot can resume the run later
+func progressRecorder(s *swarm.Swarm, def *Definition, wr *WorkflowResult, values map[string]string) func(outputs map[string]interface{}) {
+	definition, err := json.Marshal(def)
+	if err != nil {
+		// Definitions come from YAML or JSON, so this doesn't happen
+		slog.Warn("workflow can't be checkpointed", "workflow", wr.WorkflowID, logging.KeyError, err)
+		return func(map[string]interface{}) {}
+	}
+
+	return func(outputs map[string]interface{}) {
+		completed := make(map[string]interface{}, len(outputs))
+		for name, out := range outputs {
+			completed[name] = out
+		}
+		s.RecordWorkflow(swarm.WorkflowProgress{
+			ID:         wr.WorkflowID,
+			Name:       def.Name,
+			RunID:      wr.RunID,
+			Definition: definition,
+			Inputs:     values,
+			Completed:  completed,
+			Started:    wr.StartTime,
+		})
+	}
+}
+
+// Resume continues a workflow run recorded in a restored swarm (see
+// swarm.Restore), keeping its ID and the outputs of the steps that had
+// finished. Steps that were running are started again. Step outputs come back
+// as their JSON form, and the run's budget counts only what is spent from
+// here on.
+func (e *Engine) Resume(progress swarm.WorkflowProgress) (*WorkflowResult, error) {
+	def := &Definition{}
+	if err := json.Unmarshal(progress.Definition, def); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", progress.ID, err)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", progress.ID, err)
+	}
+	return e.run(def, progress.Inputs, &progress)
+}
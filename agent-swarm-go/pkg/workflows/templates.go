@@ -0,0 +1,53 @@
+package workflows
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// templateFiles holds the built-in workflow templates
+//
+//go:embed templates/*.yaml
+var templateFiles embed.FS
+
+// Template is a workflow definition built into the binary
+type Template struct {
+	ID         string // Its file name without the extension, e.g. "swot"
+	Definition *Definition
+}
+
+// Templates returns the built-in workflow templates, sorted by name: SWOT
+// analysis, competitor comparison, literature review, and product brief. They
+// run like definition files (see Engine.Run); to adapt one, copy it from
+// pkg/workflows/templates into ./workflows.
+func Templates() ([]Template, error) {
+	entries, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Template
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		def, err := LoadTemplate(id)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, Template{ID: id, Definition: def})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Definition.Name < list[j].Definition.Name })
+	return list, nil
+}
+
+// LoadTemplate returns the built-in template with the given ID, e.g. "swot"
+func LoadTemplate(id string) (*Definition, error) {
+	name := path.Join("templates", id+".yaml")
+	data, err := templateFiles.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("no built-in workflow template %q", id)
+	}
+	return parseDefinition(name, data)
+}
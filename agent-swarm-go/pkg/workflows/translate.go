@@ -0,0 +1,49 @@
+package workflows
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"agent-swarm-go/pkg/swarm"
+	"agent-swarm-go/pkg/types"
+)
+
+// translateReport has a translation agent translate a finished workflow's
+// report into each language and stores the results in wr.Translations.
+// Like the cover page, translations are extras: when there is no translation
+// agent or the translation fails, the workflow still succeeds without them.
+func translateReport(s *swarm.Swarm, wr *WorkflowResult, languages []string, timeout time.Duration) {
+	if len(languages) == 0 || wr.FinalReport == "" {
+		return
+	}
+	if !s.HasSpecialty("translation") {
+		fmt.Println("⚠️  Translations skipped: no translation agent in the swarm")
+		return
+	}
+
+	fmt.Printf("\n🌍 Translating the report into %s\n", strings.Join(languages, ", "))
+	task := types.Task{
+		ID:          fmt.Sprintf("translate-%d", time.Now().UnixNano()),
+		Description: fmt.Sprintf("Translate the final report on %s.", wr.Topic),
+		Payload:     map[string]interface{}{"type": "translation", "agent_type": "translation", "languages": languages, swarm.WorkflowPayloadKey: wr.WorkflowID},
+		Context:     map[string]interface{}{"final_report": wr.FinalReport},
+		// One LLM call per language, so each language gets a full step's time
+		Timeout: timeout * time.Duration(len(languages)),
+		RunID:   wr.RunID,
+	}
+
+	if err := s.DistributeTask(task); err != nil {
+		fmt.Printf("⚠️  Translations skipped: %v\n", err)
+		return
+	}
+	result := awaitResult(s, task.ID, task.Timeout+stepWaitGrace)
+	translations, ok := result.Data.(types.Translations)
+	if !result.Success || !ok {
+		fmt.Printf("⚠️  Translations skipped: %v\n", result.Data)
+		return
+	}
+
+	wr.Translations = translations
+	fmt.Printf("✅ Report translated into %d language(s)\n", len(translations))
+}
@@ -2,12 +2,18 @@ package handler
 
 import (
     "bytes"
+    "crypto/subtle"
     "encoding/json"
     "fmt"
+    "html/template"
     "io"
     "net/http"
+    "net/url"
     "os"
+    "regexp"
+    "sort"
     "strings"
+    "sync"
     "time"
 )
 
@@ -17,11 +23,237 @@ type ContactForm struct {
     Message string `json:"message"`
 }
 
+// InvestorInquiry is submitted through the multi-step form on /investors and
+// stored separately from general contact messages so it can be triaged.
+type InvestorInquiry struct {
+    Name        string    `json:"name"`
+    Email       string    `json:"email"`
+    FundName    string    `json:"fund_name"`
+    CheckSize   string    `json:"check_size"`  // One of checkSizes
+    StageFocus  string    `json:"stage_focus"` // One of stageFocuses
+    Message     string    `json:"message"`
+    SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// checkSizes and stageFocuses are the form's choices, in display order
+var checkSizes = []string{"under-100k", "100k-500k", "500k-2m", "2m-plus"}
+var stageFocuses = []string{"pre-seed", "seed", "series-a", "series-b-plus"}
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// Validate checks an inquiry server-side and returns per-field errors
+// (keyed by JSON field name), or nil when it is valid
+func (q InvestorInquiry) Validate() map[string]string {
+    errs := map[string]string{}
+    if strings.TrimSpace(q.Name) == "" {
+        errs["name"] = "Please enter your name"
+    }
+    if !emailPattern.MatchString(q.Email) {
+        errs["email"] = "Please enter a valid email address"
+    }
+    if strings.TrimSpace(q.FundName) == "" {
+        errs["fund_name"] = "Please enter your fund or firm name"
+    }
+    if !contains(checkSizes, q.CheckSize) {
+        errs["check_size"] = "Please choose a typical check size"
+    }
+    if !contains(stageFocuses, q.StageFocus) {
+        errs["stage_focus"] = "Please choose a stage focus"
+    }
+    if n := len(strings.TrimSpace(q.Message)); n < 20 || n > 2000 {
+        errs["message"] = "Please write between 20 and 2000 characters"
+    }
+    if len(errs) == 0 {
+        return nil
+    }
+    return errs
+}
+
+// LeadScore rates how promising an inquiry is (0-100) for triage: we're
+// raising a pre-seed/seed round, so stage fit and check size count most.
+func (q InvestorInquiry) LeadScore() int {
+    score := 0
+    switch q.StageFocus {
+    case "pre-seed", "seed":
+        score += 40
+    case "series-a":
+        score += 20
+    }
+    switch q.CheckSize {
+    case "100k-500k", "500k-2m":
+        score += 35
+    case "2m-plus":
+        score += 25
+    case "under-100k":
+        score += 15
+    }
+    if n := len(strings.TrimSpace(q.Message)); n >= 200 {
+        score += 15
+    } else if n >= 80 {
+        score += 10
+    }
+    if domain := q.Email[strings.LastIndex(q.Email, "@")+1:]; !freeEmailDomains[strings.ToLower(domain)] {
+        score += 10 // Firm address rather than a personal one
+    }
+    return score
+}
+
+// LeadLabel buckets LeadScore for the admin page
+func (q InvestorInquiry) LeadLabel() string {
+    switch score := q.LeadScore(); {
+    case score >= 70:
+        return "hot"
+    case score >= 45:
+        return "warm"
+    default:
+        return "cold"
+    }
+}
+
+var freeEmailDomains = map[string]bool{
+    "gmail.com": true, "yahoo.com": true, "hotmail.com": true, "outlook.com": true, "icloud.com": true, "proton.me": true,
+}
+
+func contains(list []string, value string) bool {
+    for _, v := range list {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
+
+// SiteConfig holds the branding rendered into every page, so the site can be
+// re-skinned for another startup from /admin/site without touching the HTML.
+type SiteConfig struct {
+    Title           string       `json:"title"`
+    Tagline         string       `json:"tagline"`
+    BackgroundImage string       `json:"background_image"`
+    Colors          SiteColors   `json:"colors"`
+    Partners        []Partner    `json:"partners"`
+    Social          []SocialLink `json:"social"`
+}
+
+// SiteColors are CSS hex colors, e.g. "#0a0a0a"
+type SiteColors struct {
+    Background string `json:"background"`
+    Text       string `json:"text"`
+    Muted      string `json:"muted"`
+    Accent     string `json:"accent"`      // Button gradient start
+    AccentDark string `json:"accent_dark"` // Button gradient end
+}
+
+// Partner is a badge on the landing page; Logo (an image URL) replaces the name when set
+type Partner struct {
+    Name       string `json:"name"`
+    URL        string `json:"url"`
+    Logo       string `json:"logo,omitempty"`
+    Background string `json:"background"`
+    TextColor  string `json:"text_color"`
+}
+
+// SocialLink is an icon link; Network picks the icon (twitter, linkedin, github, instagram, youtube)
+type SocialLink struct {
+    Network string `json:"network"`
+    URL     string `json:"url"`
+}
+
+// defaultSiteConfigJSON is the Brainreader AI branding, used until an admin saves a config
+const defaultSiteConfigJSON = `{
+    "title": "Brainreader AI",
+    "tagline": "Understand and Control Neural Syntax",
+    "background_image": "/chalkboard.png",
+    "colors": {
+        "background": "#0a0a0a",
+        "text": "#ffffff",
+        "muted": "#888888",
+        "accent": "#6a0dad",
+        "accent_dark": "#4b0082"
+    },
+    "partners": [
+        {"name": "Y Combinator", "url": "https://www.ycombinator.com", "background": "#ff6600", "text_color": "#ffffff"},
+        {"name": "NVIDIA Inception", "url": "https://www.nvidia.com", "background": "#76b900", "text_color": "#000000"}
+    ],
+    "social": [
+        {"network": "twitter", "url": "https://twitter.com"},
+        {"network": "linkedin", "url": "https://linkedin.com"}
+    ]
+}`
+
+// socialIcons maps SocialLink.Network to an SVG icon
+var socialIcons = map[string]template.HTML{
+    "twitter":   `<svg width="20" height="20" fill="currentColor" viewBox="0 0 24 24"><path d="M23 3a10.9 10.9 0 01-3.14 1.53 4.48 4.48 0 00-7.86 3v1A10.66 10.66 0 013 4s-4 9 5 13a11.64 11.64 0 01-7 2c9 5 20 0 20-11.5a4.5 4.5 0 00-.08-.83A7.72 7.72 0 0023 3z"/></svg>`,
+    "linkedin":  `<svg width="20" height="20" fill="currentColor" viewBox="0 0 24 24"><path d="M16 8a6 6 0 016 6v7h-4v-7a2 2 0 00-2-2 2 2 0 00-2 2v7h-4v-7a6 6 0 016-6zM2 9h4v12H2z"/><circle cx="4" cy="4" r="2"/></svg>`,
+    "github":    `<svg width="20" height="20" fill="currentColor" viewBox="0 0 24 24"><path d="M12 .5a11.5 11.5 0 00-3.64 22.41c.58.1.79-.25.79-.56v-2c-3.2.7-3.88-1.37-3.88-1.37-.53-1.33-1.28-1.69-1.28-1.69-1.05-.72.08-.7.08-.7 1.16.08 1.77 1.19 1.77 1.19 1.03 1.77 2.7 1.26 3.36.96.1-.75.4-1.26.73-1.55-2.55-.29-5.24-1.28-5.24-5.69 0-1.26.45-2.28 1.19-3.09-.12-.29-.52-1.46.11-3.05 0 0 .97-.31 3.17 1.18a11 11 0 015.77 0c2.2-1.49 3.17-1.18 3.17-1.18.63 1.59.23 2.76.11 3.05.74.81 1.19 1.83 1.19 3.09 0 4.42-2.69 5.39-5.26 5.68.41.36.78 1.06.78 2.14v3.17c0 .31.21.67.8.56A11.5 11.5 0 0012 .5z"/></svg>`,
+    "instagram": `<svg width="20" height="20" fill="none" stroke="currentColor" stroke-width="2" viewBox="0 0 24 24"><rect x="2" y="2" width="20" height="20" rx="5"/><circle cx="12" cy="12" r="4"/><circle cx="17.5" cy="6.5" r="1"/></svg>`,
+    "youtube":   `<svg width="20" height="20" fill="currentColor" viewBox="0 0 24 24"><path d="M23 7.5a3 3 0 00-2.1-2.1C19 5 12 5 12 5s-7 0-8.9.4A3 3 0 001 7.5 31 31 0 00.6 12 31 31 0 001 16.5a3 3 0 002.1 2.1C5 19 12 19 12 19s7 0 8.9-.4a3 3 0 002.1-2.1 31 31 0 00.4-4.5 31 31 0 00-.4-4.5zM9.75 15.02V8.98L15.5 12z"/></svg>`,
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{3}([0-9a-fA-F]{3})?$`)
+
+// Validate checks a config before it is saved
+func (c SiteConfig) Validate() error {
+    if strings.TrimSpace(c.Title) == "" {
+        return fmt.Errorf("title is required")
+    }
+    colors := [][2]string{
+        {"colors.background", c.Colors.Background},
+        {"colors.text", c.Colors.Text},
+        {"colors.muted", c.Colors.Muted},
+        {"colors.accent", c.Colors.Accent},
+        {"colors.accent_dark", c.Colors.AccentDark},
+    }
+    for i, p := range c.Partners {
+        if p.Name == "" || !validURL(p.URL) {
+            return fmt.Errorf("partner %d needs a name and an http(s) url", i+1)
+        }
+        if p.Logo != "" && !validURL(p.Logo) && !strings.HasPrefix(p.Logo, "/") {
+            return fmt.Errorf("partner %q: logo must be an http(s) or site-relative url", p.Name)
+        }
+        colors = append(colors,
+            [2]string{fmt.Sprintf("partners[%d].background", i), p.Background},
+            [2]string{fmt.Sprintf("partners[%d].text_color", i), p.TextColor})
+    }
+    for _, color := range colors {
+        if !hexColor.MatchString(color[1]) {
+            return fmt.Errorf("%s must be a hex color like #1a2b3c, got %q", color[0], color[1])
+        }
+    }
+    for _, s := range c.Social {
+        if _, ok := socialIcons[s.Network]; !ok {
+            return fmt.Errorf("unknown social network %q", s.Network)
+        }
+        if !validURL(s.URL) {
+            return fmt.Errorf("%s link must be an http(s) url", s.Network)
+        }
+    }
+    return nil
+}
+
+func validURL(u string) bool {
+    return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")
+}
+
+// Page templates, built from the HTML constants at the bottom of this file
+var (
+    pageFuncs = template.FuncMap{
+        "upper": strings.ToUpper,
+        "icon":  func(network string) template.HTML { return socialIcons[network] },
+    }
+    indexTmpl     = template.Must(template.New("index").Funcs(pageFuncs).Parse(indexHTML))
+    foundersTmpl  = template.Must(template.New("founders").Funcs(pageFuncs).Parse(foundersHTML))
+    advisorsTmpl  = template.Must(template.New("advisors").Funcs(pageFuncs).Parse(advisorsHTML))
+    investorsTmpl = template.Must(template.New("investors").Funcs(pageFuncs).Parse(investorsHTML))
+    siteAdminTmpl = template.Must(template.New("site-admin").Parse(siteAdminHTML))
+)
+
 func Handler(w http.ResponseWriter, r *http.Request) {
     if r.URL.Path == "/" || r.URL.Path == "" {
         homeHandler(w, r)
     } else if r.URL.Path == "/api/contact" {
         contactHandler(w, r)
+    } else if r.URL.Path == "/api/investor-inquiry" {
+        investorInquiryHandler(w, r)
     } else if r.URL.Path == "/founders" {
         foundersHandler(w, r)
     } else if r.URL.Path == "/advisors" {
@@ -30,14 +262,25 @@ func Handler(w http.ResponseWriter, r *http.Request) {
         investorsHandler(w, r)
     } else if r.URL.Path == "/admin" {
         adminHandler(w, r)
+    } else if r.URL.Path == "/admin/site" {
+        siteAdminHandler(w, r)
+    } else if r.URL.Path == "/api/digest" {
+        digestHandler(w, r)
     } else {
         http.NotFound(w, r)
     }
 }
 
 func homeHandler(w http.ResponseWriter, r *http.Request) {
+    renderPage(w, indexTmpl)
+}
+
+// renderPage renders a page template with the current site config
+func renderPage(w http.ResponseWriter, tmpl *template.Template) {
     w.Header().Set("Content-Type", "text/html")
-    fmt.Fprint(w, indexHTML)
+    if err := tmpl.Execute(w, loadSiteConfig()); err != nil {
+        fmt.Printf("Template error: %v\n", err)
+    }
 }
 
 func contactHandler(w http.ResponseWriter, r *http.Request) {
@@ -62,46 +305,122 @@ func contactHandler(w http.ResponseWriter, r *http.Request) {
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(map[string]string{
         "status": "success",
-        "message": "Thank you for contacting Brainreader AI",
+        "message": "Thank you for contacting " + loadSiteConfig().Title,
+    })
+}
+
+func investorInquiryHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method != "POST" {
+        http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+        return
+    }
+
+    var inquiry InvestorInquiry
+    if err := json.NewDecoder(r.Body).Decode(&inquiry); err != nil {
+        http.Error(w, "Invalid request", http.StatusBadRequest)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    if errs := inquiry.Validate(); errs != nil {
+        w.WriteHeader(http.StatusUnprocessableEntity)
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "status": "error",
+            "errors": errs,
+        })
+        return
+    }
+    inquiry.SubmittedAt = time.Now().UTC()
+
+    if err := saveInvestorInquiry(inquiry); err != nil {
+        fmt.Printf("Blob storage error: %v\n", err)
+    }
+
+    // Names and emails stay out of the function logs; /admin shows them
+    fmt.Printf("Investor inquiry: lead score %d (%s)\n", inquiry.LeadScore(), inquiry.LeadLabel())
+
+    json.NewEncoder(w).Encode(map[string]string{
+        "status":  "success",
+        "message": "Thank you for your interest in " + loadSiteConfig().Title + ". We'll be in touch soon.",
     })
 }
 
 func foundersHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "text/html")
-    fmt.Fprint(w, foundersHTML)
+    renderPage(w, foundersTmpl)
 }
 
 func advisorsHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "text/html")
-    fmt.Fprint(w, advisorsHTML)
+    renderPage(w, advisorsTmpl)
 }
 
 func investorsHandler(w http.ResponseWriter, r *http.Request) {
-    w.Header().Set("Content-Type", "text/html")
-    fmt.Fprint(w, investorsHTML)
+    renderPage(w, investorsTmpl)
 }
 
+// adminHandler lists contact messages and investor inquiries. It is
+// protected like siteAdminHandler, since both hold personal details.
 func adminHandler(w http.ResponseWriter, r *http.Request) {
+    if !requireAdmin(w, r) {
+        return
+    }
+
     w.Header().Set("Content-Type", "text/html")
 
+    // ?type=contact or ?type=investor shows only that kind of submission
+    filter := r.URL.Query().Get("type")
+
     // Get submissions from blob storage
-    submissions, err := getSubmissions()
-    if err != nil {
-        fmt.Printf("Error getting submissions: %v\n", err)
-        submissions = []ContactForm{} // Empty if error
+    var submissions []ContactForm
+    var inquiries []InvestorInquiry
+    var err error
+    if filter != "investor" {
+        if submissions, err = getSubmissions(); err != nil {
+            fmt.Printf("Error getting submissions: %v\n", err)
+            submissions = []ContactForm{} // Empty if error
+        }
+    }
+    if filter != "contact" {
+        if inquiries, err = getInvestorInquiries(); err != nil {
+            fmt.Printf("Error getting investor inquiries: %v\n", err)
+            inquiries = []InvestorInquiry{}
+        }
     }
 
+    // Best leads first
+    sort.SliceStable(inquiries, func(i, j int) bool {
+        return inquiries[i].LeadScore() > inquiries[j].LeadScore()
+    })
+
     // Generate submissions HTML
+    esc := template.HTMLEscapeString
     submissionsHTML := ""
-    if len(submissions) == 0 {
+    if len(submissions) == 0 && len(inquiries) == 0 {
         submissionsHTML = `<div class="no-data">
             <p>No submissions yet. Make sure:</p>
             <ul>
                 <li>BLOB_READ_WRITE_TOKEN is set in Environment Variables</li>
-                <li>Someone has submitted the contact form</li>
+                <li>Someone has submitted the contact form or the investor form</li>
             </ul>
         </div>`
-    } else {
+    }
+    if len(inquiries) > 0 {
+        submissionsHTML += fmt.Sprintf(`<h2>Investor Inquiries (%d)</h2>`, len(inquiries))
+        for i, q := range inquiries {
+            submissionsHTML += fmt.Sprintf(`
+            <div class="submission investor">
+                <h3>Investor Inquiry #%d <span class="lead %s">%s lead · %d</span></h3>
+                <p><strong>Fund:</strong> %s</p>
+                <p><strong>Check size:</strong> %s | <strong>Stage focus:</strong> %s</p>
+                <p><strong>Name:</strong> %s</p>
+                <p><strong>Email:</strong> %s</p>
+                <p><strong>Message:</strong> %s</p>
+                <p class="meta">Submitted %s</p>
+            </div>`, i+1, q.LeadLabel(), q.LeadLabel(), q.LeadScore(), esc(q.FundName), esc(q.CheckSize), esc(q.StageFocus),
+                esc(q.Name), esc(q.Email), esc(q.Message), q.SubmittedAt.Format("2006-01-02 15:04 UTC"))
+        }
+    }
+    if len(submissions) > 0 {
+        submissionsHTML += fmt.Sprintf(`<h2>Contact Messages (%d)</h2>`, len(submissions))
         for i, sub := range submissions {
             submissionsHTML += fmt.Sprintf(`
             <div class="submission">
@@ -109,8 +428,21 @@ func adminHandler(w http.ResponseWriter, r *http.Request) {
                 <p><strong>Name:</strong> %s</p>
                 <p><strong>Email:</strong> %s</p>
                 <p><strong>Message:</strong> %s</p>
-            </div>`, i+1, sub.Name, sub.Email, sub.Message)
+            </div>`, i+1, esc(sub.Name), esc(sub.Email), esc(sub.Message))
+        }
+    }
+
+    filterLinks := ""
+    for _, f := range []struct{ value, label string }{{"", "All"}, {"investor", "Investors"}, {"contact", "Contact"}} {
+        class := ""
+        if f.value == filter {
+            class = ` class="active"`
+        }
+        href := "/admin"
+        if f.value != "" {
+            href += "?type=" + f.value
         }
+        filterLinks += fmt.Sprintf(`<a href="%s"%s>%s</a>`, href, class, f.label)
     }
 
     html := fmt.Sprintf(`<!DOCTYPE html>
@@ -124,66 +456,495 @@ func adminHandler(w http.ResponseWriter, r *http.Request) {
         .submission { border: 1px solid #ddd; padding: 15px; margin: 10px 0; border-radius: 5px; background: #f9f9f9; }
         .no-data { background: #fff3cd; padding: 15px; border-radius: 5px; border: 1px solid #ffeaa7; }
         .back-link { color: #1976d2; text-decoration: none; }
+        .submission.investor { border-left: 4px solid #ff9800; }
+        .lead { font-size: 12px; padding: 2px 8px; border-radius: 10px; margin-left: 8px; vertical-align: middle; color: white; }
+        .lead.hot { background: #e53935; }
+        .lead.warm { background: #fb8c00; }
+        .lead.cold { background: #90a4ae; }
+        .meta { color: #888; font-size: 12px; }
+        .filters a { margin-right: 10px; color: #1976d2; text-decoration: none; }
+        .filters a.active { font-weight: bold; text-decoration: underline; }
     </style>
 </head>
 <body>
     <div class="container">
-        <a href="/" class="back-link">← Back to Home</a>
+        <a href="/" class="back-link">← Back to Home</a> | <a href="/admin/site" class="back-link">Edit site config</a>
         <h1>Contact Submissions</h1>
+        <div class="filters">Show: %s</div>
         %s
     </div>
 </body>
-</html>`, submissionsHTML)
+</html>`, filterLinks, submissionsHTML)
 
     fmt.Fprint(w, html)
 }
 
-func getSubmissions() ([]ContactForm, error) {
+// siteAdminHandler shows (GET) and saves (POST) the site config.
+// It is protected by HTTP basic auth with the ADMIN_PASSWORD environment
+// variable (any username) and disabled when that isn't set.
+func siteAdminHandler(w http.ResponseWriter, r *http.Request) {
+    if !requireAdmin(w, r) {
+        return
+    }
+
+    data := struct {
+        ConfigJSON string
+        Message    string
+        Error      string
+    }{}
+
+    if r.Method == "POST" {
+        // The browser resends the admin login to any page that posts here,
+        // so only the editor's own form may save
+        if !sameOrigin(r) {
+            http.Error(w, "Cross-site request refused", http.StatusForbidden)
+            return
+        }
+        data.ConfigJSON = r.FormValue("config")
+        var config SiteConfig
+        if err := json.Unmarshal([]byte(data.ConfigJSON), &config); err != nil {
+            data.Error = "Invalid JSON: " + err.Error()
+        } else if err := config.Validate(); err != nil {
+            data.Error = err.Error()
+        } else if err := saveSiteConfig(config); err != nil {
+            data.Error = "Could not save: " + err.Error()
+        } else {
+            data.Message = "Saved. The site now uses this config."
+        }
+    }
+
+    if data.ConfigJSON == "" {
+        pretty, _ := json.MarshalIndent(loadSiteConfig(), "", "    ")
+        data.ConfigJSON = string(pretty)
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    if data.Error != "" {
+        w.WriteHeader(http.StatusBadRequest)
+    }
+    siteAdminTmpl.Execute(w, data)
+}
+
+// sameOrigin reports whether r was sent from a page on this site, by its
+// Origin header or, in browsers that don't send one, its Referer
+func sameOrigin(r *http.Request) bool {
+    source := r.Header.Get("Origin")
+    if source == "" {
+        source = r.Header.Get("Referer")
+    }
+    u, err := url.Parse(source)
+    return err == nil && source != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// requireAdmin checks basic auth against ADMIN_PASSWORD, writing the
+// challenge or error response when it fails
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+    password := os.Getenv("ADMIN_PASSWORD")
+    if password == "" {
+        http.Error(w, "Admin pages disabled: set ADMIN_PASSWORD", http.StatusServiceUnavailable)
+        return false
+    }
+
+    _, given, ok := r.BasicAuth()
+    if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(password)) != 1 {
+        w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+        http.Error(w, "Unauthorized", http.StatusUnauthorized)
+        return false
+    }
+    return true
+}
+
+// Submission digest: /api/digest emails the team one summary of every contact
+// message and investor inquiry stored since the previous digest. It is meant
+// to be hit by a Vercel cron job (see vercel.json), which sends
+// "Authorization: Bearer $CRON_SECRET". Each sent digest is recorded as
+// digests/digest-<unix>.json listing the files it covered; the newest
+// record's Through is where the next digest starts. Submissions stored while
+// a digest was being sent come after it, so they go out next time, and the
+// files a digest covered are never sent twice.
+type digestRecord struct {
+    SentAt    time.Time `json:"sent_at"`
+    Since     time.Time `json:"since"`
+    Through   time.Time `json:"through"` // Upload time of the newest submission included
+    Recipient string    `json:"recipient"`
+    Items     []string  `json:"items"` // Blob pathnames included in this digest
+}
+
+// digestItem is one new submission, with the upload time blob storage reports
+type digestItem struct {
+    Pathname   string
+    UploadedAt time.Time
+    Contact    *ContactForm
+    Inquiry    *InvestorInquiry
+}
+
+func digestHandler(w http.ResponseWriter, r *http.Request) {
+    secret := os.Getenv("CRON_SECRET")
+    if secret == "" {
+        http.Error(w, "Digest disabled: set CRON_SECRET", http.StatusServiceUnavailable)
+        return
+    }
+    if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+secret)) != 1 {
+        http.Error(w, "Unauthorized", http.StatusUnauthorized)
+        return
+    }
+
     blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
     if blobToken == "" {
-        return nil, fmt.Errorf("BLOB_READ_WRITE_TOKEN not configured")
+        http.Error(w, "BLOB_READ_WRITE_TOKEN not configured", http.StatusServiceUnavailable)
+        return
     }
 
-    // List files in contacts folder
-    listURL := "https://blob.vercel-storage.com/list?prefix=contacts/"
+    last, err := lastDigest(blobToken)
+    if err != nil {
+        fmt.Printf("Digest error: %v\n", err)
+        http.Error(w, "Could not read digest history", http.StatusBadGateway)
+        return
+    }
+    since := last.Through
+    items, err := newSubmissions(last, blobToken)
+    if err != nil {
+        fmt.Printf("Digest error: %v\n", err)
+        http.Error(w, "Could not list submissions", http.StatusBadGateway)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    result := map[string]interface{}{
+        "since": since,
+        "items": len(items),
+    }
 
-    req, err := http.NewRequest("GET", listURL, nil)
+    // Nothing new: send no email and leave the digest history unchanged
+    if len(items) == 0 {
+        result["status"] = "nothing new"
+        json.NewEncoder(w).Encode(result)
+        return
+    }
+
+    subject, body := buildDigest(since, items)
+
+    // ?dry_run=1 shows what would be sent without emailing or marking anything
+    if r.URL.Query().Get("dry_run") != "" {
+        result["status"] = "dry run"
+        result["subject"] = subject
+        result["body"] = body
+        json.NewEncoder(w).Encode(result)
+        return
+    }
+
+    recipient := os.Getenv("DIGEST_TO")
+    if err := sendEmail(recipient, subject, body); err != nil {
+        fmt.Printf("Digest email error: %v\n", err)
+        http.Error(w, "Could not send digest email: "+err.Error(), http.StatusBadGateway)
+        return
+    }
+
+    record := digestRecord{SentAt: time.Now().UTC(), Since: since, Recipient: recipient}
+    for _, item := range items {
+        record.Items = append(record.Items, item.Pathname)
+        if item.UploadedAt.After(record.Through) {
+            record.Through = item.UploadedAt
+        }
+    }
+    data, err := json.Marshal(record)
+    if err == nil {
+        err = putBlob(fmt.Sprintf("digests/digest-%d.json", record.SentAt.Unix()), data, blobToken)
+    }
     if err != nil {
-        return nil, err
+        // The email went out; the same items will be included again next time
+        fmt.Printf("Digest sent but not recorded: %v\n", err)
+    }
+
+    result["status"] = "sent"
+    json.NewEncoder(w).Encode(result)
+}
+
+// lastDigest returns the newest digest record, or an empty one when no
+// digest has been sent yet. A record from before Through was kept starts
+// the next digest at its own upload time.
+func lastDigest(token string) (digestRecord, error) {
+    blobs, err := listBlobs("digests/digest-", token)
+    if err != nil {
+        return digestRecord{}, err
+    }
+    var newest *blobInfo
+    for i := range blobs {
+        if newest == nil || blobs[i].UploadedAt.After(newest.UploadedAt) {
+            newest = &blobs[i]
+        }
+    }
+    if newest == nil {
+        return digestRecord{}, nil
     }
 
-    req.Header.Set("Authorization", "Bearer "+blobToken)
+    body, err := fetchBlob(newest.URL, token)
+    if err != nil {
+        return digestRecord{}, err
+    }
+    var record digestRecord
+    if err := json.Unmarshal(body, &record); err != nil {
+        return digestRecord{}, fmt.Errorf("reading %s: %v", newest.Pathname, err)
+    }
+    if record.Through.IsZero() {
+        record.Through = newest.UploadedAt
+    }
+    return record, nil
+}
+
+// newSubmissions loads the contact messages and investor inquiries uploaded
+// since the last digest's newest submission, oldest first, leaving out the
+// ones it included
+func newSubmissions(last digestRecord, token string) ([]digestItem, error) {
+    sent := make(map[string]bool, len(last.Items))
+    for _, pathname := range last.Items {
+        sent[pathname] = true
+    }
+
+    var items []digestItem
+    for _, prefix := range []string{"contacts/contact-", "investors/inquiry-"} {
+        blobs, err := listBlobs(prefix, token)
+        if err != nil {
+            return nil, err
+        }
+        for _, blob := range blobs {
+            if blob.UploadedAt.Before(last.Through) || sent[blob.Pathname] {
+                continue
+            }
+            body, err := fetchBlob(blob.URL, token)
+            if err != nil {
+                fmt.Printf("Skipping %s: %v\n", blob.Pathname, err)
+                continue
+            }
+
+            item := digestItem{Pathname: blob.Pathname, UploadedAt: blob.UploadedAt}
+            if prefix == "contacts/contact-" {
+                item.Contact = &ContactForm{}
+                err = json.Unmarshal(body, item.Contact)
+            } else {
+                item.Inquiry = &InvestorInquiry{}
+                err = json.Unmarshal(body, item.Inquiry)
+            }
+            if err != nil {
+                fmt.Printf("Skipping %s: %v\n", blob.Pathname, err)
+                continue
+            }
+            items = append(items, item)
+        }
+    }
+
+    sort.Slice(items, func(i, j int) bool {
+        return items[i].UploadedAt.Before(items[j].UploadedAt)
+    })
+    return items, nil
+}
+
+// buildDigest formats the digest email as plain text, investor inquiries
+// (best leads first) before contact messages
+func buildDigest(since time.Time, items []digestItem) (subject, body string) {
+    var inquiries, contacts []digestItem
+    for _, item := range items {
+        if item.Inquiry != nil {
+            inquiries = append(inquiries, item)
+        } else {
+            contacts = append(contacts, item)
+        }
+    }
+    sort.SliceStable(inquiries, func(i, j int) bool {
+        return inquiries[i].Inquiry.LeadScore() > inquiries[j].Inquiry.LeadScore()
+    })
+
+    title := loadSiteConfig().Title
+    subject = fmt.Sprintf("%s digest: %d investor inquiries, %d contact messages", title, len(inquiries), len(contacts))
+
+    var b strings.Builder
+    if since.IsZero() {
+        fmt.Fprintf(&b, "All submissions to date (first digest).\n")
+    } else {
+        fmt.Fprintf(&b, "New submissions since %s.\n", since.Format("2006-01-02 15:04 UTC"))
+    }
+
+    if len(inquiries) > 0 {
+        fmt.Fprintf(&b, "\nINVESTOR INQUIRIES (%d)\n", len(inquiries))
+        for _, item := range inquiries {
+            q := item.Inquiry
+            fmt.Fprintf(&b, "\n[%s lead, %d] %s <%s>, %s\n", strings.ToUpper(q.LeadLabel()), q.LeadScore(), q.Name, q.Email, q.FundName)
+            fmt.Fprintf(&b, "Check size: %s | Stage focus: %s | %s\n", q.CheckSize, q.StageFocus, item.UploadedAt.Format("2006-01-02 15:04 UTC"))
+            fmt.Fprintf(&b, "%s\n", q.Message)
+        }
+    }
+
+    if len(contacts) > 0 {
+        fmt.Fprintf(&b, "\nCONTACT MESSAGES (%d)\n", len(contacts))
+        for _, item := range contacts {
+            c := item.Contact
+            fmt.Fprintf(&b, "\n%s <%s>, %s\n", c.Name, c.Email, item.UploadedAt.Format("2006-01-02 15:04 UTC"))
+            fmt.Fprintf(&b, "%s\n", c.Message)
+        }
+    }
+
+    return subject, b.String()
+}
+
+// sendEmail sends a plain-text email through the Resend API (RESEND_API_KEY,
+// from DIGEST_FROM). to may list several comma-separated addresses.
+func sendEmail(to, subject, body string) error {
+    apiKey := os.Getenv("RESEND_API_KEY")
+    from := os.Getenv("DIGEST_FROM")
+    if apiKey == "" || from == "" || to == "" {
+        return fmt.Errorf("email not configured: set RESEND_API_KEY, DIGEST_FROM and DIGEST_TO")
+    }
+
+    var recipients []string
+    for _, addr := range strings.Split(to, ",") {
+        if addr = strings.TrimSpace(addr); addr != "" {
+            recipients = append(recipients, addr)
+        }
+    }
+
+    payload, err := json.Marshal(map[string]interface{}{
+        "from":    from,
+        "to":      recipients,
+        "subject": subject,
+        "text":    body,
+    })
+    if err != nil {
+        return err
+    }
+
+    req, err := http.NewRequest("POST", "https://api.resend.com/emails", bytes.NewBuffer(payload))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", "Bearer "+apiKey)
+    req.Header.Set("Content-Type", "application/json")
 
     client := &http.Client{Timeout: 10 * time.Second}
     resp, err := client.Do(req)
     if err != nil {
-        return nil, err
+        return err
     }
     defer resp.Body.Close()
 
-    if resp.StatusCode != 200 {
-        return nil, fmt.Errorf("failed to list files: %d", resp.StatusCode)
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        return fmt.Errorf("email API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
     }
+    return nil
+}
 
-    // Parse response to get file URLs
-    body, err := io.ReadAll(resp.Body)
+// Site config cache: serverless instances are reused between requests, so
+// the stored config is only re-read from blob storage once a minute
+var siteConfigCache struct {
+    sync.Mutex
+    config  SiteConfig
+    fetched time.Time
+}
+
+const siteConfigTTL = time.Minute
+
+// loadSiteConfig returns the saved site config, or the default branding when
+// none was saved (or blob storage isn't configured)
+func loadSiteConfig() SiteConfig {
+    siteConfigCache.Lock()
+    defer siteConfigCache.Unlock()
+
+    if !siteConfigCache.fetched.IsZero() && time.Since(siteConfigCache.fetched) < siteConfigTTL {
+        return siteConfigCache.config
+    }
+
+    config := defaultSiteConfig()
+    if stored, err := fetchStoredSiteConfig(); err == nil {
+        config = stored
+    } else if os.Getenv("BLOB_READ_WRITE_TOKEN") != "" {
+        fmt.Printf("Using default site config: %v\n", err)
+    }
+
+    siteConfigCache.config = config
+    siteConfigCache.fetched = time.Now()
+    return config
+}
+
+func defaultSiteConfig() SiteConfig {
+    var config SiteConfig
+    if err := json.Unmarshal([]byte(defaultSiteConfigJSON), &config); err != nil {
+        panic("bad default site config: " + err.Error())
+    }
+    return config
+}
+
+// fetchStoredSiteConfig reads the most recently saved config from blob storage.
+// Each save is a new site/config-<unix>.json file, which keeps earlier versions.
+func fetchStoredSiteConfig() (SiteConfig, error) {
+    blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
+    if blobToken == "" {
+        return SiteConfig{}, fmt.Errorf("BLOB_READ_WRITE_TOKEN not configured")
+    }
+
+    blobs, err := listBlobs("site/config-", blobToken)
     if err != nil {
-        return nil, err
+        return SiteConfig{}, err
+    }
+    if len(blobs) == 0 {
+        return SiteConfig{}, fmt.Errorf("no saved site config")
     }
 
-    var listResp struct {
-        Blobs []struct {
-            URL string `json:"url"`
-        } `json:"blobs"`
+    latest := blobs[0]
+    for _, blob := range blobs[1:] {
+        if blob.UploadedAt.After(latest.UploadedAt) {
+            latest = blob
+        }
     }
 
-    if err := json.Unmarshal(body, &listResp); err != nil {
+    body, err := fetchBlob(latest.URL, blobToken)
+    if err != nil {
+        return SiteConfig{}, err
+    }
+
+    // Start from the defaults so fields missing from an older config keep working
+    config := defaultSiteConfig()
+    if err := json.Unmarshal(body, &config); err != nil {
+        return SiteConfig{}, err
+    }
+    return config, nil
+}
+
+func saveSiteConfig(config SiteConfig) error {
+    blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
+    if blobToken == "" {
+        return fmt.Errorf("Blob credentials not configured")
+    }
+
+    data, err := json.Marshal(config)
+    if err != nil {
+        return err
+    }
+    if err := putBlob(fmt.Sprintf("site/config-%d.json", time.Now().Unix()), data, blobToken); err != nil {
+        return err
+    }
+
+    siteConfigCache.Lock()
+    siteConfigCache.config = config
+    siteConfigCache.fetched = time.Now()
+    siteConfigCache.Unlock()
+    return nil
+}
+
+func getSubmissions() ([]ContactForm, error) {
+    blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
+    if blobToken == "" {
+        return nil, fmt.Errorf("BLOB_READ_WRITE_TOKEN not configured")
+    }
+
+    // List files in contacts folder
+    blobs, err := listBlobs("contacts/", blobToken)
+    if err != nil {
         return nil, err
     }
 
     // Fetch each file and parse
     var submissions []ContactForm
-    for _, blob := range listResp.Blobs {
+    for _, blob := range blobs {
         if strings.Contains(blob.URL, "contact-") {
             submission, err := fetchSubmission(blob.URL, blobToken)
             if err == nil {
@@ -195,22 +956,48 @@ func getSubmissions() ([]ContactForm, error) {
     return submissions, nil
 }
 
-func fetchSubmission(url, token string) (ContactForm, error) {
-    req, err := http.NewRequest("GET", url, nil)
+// getInvestorInquiries loads every stored investor inquiry
+func getInvestorInquiries() ([]InvestorInquiry, error) {
+    blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
+    if blobToken == "" {
+        return nil, fmt.Errorf("BLOB_READ_WRITE_TOKEN not configured")
+    }
+
+    blobs, err := listBlobs("investors/", blobToken)
     if err != nil {
-        return ContactForm{}, err
+        return nil, err
     }
 
-    req.Header.Set("Authorization", "Bearer "+token)
+    var inquiries []InvestorInquiry
+    for _, blob := range blobs {
+        body, err := fetchBlob(blob.URL, blobToken)
+        if err != nil {
+            continue
+        }
+        var inquiry InvestorInquiry
+        if err := json.Unmarshal(body, &inquiry); err == nil {
+            inquiries = append(inquiries, inquiry)
+        }
+    }
+    return inquiries, nil
+}
 
-    client := &http.Client{Timeout: 10 * time.Second}
-    resp, err := client.Do(req)
+// saveInvestorInquiry stores an inquiry under investors/, apart from contact messages
+func saveInvestorInquiry(inquiry InvestorInquiry) error {
+    blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
+    if blobToken == "" {
+        return fmt.Errorf("Blob credentials not configured")
+    }
+
+    data, err := json.Marshal(inquiry)
     if err != nil {
-        return ContactForm{}, err
+        return err
     }
-    defer resp.Body.Close()
+    return putBlob(fmt.Sprintf("investors/inquiry-%d.json", inquiry.SubmittedAt.UnixNano()), data, blobToken)
+}
 
-    body, err := io.ReadAll(resp.Body)
+func fetchSubmission(url, token string) (ContactForm, error) {
+    body, err := fetchBlob(url, token)
     if err != nil {
         return ContactForm{}, err
     }
@@ -239,15 +1026,114 @@ func saveToBlob(form ContactForm) error {
         return err
     }
 
-    // Prepare blob upload request
-    url := fmt.Sprintf("https://blob.vercel-storage.com/%s", filename)
+    return putBlob(filename, formData, blobToken)
+}
+
+// blobInfo is one entry from the Vercel Blob list API
+type blobInfo struct {
+    URL        string    `json:"url"`
+    Pathname   string    `json:"pathname"`
+    UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// listBlobs lists the stored files whose path starts with prefix, following
+// the listing's cursor until every page has been read
+func listBlobs(prefix, token string) ([]blobInfo, error) {
+    var blobs []blobInfo
+    cursor := ""
+    for {
+        page, next, err := listBlobPage(prefix, cursor, token)
+        if err != nil {
+            return nil, err
+        }
+        blobs = append(blobs, page...)
+        if next == "" {
+            return blobs, nil
+        }
+        cursor = next
+    }
+}
+
+// listBlobPage lists one page of files, starting at cursor ("" for the
+// first), and returns the cursor of the next page, or "" after the last
+func listBlobPage(prefix, cursor, token string) ([]blobInfo, string, error) {
+    query := url.Values{"prefix": {prefix}}
+    if cursor != "" {
+        query.Set("cursor", cursor)
+    }
+    req, err := http.NewRequest("GET", "https://blob.vercel-storage.com/list?"+query.Encode(), nil)
+    if err != nil {
+        return nil, "", err
+    }
+
+    req.Header.Set("Authorization", "Bearer "+token)
+
+    client := &http.Client{Timeout: 10 * time.Second}
+    resp, err := client.Do(req)
+    if err != nil {
+        return nil, "", err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != 200 {
+        return nil, "", fmt.Errorf("failed to list files: %d", resp.StatusCode)
+    }
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, "", err
+    }
+
+    var listResp struct {
+        Blobs   []blobInfo `json:"blobs"`
+        Cursor  string     `json:"cursor"`
+        HasMore bool       `json:"hasMore"`
+    }
+    if err := json.Unmarshal(body, &listResp); err != nil {
+        return nil, "", err
+    }
+    if !listResp.HasMore {
+        return listResp.Blobs, "", nil
+    }
+    if listResp.Cursor == "" {
+        return nil, "", fmt.Errorf("failed to list files: more pages but no cursor")
+    }
+    return listResp.Blobs, listResp.Cursor, nil
+}
+
+// fetchBlob downloads a stored file
+func fetchBlob(url, token string) ([]byte, error) {
+    req, err := http.NewRequest("GET", url, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    req.Header.Set("Authorization", "Bearer "+token)
+
+    client := &http.Client{Timeout: 10 * time.Second}
+    resp, err := client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != 200 {
+        return nil, fmt.Errorf("failed to fetch %s: %d", url, resp.StatusCode)
+    }
+
+    return io.ReadAll(resp.Body)
+}
+
+// putBlob uploads a JSON file to blob storage at the given path
+func putBlob(path string, data []byte, token string) error {
+    url := fmt.Sprintf("https://blob.vercel-storage.com/%s", path)
 
-    req, err := http.NewRequest("PUT", url, bytes.NewBuffer(formData))
+    req, err := http.NewRequest("PUT", url, bytes.NewBuffer(data))
     if err != nil {
         return err
     }
 
-    req.Header.Set("Authorization", "Bearer "+blobToken)
+    req.Header.Set("Authorization", "Bearer "+token)
     req.Header.Set("Content-Type", "application/json")
 
     client := &http.Client{Timeout: 10 * time.Second}
@@ -269,7 +1155,7 @@ const indexHTML = `<!DOCTYPE html>
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Brainreader AI - Understand and Control Neural Syntax</title>
+    <title>{{.Title}} - {{.Tagline}}</title>
     <style>
         * {
             margin: 0;
@@ -279,10 +1165,10 @@ const indexHTML = `<!DOCTYPE html>
 
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: #0a0a0a url('/chalkboard.png') center center;
+            background: {{.Colors.Background}}{{if .BackgroundImage}} url('{{.BackgroundImage}}') center center{{end}};
             background-size: cover;
             background-attachment: fixed;
-            color: #fff;
+            color: {{.Colors.Text}};
             min-height: 100vh;
             display: flex;
             flex-direction: column;
@@ -309,7 +1195,7 @@ const indexHTML = `<!DOCTYPE html>
 
         .tagline {
             font-size: 1.5rem;
-            color: #888;
+            color: {{.Colors.Muted}};
             margin-bottom: 3rem;
         }
 
@@ -383,13 +1269,9 @@ const indexHTML = `<!DOCTYPE html>
             transform: scale(1.05);
         }
 
-        .partner.yc {
-            background: #ff6600;
-            color: #fff;
-        }
-
-        .partner.nvidia {
-            background: #76b900;
+        .partner img {
+            height: 1.5rem;
+            display: block;
         }
 
         .contact-section {
@@ -423,7 +1305,7 @@ const indexHTML = `<!DOCTYPE html>
 
         .contact-form button {
             padding: 0.75rem 2rem;
-            background: linear-gradient(45deg, #6a0dad, #4b0082);
+            background: linear-gradient(45deg, {{.Colors.Accent}}, {{.Colors.AccentDark}});
             border: none;
             border-radius: 0.5rem;
             color: #fff;
@@ -458,8 +1340,8 @@ const indexHTML = `<!DOCTYPE html>
 </head>
 <body>
     <div class="container">
-        <h1>BRAINREADER AI</h1>
-        <p class="tagline">Understand and Control Neural Syntax</p>
+        <h1>{{upper .Title}}</h1>
+        <p class="tagline">{{.Tagline}}</p>
 
         <div class="links">
             <a href="/founders" class="link">Founders</a>
@@ -467,27 +1349,25 @@ const indexHTML = `<!DOCTYPE html>
             <a href="/investors" class="link">Investors</a>
         </div>
 
+        {{if .Social}}
         <div class="social">
-            <a href="https://twitter.com" target="_blank">
-                <svg width="20" height="20" fill="currentColor" viewBox="0 0 24 24">
-                    <path d="M23 3a10.9 10.9 0 01-3.14 1.53 4.48 4.48 0 00-7.86 3v1A10.66 10.66 0 013 4s-4 9 5 13a11.64 11.64 0 01-7 2c9 5 20 0 20-11.5a4.5 4.5 0 00-.08-.83A7.72 7.72 0 0023 3z"/>
-                </svg>
-            </a>
-            <a href="https://linkedin.com" target="_blank">
-                <svg width="20" height="20" fill="currentColor" viewBox="0 0 24 24">
-                    <path d="M16 8a6 6 0 016 6v7h-4v-7a2 2 0 00-2-2 2 2 0 00-2 2v7h-4v-7a6 6 0 016-6zM2 9h4v12H2z"/>
-                    <circle cx="4" cy="4" r="2"/>
-                </svg>
-            </a>
+            {{range .Social}}
+            <a href="{{.URL}}" target="_blank" title="{{.Network}}">{{icon .Network}}</a>
+            {{end}}
         </div>
+        {{end}}
 
+        {{if .Partners}}
         <div class="partners">
-            <a href="https://www.ycombinator.com" target="_blank" class="partner yc">Y Combinator</a>
-            <a href="https://www.nvidia.com" target="_blank" class="partner nvidia">NVIDIA Inception</a>
+            {{range .Partners}}
+            <a href="{{.URL}}" target="_blank" class="partner" style="background: {{.Background}}; color: {{.TextColor}};">{{if .Logo}}<img src="{{.Logo}}" alt="{{.Name}}">{{else}}{{.Name}}{{end}}</a>
+            {{end}}
         </div>
+        {{end}}
 
         <div class="contact-section">
             <h2 style="margin-bottom: 1.5rem;">Get in Touch</h2>
+            <p class="tagline" style="font-size: 1rem; margin-bottom: 1.5rem;">Investor? Please use our <a href="/investors#inquiry" style="color: inherit;">investor inquiry form</a>.</p>
             <form class="contact-form" id="contactForm">
                 <input type="text" name="name" placeholder="Your Name" required>
                 <input type="email" name="email" placeholder="Your Email" required>
@@ -544,7 +1424,7 @@ const foundersHTML = `<!DOCTYPE html>
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Founders - Brainreader AI</title>
+    <title>Founders - {{.Title}}</title>
     <style>
         * {
             margin: 0;
@@ -554,8 +1434,8 @@ const foundersHTML = `<!DOCTYPE html>
 
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: #0a0a0a;
-            color: #fff;
+            background: {{.Colors.Background}};
+            color: {{.Colors.Text}};
             min-height: 100vh;
             padding: 2rem;
         }
@@ -661,7 +1541,7 @@ const foundersHTML = `<!DOCTYPE html>
                 <div class="founder-name">Your Name Here</div>
                 <div class="founder-title">Co-Founder & CEO</div>
                 <div class="founder-bio">
-                    Add your bio here. Describe your background, expertise, and vision for Brainreader AI.
+                    Add your bio here. Describe your background, expertise, and vision for {{.Title}}.
                 </div>
             </div>
 
@@ -679,7 +1559,7 @@ const advisorsHTML = `<!DOCTYPE html>
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Advisors - Brainreader AI</title>
+    <title>Advisors - {{.Title}}</title>
     <style>
         * {
             margin: 0;
@@ -689,8 +1569,8 @@ const advisorsHTML = `<!DOCTYPE html>
 
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: #0a0a0a;
-            color: #fff;
+            background: {{.Colors.Background}};
+            color: {{.Colors.Text}};
             min-height: 100vh;
             padding: 2rem;
         }
@@ -796,7 +1676,7 @@ const advisorsHTML = `<!DOCTYPE html>
                 <div class="advisor-name">Advisor Name</div>
                 <div class="advisor-title">Former CTO at Tech Company</div>
                 <div class="advisor-bio">
-                    Add advisor bio here. Include their experience, achievements, and how they help guide Brainreader AI.
+                    Add advisor bio here. Include their experience, achievements, and how they help guide {{.Title}}.
                 </div>
             </div>
 
@@ -814,7 +1694,7 @@ const investorsHTML = `<!DOCTYPE html>
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Investors - Brainreader AI</title>
+    <title>Investors - {{.Title}}</title>
     <style>
         * {
             margin: 0;
@@ -824,8 +1704,8 @@ const investorsHTML = `<!DOCTYPE html>
 
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: #0a0a0a;
-            color: #fff;
+            background: {{.Colors.Background}};
+            color: {{.Colors.Text}};
             min-height: 100vh;
             padding: 2rem;
         }
@@ -915,6 +1795,119 @@ const investorsHTML = `<!DOCTYPE html>
             color: #ff9800;
             font-size: 1.2rem;
         }
+
+        .inquiry {
+            max-width: 560px;
+            margin: 0 auto 3rem;
+            padding: 2rem;
+            background: rgba(255, 255, 255, 0.05);
+            border: 1px solid rgba(255, 255, 255, 0.2);
+            border-radius: 1rem;
+        }
+
+        .inquiry h2 {
+            font-weight: 300;
+            margin-bottom: 0.5rem;
+        }
+
+        .steps {
+            display: flex;
+            gap: 0.5rem;
+            margin-bottom: 1.5rem;
+        }
+
+        .steps span {
+            flex: 1;
+            height: 4px;
+            border-radius: 2px;
+            background: #333;
+        }
+
+        .steps span.done {
+            background: #ff9800;
+        }
+
+        .step {
+            display: none;
+            flex-direction: column;
+            gap: 1rem;
+        }
+
+        .step.active {
+            display: flex;
+        }
+
+        .step label {
+            display: flex;
+            flex-direction: column;
+            gap: 0.35rem;
+            color: #aaa;
+            font-size: 0.9rem;
+        }
+
+        .step input,
+        .step select,
+        .step textarea {
+            padding: 0.75rem;
+            background: rgba(255, 255, 255, 0.1);
+            border: 1px solid #444;
+            border-radius: 0.5rem;
+            color: #fff;
+            font-family: inherit;
+            font-size: 1rem;
+        }
+
+        .step select option {
+            color: #000;
+        }
+
+        .field-error {
+            color: #f44336;
+            font-size: 0.85rem;
+            min-height: 1em;
+        }
+
+        .step-buttons {
+            display: flex;
+            justify-content: space-between;
+            margin-top: 0.5rem;
+        }
+
+        .step-buttons button {
+            padding: 0.75rem 2rem;
+            border: none;
+            border-radius: 0.5rem;
+            color: #fff;
+            cursor: pointer;
+            font-size: 1rem;
+            background: linear-gradient(45deg, {{.Colors.Accent}}, {{.Colors.AccentDark}});
+        }
+
+        .step-buttons button.back {
+            background: transparent;
+            border: 1px solid #444;
+        }
+
+        .inquiry-message {
+            margin-top: 1rem;
+            padding: 0.75rem;
+            border-radius: 0.5rem;
+            display: none;
+        }
+
+        .inquiry-message.success {
+            display: block;
+            background: rgba(76, 175, 80, 0.2);
+            border: 1px solid #4caf50;
+            color: #4caf50;
+        }
+
+        .inquiry-message.error {
+            display: block;
+            background: rgba(244, 67, 54, 0.2);
+            border: 1px solid #f44336;
+            color: #f44336;
+        }
     </style>
 </head>
 <body>
@@ -931,7 +1924,7 @@ const investorsHTML = `<!DOCTYPE html>
                 <div class="investor-name">Investor Name</div>
                 <div class="investor-title">Partner at VC Fund</div>
                 <div class="investor-bio">
-                    Add investor bio here. Include their fund, investment focus, and belief in Brainreader AI's mission.
+                    Add investor bio here. Include their fund, investment focus, and belief in {{.Title}}'s mission.
                 </div>
             </div>
 
@@ -940,6 +1933,174 @@ const investorsHTML = `<!DOCTYPE html>
                 <div class="add-investor-text">+ Add Investor</div>
             </div>
         </div>
+
+        <!-- Investor inquiry: three steps, validated again on the server -->
+        <div class="inquiry" id="inquiry">
+            <h2>Investor Inquiry</h2>
+            <p style="color: #888; margin-bottom: 1.5rem;">Interested in backing {{.Title}}? Tell us about your fund.</p>
+            <div class="steps"><span class="done"></span><span></span><span></span></div>
+
+            <form id="inquiryForm" novalidate>
+                <div class="step active" data-step="0">
+                    <label>Fund or firm name
+                        <input type="text" name="fund_name" required>
+                        <span class="field-error" data-for="fund_name"></span>
+                    </label>
+                    <label>Your name
+                        <input type="text" name="name" required>
+                        <span class="field-error" data-for="name"></span>
+                    </label>
+                    <label>Email
+                        <input type="email" name="email" required>
+                        <span class="field-error" data-for="email"></span>
+                    </label>
+                    <div class="step-buttons"><span></span><button type="button" data-next>Next</button></div>
+                </div>
+
+                <div class="step" data-step="1">
+                    <label>Typical check size
+                        <select name="check_size" required>
+                            <option value="">Choose...</option>
+                            <option value="under-100k">Under $100k</option>
+                            <option value="100k-500k">$100k - $500k</option>
+                            <option value="500k-2m">$500k - $2M</option>
+                            <option value="2m-plus">$2M+</option>
+                        </select>
+                        <span class="field-error" data-for="check_size"></span>
+                    </label>
+                    <label>Stage focus
+                        <select name="stage_focus" required>
+                            <option value="">Choose...</option>
+                            <option value="pre-seed">Pre-seed</option>
+                            <option value="seed">Seed</option>
+                            <option value="series-a">Series A</option>
+                            <option value="series-b-plus">Series B+</option>
+                        </select>
+                        <span class="field-error" data-for="stage_focus"></span>
+                    </label>
+                    <div class="step-buttons"><button type="button" class="back" data-back>Back</button><button type="button" data-next>Next</button></div>
+                </div>
+
+                <div class="step" data-step="2">
+                    <label>Message
+                        <textarea name="message" rows="5" minlength="20" maxlength="2000" placeholder="Your thesis, what caught your interest, and next steps you'd suggest" required></textarea>
+                        <span class="field-error" data-for="message"></span>
+                    </label>
+                    <div class="step-buttons"><button type="button" class="back" data-back>Back</button><button type="submit">Send Inquiry</button></div>
+                </div>
+            </form>
+            <div id="inquiryMessage" class="inquiry-message"></div>
+        </div>
     </div>
+
+    <script>
+        (function () {
+            const form = document.getElementById('inquiryForm');
+            const steps = form.querySelectorAll('.step');
+            const bars = document.querySelectorAll('.steps span');
+            const messageDiv = document.getElementById('inquiryMessage');
+            let current = 0;
+
+            function show(index) {
+                steps.forEach((step, i) => step.classList.toggle('active', i === index));
+                bars.forEach((bar, i) => bar.classList.toggle('done', i <= index));
+                current = index;
+            }
+
+            function setErrors(errors) {
+                form.querySelectorAll('.field-error').forEach(el => {
+                    el.textContent = errors[el.dataset.for] || '';
+                });
+            }
+
+            // Quick browser-side check of the current step before moving on
+            function stepValid(index) {
+                const errors = {};
+                steps[index].querySelectorAll('input, select, textarea').forEach(field => {
+                    if (!field.checkValidity()) {
+                        errors[field.name] = field.validationMessage;
+                    }
+                });
+                setErrors(errors);
+                return Object.keys(errors).length === 0;
+            }
+
+            form.querySelectorAll('[data-next]').forEach(btn => btn.addEventListener('click', () => {
+                if (stepValid(current)) show(current + 1);
+            }));
+            form.querySelectorAll('[data-back]').forEach(btn => btn.addEventListener('click', () => show(current - 1)));
+
+            form.addEventListener('submit', async (e) => {
+                e.preventDefault();
+                if (!stepValid(current)) return;
+
+                const data = Object.fromEntries(new FormData(form));
+                messageDiv.className = 'inquiry-message';
+
+                try {
+                    const response = await fetch('/api/investor-inquiry', {
+                        method: 'POST',
+                        headers: { 'Content-Type': 'application/json' },
+                        body: JSON.stringify(data),
+                    });
+                    const result = await response.json();
+
+                    if (response.ok) {
+                        messageDiv.textContent = result.message;
+                        messageDiv.className = 'inquiry-message success';
+                        form.reset();
+                        setErrors({});
+                        show(0);
+                    } else if (result.errors) {
+                        // Server-side validation: jump back to the first step with a problem
+                        setErrors(result.errors);
+                        for (let i = 0; i < steps.length; i++) {
+                            const names = Array.from(steps[i].querySelectorAll('[name]')).map(f => f.name);
+                            if (names.some(name => result.errors[name])) { show(i); break; }
+                        }
+                    } else {
+                        throw new Error('Failed to send inquiry');
+                    }
+                } catch (error) {
+                    messageDiv.textContent = 'Error sending inquiry. Please try again.';
+                    messageDiv.className = 'inquiry-message error';
+                }
+            });
+        })();
+    </script>
 </body>
-</html>`
\ No newline at end of file
+</html>`
+
+const siteAdminHTML = `<!DOCTYPE html>
+<html>
+<head>
+    <title>Admin - Site Config</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; background: #f5f5f5; }
+        .container { max-width: 800px; margin: 0 auto; background: white; padding: 30px; border-radius: 10px; }
+        h1 { color: #333; }
+        textarea { width: 100%; height: 480px; font-family: monospace; font-size: 13px; padding: 10px; box-sizing: border-box; }
+        button { background: #1976d2; color: white; padding: 10px 24px; border: none; border-radius: 5px; cursor: pointer; margin-top: 10px; }
+        .ok { background: #e8f5e9; border: 1px solid #4caf50; padding: 10px; border-radius: 5px; }
+        .error { background: #ffebee; border: 1px solid #f44336; padding: 10px; border-radius: 5px; }
+        .help { color: #666; font-size: 14px; }
+        .back-link { color: #1976d2; text-decoration: none; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <a href="/admin" class="back-link">← Back to Submissions</a>
+        <h1>Site Config</h1>
+        {{if .Message}}<p class="ok">{{.Message}} <a href="/" target="_blank">View site</a></p>{{end}}
+        {{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+        <p class="help">
+            Title, tagline, colors (hex, e.g. #0a0a0a), partner badges (optional logo image URL)
+            and social links (twitter, linkedin, github, instagram, youtube).
+        </p>
+        <form method="post">
+            <textarea name="config" spellcheck="false">{{.ConfigJSON}}</textarea>
+            <button type="submit">Save</button>
+        </form>
+    </div>
+</body>
+</html>`
@@ -23,6 +23,7 @@ package main
 
 import (
 	"bytes"
+	_ "embed" // Lets us bundle demo_responses.json into the binary
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -56,6 +57,12 @@ type Player struct {
 	AIValue      string  `json:"ai_value,omitempty"`      // AI's estimated market value
 	AIAnalysis   string  `json:"ai_analysis,omitempty"`   // AI's reasoning and analysis
 	FantasyScore float64 `json:"fantasy_score,omitempty"` // Fantasy football potential (0-100)
+
+	// What each valuation pipeline stage did to this player (see VALUATION PIPELINE)
+	Pipeline []StageRecord `json:"pipeline,omitempty"`
+
+	// True when the valuation came from a canned demo response, not OpenAI
+	Demo bool `json:"demo,omitempty"`
 }
 
 // OpenAI API request structure
@@ -108,6 +115,11 @@ func main() {
 	http.HandleFunc("/results", resultsHandler) // Shows analysis results with charts
 	http.HandleFunc("/static/", staticHandler)  // Serves static files (if any)
 
+	if demoMode() {
+		fmt.Println("DEMO MODE: valuations are canned responses, no OpenAI calls are made")
+		fmt.Println("Set OPENAI_API_KEY (and leave DEMO_MODE unset) for live AI analysis")
+	}
+
 	// Start the web server
 	// Using port 3001 to avoid conflicts with other common development servers
 	fmt.Println("Server starting on :3001")
@@ -206,37 +218,162 @@ func parseCSVData(csvData string) ([]Player, error) {
 	return parsedPlayers, nil
 }
 
-// analyzePlayerWithAI sends player data to OpenAI for market valuation analysis
-// This is the core AI integration that evaluates player worth beyond simple stats
+// analyzePlayerWithAI values a player by running them through the valuation
+// pipeline (see VALUATION PIPELINE below). The stages used come from the
+// VALUATION_STAGES setting, defaulting to prompt → llm → parse → boost → postprocess.
 func analyzePlayerWithAI(player Player) (Player, error) {
-	// API Key Management - Multiple sources for flexibility
-	// Priority: .env file -> environment variable
-	// This allows both local development and production deployment
+	stages, err := buildPipeline(envValue("VALUATION_STAGES"))
+	if err != nil {
+		return player, err
+	}
+	return runPipeline(stages, player)
+}
+
+// envValue looks a setting up in the .env file first, then the environment
+// Priority: .env file -> environment variable
+// This allows both local development and production deployment
+func envValue(key string) string {
 	envData, err := os.ReadFile(".env")
-	apiKey := ""
 	if err == nil {
 		lines := strings.Split(string(envData), "\n")
 		for _, line := range lines {
-			if strings.HasPrefix(line, "OPENAI_API_KEY=") {
-				apiKey = strings.TrimPrefix(line, "OPENAI_API_KEY=")
-				apiKey = strings.TrimSpace(apiKey)
+			if strings.HasPrefix(line, key+"=") {
+				if value := strings.TrimSpace(strings.TrimPrefix(line, key+"=")); value != "" {
+					return value
+				}
 				break
 			}
 		}
 	}
 
 	// Fallback to system environment variable (useful for production)
-	if apiKey == "" {
-		apiKey = os.Getenv("OPENAI_API_KEY")
+	return os.Getenv(key)
+}
+
+// ============================================================
+// VALUATION PIPELINE
+// ============================================================
+//
+// A player's valuation is built up by a chain of stages, each doing one job:
+//
+//   prompt      -> writes the AI prompt from the player's stats
+//   llm         -> sends the prompt to OpenAI and keeps the raw reply
+//   demo        -> stands in for llm in demo mode (see DEMO MODE below)
+//   parse       -> turns the reply into AIValue / AIAnalysis / FantasyScore
+//   boost       -> our goals-per-match multiplier (x1.5 or x2.0)
+//   value_cap   -> optional sanity check: caps the AI value at N x Transfermarkt
+//   postprocess -> tidies the value format and clamps the fantasy score
+//
+// Choose and order stages with VALUATION_STAGES (in .env or the environment):
+//
+//   VALUATION_STAGES=prompt,llm,parse,boost,value_cap,postprocess
+//
+// To add your own stage (e.g. a wage-to-value check), implement ValuationStage
+// and add a constructor to stageRegistry under a new name, then list that name
+// in VALUATION_STAGES. Every stage's effect is recorded in Player.Pipeline and
+// shown on the results page, so you can see exactly where a number came from.
+
+// defaultStages is used when VALUATION_STAGES is not set
+const defaultStages = "prompt,llm,parse,boost,postprocess"
+
+// ValuationStage is one step of the valuation pipeline
+type ValuationStage interface {
+	Name() string             // Name used in VALUATION_STAGES and in the per-player record
+	Apply(v *Valuation) error // Does the stage's work; an error stops the pipeline
+}
+
+// Valuation is the working state handed from stage to stage
+type Valuation struct {
+	Player   Player // The player; AI fields are filled in as the stages run
+	Prompt   string // Set by the prompt stage
+	Response string // Raw AI reply, set by the llm stage
+	Parsed   bool   // True once the reply was valid JSON (later stages skip otherwise)
+	Note     string // A stage's explanation of what it did (recorded, then cleared)
+}
+
+// StageRecord documents one stage's contribution to a player's valuation
+type StageRecord struct {
+	Stage       string `json:"stage"`
+	ValueBefore string `json:"value_before,omitempty"` // AIValue before the stage ran
+	ValueAfter  string `json:"value_after,omitempty"`  // AIValue after the stage ran
+	Note        string `json:"note,omitempty"`
+}
+
+// stageRegistry maps stage names to constructors
+// Add your custom stages here
+var stageRegistry = map[string]func() ValuationStage{
+	"prompt":      func() ValuationStage { return promptStage{} },
+	"llm":         func() ValuationStage { return llmStage{} },
+	"demo":        func() ValuationStage { return demoStage{} },
+	"parse":       func() ValuationStage { return parseStage{} },
+	"boost":       func() ValuationStage { return boostStage{} },
+	"value_cap":   func() ValuationStage { return valueCapStage{} },
+	"postprocess": func() ValuationStage { return postProcessStage{} },
+}
+
+// buildPipeline turns a comma-separated list of stage names into stages
+func buildPipeline(spec string) ([]ValuationStage, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = defaultStages
 	}
 
-	if apiKey == "" {
-		return player, fmt.Errorf("OPENAI_API_KEY not set in .env file or environment")
+	var stages []ValuationStage
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "llm" && demoMode() {
+			name = "demo" // Same place in the pipeline, canned reply instead of an API call
+		}
+		newStage, ok := stageRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown valuation stage %q in VALUATION_STAGES", name)
+		}
+		stages = append(stages, newStage())
 	}
+	return stages, nil
+}
+
+// runPipeline passes a player through every stage in order, recording each
+// stage's effect on the player
+func runPipeline(stages []ValuationStage, player Player) (Player, error) {
+	v := &Valuation{Player: player}
+	v.Player.Pipeline = nil
+
+	for _, stage := range stages {
+		before := v.Player.AIValue
+		v.Note = ""
+
+		err := stage.Apply(v)
 
-	// Construct the AI prompt with comprehensive player analysis criteria
-	// This prompt engineering is crucial - it guides the AI's evaluation process
-	prompt := fmt.Sprintf(`Analyze this football player's real market value:
+		record := StageRecord{Stage: stage.Name(), ValueBefore: before, ValueAfter: v.Player.AIValue, Note: v.Note}
+		if err != nil {
+			record.Note = "error: " + err.Error()
+		}
+		v.Player.Pipeline = append(v.Player.Pipeline, record)
+
+		if err != nil {
+			return v.Player, fmt.Errorf("%s stage: %w", stage.Name(), err)
+		}
+	}
+	return v.Player, nil
+}
+
+// promptStage builds the AI prompt with comprehensive player analysis criteria
+// This prompt engineering is crucial - it guides the AI's evaluation process
+type promptStage struct{}
+
+func (promptStage) Name() string { return "prompt" }
+
+func (promptStage) Apply(v *Valuation) error {
+	player := v.Player
+	goalsPerMatch := 0.0
+	if player.Matches > 0 {
+		goalsPerMatch = float64(player.Goals) / float64(player.Matches)
+	}
+
+	v.Prompt = fmt.Sprintf(`Analyze this football player's real market value:
 
 Player: %s (%s)
 Position: %s
@@ -269,23 +406,41 @@ Format your response as JSON:
   "estimated_value": "€X.XXm",
   "analysis": "your analysis here",
   "fantasy_score": 85
-}`, player.DisplayName, player.Name, player.Position, player.Age, player.League, player.Club, player.Goals, player.Matches, float64(player.Goals)/float64(player.Matches), player.MarketValue)
+}`, player.DisplayName, player.Name, player.Position, player.Age, player.League, player.Club, player.Goals, player.Matches, goalsPerMatch, player.MarketValue)
+
+	v.Note = fmt.Sprintf("%d-character prompt", len(v.Prompt))
+	return nil
+}
+
+// llmStage sends the prompt to OpenAI and stores the raw reply
+type llmStage struct{}
+
+func (llmStage) Name() string { return "llm" }
+
+func (llmStage) Apply(v *Valuation) error {
+	apiKey := envValue("OPENAI_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("OPENAI_API_KEY not set in .env file or environment")
+	}
+	if v.Prompt == "" {
+		return fmt.Errorf("no prompt to send (is the prompt stage missing?)")
+	}
 
 	reqBody := OpenAIRequest{
 		Model: "gpt-3.5-turbo",
 		Messages: []Message{
-			{Role: "user", Content: prompt},
+			{Role: "user", Content: v.Prompt},
 		},
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return player, err
+		return err
 	}
 
 	req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return player, err
+		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -294,66 +449,288 @@ Format your response as JSON:
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return player, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return player, err
+		return err
 	}
 
 	var openAIResp OpenAIResponse
 	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return player, err
+		return err
 	}
 
 	if len(openAIResp.Choices) == 0 {
-		return player, fmt.Errorf("no response from OpenAI")
+		return fmt.Errorf("no response from OpenAI")
+	}
+
+	v.Response = openAIResp.Choices[0].Message.Content
+	v.Note = "gpt-3.5-turbo replied"
+	return nil
+}
+
+// ============================================================
+// DEMO MODE
+// ============================================================
+//
+// Demo mode lets you show the app off without an API key or network access:
+// the llm stage is swapped for the demo stage, which replies with a canned
+// valuation instead of calling OpenAI. Every other stage runs as normal, so
+// the pipeline, charts and results page behave exactly as they would live.
+//
+// It is switched on by DEMO_MODE=true, or automatically when no
+// OPENAI_API_KEY is configured. Demo results are labeled on every page.
+//
+// LEARNING NOTE: //go:embed copies a file into the compiled program, so the
+// canned responses travel with the binary and can't go missing at runtime.
+
+//go:embed demo_responses.json
+var demoResponsesJSON []byte
+
+// demoResponse is one canned reply, in the same JSON format we ask OpenAI for
+type demoResponse struct {
+	EstimatedValue string  `json:"estimated_value"`
+	Analysis       string  `json:"analysis"`
+	FantasyScore   float64 `json:"fantasy_score"`
+}
+
+// demoResponses holds the canned replies for the bundled dataset, keyed by player name
+var demoResponses = loadDemoResponses()
+
+func loadDemoResponses() map[string]demoResponse {
+	responses := map[string]demoResponse{}
+	if err := json.Unmarshal(demoResponsesJSON, &responses); err != nil {
+		log.Printf("Warning: could not read embedded demo responses: %v", err)
+	}
+	return responses
+}
+
+// demoMode reports whether canned responses should be used instead of OpenAI
+func demoMode() bool {
+	switch strings.ToLower(envValue("DEMO_MODE")) {
+	case "true", "1", "yes", "on":
+		return true
+	case "false", "0", "no", "off":
+		return false
 	}
+	return envValue("OPENAI_API_KEY") == "" // Not set: demo only when there's no key
+}
+
+// demoStage replies with a canned valuation instead of calling OpenAI
+type demoStage struct{}
+
+func (demoStage) Name() string { return "demo" }
 
-	// Parse the JSON response from AI
+func (demoStage) Apply(v *Valuation) error {
+	v.Player.Demo = true
+
+	reply, ok := demoResponses[v.Player.Name]
+	if ok {
+		v.Note = "canned demo response"
+	} else {
+		// Uploaded players aren't in the bundled file: make a simple
+		// stats-based estimate so the demo still works with your own data
+		reply = demoEstimate(v.Player)
+		v.Note = "demo estimate from stats (player not in canned responses)"
+	}
+	reply.Analysis = "[DEMO] " + reply.Analysis
+
+	response, err := json.Marshal(reply)
+	if err != nil {
+		return err
+	}
+	v.Response = string(response)
+	return nil
+}
+
+// demoEstimate makes a rough valuation from the player's stats
+// It rewards goals per match and prime age; it's a stand-in, not a real model
+func demoEstimate(player Player) demoResponse {
+	base := valueInThousands(player.MarketValue)
+	if base <= 0 {
+		base = 150 // No Transfermarkt value: assume a modest lower-league fee
+	}
+
+	goalsPerMatch := 0.0
+	if player.Matches > 0 {
+		goalsPerMatch = float64(player.Goals) / float64(player.Matches)
+	}
+
+	ageFactor := 1.0
+	switch {
+	case player.Age > 0 && player.Age <= 23:
+		ageFactor = 1.2
+	case player.Age >= 31:
+		ageFactor = 0.8
+	}
+
+	valueInK := base * (0.9 + 0.3*goalsPerMatch) * ageFactor
+	value := fmt.Sprintf("€%.0fk", valueInK)
+	if valueInK >= 1000 {
+		value = fmt.Sprintf("€%.2fm", valueInK/1000)
+	}
+
+	score := 40 + goalsPerMatch*30
+	if ageFactor > 1 {
+		score += 5
+	} else if ageFactor < 1 {
+		score -= 5
+	}
+
+	return demoResponse{
+		EstimatedValue: value,
+		Analysis: fmt.Sprintf("Estimated from stats only: %d goals in %d matches (%.2f per match) at age %d in the %s. No scouting-style analysis is available for uploaded players in demo mode.",
+			player.Goals, player.Matches, goalsPerMatch, player.Age, player.League),
+		FantasyScore: score,
+	}
+}
+
+// parseStage reads the AI's JSON reply into the player's AI fields
+type parseStage struct{}
+
+func (parseStage) Name() string { return "parse" }
+
+func (parseStage) Apply(v *Valuation) error {
 	var aiResult struct {
 		EstimatedValue string  `json:"estimated_value"`
 		Analysis       string  `json:"analysis"`
 		FantasyScore   float64 `json:"fantasy_score"`
 	}
 
-	content := openAIResp.Choices[0].Message.Content
-	if err := json.Unmarshal([]byte(content), &aiResult); err != nil {
+	if err := json.Unmarshal([]byte(v.Response), &aiResult); err != nil {
 		// If JSON parsing fails, use the raw content
-		player.AIValue = "Analysis failed"
-		player.AIAnalysis = content
-		player.FantasyScore = 50.0
+		v.Player.AIValue = "Analysis failed"
+		v.Player.AIAnalysis = v.Response
+		v.Player.FantasyScore = 50.0
+		v.Note = "reply was not valid JSON, raw text kept"
+		return nil
+	}
+
+	v.Player.AIValue = aiResult.EstimatedValue
+	v.Player.AIAnalysis = aiResult.Analysis
+	v.Player.FantasyScore = aiResult.FantasyScore
+	v.Parsed = true
+	v.Note = fmt.Sprintf("AI estimate %s, fantasy score %.0f", aiResult.EstimatedValue, aiResult.FantasyScore)
+	return nil
+}
+
+// boostStage is the CUSTOM VALUATION BOOST SYSTEM
+// This is our own proprietary scoring system that rewards high goal-scorers
+// Rationale: Goals per match is the most reliable predictor of striker value
+type boostStage struct{}
+
+func (boostStage) Name() string { return "boost" }
+
+func (boostStage) Apply(v *Valuation) error {
+	player := &v.Player
+	if !v.Parsed || player.Matches == 0 {
+		v.Note = "skipped"
+		return nil
+	}
+
+	goalsPerMatch := float64(player.Goals) / float64(player.Matches)
+
+	// HARDCODED THRESHOLDS (you can adjust these):
+	if goalsPerMatch > 0.9 {
+		// DOUBLE VALUE: >0.9 goals/match indicates exceptional talent
+		// Examples: Jonathan Júnior (10 goals in 6 matches = 1.67 goals/match)
+		// This catches potential superstars in smaller leagues
+		player.AIValue = adjustMarketValue(player.AIValue, 2.0)
+		player.AIAnalysis += fmt.Sprintf(" [BOOST: Exceptional striker with %.2f goals/match - value doubled!]", goalsPerMatch)
+		v.Note = fmt.Sprintf("%.2f goals/match: x2.0", goalsPerMatch)
+	} else if goalsPerMatch > 0.7 {
+		// 50% INCREASE: >0.7 goals/match shows strong consistent performance
+		// This rewards players who consistently find the net
+		player.AIValue = adjustMarketValue(player.AIValue, 1.5)
+		player.AIAnalysis += fmt.Sprintf(" [BOOST: Strong striker with %.2f goals/match - value increased 50%%]", goalsPerMatch)
+		v.Note = fmt.Sprintf("%.2f goals/match: x1.5", goalsPerMatch)
 	} else {
-		player.AIValue = aiResult.EstimatedValue
-		player.AIAnalysis = aiResult.Analysis
-		player.FantasyScore = aiResult.FantasyScore
-
-		// CUSTOM VALUATION BOOST SYSTEM
-		// This is our own proprietary scoring system that rewards high goal-scorers
-		// Rationale: Goals per match is the most reliable predictor of striker value
-		if player.Matches > 0 {
-			goalsPerMatch := float64(player.Goals) / float64(player.Matches)
-
-			// HARDCODED THRESHOLDS (you can adjust these):
-			if goalsPerMatch > 0.9 {
-				// DOUBLE VALUE: >0.9 goals/match indicates exceptional talent
-				// Examples: Jonathan Júnior (10 goals in 6 matches = 1.67 goals/match)
-				// This catches potential superstars in smaller leagues
-				player.AIValue = adjustMarketValue(player.AIValue, 2.0)
-				player.AIAnalysis += fmt.Sprintf(" [BOOST: Exceptional striker with %.2f goals/match - value doubled!]", goalsPerMatch)
-			} else if goalsPerMatch > 0.7 {
-				// 50% INCREASE: >0.7 goals/match shows strong consistent performance
-				// This rewards players who consistently find the net
-				player.AIValue = adjustMarketValue(player.AIValue, 1.5)
-				player.AIAnalysis += fmt.Sprintf(" [BOOST: Strong striker with %.2f goals/match - value increased 50%%]", goalsPerMatch)
-			}
-			// Players with ≤0.7 goals/match get no boost (AI analysis only)
+		// Players with ≤0.7 goals/match get no boost (AI analysis only)
+		v.Note = fmt.Sprintf("%.2f goals/match: no boost", goalsPerMatch)
+	}
+	return nil
+}
+
+// valueCapStage is an example sanity-check stage: it stops the AI (plus
+// boosts) from valuing a player at more than VALUE_CAP_MULTIPLIER times their
+// Transfermarkt value (default 10x). Not in the default pipeline - add
+// "value_cap" to VALUATION_STAGES to use it.
+type valueCapStage struct{}
+
+func (valueCapStage) Name() string { return "value_cap" }
+
+func (valueCapStage) Apply(v *Valuation) error {
+	if !v.Parsed {
+		v.Note = "skipped"
+		return nil
+	}
+
+	multiplier := 10.0
+	if raw := envValue("VALUE_CAP_MULTIPLIER"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid VALUE_CAP_MULTIPLIER %q", raw)
 		}
+		multiplier = parsed
+	}
+
+	marketK := valueInThousands(v.Player.MarketValue)
+	aiK := valueInThousands(v.Player.AIValue)
+	if marketK <= 0 || aiK <= marketK*multiplier {
+		v.Note = fmt.Sprintf("within %.0fx Transfermarkt value", multiplier)
+		return nil
+	}
+
+	v.Player.AIValue = adjustMarketValue(v.Player.MarketValue, multiplier)
+	v.Player.AIAnalysis += fmt.Sprintf(" [CAP: limited to %.0fx Transfermarkt value]", multiplier)
+	v.Note = fmt.Sprintf("capped at %.0fx Transfermarkt value", multiplier)
+	return nil
+}
+
+// postProcessStage tidies up the final numbers for display
+type postProcessStage struct{}
+
+func (postProcessStage) Name() string { return "postprocess" }
+
+func (postProcessStage) Apply(v *Valuation) error {
+	if !v.Parsed {
+		v.Note = "skipped"
+		return nil
+	}
+
+	var notes []string
+
+	// Normalize the value format: "€1.5m", "1500k" and "1500" all become "€1.50m"
+	if normalized := adjustMarketValue(v.Player.AIValue, 1.0); normalized != v.Player.AIValue {
+		v.Player.AIValue = normalized
+		notes = append(notes, "value reformatted")
+	}
+
+	// Fantasy scores must stay on the 0-100 scale
+	if v.Player.FantasyScore < 0 {
+		v.Player.FantasyScore = 0
+		notes = append(notes, "fantasy score raised to 0")
+	} else if v.Player.FantasyScore > 100 {
+		v.Player.FantasyScore = 100
+		notes = append(notes, "fantasy score capped at 100")
 	}
 
-	return player, nil
+	v.Note = strings.Join(notes, ", ")
+	return nil
+}
+
+// valueInThousands converts "€1.50m" / "€500k" to thousands of euros (1500 / 500)
+func valueInThousands(value string) float64 {
+	value = strings.TrimSpace(strings.Replace(value, "€", "", -1))
+	if strings.HasSuffix(value, "m") {
+		num, _ := strconv.ParseFloat(strings.TrimSuffix(value, "m"), 64)
+		return num * 1000
+	}
+	num, _ := strconv.ParseFloat(strings.TrimSuffix(value, "k"), 64)
+	return num
 }
 
 // adjustMarketValue applies multipliers to currency strings (€500k, €2.00m, etc.)
@@ -414,11 +791,15 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
         .value { font-weight: bold; color: #27ae60; }
         .nav { text-align: center; margin: 20px 0; }
         .nav a { margin: 0 10px; text-decoration: none; color: #3498db; }
+        .demo-banner { background: #fff3cd; border: 1px solid #f0c36d; color: #7a5b00; padding: 10px 15px; border-radius: 5px; text-align: center; }
     </style>
 </head>
 <body>
     <div class="container">
         <h1>Football Player Value Analyzer</h1>
+        {{if .Demo}}
+        <div class="demo-banner"><strong>DEMO MODE</strong>: analysis uses canned example valuations, not live OpenAI calls. Set OPENAI_API_KEY for real AI analysis.</div>
+        {{end}}
         <div class="nav">
             <a href="/">Home</a> |
             <a href="/results">Analysis Results</a>
@@ -545,8 +926,10 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 
 	data := struct {
 		Players []Player
+		Demo    bool
 	}{
 		Players: players,
+		Demo:    demoMode(),
 	}
 
 	err = t.Execute(w, data)
@@ -597,6 +980,7 @@ func analyzeHandler(w http.ResponseWriter, r *http.Request) {
 
 		// Analyze all players
 		maxAnalyze := len(players)
+		demo := demoMode()
 
 		// Initialize progress
 		analysisProgress.Current = 0
@@ -609,6 +993,9 @@ func analyzeHandler(w http.ResponseWriter, r *http.Request) {
 			analysisProgress.Current = i + 1
 			analysisProgress.PlayerName = players[i].DisplayName
 			analysisProgress.Status = fmt.Sprintf("Analyzing player %d of %d", i+1, maxAnalyze)
+			if demo {
+				analysisProgress.Status += " (demo mode)"
+			}
 
 			fmt.Printf("Analyzing player %d/%d: %s\n", i+1, maxAnalyze, players[i].DisplayName)
 			analyzed, err := analyzePlayerWithAI(players[i])
@@ -621,7 +1008,12 @@ func analyzeHandler(w http.ResponseWriter, r *http.Request) {
 			analysisResults = append(analysisResults, analyzed)
 
 			// Add delay to avoid rate limiting
-			time.Sleep(2 * time.Second)
+			// Demo mode makes no API calls, so only pause long enough to see the progress bar move
+			if demo {
+				time.Sleep(200 * time.Millisecond)
+			} else {
+				time.Sleep(2 * time.Second)
+			}
 		}
 
 		// Mark as done
@@ -661,11 +1053,18 @@ func resultsHandler(w http.ResponseWriter, r *http.Request) {
         .fantasy-score { text-align: center; font-size: 24px; font-weight: bold; color: #8e44ad; }
         .charts { display: grid; grid-template-columns: 1fr 1fr; gap: 20px; margin: 30px 0; }
         canvas { max-height: 400px; }
+        .pipeline { margin-top: 10px; font-size: 13px; }
+        .pipeline table { width: 100%; border-collapse: collapse; margin-top: 8px; }
+        .pipeline th, .pipeline td { text-align: left; padding: 4px; border-bottom: 1px solid #ddd; }
+        .demo-banner { background: #fff3cd; border: 1px solid #f0c36d; color: #7a5b00; padding: 10px 15px; border-radius: 5px; text-align: center; }
     </style>
 </head>
 <body>
     <div class="container">
         <h1>AI Analysis Results</h1>
+        {{if .Demo}}
+        <div class="demo-banner"><strong>DEMO RESULTS</strong>: these valuations are canned examples, not live AI analysis.</div>
+        {{end}}
         <div class="nav">
             <a href="/">Home</a> |
             <a href="/results">Analysis Results</a>
@@ -692,7 +1091,7 @@ func resultsHandler(w http.ResponseWriter, r *http.Request) {
                 <div class="value-comparison">
                     <div>
                         <div class="original-value">Transfermarkt: {{.MarketValue}}</div>
-                        <div class="ai-value">AI Estimate: {{.AIValue}}</div>
+                        <div class="ai-value">{{if .Demo}}Demo Estimate{{else}}AI Estimate{{end}}: {{.AIValue}}</div>
                     </div>
                 </div>
 
@@ -702,6 +1101,18 @@ func resultsHandler(w http.ResponseWriter, r *http.Request) {
                     <strong>AI Analysis:</strong><br>
                     {{.AIAnalysis}}
                 </div>
+
+                {{if .Pipeline}}
+                <details class="pipeline">
+                    <summary>How this value was built ({{len .Pipeline}} stages)</summary>
+                    <table>
+                        <tr><th>Stage</th><th>Before</th><th>After</th><th>Note</th></tr>
+                        {{range .Pipeline}}
+                        <tr><td>{{.Stage}}</td><td>{{.ValueBefore}}</td><td>{{.ValueAfter}}</td><td>{{.Note}}</td></tr>
+                        {{end}}
+                    </table>
+                </details>
+                {{end}}
             </div>
             {{end}}
         </div>
@@ -777,12 +1188,23 @@ func resultsHandler(w http.ResponseWriter, r *http.Request) {
 
 	resultsJSON, _ := json.Marshal(analysisResults)
 
+	// Label the page as a demo if any result came from a canned response
+	demo := false
+	for _, p := range analysisResults {
+		if p.Demo {
+			demo = true
+			break
+		}
+	}
+
 	data := struct {
 		Results     []Player
 		ResultsJSON template.JS
+		Demo        bool
 	}{
 		Results:     analysisResults,
 		ResultsJSON: template.JS(resultsJSON),
+		Demo:        demo,
 	}
 	err = t.Execute(w, data)
 	if err != nil {